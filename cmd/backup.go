@@ -0,0 +1,124 @@
+// Package cmd — backup.go implements `srv backup` and `srv restore`, which
+// snapshot the whole srv config directory to a .tar.gz and bring it back.
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+
+	"github.com/stubbedev/srv/internal/backup"
+	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/traefik"
+	"github.com/stubbedev/srv/internal/ui"
+)
+
+var restoreFlags struct {
+	yes bool
+}
+
+var backupCmd = &cobra.Command{
+	Use:   "backup OUTPUT.tar.gz",
+	Short: "Archive the srv config directory",
+	Long: `Write a snapshot of the srv config directory (site metadata, generated
+site configs, Traefik config, proxies, redirects and the domain registry) to
+a .tar.gz. Logs, runtime state such as the daemon's PID file, and the
+Let's Encrypt stores (acme.json) are left out.
+
+The archive includes env.traefik, which holds credentials such as the DNS
+provider token set by 'srv add --acme-dns-challenge'. It is written readable
+by you only; store it as you would the credentials themselves.
+
+The archive records the srv version and a checksum that 'srv restore'
+verifies.
+
+Examples:
+  srv backup ~/srv-backup.tar.gz`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBackup,
+}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore INPUT.tar.gz",
+	Short: "Restore the srv config directory from a backup",
+	Long: `Replace the srv config directory with the contents of an archive written
+by 'srv backup', then re-sync Traefik's dynamic config and dnsmasq. Files not
+in the backup are removed; logs, runtime state and the Let's Encrypt stores
+are kept.
+
+The archive is verified against its checksum before anything is changed.
+You're asked to confirm first; pass --yes to skip the prompt (required when
+stdin isn't a terminal). Restart sites afterwards to pick up restored
+container settings.
+
+Examples:
+  srv restore ~/srv-backup.tar.gz
+  srv restore ~/srv-backup.tar.gz --yes`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRestore,
+}
+
+func init() {
+	restoreCmd.Flags().BoolVarP(&restoreFlags.yes, "yes", "y", false, "Restore without asking for confirmation")
+	backupCmd.GroupID = GroupSystem
+	restoreCmd.GroupID = GroupSystem
+	RootCmd.AddCommand(backupCmd, restoreCmd)
+}
+
+func runBackup(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	manifest, err := backup.Create(cfg.Root, args[0], Version)
+	if err != nil {
+		return fmt.Errorf("backup failed: %w", err)
+	}
+	ui.Success("Backed up %d files from %s to %s", manifest.Files, cfg.Root, args[0])
+	return nil
+}
+
+func runRestore(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	staged, err := backup.Extract(args[0], cfg.Root)
+	if err != nil {
+		return fmt.Errorf("restore failed: %w", err)
+	}
+	defer staged.Cleanup()
+
+	m := staged.Manifest
+	ui.Info("Backup of %d files from %s (srv %s)", m.Files, m.CreatedAt.Local().Format(time.DateTime), m.Version)
+	if m.Version != Version {
+		ui.Warn("The backup was written by srv %s; this is srv %s.", m.Version, Version)
+	}
+	if !restoreFlags.yes {
+		if !isatty.IsTerminal(os.Stdin.Fd()) {
+			return fmt.Errorf("restore refused: stdin is not a terminal — re-run with --yes to overwrite %s", cfg.Root)
+		}
+		if !ui.Confirm(fmt.Sprintf("Overwrite the config in %s?", cfg.Root)) {
+			ui.Dim("Restore cancelled.")
+			return nil
+		}
+	}
+
+	if err := staged.Apply(cfg.Root); err != nil {
+		return fmt.Errorf("restore failed part-way, config may be incomplete: %w", err)
+	}
+	config.ResetCache()
+	ui.Success("Restored %s from %s", cfg.Root, args[0])
+
+	if err := traefik.UpdateDynamicConfig(); err != nil {
+		ui.Warn("Failed to refresh Traefik dynamic config: %v", err)
+	}
+	if err := traefik.UpdateDnsmasqConfig(); err != nil {
+		ui.Warn("Failed to refresh dnsmasq config: %v", err)
+	}
+	ui.Dim("Run `srv start --all` to bring sites up with the restored config.")
+	return nil
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stubbedev/srv/internal/site"
+)
+
+func TestRunBackupRestore(t *testing.T) {
+	root := setupSrvRoot(t)
+	writeTestSite(t, "blog", site.SiteMetadata{Type: site.SiteTypeStatic, ProjectPath: root, Port: 80, NetworkName: "n"})
+	out := filepath.Join(t.TempDir(), "srv.tar.gz")
+	if err := runBackup(nil, []string{out}); err != nil {
+		t.Fatalf("backup: %v", err)
+	}
+
+	writeTestSite(t, "later", site.SiteMetadata{Type: site.SiteTypeStatic, ProjectPath: root, Port: 80, NetworkName: "n"})
+	restoreFlags.yes = true
+	t.Cleanup(func() { restoreFlags.yes = false })
+	if err := runRestore(nil, []string{out}); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if !site.HasSiteMetadata("blog") {
+		t.Error("blog should be restored")
+	}
+	if site.HasSiteMetadata("later") {
+		t.Error("site added after the backup should be gone")
+	}
+}
+
+func TestRunRestoreRefusesWithoutYes(t *testing.T) {
+	root := setupSrvRoot(t)
+	writeTestSite(t, "blog", site.SiteMetadata{Type: site.SiteTypeStatic, ProjectPath: root, Port: 80, NetworkName: "n"})
+	out := filepath.Join(t.TempDir(), "srv.tar.gz")
+	if err := runBackup(nil, []string{out}); err != nil {
+		t.Fatal(err)
+	}
+	writeTestSite(t, "later", site.SiteMetadata{Type: site.SiteTypeStatic, ProjectPath: root, Port: 80, NetworkName: "n"})
+
+	// Test stdin is never a terminal, so there's no one to confirm.
+	if err := runRestore(nil, []string{out}); err == nil {
+		t.Error("expected err without --yes")
+	}
+	if !site.HasSiteMetadata("later") {
+		t.Error("a refused restore must not change the config")
+	}
+}
+
+func TestRunRestoreMissingArchive(t *testing.T) {
+	setupSrvRoot(t)
+	if err := runRestore(nil, []string{filepath.Join(os.TempDir(), "srv-no-such-backup.tar.gz")}); err == nil {
+		t.Error("expected err")
+	}
+}
@@ -0,0 +1,517 @@
+// Package cmd — cert.go implements `srv cert`, helpers for the mkcert-issued
+// certificates srv keeps for local sites.
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/constants"
+	"github.com/stubbedev/srv/internal/site"
+	"github.com/stubbedev/srv/internal/traefik"
+	"github.com/stubbedev/srv/internal/ui"
+)
+
+var certCmd = &cobra.Command{
+	Use:   "cert",
+	Short: "Manage local site certificates",
+}
+
+var certExportFlags struct {
+	format string
+}
+
+var certExportCmd = &cobra.Command{
+	Use:   "export SITE OUTPUT_DIR",
+	Short: "Copy a local site's certificate and key to a directory",
+	Long: `Copy the mkcert-generated certificate and key for a local site into
+OUTPUT_DIR, named after the site. Useful for tools that don't read the
+mkcert trust store (curl --cert, Postman, desktop apps).
+
+Formats:
+  pem  SITE.crt and SITE.key (default)
+  der  SITE.der and SITE.key.der
+
+Examples:
+  srv cert export myapp ./certs
+  srv cert export myapp ./certs --format der`,
+	Args: cobra.ExactArgs(2),
+	RunE: runCertExport,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return GetSiteNames(), cobra.ShellCompDirectiveNoFileComp
+		}
+		return nil, cobra.ShellCompDirectiveFilterDirs
+	},
+}
+
+var certListFlags struct {
+	expiredOnly  bool
+	expiringSoon bool
+}
+
+var certListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List local certificates with their expiry",
+	Long: `List every mkcert-issued certificate srv keeps, grouped by the site,
+proxy or redirect that owns it, with its status and expiry date.
+
+--expired-only shows only expired certificates; --expiring-soon shows only
+those inside the cert_warning_days window (expired ones included).
+
+Examples:
+  srv cert list
+  srv cert list --expiring-soon
+  srv cert list --json`,
+	Args: cobra.NoArgs,
+	RunE: runCertList,
+}
+
+var certInfoCmd = &cobra.Command{
+	Use:   "info DOMAIN",
+	Short: "Show the details of a local certificate",
+	Long: `Show the subject, issuer, SANs, validity and SHA-256 fingerprint of
+the local certificate issued for DOMAIN.
+
+Examples:
+  srv cert info myapp.test
+  srv cert info myapp.test --json`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return ui.UsageError("srv cert info DOMAIN", "requires exactly one domain")
+		}
+		return nil
+	},
+	RunE: runCertInfo,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) != 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		var domains []string
+		for _, cert := range traefik.ListLocalCerts() {
+			domains = append(domains, cert.Domain)
+		}
+		return domains, cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+var certRenewFlags struct {
+	all   bool
+	proxy string
+}
+
+var certRenewCmd = &cobra.Command{
+	Use:   "renew [SITE]",
+	Short: "Reissue a local certificate even if it isn't expiring",
+	Long: `Reissue the mkcert certificate for a local site (or, with --proxy, a
+proxy) regardless of its expiry, then reload Traefik's certificate list and
+restart Traefik so it serves the new certificate. The site's own containers
+are left running.
+
+--all reissues the certificates of every local site.
+
+Examples:
+  srv cert renew myapp
+  srv cert renew --all
+  srv cert renew --proxy api`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		const usage = "srv cert renew SITE | --all | --proxy NAME"
+		targets := len(args)
+		if certRenewFlags.all {
+			targets++
+		}
+		if certRenewFlags.proxy != "" {
+			targets++
+		}
+		switch {
+		case len(args) > 1:
+			return ui.UsageError(usage, "too many arguments — expected a single site name, got %d", len(args))
+		case targets == 0:
+			return ui.UsageError(usage, "a site name, --all or --proxy is required")
+		case targets > 1:
+			return ui.UsageError(usage, "pass only one of SITE, --all and --proxy")
+		}
+		return nil
+	},
+	RunE: runCertRenew,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return GetSiteNames(), cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+var certImportFlags struct {
+	cert string
+	key  string
+}
+
+var certImportCmd = &cobra.Command{
+	Use:   "import SITE --cert FILE --key FILE",
+	Short: "Serve a certificate you supply instead of an mkcert one",
+	Long: `Install a PEM certificate and private key (for example one issued by a
+corporate CA) as a local site's certificate. The pair must match and the
+certificate must cover the site's domain. srv then never reissues the
+certificate on start; renewing it is up to you (import again).
+
+Examples:
+  srv cert import myapp --cert ~/corp/myapp.crt --key ~/corp/myapp.key`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return ui.UsageError("srv cert import SITE --cert FILE --key FILE", "expected a single site name, got %d argument(s)", len(args))
+		}
+		return nil
+	},
+	RunE: runCertImport,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return GetSiteNames(), cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+func init() {
+	certImportCmd.Flags().StringVar(&certImportFlags.cert, "cert", "", "PEM certificate file (may include the chain)")
+	certImportCmd.Flags().StringVar(&certImportFlags.key, "key", "", "PEM private key file")
+	_ = certImportCmd.MarkFlagRequired("cert")
+	_ = certImportCmd.MarkFlagRequired("key")
+	certRenewCmd.Flags().BoolVar(&certRenewFlags.all, "all", false, "Renew the certificates of all local sites")
+	certRenewCmd.Flags().StringVar(&certRenewFlags.proxy, "proxy", "", "Renew a proxy's certificate instead of a site's")
+	_ = certRenewCmd.RegisterFlagCompletionFunc("proxy", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return proxyNameCompletions(), cobra.ShellCompDirectiveNoFileComp
+	})
+	certListCmd.Flags().BoolVar(&certListFlags.expiredOnly, "expired-only", false, "Only show expired certificates")
+	certListCmd.Flags().BoolVar(&certListFlags.expiringSoon, "expiring-soon", false, "Only show expired or soon-to-expire certificates")
+	addJSONFlag(certListCmd)
+	addJSONFlag(certInfoCmd)
+	certExportCmd.Flags().StringVar(&certExportFlags.format, "format", traefik.CertFormatPEM, "Output format: pem or der")
+	_ = certExportCmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{traefik.CertFormatPEM, traefik.CertFormatDER}, cobra.ShellCompDirectiveNoFileComp
+	})
+	certCmd.GroupID = GroupSites
+	certCmd.AddCommand(certListCmd, certInfoCmd, certRenewCmd, certImportCmd, certExportCmd)
+	RootCmd.AddCommand(certCmd)
+}
+
+func runCertExport(cmd *cobra.Command, args []string) error {
+	siteName, outDir := args[0], expandHome(args[1])
+	meta, err := site.ReadSiteMetadata(siteName)
+	if err != nil {
+		return err
+	}
+	if meta == nil {
+		return fmt.Errorf("site not found: %s", siteName)
+	}
+	if !meta.IsLocal {
+		return fmt.Errorf("site %s uses Let's Encrypt; only local (mkcert) certificates can be exported", siteName)
+	}
+	domain := meta.PrimaryDomain()
+
+	info := traefik.GetLocalCertInfo(siteName, domain)
+	if !info.Exists {
+		ui.Warn("No valid certificate for %s", domain)
+		return fmt.Errorf("nothing to export; run 'srv start %s' to regenerate it", siteName)
+	}
+	if info.IsExpired {
+		ui.Warn("Certificate for %s expired on %s", domain, info.ExpiresAt.Format("2006-01-02"))
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	certOut, keyOut, err := traefik.ExportLocalCert(cfg, siteName, domain, outDir, certExportFlags.format)
+	if err != nil {
+		return err
+	}
+	ui.Success("Exported certificate for %s", domain)
+	ui.Dim("Cert: %s", certOut)
+	ui.Dim("Key:  %s", keyOut)
+	return nil
+}
+
+// certListRow is one certificate in `srv cert list --json`.
+type certListRow struct {
+	Domain    string `json:"domain"`
+	Kind      string `json:"kind"`
+	Owner     string `json:"owner"`
+	Status    string `json:"status"`
+	ExpiresAt string `json:"expires_at,omitempty"`
+	DaysLeft  *int   `json:"days_left,omitempty"`
+}
+
+func runCertList(cmd *cobra.Command, args []string) error {
+	if certListFlags.expiredOnly && certListFlags.expiringSoon {
+		return ui.UsageError("srv cert list [--expired-only | --expiring-soon]", "--expired-only and --expiring-soon cannot be combined")
+	}
+
+	var rows []certListRow
+	for _, cert := range traefik.ListLocalCerts() {
+		status := cert.Status()
+		switch {
+		case certListFlags.expiredOnly && status != traefik.CertStatusExpired:
+			continue
+		case certListFlags.expiringSoon && status != traefik.CertStatusExpired && status != traefik.CertStatusExpiring:
+			continue
+		}
+		kind, owner := traefik.CertOwner(cert.SiteName)
+		row := certListRow{Domain: cert.Domain, Kind: kind, Owner: owner, Status: string(status)}
+		if cert.Exists {
+			days := cert.DaysLeft
+			row.ExpiresAt = cert.ExpiresAt.Format(constants.DateFormat)
+			row.DaysLeft = &days
+		}
+		rows = append(rows, row)
+	}
+
+	if jsonOutput() {
+		if rows == nil {
+			rows = []certListRow{}
+		}
+		return ui.PrintJSON(rows)
+	}
+	if len(rows) == 0 {
+		ui.Info("No local certificates found")
+		return nil
+	}
+
+	sections := []struct{ kind, title string }{
+		{traefik.CertOwnerSite, "Sites"},
+		{traefik.CertOwnerProxy, "Proxies"},
+		{traefik.CertOwnerRedirect, "Redirects"},
+	}
+	first := true
+	for _, section := range sections {
+		var table [][]string
+		for _, row := range rows {
+			if row.Kind != section.kind {
+				continue
+			}
+			expires, daysLeft := ui.DimText("-"), ui.DimText("-")
+			if row.DaysLeft != nil {
+				expires, daysLeft = row.ExpiresAt, strconv.Itoa(*row.DaysLeft)
+			}
+			table = append(table, []string{row.Domain, row.Owner, ui.StatusColor(row.Status), expires, daysLeft})
+		}
+		if len(table) == 0 {
+			continue
+		}
+		if !first {
+			ui.Print("")
+		}
+		first = false
+		ui.Print("%s:", section.title)
+		ui.PrintTable([]string{"DOMAIN", strings.ToUpper(section.kind), "STATUS", "EXPIRES", "DAYS LEFT"}, table)
+	}
+	return nil
+}
+
+func runCertInfo(cmd *cobra.Command, args []string) error {
+	details, err := traefik.LocalCertDetails(args[0])
+	if err != nil {
+		return err
+	}
+	kind, owner := traefik.CertOwner(details.SiteName)
+	status := string(details.Status())
+
+	if jsonOutput() {
+		return ui.PrintJSON(struct {
+			Domain      string   `json:"domain"`
+			Kind        string   `json:"kind"`
+			Owner       string   `json:"owner"`
+			Status      string   `json:"status"`
+			Path        string   `json:"path"`
+			Subject     string   `json:"subject"`
+			Issuer      string   `json:"issuer"`
+			SANs        []string `json:"sans"`
+			NotBefore   string   `json:"not_before"`
+			NotAfter    string   `json:"not_after"`
+			DaysLeft    int      `json:"days_left"`
+			Fingerprint string   `json:"sha256_fingerprint"`
+		}{
+			Domain:      details.Domain,
+			Kind:        kind,
+			Owner:       owner,
+			Status:      status,
+			Path:        details.Path,
+			Subject:     details.Subject,
+			Issuer:      details.Issuer,
+			SANs:        details.SANs,
+			NotBefore:   details.NotBefore.Format(time.RFC3339),
+			NotAfter:    details.ExpiresAt.Format(time.RFC3339),
+			DaysLeft:    details.DaysLeft,
+			Fingerprint: details.Fingerprint,
+		})
+	}
+
+	ui.Print("%s (%s)", details.Domain, ui.StatusColor(status))
+	ui.Print("  Owner:       %s %s", kind, owner)
+	ui.Print("  Subject:     %s", details.Subject)
+	ui.Print("  Issuer:      %s", details.Issuer)
+	ui.Print("  SANs:        %s", strings.Join(details.SANs, ", "))
+	ui.Print("  Valid from:  %s", details.NotBefore.Format(constants.DateFormat))
+	ui.Print("  Expires:     %s (%d days left)", details.ExpiresAt.Format(constants.DateFormat), details.DaysLeft)
+	ui.Print("  SHA-256:     %s", details.Fingerprint)
+	ui.Print("  File:        %s", details.Path)
+	return nil
+}
+
+func runCertRenew(cmd *cobra.Command, args []string) error {
+	if err := traefik.CheckMkcert(); err != nil {
+		ui.Warn("mkcert is not installed; certificates can't be renewed")
+		return err
+	}
+	if !traefik.IsCAInstalled() {
+		ui.Warn("The mkcert CA is not installed; browsers won't trust the renewed certificates (run 'srv install')")
+	}
+
+	switch {
+	case certRenewFlags.proxy != "":
+		if err := renewProxyCert(certRenewFlags.proxy); err != nil {
+			return err
+		}
+	case certRenewFlags.all:
+		sites, err := site.List()
+		if err != nil {
+			return err
+		}
+		var local []site.Site
+		for _, s := range sites {
+			switch {
+			case !s.IsLocal || !s.ServesHTTPS():
+			case s.CustomCert:
+				ui.Dim("Skipping %s (imported certificate)", s.Name)
+			default:
+				local = append(local, s)
+			}
+		}
+		if len(local) == 0 {
+			ui.Info("No local sites to renew")
+			return nil
+		}
+		ui.Info("Renewing %d %s...", len(local), plural(len(local), "certificate", "certificates"))
+		if err := runBatchSiteOperation(local, "renew", renewSiteCert); err != nil {
+			return err
+		}
+	default:
+		s, err := site.GetByName(args[0])
+		if err != nil {
+			return err
+		}
+		if !s.IsLocal {
+			return fmt.Errorf("site %s uses Let's Encrypt; only local (mkcert) certificates can be renewed", s.Name)
+		}
+		if !s.ServesHTTPS() {
+			return fmt.Errorf("site %s serves plain HTTP only and has no certificate", s.Name)
+		}
+		if s.CustomCert {
+			return fmt.Errorf("site %s serves an imported certificate; replace it with 'srv cert import'", s.Name)
+		}
+		if err := renewSiteCert(s); err != nil {
+			return err
+		}
+	}
+	return reloadTraefikCerts()
+}
+
+// renewSiteCert reissues a local site's certificate and reports its expiry.
+func renewSiteCert(s *site.Site) error {
+	return renewCert(s.Name, s.Domains, s.Wildcard)
+}
+
+// renewProxyCert reissues a proxy's certificate and reports its expiry.
+func renewProxyCert(name string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(getProxyNames(), name) {
+		return fmt.Errorf("proxy '%s' not found", name)
+	}
+	info := readProxyConfig(cfg, name)
+	if info.Domain == "" {
+		return fmt.Errorf("could not read the domain of proxy '%s'", name)
+	}
+	if !traefik.LocalCertsExist(proxyCertSiteName(name), info.Domain) {
+		return fmt.Errorf("proxy '%s' has no local certificate to renew", name)
+	}
+	return renewCert(proxyCertSiteName(name), []string{info.Domain}, info.Wildcard)
+}
+
+// renewCert reissues the certificate stored under certSite for domains.
+func renewCert(certSite string, domains []string, wildcard bool) error {
+	if err := traefik.GenerateLocalCert(certSite, domains, wildcard); err != nil {
+		return err
+	}
+	cert := traefik.GetLocalCertInfo(certSite, domains[0])
+	if !cert.Exists {
+		return fmt.Errorf("certificate for %s was not written", domains[0])
+	}
+	ui.SafeSuccess("Renewed certificate for %s (expires %s)", domains[0], cert.ExpiresAt.Format(constants.DateFormat))
+	return nil
+}
+
+// reloadTraefikCerts rewrites Traefik's certificate list and restarts Traefik,
+// if it is running, so renewed certificates are served straight away.
+func reloadTraefikCerts() error {
+	if err := traefik.UpdateDynamicConfig(); err != nil {
+		return fmt.Errorf("failed to update Traefik config: %w", err)
+	}
+	if !traefik.IsRunning() {
+		return nil
+	}
+	if err := traefik.RestartTraefik(); err != nil {
+		return fmt.Errorf("failed to restart Traefik: %w", err)
+	}
+	ui.Dim("Restarted Traefik")
+	return nil
+}
+
+func runCertImport(cmd *cobra.Command, args []string) error {
+	siteName := args[0]
+	meta, err := site.ReadSiteMetadata(siteName)
+	if err != nil {
+		return err
+	}
+	if meta == nil {
+		return fmt.Errorf("site not found: %s", siteName)
+	}
+	if !meta.IsLocal {
+		return fmt.Errorf("site %s uses Let's Encrypt; only local sites can serve an imported certificate", siteName)
+	}
+	domain := meta.PrimaryDomain()
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	leaf, err := traefik.ImportLocalCert(cfg, siteName, domain, expandHome(certImportFlags.cert), expandHome(certImportFlags.key))
+	if err != nil {
+		return err
+	}
+	if !meta.CustomCert {
+		meta.CustomCert = true
+		if err := site.WriteSiteMetadata(siteName, *meta); err != nil {
+			return fmt.Errorf("update site metadata: %w", err)
+		}
+	}
+	if !traefik.LocalCertCovers(siteName, meta.Domains, meta.Wildcard) {
+		ui.Warn("The certificate does not cover every domain of %s", siteName)
+	}
+	if time.Now().After(leaf.NotAfter) {
+		ui.Warn("The certificate expired on %s", leaf.NotAfter.Format(constants.DateFormat))
+	}
+	if err := reloadTraefikCerts(); err != nil {
+		return err
+	}
+	ui.Success("Imported certificate for %s (expires %s)", domain, leaf.NotAfter.Format(constants.DateFormat))
+	return nil
+}
@@ -0,0 +1,253 @@
+package cmd
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stubbedev/srv/internal/mkcert"
+	"github.com/stubbedev/srv/internal/site"
+	"github.com/stubbedev/srv/internal/traefik"
+)
+
+func TestRunCertExportRejects(t *testing.T) {
+	setupSrvRoot(t)
+	out := t.TempDir()
+	if err := runCertExport(nil, []string{"ghost", out}); err == nil {
+		t.Error("expected error for missing site")
+	}
+	writeTestSite(t, "prod", site.SiteMetadata{Type: site.SiteTypeStatic, Domains: []string{"prod.example.com"}})
+	if err := runCertExport(nil, []string{"prod", out}); err == nil {
+		t.Error("expected error for a Let's Encrypt site")
+	}
+	writeTestSite(t, "app", site.SiteMetadata{Type: site.SiteTypeStatic, Domains: []string{"app.test"}, IsLocal: true})
+	if err := runCertExport(nil, []string{"app", out}); err == nil {
+		t.Error("expected error when no certificate exists")
+	}
+}
+
+// selfSignedPEM returns a PEM certificate for domain expiring after validFor,
+// and its PEM private key.
+func selfSignedPEM(domain string, validFor time.Duration) (certPEM, keyPEM []byte, err error) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: domain},
+		NotBefore:    time.Now().Add(-48 * time.Hour),
+		NotAfter:     time.Now().Add(validFor),
+		DNSNames:     []string{domain},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &priv.PublicKey, priv)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), nil
+}
+
+// writeLocalCert writes a self-signed certificate and key for domain into
+// siteDir's certs directory, expiring after validFor.
+func writeLocalCert(t *testing.T, siteDir, domain string, validFor time.Duration) {
+	t.Helper()
+	certPEM, keyPEM, err := selfSignedPEM(domain, validFor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := mustLoadConfig(t).SiteCertsDir(siteDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, domain+".crt"), certPEM, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, domain+".key"), keyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCertListAndInfo(t *testing.T) {
+	setupSrvRoot(t)
+	writeLocalCert(t, "app", "app.test", 300*24*time.Hour)
+	writeLocalCert(t, "_proxy-api", "api.test", 5*24*time.Hour)
+	writeLocalCert(t, "_redirect-old", "old.test", -24*time.Hour)
+	t.Cleanup(func() { certListFlags.expiredOnly, certListFlags.expiringSoon = false, false })
+
+	stdout, _ := executeRoot(t, "cert", "list")
+	for _, want := range []string{"Sites:", "Proxies:", "Redirects:", "app.test", "api.test", "old.test"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("cert list output missing %q:\n%s", want, stdout)
+		}
+	}
+
+	var rows []certListRow
+	runJSONCommand(t, &rows, "cert", "list", "--json", "--expiring-soon")
+	if len(rows) != 2 || rows[0].Kind == "site" || rows[1].Kind == "site" {
+		t.Errorf("--expiring-soon rows = %+v, want the proxy and redirect certs", rows)
+	}
+	certListFlags.expiringSoon = false
+
+	rows = nil
+	runJSONCommand(t, &rows, "cert", "list", "--json", "--expired-only")
+	if len(rows) != 1 || rows[0].Domain != "old.test" || rows[0].Kind != "redirect" || rows[0].Owner != "old" || rows[0].Status != "expired" {
+		t.Errorf("--expired-only rows = %+v", rows)
+	}
+
+	var info struct {
+		Kind        string   `json:"kind"`
+		Owner       string   `json:"owner"`
+		SANs        []string `json:"sans"`
+		Fingerprint string   `json:"sha256_fingerprint"`
+	}
+	runJSONCommand(t, &info, "cert", "info", "api.test", "--json")
+	if info.Kind != "proxy" || info.Owner != "api" || len(info.SANs) != 1 || info.Fingerprint == "" {
+		t.Errorf("cert info = %+v", info)
+	}
+}
+
+// issuingMkcertRunner stands in for mkcert's cert generation: it writes a
+// fresh year-long certificate to -cert-file and records the domains asked for.
+type issuingMkcertRunner struct {
+	stubMkcertRunner
+	mu     sync.Mutex
+	issued []string
+}
+
+func (r *issuingMkcertRunner) Output(args ...string) ([]byte, error) {
+	if len(args) < 4 || args[0] != "-cert-file" {
+		return stubMkcertRunner{}.Output(args...)
+	}
+	certPEM, keyPEM, err := selfSignedPEM(args[4], 365*24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(args[1], certPEM, 0o644); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(args[3], keyPEM, 0o600); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.issued = append(r.issued, args[4])
+	return nil, nil
+}
+
+func TestCertRenew(t *testing.T) {
+	root := setupSrvRoot(t)
+	runner := &issuingMkcertRunner{}
+	t.Cleanup(mkcert.SwapRunner(runner))
+	t.Cleanup(func() { certRenewFlags.all, certRenewFlags.proxy = false, "" })
+
+	for name, domain := range map[string]string{"app": "app.test", "blog": "blog.test", "prod": "prod.example.com"} {
+		projectDir := filepath.Join(root, "projects", name)
+		if err := os.MkdirAll(projectDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		writeTestSite(t, name, site.SiteMetadata{
+			Type:        site.SiteTypeStatic,
+			Domains:     []string{domain},
+			ProjectPath: projectDir,
+			IsLocal:     name != "prod",
+		})
+	}
+	// Well inside its validity: a plain start would leave it alone.
+	writeLocalCert(t, "app", "app.test", 300*24*time.Hour)
+
+	executeRoot(t, "cert", "renew", "app")
+	if cert := traefik.GetLocalCertInfo("app", "app.test"); cert.DaysLeft < 360 {
+		t.Errorf("app.test has %d days left after renew, want a fresh certificate", cert.DaysLeft)
+	}
+	dynamic, err := os.ReadFile(filepath.Join(mustLoadConfig(t).TraefikConfDir(), "traefik-dynamic.yml"))
+	if err != nil || !strings.Contains(string(dynamic), "app.test.crt") {
+		t.Errorf("dynamic config not refreshed (%v):\n%s", err, dynamic)
+	}
+
+	runner.issued = nil
+	executeRoot(t, "cert", "renew", "--all")
+	slices.Sort(runner.issued)
+	if !slices.Equal(runner.issued, []string{"app.test", "blog.test"}) {
+		t.Errorf("--all issued %v, want the two local sites", runner.issued)
+	}
+
+	certRenewFlags.all = false
+	if err := runCertRenew(nil, []string{"prod"}); err == nil {
+		t.Error("expected an error renewing a Let's Encrypt site")
+	}
+}
+
+func TestCertImport(t *testing.T) {
+	root := setupSrvRoot(t)
+	runner := &issuingMkcertRunner{}
+	t.Cleanup(mkcert.SwapRunner(runner))
+	t.Cleanup(func() { certImportFlags.cert, certImportFlags.key = "", "" })
+	projectDir := filepath.Join(root, "projects", "app")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestSite(t, "app", site.SiteMetadata{Type: site.SiteTypeStatic, Domains: []string{"app.corp"}, ProjectPath: projectDir, IsLocal: true})
+
+	src := t.TempDir()
+	writeCert := func(domain string) (string, string) {
+		certPEM, keyPEM, err := selfSignedPEM(domain, 10*24*time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		certPath, keyPath := filepath.Join(src, domain+".crt"), filepath.Join(src, domain+".key")
+		if err := os.WriteFile(certPath, certPEM, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(keyPath, keyPEM, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return certPath, keyPath
+	}
+
+	otherCert, otherKey := writeCert("other.corp")
+	certImportFlags.cert, certImportFlags.key = otherCert, otherKey
+	if err := runCertImport(nil, []string{"app"}); err == nil {
+		t.Error("expected an error importing a certificate for another domain")
+	}
+	certPath, _ := writeCert("app.corp")
+	certImportFlags.cert, certImportFlags.key = certPath, otherKey
+	if err := runCertImport(nil, []string{"app"}); err == nil {
+		t.Error("expected an error importing a mismatched key")
+	}
+
+	executeRoot(t, "cert", "import", "app", "--cert", certPath, "--key", filepath.Join(src, "app.corp.key"))
+	meta, err := site.ReadSiteMetadata("app")
+	if err != nil || !meta.CustomCert {
+		t.Fatalf("custom_cert not recorded: %+v, %v", meta, err)
+	}
+	if cert := traefik.GetLocalCertInfo("app", "app.corp"); !cert.Exists || cert.DaysLeft > 10 {
+		t.Errorf("imported certificate not installed: %+v", cert)
+	}
+
+	// Even inside the renewal window, the imported certificate is left alone.
+	if _, err := site.Reload("app"); err != nil {
+		t.Fatal(err)
+	}
+	if err := runCertRenew(nil, []string{"app"}); err == nil {
+		t.Error("expected cert renew to refuse an imported certificate")
+	}
+	if len(runner.issued) != 0 {
+		t.Errorf("mkcert reissued %v over the imported certificate", runner.issued)
+	}
+}
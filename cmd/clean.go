@@ -0,0 +1,338 @@
+// Package cmd — clean.go implements `srv clean`, which removes what deleted
+// projects and removed proxies leave behind: broken sites, Traefik configs
+// without an owner, and containers still attached to srv's network.
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+
+	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/constants"
+	"github.com/stubbedev/srv/internal/docker"
+	"github.com/stubbedev/srv/internal/metrics"
+	"github.com/stubbedev/srv/internal/site"
+	"github.com/stubbedev/srv/internal/traefik"
+	"github.com/stubbedev/srv/internal/ui"
+)
+
+var cleanFlags struct {
+	prune  bool
+	yes    bool
+	dryRun bool
+}
+
+var cleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Remove orphaned sites, configs and containers",
+	Long: `Find and remove what deleted projects and removed proxies leave behind:
+
+  - sites whose project directory no longer exists (their config, local
+    certificate and DNS records are removed as with 'srv remove')
+  - Traefik site and route configs for sites that aren't registered, and
+    certificates of proxies that no longer exist
+  - containers attached to srv's Docker network that no site or proxy uses
+    (they're disconnected, not removed)
+
+--prune also runs 'docker image prune -f' and 'docker volume prune -f'.
+
+Everything found is listed and you're asked to confirm first; pass --yes to
+skip the prompt (required when stdin isn't a terminal), or --dry-run to only
+list it.
+
+Examples:
+  srv clean
+  srv clean --dry-run
+  srv clean --prune --yes`,
+	Args: cobra.NoArgs,
+	RunE: runClean,
+}
+
+func init() {
+	cleanCmd.Flags().BoolVar(&cleanFlags.prune, "prune", false, "Also prune unused Docker images and volumes")
+	cleanCmd.Flags().BoolVarP(&cleanFlags.yes, "yes", "y", false, "Clean up without asking for confirmation")
+	cleanCmd.Flags().BoolVar(&cleanFlags.dryRun, "dry-run", false, "Show what would be cleaned up without changing anything")
+	cleanCmd.GroupID = GroupSystem
+	RootCmd.AddCommand(cleanCmd)
+}
+
+// cleanNetworkContainers lists the containers on a network. Tests swap it.
+var cleanNetworkContainers = docker.NetworkContainers
+
+// pruneKinds are the `docker <kind> prune -f` runs --prune adds.
+var pruneKinds = []string{"image", "volume"}
+
+// cleanPlan is what `srv clean` found to remove.
+type cleanPlan struct {
+	brokenSites []string // registered sites whose project directory is gone
+	files       []string // configs and cert dirs nothing refers to
+	containers  []string // containers on srv's network no site or proxy uses
+}
+
+func (p cleanPlan) empty() bool {
+	return len(p.brokenSites) == 0 && len(p.files) == 0 && len(p.containers) == 0
+}
+
+func runClean(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	plan, err := buildCleanPlan(cfg)
+	if err != nil {
+		return err
+	}
+	if plan.empty() && !cleanFlags.prune {
+		ui.Success("Nothing to clean up")
+		return nil
+	}
+
+	if cleanFlags.dryRun {
+		ui.Info("Dry run: srv clean would")
+	} else {
+		ui.Info("srv clean will")
+	}
+	printPlanSection("remove broken sites", plan.brokenSites)
+	printPlanSection("delete orphaned files", plan.files)
+	printPlanSection("disconnect from "+cfg.NetworkName, plan.containers)
+	if cleanFlags.prune {
+		runs := make([]string, len(pruneKinds))
+		for i, kind := range pruneKinds {
+			runs[i] = "docker " + kind + " prune -f"
+		}
+		printPlanSection("run", runs)
+	}
+	if cleanFlags.dryRun {
+		ui.Dim("Nothing was changed (--dry-run)")
+		return nil
+	}
+	if !cleanFlags.yes {
+		if !isatty.IsTerminal(os.Stdin.Fd()) {
+			return fmt.Errorf("clean refused: stdin is not a terminal — re-run with --yes to clean up")
+		}
+		if !ui.Confirm("Clean up?") {
+			ui.Dim("Clean cancelled.")
+			return nil
+		}
+	}
+
+	failed := applyCleanPlan(cfg, plan)
+	if cleanFlags.prune {
+		for _, kind := range pruneKinds {
+			if err := docker.Prune(kind); err != nil {
+				ui.Warn("docker %s prune failed: %v", kind, err)
+				failed++
+			}
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("clean finished with %d %s", failed, plural(failed, "failure", "failures"))
+	}
+	ui.Success("Clean up complete")
+	return nil
+}
+
+// buildCleanPlan collects the broken sites, orphaned files and stray network
+// containers. An unreachable Docker daemon only skips the container scan.
+func buildCleanPlan(cfg *config.Config) (cleanPlan, error) {
+	var plan cleanPlan
+	sites, err := site.List()
+	if err != nil {
+		return plan, err
+	}
+	registered := map[string]bool{}
+	var live []site.Site
+	for _, s := range sites {
+		if s.IsBroken {
+			plan.brokenSites = append(plan.brokenSites, s.Name)
+			continue
+		}
+		registered[s.Name] = true
+		live = append(live, s)
+	}
+	proxies := map[string]bool{}
+	for _, name := range getProxyNames() {
+		proxies[name] = true
+	}
+
+	plan.files, err = orphanedConfigFiles(cfg, registered, proxies)
+	if err != nil {
+		return plan, err
+	}
+
+	containers, err := cleanNetworkContainers(cfg.NetworkName)
+	if err != nil {
+		ui.Warn("Skipping the %s network scan: %v", cfg.NetworkName, err)
+		return plan, nil
+	}
+	plan.containers = orphanedNetworkContainers(containers, live, proxyContainerNames(cfg))
+	return plan, nil
+}
+
+// orphanedConfigFiles returns the site and route configs in the Traefik conf
+// dir whose site isn't registered (broken sites count as unregistered, since
+// clean removes them) and the cert dirs of proxies that no longer exist.
+func orphanedConfigFiles(cfg *config.Config, registered, proxies map[string]bool) ([]string, error) {
+	var files []string
+	entries, err := os.ReadDir(cfg.TraefikConfDir())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, constants.ExtYAML) {
+			continue
+		}
+		for _, prefix := range []string{constants.SiteConfigPrefix, constants.RoutesConfigPrefix} {
+			if siteName, ok := strings.CutPrefix(strings.TrimSuffix(name, constants.ExtYAML), prefix); ok && !registered[siteName] {
+				files = append(files, filepath.Join(cfg.TraefikConfDir(), name))
+			}
+		}
+	}
+
+	entries, err = os.ReadDir(cfg.SitesDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if proxyName, ok := strings.CutPrefix(entry.Name(), proxyCertSiteName("")); ok && entry.IsDir() && !proxies[proxyName] {
+			files = append(files, filepath.Join(cfg.SitesDir, entry.Name()))
+		}
+	}
+	return files, nil
+}
+
+// orphanedNetworkContainers returns the containers that nothing srv manages
+// accounts for (see networkClaims).
+func orphanedNetworkContainers(containers []docker.NetworkContainer, sites []site.Site, proxyContainers map[string]bool) []string {
+	claims := newNetworkClaims(sites, proxyContainers)
+	var orphans []string
+	for _, c := range containers {
+		if claims.owner(c) == "" {
+			orphans = append(orphans, c.Name)
+		}
+	}
+	return orphans
+}
+
+// Owners networkClaims reports for containers that aren't a site's.
+const (
+	ownerSrv   = "srv"
+	ownerProxy = "proxy"
+)
+
+// networkClaims records what accounts for the containers on srv's network:
+// srv's own containers, containers labelled with a registered site, those of
+// a registered compose project, the service a site routes to, and container
+// proxy targets.
+type networkClaims struct {
+	srv         map[string]bool
+	proxies     map[string]bool
+	sites       map[string]bool
+	services    map[string]string // container name -> site
+	composeDirs map[string]string // compose working dir -> site
+}
+
+func newNetworkClaims(sites []site.Site, proxyContainers map[string]bool) networkClaims {
+	claims := networkClaims{
+		srv: map[string]bool{
+			docker.ContainerTraefik:     true,
+			docker.ContainerDNS:         true,
+			metrics.PrometheusContainer: true,
+			metrics.GrafanaContainer:    true,
+		},
+		proxies:     proxyContainers,
+		sites:       map[string]bool{},
+		services:    map[string]string{},
+		composeDirs: map[string]string{},
+	}
+	for _, s := range sites {
+		claims.sites[s.Name] = true
+		if s.ServiceName != "" {
+			claims.services[s.ServiceName] = s.Name
+		}
+		if s.ComposeDir != "" {
+			claims.composeDirs[s.ComposeDir] = s.Name
+		}
+	}
+	return claims
+}
+
+// owner returns the site a container belongs to, ownerSrv or ownerProxy, or
+// "" when nothing claims it. A container labelled with a site that isn't
+// registered is unclaimed.
+func (n networkClaims) owner(c docker.NetworkContainer) string {
+	if siteName, ok := c.Labels[constants.LabelSrvSite]; ok {
+		if n.sites[siteName] {
+			return siteName
+		}
+		return ""
+	}
+	switch {
+	case n.srv[c.Name]:
+		return ownerSrv
+	case n.proxies[c.Name]:
+		return ownerProxy
+	}
+	if siteName, ok := n.services[c.Name]; ok {
+		return siteName
+	}
+	return n.composeDirs[c.Labels["com.docker.compose.project.working_dir"]]
+}
+
+// proxyContainerNames returns the containers that proxies route to.
+func proxyContainerNames(cfg *config.Config) map[string]bool {
+	names := map[string]bool{}
+	for _, name := range getProxyNames() {
+		if c := readProxyConfig(cfg, name).Container; c != "" {
+			names[c] = true
+		}
+	}
+	return names
+}
+
+// applyCleanPlan carries out the plan, warning about each step that fails,
+// and returns how many did.
+func applyCleanPlan(cfg *config.Config, plan cleanPlan) int {
+	failed := 0
+	for _, name := range plan.brokenSites {
+		warnings, err := site.RemoveSite(name)
+		for _, w := range warnings {
+			ui.Warn("%s: %s", name, w)
+		}
+		if err != nil {
+			ui.Warn("Failed to remove site %s: %v", name, err)
+			failed++
+			continue
+		}
+		ui.Success("Removed broken site %s", name)
+	}
+	for _, path := range plan.files {
+		if err := os.RemoveAll(path); err != nil {
+			ui.Warn("Failed to delete %s: %v", path, err)
+			failed++
+			continue
+		}
+		ui.Success("Deleted %s", path)
+	}
+	if len(plan.files) > 0 {
+		// Drop the deleted certificates from the dynamic config.
+		if err := traefik.UpdateDynamicConfig(); err != nil {
+			ui.Warn("Failed to refresh Traefik dynamic config: %v", err)
+		}
+	}
+	for _, name := range plan.containers {
+		if err := docker.DisconnectContainerFromNetwork(name, cfg.NetworkName); err != nil {
+			ui.Warn("Failed to disconnect %s: %v", name, err)
+			failed++
+			continue
+		}
+		ui.Success("Disconnected %s from %s", name, cfg.NetworkName)
+	}
+	return failed
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stubbedev/srv/internal/docker"
+	"github.com/stubbedev/srv/internal/site"
+)
+
+func swapCleanNetworkContainers(t *testing.T, containers []docker.NetworkContainer, err error) {
+	t.Helper()
+	prev := cleanNetworkContainers
+	cleanNetworkContainers = func(string) ([]docker.NetworkContainer, error) { return containers, err }
+	t.Cleanup(func() { cleanNetworkContainers = prev })
+}
+
+func resetCleanFlags(t *testing.T) {
+	t.Helper()
+	cleanFlags.prune, cleanFlags.yes, cleanFlags.dryRun = false, false, false
+	t.Cleanup(func() { cleanFlags.prune, cleanFlags.yes, cleanFlags.dryRun = false, false, false })
+}
+
+func TestOrphanedNetworkContainers(t *testing.T) {
+	sites := []site.Site{
+		{Name: "blog", ServiceName: "blog-web", ComposeDir: "/src/blog"},
+	}
+	containers := []docker.NetworkContainer{
+		{Name: docker.ContainerTraefik},
+		{Name: "blog-web"},
+		{Name: "blog-db", Labels: map[string]string{"com.docker.compose.project.working_dir": "/src/blog"}},
+		{Name: "srv_static_docs", Labels: map[string]string{"dev.srv.site": "docs"}},
+		{Name: "api"},
+		{Name: "leftover"},
+	}
+	got := orphanedNetworkContainers(containers, sites, map[string]bool{"api": true})
+	if strings.Join(got, ",") != "srv_static_docs,leftover" {
+		t.Errorf("orphans = %v, want [srv_static_docs leftover]", got)
+	}
+}
+
+func TestNetworkClaimsOwner(t *testing.T) {
+	claims := newNetworkClaims([]site.Site{
+		{Name: "blog", ServiceName: "blog-web", ComposeDir: "/src/blog"},
+	}, map[string]bool{"api": true})
+	cases := map[string]docker.NetworkContainer{
+		"blog":  {Name: "blog-db", Labels: map[string]string{"com.docker.compose.project.working_dir": "/src/blog"}},
+		"srv":   {Name: docker.ContainerTraefik},
+		"proxy": {Name: "api"},
+		"":      {Name: "srv_static_docs", Labels: map[string]string{"dev.srv.site": "docs"}},
+	}
+	for want, c := range cases {
+		if got := claims.owner(c); got != want {
+			t.Errorf("owner(%s) = %q, want %q", c.Name, got, want)
+		}
+	}
+}
+
+func TestOrphanedConfigFiles(t *testing.T) {
+	setupSrvRoot(t)
+	cfg := mustLoadConfig(t)
+	for _, name := range []string{"site-blog.yml", "site-gone.yml", "routes-gone.yml", "proxy-api.yml", "dynamic.yml"} {
+		if err := os.WriteFile(filepath.Join(cfg.TraefikConfDir(), name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, dir := range []string{"_proxy-api", "_proxy-old"} {
+		if err := os.MkdirAll(filepath.Join(cfg.SitesDir, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := orphanedConfigFiles(cfg, map[string]bool{"blog": true}, map[string]bool{"api": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		filepath.Join(cfg.TraefikConfDir(), "routes-gone.yml"),
+		filepath.Join(cfg.TraefikConfDir(), "site-gone.yml"),
+		filepath.Join(cfg.SitesDir, "_proxy-old"),
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+}
+
+func TestRunCleanRemovesBrokenSite(t *testing.T) {
+	root := setupSrvRoot(t)
+	resetCleanFlags(t)
+	t.Cleanup(docker.SwapNewClientOK())
+	swapCleanNetworkContainers(t, nil, errors.New("no network"))
+	writeTestSite(t, "gone", site.SiteMetadata{
+		Type:        site.SiteTypeCompose,
+		Domains:     []string{"gone.example.com"},
+		ProjectPath: filepath.Join(root, "missing"),
+	})
+	cfg := mustLoadConfig(t)
+	routeFile := filepath.Join(cfg.TraefikConfDir(), "site-gone.yml")
+	if err := os.WriteFile(routeFile, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cleanFlags.dryRun = true
+	if err := runClean(nil, nil); err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if _, err := os.Stat(routeFile); err != nil {
+		t.Fatalf("dry run changed files: %v", err)
+	}
+
+	cleanFlags.dryRun = false
+	cleanFlags.yes = true
+	if err := runClean(nil, nil); err != nil {
+		t.Fatalf("clean: %v", err)
+	}
+	if _, err := os.Stat(site.SiteConfigDir(cfg, "gone")); !os.IsNotExist(err) {
+		t.Errorf("broken site config dir still exists: %v", err)
+	}
+	if _, err := os.Stat(routeFile); !os.IsNotExist(err) {
+		t.Errorf("orphaned route config still exists: %v", err)
+	}
+}
+
+func TestRunCleanRefusesWithoutTerminal(t *testing.T) {
+	root := setupSrvRoot(t)
+	resetCleanFlags(t)
+	t.Cleanup(docker.SwapNewClientOK())
+	swapCleanNetworkContainers(t, []docker.NetworkContainer{{Name: "leftover"}}, nil)
+	writeTestSite(t, "gone", site.SiteMetadata{
+		Type:        site.SiteTypeCompose,
+		Domains:     []string{"gone.example.com"},
+		ProjectPath: filepath.Join(root, "missing"),
+	})
+	if err := runClean(nil, nil); err == nil || !strings.Contains(err.Error(), "--yes") {
+		t.Errorf("err = %v, want refusal mentioning --yes", err)
+	}
+}
+
+func TestRunCleanPrune(t *testing.T) {
+	setupSrvRoot(t)
+	resetCleanFlags(t)
+	t.Cleanup(docker.SwapNewClientOK())
+	swapCleanNetworkContainers(t, nil, nil)
+	var runs []string
+	t.Cleanup(docker.SwapDockerExec(func(_ bool, args ...string) error {
+		runs = append(runs, strings.Join(args, " "))
+		return nil
+	}))
+	cleanFlags.prune = true
+	cleanFlags.yes = true
+	if err := runClean(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(runs, ";") != "image prune -f;volume prune -f" {
+		t.Errorf("docker runs = %v", runs)
+	}
+}
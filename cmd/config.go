@@ -0,0 +1,500 @@
+// Package cmd — config.go implements `srv config get|set|list|show`, which
+// read and change user settings stored in ~/.config/srv/config.yml.
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/constants"
+	"github.com/stubbedev/srv/internal/daemon"
+	"github.com/stubbedev/srv/internal/docker"
+	"github.com/stubbedev/srv/internal/traefik"
+	"github.com/stubbedev/srv/internal/ui"
+	"github.com/stubbedev/srv/internal/validate"
+)
+
+// configKey describes one user setting exposed through `srv config`.
+type configKey struct {
+	name  string
+	desc  string
+	def   string // value in effect when the setting is unset
+	get   func(uc *config.UserConfig) string
+	set   func(uc *config.UserConfig, value string) error
+	apply func() error // re-applies derived state after a change; may be nil
+	// needsUpdate marks settings that only take effect once `srv images update`
+	// pulls the image and recreates the containers.
+	needsUpdate bool
+}
+
+// configKeys lists the settings `srv config` can read and write.
+var configKeys = []configKey{
+	{
+		name: "local-tlds",
+		desc: "Extra comma-separated TLDs treated as local, in addition to " + strings.Join(traefik.LocalDomains, ", "),
+		get:  func(uc *config.UserConfig) string { return strings.Join(uc.LocalTLDs, ",") },
+		set: func(uc *config.UserConfig, value string) error {
+			tlds, err := parseLocalTLDs(value)
+			if err != nil {
+				return err
+			}
+			uc.LocalTLDs = tlds
+			return nil
+		},
+		apply: traefik.UpdateDnsmasqConfig,
+	},
+	{
+		name: "upstream-dns",
+		desc: "Comma-separated resolvers dnsmasq forwards non-local queries to",
+		def:  constants.GoogleDNS1 + "," + constants.GoogleDNS2,
+		get:  func(uc *config.UserConfig) string { return strings.Join(uc.UpstreamDNS, ",") },
+		set: func(uc *config.UserConfig, value string) error {
+			servers, err := parseUpstreamDNS(value)
+			if err != nil {
+				return err
+			}
+			uc.UpstreamDNS = servers
+			return nil
+		},
+		apply: traefik.UpdateDnsmasqConfig,
+	},
+	{
+		name: "max-workers",
+		desc: "How many sites batch operations (start/stop/pull --all) handle in parallel",
+		def:  strconv.Itoa(constants.MaxWorkers),
+		get:  func(uc *config.UserConfig) string { return formatIntSetting(uc.MaxWorkers) },
+		set: func(uc *config.UserConfig, value string) (err error) {
+			uc.MaxWorkers, err = parseIntSetting(value, 1, 64)
+			return err
+		},
+	},
+	{
+		name: "default-cert-days-warning",
+		desc: "Days before expiry a local certificate is reported as expiring",
+		def:  strconv.Itoa(constants.CertExpiryWarningDays),
+		get:  func(uc *config.UserConfig) string { return formatIntSetting(uc.CertWarningDays) },
+		set: func(uc *config.UserConfig, value string) (err error) {
+			uc.CertWarningDays, err = parseIntSetting(value, 1, 365)
+			return err
+		},
+	},
+	{
+		name: "traefik-image",
+		desc: "Docker image for the Traefik container",
+		def:  docker.ImageTraefik,
+		get:  func(uc *config.UserConfig) string { return uc.TraefikImage },
+		set: func(uc *config.UserConfig, value string) (err error) {
+			uc.TraefikImage, err = parseImageSetting(value)
+			return err
+		},
+		apply:       traefik.WriteCompose,
+		needsUpdate: true,
+	},
+	{
+		name: "dns-image",
+		desc: "Docker image for the dnsmasq container",
+		def:  docker.ImageDNS,
+		get:  func(uc *config.UserConfig) string { return uc.DNSImage },
+		set: func(uc *config.UserConfig, value string) (err error) {
+			uc.DNSImage, err = parseImageSetting(value)
+			return err
+		},
+		apply:       traefik.WriteCompose,
+		needsUpdate: true,
+	},
+	{
+		name: "daemon-health-port",
+		desc: "Loopback port the daemon serves GET /health on",
+		def:  strconv.Itoa(constants.PortDaemonHealth),
+		get:  func(uc *config.UserConfig) string { return formatIntSetting(uc.DaemonHealthPort) },
+		set: func(uc *config.UserConfig, value string) (err error) {
+			uc.DaemonHealthPort, err = parseIntSetting(value, constants.PortMin, constants.PortMax)
+			return err
+		},
+		apply: restartDaemonIfRunning,
+	},
+	{
+		name: "no-daemon",
+		desc: "Skip installing the daemon service during 'srv install'",
+		def:  "false",
+		get:  func(uc *config.UserConfig) string { return formatBoolSetting(uc.DisableDaemon) },
+		set: func(uc *config.UserConfig, value string) (err error) {
+			uc.DisableDaemon, err = parseBoolSetting(value)
+			return err
+		},
+	},
+	{
+		name: "park-depth",
+		desc: "How many directory levels below a parked path are scanned for projects",
+		def:  strconv.Itoa(constants.ParkDepth),
+		get:  func(uc *config.UserConfig) string { return formatIntSetting(uc.ParkDepth) },
+		set: func(uc *config.UserConfig, value string) (err error) {
+			uc.ParkDepth, err = parseIntSetting(value, 1, 5)
+			return err
+		},
+	},
+}
+
+// restartDaemonIfRunning restarts a running daemon so it picks up a changed
+// setting.
+func restartDaemonIfRunning() error {
+	if !daemon.IsRunning() {
+		return nil
+	}
+	return daemon.Restart()
+}
+
+// formatIntSetting renders an int setting, "" when unset.
+func formatIntSetting(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// parseIntSetting parses an int setting within [lo, hi]; an empty value
+// resets it to the default.
+func parseIntSetting(value string, lo, hi int) (int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < lo || n > hi {
+		return 0, fmt.Errorf("invalid value %q (expected a whole number from %d to %d)", value, lo, hi)
+	}
+	return n, nil
+}
+
+// formatBoolSetting renders a bool setting, "" when unset (false).
+func formatBoolSetting(b bool) string {
+	if !b {
+		return ""
+	}
+	return "true"
+}
+
+// parseBoolSetting parses a bool setting; an empty value resets it to false.
+func parseBoolSetting(value string) (bool, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value %q (expected true or false)", value)
+	}
+	return b, nil
+}
+
+// parseImageSetting checks an image reference such as traefik:v3.1; an
+// empty value resets it to the default.
+func parseImageSetting(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if strings.ContainsAny(value, " \t\n") || strings.HasPrefix(value, "-") || strings.HasSuffix(value, ":") {
+		return "", fmt.Errorf("invalid image %q (expected a reference such as traefik:v3.1)", value)
+	}
+	return value, nil
+}
+
+// findConfigKey returns the setting called name, or nil.
+func findConfigKey(name string) *configKey {
+	for i := range configKeys {
+		if configKeys[i].name == name {
+			return &configKeys[i]
+		}
+	}
+	return nil
+}
+
+// configKeyNames returns every setting name, for completion and errors.
+func configKeyNames() []string {
+	names := make([]string, len(configKeys))
+	for i, k := range configKeys {
+		names[i] = k.name
+	}
+	return names
+}
+
+// parseLocalTLDs splits a comma-separated TLD list, validating each entry as a
+// single DNS label. Built-in TLDs and duplicates are dropped; an empty value
+// clears the list.
+func parseLocalTLDs(value string) ([]string, error) {
+	var out []string
+	for _, tld := range strings.Split(value, ",") {
+		tld = strings.ToLower(strings.Trim(strings.TrimSpace(tld), "."))
+		if tld == "" {
+			continue
+		}
+		if strings.Contains(tld, ".") || validate.Domain(tld) != nil {
+			return nil, fmt.Errorf("invalid TLD %q (use a single label such as dev or internal)", tld)
+		}
+		if slices.Contains(traefik.LocalDomains, tld) || slices.Contains(out, tld) {
+			continue
+		}
+		out = append(out, tld)
+	}
+	return out, nil
+}
+
+// parseUpstreamDNS splits a comma-separated list of resolver IPs, validating
+// each; duplicates are dropped and an empty value restores the default.
+func parseUpstreamDNS(value string) ([]string, error) {
+	var out []string
+	for _, server := range strings.Split(value, ",") {
+		server = strings.TrimSpace(server)
+		if server == "" {
+			continue
+		}
+		ip := net.ParseIP(server)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid upstream DNS server %q (expected an IP address such as 1.1.1.1)", server)
+		}
+		if server = ip.String(); !slices.Contains(out, server) {
+			out = append(out, server)
+		}
+	}
+	return out, nil
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Read and change srv settings",
+	Long: `Read and change user settings stored in config.yml. Setting a key to ""
+restores its default.
+
+Keys:
+  local-tlds                 Extra TLDs treated as local (mkcert certs + dnsmasq), e.g. dev,internal
+  upstream-dns               Resolvers dnsmasq forwards other queries to (default 8.8.8.8,8.8.4.4)
+  max-workers                Sites handled in parallel by --all operations (default 4)
+  default-cert-days-warning  Days before expiry a local certificate counts as expiring (default 30)
+  traefik-image              Traefik image (default traefik:latest); run 'srv images update' after changing
+  dns-image                  dnsmasq image (default jpillora/dnsmasq:latest); run 'srv images update' after changing
+  daemon-health-port         Port of the daemon's /health endpoint (default 7777)
+  no-daemon                  true to never install the daemon service during 'srv install' (default false)
+  park-depth                 Directory levels 'srv park' scans for projects (default 1)`,
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show every setting and the value in effect",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigList,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get [KEY]",
+	Short: "Show one setting, or all of them",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runConfigGet,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return configKeyNames(), cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set KEY VALUE",
+	Short: "Change a setting",
+	Long: `Change a setting and re-apply anything derived from it.
+
+Examples:
+  srv config set local-tlds dev,internal
+  srv config set local-tlds ""            # back to the built-in TLDs only
+  srv config set upstream-dns 10.0.0.53,10.0.1.53
+  srv config set max-workers 8
+  srv config set traefik-image traefik:v3.1
+  srv config set no-daemon true`,
+	Args: cobra.ExactArgs(2),
+	RunE: runConfigSet,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return configKeyNames(), cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the resolved configuration and where each value comes from",
+	Long: `Show every value srv runs with, including the paths and network name it
+derives, and where each comes from: default (built in), env (SRV_ROOT or
+XDG_CONFIG_HOME) or file (config.yml). Useful when srv writes files somewhere
+unexpected.
+
+Examples:
+  srv config show
+  srv config show --json`,
+	Args: cobra.NoArgs,
+	RunE: runConfigShow,
+}
+
+func init() {
+	addJSONFlag(configShowCmd)
+	configCmd.GroupID = GroupSystem
+	configCmd.AddCommand(configGetCmd, configSetCmd, configListCmd, configShowCmd)
+	RootCmd.AddCommand(configCmd)
+}
+
+func runConfigGet(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return runConfigList(cmd, args)
+	}
+	key := findConfigKey(args[0])
+	if key == nil {
+		return fmt.Errorf("unknown setting %q (known: %s)", args[0], strings.Join(configKeyNames(), ", "))
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	uc, err := cfg.LoadUserConfig()
+	if err != nil {
+		return err
+	}
+	ui.Print("%s", cmp.Or(key.get(uc), key.def))
+	return nil
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) error {
+	key := findConfigKey(args[0])
+	if key == nil {
+		return fmt.Errorf("unknown setting %q (known: %s)", args[0], strings.Join(configKeyNames(), ", "))
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	uc, err := cfg.LoadUserConfig()
+	if err != nil {
+		return err
+	}
+	if err := key.set(uc, args[1]); err != nil {
+		return err
+	}
+	if err := cfg.SaveUserConfig(uc); err != nil {
+		return err
+	}
+	ui.Success("Set %s", key.name)
+
+	if key.apply != nil {
+		if err := key.apply(); err != nil {
+			ui.Warn("Saved, but applying the change failed: %v", err)
+		}
+	}
+	if key.needsUpdate {
+		ui.Warn("Run 'srv images update' to pull the image and recreate the containers")
+	}
+	return nil
+}
+
+// configListRow is the json shape for `srv config list --format json`.
+type configListRow struct {
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+	Default string `json:"default"`
+	Set     bool   `json:"set"`
+}
+
+func runConfigList(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	uc, err := cfg.LoadUserConfig()
+	if err != nil {
+		return err
+	}
+
+	out := make([]configListRow, 0, len(configKeys))
+	for _, key := range configKeys {
+		value := key.get(uc)
+		out = append(out, configListRow{Key: key.name, Value: cmp.Or(value, key.def), Default: key.def, Set: value != ""})
+	}
+	if jsonOutput() {
+		return ui.PrintJSON(out)
+	}
+	rows := make([][]string, 0, len(out))
+	for _, row := range out {
+		value := row.Value
+		switch {
+		case !row.Set && value == "":
+			value = ui.DimText("(none)")
+		case !row.Set:
+			value += " " + ui.DimText("(default)")
+		}
+		rows = append(rows, []string{row.Key, value})
+	}
+	ui.PrintTable([]string{"KEY", "VALUE"}, rows)
+	return nil
+}
+
+// Sources of a value in `srv config show`.
+const (
+	configSourceDefault = "default"
+	configSourceEnv     = "env"
+	configSourceFile    = "file"
+)
+
+// configShowRow is one resolved value of `srv config show`.
+type configShowRow struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// resolvedConfig lists the paths and network srv derives, then every
+// setting with the value in effect.
+func resolvedConfig(cfg *config.Config, uc *config.UserConfig) []configShowRow {
+	rootSource := configSourceDefault
+	if env := config.RootSource(); env != "" {
+		rootSource = configSourceEnv + " (" + env + ")"
+	}
+	out := []configShowRow{
+		{Key: "root", Value: cfg.Root, Source: rootSource},
+		{Key: "config-file", Value: cfg.ConfigPath(), Source: rootSource},
+		{Key: "traefik-dir", Value: cfg.TraefikDir, Source: rootSource},
+		{Key: "sites-dir", Value: cfg.SitesDir, Source: rootSource},
+		{Key: "network-name", Value: cfg.NetworkName, Source: configSourceDefault},
+	}
+	for _, key := range configKeys {
+		row := configShowRow{Key: key.name, Value: key.def, Source: configSourceDefault}
+		if value := key.get(uc); value != "" {
+			row.Value, row.Source = value, configSourceFile
+		}
+		out = append(out, row)
+	}
+	return out
+}
+
+func runConfigShow(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	uc, err := cfg.LoadUserConfig()
+	if err != nil {
+		return err
+	}
+	out := resolvedConfig(cfg, uc)
+	if jsonOutput() {
+		return ui.PrintJSON(out)
+	}
+	rows := make([][]string, 0, len(out))
+	for _, row := range out {
+		rows = append(rows, []string{row.Key, cmp.Or(row.Value, ui.DimText("(none)")), row.Source})
+	}
+	ui.PrintTable([]string{"KEY", "VALUE", "SOURCE"}, rows)
+	return nil
+}
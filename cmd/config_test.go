@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stubbedev/srv/internal/constants"
+)
+
+func TestParseLocalTLDs(t *testing.T) {
+	got, err := parseLocalTLDs(" dev, .Internal ,test,dev,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(got, ",") != "dev,internal" {
+		t.Errorf("parseLocalTLDs = %v, want [dev internal] (built-ins and dupes dropped)", got)
+	}
+	if got, _ := parseLocalTLDs(""); len(got) != 0 {
+		t.Errorf("empty value should clear, got %v", got)
+	}
+	for _, bad := range []string{"a.b", "has space", "bad/tld"} {
+		if _, err := parseLocalTLDs(bad); err == nil {
+			t.Errorf("parseLocalTLDs(%q) = nil error, want error", bad)
+		}
+	}
+}
+
+func TestRunConfigSetLocalTLDs(t *testing.T) {
+	setupSrvRoot(t)
+	if err := runConfigSet(nil, []string{"local-tlds", "dev,internal"}); err != nil {
+		t.Fatal(err)
+	}
+	uc, err := mustLoadConfig(t).LoadUserConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(uc.LocalTLDs, ",") != "dev,internal" {
+		t.Errorf("LocalTLDs = %v", uc.LocalTLDs)
+	}
+	if err := runConfigGet(nil, []string{"local-tlds"}); err != nil {
+		t.Errorf("get: %v", err)
+	}
+
+	if err := runConfigSet(nil, []string{"nope", "x"}); err == nil {
+		t.Error("expected error for unknown key")
+	}
+	if err := runConfigGet(nil, []string{"nope"}); err == nil {
+		t.Error("expected error for unknown key")
+	}
+}
+
+func TestParseUpstreamDNS(t *testing.T) {
+	got, err := parseUpstreamDNS(" 10.0.0.53, 2001:DB8::53 ,10.0.0.53,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(got, ",") != "10.0.0.53,2001:db8::53" {
+		t.Errorf("parseUpstreamDNS = %v, want [10.0.0.53 2001:db8::53] (dupes dropped)", got)
+	}
+	if got, _ := parseUpstreamDNS(""); len(got) != 0 {
+		t.Errorf("empty value should reset, got %v", got)
+	}
+	for _, bad := range []string{"dns.google", "10.0.0", "10.0.0.53:53"} {
+		if _, err := parseUpstreamDNS(bad); err == nil {
+			t.Errorf("parseUpstreamDNS(%q) = nil error, want error", bad)
+		}
+	}
+}
+
+func TestRunConfigSetUpstreamDNS(t *testing.T) {
+	setupSrvRoot(t)
+	if err := runConfigSet(nil, []string{"upstream-dns", "10.0.0.53,10.0.1.53"}); err != nil {
+		t.Fatal(err)
+	}
+	conf, err := os.ReadFile(filepath.Join(mustLoadConfig(t).TraefikDir, constants.DnsmasqConfFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(conf), "server=10.0.0.53\nserver=10.0.1.53\n") || strings.Contains(string(conf), constants.GoogleDNS1) {
+		t.Errorf("dnsmasq.conf does not forward to the configured servers:\n%s", conf)
+	}
+}
+
+func TestParseIntSetting(t *testing.T) {
+	if n, err := parseIntSetting(" 8 ", 1, 64); err != nil || n != 8 {
+		t.Errorf("parseIntSetting(8) = %d, %v", n, err)
+	}
+	if n, err := parseIntSetting("", 1, 64); err != nil || n != 0 {
+		t.Errorf("empty value should reset, got %d, %v", n, err)
+	}
+	for _, bad := range []string{"0", "65", "four", "-1"} {
+		if _, err := parseIntSetting(bad, 1, 64); err == nil {
+			t.Errorf("parseIntSetting(%q) = nil error, want error", bad)
+		}
+	}
+}
+
+func TestParseImageSetting(t *testing.T) {
+	for _, good := range []string{"traefik:v3.1", "ghcr.io/acme/dnsmasq@sha256:abc", ""} {
+		if _, err := parseImageSetting(good); err != nil {
+			t.Errorf("parseImageSetting(%q): %v", good, err)
+		}
+	}
+	for _, bad := range []string{"traefik v3", "-traefik", "traefik:"} {
+		if _, err := parseImageSetting(bad); err == nil {
+			t.Errorf("parseImageSetting(%q) = nil error, want error", bad)
+		}
+	}
+}
+
+func TestRunConfigSetImagesAndWorkers(t *testing.T) {
+	setupSrvRoot(t)
+	for _, kv := range [][]string{
+		{"max-workers", "8"},
+		{"default-cert-days-warning", "14"},
+		{"traefik-image", "traefik:v3.1"},
+		{"dns-image", "ghcr.io/acme/dnsmasq:2"},
+	} {
+		if err := runConfigSet(nil, kv); err != nil {
+			t.Fatalf("set %s: %v", kv[0], err)
+		}
+	}
+	uc, err := mustLoadConfig(t).LoadUserConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uc.MaxWorkers != 8 || uc.CertWarningDays != 14 || uc.TraefikImage != "traefik:v3.1" || uc.DNSImage != "ghcr.io/acme/dnsmasq:2" {
+		t.Errorf("user config = %+v", uc)
+	}
+	if got := maxWorkers(); got != 8 {
+		t.Errorf("maxWorkers() = %d, want 8", got)
+	}
+
+	if err := runConfigSet(nil, []string{"max-workers", "0"}); err == nil {
+		t.Error("expected error for max-workers 0")
+	}
+	if err := runConfigSet(nil, []string{"max-workers", ""}); err != nil {
+		t.Fatal(err)
+	}
+	if uc, _ := mustLoadConfig(t).LoadUserConfig(); uc.MaxWorkers != 0 {
+		t.Errorf("MaxWorkers = %d, want reset to 0", uc.MaxWorkers)
+	}
+	if err := runConfigList(nil, nil); err != nil {
+		t.Errorf("list: %v", err)
+	}
+}
+
+func TestRunConfigShow(t *testing.T) {
+	root := setupSrvRoot(t)
+	if err := runConfigSet(nil, []string{"max-workers", "8"}); err != nil {
+		t.Fatal(err)
+	}
+	var rows []configShowRow
+	runJSONCommand(t, &rows, "config", "show", "--json")
+	byKey := map[string]configShowRow{}
+	for _, row := range rows {
+		byKey[row.Key] = row
+	}
+	if got := byKey["root"]; got.Value != root || got.Source != "env (SRV_ROOT)" {
+		t.Errorf("root = %+v, want %s from SRV_ROOT", got, root)
+	}
+	if got := byKey["sites-dir"]; got.Value != filepath.Join(root, constants.SitesSubdir) {
+		t.Errorf("sites-dir = %+v", got)
+	}
+	if got := byKey["network-name"]; got.Value == "" {
+		t.Error("network-name is empty")
+	}
+	if got := byKey["max-workers"]; got.Value != "8" || got.Source != "file" {
+		t.Errorf("max-workers = %+v, want 8 from file", got)
+	}
+	if got := byKey["park-depth"]; got.Value != "1" || got.Source != "default" {
+		t.Errorf("park-depth = %+v, want default 1", got)
+	}
+}
@@ -2,10 +2,14 @@ package cmd
 
 import (
 	"bufio"
+	"cmp"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -25,7 +29,8 @@ var daemonCmd = &cobra.Command{
 	Use:   "daemon",
 	Short: "Manage the srv daemon",
 	Long: `The srv daemon watches for Docker container start events and automatically
-connects registered site containers to the srv network.
+connects registered site containers to the srv network, and disconnects them
+again when they stop so no stale endpoints are left behind.
 
 This ensures that containers are properly connected even when started
 outside of srv (e.g., via docker compose up directly).`,
@@ -41,8 +46,10 @@ func init() {
 // =============================================================================
 
 var daemonStartFlags struct {
-	foreground bool
-	noWatch    bool
+	foreground        bool
+	noWatch           bool
+	logFormat         string
+	certCheckInterval time.Duration
 }
 
 var daemonStartCmd = &cobra.Command{
@@ -53,17 +60,55 @@ var daemonStartCmd = &cobra.Command{
 The daemon watches Docker events and automatically connects containers
 from registered sites to the srv network when they start.
 
-Use --foreground to run in the foreground (useful for debugging).`,
+Use --foreground to run in the foreground (useful for debugging).
+
+--log-format json writes the log as one JSON object per line
+({"level","ts","msg",...}) for log shippers such as Loki or Datadog. For the
+service it is saved to daemon.yml and the daemon restarts to apply it; with
+--foreground it only applies to that run.
+
+The daemon also renews local certificates that expire within 30 days,
+checking once at startup and then every --cert-check-interval (24h by
+default). The interval is saved to daemon.yml the same way as --log-format.
+
+Examples:
+  srv daemon start
+  srv daemon start --log-format json
+  srv daemon start --cert-check-interval 6h
+  srv daemon start --foreground --no-watch`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if daemonStartFlags.logFormat != "" && !daemon.ValidLogFormat(daemonStartFlags.logFormat) {
+			return ui.UsageError("srv daemon start --log-format FORMAT", "invalid log format %q (expected %s)",
+				daemonStartFlags.logFormat, strings.Join(daemon.LogFormats, " or "))
+		}
+		if daemonStartFlags.certCheckInterval < daemon.MinCertCheckInterval {
+			return ui.UsageError("srv daemon start --cert-check-interval DURATION", "--cert-check-interval must be at least %s, got %s",
+				daemon.MinCertCheckInterval, daemonStartFlags.certCheckInterval)
+		}
+		return cobra.NoArgs(cmd, args)
+	},
 	RunE: runDaemonStart,
 }
 
 func init() {
 	daemonStartCmd.Flags().BoolVarP(&daemonStartFlags.foreground, "foreground", "f", false, "Run in foreground (don't daemonize)")
 	daemonStartCmd.Flags().BoolVar(&daemonStartFlags.noWatch, "no-watch", false, "Disable the metadata.yml file watcher (hot-reload)")
+	daemonStartCmd.Flags().StringVar(&daemonStartFlags.logFormat, "log-format", "", "Log format: text or json (saved to daemon.yml)")
+	daemonStartCmd.Flags().DurationVar(&daemonStartFlags.certCheckInterval, "cert-check-interval", daemon.DefaultCertCheckInterval, "How often to renew expiring local certificates (saved to daemon.yml)")
+	_ = daemonStartCmd.RegisterFlagCompletionFunc("log-format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return daemon.LogFormats, cobra.ShellCompDirectiveNoFileComp
+	})
 	daemonCmd.AddCommand(daemonStartCmd)
 }
 
 func runDaemonStart(cmd *cobra.Command, args []string) error {
+	// The interval only overrides daemon.yml when given explicitly; its
+	// default would otherwise replace a saved value on every start.
+	var certCheckInterval time.Duration
+	if cmd != nil && cmd.Flags().Changed("cert-check-interval") {
+		certCheckInterval = daemonStartFlags.certCheckInterval
+	}
+
 	if daemonStartFlags.foreground {
 		// The daemon is a service with no TTY, so it can never answer a sudo
 		// prompt. Force non-interactive sudo: the one privileged step reconcile
@@ -87,9 +132,20 @@ func runDaemonStart(cmd *cobra.Command, args []string) error {
 			return err
 		}
 		d.WatchMetadata = !daemonStartFlags.noWatch
+		if daemonStartFlags.logFormat != "" {
+			d.LogFormat = daemonStartFlags.logFormat
+		}
+		if certCheckInterval > 0 {
+			d.CertCheckInterval = certCheckInterval
+		}
 		return d.Run()
 	}
 
+	settingsChanged, err := saveDaemonStartSettings(daemonStartFlags.logFormat, certCheckInterval)
+	if err != nil {
+		return err
+	}
+
 	// For non-foreground, we require the service to be installed
 	if !daemon.IsInstalled() {
 		ui.Warn("Daemon service is not installed")
@@ -98,7 +154,15 @@ func runDaemonStart(cmd *cobra.Command, args []string) error {
 	}
 
 	if daemon.IsRunning() {
-		ui.Warn("Daemon is already running")
+		if !settingsChanged {
+			ui.Warn("Daemon is already running")
+			return nil
+		}
+		ui.Info("Restarting daemon to apply the new settings...")
+		if err := daemon.Restart(); err != nil {
+			return fmt.Errorf("failed to restart daemon: %w", err)
+		}
+		ui.Success("Daemon restarted")
 		return nil
 	}
 
@@ -111,6 +175,41 @@ func runDaemonStart(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// saveDaemonStartSettings records the log format and certificate check
+// interval in daemon.yml, reporting whether either changed. An empty format
+// or zero interval leaves that setting alone.
+func saveDaemonStartSettings(format string, certCheckInterval time.Duration) (bool, error) {
+	if format == "" && certCheckInterval == 0 {
+		return false, nil
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return false, err
+	}
+	settings, err := daemon.LoadSettings(cfg)
+	if err != nil {
+		return false, err
+	}
+	changed := false
+	if format != "" && cmp.Or(settings.LogFormat, daemon.LogFormatText) != format {
+		settings.LogFormat = format
+		changed = true
+		ui.Dim("Saving log format %s to %s", format, daemon.SettingsPath(cfg))
+	}
+	if certCheckInterval > 0 && cmp.Or(settings.CertCheckInterval, daemon.DefaultCertCheckInterval) != certCheckInterval {
+		settings.CertCheckInterval = certCheckInterval
+		changed = true
+		ui.Dim("Saving certificate check interval %s to %s", certCheckInterval, daemon.SettingsPath(cfg))
+	}
+	if !changed {
+		return false, nil
+	}
+	if err := daemon.SaveSettings(cfg, settings); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // =============================================================================
 // daemon stop command
 // =============================================================================
@@ -165,6 +264,11 @@ func runDaemonStatus(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if config.UserSettings().DisableDaemon {
+		ui.Print("Daemon: disabled (run 'srv config set no-daemon false' to re-enable)")
+		return nil
+	}
+
 	// Check if installed as service
 	if daemon.IsInstalled() {
 		status, err := daemon.ServiceStatus()
@@ -194,6 +298,55 @@ func runDaemonStatus(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// =============================================================================
+// daemon health command
+// =============================================================================
+
+// daemonHealthTimeout bounds the wait for the daemon's health dump.
+const daemonHealthTimeout = 3 * time.Second
+
+var daemonHealthCmd = &cobra.Command{
+	Use:   "health",
+	Short: "Check that the running daemon is responsive",
+	Long: `Check the running daemon: signal it (SIGUSR1) to write a health report
+to its log, then show the uptime, Docker events processed and the last event.
+
+The daemon also serves the same report as JSON at
+http://127.0.0.1:7777/health (port set by 'srv config set daemon-health-port').`,
+	Args: cobra.NoArgs,
+	RunE: runDaemonHealth,
+}
+
+func init() {
+	daemonCmd.AddCommand(daemonHealthCmd)
+}
+
+func runDaemonHealth(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	health, at, err := daemon.RequestHealth(cfg, daemonHealthTimeout)
+	if errors.Is(err, daemon.ErrNotRunning) {
+		return fmt.Errorf("%w (start it with 'srv daemon start')", err)
+	}
+	if err != nil {
+		return fmt.Errorf("daemon did not report its health: %w", err)
+	}
+	if jsonOutput() {
+		return ui.PrintJSON(health)
+	}
+
+	ui.Success("Daemon is %s", health.Status)
+	ui.Print("Uptime:     %s", health.Uptime)
+	ui.Print("Events:     %d processed", health.EventsProcessed)
+	ui.Print("Last event: %s", cmp.Or(health.LastEvent, ui.DimText("(none)")))
+	ui.Print("Reported:   %s", at.Format(time.DateTime))
+	ui.Print("Endpoint:   %s", daemon.HealthURL())
+	return nil
+}
+
 // =============================================================================
 // daemon logs command
 // =============================================================================
@@ -412,3 +565,221 @@ func runDaemonRestart(cmd *cobra.Command, args []string) error {
 	ui.Success("Daemon service restarted")
 	return nil
 }
+
+// =============================================================================
+// daemon config command
+// =============================================================================
+
+// daemonConfigKey describes one daemon.yml setting exposed through
+// `srv daemon config`.
+type daemonConfigKey struct {
+	name string
+	get  func(s *daemon.Settings) string
+	set  func(s *daemon.Settings, value string) error
+	// live marks settings the running daemon picks up without a restart.
+	live bool
+}
+
+// daemonConfigKeys lists the settings `srv daemon config` can read and write.
+var daemonConfigKeys = []daemonConfigKey{
+	{
+		name: "ignore-containers",
+		get:  func(s *daemon.Settings) string { return strings.Join(s.IgnoreContainers, ",") },
+		set: func(s *daemon.Settings, value string) (err error) {
+			s.IgnoreContainers, err = parseIgnoreContainers(value)
+			return err
+		},
+		live: true,
+	},
+	{
+		name: "log-format",
+		get:  func(s *daemon.Settings) string { return s.LogFormat },
+		set: func(s *daemon.Settings, value string) error {
+			if value != "" && !daemon.ValidLogFormat(value) {
+				return fmt.Errorf("invalid log format %q (expected %s)", value, strings.Join(daemon.LogFormats, " or "))
+			}
+			s.LogFormat = value
+			return nil
+		},
+	},
+	{
+		name: "connect-timeout",
+		get:  func(s *daemon.Settings) string { return formatDaemonTimeout(s.ConnectTimeout) },
+		set: func(s *daemon.Settings, value string) (err error) {
+			s.ConnectTimeout, err = parseDaemonTimeout(value)
+			return err
+		},
+	},
+	{
+		name: "response-timeout",
+		get:  func(s *daemon.Settings) string { return formatDaemonTimeout(s.ResponseTimeout) },
+		set: func(s *daemon.Settings, value string) (err error) {
+			s.ResponseTimeout, err = parseDaemonTimeout(value)
+			return err
+		},
+	},
+}
+
+// parseDaemonTimeout parses a positive duration such as 45s; "" is zero,
+// the default.
+func parseDaemonTimeout(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid timeout %q (expected a positive duration such as 45s or 2m)", value)
+	}
+	return d, nil
+}
+
+// formatDaemonTimeout renders a timeout setting, "" for the default.
+func formatDaemonTimeout(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return d.String()
+}
+
+var daemonConfigCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Read and change daemon settings",
+	Long: `Read and change the daemon's settings, stored in daemon.yml. Setting a key
+to "" restores its default.
+
+Keys:
+  ignore-containers  Comma-separated container names (globs allowed) the daemon
+                     never connects or disconnects, e.g. healthcheck-*,test-db;
+                     applied live
+  log-format         text (default) or json; applied on the next daemon restart
+  connect-timeout    How long to wait for the Docker daemon to answer (default
+                     10s, 30s over SSH); applied on the next daemon restart
+  response-timeout   How long Docker status and inspect calls may take (default
+                     30s); applied on the next daemon restart`,
+}
+
+var daemonConfigGetCmd = &cobra.Command{
+	Use:   "get [KEY]",
+	Short: "Show one daemon setting, or all of them",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runDaemonConfigGet,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return daemonConfigKeyNames(), cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+var daemonConfigSetCmd = &cobra.Command{
+	Use:   "set KEY VALUE",
+	Short: "Change a daemon setting",
+	Long: `Change a daemon setting. The running daemon watches daemon.yml, so
+ignore-containers takes effect without a restart.
+
+Examples:
+  srv daemon config set ignore-containers 'healthcheck-*,myapp-test-db'
+  srv daemon config set ignore-containers ""   # connect every site container again
+  srv daemon config set log-format json
+  srv daemon config set response-timeout 2m`,
+	Args: cobra.ExactArgs(2),
+	RunE: runDaemonConfigSet,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return daemonConfigKeyNames(), cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+func init() {
+	daemonConfigCmd.AddCommand(daemonConfigGetCmd, daemonConfigSetCmd)
+	daemonCmd.AddCommand(daemonConfigCmd)
+}
+
+// parseIgnoreContainers splits a comma-separated list of container name
+// patterns, rejecting malformed globs.
+func parseIgnoreContainers(value string) ([]string, error) {
+	var out []string
+	for _, pattern := range strings.Split(value, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" || slices.Contains(out, pattern) {
+			continue
+		}
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid container pattern %q: %w", pattern, err)
+		}
+		out = append(out, pattern)
+	}
+	return out, nil
+}
+
+// findDaemonConfigKey returns the daemon setting called name, or nil.
+func findDaemonConfigKey(name string) *daemonConfigKey {
+	for i := range daemonConfigKeys {
+		if daemonConfigKeys[i].name == name {
+			return &daemonConfigKeys[i]
+		}
+	}
+	return nil
+}
+
+// daemonConfigKeyNames returns every daemon setting name.
+func daemonConfigKeyNames() []string {
+	names := make([]string, len(daemonConfigKeys))
+	for i, key := range daemonConfigKeys {
+		names[i] = key.name
+	}
+	return names
+}
+
+func runDaemonConfigGet(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	settings, err := daemon.LoadSettings(cfg)
+	if err != nil {
+		return err
+	}
+	if len(args) == 1 {
+		key := findDaemonConfigKey(args[0])
+		if key == nil {
+			return fmt.Errorf("unknown daemon setting %q (known: %s)", args[0], strings.Join(daemonConfigKeyNames(), ", "))
+		}
+		ui.Print("%s", key.get(settings))
+		return nil
+	}
+	rows := make([][]string, 0, len(daemonConfigKeys))
+	for _, key := range daemonConfigKeys {
+		rows = append(rows, []string{key.name, cmp.Or(key.get(settings), ui.DimText("(default)"))})
+	}
+	ui.PrintTable([]string{"KEY", "VALUE"}, rows)
+	return nil
+}
+
+func runDaemonConfigSet(cmd *cobra.Command, args []string) error {
+	key := findDaemonConfigKey(args[0])
+	if key == nil {
+		return fmt.Errorf("unknown daemon setting %q (known: %s)", args[0], strings.Join(daemonConfigKeyNames(), ", "))
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	settings, err := daemon.LoadSettings(cfg)
+	if err != nil {
+		return err
+	}
+	if err := key.set(settings, args[1]); err != nil {
+		return err
+	}
+	if err := daemon.SaveSettings(cfg, settings); err != nil {
+		return err
+	}
+	ui.Success("Set %s", key.name)
+	if !key.live && daemon.IsRunning() {
+		ui.Warn("Run 'srv daemon restart' to apply it to the running daemon")
+	}
+	return nil
+}
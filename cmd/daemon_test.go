@@ -5,7 +5,9 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/stubbedev/srv/internal/daemon"
 	"github.com/stubbedev/srv/internal/shell"
 	"github.com/stubbedev/srv/internal/shell/shelltest"
 )
@@ -51,6 +53,46 @@ func TestRunDaemonStartNoServiceInstalled(t *testing.T) {
 	}
 }
 
+func TestRunDaemonStartSavesLogFormat(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	setupSrvRoot(t)
+	t.Cleanup(func() { daemonStartFlags.logFormat = "" })
+
+	executeRoot(t, "daemon", "start", "--log-format", "json")
+	settings, err := daemon.LoadSettings(mustLoadConfig(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.LogFormat != daemon.LogFormatJSON {
+		t.Errorf("daemon.yml log_format = %q, want json", settings.LogFormat)
+	}
+
+	daemonStartFlags.logFormat = "xml"
+	if err := daemonStartCmd.Args(daemonStartCmd, nil); err == nil {
+		t.Error("expected error for --log-format xml")
+	}
+}
+
+func TestRunDaemonStartSavesCertCheckInterval(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	setupSrvRoot(t)
+	t.Cleanup(func() { daemonStartFlags.certCheckInterval = daemon.DefaultCertCheckInterval })
+
+	executeRoot(t, "daemon", "start", "--cert-check-interval", "6h")
+	settings, err := daemon.LoadSettings(mustLoadConfig(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.CertCheckInterval != 6*time.Hour {
+		t.Errorf("daemon.yml cert_check_interval = %s, want 6h", settings.CertCheckInterval)
+	}
+
+	daemonStartFlags.certCheckInterval = time.Second
+	if err := daemonStartCmd.Args(daemonStartCmd, nil); err == nil {
+		t.Error("expected error for --cert-check-interval 1s")
+	}
+}
+
 func TestRunDaemonStopNotInstalled(t *testing.T) {
 	home := t.TempDir()
 	t.Setenv("HOME", home)
@@ -206,3 +248,72 @@ func TestRunDaemonStartForegroundLogErr(t *testing.T) {
 		t.Error("expected err opening log dir as file")
 	}
 }
+
+func TestRunDaemonHealthNotRunning(t *testing.T) {
+	setupSrvRoot(t)
+	err := runDaemonHealth(nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "not running") {
+		t.Errorf("err = %v, want not running", err)
+	}
+}
+
+func TestRunDaemonConfigSet(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	setupSrvRoot(t)
+
+	executeRoot(t, "daemon", "config", "set", "ignore-containers", "healthcheck-*, test-db,test-db")
+	settings, err := daemon.LoadSettings(mustLoadConfig(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(settings.IgnoreContainers, ",") != "healthcheck-*,test-db" {
+		t.Errorf("ignore_containers = %v", settings.IgnoreContainers)
+	}
+	stdout, _ := executeRoot(t, "daemon", "config", "get", "ignore-containers")
+	if strings.TrimSpace(stdout) != "healthcheck-*,test-db" {
+		t.Errorf("get = %q", stdout)
+	}
+
+	if err := runDaemonConfigSet(nil, []string{"ignore-containers", "web-["}); err == nil {
+		t.Error("expected error for a malformed glob")
+	}
+	if err := runDaemonConfigSet(nil, []string{"log-format", "xml"}); err == nil {
+		t.Error("expected error for an unknown log format")
+	}
+	executeRoot(t, "daemon", "config", "set", "response-timeout", "90s")
+	if settings, _ = daemon.LoadSettings(mustLoadConfig(t)); settings.ResponseTimeout != 90*time.Second {
+		t.Errorf("response_timeout = %v", settings.ResponseTimeout)
+	}
+	if err := runDaemonConfigSet(nil, []string{"connect-timeout", "-5s"}); err == nil {
+		t.Error("expected error for a negative timeout")
+	}
+	if err := runDaemonConfigSet(nil, []string{"ignore-containers", ""}); err != nil {
+		t.Fatal(err)
+	}
+	if settings, _ = daemon.LoadSettings(mustLoadConfig(t)); len(settings.IgnoreContainers) != 0 {
+		t.Errorf("ignore_containers not cleared: %v", settings.IgnoreContainers)
+	}
+}
+
+func TestRunDaemonStatusDisabled(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	setupSrvRoot(t)
+	if err := runConfigSet(nil, []string{"no-daemon", "true"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := runConfigSet(nil, []string{"no-daemon", "maybe"}); err == nil {
+		t.Error("expected error for a non-bool no-daemon value")
+	}
+
+	stdout, _ := executeRoot(t, "daemon", "status")
+	if !strings.Contains(stdout, "disabled (run 'srv config set no-daemon false' to re-enable)") {
+		t.Errorf("status output:\n%s", stdout)
+	}
+
+	if err := runConfigSet(nil, []string{"no-daemon", "false"}); err != nil {
+		t.Fatal(err)
+	}
+	if stdout, _ = executeRoot(t, "daemon", "status"); strings.Contains(stdout, "disabled") {
+		t.Errorf("status still disabled:\n%s", stdout)
+	}
+}
@@ -0,0 +1,101 @@
+// Package cmd — dependency.go implements `srv dependency`, which records that
+// a site needs other sites running first (an API and its database site).
+// Batch starts go in dependency order and `srv stop --all` in reverse.
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/stubbedev/srv/internal/site"
+	"github.com/stubbedev/srv/internal/ui"
+)
+
+var dependencyFlags struct {
+	dependsOn []string
+}
+
+var dependencyCmd = &cobra.Command{
+	Use:     "dependency",
+	Aliases: []string{"dep"},
+	Short:   "Order site starts by their dependencies",
+	Long: `Record that a site depends on other sites, e.g. an API on its database.
+'srv start --all' and 'srv start --tag' start dependencies first and wait for
+them to run; 'srv start SITE' starts the site's stopped dependencies first;
+'srv stop --all' stops dependents first. Dependency cycles are refused.`,
+}
+
+var dependencyAddCmd = &cobra.Command{
+	Use:   "add SITE --depends-on OTHER_SITE",
+	Short: "Make a site depend on other sites",
+	Long: `Make SITE depend on one or more other sites, so they start before it.
+
+Examples:
+  srv dependency add api --depends-on db
+  srv dependency add web --depends-on api --depends-on cache`,
+	Args:              dependencyArgs("srv dependency add SITE --depends-on OTHER_SITE"),
+	RunE:              runDependencyAdd,
+	ValidArgsFunction: completeTagSite,
+}
+
+var dependencyRemoveCmd = &cobra.Command{
+	Use:     "remove SITE --depends-on OTHER_SITE",
+	Aliases: []string{"rm"},
+	Short:   "Drop dependencies from a site",
+	Long: `Drop one or more dependencies from SITE.
+
+Examples:
+  srv dependency remove api --depends-on db`,
+	Args:              dependencyArgs("srv dependency remove SITE --depends-on OTHER_SITE"),
+	RunE:              runDependencyRemove,
+	ValidArgsFunction: completeTagSite,
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{dependencyAddCmd, dependencyRemoveCmd} {
+		cmd.Flags().StringSliceVar(&dependencyFlags.dependsOn, "depends-on", nil, "Site the site depends on (repeatable)")
+		_ = cmd.RegisterFlagCompletionFunc("depends-on", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return GetSiteNames(), cobra.ShellCompDirectiveNoFileComp
+		})
+	}
+	dependencyCmd.GroupID = GroupSites
+	dependencyCmd.AddCommand(dependencyAddCmd, dependencyRemoveCmd)
+	RootCmd.AddCommand(dependencyCmd)
+}
+
+// dependencyArgs expects one site name and at least one --depends-on.
+func dependencyArgs(usage string) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return ui.UsageError(usage, "expected a single site name, got %d argument(s)", len(args))
+		}
+		if len(dependencyFlags.dependsOn) == 0 {
+			return ui.UsageError(usage, "--depends-on is required")
+		}
+		return nil
+	}
+}
+
+func runDependencyAdd(cmd *cobra.Command, args []string) error {
+	for _, dep := range dependencyFlags.dependsOn {
+		changed, err := site.AddDependency(args[0], dep)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			ui.Dim("Site '%s' already depends on '%s'", args[0], dep)
+			continue
+		}
+		ui.Success("Site '%s' now depends on '%s'", args[0], dep)
+	}
+	return nil
+}
+
+func runDependencyRemove(cmd *cobra.Command, args []string) error {
+	for _, dep := range dependencyFlags.dependsOn {
+		if err := site.RemoveDependency(args[0], dep); err != nil {
+			return err
+		}
+		ui.Success("Site '%s' no longer depends on '%s'", args[0], dep)
+	}
+	return nil
+}
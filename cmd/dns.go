@@ -0,0 +1,174 @@
+// Package cmd — dns.go implements `srv dns`, tools for debugging how local
+// domains resolve. `srv dns lookup` resolves a name through srv's DNS, the
+// system resolver and a public resolver side by side; `srv dns flush` clears
+// dnsmasq's and the system's caches.
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stubbedev/srv/internal/docker"
+	"github.com/stubbedev/srv/internal/traefik"
+	"github.com/stubbedev/srv/internal/ui"
+)
+
+var dnsCmd = &cobra.Command{
+	Use:   "dns",
+	Short: "Debug local domain resolution",
+}
+
+var dnsLookupCmd = &cobra.Command{
+	Use:   "lookup DOMAIN",
+	Short: "Resolve a domain through srv's DNS, the system resolver and public DNS",
+	Long: `Resolve DOMAIN three ways and compare the answers:
+
+  local   srv's DNS server, queried directly
+  system  the system resolver, which browsers and apps use
+  public  Google DNS (8.8.8.8), for comparison
+
+Each path shows its addresses, TTL and latency. When srv's DNS answers but
+the system resolver doesn't, or the system resolver returns a different
+address, the likely cause and a fix are printed.
+
+Examples:
+  srv dns lookup myapp.test
+  srv dns lookup myapp.test --json`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return ui.UsageError("srv dns lookup DOMAIN", "expected a single domain, got %d argument(s)", len(args))
+		}
+		return nil
+	},
+	RunE: runDNSLookup,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		domains, _ := traefik.LoadLocalDomains()
+		for i, d := range domains {
+			domains[i] = traefik.BareDomain(d)
+		}
+		return domains, cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+var dnsFlushCmd = &cobra.Command{
+	Use:   "flush",
+	Short: "Restart srv's DNS server and flush the system DNS cache",
+	Long: `Recreate the dnsmasq container, which empties its cache, and flush the
+system resolver's cache (systemd-resolved, mDNSResponder on macOS, and nscd
+when installed). Then check that a registered local domain resolves.
+
+Examples:
+  srv dns flush`,
+	Args: cobra.NoArgs,
+	RunE: runDNSFlush,
+}
+
+func init() {
+	addJSONFlag(dnsLookupCmd)
+	dnsCmd.GroupID = GroupSystem
+	dnsCmd.AddCommand(dnsLookupCmd, dnsFlushCmd)
+	RootCmd.AddCommand(dnsCmd)
+}
+
+// lookupDomain resolves a domain through every path. Tests swap it.
+var lookupDomain = traefik.LookupDomain
+
+func runDNSLookup(cmd *cobra.Command, args []string) error {
+	domain := strings.ToLower(strings.TrimSuffix(args[0], "."))
+	results := lookupDomain(domain)
+	diagnosis := traefik.DiagnoseLookup(results)
+
+	if jsonOutput() {
+		return ui.PrintJSON(struct {
+			Domain    string                 `json:"domain"`
+			Results   []traefik.LookupResult `json:"results"`
+			Diagnosis string                 `json:"diagnosis,omitempty"`
+		}{domain, results, diagnosis})
+	}
+
+	rows := make([][]string, 0, len(results))
+	for _, r := range results {
+		answer := ui.ErrorText(r.Error)
+		if r.OK() {
+			answer = strings.Join(r.Addrs, ", ")
+		}
+		ttl := ui.DimText("-")
+		if r.TTL >= 0 {
+			ttl = strconv.Itoa(r.TTL) + "s"
+		}
+		rows = append(rows, []string{r.Path, r.Server, answer, ttl, fmt.Sprintf("%dms", r.LatencyMS)})
+	}
+	ui.PrintTable([]string{"PATH", "SERVER", "RESULT", "TTL", "LATENCY"}, rows)
+
+	switch {
+	case diagnosis == "":
+	case strings.HasPrefix(diagnosis, traefik.DiagnosisNoSystemDNS):
+		ui.Blank()
+		ui.Warn("%s", diagnosis)
+		ui.Dim("Run 'srv doctor --fix' (or 'srv install') to point the system resolver at srv's DNS")
+		if strings.HasSuffix(domain, ".local") {
+			ui.Dim(".local is reserved for mDNS and may never reach srv's DNS; a .test domain avoids this")
+		}
+	default:
+		ui.Blank()
+		ui.Warn("%s", diagnosis)
+		ui.Dim("Check /etc/hosts and any VPN or network DNS settings that override %s", domain)
+	}
+	return nil
+}
+
+func runDNSFlush(cmd *cobra.Command, args []string) error {
+	if err := docker.EnsureRunning(); err != nil {
+		return err
+	}
+	ui.Dim("Restarting the DNS container...")
+	if err := traefik.ReloadDNS(); err != nil {
+		return fmt.Errorf("failed to restart the DNS container: %w", err)
+	}
+	ui.Success("Flushed the dnsmasq cache")
+
+	resolver := traefik.GetResolverName()
+	flushed, err := traefik.FlushAllDNSCaches()
+	if err != nil {
+		ui.Warn("%v", err)
+	}
+	if len(flushed) > 0 {
+		ui.Success("Flushed the system DNS cache: %s (resolver: %s)", strings.Join(flushed, ", "), resolver)
+	} else {
+		ui.Dim("No system DNS cache to flush (resolver: %s)", resolver)
+	}
+
+	domains, _ := traefik.LoadLocalDomains()
+	if len(domains) == 0 {
+		ui.Dim("No local domains registered; skipping the resolution check")
+		return nil
+	}
+	domain := traefik.BareDomain(domains[0])
+	// dnsmasq needs a moment to bind its port after the recreate.
+	for attempt := 0; ; attempt++ {
+		if traefik.CheckDNS(domain) {
+			ui.Success("%s resolves through srv's DNS", domain)
+			return nil
+		}
+		if attempt == dnsFlushCheckAttempts {
+			break
+		}
+		time.Sleep(dnsFlushCheckInterval)
+	}
+	ui.Warn("%s does not resolve through srv's DNS", domain)
+	ui.Dim("Run 'srv dns lookup %s' to see where resolution fails", domain)
+	return nil
+}
+
+// Retry schedule for the resolution check after `srv dns flush`.
+const (
+	dnsFlushCheckAttempts = 5
+	dnsFlushCheckInterval = 500 * time.Millisecond
+)
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stubbedev/srv/internal/docker"
+	"github.com/stubbedev/srv/internal/traefik"
+)
+
+func TestDNSLookup(t *testing.T) {
+	setupSrvRoot(t)
+	orig := lookupDomain
+	t.Cleanup(func() { lookupDomain = orig })
+	lookupDomain = func(domain string) []traefik.LookupResult {
+		return []traefik.LookupResult{
+			{Path: traefik.LookupPathLocal, Server: "127.0.0.1:53", Addrs: []string{"127.0.0.1"}, TTL: 0, LatencyMS: 1},
+			{Path: traefik.LookupPathSystem, Server: "system resolver", TTL: -1, LatencyMS: 3, Error: "no such host"},
+			{Path: traefik.LookupPathPublic, Server: "8.8.8.8:53", TTL: -1, LatencyMS: 20, Error: "no such host"},
+		}
+	}
+
+	stdout, stderr := executeRoot(t, "dns", "lookup", "App.Test.")
+	for _, want := range []string{"local", "127.0.0.1", "0s", "no such host", "20ms"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("output missing %q:\n%s", want, stdout)
+		}
+	}
+	if !strings.Contains(stderr, traefik.DiagnosisNoSystemDNS) || !strings.Contains(stderr, "srv doctor --fix") {
+		t.Errorf("missing diagnosis and remediation:\n%s", stderr)
+	}
+
+	var out struct {
+		Domain    string                 `json:"domain"`
+		Results   []traefik.LookupResult `json:"results"`
+		Diagnosis string                 `json:"diagnosis"`
+	}
+	runJSONCommand(t, &out, "dns", "lookup", "app.test", "--json")
+	if out.Domain != "app.test" || len(out.Results) != 3 || !strings.HasPrefix(out.Diagnosis, traefik.DiagnosisNoSystemDNS) {
+		t.Errorf("json = %+v", out)
+	}
+}
+
+func TestDNSFlush(t *testing.T) {
+	setupSrvRoot(t)
+	t.Cleanup(docker.SwapNewClientOK())
+	var composeArgs [][]string
+	t.Cleanup(docker.SwapComposeExec(func(dir string, quiet bool, args ...string) error {
+		composeArgs = append(composeArgs, args)
+		return nil
+	}))
+
+	_, stderr := executeRoot(t, "dns", "flush")
+	if !slices.ContainsFunc(composeArgs, func(args []string) bool {
+		return slices.Contains(args, "--force-recreate") && slices.Contains(args, "dns")
+	}) {
+		t.Errorf("DNS container not recreated; compose calls: %v", composeArgs)
+	}
+	if !strings.Contains(stderr, "Flushed the dnsmasq cache") || !strings.Contains(stderr, "resolver: ") {
+		t.Errorf("flush output:\n%s", stderr)
+	}
+	if !strings.Contains(stderr, "skipping the resolution check") {
+		t.Errorf("expected the resolution check to be skipped without local domains:\n%s", stderr)
+	}
+}
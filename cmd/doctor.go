@@ -6,7 +6,9 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -27,6 +29,9 @@ import (
 
 var doctorFlags struct {
 	fixPerms bool
+	fix      bool
+	check    string
+	timeout  time.Duration
 }
 
 var doctorCmd = &cobra.Command{
@@ -43,94 +48,198 @@ Checks performed:
   - mkcert installation
   - Site metadata validity
   - .env host-loopback references in container-backed sites
-  - Ownership of ~/.config/srv (use --fix-perms to repair)`,
+  - Ownership of ~/.config/srv (use --fix-perms to repair)
+
+With --fix, failing checks are repaired where srv knows how: a missing
+Docker network is created, system DNS is configured, a stopped Traefik is
+recreated and expired local certificates are regenerated.
+
+--check NAME runs a single check and exits non-zero when it finds issues,
+for scripts. Names: docker, firewall, ports, network, traefik, dns, certs,
+metrics, sites, env, perms, connectivity.
+
+The connectivity check only runs when asked for: it requests
+https://DOMAIN for every running site (up to --timeout each) and flags
+sites that answer 5xx or not at all.
+
+--format json prints every check's status (ok, warning or error) and its
+issues, each with a severity, message and suggestion, for CI health gates.
+srv doctor exits non-zero when any check reports an error.
+
+Examples:
+  srv doctor
+  srv doctor --fix
+  srv doctor --format json
+  srv doctor --check traefik
+  srv doctor --check connectivity --timeout 10s`,
 	RunE: runDoctor,
 }
 
+// doctorCheck is one named section of `srv doctor`. explicit checks only run
+// when named with --check.
+type doctorCheck struct {
+	name     string
+	run      func() CheckResult
+	explicit bool
+}
+
+// doctorChecks lists the checks in the order they run.
+var doctorChecks = []doctorCheck{
+	{"docker", checkDocker, false},
+	{"firewall", checkFirewall, false},
+	{"ports", checkPorts, false},
+	{"network", checkNetwork, false},
+	{"traefik", checkTraefik, false},
+	{"dns", checkDNS, false},
+	{"certs", checkCertificates, false},
+	{"metrics", checkMetrics, false},
+	{"sites", checkSitesValid, false},
+	{"env", checkSiteEnvHostLoopback, false},
+	{"perms", func() CheckResult { return checkConfigDirOwnership(doctorFlags.fixPerms) }, false},
+	{"connectivity", checkSiteConnectivity, true},
+}
+
+// doctorCheckNames returns every check name, for --check completion and errors.
+func doctorCheckNames() []string {
+	names := make([]string, len(doctorChecks))
+	for i, c := range doctorChecks {
+		names[i] = c.name
+	}
+	return names
+}
+
+// Remediations applied by `srv doctor --fix`. Tests swap them.
+var (
+	fixCreateNetwork   = docker.CreateNetwork
+	fixSetupDNS        = traefik.SetupDNS
+	fixRecreateTraefik = traefik.RecreateTraefik
+	fixGenerateCert    = traefik.GenerateLocalCert
+)
+
 func init() {
 	doctorCmd.Flags().BoolVar(&doctorFlags.fixPerms, "fix-perms", false, "Interactively sudo chown ~/.config/srv back to the current user when files are root-owned")
+	doctorCmd.Flags().BoolVar(&doctorFlags.fix, "fix", false, "Try to repair failing checks (network, DNS, Traefik, expired certificates)")
+	doctorCmd.Flags().StringVar(&doctorFlags.check, "check", "", "Run only this check (docker, ports, network, traefik, dns, certs, connectivity, ...)")
+	doctorCmd.Flags().DurationVar(&doctorFlags.timeout, "timeout", defaultConnectivityTimeout, "Per-request timeout for the connectivity check")
+	_ = doctorCmd.RegisterFlagCompletionFunc("check", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return doctorCheckNames(), cobra.ShellCompDirectiveNoFileComp
+	})
+	addJSONFlag(doctorCmd)
 	doctorCmd.GroupID = GroupSystem
 	RootCmd.AddCommand(doctorCmd)
 }
 
 func runDoctor(cmd *cobra.Command, args []string) error {
-	ui.Blank()
-	ui.Info("Running diagnostics...")
-	ui.Blank()
-
-	issues := 0
-	issues += checkDocker()
-	issues += checkFirewall()
-	issues += checkPorts()
-	issues += checkNetwork()
-	issues += checkTraefik()
-	issues += checkDNS()
-	issues += checkCertificates()
-	issues += checkMetrics()
-	issues += checkSitesValid()
-	issues += checkSiteEnvHostLoopback()
-	issues += checkConfigDirOwnership(doctorFlags.fixPerms)
-
-	// Summary
-	ui.Blank()
-	if issues == 0 {
-		ui.Success("All checks passed!")
+	checks := doctorChecks
+	if doctorFlags.check != "" {
+		i := slices.IndexFunc(doctorChecks, func(c doctorCheck) bool { return c.name == doctorFlags.check })
+		if i < 0 {
+			return ui.UsageError("srv doctor --check NAME", "unknown check %q (available: %s)", doctorFlags.check, strings.Join(doctorCheckNames(), ", "))
+		}
+		checks = doctorChecks[i : i+1]
+	}
+
+	if !jsonOutput() {
+		ui.Blank()
+		ui.Info("Running diagnostics...")
+		ui.Blank()
+	}
+
+	results := make([]CheckResult, 0, len(checks))
+	issues, failed := 0, 0
+	for _, c := range checks {
+		if c.explicit && doctorFlags.check == "" {
+			continue
+		}
+		res := c.run()
+		res.Name = c.name
+		results = append(results, res)
+		issues += len(res.Issues)
+		if res.Status == checkStatusError {
+			failed++
+		}
+	}
+
+	if jsonOutput() {
+		if err := ui.PrintJSON(results); err != nil {
+			return err
+		}
 	} else {
-		ui.Warn("%d issue(s) found", issues)
+		ui.Blank()
+		if issues == 0 {
+			ui.Success("All checks passed!")
+		} else {
+			ui.Warn("%d issue(s) found", issues)
+		}
+		ui.Blank()
 	}
-	ui.Blank()
 
+	// A single check is meant for scripts, so any issue fails it; a full run
+	// fails when a check found errors.
+	if doctorFlags.check != "" && issues > 0 {
+		return fmt.Errorf("%s check found %d issue(s)", doctorFlags.check, issues)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
 	return nil
 }
 
 // checkDocker verifies Docker is running
-func checkDocker() int {
-	ui.Bold("Docker")
+func checkDocker() CheckResult {
+	r := newCheckReport("Docker")
+	if name := docker.ActiveContext(); name != "" {
+		host, err := docker.ContextHost()
+		if err != nil {
+			r.suggest("Fix the context with 'docker context inspect "+name+"' or switch back with 'docker context use default'",
+				r.fail("Docker context %s: %v", name, err))
+			return r.done()
+		}
+		r.note(1, "Context: %s (%s)", name, host)
+	} else {
+		r.note(1, "Context: default")
+	}
+	if rt := docker.ActiveRuntime(); rt != docker.RuntimeDocker {
+		r.note(1, "Runtime: %s (compose: %s)", rt, strings.Join(docker.ComposeCmd(), " "))
+	}
 	if err := docker.EnsureRunning(); err != nil {
-		ui.IndentedError(1, "Docker is not running or not installed")
-		ui.Blank()
-		return 1
+		r.fail("Docker is not running or not installed").Suggestion = "Start Docker, or install it, and re-run 'srv doctor'"
+		return r.done()
 	}
-	ui.IndentedSuccess(1, "Docker is running")
-	ui.Blank()
-	return 0
+	r.ok("Docker is running")
+	return r.done()
 }
 
 // checkFirewall checks firewall status and port accessibility
-func checkFirewall() int {
-	issues := 0
-	ui.Bold("Firewall")
+func checkFirewall() CheckResult {
+	r := newCheckReport("Firewall")
 	fwStatus := firewall.CheckPorts()
 
 	if fwStatus.Firewall == firewall.FirewallNone {
-		ui.IndentedDim(1, "No active firewall detected")
+		r.note(1, "No active firewall detected")
+		return r.done()
+	}
+	r.note(1, "Firewall: %s", fwStatus.Firewall)
+	var blocked []*Issue
+	if fwStatus.HTTPOpen {
+		r.ok("Port 80 (HTTP) - open")
 	} else {
-		ui.IndentedDim(1, "Firewall: %s", fwStatus.Firewall)
-		if fwStatus.HTTPOpen {
-			ui.IndentedSuccess(1, "Port 80 (HTTP) - open")
-		} else {
-			ui.IndentedWarn(1, "Port 80 (HTTP) - blocked")
-			issues++
-		}
-		if fwStatus.HTTPSOpen {
-			ui.IndentedSuccess(1, "Port 443 (HTTPS) - open")
-		} else {
-			ui.IndentedWarn(1, "Port 443 (HTTPS) - blocked")
-			issues++
-		}
-		if !fwStatus.HTTPOpen || !fwStatus.HTTPSOpen {
-			ui.IndentedDim(1, "Run 'srv install' to configure firewall")
-		}
+		blocked = append(blocked, r.warn("Port 80 (HTTP) - blocked"))
 	}
-
-	ui.Blank()
-	return issues
+	if fwStatus.HTTPSOpen {
+		r.ok("Port 443 (HTTPS) - open")
+	} else {
+		blocked = append(blocked, r.warn("Port 443 (HTTPS) - blocked"))
+	}
+	if len(blocked) > 0 {
+		r.suggest("Run 'srv install' to configure firewall", blocked...)
+	}
+	return r.done()
 }
 
 // checkPorts verifies required ports are available or in use by srv
-func checkPorts() int {
-	issues := 0
-	ui.Bold("Ports")
+func checkPorts() CheckResult {
+	r := newCheckReport("Ports")
 
 	type portInfo struct {
 		port      int
@@ -148,108 +257,110 @@ func checkPorts() int {
 
 	for _, p := range ports {
 		if traefik.CheckPortAvailable(p.port) {
-			ui.IndentedDim(1, ":%d (%s) - available", p.port, p.name)
+			r.note(1, ":%d (%s) - available", p.port, p.name)
 			continue
 		}
 
 		if p.ownedByFn() {
 			version := docker.GetContainerImageVersion(p.container)
-			ui.IndentedSuccess(1, ":%d (%s) - in use by srv [%s:%s]", p.port, p.name, p.container, version)
+			r.ok(":%d (%s) - in use by srv [%s:%s]", p.port, p.name, p.container, version)
 			continue
 		}
 
 		// Port is occupied by a foreign process — identify it.
 		conflict := traefik.PortConflict{Port: p.port, Name: p.name, Process: shell.IdentifyPortProcess(fmt.Sprintf("%d", p.port))}
+		var issue *Issue
 		if conflict.Process != "" {
-			ui.IndentedWarn(1, ":%d (%s) - in use by %s", p.port, p.name, conflict.Process)
+			issue = r.warn(":%d (%s) - in use by %s", p.port, p.name, conflict.Process)
 		} else {
-			ui.IndentedWarn(1, ":%d (%s) - in use by an unknown process", p.port, p.name)
+			issue = r.warn(":%d (%s) - in use by an unknown process", p.port, p.name)
 		}
-		ui.IndentedDim(2, "stop it with: %s", conflict.StopHint())
-		issues++
+		issue.Suggestion = "Stop it with: " + conflict.StopHint()
+		r.note(2, "stop it with: %s", conflict.StopHint())
 	}
 
-	ui.Blank()
-	return issues
+	return r.done()
 }
 
 // checkNetwork verifies Docker network exists
-func checkNetwork() int {
-	ui.Bold("Docker Network")
+func checkNetwork() CheckResult {
+	r := newCheckReport("Docker Network")
 	cfg, err := config.Load()
 	if err != nil {
-		ui.IndentedError(1, "Failed to load config: %v", err)
-		ui.Blank()
-		return 1
+		r.fail("Failed to load config: %v", err)
+		return r.done()
 	}
 
 	if docker.NetworkExists(cfg.NetworkName) {
-		ui.IndentedSuccess(1, "Network '%s' exists", cfg.NetworkName)
-	} else {
-		ui.IndentedWarn(1, "Network '%s' does not exist", cfg.NetworkName)
-		ui.IndentedDim(1, "Run 'srv install' to create it")
-		ui.Blank()
-		return 1
+		r.ok("Network '%s' exists", cfg.NetworkName)
+		return r.done()
+	}
+	issue := r.fail("Network '%s' does not exist", cfg.NetworkName)
+	if doctorFlags.fix {
+		if r.applyFix("created network '"+cfg.NetworkName+"'", func() error {
+			return fixCreateNetwork(cfg.NetworkName)
+		}) {
+			r.resolve(issue)
+		}
+		return r.done()
 	}
-
-	ui.Blank()
-	return 0
+	r.suggest("Run 'srv install' or 'srv doctor --fix' to create it", issue)
+	return r.done()
 }
 
 // checkTraefik verifies Traefik container is running
-func checkTraefik() int {
-	ui.Bold("Traefik")
+func checkTraefik() CheckResult {
+	r := newCheckReport("Traefik")
 	if traefik.IsRunning() {
-		ui.IndentedSuccess(1, "Container is running")
-		ui.Blank()
-		return 0
+		r.ok("Container is running")
+		return r.done()
 	}
 
-	ui.IndentedWarn(1, "Container is not running")
-	ui.IndentedDim(1, "Run 'srv install' to start")
-	ui.Blank()
-	return 1
+	issue := r.fail("Container is not running")
+	if doctorFlags.fix {
+		if r.applyFix("recreated the Traefik container", fixRecreateTraefik) {
+			r.resolve(issue)
+		}
+		return r.done()
+	}
+	r.suggest("Run 'srv install' or 'srv doctor --fix' to start", issue)
+	return r.done()
 }
 
 // checkDNS verifies DNS server status and configuration
-func checkDNS() int {
-	issues := 0
-	ui.Bold("DNS Server")
+func checkDNS() CheckResult {
+	r := newCheckReport("DNS Server")
 
 	// Check if there are any local domains registered
 	localDomains, _ := traefik.LoadLocalDomains()
 	hasLocalDomains := len(localDomains) > 0
 
 	if traefik.IsDNSRunning() {
-		ui.IndentedSuccess(1, "Container is running")
+		r.ok("Container is running")
 
 		// Only check DNS resolution if there are local domains to test against
 		if hasLocalDomains {
 			testDomain := localDomains[0]
 			if traefik.CheckDNS(testDomain) {
-				ui.IndentedSuccess(1, "Responding to queries")
+				r.ok("Responding to queries")
 			} else {
-				ui.IndentedWarn(1, "Not responding to queries")
-				issues++
+				r.warn("Not responding to queries").Suggestion = "Run 'srv dns flush' to restart srv's DNS server"
 			}
 
-			issues += checkSystemDNSResolution(localDomains)
+			checkSystemDNSResolution(r, localDomains)
 		} else {
-			ui.IndentedDim(1, "No local domains registered")
+			r.note(1, "No local domains registered")
 		}
 	} else {
 		// DNS container not running is only an issue if there are local domains
 		if hasLocalDomains {
-			ui.IndentedWarn(1, "Container is not running")
-			ui.IndentedDim(1, "Run 'srv install' to start")
-			issues++
+			r.suggest("Run 'srv install' to start", r.warn("Container is not running"))
 		} else {
-			ui.IndentedDim(1, "Not running (no local domains registered)")
+			r.note(1, "Not running (no local domains registered)")
 		}
 	}
 
-	ui.Blank()
-	return issues
+	return r.done()
 }
 
 // checkSystemDNSResolution probes every registered local domain through the
@@ -261,7 +372,7 @@ func checkDNS() int {
 //     systemd-resolved (the common Linux default) it never reaches srv's DNS,
 //     so the guidance points at .test or the nss ordering fix rather than the
 //     misleading "re-add the site".
-func checkSystemDNSResolution(domains []string) int {
+func checkSystemDNSResolution(r *checkReport, domains []string) {
 	var realFail, localFail []string
 	for _, d := range domains {
 		bare := traefik.BareDomain(d)
@@ -276,83 +387,74 @@ func checkSystemDNSResolution(domains []string) int {
 	}
 
 	if len(realFail) == 0 && len(localFail) == 0 {
-		ui.IndentedSuccess(1, "System DNS configured (%d domain(s) resolve)", len(domains))
-		return 0
+		r.ok("System DNS configured (%d domain(s) resolve)", len(domains))
+		return
 	}
 
-	issues := 0
 	if len(realFail) > 0 {
-		ui.IndentedWarn(1, "System DNS not configured for: %s", strings.Join(realFail, ", "))
-		ui.IndentedDim(1, "Re-run 'srv install', or remove and re-add the site to trigger DNS setup")
-		issues++
+		issue := r.warn("System DNS not configured for: %s", strings.Join(realFail, ", "))
+		if !doctorFlags.fix {
+			r.suggest("Re-run 'srv install' or 'srv doctor --fix', or remove and re-add the site to trigger DNS setup", issue)
+		} else if r.applyFix("configured system DNS", fixSetupDNS) {
+			r.resolve(issue)
+		}
 	}
 	if len(localFail) > 0 {
-		ui.IndentedWarn(1, ".local not resolving via system resolver: %s", strings.Join(localFail, ", "))
-		ui.IndentedDim(1, ".local is reserved for mDNS — nss-mdns intercepts it before srv's DNS.")
-		ui.IndentedDim(1, "Fastest fix: use a .test domain (no mDNS conflict, zero config).")
-		ui.IndentedDim(1, "To keep .local: make nss 'resolve' win (NixOS: services.avahi.nssmdns4 = false).")
-		issues++
+		issue := r.warn(".local not resolving via system resolver: %s", strings.Join(localFail, ", "))
+		r.note(1, ".local is reserved for mDNS — nss-mdns intercepts it before srv's DNS.")
+		r.suggest("Fastest fix: use a .test domain (no mDNS conflict, zero config).", issue)
+		r.note(1, "To keep .local: make nss 'resolve' win (NixOS: services.avahi.nssmdns4 = false).")
 	}
-	return issues
 }
 
 // checkMetrics flags the common "route live, backend dead" case: the metrics
 // stack was enabled (its routes/cert/DNS persist) but its containers are not
 // running — so grafana.local / prometheus.local return 502. Only reports when
 // the stack is configured at all; an unused metrics feature is silent.
-func checkMetrics() int {
+func checkMetrics() CheckResult {
 	cfg, err := config.Load()
 	if err != nil || !metrics.IsConfigured(cfg) {
-		return 0
+		return passedCheck()
 	}
-	ui.Bold("Metrics")
+	r := newCheckReport("Metrics")
 	prom := docker.IsContainerRunning(metrics.PrometheusContainer)
 	graf := docker.IsContainerRunning(metrics.GrafanaContainer)
 	if prom && graf {
-		ui.IndentedSuccess(1, "Stack running (https://%s)", metrics.GrafanaDomain)
-		ui.Blank()
-		return 0
+		r.ok("Stack running (https://%s)", metrics.GrafanaDomain)
+		return r.done()
 	}
-	ui.IndentedWarn(1, "Routes configured but stack not running — grafana.local/prometheus.local will 502")
-	ui.IndentedDim(1, "Run 'srv metrics enable' (or 'srv install') to start it")
-	ui.Blank()
-	return 1
+	r.suggest("Run 'srv metrics enable' (or 'srv install') to start it",
+		r.warn("Routes configured but stack not running — grafana.local/prometheus.local will 502"))
+	return r.done()
 }
 
 // checkCertificates verifies mkcert installation and certificate status
-func checkCertificates() int {
-	issues := 0
-	ui.Bold("Local SSL Certificates")
+func checkCertificates() CheckResult {
+	r := newCheckReport("Local SSL Certificates")
 
 	if err := traefik.CheckMkcert(); err != nil {
-		ui.IndentedWarn(1, "mkcert is not installed")
-		ui.IndentedDim(1, "Install mkcert for local HTTPS support")
-		ui.Blank()
-		return 1
+		r.suggest("Install mkcert for local HTTPS support", r.warn("mkcert is not installed"))
+		return r.done()
 	}
 
-	ui.IndentedSuccess(1, "mkcert is installed")
+	r.ok("mkcert is installed")
 
 	if traefik.IsCAInstalled() {
-		ui.IndentedSuccess(1, "CA is installed in system trust store")
+		r.ok("CA is installed in system trust store")
 	} else {
-		ui.IndentedWarn(1, "CA not installed")
-		ui.IndentedDim(1, "CA will be auto-installed on first 'srv add --local'")
-		issues++
+		r.suggest("CA will be auto-installed on first 'srv add --local'", r.warn("CA not installed"))
 	}
 
-	issues += checkCertificateExpiry()
-
-	ui.Blank()
-	return issues
+	checkCertificateExpiry(r)
+	return r.done()
 }
 
 // checkCertificateExpiry checks for expired or expiring certificates
-func checkCertificateExpiry() int {
+func checkCertificateExpiry(r *checkReport) {
 	certs := traefik.ListLocalCerts()
 	if len(certs) == 0 {
-		ui.IndentedDim(1, "No local certificates (generated when adding local sites)")
-		return 0
+		r.note(1, "No local certificates (generated when adding local sites)")
+		return
 	}
 
 	expired := 0
@@ -367,54 +469,86 @@ func checkCertificateExpiry() int {
 	}
 
 	if expired > 0 {
-		ui.IndentedError(1, "%d certificate(s) EXPIRED", expired)
-		ui.IndentedDim(1, "Certificates auto-renew on 'srv start'")
-		return 1
+		issue := r.fail("%d certificate(s) EXPIRED", expired)
+		if doctorFlags.fix {
+			if regenerateExpiredCerts(r, certs) {
+				r.resolve(issue)
+			}
+			return
+		}
+		r.suggest("Certificates auto-renew on 'srv start' (or run 'srv doctor --fix')", issue)
+		return
 	}
 
 	if expiringSoon > 0 {
-		ui.IndentedWarn(1, "%d certificate(s) expiring soon", expiringSoon)
-		return 1
+		r.warn("%d certificate(s) expiring soon", expiringSoon).Suggestion = "Certificates auto-renew on 'srv start'"
+		return
 	}
 
-	ui.IndentedSuccess(1, "%d certificate(s) valid", len(certs))
-	return 0
+	r.ok("%d certificate(s) valid", len(certs))
+}
+
+// regenerateExpiredCerts reissues every expired certificate in certs with the
+// owning site's domains. Imported certificates (srv cert import) are never
+// replaced with mkcert ones; they get a hint to import a fresh one instead.
+// Returns false if any certificate is left expired.
+func regenerateExpiredCerts(r *checkReport, certs []traefik.CertInfo) bool {
+	ok := true
+	for _, cert := range certs {
+		if cert.Status() != traefik.CertStatusExpired {
+			continue
+		}
+		domains, wildcard := []string{cert.Domain}, false
+		// Sites keep their SANs in metadata; proxies and redirects have a
+		// single domain.
+		meta, err := site.ReadSiteMetadata(cert.SiteName)
+		if err == nil && meta != nil && meta.CustomCert {
+			r.note(1, "%s serves an imported certificate; re-run 'srv cert import %s --cert FILE --key FILE' with a renewed one", cert.SiteName, cert.SiteName)
+			ok = false
+			continue
+		}
+		if err == nil && meta != nil && len(meta.Domains) > 0 && meta.Domains[0] == cert.Domain {
+			domains, wildcard = meta.Domains, meta.Wildcard
+		}
+		if !r.applyFix("regenerated certificate for "+cert.Domain, func() error {
+			return fixGenerateCert(cert.SiteName, domains, wildcard)
+		}) {
+			ok = false
+		}
+	}
+	return ok
 }
 
 // checkSitesValid validates every site's metadata.yml so users learn about
 // hand-edits that won't be hot-reloaded before they hit an error at runtime.
-func checkSitesValid() int {
-	ui.Bold("Site Metadata")
+func checkSitesValid() CheckResult {
+	r := newCheckReport("Site Metadata")
 	sites, err := site.List()
 	if err != nil {
-		ui.IndentedWarn(1, "Could not list sites: %v", err)
-		ui.Blank()
-		return 1
+		r.warn("Could not list sites: %v", err)
+		return r.done()
 	}
 	if len(sites) == 0 {
-		ui.IndentedDim(1, "No sites registered")
-		ui.Blank()
-		return 0
+		r.note(1, "No sites registered")
+		return r.done()
 	}
-	issues := 0
+	var invalid []*Issue
 	for _, s := range sites {
 		meta, err := site.ReadSiteMetadata(s.Name)
-		if err != nil {
-			ui.IndentedWarn(1, "%s: %v", s.Name, err)
-			issues++
-			continue
+		if err == nil {
+			err = site.ValidateMetadata(meta)
 		}
-		if err := site.ValidateMetadata(meta); err != nil {
-			ui.IndentedWarn(1, "%s: %v", s.Name, err)
-			issues++
-			continue
+		if err != nil {
+			invalid = append(invalid, r.warn("%s: %v", s.Name, err))
 		}
 	}
-	if issues == 0 {
-		ui.IndentedSuccess(1, "%d site(s) valid", len(sites))
+	if len(invalid) == 0 {
+		r.ok("%d site(s) valid", len(sites))
 	}
-	ui.Blank()
-	return issues
+	for _, issue := range invalid {
+		issue.Suggestion = "Fix the site's metadata.yml with 'srv edit'"
+	}
+	return r.done()
 }
 
 // checkSiteEnvHostLoopback scans every container-backed site's `.env` for
@@ -426,17 +560,15 @@ func checkSitesValid() int {
 //   - lines matching `*_HOST(S)?=...127.0.0.1` or `*_ENDPOINT=...://127.0.0.1...`
 //   - commented lines (starting with #) are skipped
 //   - case-insensitive variable name match
-func checkSiteEnvHostLoopback() int {
-	ui.Bold(".env host references")
+func checkSiteEnvHostLoopback() CheckResult {
+	r := newCheckReport(".env host references")
 	sites, err := site.List()
 	if err != nil {
-		ui.IndentedWarn(1, "Could not list sites: %v", err)
-		ui.Blank()
-		return 1
+		r.warn("Could not list sites: %v", err)
+		return r.done()
 	}
 
-	totalHits := 0
-	checked := 0
+	var found []*Issue
 	for _, s := range sites {
 		if s.Type == site.SiteTypeStatic {
 			continue
@@ -445,28 +577,29 @@ func checkSiteEnvHostLoopback() int {
 		if len(hits) == 0 {
 			continue
 		}
-		checked++
-		ui.IndentedWarn(1, "%s: %d .env entr%s point at 127.0.0.1", s.Name, len(hits), plural(len(hits), "y", "ies"))
+		found = append(found, r.warn("%s: %d .env entr%s point at 127.0.0.1", s.Name, len(hits), plural(len(hits), "y", "ies")))
 		for _, h := range hits {
-			ui.IndentedDim(2, "%s", h)
+			r.note(2, "%s", h)
 		}
-		totalHits += len(hits)
 	}
 
-	if totalHits == 0 {
-		ui.IndentedDim(1, "No host-loopback references found in site .env files")
-		ui.Blank()
-		return 0
+	if len(found) == 0 {
+		r.note(1, "No host-loopback references found in site .env files")
+		return r.done()
 	}
 
-	ui.Blank()
-	ui.IndentedDim(1, "These sites run in a container; 127.0.0.1 inside the container is the container itself.")
-	ui.IndentedDim(1, "Fix one of:")
-	ui.IndentedDim(2, "(a) rewrite to host.docker.internal — works because srv adds extra_hosts")
-	ui.IndentedDim(2, "(b) attach the site's container to the host service's docker network and use its container name")
-	ui.IndentedDim(2, "    srv network attach <site> <docker-network>")
-	ui.Blank()
-	return checked
+	for _, issue := range found {
+		issue.Suggestion = "Rewrite them to host.docker.internal, or attach the site to the host service's network with 'srv network attach SITE NETWORK'"
+	}
+	if r.text {
+		ui.Blank()
+	}
+	r.note(1, "These sites run in a container; 127.0.0.1 inside the container is the container itself.")
+	r.note(1, "Fix one of:")
+	r.note(2, "(a) rewrite to host.docker.internal — works because srv adds extra_hosts")
+	r.note(2, "(b) attach the site's container to the host service's docker network and use its container name")
+	r.note(2, "    srv network attach <site> <docker-network>")
+	return r.done()
 }
 
 func plural(n int, singular, pluralForm string) string {
@@ -510,60 +643,54 @@ func scanEnvForHostLoopback(path string) []string {
 //
 // Skipped on non-Linux/Darwin platforms where os.Stat doesn't yield Unix
 // uid/gid info.
-func checkConfigDirOwnership(fix bool) int {
-	ui.Bold("Config dir ownership")
+func checkConfigDirOwnership(fix bool) CheckResult {
+	r := newCheckReport("Config dir ownership")
 
 	if currentUID() == 0 {
-		ui.IndentedDim(1, "Running as root — ownership checks skipped")
-		ui.Blank()
-		return 0
+		r.note(1, "Running as root — ownership checks skipped")
+		return r.done()
 	}
 
 	cfg, err := config.Load()
 	if err != nil {
-		ui.IndentedWarn(1, "Could not load config: %v", err)
-		ui.Blank()
-		return 1
+		r.warn("Could not load config: %v", err)
+		return r.done()
 	}
 
 	wrong, err := findRootOwnedPaths(cfg.Root)
 	if err != nil {
-		ui.IndentedWarn(1, "Could not walk config dir: %v", err)
-		ui.Blank()
-		return 1
+		r.warn("Could not walk config dir: %v", err)
+		return r.done()
 	}
 	if len(wrong) == 0 {
-		ui.IndentedSuccess(1, "All files in %s are owned by the current user", cfg.Root)
-		ui.Blank()
-		return 0
+		r.ok("All files in %s are owned by the current user", cfg.Root)
+		return r.done()
 	}
 
-	ui.IndentedWarn(1, "%d path(s) in %s are root-owned", len(wrong), cfg.Root)
+	issue := r.warn("%d path(s) in %s are root-owned", len(wrong), cfg.Root)
 	preview := wrong
 	if len(preview) > 5 {
 		preview = preview[:5]
 	}
 	for _, p := range preview {
-		ui.IndentedDim(2, "%s", p)
+		r.note(2, "%s", p)
 	}
 	if len(wrong) > len(preview) {
-		ui.IndentedDim(2, "… and %d more", len(wrong)-len(preview))
+		r.note(2, "… and %d more", len(wrong)-len(preview))
 	}
 
 	if !fix {
-		ui.IndentedDim(1, "Re-run with --fix-perms to chown them back to the current user")
-		ui.Blank()
-		return 1
+		r.suggest("Re-run with --fix-perms to chown them back to the current user", issue)
+		return r.done()
 	}
 
 	if err := sudoChownTree(cfg.Root); err != nil {
-		ui.IndentedError(1, "chown failed: %v", err)
-		ui.Blank()
-		return 1
+		r.fail("chown failed: %v", err)
+		return r.done()
 	}
-	ui.IndentedSuccess(1, "Repaired ownership of %s", cfg.Root)
-	ui.Blank()
-	return 0
+	r.resolve(issue)
+	r.ok("Repaired ownership of %s", cfg.Root)
+	return r.done()
 }
 
 // findRootOwnedPaths walks root and collects paths whose owning uid is 0 (or
@@ -600,35 +727,61 @@ func sudoChownTree(root string) error {
 }
 
 // =============================================================================
-// update command
+// images command
 // =============================================================================
 
-var updateCmd = &cobra.Command{
+var imagesCmd = &cobra.Command{
+	Use:   "images",
+	Short: "Manage the Traefik and DNS images",
+}
+
+var imagesUpdateFlags struct {
+	version string
+}
+
+var imagesUpdateCmd = &cobra.Command{
 	Use:   "update",
 	Short: "Update Traefik and DNS images",
 	Long: `Pull the latest Traefik and DNS images and restart the containers.
 
 This ensures you're running the latest versions with security
-patches and new features.`,
-	RunE: runUpdate,
+patches and new features.
+
+--version pins Traefik to a release: it sets the traefik-image setting to
+that tag of the current image, regenerates the compose file and recreates
+the containers. 'srv config set traefik-image traefik:latest' unpins it.
+
+Examples:
+  srv images update
+  srv images update --version v3.1`,
+	Args: cobra.NoArgs,
+	RunE: runImagesUpdate,
 }
 
 func init() {
-	updateCmd.GroupID = GroupSystem
-	RootCmd.AddCommand(updateCmd)
+	imagesUpdateCmd.Flags().StringVar(&imagesUpdateFlags.version, "version", "", "Pin Traefik to this image tag (e.g. v3.1)")
+	imagesCmd.GroupID = GroupSystem
+	imagesCmd.AddCommand(imagesUpdateCmd)
+	RootCmd.AddCommand(imagesCmd)
 }
 
-func runUpdate(cmd *cobra.Command, args []string) error {
+func runImagesUpdate(cmd *cobra.Command, args []string) error {
 	if err := docker.EnsureRunning(); err != nil {
 		return err
 	}
 
+	if imagesUpdateFlags.version != "" {
+		if err := pinTraefikVersion(imagesUpdateFlags.version); err != nil {
+			return err
+		}
+	}
+
 	// Pull both images
 	ui.Info("Pulling latest images...")
-	if err := docker.Pull(docker.ImageTraefik); err != nil {
+	if err := docker.Pull(traefik.TraefikImage()); err != nil {
 		return fmt.Errorf("failed to pull Traefik image: %w", err)
 	}
-	if err := docker.Pull(docker.ImageDNS); err != nil {
+	if err := docker.Pull(traefik.DNSImage()); err != nil {
 		return fmt.Errorf("failed to pull DNS image: %w", err)
 	}
 
@@ -647,24 +800,120 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// pinTraefikVersion saves the traefik-image setting as the current image at
+// tag and regenerates traefik/docker-compose.yml to use it.
+func pinTraefikVersion(tag string) error {
+	tag = strings.TrimSpace(tag)
+	if tag == "" || strings.ContainsAny(tag, ":/@ \t") {
+		return ui.UsageError("srv images update --version TAG", "invalid version %q (expected an image tag such as v3.1)", tag)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	uc, err := cfg.LoadUserConfig()
+	if err != nil {
+		return err
+	}
+	uc.TraefikImage = imageWithTag(traefik.TraefikImage(), tag)
+	if err := cfg.SaveUserConfig(uc); err != nil {
+		return err
+	}
+	if err := traefik.WriteCompose(); err != nil {
+		return fmt.Errorf("failed to regenerate the Traefik compose file: %w", err)
+	}
+	ui.Success("Pinned Traefik to %s", uc.TraefikImage)
+	return nil
+}
+
+// imageWithTag replaces the tag (and any digest) of an image reference.
+// A registry port ("localhost:5000/traefik") is not mistaken for a tag.
+func imageWithTag(image, tag string) string {
+	repo, _, _ := strings.Cut(image, "@")
+	if i := strings.LastIndex(repo, ":"); i > strings.LastIndex(repo, "/") {
+		repo = repo[:i]
+	}
+	return repo + ":" + tag
+}
+
 // =============================================================================
 // version command
 // =============================================================================
 
+var versionFlags struct {
+	check bool
+}
+
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Show version info",
-	Run: func(cmd *cobra.Command, args []string) {
-		ui.Info("srv %s", Version)
-		if Commit != constants.DefaultCommit {
-			ui.Dim("Commit: %s", Commit)
-		}
-		if BuildDate != constants.DefaultBuildDate {
-			ui.Dim("Built:  %s", BuildDate)
-		}
-	},
+	Long: `Show srv's version, commit and build date.
+
+--check asks GitHub for the latest release and reports whether it is newer.
+The answer is cached for 24 hours. Development builds skip the check.
+
+Examples:
+  srv version
+  srv version --check
+  srv version --check --json`,
+	Args: cobra.NoArgs,
+	RunE: runVersion,
 }
 
 func init() {
+	versionCmd.Flags().BoolVar(&versionFlags.check, "check", false, "Check whether a newer release is available")
+	addJSONFlag(versionCmd)
 	RootCmd.AddCommand(versionCmd)
 }
+
+// versionInfo is the json shape of `srv version`.
+type versionInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildDate string `json:"build_date,omitempty"`
+	// The fields below are set by --check.
+	Latest          string `json:"latest,omitempty"`
+	UpdateAvailable bool   `json:"update_available,omitempty"`
+}
+
+func runVersion(cmd *cobra.Command, args []string) error {
+	info := versionInfo{Version: Version}
+	if Commit != constants.DefaultCommit {
+		info.Commit = Commit
+	}
+	if BuildDate != constants.DefaultBuildDate {
+		info.BuildDate = BuildDate
+	}
+	checked := versionFlags.check && Version != constants.DefaultVersion
+	if checked {
+		latest, err := latestRelease()
+		if err != nil {
+			return fmt.Errorf("check for updates: %w", err)
+		}
+		newer, err := isNewerVersion(latest, Version)
+		if err != nil {
+			return fmt.Errorf("check for updates: %w", err)
+		}
+		info.Latest, info.UpdateAvailable = latest, newer
+	}
+
+	if jsonOutput() {
+		return ui.PrintJSON(info)
+	}
+	ui.Info("srv %s", info.Version)
+	if info.Commit != "" {
+		ui.Dim("Commit: %s", info.Commit)
+	}
+	if info.BuildDate != "" {
+		ui.Dim("Built:  %s", info.BuildDate)
+	}
+	switch {
+	case versionFlags.check && !checked:
+		ui.Dim("Development build; skipping the update check")
+	case info.UpdateAvailable:
+		ui.Warn("Update available: %s — run 'srv update' to upgrade", info.Latest)
+	case checked:
+		ui.Success("Up to date")
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+// Package cmd — doctor_connectivity.go implements `srv doctor --check
+// connectivity`, which sends a real HTTPS request to every running site
+// instead of trusting container status alone.
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stubbedev/srv/internal/constants"
+	"github.com/stubbedev/srv/internal/site"
+	"github.com/stubbedev/srv/internal/ui"
+)
+
+// defaultConnectivityTimeout bounds each request of the connectivity check.
+const defaultConnectivityTimeout = 5 * time.Second
+
+// connectivityResult is the outcome of probing one site.
+type connectivityResult struct {
+	site    site.Site
+	url     string
+	status  int
+	latency time.Duration
+	err     error
+}
+
+// connectivityProbe performs the request for one site. Tests swap it.
+var connectivityProbe = probeSiteURL
+
+// probeSiteURL GETs url and returns the response status and how long the
+// response took to arrive. Local sites use mkcert certificates this process
+// may not trust, and their domains may not resolve outside a browser, so
+// their requests skip TLS verification and go straight to Traefik on
+// 127.0.0.1, port 443 or 80 by the URL's scheme. Redirects aren't followed; a 3xx means the site answered.
+func probeSiteURL(url string, local bool, timeout time.Duration) (int, time.Duration, error) {
+	transport := &http.Transport{}
+	if local {
+		port := "443"
+		if strings.HasPrefix(url, "http://") {
+			port = "80"
+		}
+		dialer := &net.Dialer{Timeout: timeout}
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec // local reachability probe only
+		transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, net.JoinHostPort(constants.LocalhostIP, port))
+		}
+	}
+	client := &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	start := time.Now()
+	resp, err := client.Get(url)
+	if err != nil {
+		return 0, 0, err
+	}
+	latency := time.Since(start)
+	_ = resp.Body.Close()
+	return resp.StatusCode, latency, nil
+}
+
+// checkSiteConnectivity requests the URL of every running site, a
+// few at a time, and flags sites that answer 5xx or not at all.
+func checkSiteConnectivity() CheckResult {
+	r := newCheckReport("Site Connectivity")
+	sites, err := site.List()
+	if err != nil {
+		r.warn("Could not list sites: %v", err)
+		return r.done()
+	}
+	var running []site.Site
+	for _, s := range sites {
+		if !s.IsBroken && s.Status == constants.StatusRunning && s.Domain() != "" {
+			running = append(running, s)
+		}
+	}
+	if len(running) == 0 {
+		r.note(1, "No running sites")
+		return r.done()
+	}
+
+	results := probeSites(running, doctorFlags.timeout)
+	for _, res := range results {
+		switch {
+		case res.err != nil:
+			r.fail("%s (%s) - unreachable: %v", res.site.Name, res.url, res.err).Suggestion = "Check the site with 'srv logs " + res.site.Name + "'"
+		case res.status >= 500:
+			r.fail("%s (%s) - %d in %s", res.site.Name, res.url, res.status, res.latency.Round(time.Millisecond)).Suggestion = "Check the site with 'srv logs " + res.site.Name + "'"
+		case res.status >= 400:
+			if r.text {
+				ui.IndentedWarn(1, "%s (%s) - %d in %s", res.site.Name, res.url, res.status, res.latency.Round(time.Millisecond))
+			}
+		default:
+			r.ok("%s (%s) - %d in %s", res.site.Name, res.url, res.status, res.latency.Round(time.Millisecond))
+		}
+	}
+	return r.done()
+}
+
+// probeSites probes every site with a worker pool, returning results in the
+// order of sites so the report reads the same as `srv list`.
+func probeSites(sites []site.Site, timeout time.Duration) []connectivityResult {
+	results := make([]connectivityResult, len(sites))
+	jobs := make(chan int, len(sites))
+	for i := range sites {
+		jobs <- i
+	}
+	close(jobs)
+
+	var wg sync.WaitGroup
+	for range min(maxWorkers(), len(sites)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				s := sites[i]
+				url := s.URL()
+				status, latency, err := connectivityProbe(url, s.IsLocal, timeout)
+				results[i] = connectivityResult{site: s, url: url, status: status, latency: latency, err: err}
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stubbedev/srv/internal/site"
+)
+
+func TestProbeSiteURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/moved" {
+			http.Redirect(w, r, "/elsewhere", http.StatusFound)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	status, latency, err := probeSiteURL(srv.URL, false, time.Second)
+	if err != nil || status != http.StatusServiceUnavailable || latency <= 0 {
+		t.Errorf("probe = %d, %v, %v", status, latency, err)
+	}
+	if status, _, err := probeSiteURL(srv.URL+"/moved", false, time.Second); err != nil || status != http.StatusFound {
+		t.Errorf("redirect should be reported, not followed: %d, %v", status, err)
+	}
+}
+
+func TestProbeSites(t *testing.T) {
+	prev := connectivityProbe
+	t.Cleanup(func() { connectivityProbe = prev })
+	connectivityProbe = func(url string, local bool, timeout time.Duration) (int, time.Duration, error) {
+		if timeout != 3*time.Second {
+			t.Errorf("timeout = %v", timeout)
+		}
+		switch url {
+		case "https://a.test":
+			if !local {
+				t.Error("a.test is a local site")
+			}
+			return 200, time.Millisecond, nil
+		case "https://b.example.com":
+			return 502, time.Millisecond, nil
+		}
+		return 0, 0, errors.New("connection refused")
+	}
+
+	sites := []site.Site{
+		{Name: "a", Domains: []string{"a.test"}, IsLocal: true},
+		{Name: "b", Domains: []string{"b.example.com"}},
+		{Name: "c", Domains: []string{"c.test"}, IsLocal: true},
+	}
+	results := probeSites(sites, 3*time.Second)
+	if len(results) != 3 {
+		t.Fatalf("got %d results", len(results))
+	}
+	if results[0].site.Name != "a" || results[0].status != 200 {
+		t.Errorf("a = %+v", results[0])
+	}
+	if results[1].status != 502 {
+		t.Errorf("b = %+v", results[1])
+	}
+	if results[2].err == nil {
+		t.Errorf("c = %+v, want an error", results[2])
+	}
+}
+
+func TestCheckSiteConnectivityNoSites(t *testing.T) {
+	setupSrvRoot(t)
+	if res := checkSiteConnectivity(); len(res.Issues) != 0 {
+		t.Errorf("no sites -> %d issues", len(res.Issues))
+	}
+}
@@ -0,0 +1,139 @@
+// Package cmd — doctor_report.go holds the result types of `srv doctor` and
+// checkReport, which each check uses to record its issues while printing its
+// section of the text report. With --format json nothing is printed and
+// runDoctor marshals the collected results instead.
+package cmd
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/stubbedev/srv/internal/ui"
+)
+
+// Check statuses and issue severities.
+const (
+	checkStatusOK      = "ok"
+	checkStatusWarning = "warning"
+	checkStatusError   = "error"
+)
+
+// CheckResult is the outcome of one doctor check, as printed by
+// `srv doctor --format json`.
+type CheckResult struct {
+	Name string `json:"name"`
+	// Status is ok, warning or error: the most severe of the issues.
+	Status string  `json:"status"`
+	Issues []Issue `json:"issues"`
+}
+
+// Issue is one problem a check found.
+type Issue struct {
+	Severity   string `json:"severity"` // error or warning
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// checkReport builds a CheckResult. Its methods print the matching line of
+// the text report unless JSON output was asked for.
+type checkReport struct {
+	text   bool
+	issues []*Issue
+}
+
+// newCheckReport starts a check, printing title as its heading; an empty
+// title prints none.
+func newCheckReport(title string) *checkReport {
+	r := &checkReport{text: !jsonOutput()}
+	if r.text && title != "" {
+		ui.Bold("%s", title)
+	}
+	return r
+}
+
+// passedCheck is the result of a check that has nothing to report.
+func passedCheck() CheckResult {
+	return CheckResult{Status: checkStatusOK, Issues: []Issue{}}
+}
+
+// ok prints a passing line.
+func (r *checkReport) ok(format string, args ...any) {
+	if r.text {
+		ui.IndentedSuccess(1, format, args...)
+	}
+}
+
+// note prints a dim detail line at the given indent level.
+func (r *checkReport) note(level int, format string, args ...any) {
+	if r.text {
+		ui.IndentedDim(level, format, args...)
+	}
+}
+
+// warn records and prints a warning.
+func (r *checkReport) warn(format string, args ...any) *Issue {
+	if r.text {
+		ui.IndentedWarn(1, format, args...)
+	}
+	return r.add(checkStatusWarning, format, args...)
+}
+
+// fail records and prints an error.
+func (r *checkReport) fail(format string, args ...any) *Issue {
+	if r.text {
+		ui.IndentedError(1, format, args...)
+	}
+	return r.add(checkStatusError, format, args...)
+}
+
+func (r *checkReport) add(severity, format string, args ...any) *Issue {
+	issue := &Issue{Severity: severity, Message: fmt.Sprintf(format, args...)}
+	r.issues = append(r.issues, issue)
+	return issue
+}
+
+// suggest attaches hint to issues and prints it once.
+func (r *checkReport) suggest(hint string, issues ...*Issue) {
+	for _, issue := range issues {
+		issue.Suggestion = hint
+	}
+	r.note(1, "%s", hint)
+}
+
+// resolve drops an issue that --fix repaired.
+func (r *checkReport) resolve(issue *Issue) {
+	r.issues = slices.DeleteFunc(r.issues, func(i *Issue) bool { return i == issue })
+}
+
+// applyFix runs one --fix remediation and prints how it went. Returns true
+// when it succeeded.
+func (r *checkReport) applyFix(desc string, fix func() error) bool {
+	if err := fix(); err != nil {
+		if r.text {
+			ui.IndentedError(1, "Fix failed (%s): %v", desc, err)
+		}
+		return false
+	}
+	if r.text {
+		ui.IndentedSuccess(1, "Fixed: %s", desc)
+	}
+	return true
+}
+
+// done ends the check's section and returns its result.
+func (r *checkReport) done() CheckResult {
+	if r.text {
+		ui.Blank()
+	}
+	res := passedCheck()
+	for _, issue := range r.issues {
+		res.Issues = append(res.Issues, *issue)
+		switch {
+		case issue.Severity == checkStatusError:
+			res.Status = checkStatusError
+		case res.Status == checkStatusOK:
+			res.Status = checkStatusWarning
+		}
+	}
+	return res
+}
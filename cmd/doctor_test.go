@@ -1,35 +1,42 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/stubbedev/srv/internal/docker"
 	"github.com/stubbedev/srv/internal/mkcert"
 	"github.com/stubbedev/srv/internal/shell"
 	"github.com/stubbedev/srv/internal/shell/shelltest"
+	"github.com/stubbedev/srv/internal/site"
+	"github.com/stubbedev/srv/internal/traefik"
+	"github.com/stubbedev/srv/internal/ui"
 )
 
 func TestCheckDockerFail(t *testing.T) {
 	t.Cleanup(docker.SwapNewClientErr(errors.New("not running")))
-	if issues := checkDocker(); issues != 1 {
-		t.Errorf("expected 1 issue, got %d", issues)
+	if res := checkDocker(); len(res.Issues) != 1 || res.Status != checkStatusError {
+		t.Errorf("expected 1 error, got %+v", res)
 	}
 }
 
 func TestCheckDockerOK(t *testing.T) {
 	t.Cleanup(docker.SwapNewClientOK())
-	if issues := checkDocker(); issues != 0 {
-		t.Errorf("expected 0 issues, got %d", issues)
+	if res := checkDocker(); len(res.Issues) != 0 || res.Status != checkStatusOK {
+		t.Errorf("expected no issues, got %+v", res)
 	}
 }
 
 func TestCheckFirewallNone(t *testing.T) {
 	t.Cleanup(shell.SwapDefault(shelltest.New(nil)))
-	if issues := checkFirewall(); issues != 0 {
-		t.Errorf("no firewall -> %d issues, want 0", issues)
+	if res := checkFirewall(); len(res.Issues) != 0 {
+		t.Errorf("no firewall -> %d issues, want 0", len(res.Issues))
 	}
 }
 
@@ -37,23 +44,23 @@ func TestRunDoctorSmoke(t *testing.T) {
 	setupSrvRoot(t)
 	t.Cleanup(docker.SwapNewClientErr(errors.New("offline")))
 	t.Cleanup(shell.SwapDefault(shelltest.New(nil)))
-	// Runs all checks; returns nil regardless of issue count.
-	if err := runDoctor(nil, nil); err != nil {
-		t.Errorf("err: %v", err)
+	// Runs all checks; Docker being down is an error, so the run fails.
+	if err := runDoctor(nil, nil); err == nil {
+		t.Error("expected err when a check reports an error")
 	}
 }
 
 func TestCheckNetworkMissing(t *testing.T) {
 	setupSrvRoot(t)
 	t.Cleanup(docker.SwapNewClientOK())
-	if checkNetwork() == 0 {
+	if len(checkNetwork().Issues) == 0 {
 		t.Error("missing network should yield issue")
 	}
 }
 
 func TestCheckTraefikDown(t *testing.T) {
 	t.Cleanup(docker.SwapNewClientErr(errors.New("offline")))
-	if checkTraefik() == 0 {
+	if len(checkTraefik().Issues) == 0 {
 		t.Error("expected issue when traefik down")
 	}
 }
@@ -61,7 +68,7 @@ func TestCheckTraefikDown(t *testing.T) {
 func TestCheckDNSNoDomains(t *testing.T) {
 	setupSrvRoot(t)
 	t.Cleanup(docker.SwapNewClientErr(errors.New("offline")))
-	if checkDNS() != 0 {
+	if len(checkDNS().Issues) != 0 {
 		t.Error("no local domains -> no issue")
 	}
 }
@@ -74,7 +81,7 @@ func TestCheckCertificatesNoMkcertOrNot(t *testing.T) {
 
 func TestCheckSitesValidEmpty(t *testing.T) {
 	setupSrvRoot(t)
-	if checkSitesValid() != 0 {
+	if len(checkSitesValid().Issues) != 0 {
 		t.Error("no sites -> no issues")
 	}
 }
@@ -84,27 +91,75 @@ func TestCheckPortsSmoke(t *testing.T) {
 	_ = checkPorts()
 }
 
-func TestRunUpdateDockerDown(t *testing.T) {
+func TestRunImagesUpdateDockerDown(t *testing.T) {
 	t.Cleanup(docker.SwapNewClientErr(errors.New("offline")))
-	if err := runUpdate(nil, nil); err == nil {
+	if err := runImagesUpdate(nil, nil); err == nil {
 		t.Error("expected err")
 	}
 }
 
-func TestRunUpdateHappy(t *testing.T) {
+func TestRunImagesUpdateHappy(t *testing.T) {
 	t.Cleanup(docker.SwapNewClientOK())
 	t.Cleanup(docker.SwapComposeExec(func(string, bool, ...string) error { return nil }))
-	if err := runUpdate(nil, nil); err != nil {
+	if err := runImagesUpdate(nil, nil); err != nil {
 		t.Errorf("err: %v", err)
 	}
 }
 
+func TestRunImagesUpdatePinsVersion(t *testing.T) {
+	setupSrvRoot(t)
+	cfg := mustLoadConfig(t)
+	if err := os.MkdirAll(cfg.TraefikDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cfg.TraefikComposePath(), []byte("services: {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(docker.SwapNewClientOK())
+	t.Cleanup(docker.SwapComposeExec(func(string, bool, ...string) error { return nil }))
+	t.Cleanup(func() { imagesUpdateFlags.version = "" })
+
+	executeRoot(t, "images", "update", "--version", "v3.1")
+	uc, err := cfg.LoadUserConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uc.TraefikImage != "traefik:v3.1" {
+		t.Errorf("traefik_image = %q, want traefik:v3.1", uc.TraefikImage)
+	}
+	compose, err := os.ReadFile(cfg.TraefikComposePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(compose), "image: traefik:v3.1") {
+		t.Errorf("compose file not regenerated with the pinned image:\n%s", compose)
+	}
+
+	imagesUpdateFlags.version = "traefik:v3.1"
+	if err := runImagesUpdate(nil, nil); err == nil {
+		t.Error("expected error for a full image reference as --version")
+	}
+}
+
+func TestImageWithTag(t *testing.T) {
+	for _, tc := range []struct{ image, want string }{
+		{"traefik:latest", "traefik:v3.1"},
+		{"traefik", "traefik:v3.1"},
+		{"localhost:5000/traefik", "localhost:5000/traefik:v3.1"},
+		{"ghcr.io/acme/traefik:v2@sha256:abc", "ghcr.io/acme/traefik:v3.1"},
+	} {
+		if got := imageWithTag(tc.image, "v3.1"); got != tc.want {
+			t.Errorf("imageWithTag(%q) = %q, want %q", tc.image, got, tc.want)
+		}
+	}
+}
+
 func TestCheckFirewallActive(t *testing.T) {
 	t.Cleanup(shell.SwapDefault(shelltest.New(map[string]shelltest.Response{
 		"ufw":      {Exists: true},
 		"sudo:ufw": {Out: []byte("Status: active\n80                         ALLOW       Anywhere\n443/tcp                    ALLOW       Anywhere\n")},
 	})))
-	if checkFirewall() != 0 {
+	if len(checkFirewall().Issues) != 0 {
 		t.Error("expected 0 issues when ports open")
 	}
 }
@@ -114,7 +169,7 @@ func TestCheckFirewallBlocked(t *testing.T) {
 		"ufw":      {Exists: true},
 		"sudo:ufw": {Out: []byte("Status: active\n")},
 	})))
-	if checkFirewall() != 2 {
+	if res := checkFirewall(); len(res.Issues) != 2 || res.Status != checkStatusWarning {
 		t.Error("expected 2 issues (HTTP+HTTPS blocked)")
 	}
 }
@@ -130,13 +185,13 @@ func TestCheckSitesValidWithBroken(t *testing.T) {
 
 func TestCheckCertificateExpiry(t *testing.T) {
 	setupSrvRoot(t)
-	_ = checkCertificateExpiry() // no certs → returns 0
+	checkCertificateExpiry(newCheckReport("")) // no certs → no issue
 }
 
 func TestCheckCertificateExpiryWithExpiring(t *testing.T) {
 	setupSrvRoot(t)
 	// Just call; ListLocalCerts returns nothing here.
-	_ = checkCertificateExpiry()
+	checkCertificateExpiry(newCheckReport(""))
 }
 
 func TestCheckDNSWithDomains(t *testing.T) {
@@ -191,3 +246,154 @@ func TestScanEnvForHostLoopbackMissingFile(t *testing.T) {
 		t.Errorf("missing file should return nil, got %v", hits)
 	}
 }
+
+// withDoctorFix turns on --fix for the test.
+func withDoctorFix(t *testing.T) {
+	t.Helper()
+	doctorFlags.fix = true
+	t.Cleanup(func() { doctorFlags.fix = false })
+}
+
+func TestCheckNetworkFix(t *testing.T) {
+	setupSrvRoot(t)
+	t.Cleanup(docker.SwapNewClientOK())
+	withDoctorFix(t)
+
+	var created string
+	prev := fixCreateNetwork
+	t.Cleanup(func() { fixCreateNetwork = prev })
+	fixCreateNetwork = func(name string) error { created = name; return nil }
+	if res := checkNetwork(); len(res.Issues) != 0 || created == "" {
+		t.Errorf("checkNetwork() = %+v, created %q; want the network created and no issue", res, created)
+	}
+
+	fixCreateNetwork = func(string) error { return errors.New("denied") }
+	if res := checkNetwork(); len(res.Issues) != 1 {
+		t.Errorf("failed fix should still count as an issue, got %d", len(res.Issues))
+	}
+}
+
+func TestCheckTraefikFix(t *testing.T) {
+	t.Cleanup(docker.SwapNewClientErr(errors.New("offline")))
+	withDoctorFix(t)
+	prev := fixRecreateTraefik
+	t.Cleanup(func() { fixRecreateTraefik = prev })
+
+	calls := 0
+	fixRecreateTraefik = func() error { calls++; return nil }
+	if res := checkTraefik(); len(res.Issues) != 0 || calls != 1 {
+		t.Errorf("checkTraefik() = %+v after %d recreate(s)", res, calls)
+	}
+}
+
+func TestRegenerateExpiredCerts(t *testing.T) {
+	setupSrvRoot(t)
+	writeTestSite(t, "blog", site.SiteMetadata{
+		Type:        site.SiteTypeStatic,
+		Domains:     []string{"blog.test", "www.blog.test"},
+		Wildcard:    true,
+		ProjectPath: "/tmp/blog",
+		Port:        80,
+		NetworkName: "n",
+	})
+	prev := fixGenerateCert
+	t.Cleanup(func() { fixGenerateCert = prev })
+	got := map[string][]string{}
+	fixGenerateCert = func(siteName string, domains []string, wildcard bool) error {
+		got[siteName] = domains
+		if siteName == "blog" && !wildcard {
+			t.Error("blog's wildcard SAN should be kept")
+		}
+		return nil
+	}
+
+	certs := []traefik.CertInfo{
+		{SiteName: "blog", Domain: "blog.test", Exists: true, IsExpired: true},
+		{SiteName: "srv-proxy-api", Domain: "api.test", Exists: true, IsExpired: true},
+		{SiteName: "fresh", Domain: "fresh.test", Exists: true, DaysLeft: 300},
+	}
+	if !regenerateExpiredCerts(newCheckReport(""), certs) {
+		t.Error("regeneration reported a failure")
+	}
+	if !slices.Equal(got["blog"], []string{"blog.test", "www.blog.test"}) {
+		t.Errorf("blog domains = %v", got["blog"])
+	}
+	if !slices.Equal(got["srv-proxy-api"], []string{"api.test"}) {
+		t.Errorf("proxy domains = %v", got["srv-proxy-api"])
+	}
+	if _, ok := got["fresh"]; ok {
+		t.Error("valid cert should not be regenerated")
+	}
+
+	fixGenerateCert = func(string, []string, bool) error { return errors.New("mkcert missing") }
+	if regenerateExpiredCerts(newCheckReport(""), certs) {
+		t.Error("failed regeneration reported success")
+	}
+}
+
+func TestRegenerateExpiredCertsSkipsImported(t *testing.T) {
+	setupSrvRoot(t)
+	writeTestSite(t, "corp", site.SiteMetadata{
+		Type:        site.SiteTypeStatic,
+		Domains:     []string{"corp.test"},
+		ProjectPath: "/tmp/corp",
+		Port:        80,
+		NetworkName: "n",
+		IsLocal:     true,
+		CustomCert:  true,
+	})
+	prev := fixGenerateCert
+	t.Cleanup(func() { fixGenerateCert = prev })
+	fixGenerateCert = func(siteName string, domains []string, wildcard bool) error {
+		t.Errorf("imported certificate for %s was replaced with an mkcert one", siteName)
+		return nil
+	}
+	certs := []traefik.CertInfo{{SiteName: "corp", Domain: "corp.test", Exists: true, IsExpired: true}}
+	if regenerateExpiredCerts(newCheckReport(""), certs) {
+		t.Error("an expired imported certificate was reported as fixed")
+	}
+}
+
+func TestRunDoctorCheck(t *testing.T) {
+	setupSrvRoot(t)
+	t.Cleanup(func() { doctorFlags.check = "" })
+
+	doctorFlags.check = "bogus"
+	if err := runDoctor(nil, nil); err == nil {
+		t.Error("expected err for an unknown check")
+	}
+
+	doctorFlags.check = "docker"
+	t.Cleanup(docker.SwapNewClientErr(errors.New("offline")))
+	if err := runDoctor(nil, nil); err == nil {
+		t.Error("expected err when the single check finds issues")
+	}
+	t.Cleanup(docker.SwapNewClientOK())
+	if err := runDoctor(nil, nil); err != nil {
+		t.Errorf("passing check: %v", err)
+	}
+}
+
+func TestRunDoctorJSON(t *testing.T) {
+	setupSrvRoot(t)
+	t.Cleanup(docker.SwapNewClientErr(errors.New("offline")))
+	var stdout bytes.Buffer
+	t.Cleanup(ui.SwapStdout(&stdout))
+	t.Cleanup(func() { doctorFlags.check, outputFormat = "", "table" })
+	doctorFlags.check, outputFormat = "docker", "json"
+
+	if err := runDoctor(nil, nil); err == nil {
+		t.Error("expected err when Docker is down")
+	}
+	var results []CheckResult
+	if err := json.Unmarshal(stdout.Bytes(), &results); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, stdout.String())
+	}
+	if len(results) != 1 || results[0].Name != "docker" || results[0].Status != checkStatusError {
+		t.Fatalf("results = %+v", results)
+	}
+	issue := results[0].Issues[0]
+	if issue.Severity != checkStatusError || issue.Message == "" || issue.Suggestion == "" {
+		t.Errorf("issue = %+v", issue)
+	}
+}
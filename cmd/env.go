@@ -0,0 +1,165 @@
+// Package cmd — env.go implements `srv env`, per-site environment overrides
+// layered over the project's compose file without editing its .env.
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stubbedev/srv/internal/site"
+	"github.com/stubbedev/srv/internal/ui"
+)
+
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Manage per-site environment overrides",
+	Long: `Override environment variables of a site's container without touching the
+project's .env or compose file. Overrides are stored in the site's config dir
+(env-override.env) and applied on every start: srv layers a temporary compose
+file that sets them on the routed service, and removes it once compose exits.
+
+Changes take effect the next time the site starts ('srv start SITE').`,
+}
+
+var envSetCmd = &cobra.Command{
+	Use:   "set SITE KEY=VALUE...",
+	Short: "Set environment overrides for a site",
+	Long: `Set one or more KEY=VALUE overrides for a site, replacing existing values.
+
+Examples:
+  srv env set myapp APP_DEBUG=true
+  srv env set myapp DB_HOST=mysql01 DB_PORT=3306`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runEnvSet,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return GetSiteNames(), cobra.ShellCompDirectiveNoFileComp
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+var envUnsetCmd = &cobra.Command{
+	Use:   "unset SITE KEY...",
+	Short: "Remove environment overrides from a site",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  runEnvUnset,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return GetSiteNames(), cobra.ShellCompDirectiveNoFileComp
+		}
+		env, _ := site.ReadEnvOverrides(args[0])
+		keys := make([]string, 0, len(env))
+		for k := range env {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		return keys, cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+var envListCmd = &cobra.Command{
+	Use:   "list SITE",
+	Short: "List a site's environment overrides",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runEnvList,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return GetSiteNames(), cobra.ShellCompDirectiveNoFileComp
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+func init() {
+	envCmd.GroupID = GroupSites
+	envCmd.AddCommand(envSetCmd, envUnsetCmd, envListCmd)
+	RootCmd.AddCommand(envCmd)
+}
+
+func runEnvSet(cmd *cobra.Command, args []string) error {
+	siteName := args[0]
+	// Parse every pair before writing any, so a typo doesn't leave half the
+	// overrides applied.
+	type pair struct{ key, value string }
+	pairs := make([]pair, 0, len(args)-1)
+	for _, arg := range args[1:] {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return ui.UsageError(cmd.UseLine(), "expected KEY=VALUE, got %q", arg)
+		}
+		if err := site.ValidateEnvKey(key); err != nil {
+			return err
+		}
+		pairs = append(pairs, pair{key, value})
+	}
+	for _, p := range pairs {
+		if err := site.SetEnvOverride(siteName, p.key, p.value); err != nil {
+			return err
+		}
+		ui.Success("Set %s for %s", p.key, siteName)
+	}
+	ui.Dim("Run `srv start %s` to apply.", siteName)
+	return nil
+}
+
+func runEnvUnset(cmd *cobra.Command, args []string) error {
+	siteName := args[0]
+	for _, key := range args[1:] {
+		removed, err := site.UnsetEnvOverride(siteName, key)
+		if err != nil {
+			return err
+		}
+		if !removed {
+			ui.Dim("%s is not set for %s", key, siteName)
+			continue
+		}
+		ui.Success("Unset %s for %s", key, siteName)
+	}
+	ui.Dim("Run `srv start %s` to apply.", siteName)
+	return nil
+}
+
+// envOverrideRow is the json shape for one override under
+// `srv env list --format json`.
+type envOverrideRow struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+func runEnvList(cmd *cobra.Command, args []string) error {
+	siteName := args[0]
+	if !site.HasSiteMetadata(siteName) {
+		return fmt.Errorf("site not found: %s", siteName)
+	}
+	env, err := site.ReadEnvOverrides(siteName)
+	if err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	if jsonOutput() {
+		out := make([]envOverrideRow, 0, len(keys))
+		for _, k := range keys {
+			out = append(out, envOverrideRow{Key: k, Value: env[k]})
+		}
+		return ui.PrintJSON(out)
+	}
+	if len(keys) == 0 {
+		ui.Dim("No environment overrides for %s. Use 'srv env set %s KEY=VALUE' to add one.", siteName, siteName)
+		return nil
+	}
+	rows := make([][]string, 0, len(keys))
+	for _, k := range keys {
+		rows = append(rows, []string{k, env[k]})
+	}
+	ui.PrintTable([]string{"KEY", "VALUE"}, rows)
+	return nil
+}
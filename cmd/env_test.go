@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stubbedev/srv/internal/site"
+)
+
+func TestRunEnvSetListUnset(t *testing.T) {
+	setupSrvRoot(t)
+	writeTestSite(t, "app", site.SiteMetadata{
+		Type:        site.SiteTypeStatic,
+		Domains:     []string{"app.test"},
+		ProjectPath: t.TempDir(),
+		Port:        80,
+		NetworkName: "n",
+	})
+
+	if err := runEnvSet(envSetCmd, []string{"app", "DB_HOST=mysql01", "MOTD=a=b c"}); err != nil {
+		t.Fatal(err)
+	}
+	env, err := site.ReadEnvOverrides("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env["DB_HOST"] != "mysql01" || env["MOTD"] != "a=b c" {
+		t.Errorf("overrides = %v", env)
+	}
+	if err := runEnvList(envListCmd, []string{"app"}); err != nil {
+		t.Errorf("list: %v", err)
+	}
+
+	if err := runEnvUnset(envUnsetCmd, []string{"app", "DB_HOST", "NOPE"}); err != nil {
+		t.Fatal(err)
+	}
+	env, _ = site.ReadEnvOverrides("app")
+	if _, ok := env["DB_HOST"]; ok || env["MOTD"] != "a=b c" {
+		t.Errorf("after unset = %v", env)
+	}
+}
+
+func TestRunEnvSetRejectsBadPairs(t *testing.T) {
+	setupSrvRoot(t)
+	writeTestSite(t, "app", site.SiteMetadata{
+		Type:        site.SiteTypeStatic,
+		Domains:     []string{"app.test"},
+		ProjectPath: t.TempDir(),
+		Port:        80,
+		NetworkName: "n",
+	})
+	// A bad pair anywhere in the list must leave nothing written.
+	err := runEnvSet(envSetCmd, []string{"app", "GOOD=1", "MISSING_EQUALS"})
+	if err == nil || !strings.Contains(err.Error(), "KEY=VALUE") {
+		t.Errorf("err = %v", err)
+	}
+	if err := runEnvSet(envSetCmd, []string{"app", "GOOD=1", "9BAD=1"}); err == nil {
+		t.Error("expected error for an invalid key")
+	}
+	if env, _ := site.ReadEnvOverrides("app"); len(env) != 0 {
+		t.Errorf("partial write: %v", env)
+	}
+}
+
+func TestRunEnvListMissingSite(t *testing.T) {
+	setupSrvRoot(t)
+	if err := runEnvList(envListCmd, []string{"ghost"}); err == nil {
+		t.Error("expected error for a missing site")
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/fsutil"
 	"github.com/stubbedev/srv/internal/importers/valet"
 	"github.com/stubbedev/srv/internal/ui"
 )
@@ -219,7 +220,7 @@ func saveImportDecisions(d importDecisions) error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, data, 0o644)
+	return fsutil.AtomicWriteFile(path, data, 0o644)
 }
 
 // resolveValetDir picks a valet config directory. When --valet-dir is given it
@@ -20,9 +20,10 @@ import (
 )
 
 var installFlags struct {
-	fresh bool
-	yes   bool
-	email string
+	fresh      bool
+	yes        bool
+	skipDaemon bool
+	email      string
 }
 
 var installCmd = &cobra.Command{
@@ -35,13 +36,21 @@ var installCmd = &cobra.Command{
   4. Installs the daemon service
   5. Starts all registered sites
 
-Use --fresh to remove all existing configuration and start fresh.`,
+Re-running it is cheap and safe (e.g. from a login script): steps that are
+already done - an existing network, unchanged config, a running Traefik, an
+installed daemon, running sites - are reported as skipped and left alone.
+
+Use --fresh to remove all existing configuration and start fresh.
+
+Use --skip-daemon to leave the daemon service out (CI runners, Docker-in-
+Docker); 'srv config set no-daemon true' makes every install skip it.`,
 	RunE: runInstall,
 }
 
 func init() {
 	installCmd.Flags().BoolVar(&installFlags.fresh, "fresh", false, "Remove existing configuration and start fresh")
 	installCmd.Flags().BoolVarP(&installFlags.yes, "yes", "y", false, "Assume yes to every confirmable action (firewall open, port conflict auto-fix, valet stop, mkcert CA install retry). Required for non-interactive runs.")
+	installCmd.Flags().BoolVar(&installFlags.skipDaemon, "skip-daemon", false, "Don't install the daemon service")
 	installCmd.Flags().StringVar(&installFlags.email, "email", "", "Let's Encrypt account email for production SSL. Stored on disk after first set; only required once. Pass an empty string to disable production SSL entirely.")
 	installCmd.GroupID = GroupSystem
 	RootCmd.AddCommand(installCmd)
@@ -68,6 +77,9 @@ func runInstall(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// Upgrade metadata written by older srv versions before anything reads it.
+	migrateSitesQuietly()
+
 	// Pre-flight: a previously-installed Valet will own :80/:443/:53 and break
 	// the port-bind step further down. Offer to stop its systemd units first
 	// so the install can proceed without the user having to retry.
@@ -92,8 +104,10 @@ func runInstall(cmd *cobra.Command, args []string) error {
 	if len(sites) > 0 {
 		totalSteps++
 	}
-	// Add step for daemon installation
-	needDaemon := !daemon.IsInstalled()
+	// Add step for daemon installation, unless it is turned off for this run
+	// (--skip-daemon) or for good (no-daemon).
+	daemonDisabled := installFlags.skipDaemon || config.UserSettings().DisableDaemon
+	needDaemon := !daemonDisabled && !daemon.IsInstalled()
 	if needDaemon {
 		totalSteps++
 	}
@@ -140,38 +154,47 @@ func runInstall(cmd *cobra.Command, args []string) error {
 
 	// Step 2: Generate Traefik config
 	steps.Next("Configuring Traefik")
-	if err := traefik.EnsureConfig(email); err != nil {
+	configChanged, err := traefik.EnsureConfig(email)
+	if err != nil {
 		return err
 	}
-	steps.Done("Traefik configured")
+	if configChanged {
+		steps.Done("Traefik configured")
+	} else {
+		steps.Skip("Traefik config already up to date")
+	}
 
-	// Step 3: Start Traefik
+	// Step 3: Start Traefik. A running Traefik is only touched when its
+	// static config or compose file just changed.
 	steps.Next("Starting Traefik")
-
-	// Pre-flight: check for port conflicts before attempting to bind.
-	if conflicts := traefik.CheckPortConflicts(); len(conflicts) > 0 {
-		if err := resolvePortConflicts(conflicts); err != nil {
-			return err
+	if traefik.IsRunning() && !configChanged {
+		steps.Skip("Traefik already running")
+	} else {
+		// Pre-flight: check for port conflicts before attempting to bind.
+		if conflicts := traefik.CheckPortConflicts(); len(conflicts) > 0 {
+			if err := resolvePortConflicts(conflicts); err != nil {
+				return err
+			}
 		}
-	}
 
-	// /etc/resolv.conf may still point at a loopback DNS server the user just
-	// stopped (e.g. a former Valet dnsmasq). Without working resolution the
-	// next `docker compose up` can't pull Traefik/dnsmasq images. Swap in
-	// public DNS for the duration of the pull, then restore the original
-	// once srv's own dnsmasq is up and 127.0.0.1:53 resolves again.
-	restoreResolv, rerr := traefik.EnsureBootstrapResolution()
-	if rerr != nil {
-		ui.Warn("Could not pre-swap /etc/resolv.conf: %v", rerr)
-	} else if restoreResolv != nil {
-		ui.Dim("Pre-swapped /etc/resolv.conf to public DNS for the image pull")
-		defer restoreResolv()
-	}
+		// /etc/resolv.conf may still point at a loopback DNS server the user just
+		// stopped (e.g. a former Valet dnsmasq). Without working resolution the
+		// next `docker compose up` can't pull Traefik/dnsmasq images. Swap in
+		// public DNS for the duration of the pull, then restore the original
+		// once srv's own dnsmasq is up and 127.0.0.1:53 resolves again.
+		restoreResolv, rerr := traefik.EnsureBootstrapResolution()
+		if rerr != nil {
+			ui.Warn("Could not pre-swap /etc/resolv.conf: %v", rerr)
+		} else if restoreResolv != nil {
+			ui.Dim("Pre-swapped /etc/resolv.conf to public DNS for the image pull")
+			defer restoreResolv()
+		}
 
-	if err := docker.ComposeUp(cfg.TraefikDir); err != nil {
-		return fmt.Errorf("failed to start Traefik: %w", err)
+		if err := docker.ComposeUp(cfg.TraefikDir); err != nil {
+			return fmt.Errorf("failed to start Traefik: %w", err)
+		}
+		steps.Done("Traefik started")
 	}
-	steps.Done("Traefik started")
 
 	// Pre-warm dnsmasq with every domain that's already registered so site
 	// hostnames resolve immediately after `srv install` instead of waiting
@@ -209,6 +232,10 @@ func runInstall(cmd *cobra.Command, args []string) error {
 		} else {
 			steps.Done("Daemon service installed")
 		}
+	} else if daemonDisabled {
+		ui.Dim("Daemon service skipped; containers started outside srv won't be connected to %s automatically", cfg.NetworkName)
+	} else {
+		ui.Dim("Daemon service already installed")
 	}
 
 	// One-time migration off the legacy shared "srv" compose project (which made
@@ -216,14 +243,19 @@ func runInstall(cmd *cobra.Command, args []string) error {
 	// now uses its own project. Regenerate the generated compose files so they
 	// carry the new per-stack project name, then clear the old project's
 	// containers so the per-stack `up` below can reuse their fixed
-	// container_names without a conflict. All idempotent once migrated.
+	// container_names without a conflict. All idempotent once migrated: the
+	// same binary re-running install only reloads sites whose metadata changed.
 	if metrics.IsConfigured(cfg) {
 		if err := metrics.WriteStack(cfg); err != nil {
 			ui.Warn("Failed to regenerate metrics stack: %v", err)
 		}
 	}
+	reload := site.Reload
+	if traefik.InstalledVersion(cfg) != Version {
+		reload = site.ForceReload
+	}
 	for _, s := range sites {
-		if _, err := site.ForceReload(s.Name); err != nil {
+		if _, err := reload(s.Name); err != nil {
 			ui.Warn("Failed to regenerate config for %s: %v", s.Name, err)
 		}
 	}
@@ -234,8 +266,12 @@ func runInstall(cmd *cobra.Command, args []string) error {
 	// Step 6: Start all sites (if any)
 	if len(sites) > 0 {
 		steps.Next("Starting %d site(s)", len(sites))
-		startSites(sites)
-		steps.Done("Sites started")
+		if stopped := sitesToStart(sites); len(stopped) == 0 {
+			steps.Skip("All sites already running")
+		} else {
+			startSites(stopped)
+			steps.Done("Sites started")
+		}
 	}
 
 	// Re-up a previously-enabled metrics stack. Its routes/cert/DNS persist but
@@ -243,7 +279,9 @@ func runInstall(cmd *cobra.Command, args []string) error {
 	// prometheus.local 502 until the user re-runs `srv metrics enable`.
 	if metrics.IsConfigured(cfg) {
 		steps.Next("Restarting metrics stack")
-		if err := docker.ComposeUp(metrics.Dir(cfg)); err != nil {
+		if docker.IsContainerRunning(metrics.PrometheusContainer) && docker.IsContainerRunning(metrics.GrafanaContainer) {
+			steps.Skip("Metrics stack already running")
+		} else if err := docker.ComposeUp(metrics.Dir(cfg)); err != nil {
 			ui.Warn("Failed to restart metrics stack: %v", err)
 			steps.Skip("Metrics stack skipped")
 		} else {
@@ -265,9 +303,21 @@ func runInstall(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// sitesToStart returns the sites `srv install` has to bring up: those not
+// already running. Broken sites stay in so startSites reports them.
+func sitesToStart(sites []site.Site) []site.Site {
+	var out []site.Site
+	for _, s := range sites {
+		if s.Status != constants.StatusRunning {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
 func startSites(sites []site.Site) {
 	_ = runBatchSiteOperation(sites, "Starting", func(s *site.Site) error {
-		return docker.ComposeUp(s.ComposeDir)
+		return site.ComposeUp(s, false)
 	})
 }
 
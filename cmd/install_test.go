@@ -3,6 +3,8 @@ package cmd
 import (
 	"testing"
 
+	"github.com/stubbedev/srv/internal/constants"
+	"github.com/stubbedev/srv/internal/site"
 	"github.com/stubbedev/srv/internal/traefik"
 )
 
@@ -29,3 +31,18 @@ func TestStartSitesEmpty(t *testing.T) {
 	// startSites with no sites should be a noop.
 	startSites(nil)
 }
+
+func TestSitesToStart(t *testing.T) {
+	sites := []site.Site{
+		{Name: "up", Status: constants.StatusRunning},
+		{Name: "down", Status: constants.StatusStopped},
+		{Name: "gone", IsBroken: true},
+	}
+	got := sitesToStart(sites)
+	if len(got) != 2 || got[0].Name != "down" || got[1].Name != "gone" {
+		t.Errorf("sitesToStart = %v", got)
+	}
+	if got := sitesToStart(sites[:1]); len(got) != 0 {
+		t.Errorf("all running: got %v", got)
+	}
+}
@@ -0,0 +1,192 @@
+// Package cmd — logs_access.go implements `srv logs --access`, which shows
+// the requests Traefik served for a site from its JSON access log.
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/constants"
+	"github.com/stubbedev/srv/internal/docker"
+	"github.com/stubbedev/srv/internal/site"
+	"github.com/stubbedev/srv/internal/traefik"
+	"github.com/stubbedev/srv/internal/ui"
+)
+
+// accessLogHeaders are the columns of the `srv logs --access` table.
+var accessLogHeaders = []string{"TIME", "CLIENT", "METHOD", "STATUS", "DURATION", "PATH"}
+
+func runAccessLogs(siteName string) error {
+	s, err := site.GetByName(siteName)
+	if err != nil {
+		return err
+	}
+	if len(s.Domains) == 0 {
+		return fmt.Errorf("site '%s' has no domains to match in the access log", s.Name)
+	}
+	filter, tail, err := accessLogFilter(s.Domains, time.Now())
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	path := cfg.TraefikAccessLogPath()
+
+	var (
+		entries  []traefik.AccessLogEntry
+		unparsed int
+		offset   int64
+	)
+	file, err := os.Open(path) //nolint:gosec // path is srv's own access log
+	switch {
+	case err == nil:
+		if tail != 0 {
+			entries, unparsed, err = traefik.ReadAccessLog(file, filter, tail)
+		}
+		if err == nil {
+			offset, err = file.Seek(0, io.SeekEnd)
+		}
+		_ = file.Close()
+		if err != nil {
+			return err
+		}
+	case os.IsNotExist(err):
+		if !logsFlags.follow {
+			ui.Dim("No access log yet at %s — Traefik writes it once it serves a request.", path)
+			return nil
+		}
+	default:
+		return err
+	}
+
+	if unparsed > 0 && len(entries) == 0 {
+		ui.Warn("%d access log lines aren't JSON. Set `format: json` under accessLog in %s, then run `docker restart %s`.",
+			unparsed, filepath.Join(cfg.TraefikConfDir(), "traefik.yml"), docker.ContainerTraefik)
+	}
+
+	if !logsFlags.follow {
+		if jsonOutput() {
+			if entries == nil {
+				entries = []traefik.AccessLogEntry{}
+			}
+			return ui.PrintJSON(entries)
+		}
+		if len(entries) == 0 {
+			ui.Dim("No matching requests for %s.", s.Name)
+			return nil
+		}
+		rows := make([][]string, 0, len(entries))
+		for _, e := range entries {
+			rows = append(rows, accessLogRow(e))
+		}
+		ui.PrintTable(accessLogHeaders, rows)
+		return nil
+	}
+
+	for _, e := range entries {
+		printAccessLogEntry(e)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return traefik.FollowAccessLog(ctx, path, offset, filter, printAccessLogEntry)
+}
+
+// accessLogFilter builds the entry filter and tail count from the logs
+// flags. tail is -1 for every entry.
+func accessLogFilter(domains []string, now time.Time) (traefik.AccessLogFilter, int, error) {
+	filter := traefik.AccessLogFilter{Domains: domains}
+	if logsFlags.status != "" {
+		lo, hi, err := traefik.ParseStatusFilter(logsFlags.status)
+		if err != nil {
+			return filter, 0, err
+		}
+		filter.StatusMin, filter.StatusMax = lo, hi
+	}
+	if logsFlags.since != "" {
+		since, err := parseLogsSince(logsFlags.since, now)
+		if err != nil {
+			return filter, 0, err
+		}
+		filter.Since = since
+	}
+	tail := -1
+	if logsFlags.tail != "" && logsFlags.tail != "all" {
+		n, err := strconv.Atoi(logsFlags.tail)
+		if err != nil || n < 0 {
+			return filter, 0, fmt.Errorf("invalid --tail %q: expected a number or \"all\"", logsFlags.tail)
+		}
+		tail = n
+	}
+	return filter, tail, nil
+}
+
+// parseLogsSince accepts what `docker compose logs --since` does for
+// access logs: a relative duration (10m, 1h30m), an RFC 3339 timestamp, or
+// a local date or date-time.
+func parseLogsSince(s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", time.DateTime, constants.DateFormat} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q: expected a duration (10m) or a timestamp (2006-01-02T15:04:05Z)", s)
+}
+
+// accessLogRow renders an entry as a table row.
+func accessLogRow(e traefik.AccessLogEntry) []string {
+	status := strconv.Itoa(e.Status)
+	switch {
+	case e.Status >= 500:
+		status = ui.ErrorText(status)
+	case e.Status >= 400:
+		status = ui.WarnText(status)
+	}
+	return []string{
+		e.Time.Local().Format(time.DateTime),
+		e.ClientIP,
+		e.Method,
+		status,
+		accessLogDuration(e.Duration).String(),
+		e.Path,
+	}
+}
+
+// printAccessLogEntry prints one entry on its own line, for --follow where a
+// table can't be laid out up front.
+func printAccessLogEntry(e traefik.AccessLogEntry) {
+	if jsonOutput() {
+		out, err := json.Marshal(e)
+		if err == nil {
+			fmt.Println(string(out))
+		}
+		return
+	}
+	r := accessLogRow(e)
+	ui.Print("%s  %-15s  %-7s  %s  %8s  %s", r[0], r[1], r[2], r[3], r[4], r[5])
+}
+
+// accessLogDuration rounds a request duration for display: to the
+// millisecond, or the microsecond for sub-millisecond requests.
+func accessLogDuration(d time.Duration) time.Duration {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond)
+	}
+	return d.Round(time.Millisecond)
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/site"
+)
+
+func TestParseLogsSince(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	got, err := parseLogsSince("90m", now)
+	if err != nil || !got.Equal(now.Add(-90*time.Minute)) {
+		t.Errorf("90m = %v, %v", got, err)
+	}
+	got, err = parseLogsSince("2026-10-16T09:00:00Z", now)
+	if err != nil || !got.Equal(time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("RFC 3339 = %v, %v", got, err)
+	}
+	if _, err := parseLogsSince("2026-10-16", now); err != nil {
+		t.Errorf("date: %v", err)
+	}
+	if _, err := parseLogsSince("yesterday", now); err == nil {
+		t.Error("expected err")
+	}
+}
+
+func TestAccessLogFilterFlags(t *testing.T) {
+	t.Cleanup(func() { logsFlags.status, logsFlags.tail, logsFlags.since = "", "", "" })
+
+	logsFlags.status, logsFlags.tail = "5xx", "20"
+	f, tail, err := accessLogFilter([]string{"app.test"}, time.Now())
+	if err != nil || tail != 20 || f.StatusMin != 500 || f.StatusMax != 599 {
+		t.Errorf("filter = %+v, tail %d, %v", f, tail, err)
+	}
+
+	logsFlags.status, logsFlags.tail = "", "all"
+	if _, tail, err := accessLogFilter(nil, time.Now()); err != nil || tail != -1 {
+		t.Errorf("tail all = %d, %v; want -1", tail, err)
+	}
+
+	for _, bad := range []struct{ status, tail, since string }{
+		{status: "teapot"},
+		{tail: "-3"},
+		{since: "soon"},
+	} {
+		logsFlags.status, logsFlags.tail, logsFlags.since = bad.status, bad.tail, bad.since
+		if _, _, err := accessLogFilter(nil, time.Now()); err == nil {
+			t.Errorf("expected err for %+v", bad)
+		}
+	}
+}
+
+func TestAccessLogDuration(t *testing.T) {
+	if got := accessLogDuration(12345678 * time.Nanosecond); got != 12*time.Millisecond {
+		t.Errorf("got %v", got)
+	}
+	if got := accessLogDuration(850400 * time.Nanosecond); got != 850*time.Microsecond {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestRunAccessLogsMissingSite(t *testing.T) {
+	setupSrvRoot(t)
+	if err := runAccessLogs("ghost"); err == nil {
+		t.Error("expected err")
+	}
+}
+
+func TestRunAccessLogsReadsLog(t *testing.T) {
+	root := setupSrvRoot(t)
+	projectDir := filepath.Join(root, "p")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestSite(t, "blog", site.SiteMetadata{
+		Type:        site.SiteTypeStatic,
+		Domains:     []string{"blog.test"},
+		ProjectPath: projectDir,
+		Port:        80,
+		NetworkName: "n",
+	})
+	cfg, _ := config.Load()
+	path := cfg.TraefikAccessLogPath()
+
+	// No log yet is not an error.
+	if err := runAccessLogs("blog"); err != nil {
+		t.Fatalf("missing log: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	line := `{"ClientHost":"203.0.113.7","DownstreamStatus":200,"Duration":1000000,"RequestHost":"blog.test","RequestMethod":"GET","RequestPath":"/","StartUTC":"2026-10-16T09:00:00Z"}`
+	if err := os.WriteFile(path, []byte(line+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := runAccessLogs("blog"); err != nil {
+		t.Errorf("err: %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+// Package cmd — logs_merge.go implements `srv logs --merge-all`, which
+// streams the container logs of many sites into one terminal, each line
+// prefixed with its site's name in a colour of its own.
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/stubbedev/srv/internal/constants"
+	"github.com/stubbedev/srv/internal/docker"
+	"github.com/stubbedev/srv/internal/site"
+	"github.com/stubbedev/srv/internal/ui"
+)
+
+// runLogsMerged runs `docker compose logs` for each site in parallel and
+// multiplexes the output onto stdout. names limits it to those sites;
+// otherwise every running site is streamed. Returns once every stream has
+// ended (with --follow, when the sites stop or on Ctrl-C).
+func runLogsMerged(names []string) error {
+	sites, err := mergeLogSites(names)
+	if err != nil {
+		return err
+	}
+	if len(sites) == 0 {
+		ui.Dim("No running sites")
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	for i := range sites {
+		s := &sites[i]
+		prefix := ui.PaletteText(i, "["+s.Name+"]")
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := streamSiteLogs(s, prefix)
+			switch {
+			case err != nil:
+				ui.SafeWarn("[%s] log stream ended: %v", s.Name, err)
+			case logsFlags.follow:
+				// A followed stream only ends when the site's containers stop.
+				ui.SafePrint("%s %s", prefix, ui.DimText("exited"))
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// mergeLogSites resolves the sites to stream: the named ones (which must
+// exist and not be broken), or every running site when names is empty.
+func mergeLogSites(names []string) ([]site.Site, error) {
+	if len(names) > 0 {
+		sites := make([]site.Site, 0, len(names))
+		for _, name := range names {
+			s, err := site.GetByName(name)
+			if err != nil {
+				return nil, err
+			}
+			if s.IsBroken {
+				return nil, fmt.Errorf("site '%s' is broken (target directory missing)", s.Name)
+			}
+			sites = append(sites, *s)
+		}
+		return sites, nil
+	}
+	all, err := site.List()
+	if err != nil {
+		return nil, err
+	}
+	var running []site.Site
+	for _, s := range all {
+		if !s.IsBroken && s.Status == constants.StatusRunning {
+			running = append(running, s)
+		}
+	}
+	return running, nil
+}
+
+// streamSiteLogs pipes one site's `docker compose logs` into a line scanner
+// and prints each complete line (that --filter keeps) with prefix, so lines
+// from different sites never interleave mid-line.
+func streamSiteLogs(s *site.Site, prefix string) error {
+	pr, pw := io.Pipe()
+	go func() {
+		_ = pw.CloseWithError(docker.ComposeStream(s.ComposeDir, pw, logsComposeArgs(s)...))
+	}()
+	scanner := bufio.NewScanner(pr)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if logsFilter != nil && !keepLogLine(line) {
+			continue
+		}
+		ui.SafePrint("%s %s", prefix, line)
+	}
+	err := scanner.Err()
+	if err != nil {
+		// Drain so the compose process never blocks writing to the pipe.
+		_, _ = io.Copy(io.Discard, pr)
+	}
+	return err
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stubbedev/srv/internal/docker"
+	"github.com/stubbedev/srv/internal/site"
+	"github.com/stubbedev/srv/internal/ui"
+)
+
+func writeMergeTestSites(t *testing.T, names ...string) {
+	t.Helper()
+	root := setupSrvRoot(t)
+	for _, name := range names {
+		dir := filepath.Join(root, "p-"+name)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		writeTestSite(t, name, site.SiteMetadata{
+			Type:        site.SiteTypeStatic,
+			Domains:     []string{name + ".local"},
+			ProjectPath: dir,
+			Port:        80,
+			NetworkName: "n",
+		})
+	}
+}
+
+func TestRunLogsMergedPrefixesLines(t *testing.T) {
+	writeMergeTestSites(t, "api", "web")
+	saved := logsFlags
+	t.Cleanup(func() { logsFlags = saved; logsFilter = nil })
+	logsFlags.follow = true
+	logsFilter = nil
+
+	// Each site writes a partial line first; the prefix must still land once
+	// at the start of the complete line.
+	t.Cleanup(docker.SwapComposeStreamExec(func(dir string, w io.Writer, args ...string) error {
+		name := strings.TrimPrefix(filepath.Base(dir), "p-")
+		if _, err := io.WriteString(w, "hello "); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintf(w, "from %s\nbye\n", name)
+		return err
+	}))
+	var buf bytes.Buffer
+	t.Cleanup(ui.SwapStdout(&buf))
+
+	if err := runLogsMerged([]string{"api", "web"}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"[api] hello from api", "[web] hello from web", "[api] bye", "[web] exited"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+	if n := strings.Count(out, "\n"); n != 6 {
+		t.Errorf("expected 6 lines, got %d:\n%s", n, out)
+	}
+}
+
+func TestRunLogsMergedUnknownSite(t *testing.T) {
+	writeMergeTestSites(t, "api")
+	if err := runLogsMerged([]string{"api", "ghost"}); err == nil {
+		t.Error("expected an error for an unknown site")
+	}
+}
+
+func TestLogsSitesNeedsMergeAll(t *testing.T) {
+	saved := logsFlags
+	t.Cleanup(func() { logsFlags = saved })
+	logsFlags = saved
+	logsFlags.sites = []string{"api"}
+	logsFlags.all, logsFlags.mergeAll = false, false
+	if err := logsCmd.Args(logsCmd, nil); err == nil {
+		t.Error("expected --sites without --merge-all to be rejected")
+	}
+	logsFlags.mergeAll = true
+	if err := logsCmd.Args(logsCmd, nil); err != nil {
+		t.Errorf("--merge-all --sites: %v", err)
+	}
+}
@@ -1,36 +1,67 @@
-// Package cmd — metrics.go implements `srv metrics` for managing the opt-in
-// prometheus + grafana stack scraping Traefik.
+// Package cmd — metrics.go implements `srv metrics`: a traffic summary read
+// straight from Traefik's Prometheus endpoint, and the subcommands managing
+// the opt-in prometheus + grafana stack scraping it.
 package cmd
 
 import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"math"
 	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
 	"time"
 
+	"github.com/mattn/go-isatty"
+
 	"github.com/spf13/cobra"
 
 	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/constants"
 	"github.com/stubbedev/srv/internal/docker"
 	"github.com/stubbedev/srv/internal/metrics"
 	"github.com/stubbedev/srv/internal/traefik"
 	"github.com/stubbedev/srv/internal/ui"
 )
 
+var metricsFlags struct {
+	site  string
+	watch int
+}
+
 var metricsCmd = &cobra.Command{
 	Use:   "metrics",
-	Short: "Manage the optional metrics stack (prometheus + grafana)",
-	Long: `Prometheus scrapes Traefik's existing /metrics endpoint; Grafana ships with
-a pre-wired Prometheus datasource. Both UIs route through Traefik with
-mkcert-signed TLS:
+	Short: "Show per-site traffic, or manage the optional metrics stack (prometheus + grafana)",
+	Long: `Without a subcommand, read Traefik's Prometheus endpoint
+(` + metrics.EndpointURL + `) and summarise each site's traffic: requests per
+second, 4xx and 5xx rates, and p50/p95/p99 latency, plus the connections open
+on Traefik. Rates cover a one-second sample (or the --watch interval); when
+no requests arrived in it, error rates and latencies cover everything since
+Traefik started. Traefik's exporter must be on: 'srv metrics enable' turns it
+on.
+
+The subcommands manage the metrics stack. Prometheus scrapes Traefik's
+/metrics endpoint; Grafana ships with a pre-wired Prometheus datasource. Both
+UIs route through Traefik with mkcert-signed TLS:
 
     Grafana:     https://` + metrics.GrafanaDomain + `   (admin / admin)
     Prometheus:  https://` + metrics.PrometheusDomain + `
 
 Import a Traefik dashboard in Grafana (dashboard ID 17347) to see request
-rates, latency, and error percentages per router.`,
+rates, latency, and error percentages per router.
+
+Examples:
+  srv metrics
+  srv metrics --site blog
+  srv metrics --watch 5
+  srv metrics --json`,
+	Args: cobra.NoArgs,
+	RunE: runMetrics,
 }
 
 var metricsEnableCmd = &cobra.Command{
@@ -55,11 +86,163 @@ var metricsStatusCmd = &cobra.Command{
 }
 
 func init() {
+	metricsCmd.Flags().StringVar(&metricsFlags.site, "site", "", "Only show this site's traffic")
+	metricsCmd.Flags().IntVar(&metricsFlags.watch, "watch", 0, "Refresh every N seconds until interrupted")
+	addJSONFlag(metricsCmd)
+	_ = metricsCmd.RegisterFlagCompletionFunc("site", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return GetSiteNames(), cobra.ShellCompDirectiveNoFileComp
+	})
 	metricsCmd.GroupID = GroupSystem
 	metricsCmd.AddCommand(metricsEnableCmd, metricsDisableCmd, metricsStatusCmd)
 	RootCmd.AddCommand(metricsCmd)
 }
 
+// metricsSampleWindow is how long a one-shot `srv metrics` waits between its
+// two scrapes. Tests shorten it.
+var metricsSampleWindow = time.Second
+
+func runMetrics(cmd *cobra.Command, args []string) error {
+	if metricsFlags.watch < 0 {
+		return ui.UsageError("srv metrics --watch N", "--watch must be a positive number of seconds")
+	}
+	if metricsFlags.watch > 0 && jsonOutput() {
+		return ui.UsageError("srv metrics --watch N", "--watch and --json are mutually exclusive")
+	}
+	names := GetSiteNames()
+	if metricsFlags.site != "" && !slices.Contains(names, metricsFlags.site) {
+		return fmt.Errorf("site %q not found", metricsFlags.site)
+	}
+	group := func(router string) string { return metricsGroup(router, names, metricsFlags.site) }
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	prev, err := metrics.Scrape(ctx)
+	if err != nil {
+		return err
+	}
+	if metricsFlags.watch == 0 {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(metricsSampleWindow):
+		}
+		cur, err := metrics.Scrape(ctx)
+		if err != nil {
+			return err
+		}
+		return printMetrics(metrics.Summarize(prev, cur, group), cur.OpenConnections())
+	}
+
+	// The previous snapshot is kept between refreshes so every frame's rates
+	// cover the interval since the last one.
+	clear := ""
+	if isatty.IsTerminal(os.Stdout.Fd()) {
+		clear = ansiClearHome
+	}
+	ticker := time.NewTicker(time.Duration(metricsFlags.watch) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		cur, err := metrics.Scrape(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			ui.Warn("%v", err)
+			continue
+		}
+		ui.Print("%sLast updated %s (every %ds)", clear, cur.At.Format(time.TimeOnly), metricsFlags.watch)
+		if err := printMetrics(metrics.Summarize(prev, cur, group), cur.OpenConnections()); err != nil {
+			return err
+		}
+		ui.Blank()
+		prev = cur
+	}
+}
+
+// metricsGroup maps a Traefik router label to the row it's counted under: the
+// site for srv's site routers (site-NAME, site-NAME-http and the like; the
+// longest matching site name wins), else the router name without its
+// @provider suffix. Traefik's own @internal routers are dropped, and so is
+// everything but only's site when only is set.
+func metricsGroup(router string, sites []string, only string) string {
+	name, provider, _ := strings.Cut(router, "@")
+	if provider == "internal" || name == "" {
+		return ""
+	}
+	if rest, ok := strings.CutPrefix(name, constants.SiteConfigPrefix); ok {
+		best := ""
+		for _, s := range sites {
+			if (rest == s || strings.HasPrefix(rest, s+"-")) && len(s) > len(best) {
+				best = s
+			}
+		}
+		if best != "" {
+			name = best
+		}
+	}
+	if only != "" && name != only {
+		return ""
+	}
+	return name
+}
+
+// printMetrics renders a traffic summary as a table, or JSON with --json.
+func printMetrics(rows []metrics.Summary, openConns float64) error {
+	if jsonOutput() {
+		return ui.PrintJSON(struct {
+			Sites           []metrics.Summary `json:"sites"`
+			OpenConnections float64           `json:"open_connections"`
+		}{rows, openConns})
+	}
+	if len(rows) == 0 {
+		ui.Dim("No requests recorded yet")
+	} else {
+		table := make([][]string, 0, len(rows))
+		for _, r := range rows {
+			table = append(table, []string{
+				r.Name,
+				fmt.Sprintf("%.2f", r.RequestsPerSec),
+				formatErrorRate(r.ErrorRate4xx, r.Requests),
+				formatErrorRate(r.ErrorRate5xx, r.Requests),
+				formatLatency(r.P50),
+				formatLatency(r.P95),
+				formatLatency(r.P99),
+			})
+		}
+		ui.PrintTable([]string{"SITE", "REQ/S", "4XX", "5XX", "P50", "P95", "P99"}, table)
+	}
+	ui.Dim("Open connections: %.0f", openConns)
+	return nil
+}
+
+func formatErrorRate(pct, requests float64) string {
+	if requests == 0 {
+		return ui.DimText("-")
+	}
+	s := fmt.Sprintf("%.1f%%", pct)
+	if pct > 0 {
+		return ui.WarnText(s)
+	}
+	return s
+}
+
+func formatLatency(ms float64) string {
+	switch {
+	case ms < 0:
+		return ui.DimText("-")
+	case ms >= 1000:
+		return fmt.Sprintf("%.2fs", ms/1000)
+	default:
+		return fmt.Sprintf("%.0fms", math.Round(ms))
+	}
+}
+
 func runMetricsEnable(cmd *cobra.Command, args []string) error {
 	cfg, err := config.Load()
 	if err != nil {
@@ -2,9 +2,13 @@ package cmd
 
 import (
 	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stubbedev/srv/internal/docker"
+	"github.com/stubbedev/srv/internal/metrics"
 	"github.com/stubbedev/srv/internal/mkcert"
 )
 
@@ -71,3 +75,50 @@ func (stubMkcertRunner) Output(args ...string) ([]byte, error) { return []byte("
 func (stubMkcertRunner) Combined(args ...string) ([]byte, error) {
 	return []byte("Created a new local CA"), nil
 }
+
+func TestMetricsGroup(t *testing.T) {
+	sites := []string{"blog", "blog-api"}
+	cases := []struct {
+		router, only, want string
+	}{
+		{"site-blog@file", "", "blog"},
+		{"site-blog-http@file", "", "blog"},
+		{"site-blog-api@file", "", "blog-api"},
+		{"site-blog-api-internal@file", "", "blog-api"},
+		{"proxy-grafana@file", "", "proxy-grafana"},
+		{"api@internal", "", ""},
+		{"site-blog-api@file", "blog", ""},
+		{"site-blog-http@file", "blog", "blog"},
+	}
+	for _, c := range cases {
+		if got := metricsGroup(c.router, sites, c.only); got != c.want {
+			t.Errorf("metricsGroup(%q, only %q) = %q, want %q", c.router, c.only, got, c.want)
+		}
+	}
+}
+
+func TestRunMetricsJSON(t *testing.T) {
+	setupSrvRoot(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`traefik_router_requests_total{code="200",router="proxy-api@file"} 8
+traefik_router_requests_total{code="500",router="proxy-api@file"} 2
+traefik_open_connections{entrypoint="websecure"} 4
+`))
+	}))
+	defer srv.Close()
+	prevURL, prevWindow := metrics.EndpointURL, metricsSampleWindow
+	metrics.EndpointURL, metricsSampleWindow = srv.URL, time.Millisecond
+	t.Cleanup(func() { metrics.EndpointURL, metricsSampleWindow = prevURL, prevWindow })
+
+	var out struct {
+		Sites           []metrics.Summary `json:"sites"`
+		OpenConnections float64           `json:"open_connections"`
+	}
+	runJSONCommand(t, &out, "metrics", "--json")
+	if len(out.Sites) != 1 || out.Sites[0].Name != "proxy-api" || out.Sites[0].ErrorRate5xx != 20 {
+		t.Errorf("sites = %+v", out.Sites)
+	}
+	if out.OpenConnections != 4 {
+		t.Errorf("open connections = %v, want 4", out.OpenConnections)
+	}
+}
@@ -0,0 +1,101 @@
+// Package cmd — migrate.go implements `srv migrate`, which upgrades site
+// metadata.yml files written by older srv versions to the current schema.
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stubbedev/srv/internal/site"
+	"github.com/stubbedev/srv/internal/ui"
+)
+
+var migrateFlags struct {
+	check bool
+}
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade site metadata written by older srv versions",
+	Long: `Upgrade every site's metadata.yml to the schema this srv version writes.
+Sites already on the current schema are left alone. 'srv install' runs this
+automatically.
+
+--check only reports which sites need migrating and exits non-zero if any do.
+
+Examples:
+  srv migrate
+  srv migrate --check`,
+	Args: cobra.NoArgs,
+	RunE: runMigrate,
+}
+
+func init() {
+	migrateCmd.Flags().BoolVar(&migrateFlags.check, "check", false, "Report sites that need migrating without changing anything")
+	migrateCmd.GroupID = GroupSystem
+	RootCmd.AddCommand(migrateCmd)
+}
+
+func runMigrate(cmd *cobra.Command, args []string) error {
+	results, err := site.MigrateSites(migrateFlags.check)
+	if err != nil {
+		return err
+	}
+	if jsonOutput() {
+		if err := ui.PrintJSON(results); err != nil {
+			return err
+		}
+	} else {
+		printMigrations(results, migrateFlags.check)
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.Error != "" {
+			failed++
+		}
+	}
+	switch {
+	case failed > 0:
+		return fmt.Errorf("%d %s could not be migrated", failed, plural(failed, "site", "sites"))
+	case migrateFlags.check && len(results) > 0:
+		return fmt.Errorf("%d %s migrating (run 'srv migrate')", len(results), plural(len(results), "site needs", "sites need"))
+	}
+	return nil
+}
+
+// printMigrations reports each site's migration; check phrases it as pending.
+func printMigrations(results []site.SiteMigration, check bool) {
+	if len(results) == 0 {
+		ui.Success("All sites are on metadata schema %d", site.CurrentMetadataSchema)
+		return
+	}
+	for _, r := range results {
+		switch {
+		case r.Error != "":
+			ui.Warn("%s: %s", r.Name, r.Error)
+		case check:
+			ui.Info("%s needs migrating (schema %d -> %d)", r.Name, r.From, r.To)
+		default:
+			ui.Success("Migrated %s (schema %d -> %d)", r.Name, r.From, r.To)
+		}
+	}
+}
+
+// migrateSitesQuietly runs the migrations as a non-fatal step of another
+// command, warning about sites that fail.
+func migrateSitesQuietly() {
+	results, err := site.MigrateSites(false)
+	if err != nil {
+		ui.Warn("Skipping metadata migration: %v", err)
+		return
+	}
+	for _, r := range results {
+		if r.Error != "" {
+			ui.Warn("Failed to migrate %s: %s", r.Name, r.Error)
+		} else {
+			ui.VerboseLog("Migrated %s metadata (schema %d -> %d)", r.Name, r.From, r.To)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stubbedev/srv/internal/site"
+)
+
+func TestRunMigrate(t *testing.T) {
+	root := setupSrvRoot(t)
+	cfg := mustLoadConfig(t)
+	dir := site.SiteConfigDir(cfg, "old")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	legacy := "type: static\ndomain: old.test\nproject_path: " + root + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "metadata.yml"), []byte(legacy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { migrateFlags.check = false })
+
+	migrateFlags.check = true
+	if err := runMigrate(nil, nil); err == nil || !strings.Contains(err.Error(), "1 site needs") {
+		t.Errorf("check err = %v, want a pending-migration error", err)
+	}
+
+	migrateFlags.check = false
+	if err := runMigrate(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	meta, err := site.ReadSiteMetadata("old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.SchemaVersion != site.CurrentMetadataSchema {
+		t.Errorf("SchemaVersion = %d", meta.SchemaVersion)
+	}
+
+	migrateFlags.check = true
+	if err := runMigrate(nil, nil); err != nil {
+		t.Errorf("check after migrating: %v", err)
+	}
+}
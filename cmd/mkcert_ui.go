@@ -37,11 +37,32 @@ func reportCAInstall(res mkcert.InstallResult, quiet bool) {
 	if !res.BrowserTrustOK && (res.BrowserUnavailable || res.CertutilMissing) {
 		printBrowserTrustHelp()
 	}
-	if res.SystemTrustOK || res.BrowserTrustOK {
+	if platform.IsWSL() {
+		printWindowsTrustHelp(res)
+	}
+	if res.SystemTrustOK || res.BrowserTrustOK || res.WindowsTrustOK {
 		ui.Dim("Restart your browser for the CA to take effect")
 	}
 }
 
+// printWindowsTrustHelp reports whether the CA reached the Windows
+// certificate store under WSL2, where the browsers actually run.
+func printWindowsTrustHelp(res mkcert.InstallResult) {
+	switch {
+	case res.WindowsTrustOK:
+		ui.Dim("mkcert CA also trusted by Windows")
+	case res.WindowsMkcertMissing:
+		ui.Dim("Windows browsers won't trust srv's certificates until mkcert is installed on Windows:")
+		ui.Code("  winget install FiloSottile.mkcert")
+		ui.Dim("Then re-run 'srv install'.")
+	case res.WindowsErr != nil:
+		ui.Dim("Windows trust skipped — %v", res.WindowsErr)
+	default:
+		ui.Dim("Windows trust skipped — mkcert.exe did not report installing the CA.")
+		ui.Dim("Run with --verbose to see mkcert's output.")
+	}
+}
+
 func printSystemTrustHelp(caPath string) {
 	if caPath == "" {
 		caPath = "~/.local/share/mkcert/rootCA.pem"
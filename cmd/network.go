@@ -1,7 +1,9 @@
 // Package cmd — network.go implements `srv network` for attaching a site's
 // container(s) to additional external Docker networks. Used to reach
 // user-managed service containers (MySQL, Redis, Elasticsearch, …) by their
-// container name from inside the site's container.
+// container name from inside the site's container. `srv network list` and
+// `srv network inspect` show the Docker networks and who is attached to them,
+// for diagnosing unreachable services.
 package cmd
 
 import (
@@ -10,20 +12,26 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/docker"
 	"github.com/stubbedev/srv/internal/site"
 	"github.com/stubbedev/srv/internal/ui"
 )
 
 var networkCmd = &cobra.Command{
 	Use:   "network",
-	Short: "Manage extra Docker networks attached to a site",
+	Short: "Manage and inspect the Docker networks sites use",
 	Long: `Attach a site's container(s) to additional external Docker networks so the
 in-container process can reach user-managed containers by name.
 
 Typical use: you run MySQL/Redis/Elasticsearch via your own docker-compose
 elsewhere, and want srv-managed sites to talk to those containers by their
 container hostname (e.g. DB_HOST=mysql01) without falling back to
-host.docker.internal.`,
+host.docker.internal.
+
+'srv network list' and 'srv network inspect' help diagnose "service
+unreachable from Traefik" problems: they show the Docker networks and which
+containers are on them.`,
 }
 
 var networkAttachCmd = &cobra.Command{
@@ -57,18 +65,50 @@ var networkDetachCmd = &cobra.Command{
 }
 
 var networkListCmd = &cobra.Command{
-	Use:   "list SITE",
-	Short: "List extra Docker networks attached to a site",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runNetworkList,
+	Use:   "list [SITE]",
+	Short: "List Docker networks, or the extra networks attached to a site",
+	Long: `List all Docker networks, with srv's own network highlighted. With SITE,
+list the networks that site's container joins instead.
+
+Examples:
+  srv network list
+  srv network list mysite`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runNetworkList,
 	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
 		return GetSiteNames(), cobra.ShellCompDirectiveNoFileComp
 	},
 }
 
+var networkInspectCmd = &cobra.Command{
+	Use:   "inspect [NAME]",
+	Short: "Show the containers on a Docker network",
+	Long: `Show the running containers attached to a Docker network (srv's own
+network when NAME is omitted) with their IP addresses and aliases, and
+which srv site, if any, each belongs to.
+
+On srv's network, containers that no site or proxy claims are highlighted;
+'srv clean' disconnects them.
+
+Examples:
+  srv network inspect
+  srv network inspect mysql_default`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runNetworkInspect,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return getDockerNetworkNames(), cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
 func init() {
 	networkCmd.GroupID = GroupSites
-	networkCmd.AddCommand(networkAttachCmd, networkDetachCmd, networkListCmd)
+	networkCmd.AddCommand(networkAttachCmd, networkDetachCmd, networkListCmd, networkInspectCmd)
 	RootCmd.AddCommand(networkCmd)
 }
 
@@ -113,6 +153,9 @@ type networkListOut struct {
 }
 
 func runNetworkList(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return runNetworkListAll()
+	}
 	siteName := args[0]
 	meta, err := site.ReadSiteMetadata(siteName)
 	if err != nil {
@@ -139,6 +182,126 @@ func runNetworkList(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// runNetworkListAll lists every Docker network, marking srv's own.
+func runNetworkListAll() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	networks, err := docker.ListNetworks()
+	if err != nil {
+		return err
+	}
+	if jsonOutput() {
+		return ui.PrintJSON(networks)
+	}
+	rows := make([][]string, 0, len(networks))
+	for _, n := range networks {
+		name := n.Name
+		if name == cfg.NetworkName {
+			name = ui.AccentText(name + " (srv)")
+		}
+		rows = append(rows, []string{name, n.Driver, n.Scope, shortID(n.ID)})
+	}
+	ui.PrintTable([]string{"NAME", "DRIVER", "SCOPE", "ID"}, rows)
+	return nil
+}
+
+// networkInspectOut is the json shape for `srv network inspect --format json`.
+type networkInspectOut struct {
+	Name       string                  `json:"name"`
+	Driver     string                  `json:"driver"`
+	Containers []networkInspectElement `json:"containers"`
+}
+
+type networkInspectElement struct {
+	docker.NetworkEndpoint
+	Owner string `json:"owner,omitempty"` // site name, "srv" or "proxy"
+}
+
+func runNetworkInspect(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	name := cfg.NetworkName
+	if len(args) > 0 {
+		name = args[0]
+	}
+	detail, err := docker.InspectNetwork(name)
+	if err != nil {
+		return err
+	}
+	sites, err := site.List()
+	if err != nil {
+		return err
+	}
+	claims := newNetworkClaims(sites, proxyContainerNames(cfg))
+	srvNetwork := detail.Name == cfg.NetworkName
+
+	out := networkInspectOut{Name: detail.Name, Driver: detail.Driver, Containers: make([]networkInspectElement, 0, len(detail.Containers))}
+	for _, ep := range detail.Containers {
+		out.Containers = append(out.Containers, networkInspectElement{
+			NetworkEndpoint: ep,
+			Owner:           claims.owner(docker.NetworkContainer{Name: ep.Name, Labels: ep.Labels}),
+		})
+	}
+	if jsonOutput() {
+		return ui.PrintJSON(out)
+	}
+
+	ui.Bold("Network: %s (%s)", detail.Name, detail.Driver)
+	if len(out.Containers) == 0 {
+		ui.Dim("No running containers on %s", detail.Name)
+		return nil
+	}
+	unclaimed := 0
+	rows := make([][]string, 0, len(out.Containers))
+	for _, c := range out.Containers {
+		containerName, owner := c.Name, c.Owner
+		if owner == "" {
+			owner = "-"
+			if srvNetwork {
+				unclaimed++
+				containerName = ui.WarnText(containerName)
+				owner = ui.WarnText("unclaimed")
+			}
+		}
+		ip := c.IPv4
+		if ip == "" {
+			ip = c.IPv6
+		}
+		rows = append(rows, []string{containerName, ip, strings.Join(c.Aliases, ", "), owner})
+	}
+	ui.PrintTable([]string{"CONTAINER", "IP", "ALIASES", "SITE"}, rows)
+	if unclaimed > 0 {
+		ui.Warn("%d %s on %s no site or proxy claims — 'srv clean' disconnects them", unclaimed, plural(unclaimed, "container", "containers"), detail.Name)
+	}
+	return nil
+}
+
+// shortID truncates a Docker ID to the 12 characters docker's own CLI shows.
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+// getDockerNetworkNames returns the Docker network names for shell
+// completion; nil when Docker is unreachable.
+func getDockerNetworkNames() []string {
+	networks, err := docker.ListNetworks()
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(networks))
+	for _, n := range networks {
+		names = append(names, n.Name)
+	}
+	return names
+}
+
 // GetSiteExtraNetworks returns the extra networks attached to a site, for
 // shell completion. Returns nil on lookup error.
 func GetSiteExtraNetworks(siteName string) []string {
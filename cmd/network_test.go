@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stubbedev/srv/internal/docker"
+)
+
+func TestRunNetworkInspectDefaultsToSrvNetwork(t *testing.T) {
+	setupSrvRoot(t)
+	cfg := mustLoadConfig(t)
+	t.Cleanup(docker.SwapNewClientOK())
+	var inspected string
+	t.Cleanup(docker.SwapNetworkInspectOutput(func(_ context.Context, name string) ([]byte, error) {
+		inspected = name
+		return []byte(`{"Name":"` + name + `","Driver":"bridge","Containers":{"x":{"Name":"leftover","IPv4Address":"172.18.0.9/16"}}}`), nil
+	}))
+	if err := runNetworkInspect(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if inspected != cfg.NetworkName {
+		t.Errorf("inspected %q, want %q", inspected, cfg.NetworkName)
+	}
+}
+
+func TestRunNetworkListAll(t *testing.T) {
+	setupSrvRoot(t)
+	cfg := mustLoadConfig(t)
+	t.Cleanup(docker.SwapNewClientWithNetwork(cfg.NetworkName))
+	if err := runNetworkList(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := getDockerNetworkNames(); len(got) != 1 || got[0] != cfg.NetworkName {
+		t.Errorf("network names = %v", got)
+	}
+}
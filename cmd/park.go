@@ -0,0 +1,336 @@
+// Package cmd — park.go implements `srv park`, `srv unpark` and
+// `srv parked`: register directories whose subdirectories are compose
+// projects, list the projects that aren't sites yet, and add them in bulk.
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/site"
+	"github.com/stubbedev/srv/internal/traefik"
+	"github.com/stubbedev/srv/internal/ui"
+)
+
+var parkFlags struct {
+	autoAdd bool
+}
+
+var parkCmd = &cobra.Command{
+	Use:   "park PATH",
+	Short: "Watch a directory for compose projects",
+	Long: `Add PATH to the parked directories and list the compose projects in it:
+every subdirectory holding a docker-compose.yml (or compose.yml), scanned
+'park-depth' levels deep (default 1; see 'srv config set park-depth').
+
+Projects are only listed; pass --auto-add to add each unregistered one as a
+local site at NAME.test, where NAME is the directory name. Added sites are
+not started.
+
+Examples:
+  srv park ~/code
+  srv park ~/code --auto-add`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return ui.UsageError("srv park PATH", "expected a single directory path, got %d argument(s)", len(args))
+		}
+		return nil
+	},
+	RunE: runPark,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return nil, cobra.ShellCompDirectiveFilterDirs
+	},
+}
+
+var unparkCmd = &cobra.Command{
+	Use:   "unpark PATH",
+	Short: "Stop watching a parked directory",
+	Long: `Remove PATH from the parked directories. Sites already added from it are
+left registered; remove them with 'srv remove'.
+
+Examples:
+  srv unpark ~/code`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return ui.UsageError("srv unpark PATH", "expected a single directory path, got %d argument(s)", len(args))
+		}
+		return nil
+	},
+	RunE: runUnpark,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		paths, _ := parkedPaths()
+		return paths, cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+var parkedCmd = &cobra.Command{
+	Use:   "parked",
+	Short: "Show compose projects in parked directories",
+}
+
+var parkedListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List projects in parked directories that aren't sites yet",
+	Long: `List the compose projects found in parked directories that no registered
+site serves. Add one with 'srv add PATH --domain DOMAIN', or all of them with
+'srv park PATH --auto-add'.
+
+Examples:
+  srv parked list
+  srv parked list --json`,
+	Args: cobra.NoArgs,
+	RunE: runParkedList,
+}
+
+var parkedRefreshCmd = &cobra.Command{
+	Use:   "refresh",
+	Short: "Re-scan parked directories and report new or removed projects",
+	Long: `Scan every parked directory again and compare the projects found with the
+previous scan, reporting the ones that appeared and the ones that are gone.
+
+Examples:
+  srv parked refresh`,
+	Args: cobra.NoArgs,
+	RunE: runParkedRefresh,
+}
+
+func init() {
+	parkCmd.Flags().BoolVar(&parkFlags.autoAdd, "auto-add", false, "Add every unregistered project as a local NAME.test site")
+	addJSONFlag(parkedListCmd)
+
+	parkCmd.GroupID = GroupSites
+	unparkCmd.GroupID = GroupSites
+	parkedCmd.GroupID = GroupSites
+	parkedCmd.AddCommand(parkedListCmd, parkedRefreshCmd)
+	RootCmd.AddCommand(parkCmd, unparkCmd, parkedCmd)
+}
+
+// parkedPaths returns the parked directories from config.yml.
+func parkedPaths() ([]string, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	return cfg.GetParkedPaths()
+}
+
+func runPark(cmd *cobra.Command, args []string) error {
+	dir, err := site.ResolvePath(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid path: %w", err)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	paths, err := cfg.GetParkedPaths()
+	if err != nil {
+		return err
+	}
+	if slices.Contains(paths, dir) {
+		ui.Dim("%s is already parked", dir)
+	} else {
+		if err := cfg.SetParkedPaths(append(paths, dir)); err != nil {
+			return err
+		}
+		ui.Success("Parked %s", dir)
+	}
+
+	registered, err := site.RegisteredProjects()
+	if err != nil {
+		return err
+	}
+	projects, err := site.ScanParked(dir, site.ParkDepth(), registered)
+	if err != nil {
+		return err
+	}
+	if err := recordParkedScan(); err != nil {
+		ui.Warn("Could not record the scan: %v", err)
+	}
+	pending := unregisteredProjects(projects)
+	if len(pending) == 0 {
+		ui.Dim("No unregistered compose projects found in %s", dir)
+		return nil
+	}
+
+	if !parkFlags.autoAdd {
+		ui.Info("Found %d unregistered project(s):", len(pending))
+		for _, p := range pending {
+			ui.Print("  %s  %s", p.Name, ui.DimText(p.Path))
+		}
+		ui.Dim("Run 'srv park %s --auto-add' to add them", args[0])
+		return nil
+	}
+
+	var failed int
+	for _, p := range pending {
+		res, err := site.Add(site.AddOptions{
+			Path:   p.Path,
+			Name:   p.Name,
+			Domain: parkedDomain(p),
+			Local:  true,
+			Force:  true,
+		})
+		if err != nil {
+			ui.Warn("Could not add %s: %v", p.Path, err)
+			failed++
+			continue
+		}
+		for _, w := range res.Warnings {
+			ui.Warn("%s", w)
+		}
+		ui.Success("Added '%s' at https://%s", res.Name, res.Domain)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d project(s) could not be added", failed, len(pending))
+	}
+	ui.Dim("Start them with 'srv start --all'")
+	return nil
+}
+
+// parkedDomain is the local domain --auto-add gives a project.
+func parkedDomain(p site.ParkedProject) string {
+	return p.Name + "." + traefik.LocalDomains[0]
+}
+
+func runUnpark(cmd *cobra.Command, args []string) error {
+	dir, err := site.ResolvePath(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid path: %w", err)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	paths, err := cfg.GetParkedPaths()
+	if err != nil {
+		return err
+	}
+	i := slices.Index(paths, dir)
+	if i < 0 {
+		return fmt.Errorf("%s is not parked", dir)
+	}
+	if err := cfg.SetParkedPaths(slices.Delete(paths, i, i+1)); err != nil {
+		return err
+	}
+	if err := recordParkedScan(); err != nil {
+		ui.Warn("Could not record the scan: %v", err)
+	}
+	ui.Success("Unparked %s", dir)
+	return nil
+}
+
+func runParkedList(cmd *cobra.Command, args []string) error {
+	projects, err := scanAllParked()
+	if err != nil {
+		return err
+	}
+	pending := unregisteredProjects(projects)
+	if jsonOutput() {
+		return ui.PrintJSON(pending)
+	}
+	if len(pending) == 0 {
+		ui.Dim("No unregistered projects in parked directories")
+		return nil
+	}
+	rows := make([][]string, 0, len(pending))
+	for _, p := range pending {
+		rows = append(rows, []string{p.Name, p.Path, p.Parked})
+	}
+	ui.PrintTable([]string{"NAME", "PATH", "PARKED IN"}, rows)
+	return nil
+}
+
+func runParkedRefresh(cmd *cobra.Command, args []string) error {
+	previous, err := site.LoadParkedProjects()
+	if err != nil {
+		return err
+	}
+	projects, err := scanAllParked()
+	if err != nil {
+		return err
+	}
+	current := projectPaths(projects)
+	if err := site.SaveParkedProjects(current); err != nil {
+		return err
+	}
+
+	added, removed := site.DiffProjects(previous, current)
+	for _, p := range added {
+		ui.Print("+ %s", p)
+	}
+	for _, p := range removed {
+		ui.Print("- %s", p)
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		ui.Dim("No changes: %d project(s) in parked directories", len(current))
+		return nil
+	}
+	ui.Dim("%d new, %d removed; run 'srv parked list' to see unregistered projects", len(added), len(removed))
+	return nil
+}
+
+// scanAllParked scans every parked directory. A directory that can't be read
+// (moved or deleted since it was parked) is reported and skipped.
+func scanAllParked() ([]site.ParkedProject, error) {
+	paths, err := parkedPaths()
+	if err != nil {
+		return nil, err
+	}
+	registered, err := site.RegisteredProjects()
+	if err != nil {
+		return nil, err
+	}
+	depth := site.ParkDepth()
+	var projects []site.ParkedProject
+	for _, dir := range paths {
+		found, err := site.ScanParked(dir, depth, registered)
+		if err != nil {
+			ui.Warn("Skipping parked directory %s: %v", dir, err)
+			continue
+		}
+		projects = append(projects, found...)
+	}
+	return projects, nil
+}
+
+// recordParkedScan saves the projects in every parked directory as the
+// baseline for the next `srv parked refresh`.
+func recordParkedScan() error {
+	projects, err := scanAllParked()
+	if err != nil {
+		return err
+	}
+	return site.SaveParkedProjects(projectPaths(projects))
+}
+
+func projectPaths(projects []site.ParkedProject) []string {
+	paths := make([]string, 0, len(projects))
+	for _, p := range projects {
+		paths = append(paths, p.Path)
+	}
+	return paths
+}
+
+func unregisteredProjects(projects []site.ParkedProject) []site.ParkedProject {
+	pending := make([]site.ParkedProject, 0, len(projects))
+	for _, p := range projects {
+		if p.Site == "" {
+			pending = append(pending, p)
+		}
+	}
+	return pending
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stubbedev/srv/internal/site"
+)
+
+func TestParkListAndRefresh(t *testing.T) {
+	setupSrvRoot(t)
+	projects := t.TempDir()
+	for _, name := range []string{"api", "web"} {
+		if err := os.MkdirAll(filepath.Join(projects, name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(projects, name, "compose.yml"), []byte("services: {}\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stdout, _ := executeRoot(t, "park", projects)
+	if !strings.Contains(stdout, "api") || !strings.Contains(stdout, "web") {
+		t.Errorf("srv park output %q does not list both projects", stdout)
+	}
+	if paths, _ := mustLoadConfig(t).GetParkedPaths(); !slices.Equal(paths, []string{projects}) {
+		t.Errorf("parked paths = %v, want [%s]", paths, projects)
+	}
+
+	var pending []site.ParkedProject
+	runJSONCommand(t, &pending, "parked", "list", "--json")
+	if len(pending) != 2 || pending[0].Name != "api" || pending[1].Path != filepath.Join(projects, "web") {
+		t.Errorf("parked list = %+v", pending)
+	}
+
+	if err := os.RemoveAll(filepath.Join(projects, "web")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(projects, "docs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(projects, "docs", "docker-compose.yml"), []byte("services: {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stdout, _ = executeRoot(t, "parked", "refresh")
+	if !strings.Contains(stdout, "+ "+filepath.Join(projects, "docs")) || !strings.Contains(stdout, "- "+filepath.Join(projects, "web")) {
+		t.Errorf("srv parked refresh output %q, want docs added and web removed", stdout)
+	}
+
+	executeRoot(t, "unpark", projects)
+	if paths, _ := mustLoadConfig(t).GetParkedPaths(); len(paths) != 0 {
+		t.Errorf("parked paths after unpark = %v, want none", paths)
+	}
+}
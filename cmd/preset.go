@@ -0,0 +1,159 @@
+// Package cmd — preset.go implements `srv preset`, which manages middleware
+// presets: named bundles of Traefik middleware settings applied to new sites
+// with `srv add --middleware-preset NAME`.
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stubbedev/srv/internal/preset"
+	"github.com/stubbedev/srv/internal/ui"
+)
+
+var presetCreateFlags struct {
+	compress      bool
+	rateLimit     string
+	addHeaders    []string
+	removeHeaders []string
+	allowIPs      []string
+	middlewares   []string
+	force         bool
+}
+
+var presetCmd = &cobra.Command{
+	Use:   "preset",
+	Short: "Manage middleware presets for new sites",
+	Long: `Manage middleware presets: named bundles of the Traefik settings 'srv add'
+takes (compression, rate limit, response headers, IP allowlist and custom
+middlewares), stored in ~/.config/srv/presets/NAME.yml.
+
+Apply one with 'srv add PATH --middleware-preset NAME'. The settings are
+copied into the site, so changing or deleting a preset later leaves existing
+sites as they are.`,
+}
+
+var presetCreateCmd = &cobra.Command{
+	Use:   "create NAME",
+	Short: "Create a middleware preset",
+	Long: `Create the middleware preset NAME from the given settings. At least one
+setting is required; --force replaces an existing preset.
+
+Examples:
+  srv preset create api --compress --rate-limit 100-S --add-header "X-Env: dev"
+  srv preset create office --allow-ip 192.168.1.0/24 --remove-header X-Powered-By`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return ui.UsageError("srv preset create NAME [flags]", "expected a preset name, got %d argument(s)", len(args))
+		}
+		return nil
+	},
+	RunE: runPresetCreate,
+}
+
+var presetListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List middleware presets",
+	Long: `List the middleware presets and a summary of their settings.
+
+Examples:
+  srv preset list
+  srv preset list --json`,
+	Args: cobra.NoArgs,
+	RunE: runPresetList,
+}
+
+var presetDeleteCmd = &cobra.Command{
+	Use:   "delete NAME",
+	Short: "Delete a middleware preset",
+	Long: `Delete the middleware preset NAME. Sites added with it keep its settings.
+
+Examples:
+  srv preset delete api`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return ui.UsageError("srv preset delete NAME", "expected a preset name, got %d argument(s)", len(args))
+		}
+		return nil
+	},
+	RunE: runPresetDelete,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return preset.Names(), cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+func init() {
+	f := presetCreateCmd.Flags()
+	f.BoolVar(&presetCreateFlags.compress, "compress", false, "Compress responses at Traefik")
+	f.StringVar(&presetCreateFlags.rateLimit, "rate-limit", "", "Per-client rate limit as AVERAGE-UNIT, e.g. 100-S; units S, M, H")
+	f.StringArrayVar(&presetCreateFlags.addHeaders, "add-header", nil, `Response header to set, as "Name: Value" (repeatable)`)
+	f.StringSliceVar(&presetCreateFlags.removeHeaders, "remove-header", nil, "Response header to strip, e.g. X-Powered-By (repeatable)")
+	f.StringSliceVar(&presetCreateFlags.allowIPs, "allow-ip", nil, "Only allow clients from these CIDRs (repeatable)")
+	f.StringSliceVar(&presetCreateFlags.middlewares, "middleware", nil, "Traefik middleware to attach to the site's router (repeatable)")
+	f.BoolVarP(&presetCreateFlags.force, "force", "f", false, "Replace an existing preset")
+	addJSONFlag(presetListCmd)
+
+	presetCmd.GroupID = GroupSites
+	presetCmd.AddCommand(presetCreateCmd, presetListCmd, presetDeleteCmd)
+	RootCmd.AddCommand(presetCmd)
+}
+
+func runPresetCreate(cmd *cobra.Command, args []string) error {
+	addHeaders, err := parseHeaderSpecs(presetCreateFlags.addHeaders)
+	if err != nil {
+		return err
+	}
+	p := preset.Preset{
+		Name:          args[0],
+		Compress:      presetCreateFlags.compress,
+		RateLimit:     presetCreateFlags.rateLimit,
+		AddHeaders:    addHeaders,
+		RemoveHeaders: canonicalHeaderNames(presetCreateFlags.removeHeaders),
+		AllowIPs:      presetCreateFlags.allowIPs,
+		Middlewares:   presetCreateFlags.middlewares,
+	}
+	if p.Empty() {
+		return ui.UsageError("srv preset create NAME [flags]", "a preset needs at least one setting (e.g. --compress or --rate-limit 100-S)")
+	}
+	if err := preset.Write(p, presetCreateFlags.force); err != nil {
+		return err
+	}
+	ui.Success("Created preset '%s': %s", p.Name, p.Summary())
+	ui.Dim("Apply it with 'srv add PATH --domain DOMAIN --middleware-preset %s'", p.Name)
+	return nil
+}
+
+func runPresetList(cmd *cobra.Command, args []string) error {
+	presets, err := preset.List()
+	if err != nil {
+		return err
+	}
+	if jsonOutput() {
+		if presets == nil {
+			presets = []preset.Preset{}
+		}
+		return ui.PrintJSON(presets)
+	}
+	if len(presets) == 0 {
+		ui.Dim("No presets; create one with 'srv preset create NAME'")
+		return nil
+	}
+	rows := make([][]string, 0, len(presets))
+	for _, p := range presets {
+		rows = append(rows, []string{p.Name, p.Summary()})
+	}
+	ui.PrintTable([]string{"NAME", "SETTINGS"}, rows)
+	return nil
+}
+
+func runPresetDelete(cmd *cobra.Command, args []string) error {
+	if err := preset.Delete(args[0]); err != nil {
+		return fmt.Errorf("delete preset: %w", err)
+	}
+	ui.Success("Deleted preset '%s'", args[0])
+	return nil
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stubbedev/srv/internal/preset"
+	"github.com/stubbedev/srv/internal/ui"
+)
+
+func TestPresetCreateListDelete(t *testing.T) {
+	setupSrvRoot(t)
+	saved := presetCreateFlags
+	t.Cleanup(func() { presetCreateFlags = saved })
+
+	if err := runPresetCreate(nil, []string{"api"}); err == nil {
+		t.Error("expected an error creating an empty preset")
+	}
+	presetCreateFlags.compress = true
+	presetCreateFlags.rateLimit = "100-S"
+	presetCreateFlags.addHeaders = []string{"x-env: dev"}
+	presetCreateFlags.removeHeaders = []string{"x-powered-by"}
+	if err := runPresetCreate(nil, []string{"api"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var presets []preset.Preset
+	runJSONCommand(t, &presets, "preset", "list", "--json")
+	if len(presets) != 1 || presets[0].Name != "api" || presets[0].AddHeaders["X-Env"] != "dev" || presets[0].RemoveHeaders[0] != "X-Powered-By" {
+		t.Errorf("preset list = %+v", presets)
+	}
+
+	jsonFlag = false
+	var stdout bytes.Buffer
+	restore := ui.SwapStdout(&stdout)
+	err := runPresetList(nil, nil)
+	restore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout.String(), "rate limit 100-S") {
+		t.Errorf("preset list output %q lacks the summary", stdout.String())
+	}
+
+	if err := runPresetDelete(nil, []string{"api"}); err != nil {
+		t.Fatal(err)
+	}
+	if names := preset.Names(); len(names) != 0 {
+		t.Errorf("presets after delete = %v", names)
+	}
+}
@@ -0,0 +1,122 @@
+// Package cmd — profile.go implements `srv profile`, which lists the Docker
+// Compose profiles a compose site's compose file declares and switches the
+// one the site starts with. `srv start --profile` overrides it for one start.
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/stubbedev/srv/internal/site"
+	"github.com/stubbedev/srv/internal/ui"
+)
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "List and switch a compose site's Docker Compose profile",
+	Long: `List the Docker Compose profiles a compose site's compose file declares,
+and switch the profile the site starts with without re-adding it.
+
+Use 'srv start SITE --profile NAME' to start under another profile once.`,
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list SITE",
+	Short: "List the profiles declared in a site's compose file",
+	Long: `List every profile declared across the services of a compose site's
+compose file. The site's current profile is marked.
+
+Examples:
+  srv profile list mysite`,
+	Args: cobra.ExactArgs(1),
+	RunE: runProfileList,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return GetSiteNames(), cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+var profileSetCmd = &cobra.Command{
+	Use:   "set SITE PROFILE",
+	Short: "Set the profile a site starts with",
+	Long: `Record PROFILE as the Docker Compose profile the site starts with. Pass
+an empty PROFILE to start without one. Takes effect on the next start.
+
+Examples:
+  srv profile set mysite debug
+  srv profile set mysite ""`,
+	Args: cobra.ExactArgs(2),
+	RunE: runProfileSet,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		switch len(args) {
+		case 0:
+			return GetSiteNames(), cobra.ShellCompDirectiveNoFileComp
+		case 1:
+			profiles, _ := site.ComposeProfiles(args[0])
+			return profiles, cobra.ShellCompDirectiveNoFileComp
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+func init() {
+	profileCmd.GroupID = GroupSites
+	profileCmd.AddCommand(profileListCmd, profileSetCmd)
+	RootCmd.AddCommand(profileCmd)
+}
+
+// profileListRow is the json shape for one entry under `srv profile list --format json`.
+type profileListRow struct {
+	Name    string `json:"name"`
+	Current bool   `json:"current"`
+}
+
+func runProfileList(cmd *cobra.Command, args []string) error {
+	profiles, err := site.ComposeProfiles(args[0])
+	if err != nil {
+		return err
+	}
+	meta, err := site.ReadSiteMetadata(args[0])
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		out := make([]profileListRow, 0, len(profiles))
+		for _, p := range profiles {
+			out = append(out, profileListRow{Name: p, Current: p == meta.Profile})
+		}
+		return ui.PrintJSON(out)
+	}
+	if len(profiles) == 0 {
+		ui.Dim("%s's compose file declares no profiles.", args[0])
+		return nil
+	}
+	for _, p := range profiles {
+		if p == meta.Profile {
+			ui.Print("%s %s", p, ui.DimText("(current)"))
+		} else {
+			ui.Print("%s", p)
+		}
+	}
+	return nil
+}
+
+func runProfileSet(cmd *cobra.Command, args []string) error {
+	siteName, profile := args[0], args[1]
+	warnings, err := site.SetProfile(siteName, profile)
+	if err != nil {
+		return err
+	}
+	for _, w := range warnings {
+		ui.Warn("%s", w)
+	}
+	if profile == "" {
+		ui.Success("%s now starts without a profile", siteName)
+	} else {
+		ui.Success("%s now starts with profile %s", siteName, profile)
+	}
+	ui.Dim("Run 'srv restart %s' for the change to take effect.", siteName)
+	return nil
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stubbedev/srv/internal/site"
+)
+
+func TestRunProfileListAndSet(t *testing.T) {
+	root := setupSrvRoot(t)
+	projectDir := filepath.Join(root, "p")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	compose := "services:\n  web:\n    image: nginx\n    profiles: [dev, debug]\n  db:\n    image: postgres\n    profiles: [dev, seed]\n"
+	if err := os.WriteFile(filepath.Join(projectDir, "compose.yaml"), []byte(compose), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	writeTestSite(t, "app", site.SiteMetadata{
+		Type:               site.SiteTypeCompose,
+		Domains:            []string{"app.test"},
+		ProjectPath:        projectDir,
+		ComposeServiceName: "web",
+		Profile:            "dev",
+	})
+
+	if err := runProfileSet(nil, []string{"app", "debug"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := runProfileSet(nil, []string{"app", "prod"}); err == nil {
+		t.Error("expected an error for an undeclared profile")
+	}
+
+	stdout, _ := executeRoot(t, "profile", "list", "app", "--format", "json")
+	var rows []profileListRow
+	if err := json.Unmarshal([]byte(stdout), &rows); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout)
+	}
+	want := []profileListRow{{"debug", true}, {"dev", false}, {"seed", false}}
+	if len(rows) != len(want) {
+		t.Fatalf("rows = %+v, want %+v", rows, want)
+	}
+	for i := range want {
+		if rows[i] != want[i] {
+			t.Errorf("rows[%d] = %+v, want %+v", i, rows[i], want[i])
+		}
+	}
+}
@@ -3,9 +3,9 @@ package cmd
 import (
 	"fmt"
 	"net"
-	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -20,6 +20,7 @@ import (
 	"github.com/stubbedev/srv/internal/site"
 	"github.com/stubbedev/srv/internal/traefik"
 	"github.com/stubbedev/srv/internal/ui"
+	"github.com/stubbedev/srv/internal/validate"
 )
 
 // =============================================================================
@@ -42,6 +43,11 @@ var proxyAddCmd = &cobra.Command{
 	Short: "Add a proxy",
 	Long: `Create a proxy from a local domain to a localhost port or Docker container.
 
+With several --backend targets, --lb-method picks how requests are spread:
+wrr (weighted round robin, the default) or leastconn (Traefik's p2c, which
+sends each request to the less-loaded of two random backends). drr (dynamic
+round robin) is not available: Traefik dropped it in v2.
+
 Examples:
   # Proxy to a localhost port
   srv proxy add --domain api.test --port 3000
@@ -49,7 +55,29 @@ Examples:
 
   # Proxy to a Docker container (container_name:port)
   srv proxy add --domain api.test --container myapp:3000
-  srv proxy add -d myapp.test -c postgres:5432`,
+  srv proxy add -d myapp.test -c postgres:5432
+
+  # Load-balance across several instances (localhost ports or containers)
+  srv proxy add --domain api.test --backend localhost:3001 --backend localhost:3002
+  srv proxy add --domain api.test --backend app1:80 --backend app2:80 --lb-method leastconn
+
+  # Route only a path prefix (optionally stripping it before forwarding)
+  srv proxy add --domain app.test --name app-api --port 4000 --path-prefix /api
+  srv proxy add --domain app.test --name app-api --port 4000 --strip-prefix /api
+
+  # Give a slow upstream (debugger, cold build) longer than Traefik's default
+  srv proxy add --domain api.test --port 3000 --timeout 5m
+
+  # WebSocket-only upstream (upgrade headers, hour-long idle connections)
+  srv proxy add --domain ws.test --port 8081 --websocket
+
+  # gRPC upstream over cleartext HTTP/2 (h2c), or over TLS
+  srv proxy add --domain grpc.test --port 50051 --grpc
+  srv proxy add --domain grpc.test --port 50051 --grpc-tls
+
+  # TCP service routed by TLS SNI on its own port (clients connect with TLS)
+  srv proxy add --tcp --domain redis.test --port 6379 --entrypoint-port 6380
+  srv proxy add --tcp --domain db.test --container postgres:5432 --entrypoint-port 5433 --passthrough`,
 	PreRunE: func(cmd *cobra.Command, args []string) error {
 		if proxyAddFlags.domain == "" {
 			_ = cmd.Help()
@@ -76,7 +104,36 @@ var proxyRemoveCmd = &cobra.Command{
 	},
 	RunE: runProxyRemove,
 	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		return getProxyNames(), cobra.ShellCompDirectiveNoFileComp
+		return proxyNameCompletions(), cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+var proxyUpdateCmd = &cobra.Command{
+	Use:   "update NAME",
+	Short: "Change a proxy's target",
+	Long: `Point an existing proxy at a different localhost port or container.
+
+The domain, certificate, and DNS registration are kept as-is.
+
+Examples:
+  srv proxy update api-test --port 3001
+  srv proxy update api-test --container myapp:8080`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			_ = cmd.Help()
+			return ui.UsageError("srv proxy update NAME --port PORT", "a proxy name is required")
+		}
+		if len(args) > 1 {
+			return ui.UsageError("srv proxy update NAME --port PORT", "too many arguments — expected a single proxy name, got %d", len(args))
+		}
+		return nil
+	},
+	RunE: runProxyUpdate,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return proxyNameCompletions(), cobra.ShellCompDirectiveNoFileComp
 	},
 }
 
@@ -96,12 +153,34 @@ var proxyAddFlags struct {
 	wildcard        bool
 	fallbackURL     string
 	fallbackTimeout string
+	backends        []string
+	lbMethod        string
+	pathPrefix      string
+	stripPrefix     string
+	timeout         string
+	readTimeout     string
+	dialTimeout     string
+	websocket       bool
+	grpc            bool
+	grpcTLS         bool
+	tcp             bool
+	entrypointPort  int
+	passthrough     bool
+}
+
+var proxyUpdateFlags struct {
+	port      string
+	container string
 }
 
 func init() {
 	proxyCmd.AddCommand(proxyAddCmd)
 	proxyCmd.AddCommand(proxyRemoveCmd)
+	proxyCmd.AddCommand(proxyUpdateCmd)
 	proxyCmd.AddCommand(proxyListCmd)
+	proxyCmd.AddCommand(proxyInfoCmd)
+	proxyCmd.AddCommand(proxyDisableCmd)
+	proxyCmd.AddCommand(proxyEnableCmd)
 
 	proxyAddCmd.Flags().StringVarP(&proxyAddFlags.domain, "domain", "d", "", "Domain name (e.g., api.test)")
 	proxyAddCmd.Flags().StringVarP(&proxyAddFlags.port, "port", "p", "", "Localhost port to proxy to")
@@ -110,10 +189,33 @@ func init() {
 	proxyAddCmd.Flags().BoolVarP(&proxyAddFlags.force, "force", "f", false, "Overwrite existing proxy configuration")
 	proxyAddCmd.Flags().BoolVar(&proxyAddFlags.wildcard, "wildcard", false, "Also match one-level subdomains (e.g. *.foo.test)")
 	proxyAddCmd.Flags().StringVar(&proxyAddFlags.fallbackURL, "fallback", "", "URL to proxy to when the primary upstream returns 5xx (e.g. https://prod.example.com)")
+	proxyAddCmd.Flags().StringArrayVar(&proxyAddFlags.backends, "backend", nil, "Upstream HOST:PORT to load-balance across (localhost:PORT or container:PORT); repeatable")
+	proxyAddCmd.Flags().StringVar(&proxyAddFlags.lbMethod, "lb-method", "", "Load-balancing method across backends: wrr or leastconn (default wrr); drr isn't available since Traefik v2")
+	_ = proxyAddCmd.RegisterFlagCompletionFunc("lb-method", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{traefik.LBMethodWRR, traefik.LBMethodLeastConn}, cobra.ShellCompDirectiveNoFileComp
+	})
+	proxyAddCmd.Flags().StringVar(&proxyAddFlags.pathPrefix, "path-prefix", "", "Only route requests under this path (e.g. /api)")
+	proxyAddCmd.Flags().StringVar(&proxyAddFlags.stripPrefix, "strip-prefix", "", "Route requests under this path and strip it before forwarding (e.g. /api)")
+	proxyAddCmd.Flags().StringVar(&proxyAddFlags.timeout, "timeout", "", "Time to wait for the upstream's response headers (e.g. 5m)")
+	proxyAddCmd.Flags().StringVar(&proxyAddFlags.readTimeout, "read-timeout", "", "Idle time on an HTTP/2 upstream connection before Traefik pings it (e.g. 30s)")
+	proxyAddCmd.Flags().StringVar(&proxyAddFlags.dialTimeout, "dial-timeout", "", "How long Traefik waits to open a connection to the upstream (e.g. 10s)")
+	proxyAddCmd.Flags().BoolVar(&proxyAddFlags.websocket, "websocket", false, "Inject WebSocket upgrade headers and keep idle connections open for an hour")
+	proxyAddCmd.Flags().BoolVar(&proxyAddFlags.grpc, "grpc", false, "Proxy a gRPC upstream over cleartext HTTP/2 (h2c)")
+	proxyAddCmd.Flags().BoolVar(&proxyAddFlags.grpcTLS, "grpc-tls", false, "Proxy a gRPC upstream over HTTP/2 with TLS")
+	proxyAddCmd.Flags().BoolVar(&proxyAddFlags.tcp, "tcp", false, "Proxy a non-HTTP TCP service, routed by TLS SNI (needs --entrypoint-port)")
+	proxyAddCmd.Flags().IntVar(&proxyAddFlags.entrypointPort, "entrypoint-port", 0, "Port Traefik listens on for a --tcp proxy (not 80, 443, 88, 8080 or 53)")
+	proxyAddCmd.Flags().BoolVar(&proxyAddFlags.passthrough, "passthrough", false, "Forward the TLS stream of a --tcp proxy to the upstream instead of terminating it")
 	proxyAddCmd.Flags().StringVar(&proxyAddFlags.fallbackTimeout, "fallback-timeout", "2s", "Connect timeout to the primary upstream before falling back")
 	_ = proxyAddCmd.MarkFlagRequired("domain")
 
+	addJSONFlag(proxyListCmd)
+
+	proxyUpdateCmd.Flags().StringVarP(&proxyUpdateFlags.port, "port", "p", "", "New localhost port to proxy to")
+	proxyUpdateCmd.Flags().StringVarP(&proxyUpdateFlags.container, "container", "c", "", "New Docker container to proxy to (container:port)")
+
 	proxyCmd.GroupID = GroupProxy
+	proxyInfoCmd.Flags().IntVar(&proxyInfoFlags.requests, "requests", 0, "Also show the last N requests from the access log")
+
 	RootCmd.AddCommand(proxyCmd)
 }
 
@@ -130,6 +232,13 @@ type proxyInput struct {
 	containerPort string
 	isContainer   bool
 	wildcard      bool
+	backends      []string // extra HOST:PORT upstreams load-balanced with the primary
+	lbMethod      string
+	pathPrefix    string // only route requests under this path
+	stripPrefix   bool   // strip pathPrefix before forwarding
+	timeouts      traefik.ProxyTimeouts
+	websocket     bool
+	grpc          string // traefik.GRPCH2C or traefik.GRPCTLS; "" for HTTP
 }
 
 // validateProxyInput validates and parses proxy add command inputs.
@@ -137,14 +246,35 @@ func validateProxyInput() (*proxyInput, error) {
 	domain := proxyAddFlags.domain
 	port := proxyAddFlags.port
 	container := proxyAddFlags.container
+	backends := proxyAddFlags.backends
 
 	// Validate that either port or container is provided, but not both
-	if port == "" && container == "" {
-		return nil, fmt.Errorf("either --port or --container must be specified")
-	}
 	if port != "" && container != "" {
 		return nil, fmt.Errorf("--port and --container are mutually exclusive")
 	}
+	if port == "" && container == "" {
+		if len(backends) == 0 {
+			return nil, fmt.Errorf("either --port, --container or --backend must be specified")
+		}
+		// The first backend stands in as the primary target.
+		port, container = splitProxyBackend(backends[0])
+		backends = backends[1:]
+	}
+	grpc, err := proxyGRPCMode()
+	if err != nil {
+		return nil, err
+	}
+	if len(backends) > 0 && proxyAddFlags.fallbackURL != "" {
+		return nil, fmt.Errorf("--fallback cannot be combined with multiple backends")
+	}
+	if proxyAddFlags.lbMethod != "" {
+		if len(backends) == 0 {
+			return nil, fmt.Errorf("--lb-method needs at least two backends")
+		}
+		if err := traefik.ValidateLBMethod(proxyAddFlags.lbMethod); err != nil {
+			return nil, fmt.Errorf("invalid --lb-method: %w", err)
+		}
+	}
 
 	// Validate domain
 	if err := ValidateDomain(domain); err != nil {
@@ -152,49 +282,123 @@ func validateProxyInput() (*proxyInput, error) {
 	}
 
 	input := &proxyInput{
-		domain:   domain,
-		wildcard: proxyAddFlags.wildcard,
+		domain:    domain,
+		wildcard:  proxyAddFlags.wildcard,
+		backends:  backends,
+		lbMethod:  proxyAddFlags.lbMethod,
+		websocket: proxyAddFlags.websocket,
+		grpc:      grpc,
+	}
+	if err := parseProxyPathPrefix(input, proxyAddFlags.pathPrefix, proxyAddFlags.stripPrefix); err != nil {
+		return nil, err
+	}
+	for _, t := range []struct{ flag, value string }{
+		{"--timeout", proxyAddFlags.timeout},
+		{"--read-timeout", proxyAddFlags.readTimeout},
+		{"--dial-timeout", proxyAddFlags.dialTimeout},
+	} {
+		if t.value == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(t.value); err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid %s %q: expected a positive duration like 30s or 5m", t.flag, t.value)
+		}
+	}
+	input.timeouts = traefik.ProxyTimeouts{
+		Response: proxyAddFlags.timeout,
+		Read:     proxyAddFlags.readTimeout,
+		Dial:     proxyAddFlags.dialTimeout,
+	}
+	if err := parseProxyTarget(input, port, container); err != nil {
+		return nil, err
+	}
+	for _, b := range backends {
+		bport, bcontainer := splitProxyBackend(b)
+		if err := parseProxyTarget(&proxyInput{}, bport, bcontainer); err != nil {
+			return nil, fmt.Errorf("backend %q: %w", b, err)
+		}
+	}
+
+	// Derive name from domain if not provided
+	name := proxyAddFlags.name
+	if name == "" {
+		// Use SanitizeName for consistency with site add (dots become dashes)
+		name = site.SanitizeName(domain)
+	}
+
+	if err := ValidateProxyName(name); err != nil {
+		return nil, fmt.Errorf("invalid proxy name: %w", err)
 	}
+	if err := proxy.CheckHTTPName(name); err != nil {
+		return nil, err
+	}
+	input.name = name
+
+	return input, nil
+}
 
+// parseProxyTarget validates the --port / --container target (the caller has
+// already checked that exactly one is set) and records it on input.
+func parseProxyTarget(input *proxyInput, port, container string) error {
 	// Parse container flag (format: container_name:port)
 	if container != "" {
 		parts := strings.SplitN(container, ":", 2)
 		if len(parts) != 2 {
-			return nil, fmt.Errorf("invalid container format. Use: container_name:port (e.g., myapp:3000)")
+			return fmt.Errorf("invalid container format. Use: container_name:port (e.g., myapp:3000)")
 		}
 		input.containerName = parts[0]
 		input.containerPort = parts[1]
 		input.isContainer = true
 
 		if err := ValidatePortString(input.containerPort); err != nil {
-			return nil, fmt.Errorf("invalid container port: %w", err)
+			return fmt.Errorf("invalid container port: %w", err)
 		}
 
 		// Check if container exists
 		if !docker.ContainerExists(input.containerName) {
-			return nil, fmt.Errorf("container '%s' does not exist", input.containerName)
-		}
-	} else {
-		// Validate localhost port
-		if err := ValidatePortString(port); err != nil {
-			return nil, fmt.Errorf("invalid port: %w", err)
+			return fmt.Errorf("container '%s' does not exist", input.containerName)
 		}
-		input.port = port
+		return nil
 	}
 
-	// Derive name from domain if not provided
-	name := proxyAddFlags.name
-	if name == "" {
-		// Use SanitizeName for consistency with site add (dots become dashes)
-		name = site.SanitizeName(domain)
+	// Validate localhost port
+	if err := ValidatePortString(port); err != nil {
+		return fmt.Errorf("invalid port: %w", err)
 	}
+	input.port = port
+	return nil
+}
 
-	if err := ValidateProxyName(name); err != nil {
-		return nil, fmt.Errorf("invalid proxy name: %w", err)
+// parseProxyPathPrefix validates the --path-prefix / --strip-prefix flags
+// (at most one may be set) and records the normalized prefix on input.
+func parseProxyPathPrefix(input *proxyInput, pathPrefix, stripPrefix string) error {
+	if pathPrefix != "" && stripPrefix != "" {
+		return fmt.Errorf("--path-prefix and --strip-prefix are mutually exclusive")
 	}
-	input.name = name
+	prefix := pathPrefix
+	if stripPrefix != "" {
+		prefix = stripPrefix
+		input.stripPrefix = true
+	}
+	if prefix == "" {
+		return nil
+	}
+	prefix, err := validate.PathPrefix(prefix)
+	if err != nil {
+		return err
+	}
+	input.pathPrefix = prefix
+	return nil
+}
 
-	return input, nil
+// splitProxyBackend turns a --backend HOST:PORT into the equivalent --port
+// (localhost backends) or --container (anything else) value.
+func splitProxyBackend(spec string) (port, container string) {
+	host, p, ok := strings.Cut(spec, ":")
+	if ok && (host == "localhost" || host == constants.LocalhostIP) {
+		return p, ""
+	}
+	return "", spec
 }
 
 // =============================================================================
@@ -253,6 +457,53 @@ func connectProxyContainer(input *proxyInput, cfg *config.Config) (string, error
 	return fmt.Sprintf("http://%s:%s", input.containerName, input.containerPort), nil
 }
 
+// resolveProxyBackends connects each extra backend like the primary target and
+// returns their upstream URLs.
+func resolveProxyBackends(input *proxyInput, cfg *config.Config) ([]string, error) {
+	urls := make([]string, 0, len(input.backends))
+	for _, b := range input.backends {
+		backend := &proxyInput{}
+		bport, bcontainer := splitProxyBackend(b)
+		if err := parseProxyTarget(backend, bport, bcontainer); err != nil {
+			return nil, fmt.Errorf("backend %q: %w", b, err)
+		}
+		u, err := connectProxyContainer(backend, cfg)
+		if err != nil {
+			return nil, err
+		}
+		urls = append(urls, u)
+	}
+	return urls, nil
+}
+
+// proxyGRPCMode returns the gRPC mode --grpc or --grpc-tls selects.
+func proxyGRPCMode() (string, error) {
+	f := proxyAddFlags
+	switch {
+	case f.grpc && f.grpcTLS:
+		return "", fmt.Errorf("--grpc and --grpc-tls are mutually exclusive")
+	case (f.grpc || f.grpcTLS) && f.websocket:
+		return "", fmt.Errorf("--grpc and --websocket are mutually exclusive")
+	case (f.grpc || f.grpcTLS) && f.fallbackURL != "":
+		return "", fmt.Errorf("--grpc cannot be combined with --fallback (the fallback sidecar only speaks HTTP/1.1)")
+	case f.grpc:
+		return traefik.GRPCH2C, nil
+	case f.grpcTLS:
+		return traefik.GRPCTLS, nil
+	}
+	return "", nil
+}
+
+// proxyAddUsesCLIFeatures reports whether any proxy add flag outside the
+// shared proxy.Add flow is set.
+func proxyAddUsesCLIFeatures() bool {
+	f := proxyAddFlags
+	return f.fallbackURL != "" || len(f.backends) > 0 ||
+		f.pathPrefix != "" || f.stripPrefix != "" ||
+		f.timeout != "" || f.readTimeout != "" || f.dialTimeout != "" ||
+		f.websocket || f.grpc || f.grpcTLS
+}
+
 // =============================================================================
 // Proxy Command Handlers
 // =============================================================================
@@ -262,11 +513,18 @@ func runProxyAdd(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	if proxyAddFlags.tcp {
+		return runTCPProxyAdd(cfg)
+	}
+	if proxyAddFlags.entrypointPort != 0 || proxyAddFlags.passthrough {
+		return fmt.Errorf("--entrypoint-port and --passthrough only apply to --tcp proxies")
+	}
 
 	// The standard (no-fallback) flow is shared with the MCP add_proxy tool via
-	// internal/proxy.Add. The --fallback sidecar is a CLI-only feature handled
-	// inline below because it rewrites the target before the config is written.
-	if proxyAddFlags.fallbackURL == "" {
+	// internal/proxy.Add. The --fallback sidecar, multiple backends, path
+	// prefixes, timeouts, WebSocket tuning and gRPC are CLI-only features handled
+	// inline below.
+	if !proxyAddUsesCLIFeatures() {
 		res, err := proxy.Add(cfg, proxy.AddSpec{
 			Name:      proxyAddFlags.name,
 			Domain:    proxyAddFlags.domain,
@@ -297,6 +555,9 @@ func runProxyAdd(cmd *cobra.Command, args []string) error {
 	if _, err := os.Stat(proxyFile); err == nil && !proxyAddFlags.force {
 		return fmt.Errorf("proxy '%s' already exists. Use --force to overwrite", input.name)
 	}
+	if proxy.IsDisabled(cfg, input.name) {
+		return fmt.Errorf("proxy '%s' exists but is disabled; enable it with 'srv proxy enable %s' or remove it first", input.name, input.name)
+	}
 
 	// Setup certificate
 	if err := setupProxyCertificate(input); err != nil {
@@ -313,6 +574,10 @@ func runProxyAdd(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	backendURLs, err := resolveProxyBackends(input, cfg)
+	if err != nil {
+		return err
+	}
 
 	// When --fallback is set we sit an nginx sidecar in front of the primary
 	// upstream so 5xx responses transparently re-proxy to the fallback URL.
@@ -352,7 +617,7 @@ func runProxyAdd(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create proxy config file
-	if err := writeProxyConfig(cfg, input.name, input.domain, targetURL, input.containerName, input.wildcard); err != nil {
+	if err := writeProxyConfig(cfg, input, targetURL, backendURLs); err != nil {
 		return err
 	}
 
@@ -365,11 +630,18 @@ func runProxyAdd(cmd *cobra.Command, args []string) error {
 		existingRoutes = pmeta.Routes
 	}
 	if err := proxy.Write(proxy.Metadata{
-		Name:     input.name,
-		Domains:  []string{input.domain},
-		Wildcard: input.wildcard,
-		IsLocal:  true,
-		Routes:   existingRoutes,
+		Name:        input.name,
+		Domains:     []string{input.domain},
+		Wildcard:    input.wildcard,
+		IsLocal:     true,
+		PathPrefix:  input.pathPrefix,
+		StripPrefix: input.stripPrefix,
+		Timeout:     input.timeouts.Response,
+		ReadTimeout: input.timeouts.Read,
+		DialTimeout: input.timeouts.Dial,
+		WebSocket:   input.websocket,
+		GRPC:        input.grpc,
+		Routes:      existingRoutes,
 	}); err != nil {
 		ui.Warn("Failed to write proxy metadata sidecar: %v", err)
 	} else if len(existingRoutes) > 0 {
@@ -386,10 +658,13 @@ func runProxyAdd(cmd *cobra.Command, args []string) error {
 	}
 
 	ui.Success("Proxy '%s' created", input.name)
-	if input.isContainer {
-		ui.Dim("https://%s -> %s:%s (container)", input.domain, input.containerName, input.containerPort)
+	from := input.domain + input.pathPrefix
+	if len(backendURLs) > 0 {
+		ui.Dim("https://%s -> %s", from, strings.Join(append([]string{targetURL}, backendURLs...), ", "))
+	} else if input.isContainer {
+		ui.Dim("https://%s -> %s:%s (container)", from, input.containerName, input.containerPort)
 	} else {
-		ui.Dim("https://%s -> localhost:%s", input.domain, input.port)
+		ui.Dim("https://%s -> localhost:%s", from, input.port)
 		ui.Dim("Start your service on port %s to use this proxy", input.port)
 	}
 	return nil
@@ -421,17 +696,100 @@ func runProxyRemove(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-// proxyListRow is the json shape for one entry under `srv proxy list --format json`.
-type proxyListRow struct {
-	Name      string `json:"name"`
-	Domain    string `json:"domain"`
-	Target    string `json:"target"`
-	Type      string `json:"type"`
-	Container string `json:"container,omitempty"`
-	SSL       string `json:"ssl"`
-	Status    string `json:"status"`
+func runProxyUpdate(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	port, container := proxyUpdateFlags.port, proxyUpdateFlags.container
+	if port == "" && container == "" {
+		return fmt.Errorf("either --port or --container must be specified")
+	}
+	if port != "" && container != "" {
+		return fmt.Errorf("--port and --container are mutually exclusive")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if proxy.IsTCP(name) {
+		return fmt.Errorf("proxy '%s' is a TCP proxy; re-create it with 'srv proxy add --tcp --force'", name)
+	}
+	if proxy.IsDisabled(cfg, name) {
+		return fmt.Errorf("proxy '%s' is disabled; run 'srv proxy enable %s' first", name, name)
+	}
+	proxyFile := filepath.Join(cfg.TraefikConfDir(), constants.ProxyConfigPrefix+name+constants.ExtYAML)
+	if _, err := os.Stat(proxyFile); err != nil {
+		return fmt.Errorf("proxy '%s' not found", name)
+	}
+	// With --fallback, Traefik targets the sidecar, which bakes in the primary
+	// upstream; rewriting the Traefik target here would bypass the sidecar.
+	if _, err := os.Stat(fallbackSiteDir(cfg, name)); err == nil {
+		return fmt.Errorf("proxy '%s' uses a --fallback sidecar; re-create it with 'srv proxy add --force'", name)
+	}
+
+	old := readProxyConfig(cfg, name)
+	if old.Domain == "" {
+		return fmt.Errorf("could not read the domain of proxy '%s'", name)
+	}
+	if len(old.Backends) > 0 {
+		return fmt.Errorf("proxy '%s' load-balances several backends; re-create it with 'srv proxy add --force'", name)
+	}
+	input := &proxyInput{
+		name:        name,
+		domain:      old.Domain,
+		wildcard:    old.Wildcard,
+		pathPrefix:  old.PathPrefix,
+		stripPrefix: old.StripPrefix,
+		timeouts:    old.Timeouts,
+		websocket:   old.WebSocket,
+		grpc:        old.GRPC,
+	}
+	if err := parseProxyTarget(input, port, container); err != nil {
+		return err
+	}
+
+	targetURL, err := connectProxyContainer(input, cfg)
+	if err != nil {
+		return err
+	}
+	if err := writeProxyConfig(cfg, input, targetURL, nil); err != nil {
+		return err
+	}
+
+	// Detach the previous container from the srv network unless another
+	// proxy still routes to it.
+	if old.Container != "" && old.Container != input.containerName && !proxyContainerInUse(cfg, old.Container) {
+		if err := docker.DisconnectContainerFromNetwork(old.Container, cfg.NetworkName); err != nil {
+			ui.Warn("Failed to disconnect '%s' from %s: %v", old.Container, cfg.NetworkName, err)
+		} else {
+			ui.Dim("Disconnected container '%s' from %s network", old.Container, cfg.NetworkName)
+		}
+	}
+
+	if err := traefik.UpdateDynamicConfig(); err != nil {
+		ui.Warn("Failed to update Traefik config: %v", err)
+	}
+
+	ui.Success("Proxy '%s' updated", name)
+	ui.Dim("https://%s -> %s", input.domain+input.pathPrefix, targetURL)
+	return nil
+}
+
+// proxyContainerInUse reports whether any proxy config targets container.
+func proxyContainerInUse(cfg *config.Config, container string) bool {
+	for _, name := range getProxyNames() {
+		info := readProxyConfig(cfg, name)
+		for _, target := range append([]string{info.Target}, info.Backends...) {
+			if traefik.ContainerFromURL(target) == container {
+				return true
+			}
+		}
+	}
+	return false
 }
 
+// proxyListHeaders are the columns of the `srv proxy list` table (and CSV).
+var proxyListHeaders = []string{"NAME", "DOMAIN", "TARGET", "TYPE", "TIMEOUT", "SSL", "STATUS"}
+
 func runProxyList(cmd *cobra.Command, args []string) error {
 	cfg, err := config.Load()
 	if err != nil {
@@ -441,7 +799,10 @@ func runProxyList(cmd *cobra.Command, args []string) error {
 	proxies := getProxyNames()
 	if len(proxies) == 0 {
 		if jsonOutput() {
-			return ui.PrintJSON([]proxyListRow{})
+			return ui.PrintJSON([]proxy.ProxyView{})
+		}
+		if csvOutput() {
+			return ui.PrintCSV(proxyListHeaders, nil)
 		}
 		ui.Dim("No proxies configured. Use 'srv proxy add --domain DOMAIN --port PORT' to create one.")
 		return nil
@@ -454,27 +815,37 @@ func runProxyList(cmd *cobra.Command, args []string) error {
 	}
 
 	if jsonOutput() {
-		out := make([]proxyListRow, 0, len(proxies))
+		out := make([]proxy.ProxyView, 0, len(proxies))
 		for _, name := range proxies {
 			info := readProxyConfig(cfg, name)
 			ptype := constants.ProxyTypeLocalhost
 			if info.Container != "" {
 				ptype = constants.ProxyTypeContainer
 			}
-			out = append(out, proxyListRow{
-				Name:      name,
-				Domain:    info.Domain,
-				Target:    info.Target,
-				Type:      ptype,
-				Container: info.Container,
-				SSL:       plainProxySSLStatus(name, info.Domain),
-				Status:    status,
+			out = append(out, proxy.ProxyView{
+				Name:           name,
+				Domain:         info.Domain,
+				Target:         info.Target,
+				Type:           ptype,
+				Container:      info.Container,
+				Backends:       info.Backends,
+				PathPrefix:     info.PathPrefix,
+				StripPrefix:    info.StripPrefix,
+				Timeout:        info.Timeouts.Response,
+				ReadTimeout:    info.Timeouts.Read,
+				DialTimeout:    info.Timeouts.Dial,
+				WebSocket:      info.WebSocket,
+				GRPC:           info.GRPC,
+				TCP:            info.TCP,
+				EntryPointPort: info.EntryPointPort,
+				Passthrough:    info.Passthrough,
+				SSL:            plainProxySSLStatus(name, info.Domain),
+				Status:         proxyStatus(cfg, name, status),
 			})
 		}
 		return ui.PrintJSON(out)
 	}
 
-	headers := []string{"NAME", "DOMAIN", "TARGET", "TYPE", "SSL", "STATUS"}
 	rows := make([][]string, 0, len(proxies))
 	for _, name := range proxies {
 		info := readProxyConfig(cfg, name)
@@ -483,12 +854,59 @@ func runProxyList(cmd *cobra.Command, args []string) error {
 		if info.Container != "" {
 			ptype = constants.ProxyTypeContainer
 		}
-		rows = append(rows, []string{name, info.Domain, info.Target, ptype, sslStatus, ui.StatusColor(status)})
+		domain := info.Domain
+		if info.TCP {
+			domain = formatTCPListener(info.Domain, info.EntryPointPort)
+			ptype += "/tcp"
+			if info.Passthrough {
+				sslStatus = ui.DimText("passthrough")
+			}
+		}
+		target := strings.Join(append([]string{info.Target}, info.Backends...), ", ")
+		if info.PathPrefix != "" {
+			prefix := info.PathPrefix
+			if info.StripPrefix {
+				prefix += " (stripped)"
+			}
+			target = prefix + " -> " + target
+		}
+		rows = append(rows, []string{name, domain, target, ptype, formatProxyTimeouts(info.Timeouts), sslStatus, ui.StatusColor(proxyStatus(cfg, name, status))})
+	}
+	if csvOutput() {
+		return ui.PrintCSV(proxyListHeaders, rows)
 	}
-	ui.PrintTable(headers, rows)
+	ui.PrintTable(proxyListHeaders, rows)
 	return nil
 }
 
+// proxyStatus is the STATUS of a proxy in `srv proxy list`: "disabled" for
+// a disabled proxy, otherwise traefikStatus.
+func proxyStatus(cfg *config.Config, name, traefikStatus string) string {
+	if proxy.IsDisabled(cfg, name) {
+		return constants.StatusDisabled
+	}
+	return traefikStatus
+}
+
+// formatProxyTimeouts renders a proxy's timeout overrides for the list table:
+// the response timeout first, then any read/dial overrides, or a dimmed "-".
+func formatProxyTimeouts(t traefik.ProxyTimeouts) string {
+	var parts []string
+	if t.Response != "" {
+		parts = append(parts, t.Response)
+	}
+	if t.Read != "" {
+		parts = append(parts, "read "+t.Read)
+	}
+	if t.Dial != "" {
+		parts = append(parts, "dial "+t.Dial)
+	}
+	if len(parts) == 0 {
+		return ui.DimText("-")
+	}
+	return strings.Join(parts, ", ")
+}
+
 // plainProxySSLStatus mirrors getProxySSLStatus without colour codes for json.
 func plainProxySSLStatus(name, domain string) string {
 	return localCertStatus(proxyCertSiteName(name), domain)
@@ -503,8 +921,35 @@ func getProxySSLStatus(name, domain string) string {
 	return localCertStatusColored(proxyCertSiteName(name), domain)
 }
 
+// getProxyNames lists HTTP and TCP proxies, enabled or disabled. A
+// proxy-tcp-<name>.yml file belongs to the TCP proxy <name>; an HTTP proxy
+// created before TCP proxies existed may still be called tcp-<name>.
 func getProxyNames() []string {
-	return scanConfigNames(constants.ProxyConfigPrefix)
+	names := append(scanConfigNames(constants.ProxyConfigPrefix, constants.ExtYAML),
+		scanConfigNames(constants.ProxyConfigPrefix, constants.ExtYAML+constants.ExtDisabled)...)
+	for i, name := range names {
+		if rest, ok := strings.CutPrefix(constants.ProxyConfigPrefix+name, constants.TCPProxyConfigPrefix); ok && proxy.IsTCP(rest) {
+			names[i] = rest
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// proxyNameCompletions returns getProxyNames for shell completion, with
+// disabled proxies described as such.
+func proxyNameCompletions() []string {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil
+	}
+	names := getProxyNames()
+	for i, name := range names {
+		if proxy.IsDisabled(cfg, name) {
+			names[i] = name + "\tdisabled"
+		}
+	}
+	return names
 }
 
 // =============================================================================
@@ -514,13 +959,20 @@ func getProxyNames() []string {
 // writeProxyConfig renders the proxy's Traefik file config. The rendering lives
 // in internal/traefik (shared with the other dynamic-config writers); this
 // wrapper just builds the input struct.
-func writeProxyConfig(cfg *config.Config, name, domain, targetURL, containerName string, wildcard bool) error {
+func writeProxyConfig(cfg *config.Config, input *proxyInput, targetURL string, backends []string) error {
 	return traefik.WriteProxyConfig(cfg, traefik.ProxyRoute{
-		Name:      name,
-		Domain:    domain,
-		TargetURL: targetURL,
-		Container: containerName,
-		Wildcard:  wildcard,
+		Name:        input.name,
+		Domain:      input.domain,
+		TargetURL:   targetURL,
+		Container:   input.containerName,
+		Wildcard:    input.wildcard,
+		Backends:    backends,
+		LBMethod:    input.lbMethod,
+		PathPrefix:  input.pathPrefix,
+		StripPrefix: input.stripPrefix,
+		Timeouts:    input.timeouts,
+		WebSocket:   input.websocket,
+		GRPC:        input.grpc,
 	})
 }
 
@@ -529,35 +981,36 @@ type proxyConfigInfo struct {
 	Domain    string
 	Target    string
 	Container string
+	Wildcard  bool
+	Backends  []string // load-balanced servers after Target
+	// PathPrefix, StripPrefix, Timeouts, WebSocket and GRPC come from the
+	// proxy's metadata sidecar.
+	PathPrefix  string
+	StripPrefix bool
+	Timeouts    traefik.ProxyTimeouts
+	WebSocket   bool
+	GRPC        string
+	// TCP, EntryPointPort and Passthrough describe TCP proxies.
+	TCP            bool
+	EntryPointPort int
+	Passthrough    bool
 }
 
 // traefikRouteConfig is an alias for the shared type in the traefik package.
 // It exists here for backward compatibility within this file.
 type traefikRouteConfig = traefik.RouteConfig
 
-// extractContainerFromURL extracts the container name from a target URL.
-// Returns empty string if the target resolves to the host machine (localhost,
-// 127.0.0.1, ::1, or host.docker.internal) rather than a named container.
-func extractContainerFromURL(targetURL string) string {
-	parsed, err := url.Parse(targetURL)
-	if err != nil {
-		return ""
-	}
-
-	host := parsed.Hostname()
-	switch host {
-	case constants.DockerHostInternal, constants.LocalhostIP, "localhost", "::1":
-		return ""
-	}
-
-	return host
-}
-
 // readProxyConfig reads and parses a proxy configuration file.
 // Returns a proxyConfigInfo with all available fields populated.
 func readProxyConfig(cfg *config.Config, name string) proxyConfigInfo {
+	if proxy.IsTCP(name) {
+		return readTCPProxyConfig(cfg, name)
+	}
 	proxyFile := filepath.Join(cfg.TraefikConfDir(), constants.ProxyConfigPrefix+name+constants.ExtYAML)
 	data, err := os.ReadFile(proxyFile)
+	if os.IsNotExist(err) {
+		data, err = os.ReadFile(proxyFile + constants.ExtDisabled)
+	}
 	if err != nil {
 		return proxyConfigInfo{Target: "unknown"}
 	}
@@ -574,20 +1027,37 @@ func readProxyConfig(cfg *config.Config, name string) proxyConfigInfo {
 	for _, router := range config.HTTP.Routers {
 		if domain := traefik.ExtractDomainFromRule(router.Rule); domain != "" {
 			info.Domain = domain
+			info.Wildcard = strings.Contains(router.Rule, "HostRegexp(")
 			break
 		}
 	}
 
-	// Extract target URL from first service's first server
+	// Extract target URL from first service's servers; any beyond the first
+	// are load-balanced backends.
 	for _, service := range config.HTTP.Services {
-		if len(service.LoadBalancer.Servers) > 0 {
-			info.Target = service.LoadBalancer.Servers[0].URL
+		if servers := service.LoadBalancer.Servers; len(servers) > 0 {
+			info.Target = servers[0].URL
+			for _, s := range servers[1:] {
+				info.Backends = append(info.Backends, s.URL)
+			}
 			break
 		}
 	}
 
 	// Extract container name from target URL using proper URL parsing
-	info.Container = extractContainerFromURL(info.Target)
+	info.Container = traefik.ContainerFromURL(info.Target)
+
+	if pmeta, err := proxy.Read(name); err == nil && pmeta != nil {
+		info.PathPrefix = pmeta.PathPrefix
+		info.StripPrefix = pmeta.StripPrefix
+		info.Timeouts = traefik.ProxyTimeouts{
+			Response: pmeta.Timeout,
+			Read:     pmeta.ReadTimeout,
+			Dial:     pmeta.DialTimeout,
+		}
+		info.WebSocket = pmeta.WebSocket
+		info.GRPC = pmeta.GRPC
+	}
 
 	return info
 }
@@ -0,0 +1,282 @@
+// Package cmd — proxy_check.go implements `srv proxy check`, which tests
+// that a proxy's target actually answers: the container is running, a TCP
+// connection succeeds and, for HTTP proxies, a GET gets a response.
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/constants"
+	"github.com/stubbedev/srv/internal/docker"
+	"github.com/stubbedev/srv/internal/ui"
+)
+
+var proxyCheckFlags struct {
+	all     bool
+	timeout time.Duration
+}
+
+var proxyCheckCmd = &cobra.Command{
+	Use:   "check NAME",
+	Short: "Test that a proxy's target responds",
+	Long: `Test a proxy's target end to end: for a container target, that the
+container is running; then that a TCP connection to the target succeeds and,
+for HTTP proxies, that a GET request gets a response. Reports the connect
+latency, HTTP status and response time, with a hint for each failure.
+
+--all checks every proxy in parallel. The command fails when any check does.
+
+Examples:
+  srv proxy check api-test
+  srv proxy check --all --timeout 2s
+  srv proxy check --all --json`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 && !proxyCheckFlags.all {
+			_ = cmd.Help()
+			return ui.UsageError("srv proxy check NAME", "a proxy name is required (or use --all to check every proxy)")
+		}
+		if len(args) > 0 && proxyCheckFlags.all {
+			return ui.UsageError("srv proxy check NAME", "pass either a proxy name or --all, not both")
+		}
+		if len(args) > 1 {
+			return ui.UsageError("srv proxy check NAME", "too many arguments — expected a single proxy name, got %d", len(args))
+		}
+		return nil
+	},
+	RunE: runProxyCheck,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return proxyNameCompletions(), cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+func init() {
+	proxyCheckCmd.Flags().BoolVarP(&proxyCheckFlags.all, "all", "a", false, "Check every proxy")
+	proxyCheckCmd.Flags().DurationVar(&proxyCheckFlags.timeout, "timeout", 5*time.Second, "Time allowed for the connection and for the response")
+	addJSONFlag(proxyCheckCmd)
+	proxyCmd.AddCommand(proxyCheckCmd)
+}
+
+// proxyCheckResult is the outcome of checking one proxy.
+type proxyCheckResult struct {
+	Name      string `json:"name"`
+	Target    string `json:"target"`
+	OK        bool   `json:"ok"`
+	ConnectMS int64  `json:"connect_ms,omitempty"`
+	// Status and ResponseMS are set when an HTTP request got a response.
+	Status     int    `json:"status,omitempty"`
+	ResponseMS int64  `json:"response_ms,omitempty"`
+	Error      string `json:"error,omitempty"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// proxyContainerRunning reports whether a container is running. Tests swap it.
+var proxyContainerRunning = docker.IsContainerRunning
+
+func runProxyCheck(cmd *cobra.Command, args []string) error {
+	if proxyCheckFlags.timeout <= 0 {
+		return fmt.Errorf("--timeout must be positive")
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	names := args
+	if proxyCheckFlags.all {
+		names = getProxyNames()
+	} else if !slices.Contains(getProxyNames(), args[0]) {
+		return fmt.Errorf("proxy '%s' not found", args[0])
+	}
+
+	results := checkProxies(cfg, names, proxyCheckFlags.timeout)
+	failed := 0
+	for _, res := range results {
+		if !res.OK {
+			failed++
+		}
+	}
+	if jsonOutput() {
+		if err := ui.PrintJSON(results); err != nil {
+			return err
+		}
+	} else if len(results) == 0 {
+		ui.Dim("No proxies configured")
+	} else {
+		for _, res := range results {
+			printProxyCheck(res)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d proxy check(s) failed", failed)
+	}
+	return nil
+}
+
+func printProxyCheck(res proxyCheckResult) {
+	if !res.OK {
+		ui.Error("%s (%s): %s", res.Name, res.Target, res.Error)
+		if res.Suggestion != "" {
+			ui.IndentedDim(1, "%s", res.Suggestion)
+		}
+		return
+	}
+	detail := fmt.Sprintf("connect %dms", res.ConnectMS)
+	if res.Status != 0 {
+		detail += fmt.Sprintf(", HTTP %d in %dms", res.Status, res.ResponseMS)
+	}
+	ui.Success("%s (%s): %s", res.Name, res.Target, detail)
+}
+
+// checkProxies checks proxies with a worker pool, returning results in the
+// order of names.
+func checkProxies(cfg *config.Config, names []string, timeout time.Duration) []proxyCheckResult {
+	results := make([]proxyCheckResult, len(names))
+	jobs := make(chan int, len(names))
+	for i := range names {
+		jobs <- i
+	}
+	close(jobs)
+
+	var wg sync.WaitGroup
+	for range min(maxWorkers(), len(names)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = checkProxy(cfg, names[i], timeout)
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// checkProxy checks one proxy's target.
+func checkProxy(cfg *config.Config, name string, timeout time.Duration) proxyCheckResult {
+	info := readProxyConfig(cfg, name)
+	res := proxyCheckResult{Name: name, Target: info.Target}
+	fail := func(err error, suggestion string) proxyCheckResult {
+		res.Error = err.Error()
+		res.Suggestion = suggestion
+		return res
+	}
+	if info.Target == "unknown" {
+		return fail(errors.New("no target found in the proxy config"), "Recreate the proxy with 'srv proxy add --force'")
+	}
+
+	targetURL := info.Target
+	if info.TCP {
+		targetURL = "tcp://" + info.Target
+	}
+	u, err := url.Parse(targetURL)
+	if err != nil || u.Port() == "" {
+		return fail(fmt.Errorf("invalid target %q", info.Target), "Recreate the proxy with 'srv proxy add --force'")
+	}
+
+	// The host reaches host targets on localhost, and containers by their
+	// address on srv's network since their names only resolve inside Docker.
+	host := constants.LocalhostAlias
+	if info.Container != "" {
+		if !proxyContainerRunning(info.Container) {
+			return fail(fmt.Errorf("container %s is not running", info.Container), "Start it with 'docker start "+info.Container+"'")
+		}
+		ip, err := containerAddress(cfg.NetworkName, info.Container)
+		if err != nil {
+			return fail(err, "Reconnect it with 'srv proxy update "+name+" --container "+info.Container+":"+u.Port()+"'")
+		}
+		host = ip
+	}
+	addr := net.JoinHostPort(host, u.Port())
+
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return fail(fmt.Errorf("TCP connect to %s failed: %w", addr, err), dialSuggestion(err, info.Container, u.Port()))
+	}
+	res.ConnectMS = time.Since(start).Milliseconds()
+	_ = conn.Close()
+
+	if info.TCP {
+		res.OK = true
+		return res
+	}
+	status, latency, err := probeProxyTarget(info.Target, addr, timeout)
+	if err != nil {
+		return fail(fmt.Errorf("HTTP request failed: %w", err), "The port accepts connections but doesn't answer HTTP; check that it's the right port")
+	}
+	res.Status = status
+	res.ResponseMS = latency.Milliseconds()
+	if status >= 500 {
+		return fail(fmt.Errorf("target answered HTTP %d", status), "The service is failing; check its logs")
+	}
+	res.OK = true
+	return res
+}
+
+// dialSuggestion is the hint for a failed TCP connect.
+func dialSuggestion(err error, container, port string) string {
+	switch {
+	case errors.Is(err, syscall.ECONNREFUSED) && container != "":
+		return fmt.Sprintf("Nothing listens on port %s in %s; check the container's port", port, container)
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return fmt.Sprintf("Nothing listens on port %s; start your service", port)
+	default:
+		return "The target didn't accept the connection in time; check that it's up and not firewalled"
+	}
+}
+
+// containerAddress returns a container's IPv4 address on network.
+func containerAddress(network, container string) (string, error) {
+	detail, err := docker.InspectNetwork(network)
+	if err != nil {
+		return "", err
+	}
+	for _, ep := range detail.Containers {
+		if ep.Name == container && ep.IPv4 != "" {
+			return ep.IPv4, nil
+		}
+	}
+	return "", fmt.Errorf("container %s is not connected to %s", container, network)
+}
+
+// probeProxyTarget GETs target over a connection to addr and returns the
+// response status and how long it took. TLS isn't verified and redirects
+// aren't followed: any response means the target answers.
+func probeProxyTarget(target, addr string, timeout time.Duration) (int, time.Duration, error) {
+	dialer := &net.Dialer{Timeout: timeout}
+	client := &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec // reachability probe only
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, network, addr)
+			},
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	start := time.Now()
+	resp, err := client.Get(target)
+	if err != nil {
+		return 0, 0, err
+	}
+	latency := time.Since(start)
+	_ = resp.Body.Close()
+	return resp.StatusCode, latency, nil
+}
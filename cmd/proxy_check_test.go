@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stubbedev/srv/internal/ui"
+)
+
+func TestCheckProxy(t *testing.T) {
+	setupSrvRoot(t)
+	cfg := mustLoadConfig(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+
+	// A port nothing listens on.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedPort := l.Addr().(*net.TCPAddr).Port
+	_ = l.Close()
+
+	for name, target := range map[string]string{
+		"up":   "http://localhost:" + u.Port(),
+		"down": "http://localhost:" + strconv.Itoa(closedPort),
+		"ctr":  "http://app:3000",
+	} {
+		if err := writeProxyConfig(cfg, &proxyInput{name: name, domain: name + ".test"}, target, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	orig := proxyContainerRunning
+	proxyContainerRunning = func(string) bool { return false }
+	t.Cleanup(func() { proxyContainerRunning = orig })
+
+	if res := checkProxy(cfg, "up", 2*time.Second); !res.OK || res.Status != http.StatusNoContent {
+		t.Errorf("up = %+v", res)
+	}
+	if res := checkProxy(cfg, "down", 2*time.Second); res.OK || !strings.Contains(res.Error, "TCP connect") || !strings.Contains(res.Suggestion, "start your service") {
+		t.Errorf("down = %+v", res)
+	}
+	if res := checkProxy(cfg, "ctr", 2*time.Second); res.OK || !strings.Contains(res.Error, "not running") {
+		t.Errorf("ctr = %+v", res)
+	}
+
+	proxyCheckFlags.all, proxyCheckFlags.timeout, jsonFlag = true, 2*time.Second, true
+	t.Cleanup(func() { proxyCheckFlags.all, proxyCheckFlags.timeout, jsonFlag = false, 5*time.Second, false })
+	var stdout bytes.Buffer
+	restore := ui.SwapStdout(&stdout)
+	err = runProxyCheck(nil, nil)
+	restore()
+	if err == nil || !strings.Contains(err.Error(), "2 proxy check(s) failed") {
+		t.Errorf("check --all err = %v", err)
+	}
+	var results []proxyCheckResult
+	if err := json.Unmarshal(stdout.Bytes(), &results); err != nil || len(results) != 3 || results[2].Name != "up" {
+		t.Errorf("check --all --json = %s (%v)", stdout.String(), err)
+	}
+}
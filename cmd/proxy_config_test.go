@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/traefik"
 )
 
 func newCmdCfg(t *testing.T) *config.Config {
@@ -26,7 +27,7 @@ func newCmdCfg(t *testing.T) *config.Config {
 
 func TestWriteProxyConfigLocalhost(t *testing.T) {
 	cfg := newCmdCfg(t)
-	if err := writeProxyConfig(cfg, "blog", "blog.local", "http://host.docker.internal:8080", "", false); err != nil {
+	if err := writeProxyConfig(cfg, &proxyInput{name: "blog", domain: "blog.local"}, "http://host.docker.internal:8080", nil); err != nil {
 		t.Fatal(err)
 	}
 	data, err := os.ReadFile(filepath.Join(cfg.TraefikConfDir(), "proxy-blog.yml"))
@@ -47,7 +48,7 @@ func TestWriteProxyConfigLocalhost(t *testing.T) {
 
 func TestWriteProxyConfigContainer(t *testing.T) {
 	cfg := newCmdCfg(t)
-	if err := writeProxyConfig(cfg, "redis", "redis.local", "http://redis:6379", "redis", false); err != nil {
+	if err := writeProxyConfig(cfg, &proxyInput{name: "redis", domain: "redis.local", containerName: "redis"}, "http://redis:6379", nil); err != nil {
 		t.Fatal(err)
 	}
 	data, _ := os.ReadFile(filepath.Join(cfg.TraefikConfDir(), "proxy-redis.yml"))
@@ -56,6 +57,144 @@ func TestWriteProxyConfigContainer(t *testing.T) {
 	}
 }
 
+func TestWriteProxyConfigBackends(t *testing.T) {
+	cfg := newCmdCfg(t)
+	if err := writeProxyConfig(cfg, &proxyInput{name: "api", domain: "api.test", lbMethod: "leastconn"}, "http://localhost:3001", []string{"http://localhost:3002"}); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(cfg.TraefikConfDir(), "proxy-api.yml"))
+	if !strings.Contains(string(data), "strategy: p2c") {
+		t.Errorf("leastconn should render the p2c strategy:\n%s", data)
+	}
+	info := readProxyConfig(cfg, "api")
+	if info.Target != "http://localhost:3001" || len(info.Backends) != 1 || info.Backends[0] != "http://localhost:3002" {
+		t.Errorf("got %+v", info)
+	}
+
+	if err := writeProxyConfig(cfg, &proxyInput{name: "api", domain: "api.test", lbMethod: "drr"}, "http://localhost:3001", nil); err == nil {
+		t.Error("expected err for unknown lb method")
+	}
+}
+
+func TestWriteProxyConfigStripPrefix(t *testing.T) {
+	cfg := newCmdCfg(t)
+	in := &proxyInput{name: "api", domain: "app.test", pathPrefix: "/api", stripPrefix: true}
+	if err := writeProxyConfig(cfg, in, "http://localhost:4000", nil); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(cfg.TraefikConfDir(), "proxy-api.yml"))
+	body := string(data)
+	for _, want := range []string{"(Host(`app.test`)) && PathPrefix(`/api`)", "proxy-api-stripprefix", "stripPrefix:", "- /api"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %q:\n%s", want, body)
+		}
+	}
+	if info := readProxyConfig(cfg, "api"); info.Domain != "app.test" {
+		t.Errorf("Domain = %q", info.Domain)
+	}
+
+	in.stripPrefix = false
+	if err := writeProxyConfig(cfg, in, "http://localhost:4000", nil); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(filepath.Join(cfg.TraefikConfDir(), "proxy-api.yml"))
+	if strings.Contains(string(data), "stripPrefix") {
+		t.Errorf("--path-prefix alone should not strip:\n%s", data)
+	}
+}
+
+func TestWriteProxyConfigTimeouts(t *testing.T) {
+	cfg := newCmdCfg(t)
+	in := &proxyInput{name: "api", domain: "api.test", timeouts: traefik.ProxyTimeouts{Response: "5m", Dial: "10s"}}
+	if err := writeProxyConfig(cfg, in, "http://localhost:3000", nil); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(cfg.TraefikConfDir(), "proxy-api.yml"))
+	body := string(data)
+	for _, want := range []string{"serversTransport: proxy-api-transport", "responseHeaderTimeout: 5m", "dialTimeout: 10s"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "readIdleTimeout") {
+		t.Errorf("unset read timeout should be omitted:\n%s", body)
+	}
+}
+
+func TestWriteProxyConfigWebSocket(t *testing.T) {
+	cfg := newCmdCfg(t)
+	in := &proxyInput{name: "ws", domain: "ws.test", websocket: true, timeouts: traefik.ProxyTimeouts{Response: "5m"}}
+	if err := writeProxyConfig(cfg, in, "http://localhost:8081", nil); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(cfg.TraefikConfDir(), "proxy-ws.yml"))
+	body := string(data)
+	for _, want := range []string{"proxy-ws-websocket", "Connection: Upgrade", "Upgrade: websocket", "idleConnTimeout: 3600s", "responseHeaderTimeout: 5m"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestWriteProxyConfigGRPC(t *testing.T) {
+	cfg := newCmdCfg(t)
+	in := &proxyInput{name: "rpc", domain: "rpc.test", grpc: traefik.GRPCH2C}
+	if err := writeProxyConfig(cfg, in, "http://localhost:50051", []string{"http://app:50051"}); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(cfg.TraefikConfDir(), "proxy-rpc.yml"))
+	body := string(data)
+	for _, want := range []string{"# Service type: grpc (h2c)", "url: h2c://localhost:50051", "url: h2c://app:50051", "passHostHeader: true", "insecureSkipVerify: false", "dialTimeout: 30s", "- websecure"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %q:\n%s", want, body)
+		}
+	}
+
+	in = &proxyInput{name: "rpc", domain: "rpc.test", grpc: traefik.GRPCTLS, timeouts: traefik.ProxyTimeouts{Dial: "5s"}}
+	if err := writeProxyConfig(cfg, in, "http://localhost:50051", nil); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(filepath.Join(cfg.TraefikConfDir(), "proxy-rpc.yml"))
+	body = string(data)
+	if !strings.Contains(body, "url: https://localhost:50051") || !strings.Contains(body, "dialTimeout: 5s") {
+		t.Errorf("--grpc-tls config:\n%s", body)
+	}
+}
+
+func TestProxyGRPCMode(t *testing.T) {
+	t.Cleanup(resetProxyAddFlags)
+	proxyAddFlags.grpc = true
+	if mode, err := proxyGRPCMode(); err != nil || mode != traefik.GRPCH2C {
+		t.Errorf("--grpc = %q, %v", mode, err)
+	}
+	proxyAddFlags.websocket = true
+	if _, err := proxyGRPCMode(); err == nil {
+		t.Error("expected --grpc and --websocket to conflict")
+	}
+	proxyAddFlags.websocket = false
+	proxyAddFlags.grpcTLS = true
+	if _, err := proxyGRPCMode(); err == nil {
+		t.Error("expected --grpc and --grpc-tls to conflict")
+	}
+	proxyAddFlags.grpc = false
+	if mode, err := proxyGRPCMode(); err != nil || mode != traefik.GRPCTLS {
+		t.Errorf("--grpc-tls = %q, %v", mode, err)
+	}
+}
+
+func TestSplitProxyBackend(t *testing.T) {
+	cases := []struct{ spec, port, container string }{
+		{"localhost:3001", "3001", ""},
+		{"127.0.0.1:3002", "3002", ""},
+		{"app:80", "", "app:80"},
+	}
+	for _, c := range cases {
+		if port, container := splitProxyBackend(c.spec); port != c.port || container != c.container {
+			t.Errorf("splitProxyBackend(%q) = %q, %q", c.spec, port, container)
+		}
+	}
+}
+
 func TestReadProxyConfigMissing(t *testing.T) {
 	cfg := newCmdCfg(t)
 	info := readProxyConfig(cfg, "ghost")
@@ -66,7 +205,7 @@ func TestReadProxyConfigMissing(t *testing.T) {
 
 func TestReadProxyConfigRoundtrip(t *testing.T) {
 	cfg := newCmdCfg(t)
-	if err := writeProxyConfig(cfg, "blog", "blog.local", "http://host.docker.internal:8080", "", false); err != nil {
+	if err := writeProxyConfig(cfg, &proxyInput{name: "blog", domain: "blog.local"}, "http://host.docker.internal:8080", nil); err != nil {
 		t.Fatal(err)
 	}
 	info := readProxyConfig(cfg, "blog")
@@ -0,0 +1,108 @@
+// Package cmd — proxy_disable.go implements `srv proxy disable` and `srv
+// proxy enable`, which stop and resume routing to a proxy without removing
+// its certificate, DNS registration or metadata.
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/proxy"
+	"github.com/stubbedev/srv/internal/ui"
+)
+
+var proxyDisableCmd = &cobra.Command{
+	Use:   "disable NAME",
+	Short: "Stop routing to a proxy without removing it",
+	Long: `Stop routing to a proxy while keeping its configuration, certificate
+and DNS registration. The proxy's Traefik files are renamed to *.disabled,
+which Traefik ignores, so requests to its domain get a 404 until it is
+enabled again. The disabled state survives restarts.
+
+Examples:
+  srv proxy disable api-test
+  srv proxy enable api-test`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		return requireProxyName(cmd, args, "srv proxy disable NAME")
+	},
+	RunE: runProxyDisable,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return proxyNamesByState(false), cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+var proxyEnableCmd = &cobra.Command{
+	Use:   "enable NAME",
+	Short: "Resume routing to a disabled proxy",
+	Long: `Resume routing to a proxy stopped with 'srv proxy disable'.
+
+Examples:
+  srv proxy enable api-test`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		return requireProxyName(cmd, args, "srv proxy enable NAME")
+	},
+	RunE: runProxyEnable,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return proxyNamesByState(true), cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+// requireProxyName checks that args is exactly one proxy name.
+func requireProxyName(cmd *cobra.Command, args []string, usage string) error {
+	if len(args) == 0 {
+		_ = cmd.Help()
+		return ui.UsageError(usage, "a proxy name is required")
+	}
+	if len(args) > 1 {
+		return ui.UsageError(usage, "too many arguments — expected a single proxy name, got %d", len(args))
+	}
+	return nil
+}
+
+// proxyNamesByState returns the proxies that are disabled (or enabled).
+func proxyNamesByState(disabled bool) []string {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, name := range getProxyNames() {
+		if proxy.IsDisabled(cfg, name) == disabled {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func runProxyDisable(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if err := proxy.Disable(cfg, name); err != nil {
+		return err
+	}
+	ui.Success("Proxy '%s' disabled", name)
+	ui.Dim("Run 'srv proxy enable %s' to route to it again", name)
+	return nil
+}
+
+func runProxyEnable(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if err := proxy.Enable(cfg, name); err != nil {
+		return err
+	}
+	ui.Success("Proxy '%s' enabled", name)
+	return nil
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stubbedev/srv/internal/docker"
+	"github.com/stubbedev/srv/internal/traefik"
+)
+
+func TestProxyDisableEnable(t *testing.T) {
+	setupSrvRoot(t)
+	cfg := mustLoadConfig(t)
+	t.Cleanup(docker.SwapNewClientErr(errors.New("offline")))
+	if err := writeProxyConfig(cfg, &proxyInput{name: "blog", domain: "blog.local"}, "http://host.docker.internal:8080", nil); err != nil {
+		t.Fatal(err)
+	}
+	proxyFile := filepath.Join(cfg.TraefikConfDir(), "proxy-blog.yml")
+	routesFile := traefik.RoutesConfigPath(cfg, "blog")
+	if err := os.WriteFile(routesFile, []byte("http: {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	executeRoot(t, "proxy", "disable", "blog")
+	for _, path := range []string{proxyFile, routesFile} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s still present after disable", filepath.Base(path))
+		}
+		if _, err := os.Stat(path + ".disabled"); err != nil {
+			t.Errorf("%s.disabled missing: %v", filepath.Base(path), err)
+		}
+	}
+	if got := getProxyNames(); len(got) != 1 || got[0] != "blog" {
+		t.Errorf("getProxyNames() = %v, want [blog]", got)
+	}
+	if got := proxyNameCompletions(); len(got) != 1 || got[0] != "blog\tdisabled" {
+		t.Errorf("proxyNameCompletions() = %q", got)
+	}
+
+	stdout, _ := executeRoot(t, "proxy", "list", "--format", "csv")
+	if !strings.Contains(stdout, "blog,blog.local,") || !strings.HasSuffix(strings.TrimSpace(stdout), ",disabled") {
+		t.Errorf("list output:\n%s", stdout)
+	}
+
+	if err := runProxyDisable(nil, []string{"blog"}); err == nil || !strings.Contains(err.Error(), "already disabled") {
+		t.Errorf("second disable err = %v", err)
+	}
+
+	executeRoot(t, "proxy", "enable", "blog")
+	for _, path := range []string{proxyFile, routesFile} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s missing after enable: %v", filepath.Base(path), err)
+		}
+	}
+	if err := runProxyEnable(nil, []string{"blog"}); err == nil || !strings.Contains(err.Error(), "not disabled") {
+		t.Errorf("second enable err = %v", err)
+	}
+	if err := runProxyDisable(nil, []string{"nope"}); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("disable unknown err = %v", err)
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/stubbedev/srv/internal/config"
 	"github.com/stubbedev/srv/internal/constants"
 	"github.com/stubbedev/srv/internal/docker"
+	"github.com/stubbedev/srv/internal/fsutil"
 	"github.com/stubbedev/srv/internal/nginx"
 	"github.com/stubbedev/srv/internal/ui"
 )
@@ -86,12 +87,12 @@ func writeFallbackSidecar(cfg *config.Config, spec fallbackSpec) (string, error)
 	if err != nil {
 		return "", err
 	}
-	if err := os.WriteFile(filepath.Join(dir, "nginx.conf"), []byte(nginxConf), constants.FilePermDefault); err != nil {
+	if err := fsutil.AtomicWriteFile(filepath.Join(dir, "nginx.conf"), []byte(nginxConf), constants.FilePermDefault); err != nil {
 		return "", fmt.Errorf("write fallback nginx.conf: %w", err)
 	}
 
 	compose := renderFallbackCompose(spec, dir, cfg.NetworkName)
-	if err := os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte(compose), constants.FilePermDefault); err != nil {
+	if err := fsutil.AtomicWriteFile(filepath.Join(dir, "docker-compose.yml"), []byte(compose), constants.FilePermDefault); err != nil {
 		return "", fmt.Errorf("write fallback compose: %w", err)
 	}
 
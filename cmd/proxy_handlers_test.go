@@ -4,11 +4,14 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stubbedev/srv/internal/config"
 	"github.com/stubbedev/srv/internal/docker"
 	"github.com/stubbedev/srv/internal/mkcert"
+	"github.com/stubbedev/srv/internal/proxy"
+	"github.com/stubbedev/srv/internal/traefik"
 )
 
 func TestValidateProxyInputMissingFlags(t *testing.T) {
@@ -70,6 +73,88 @@ func TestValidateProxyInputLocalhost(t *testing.T) {
 	}
 }
 
+func TestValidateProxyInputBackends(t *testing.T) {
+	resetProxyAddFlags()
+	t.Cleanup(resetProxyAddFlags)
+	proxyAddFlags.domain = "api.test"
+	proxyAddFlags.backends = []string{"localhost:3001", "127.0.0.1:3002"}
+	proxyAddFlags.lbMethod = "leastconn"
+	in, err := validateProxyInput()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if in.port != "3001" || len(in.backends) != 1 || in.backends[0] != "127.0.0.1:3002" || in.lbMethod != "leastconn" {
+		t.Errorf("got %+v", in)
+	}
+
+	proxyAddFlags.lbMethod = "drr"
+	if _, err := validateProxyInput(); err == nil || !strings.Contains(err.Error(), "Traefik v2") {
+		t.Errorf("--lb-method drr: err = %v, want one explaining Traefik dropped it", err)
+	}
+	proxyAddFlags.lbMethod = "random"
+	if _, err := validateProxyInput(); err == nil {
+		t.Error("expected err for unknown --lb-method")
+	}
+	proxyAddFlags.lbMethod = "wrr"
+	proxyAddFlags.backends = []string{"localhost:3001"}
+	if _, err := validateProxyInput(); err == nil {
+		t.Error("expected err for --lb-method with a single backend")
+	}
+	proxyAddFlags.lbMethod = ""
+	proxyAddFlags.backends = []string{"localhost:3001", "localhost:nope"}
+	if _, err := validateProxyInput(); err == nil {
+		t.Error("expected err for invalid backend port")
+	}
+}
+
+func TestValidateProxyInputPathPrefix(t *testing.T) {
+	resetProxyAddFlags()
+	t.Cleanup(resetProxyAddFlags)
+	proxyAddFlags.domain = "app.test"
+	proxyAddFlags.port = "4000"
+	proxyAddFlags.stripPrefix = "/api/"
+	in, err := validateProxyInput()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if in.pathPrefix != "/api" || !in.stripPrefix {
+		t.Errorf("got %+v", in)
+	}
+
+	for _, bad := range []string{"api", "/", "/a b", "/`x`"} {
+		proxyAddFlags.stripPrefix = bad
+		if _, err := validateProxyInput(); err == nil {
+			t.Errorf("expected err for prefix %q", bad)
+		}
+	}
+	proxyAddFlags.stripPrefix = "/api"
+	proxyAddFlags.pathPrefix = "/api"
+	if _, err := validateProxyInput(); err == nil {
+		t.Error("expected err for --path-prefix with --strip-prefix")
+	}
+}
+
+func TestValidateProxyInputTimeouts(t *testing.T) {
+	resetProxyAddFlags()
+	t.Cleanup(resetProxyAddFlags)
+	proxyAddFlags.domain = "api.test"
+	proxyAddFlags.port = "3000"
+	proxyAddFlags.timeout = "5m"
+	in, err := validateProxyInput()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if in.timeouts != (traefik.ProxyTimeouts{Response: "5m"}) {
+		t.Errorf("--timeout alone should set only the response timeout, got %+v", in.timeouts)
+	}
+	for _, bad := range []string{"5", "soon", "-1s", "0s"} {
+		proxyAddFlags.readTimeout = bad
+		if _, err := validateProxyInput(); err == nil {
+			t.Errorf("expected err for --read-timeout %q", bad)
+		}
+	}
+}
+
 func TestValidateProxyInputContainerMissing(t *testing.T) {
 	resetProxyAddFlags()
 	proxyAddFlags.domain = "x.local"
@@ -97,7 +182,7 @@ func TestRunProxyRemoveMissing(t *testing.T) {
 func TestRunProxyRemoveExisting(t *testing.T) {
 	setupSrvRoot(t)
 	cfg, _ := config.Load()
-	if err := writeProxyConfig(cfg, "blog", "blog.local", "http://host.docker.internal:8080", "", false); err != nil {
+	if err := writeProxyConfig(cfg, &proxyInput{name: "blog", domain: "blog.local"}, "http://host.docker.internal:8080", nil); err != nil {
 		t.Fatal(err)
 	}
 	if err := runProxyRemove(nil, []string{"blog"}); err != nil {
@@ -154,6 +239,19 @@ func resetProxyAddFlags() {
 	proxyAddFlags.force = false
 	proxyAddFlags.fallbackURL = ""
 	proxyAddFlags.fallbackTimeout = ""
+	proxyAddFlags.backends = nil
+	proxyAddFlags.lbMethod = ""
+	proxyAddFlags.pathPrefix = ""
+	proxyAddFlags.stripPrefix = ""
+	proxyAddFlags.timeout = ""
+	proxyAddFlags.readTimeout = ""
+	proxyAddFlags.dialTimeout = ""
+	proxyAddFlags.websocket = false
+	proxyAddFlags.grpc = false
+	proxyAddFlags.grpcTLS = false
+	proxyAddFlags.tcp = false
+	proxyAddFlags.entrypointPort = 0
+	proxyAddFlags.passthrough = false
 }
 
 func TestConnectProxyContainerLocalhost(t *testing.T) {
@@ -201,6 +299,63 @@ func TestRunProxyAddLocalhost(t *testing.T) {
 	}
 }
 
+func TestRunProxyAddStripPrefix(t *testing.T) {
+	setupSrvRoot(t)
+	t.Cleanup(docker.SwapNewClientOK())
+	t.Cleanup(mkcert.SwapRunner(stubMkcertRunner{}))
+	resetProxyAddFlags()
+	t.Cleanup(resetProxyAddFlags)
+	proxyAddFlags.domain = "app.test"
+	proxyAddFlags.port = "4000"
+	proxyAddFlags.name = "app-api"
+	proxyAddFlags.stripPrefix = "/api"
+	if err := runProxyAdd(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	meta, err := proxy.Read("app-api")
+	if err != nil || meta == nil {
+		t.Fatalf("metadata: %v %v", meta, err)
+	}
+	if meta.PathPrefix != "/api" || !meta.StripPrefix {
+		t.Errorf("metadata = %+v", meta)
+	}
+	cfg, _ := config.Load()
+	if info := readProxyConfig(cfg, "app-api"); info.PathPrefix != "/api" || !info.StripPrefix {
+		t.Errorf("info = %+v", info)
+	}
+	if err := runProxyList(proxyListCmd, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRunProxyAddTimeoutPreservedOnUpdate(t *testing.T) {
+	setupSrvRoot(t)
+	t.Cleanup(docker.SwapNewClientOK())
+	t.Cleanup(mkcert.SwapRunner(stubMkcertRunner{}))
+	resetProxyAddFlags()
+	t.Cleanup(resetProxyAddFlags)
+	t.Cleanup(func() { proxyUpdateFlags.port = "" })
+	proxyAddFlags.domain = "api.test"
+	proxyAddFlags.port = "3000"
+	proxyAddFlags.name = "api"
+	proxyAddFlags.timeout = "5m"
+	if err := runProxyAdd(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	proxyUpdateFlags.port = "3001"
+	if err := runProxyUpdate(nil, []string{"api"}); err != nil {
+		t.Fatal(err)
+	}
+	cfg, _ := config.Load()
+	data, _ := os.ReadFile(filepath.Join(cfg.TraefikConfDir(), "proxy-api.yml"))
+	if !strings.Contains(string(data), "responseHeaderTimeout: 5m") {
+		t.Errorf("update dropped the timeout:\n%s", data)
+	}
+	if got := formatProxyTimeouts(readProxyConfig(cfg, "api").Timeouts); got != "5m" {
+		t.Errorf("TIMEOUT column = %q", got)
+	}
+}
+
 func TestRunProxyAddBadInput(t *testing.T) {
 	setupSrvRoot(t)
 	resetProxyAddFlags()
@@ -213,7 +368,7 @@ func TestRunProxyAddBadInput(t *testing.T) {
 func TestRunProxyAddExisting(t *testing.T) {
 	setupSrvRoot(t)
 	cfg, _ := config.Load()
-	if err := writeProxyConfig(cfg, "blog", "blog.local", "http://x:8080", "", false); err != nil {
+	if err := writeProxyConfig(cfg, &proxyInput{name: "blog", domain: "blog.local"}, "http://x:8080", nil); err != nil {
 		t.Fatal(err)
 	}
 	resetProxyAddFlags()
@@ -286,7 +441,7 @@ func TestRunProxyAddFallback(t *testing.T) {
 func TestRunProxyAddForceOverwrite(t *testing.T) {
 	setupSrvRoot(t)
 	cfg, _ := config.Load()
-	if err := writeProxyConfig(cfg, "blog", "blog.local", "http://x:8080", "", false); err != nil {
+	if err := writeProxyConfig(cfg, &proxyInput{name: "blog", domain: "blog.local"}, "http://x:8080", nil); err != nil {
 		t.Fatal(err)
 	}
 	t.Cleanup(docker.SwapNewClientOK())
@@ -318,7 +473,7 @@ func TestRunRedirectAddHTTP(t *testing.T) {
 func TestRunProxyListWithProxies(t *testing.T) {
 	setupSrvRoot(t)
 	cfg, _ := config.Load()
-	if err := writeProxyConfig(cfg, "blog", "blog.local", "http://host.docker.internal:8080", "", false); err != nil {
+	if err := writeProxyConfig(cfg, &proxyInput{name: "blog", domain: "blog.local"}, "http://host.docker.internal:8080", nil); err != nil {
 		t.Fatal(err)
 	}
 	t.Cleanup(docker.SwapNewClientErr(errors.New("offline")))
@@ -344,3 +499,83 @@ func TestRunRedirectAddExisting(t *testing.T) {
 		t.Error("expected err: exists without --force")
 	}
 }
+
+func TestRunProxyUpdatePort(t *testing.T) {
+	setupSrvRoot(t)
+	t.Cleanup(func() { proxyUpdateFlags.port, proxyUpdateFlags.container = "", "" })
+	cfg, _ := config.Load()
+	if err := writeProxyConfig(cfg, &proxyInput{name: "blog", domain: "blog.local", wildcard: true}, "http://localhost:8080", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	proxyUpdateFlags.port = "9090"
+	if err := runProxyUpdate(nil, []string{"blog"}); err != nil {
+		t.Fatal(err)
+	}
+	info := readProxyConfig(cfg, "blog")
+	if !strings.HasSuffix(info.Target, ":9090") || info.Domain != "blog.local" || !info.Wildcard {
+		t.Errorf("after update: %+v", info)
+	}
+}
+
+func TestRunProxyUpdateRejects(t *testing.T) {
+	setupSrvRoot(t)
+	t.Cleanup(func() { proxyUpdateFlags.port, proxyUpdateFlags.container = "", "" })
+	if err := runProxyUpdate(nil, []string{"blog"}); err == nil {
+		t.Error("expected err with neither --port nor --container")
+	}
+	proxyUpdateFlags.port, proxyUpdateFlags.container = "9090", "app:80"
+	if err := runProxyUpdate(nil, []string{"blog"}); err == nil {
+		t.Error("expected err with both --port and --container")
+	}
+	proxyUpdateFlags.container = ""
+	if err := runProxyUpdate(nil, []string{"ghost"}); err == nil {
+		t.Error("expected err for missing proxy")
+	}
+	cfg, _ := config.Load()
+	if err := writeProxyConfig(cfg, &proxyInput{name: "blog", domain: "blog.local"}, "http://localhost:8080", nil); err != nil {
+		t.Fatal(err)
+	}
+	proxyUpdateFlags.port = "notaport"
+	if err := runProxyUpdate(nil, []string{"blog"}); err == nil {
+		t.Error("expected err for invalid port")
+	}
+}
+
+func TestProxyListJSON(t *testing.T) {
+	setupSrvRoot(t)
+	t.Cleanup(docker.SwapNewClientOK())
+	t.Cleanup(mkcert.SwapRunner(stubMkcertRunner{}))
+	resetProxyAddFlags()
+	t.Cleanup(resetProxyAddFlags)
+	proxyAddFlags.domain = "api.test"
+	proxyAddFlags.port = "3000"
+	proxyAddFlags.name = "api"
+	proxyAddFlags.timeout = "5m"
+	if err := runProxyAdd(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var list []proxy.ProxyView
+	runJSONCommand(t, &list, "proxy", "list", "--json")
+	if len(list) != 1 {
+		t.Fatalf("list = %+v, want one proxy", list)
+	}
+	if got := list[0]; got.Name != "api" || got.Domain != "api.test" || got.Type != "localhost" || got.Timeout != "5m" {
+		t.Errorf("list[0] = %+v", got)
+	}
+}
+
+func TestRunProxyListCSV(t *testing.T) {
+	setupSrvRoot(t)
+	cfg, _ := config.Load()
+	if err := writeProxyConfig(cfg, &proxyInput{name: "blog", domain: "blog.local"}, "http://host.docker.internal:8080", nil); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(docker.SwapNewClientErr(errors.New("offline")))
+	stdout, _ := executeRoot(t, "proxy", "list", "--format", "csv")
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 2 || lines[0] != "NAME,DOMAIN,TARGET,TYPE,TIMEOUT,SSL,STATUS" || !strings.HasPrefix(lines[1], "blog,blog.local,") {
+		t.Errorf("csv output:\n%s", stdout)
+	}
+}
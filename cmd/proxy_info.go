@@ -0,0 +1,216 @@
+// Package cmd — proxy_info.go implements `srv proxy info`, the proxy
+// counterpart of `srv info`: target, certificate, network connection and
+// recent requests for one proxy.
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/constants"
+	"github.com/stubbedev/srv/internal/docker"
+	"github.com/stubbedev/srv/internal/traefik"
+	"github.com/stubbedev/srv/internal/ui"
+)
+
+var proxyInfoFlags struct {
+	requests int
+}
+
+var proxyInfoCmd = &cobra.Command{
+	Use:   "info NAME",
+	Short: "Show proxy details",
+	Long: `Show a proxy's domain, target, type, SSL certificate and, for container
+proxies, whether the container is connected to srv's Docker network.
+
+--requests N also lists the last N requests Traefik served for the proxy's
+domain, read from the JSON access log.
+
+Examples:
+  srv proxy info api-test
+  srv proxy info api-test --requests 20`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			_ = cmd.Help()
+			return ui.UsageError("srv proxy info NAME", "a proxy name is required")
+		}
+		if len(args) > 1 {
+			return ui.UsageError("srv proxy info NAME", "too many arguments — expected a single proxy name, got %d", len(args))
+		}
+		return nil
+	},
+	RunE: runProxyInfo,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return proxyNameCompletions(), cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+// proxyNetworkCheck reports whether a container is on a network. Tests swap it.
+var proxyNetworkCheck = docker.IsContainerOnNetwork
+
+func runProxyInfo(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if proxyInfoFlags.requests < 0 {
+		return fmt.Errorf("invalid --requests %d: expected a positive number", proxyInfoFlags.requests)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, p := range getProxyNames() {
+		if p == name {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("proxy '%s' not found", name)
+	}
+	info := readProxyConfig(cfg, name)
+
+	ui.Blank()
+	ui.Bold("Proxy: %s", name)
+	ui.Blank()
+
+	if info.Domain != "" {
+		ui.Print("  Domain:  %s", info.Domain)
+		if info.Wildcard {
+			ui.Print("  Alias:   *.%s", info.Domain)
+		}
+	}
+	ui.Print("  Target:  %s", info.Target)
+	for _, b := range info.Backends {
+		ui.Print("  Backend: %s", b)
+	}
+	if info.Container != "" {
+		ui.Print("  Type:    %s (%s)", constants.ProxyTypeContainer, info.Container)
+	} else {
+		ui.Print("  Type:    %s", constants.ProxyTypeLocalhost)
+	}
+	if info.PathPrefix != "" {
+		if info.StripPrefix {
+			ui.Print("  Path:    %s (stripped)", info.PathPrefix)
+		} else {
+			ui.Print("  Path:    %s", info.PathPrefix)
+		}
+	}
+	if info.Timeouts != (traefik.ProxyTimeouts{}) {
+		ui.Print("  Timeout: %s", formatProxyTimeouts(info.Timeouts))
+	}
+	if info.WebSocket {
+		ui.Print("  WebSocket: enabled")
+	}
+	if info.GRPC != "" {
+		ui.Print("  gRPC:    %s", info.GRPC)
+	}
+	configPath := proxyConfigPath(cfg, name)
+	if info.TCP {
+		tls := "terminated"
+		if info.Passthrough {
+			tls = "passthrough"
+		}
+		ui.Print("  TCP:     port %d, TLS %s", info.EntryPointPort, tls)
+		configPath = traefik.TCPProxyConfigPath(cfg, name)
+	}
+	ui.Print("  Config:  %s", configPath)
+	if info.Container != "" {
+		showProxyNetwork(name, info.Container, cfg.NetworkName)
+	}
+	ui.Blank()
+
+	// A passthrough proxy has no local certificate; the upstream serves one.
+	if info.Domain != "" && !info.Passthrough {
+		showProxyCertInfo(name, info.Domain)
+	}
+
+	// Traefik's access log only records HTTP requests.
+	if proxyInfoFlags.requests > 0 && info.Domain != "" && !info.TCP {
+		ui.Blank()
+		showProxyRequests(cfg, info, proxyInfoFlags.requests)
+	}
+
+	ui.Blank()
+	return nil
+}
+
+// showProxyNetwork prints whether a container proxy's target is attached to
+// the network Traefik routes over.
+func showProxyNetwork(name, container, network string) {
+	on, err := proxyNetworkCheck(container, network)
+	switch {
+	case err != nil:
+		ui.Print("  Network: %s", ui.WarnText("unknown"))
+		ui.IndentedWarn(1, "Could not inspect container %s: %v", container, err)
+	case on:
+		ui.Print("  Network: %s", ui.SuccessText("connected to "+network))
+	default:
+		ui.Print("  Network: %s", ui.ErrorText("not connected to "+network))
+		ui.IndentedDim(1, "Reconnect with 'srv proxy update %s --container %s:PORT'", name, container)
+	}
+}
+
+// showProxyCertInfo prints the proxy's local certificate, as showCertInfo
+// does for sites.
+func showProxyCertInfo(name, domain string) {
+	cert := traefik.GetLocalCertInfo(proxyCertSiteName(name), domain)
+	ui.Bold("SSL Certificate")
+	ui.Print("  Domain:  %s", domain)
+	switch status := cert.Status(); status {
+	case traefik.CertStatusMissing, traefik.CertStatusCorrupt:
+		ui.Print("  Status:  %s", ui.StatusColor(string(status)))
+	case traefik.CertStatusExpired:
+		ui.Print("  Status:  %s", ui.StatusColor(string(status)))
+		ui.Print("  Expires: %s", cert.ExpiresAt.Format(constants.DateFormat))
+	default:
+		ui.Print("  Status:  %s (%d days left)", ui.StatusColor(string(status)), cert.DaysLeft)
+		ui.Print("  Expires: %s", cert.ExpiresAt.Format(constants.DateFormat))
+	}
+}
+
+// showProxyRequests prints the last n access log entries for the proxy's
+// domain.
+func showProxyRequests(cfg *config.Config, info proxyConfigInfo, n int) {
+	ui.Bold("Recent Requests")
+	path := cfg.TraefikAccessLogPath()
+	file, err := os.Open(path) //nolint:gosec // path is srv's own access log
+	if err != nil {
+		if os.IsNotExist(err) {
+			ui.IndentedDim(1, "No access log yet at %s", path)
+		} else {
+			ui.IndentedWarn(1, "Could not read access log: %v", err)
+		}
+		return
+	}
+	defer func() { _ = file.Close() }()
+
+	domains := []string{info.Domain}
+	if info.Wildcard {
+		domains = append(domains, "*."+info.Domain)
+	}
+	entries, unparsed, err := traefik.ReadAccessLog(file, traefik.AccessLogFilter{Domains: domains}, n)
+	if err != nil {
+		ui.IndentedWarn(1, "Could not read access log: %v", err)
+		return
+	}
+	if len(entries) == 0 {
+		if unparsed > 0 {
+			ui.IndentedWarn(1, "%d access log lines aren't JSON; see 'srv logs --access' for how to switch the format", unparsed)
+		} else {
+			ui.IndentedDim(1, "No requests for %s", strings.Join(domains, ", "))
+		}
+		return
+	}
+	rows := make([][]string, 0, len(entries))
+	for _, e := range entries {
+		rows = append(rows, accessLogRow(e))
+	}
+	ui.PrintTable(accessLogHeaders, rows)
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/docker"
+	"github.com/stubbedev/srv/internal/mkcert"
+)
+
+func TestRunProxyInfoMissing(t *testing.T) {
+	setupSrvRoot(t)
+	if err := runProxyInfo(nil, []string{"nope"}); err == nil {
+		t.Error("expected error for unknown proxy")
+	}
+}
+
+func TestRunProxyInfoWithRequests(t *testing.T) {
+	setupSrvRoot(t)
+	t.Cleanup(docker.SwapNewClientOK())
+	t.Cleanup(mkcert.SwapRunner(stubMkcertRunner{}))
+	resetProxyAddFlags()
+	t.Cleanup(resetProxyAddFlags)
+	proxyAddFlags.domain = "blog.local"
+	proxyAddFlags.port = "8080"
+	proxyAddFlags.name = "blog"
+	if err := runProxyAdd(nil, nil); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	logPath := cfg.TraefikAccessLogPath()
+	if err := os.MkdirAll(filepath.Dir(logPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	line := `{"StartUTC":"2026-01-02T03:04:05Z","RequestHost":"blog.local","ClientHost":"127.0.0.1","RequestMethod":"GET","RequestPath":"/","DownstreamStatus":200,"Duration":1000}` + "\n"
+	if err := os.WriteFile(logPath, []byte(line), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	proxyInfoFlags.requests = 5
+	t.Cleanup(func() { proxyInfoFlags.requests = 0 })
+	if err := runProxyInfo(nil, []string{"blog"}); err != nil {
+		t.Errorf("info: %v", err)
+	}
+}
+
+func TestRunProxyInfoNegativeRequests(t *testing.T) {
+	setupSrvRoot(t)
+	proxyInfoFlags.requests = -1
+	t.Cleanup(func() { proxyInfoFlags.requests = 0 })
+	if err := runProxyInfo(nil, []string{"blog"}); err == nil {
+		t.Error("expected error for negative --requests")
+	}
+}
+
+func TestShowProxyNetworkInspectError(t *testing.T) {
+	var gotContainer, gotNetwork string
+	prev := proxyNetworkCheck
+	proxyNetworkCheck = func(container, network string) (bool, error) {
+		gotContainer, gotNetwork = container, network
+		return false, errors.New("no such container")
+	}
+	t.Cleanup(func() { proxyNetworkCheck = prev })
+	showProxyNetwork("redis", "redis", "srv-net")
+	if gotContainer != "redis" || gotNetwork != "srv-net" {
+		t.Errorf("checked %q on %q", gotContainer, gotNetwork)
+	}
+}
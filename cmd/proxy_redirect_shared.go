@@ -11,15 +11,14 @@ import (
 	"strings"
 
 	"github.com/stubbedev/srv/internal/config"
-	"github.com/stubbedev/srv/internal/constants"
 	"github.com/stubbedev/srv/internal/traefik"
 	"github.com/stubbedev/srv/internal/ui"
 )
 
 // scanConfigNames lists the short names of every Traefik conf file matching
-// prefix + <name> + .yml. Shared by `srv proxy list` and `srv redirect list`,
-// which differ only in the filename prefix they scan for.
-func scanConfigNames(prefix string) []string {
+// prefix + <name> + suffix. Shared by `srv proxy list` and `srv redirect
+// list`, which differ only in the filename prefix they scan for.
+func scanConfigNames(prefix, suffix string) []string {
 	cfg, err := config.Load()
 	if err != nil {
 		ui.VerboseLog("Warning: could not load config: %v", err)
@@ -33,8 +32,8 @@ func scanConfigNames(prefix string) []string {
 	var names []string
 	for _, entry := range entries {
 		name := entry.Name()
-		if strings.HasPrefix(name, prefix) && strings.HasSuffix(name, constants.ExtYAML) {
-			names = append(names, strings.TrimSuffix(strings.TrimPrefix(name, prefix), constants.ExtYAML))
+		if strings.HasPrefix(name, prefix) && strings.HasSuffix(name, suffix) {
+			names = append(names, strings.TrimSuffix(strings.TrimPrefix(name, prefix), suffix))
 		}
 	}
 	return names
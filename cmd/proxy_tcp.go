@@ -0,0 +1,211 @@
+// Package cmd — proxy_tcp.go implements `srv proxy add --tcp`: proxies for
+// non-HTTP services (Redis, PostgreSQL, SMTP) routed by TLS SNI on a
+// dedicated TCP entrypoint. The config goes to proxy-tcp-<name>.yml; list,
+// info and remove handle both kinds through getProxyNames/readProxyConfig.
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/constants"
+	"github.com/stubbedev/srv/internal/proxy"
+	"github.com/stubbedev/srv/internal/site"
+	"github.com/stubbedev/srv/internal/traefik"
+	"github.com/stubbedev/srv/internal/ui"
+)
+
+// validateTCPProxyInput validates `srv proxy add --tcp`. TCP proxies take a
+// single --port or --container upstream; the HTTP-only flags are rejected.
+func validateTCPProxyInput() (*proxyInput, error) {
+	f := proxyAddFlags
+	for _, c := range []struct {
+		set  bool
+		flag string
+	}{
+		{len(f.backends) > 0, "--backend"},
+		{f.lbMethod != "", "--lb-method"},
+		{f.fallbackURL != "", "--fallback"},
+		{f.pathPrefix != "" || f.stripPrefix != "", "--path-prefix/--strip-prefix"},
+		{f.timeout != "" || f.readTimeout != "" || f.dialTimeout != "", "--timeout"},
+		{f.websocket, "--websocket"},
+		{f.grpc || f.grpcTLS, "--grpc"},
+		{f.wildcard, "--wildcard"},
+	} {
+		if c.set {
+			return nil, fmt.Errorf("%s does not apply to TCP proxies", c.flag)
+		}
+	}
+	if f.port != "" && f.container != "" {
+		return nil, fmt.Errorf("--port and --container are mutually exclusive")
+	}
+	if f.port == "" && f.container == "" {
+		return nil, fmt.Errorf("either --port or --container must be specified")
+	}
+	if err := validateTCPEntryPointPort(f.entrypointPort); err != nil {
+		return nil, err
+	}
+	if err := ValidateDomain(f.domain); err != nil {
+		return nil, fmt.Errorf("invalid domain: %w", err)
+	}
+
+	input := &proxyInput{domain: f.domain}
+	if err := parseProxyTarget(input, f.port, f.container); err != nil {
+		return nil, err
+	}
+	name := f.name
+	if name == "" {
+		name = site.SanitizeName(f.domain)
+	}
+	if err := ValidateProxyName(name); err != nil {
+		return nil, fmt.Errorf("invalid proxy name: %w", err)
+	}
+	input.name = name
+	return input, nil
+}
+
+// validateTCPEntryPointPort checks --entrypoint-port: required, in range, and
+// not one of the ports srv already listens on.
+func validateTCPEntryPointPort(port int) error {
+	if port == 0 {
+		return fmt.Errorf("--tcp needs --entrypoint-port, the port clients connect to (e.g. --entrypoint-port 6380)")
+	}
+	if err := ValidatePort(port); err != nil {
+		return fmt.Errorf("invalid --entrypoint-port: %w", err)
+	}
+	if slices.Contains(traefik.ReservedTCPPorts, port) {
+		return fmt.Errorf("--entrypoint-port %d is already used by srv (reserved: %v)", port, traefik.ReservedTCPPorts)
+	}
+	return nil
+}
+
+func runTCPProxyAdd(cfg *config.Config) error {
+	input, err := validateTCPProxyInput()
+	if err != nil {
+		return err
+	}
+	port := proxyAddFlags.entrypointPort
+	passthrough := proxyAddFlags.passthrough
+
+	if _, err := os.Stat(proxyConfigPath(cfg, input.name)); err == nil && !proxy.IsTCP(input.name) {
+		return fmt.Errorf("an HTTP proxy named '%s' already exists; pick another --name", input.name)
+	}
+	previous, _ := proxy.Read(input.name)
+	if _, err := os.Stat(traefik.TCPProxyConfigPath(cfg, input.name)); err == nil && !proxyAddFlags.force {
+		return fmt.Errorf("proxy '%s' already exists. Use --force to overwrite", input.name)
+	}
+
+	// Passthrough leaves TLS to the upstream, so only a terminating proxy
+	// needs the local certificate.
+	if !passthrough {
+		if err := setupProxyCertificate(input); err != nil {
+			return err
+		}
+	}
+	if err := traefik.RegisterLocalDomain(input.domain, false); err != nil {
+		ui.Warn("Failed to register DNS for %s: %v", input.domain, err)
+	}
+	targetURL, err := connectProxyContainer(input, cfg)
+	if err != nil {
+		return err
+	}
+	address, err := tcpAddress(targetURL)
+	if err != nil {
+		return err
+	}
+
+	if err := traefik.WriteTCPProxyConfig(cfg, traefik.TCPProxyRoute{
+		Name:           input.name,
+		Domain:         input.domain,
+		Address:        address,
+		Container:      input.containerName,
+		EntryPointPort: port,
+		Passthrough:    passthrough,
+	}); err != nil {
+		return err
+	}
+	if err := proxy.Write(proxy.Metadata{
+		Name:           input.name,
+		Domains:        []string{input.domain},
+		IsLocal:        true,
+		TCP:            true,
+		EntryPointPort: port,
+		Passthrough:    passthrough,
+	}); err != nil {
+		ui.Warn("Failed to write proxy metadata sidecar: %v", err)
+	}
+
+	changed, err := traefik.SetTCPEntryPoint(port, true)
+	if err != nil {
+		return fmt.Errorf("add TCP entrypoint: %w", err)
+	}
+	// A --force re-create on another port leaves the old entrypoint behind.
+	if previous != nil && previous.TCP && previous.EntryPointPort != port && !proxy.TCPPortInUse(previous.EntryPointPort, input.name) {
+		removed, err := traefik.SetTCPEntryPoint(previous.EntryPointPort, false)
+		if err != nil {
+			ui.Warn("Failed to remove TCP entrypoint %d: %v", previous.EntryPointPort, err)
+		}
+		changed = changed || removed
+	}
+	if changed {
+		ui.Dim("Recreating Traefik to listen on port %d", port)
+		if err := traefik.ApplyTCPEntryPoints(); err != nil {
+			return fmt.Errorf("recreate Traefik: %w", err)
+		}
+	}
+	if err := traefik.UpdateDynamicConfig(); err != nil {
+		ui.Warn("Failed to update Traefik config: %v", err)
+	}
+
+	ui.Success("TCP proxy '%s' created", input.name)
+	ui.Dim("%s:%d -> %s", input.domain, port, address)
+	if passthrough {
+		ui.Dim("TLS is passed through; the upstream must serve a certificate for %s", input.domain)
+	} else {
+		ui.Dim("Clients must connect with TLS and SNI %s; the upstream gets plain TCP", input.domain)
+	}
+	return nil
+}
+
+// tcpAddress turns the upstream URL connectProxyContainer builds into the
+// host:port a TCP service dials.
+func tcpAddress(targetURL string) (string, error) {
+	u, err := url.Parse(targetURL)
+	if err != nil || u.Port() == "" {
+		return "", fmt.Errorf("invalid upstream %q", targetURL)
+	}
+	return net.JoinHostPort(u.Hostname(), u.Port()), nil
+}
+
+// readTCPProxyConfig is readProxyConfig for TCP proxies.
+func readTCPProxyConfig(cfg *config.Config, name string) proxyConfigInfo {
+	info := proxyConfigInfo{Target: "unknown", TCP: true}
+	route, err := traefik.ReadTCPProxyConfig(cfg, name)
+	if err != nil {
+		return info
+	}
+	info.Domain = route.Domain
+	if route.Address != "" {
+		info.Target = route.Address
+		info.Container = traefik.ContainerFromURL("tcp://" + route.Address)
+	}
+	info.EntryPointPort = route.EntryPointPort
+	info.Passthrough = route.Passthrough
+	return info
+}
+
+// proxyConfigPath returns the Traefik config file of an HTTP proxy.
+func proxyConfigPath(cfg *config.Config, name string) string {
+	return filepath.Join(cfg.TraefikConfDir(), constants.ProxyConfigPrefix+name+constants.ExtYAML)
+}
+
+// formatTCPListener renders a TCP proxy's listener for tables: DOMAIN:PORT.
+func formatTCPListener(domain string, port int) string {
+	return net.JoinHostPort(domain, strconv.Itoa(port))
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/proxy"
+)
+
+func TestValidateTCPEntryPointPort(t *testing.T) {
+	for _, port := range []int{0, 80, 443, 8080, 70000} {
+		if err := validateTCPEntryPointPort(port); err == nil {
+			t.Errorf("port %d: expected error", port)
+		}
+	}
+	if err := validateTCPEntryPointPort(6380); err != nil {
+		t.Errorf("port 6380: %v", err)
+	}
+}
+
+func TestValidateTCPProxyInput(t *testing.T) {
+	t.Cleanup(resetProxyAddFlags)
+	resetProxyAddFlags()
+	proxyAddFlags.tcp = true
+	proxyAddFlags.domain = "redis.test"
+	proxyAddFlags.port = "6379"
+	proxyAddFlags.entrypointPort = 6380
+
+	input, err := validateTCPProxyInput()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if input.name != "redis-test" || input.port != "6379" {
+		t.Errorf("input = %+v", input)
+	}
+
+	proxyAddFlags.websocket = true
+	if _, err := validateTCPProxyInput(); err == nil {
+		t.Error("expected --websocket to be rejected for a TCP proxy")
+	}
+	proxyAddFlags.websocket = false
+	proxyAddFlags.entrypointPort = 443
+	if _, err := validateTCPProxyInput(); err == nil {
+		t.Error("expected --entrypoint-port 443 to be rejected")
+	}
+}
+
+func TestTCPAddress(t *testing.T) {
+	if got, err := tcpAddress("http://redis:6379"); err != nil || got != "redis:6379" {
+		t.Errorf("tcpAddress = %q, %v", got, err)
+	}
+	if _, err := tcpAddress("http://redis"); err == nil {
+		t.Error("expected error for an upstream without a port")
+	}
+}
+
+// TestGetProxyNamesTCP: proxy-tcp-<name>.yml lists as <name> when the
+// metadata marks it a TCP proxy, and as tcp-<name> (a legacy HTTP proxy)
+// otherwise.
+func TestGetProxyNamesTCP(t *testing.T) {
+	setupSrvRoot(t)
+	cfg, _ := config.Load()
+	for _, f := range []string{"proxy-web.yml", "proxy-tcp-redis.yml", "proxy-tcp-old.yml"} {
+		if err := os.WriteFile(filepath.Join(cfg.TraefikConfDir(), f), []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := proxy.Write(proxy.Metadata{Name: "redis", TCP: true, EntryPointPort: 6380}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := getProxyNames(), []string{"redis", "tcp-old", "web"}; !slices.Equal(got, want) {
+		t.Errorf("getProxyNames = %v, want %v", got, want)
+	}
+	if err := proxy.CheckHTTPName("redis"); err == nil {
+		t.Error("expected an HTTP proxy to be refused a TCP proxy's name")
+	}
+	if err := proxy.CheckHTTPName("tcp-new"); err == nil {
+		t.Error("expected the tcp- prefix to be reserved")
+	}
+}
@@ -434,7 +434,7 @@ func getRedirectSSLStatus(name, domain string) string {
 }
 
 func getRedirectNames() []string {
-	return scanConfigNames(constants.RedirectConfigPrefix)
+	return scanConfigNames(constants.RedirectConfigPrefix, constants.ExtYAML)
 }
 
 // =============================================================================
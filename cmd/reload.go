@@ -8,7 +8,6 @@ import (
 
 	"github.com/spf13/cobra"
 
-	"github.com/stubbedev/srv/internal/docker"
 	"github.com/stubbedev/srv/internal/site"
 	"github.com/stubbedev/srv/internal/ui"
 )
@@ -93,8 +92,11 @@ func reloadOne(name string) error {
 		if s.IsBroken {
 			return fmt.Errorf("site is broken (target directory missing)")
 		}
+		if s.Disabled {
+			return site.DisabledError(name)
+		}
 		ui.Info("Restarting %s...", name)
-		if err := docker.ComposeUpWithProfile(s.ComposeDir, s.Profile); err != nil {
+		if err := site.ComposeUp(s, false); err != nil {
 			return fmt.Errorf("docker compose up: %w", err)
 		}
 		ui.Success("Reloaded and restarted %s", name)
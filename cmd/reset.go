@@ -0,0 +1,191 @@
+// Package cmd — reset.go implements `srv reset`, which rebuilds part of the
+// generated configuration while keeping the registered sites: all of
+// Traefik's directory (--traefik-only) or just the proxies (--proxy-only).
+package cmd
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/constants"
+	"github.com/stubbedev/srv/internal/docker"
+	"github.com/stubbedev/srv/internal/metrics"
+	"github.com/stubbedev/srv/internal/proxy"
+	"github.com/stubbedev/srv/internal/site"
+	"github.com/stubbedev/srv/internal/traefik"
+	"github.com/stubbedev/srv/internal/ui"
+)
+
+var resetFlags struct {
+	traefikOnly bool
+	proxyOnly   bool
+	force       bool
+}
+
+// metricsProxyName is the proxy config `srv metrics enable` writes; like the
+// dashboard proxy it belongs to srv, so --proxy-only leaves it alone.
+const metricsProxyName = "metrics"
+
+var resetCmd = &cobra.Command{
+	Use:   "reset",
+	Short: "Rebuild Traefik or proxy configuration, keeping registered sites",
+	Long: `Rebuild part of srv's generated configuration without touching the
+registered sites. Use it when Traefik's config is broken but the sites are fine;
+'srv install --fresh' removes everything instead.
+
+--traefik-only removes the Traefik directory (static and dynamic config,
+Let's Encrypt certificates, logs, DNS registrations, proxies and redirects),
+regenerates it, re-applies every site's routing and restarts Traefik.
+
+--proxy-only removes every proxy with its certificate and DNS entry. The
+dashboard and metrics proxies are kept.
+
+Both refresh the TLS certificate list afterwards. Without --force the command
+only lists what it would remove.
+
+Examples:
+  srv reset --traefik-only --force
+  srv reset --proxy-only --force`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 0 {
+			return ui.UsageError("srv reset --traefik-only|--proxy-only", "unexpected arguments: %v", args)
+		}
+		if resetFlags.traefikOnly == resetFlags.proxyOnly {
+			return ui.UsageError("srv reset --traefik-only|--proxy-only", "pass exactly one of --traefik-only or --proxy-only")
+		}
+		return nil
+	},
+	RunE: runReset,
+}
+
+func init() {
+	resetCmd.Flags().BoolVar(&resetFlags.traefikOnly, "traefik-only", false, "Rebuild the whole Traefik directory")
+	resetCmd.Flags().BoolVar(&resetFlags.proxyOnly, "proxy-only", false, "Remove every proxy")
+	resetCmd.Flags().BoolVarP(&resetFlags.force, "force", "f", false, "Skip confirmation prompt")
+	resetCmd.GroupID = GroupSystem
+	RootCmd.AddCommand(resetCmd)
+}
+
+func runReset(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if resetFlags.proxyOnly {
+		return resetProxies(cfg)
+	}
+	return resetTraefik(cfg)
+}
+
+// resetTraefik rebuilds cfg.TraefikDir from scratch and re-applies every
+// site's routing to it.
+func resetTraefik(cfg *config.Config) error {
+	if !resetFlags.force {
+		ui.Warn("This will rebuild srv's Traefik configuration:")
+		ui.Blank()
+		ui.Print("  - Stop the Traefik and DNS containers")
+		ui.Print("  - Remove %s, including proxies, redirects and Let's Encrypt certificates", cfg.TraefikDir)
+		ui.Print("  - Regenerate it and re-apply the routing of every registered site")
+		ui.Blank()
+		ui.Dim("Registered sites in %s are kept.", cfg.SitesDir)
+		return fmt.Errorf("reset refused: re-run with --force to proceed")
+	}
+	if err := docker.EnsureRunning(); err != nil {
+		return err
+	}
+	sites, err := site.List()
+	if err != nil {
+		return err
+	}
+	// The email lives in env.traefik under the srv root, so it survives.
+	email, _ := traefik.GetEmail("")
+
+	ui.Info("Removing Traefik configuration...")
+	if err := traefik.ResetTraefikDir(); err != nil {
+		return err
+	}
+	if _, err := traefik.EnsureConfig(email); err != nil {
+		return err
+	}
+	ui.Success("Traefik configuration regenerated")
+
+	for _, s := range sites {
+		res, err := site.ForceReload(s.Name)
+		if err != nil {
+			ui.Warn("Failed to regenerate config for %s: %v", s.Name, err)
+			continue
+		}
+		for _, w := range res.Warnings {
+			ui.Warn("%s: %s", s.Name, w)
+		}
+	}
+	if len(sites) > 0 {
+		ui.Success("Routing re-applied for %d site(s)", len(sites))
+	}
+	if traefik.CheckMkcert() == nil {
+		if err := traefik.SetupDashboardProxy(); err != nil {
+			ui.Warn("Failed to set up dashboard proxy: %v", err)
+		}
+	}
+
+	if err := docker.ComposeUp(cfg.TraefikDir); err != nil {
+		return fmt.Errorf("failed to start Traefik: %w", err)
+	}
+	if err := traefik.UpdateDynamicConfig(); err != nil {
+		ui.Warn("Failed to refresh Traefik dynamic config: %v", err)
+	}
+	if err := traefik.MarkInstalled(cfg, Version); err != nil {
+		ui.Warn("Failed to record installed version: %v", err)
+	}
+	ui.Success("Traefik restarted")
+	if metrics.IsConfigured(cfg) {
+		ui.Dim("Run 'srv metrics enable' to restore the metrics routes")
+	}
+	return nil
+}
+
+// resetProxies removes every user proxy the way `srv proxy remove` does.
+func resetProxies(cfg *config.Config) error {
+	var names []string
+	for _, name := range getProxyNames() {
+		if name != constants.TraefikDashboardProxyName && name != metricsProxyName {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	if !resetFlags.force {
+		if len(names) == 0 {
+			ui.Dim("No proxies to remove")
+			return nil
+		}
+		ui.Warn("This will remove %d proxy(ies) with their certificates and DNS entries:", len(names))
+		for _, name := range names {
+			ui.Print("  - %s", name)
+		}
+		return fmt.Errorf("reset refused: re-run with --force to proceed")
+	}
+
+	removed := 0
+	for _, name := range names {
+		if err := removeFallbackSidecar(cfg, name); err != nil {
+			ui.Warn("%s: failed to remove fallback sidecar: %v", name, err)
+		}
+		warnings, err := proxy.RemoveProxy(cfg, name)
+		if err != nil {
+			ui.Warn("%s: %v", name, err)
+			continue
+		}
+		for _, w := range warnings {
+			ui.Warn("%s: %s", name, w)
+		}
+		removed++
+	}
+	if err := traefik.UpdateDynamicConfig(); err != nil {
+		ui.Warn("Failed to refresh Traefik dynamic config: %v", err)
+	}
+	ui.Success("Removed %d proxy(ies)", removed)
+	return nil
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResetProxies(t *testing.T) {
+	root := setupSrvRoot(t)
+	conf := filepath.Join(root, "traefik", "conf")
+	for _, name := range []string{"proxy-api.yml", "proxy-traefik.yml", "proxy-metrics.yml", "redirect-old.yml"} {
+		if err := os.WriteFile(filepath.Join(conf, name), []byte("http: {}\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Cleanup(func() { resetFlags.proxyOnly, resetFlags.force = false, false })
+	resetFlags.proxyOnly = true
+
+	if err := runReset(nil, nil); err == nil {
+		t.Fatal("expected reset to refuse without --force")
+	}
+	if _, err := os.Stat(filepath.Join(conf, "proxy-api.yml")); err != nil {
+		t.Fatalf("proxy removed without --force: %v", err)
+	}
+
+	resetFlags.force = true
+	if err := runReset(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(conf, "proxy-api.yml")); !os.IsNotExist(err) {
+		t.Errorf("proxy-api.yml still present (err %v)", err)
+	}
+	for _, kept := range []string{"proxy-traefik.yml", "proxy-metrics.yml", "redirect-old.yml", "traefik-dynamic.yml"} {
+		if _, err := os.Stat(filepath.Join(conf, kept)); err != nil {
+			t.Errorf("%s: %v", kept, err)
+		}
+	}
+}
+
+func TestResetArgs(t *testing.T) {
+	t.Cleanup(func() { resetFlags.traefikOnly, resetFlags.proxyOnly = false, false })
+	if err := resetCmd.Args(resetCmd, nil); err == nil {
+		t.Error("expected an error without --traefik-only or --proxy-only")
+	}
+	resetFlags.traefikOnly, resetFlags.proxyOnly = true, true
+	if err := resetCmd.Args(resetCmd, nil); err == nil {
+		t.Error("expected an error with both flags")
+	}
+	resetFlags.proxyOnly = false
+	if err := resetCmd.Args(resetCmd, nil); err != nil {
+		t.Errorf("--traefik-only: %v", err)
+	}
+}
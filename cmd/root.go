@@ -7,8 +7,10 @@ import (
 	"os/exec"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	"github.com/stubbedev/srv/internal/constants"
+	"github.com/stubbedev/srv/internal/docker"
 	"github.com/stubbedev/srv/internal/proxy"
 	"github.com/stubbedev/srv/internal/site"
 	"github.com/stubbedev/srv/internal/ui"
@@ -28,17 +30,31 @@ var (
 	BuildDate = constants.DefaultBuildDate
 
 	// Root command flags
-	verbose      bool
-	quiet        bool
-	outputFormat string
+	verbose       bool
+	quiet         bool
+	outputFormat  string
+	jsonFlag      bool
+	dockerContext string
+	// dockerTimeouts holds --connect-timeout and --response-timeout.
+	dockerTimeouts docker.Timeouts
 )
 
 // RootCmd is the root command for srv.
 var RootCmd = &cobra.Command{
 	Use: constants.AppName,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if dockerTimeouts.Connect < 0 || dockerTimeouts.Response < 0 {
+			return fmt.Errorf("--connect-timeout and --response-timeout must not be negative")
+		}
 		ui.Verbose = verbose
-		ui.Quiet = quiet
+		// JSON output is for scripts; keep the diagnostics out of their way.
+		ui.Quiet = quiet || jsonOutput() || csvOutput()
+		if dockerContext == "" {
+			dockerContext = os.Getenv("DOCKER_CONTEXT")
+		}
+		docker.SetContext(dockerContext)
+		docker.SetTimeouts(dockerTimeouts)
+		return nil
 	},
 	SilenceUsage:  true,
 	SilenceErrors: true,
@@ -46,8 +62,18 @@ var RootCmd = &cobra.Command{
 
 func init() {
 	RootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
-	RootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Suppress informational diagnostic output (errors still printed)")
-	RootCmd.PersistentFlags().StringVar(&outputFormat, "format", "table", "Output format for list/inspect commands: 'table' (default, human-readable) or 'json' (scriptable)")
+	RootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Suppress informational diagnostic output (errors and warnings still printed; implied by --json)")
+	RootCmd.PersistentFlags().StringVar(&dockerContext, "docker-context", "", "Docker context to run against (default: $DOCKER_CONTEXT, then the docker CLI's current context)")
+	RootCmd.PersistentFlags().DurationVar(&dockerTimeouts.Connect, "connect-timeout", 0, "How long to wait for the Docker daemon to answer (default 10s, 30s over SSH)")
+	RootCmd.PersistentFlags().DurationVar(&dockerTimeouts.Response, "response-timeout", 0, "How long Docker status and inspect calls may take (default 30s); compose operations get at least this long (default 5m)")
+	RootCmd.PersistentFlags().StringVar(&outputFormat, "format", "table", "Output format for list/inspect commands: 'table' (default, human-readable), 'json' (scriptable) or 'csv' (list commands; alias --output)")
+	RootCmd.SetGlobalNormalizationFunc(func(f *pflag.FlagSet, name string) pflag.NormalizedName {
+		// --output is an alias for --format.
+		if name == "output" {
+			name = "format"
+		}
+		return pflag.NormalizedName(name)
+	})
 
 	// Define command groups
 	RootCmd.AddGroup(
@@ -76,10 +102,21 @@ func SetVersion(version, commit, buildDate string) {
 // =============================================================================
 
 // jsonOutput reports whether the user requested machine-readable output via
-// --format json. List/inspect commands branch on this to emit json instead of
-// a coloured table.
+// --format json (or a command's --json). List/inspect commands branch on this
+// to emit json instead of a coloured table.
 func jsonOutput() bool {
-	return outputFormat == "json"
+	return outputFormat == "json" || jsonFlag
+}
+
+// csvOutput reports whether the user asked for --format csv, which list
+// commands print as spreadsheet-friendly CSV instead of a table.
+func csvOutput() bool {
+	return outputFormat == "csv" && !jsonFlag
+}
+
+// addJSONFlag gives cmd a --json flag, shorthand for --format json.
+func addJSONFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&jsonFlag, "json", false, "Print JSON (same as --format json)")
 }
 
 // GetSiteNames returns a list of all registered site names for shell completion.
@@ -185,6 +222,14 @@ func TypeLabel(isLocal bool) string {
 	return constants.TypeLabelProduction
 }
 
+// SSLLabel is TypeLabel with Let's Encrypt staging sites called out.
+func SSLLabel(isLocal, staging bool) string {
+	if !isLocal && staging {
+		return constants.TypeLabelStaging
+	}
+	return TypeLabel(isLocal)
+}
+
 // CommandExists checks if a command is available in PATH.
 func CommandExists(name string) bool {
 	_, err := exec.LookPath(name)
@@ -1,11 +1,16 @@
 package cmd
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/stubbedev/srv/internal/docker"
 	"github.com/stubbedev/srv/internal/site"
+	"github.com/stubbedev/srv/internal/ui"
 )
 
 func TestTypeLabel(t *testing.T) {
@@ -150,3 +155,59 @@ func TestExecuteVersionCommand(t *testing.T) {
 		t.Errorf("err: %v", err)
 	}
 }
+
+// executeRoot runs srv with args through the root command and returns what it
+// wrote to stdout and stderr. Global flags are reset afterwards.
+func executeRoot(t *testing.T, args ...string) (string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	t.Cleanup(ui.SwapStdout(&stdout))
+	t.Cleanup(ui.SwapStderr(&stderr))
+	t.Cleanup(func() {
+		quiet, jsonFlag, ui.Quiet, outputFormat = false, false, false, "table"
+		RootCmd.SetArgs(nil)
+	})
+	RootCmd.SetArgs(args)
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("srv %s: %v", strings.Join(args, " "), err)
+	}
+	return stdout.String(), stderr.String()
+}
+
+func TestQuietFlag(t *testing.T) {
+	setupSrvRoot(t)
+	stdout, stderr := executeRoot(t, "list", "--quiet")
+	if stdout != "" || stderr != "" {
+		t.Errorf("srv list --quiet printed stdout %q, stderr %q; want nothing", stdout, stderr)
+	}
+}
+
+func TestJSONImpliesQuiet(t *testing.T) {
+	setupSrvRoot(t)
+	for _, args := range [][]string{{"list", "--json", "--quiet"}, {"list", "--json"}} {
+		t.Run(strings.Join(args[1:], " "), func(t *testing.T) {
+			stdout, stderr := executeRoot(t, args...)
+			if strings.TrimSpace(stdout) != "[]" || stderr != "" {
+				t.Errorf("stdout %q, stderr %q; want only []", stdout, stderr)
+			}
+		})
+	}
+}
+
+func TestTimeoutFlags(t *testing.T) {
+	setupSrvRoot(t)
+	t.Cleanup(func() {
+		dockerTimeouts = docker.Timeouts{}
+		docker.SetTimeouts(docker.Timeouts{})
+	})
+	executeRoot(t, "list", "--connect-timeout", "45s", "--response-timeout", "2m")
+	if docker.InfoTimeout != 45*time.Second || docker.StatusTimeout != 2*time.Minute {
+		t.Errorf("InfoTimeout = %v, StatusTimeout = %v", docker.InfoTimeout, docker.StatusTimeout)
+	}
+
+	RootCmd.SetArgs([]string{"list", "--connect-timeout", "-1s"})
+	t.Cleanup(func() { RootCmd.SetArgs(nil) })
+	if err := RootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "must not be negative") {
+		t.Errorf("err = %v, want negative timeout rejected", err)
+	}
+}
@@ -0,0 +1,250 @@
+// Package cmd — self_update.go implements `srv update`, which replaces the
+// running binary with the latest GitHub release for this platform after
+// checking the release's SHA256 checksum. Traefik and DNS images are updated
+// by `srv images update`.
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stubbedev/srv/internal/constants"
+	"github.com/stubbedev/srv/internal/ui"
+)
+
+var updateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Upgrade srv to the latest release",
+	Long: `Download the latest srv release for this platform from GitHub, verify its
+SHA256 checksum and replace the running binary with it. The previous binary is
+kept next to it as srv.bak until the next update.
+
+srv installed through a package manager (Homebrew, Nix) is a symlink; update
+it with that package manager instead. Traefik and DNS images are updated by
+'srv images update'.
+
+Examples:
+  srv update
+  srv version --check   # only report whether an update is available`,
+	Args: cobra.NoArgs,
+	RunE: runUpdate,
+}
+
+func init() {
+	updateCmd.GroupID = GroupSystem
+	RootCmd.AddCommand(updateCmd)
+}
+
+// releaseDownloadURL is the base URL of release assets. Tests swap it.
+var releaseDownloadURL = "https://github.com/stubbedev/srv/releases/download"
+
+// selfExecutable returns the path srv was started from. Tests swap it.
+var selfExecutable = invokedExecutable
+
+// selfUpdateTimeout bounds the whole download.
+const selfUpdateTimeout = 5 * time.Minute
+
+func runUpdate(cmd *cobra.Command, args []string) error {
+	if Version == constants.DefaultVersion {
+		return fmt.Errorf("this is a development build; install a release from %s to use 'srv update'", releasesPageURL)
+	}
+	exe, err := selfExecutable()
+	if err != nil {
+		return fmt.Errorf("locate the srv binary: %w", err)
+	}
+	if info, err := os.Lstat(exe); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		target, _ := filepath.EvalSymlinks(exe)
+		return fmt.Errorf("%s is a symlink to %s, so srv was probably installed by a package manager; update it with that instead", exe, target)
+	}
+
+	ui.Info("Checking for the latest release...")
+	latest, err := fetchLatestRelease()
+	if err != nil {
+		return fmt.Errorf("check for updates: %w", err)
+	}
+	newer, err := isNewerVersion(latest, Version)
+	if err != nil {
+		return fmt.Errorf("check for updates: %w", err)
+	}
+	if !newer {
+		ui.Success("Already up to date (srv %s)", Version)
+		return nil
+	}
+
+	asset := releaseAsset(latest)
+	ui.Info("Downloading %s...", asset)
+	binary, err := downloadRelease(latest, asset)
+	if err != nil {
+		return err
+	}
+	if err := replaceExecutable(exe, binary); err != nil {
+		return err
+	}
+	ui.Success("Updated srv to %s at %s", latest, exe)
+	ui.Dim("Previous binary kept at %s", exe+".bak")
+	return nil
+}
+
+// invokedExecutable returns the path the user ran srv as, without resolving
+// symlinks (os.Executable resolves them on Linux), so a package-managed
+// symlink can be detected.
+func invokedExecutable() (string, error) {
+	if p, err := exec.LookPath(os.Args[0]); err == nil {
+		return filepath.Abs(p)
+	}
+	return os.Executable()
+}
+
+// releaseAsset is the tarball name of a release for this platform, e.g.
+// srv-1.4.0-linux-amd64.tar.gz. Release tags carry a v; assets don't.
+func releaseAsset(tag string) string {
+	arch := runtime.GOARCH
+	if arch == "arm" {
+		arch += "v" + goarm()
+	}
+	return fmt.Sprintf("srv-%s-%s-%s.tar.gz", strings.TrimPrefix(tag, "v"), runtime.GOOS, arch)
+}
+
+// goarm returns the GOARM this binary was built with; releases ship v7.
+func goarm() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			if s.Key == "GOARM" && s.Value != "" {
+				return s.Value
+			}
+		}
+	}
+	return "7"
+}
+
+// downloadRelease fetches asset of release tag and its .sha256 file, checks
+// the checksum and returns the srv binary inside the tarball.
+func downloadRelease(tag, asset string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), selfUpdateTimeout)
+	defer cancel()
+	url := releaseDownloadURL + "/" + tag + "/" + asset
+	tarball, err := httpGet(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("download %s: %w", asset, err)
+	}
+	sum, err := httpGet(ctx, url+".sha256")
+	if err != nil {
+		return nil, fmt.Errorf("download checksum: %w", err)
+	}
+	fields := strings.Fields(string(sum))
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no checksum found in %s.sha256", asset)
+	}
+	actual := sha256.Sum256(tarball)
+	if got := hex.EncodeToString(actual[:]); !strings.EqualFold(got, fields[0]) {
+		return nil, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", asset, fields[0], got)
+	}
+	return extractBinary(tarball)
+}
+
+// httpGet returns the body of a successful GET.
+func httpGet(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// extractBinary returns the srv binary from a release tarball, which holds
+// it as srv-VERSION-OS-ARCH/srv.
+func extractBinary(tarball []byte) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(tarball))
+	if err != nil {
+		return nil, fmt.Errorf("read release tarball: %w", err)
+	}
+	defer func() { _ = gz.Close() }()
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("release tarball has no srv binary")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read release tarball: %w", err)
+		}
+		if hdr.Typeflag == tar.TypeReg && path.Base(hdr.Name) == constants.AppName {
+			return io.ReadAll(tr)
+		}
+	}
+}
+
+// replaceExecutable swaps binary in for exe, keeping the old one as
+// exe.bak. The new binary is written next to exe first so the final rename
+// stays on one filesystem and is atomic.
+func replaceExecutable(exe string, binary []byte) error {
+	dir := filepath.Dir(exe)
+	tmp, err := os.CreateTemp(dir, ".srv-update-*")
+	if err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			return fmt.Errorf("can't write to %s; re-run with sudo", dir)
+		}
+		return fmt.Errorf("stage new binary: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+	if _, err := tmp.Write(binary); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("stage new binary: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("stage new binary: %w", err)
+	}
+	if err := os.Chmod(tmpPath, 0o755); err != nil {
+		return fmt.Errorf("stage new binary: %w", err)
+	}
+
+	backup := exe + ".bak"
+	_ = os.Remove(backup)
+	if runtime.GOOS == "windows" {
+		// A running executable can't be replaced on Windows, but it can be
+		// renamed out of the way.
+		if err := os.Rename(exe, backup); err != nil {
+			return fmt.Errorf("back up %s: %w", exe, err)
+		}
+		if err := os.Rename(tmpPath, exe); err != nil {
+			_ = os.Rename(backup, exe)
+			return fmt.Errorf("install new binary: %w", err)
+		}
+		return nil
+	}
+	// Elsewhere the old binary stays in place until the rename replaces it.
+	if err := os.Link(exe, backup); err != nil {
+		return fmt.Errorf("back up %s: %w", exe, err)
+	}
+	if err := os.Rename(tmpPath, exe); err != nil {
+		return fmt.Errorf("install new binary: %w", err)
+	}
+	return nil
+}
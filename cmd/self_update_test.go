@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// releaseTarball builds a release tarball holding binary as STAGE/srv.
+func releaseTarball(t *testing.T, stage string, binary []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	if err := tw.WriteHeader(&tar.Header{Name: stage + "/srv", Mode: 0o755, Size: int64(len(binary)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write(binary); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// fakeReleases serves v1.5.0 as the latest release; checksum overrides the
+// .sha256 content when set.
+func fakeReleases(t *testing.T, checksum string) {
+	t.Helper()
+	asset := releaseAsset("v1.5.0")
+	tarball := releaseTarball(t, strings.TrimSuffix(asset, ".tar.gz"), []byte("new binary"))
+	if checksum == "" {
+		sum := sha256.Sum256(tarball)
+		checksum = hex.EncodeToString(sum[:])
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/latest", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"tag_name":"v1.5.0"}`)
+	})
+	mux.HandleFunc("/download/v1.5.0/"+asset, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(tarball)
+	})
+	mux.HandleFunc("/download/v1.5.0/"+asset+".sha256", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s  %s\n", checksum, asset)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	prevLatest, prevDownload, prevExe, prevVersion := latestReleaseURL, releaseDownloadURL, selfExecutable, Version
+	t.Cleanup(func() {
+		latestReleaseURL, releaseDownloadURL, selfExecutable, Version = prevLatest, prevDownload, prevExe, prevVersion
+	})
+	latestReleaseURL = srv.URL + "/latest"
+	releaseDownloadURL = srv.URL + "/download"
+	Version = "1.4.0"
+}
+
+func TestRunUpdate(t *testing.T) {
+	fakeReleases(t, "")
+	exe := filepath.Join(t.TempDir(), "srv")
+	if err := os.WriteFile(exe, []byte("old binary"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	selfExecutable = func() (string, error) { return exe, nil }
+
+	if err := runUpdate(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(exe); string(got) != "new binary" {
+		t.Errorf("binary = %q, want the release's", got)
+	}
+	if got, _ := os.ReadFile(exe + ".bak"); string(got) != "old binary" {
+		t.Errorf("backup = %q, want the previous binary", got)
+	}
+
+	// Up to date: nothing is downloaded.
+	Version = "v1.5.0"
+	if err := os.WriteFile(exe, []byte("current"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := runUpdate(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(exe); string(got) != "current" {
+		t.Errorf("up-to-date binary replaced: %q", got)
+	}
+}
+
+func TestRunUpdateChecksumMismatch(t *testing.T) {
+	fakeReleases(t, strings.Repeat("0", 64))
+	exe := filepath.Join(t.TempDir(), "srv")
+	if err := os.WriteFile(exe, []byte("old binary"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	selfExecutable = func() (string, error) { return exe, nil }
+
+	if err := runUpdate(nil, nil); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("err = %v, want a checksum mismatch", err)
+	}
+	if got, _ := os.ReadFile(exe); string(got) != "old binary" {
+		t.Errorf("binary replaced despite the bad checksum: %q", got)
+	}
+}
+
+func TestRunUpdateRefusesSymlink(t *testing.T) {
+	fakeReleases(t, "")
+	dir := t.TempDir()
+	target := filepath.Join(dir, "srv-real")
+	if err := os.WriteFile(target, []byte("old binary"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "srv")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+	selfExecutable = func() (string, error) { return link, nil }
+
+	if err := runUpdate(nil, nil); err == nil || !strings.Contains(err.Error(), "package manager") {
+		t.Errorf("err = %v, want the package manager hint", err)
+	}
+}
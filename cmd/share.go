@@ -0,0 +1,97 @@
+// Package cmd — share.go implements `srv share`, which exposes a site on a
+// public URL through a cloudflared or ngrok tunnel (internal/tunnel).
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stubbedev/srv/internal/constants"
+	"github.com/stubbedev/srv/internal/site"
+	"github.com/stubbedev/srv/internal/tunnel"
+	"github.com/stubbedev/srv/internal/ui"
+)
+
+var shareFlags struct {
+	tool string
+}
+
+var shareCmd = &cobra.Command{
+	Use:   "share SITE",
+	Short: "Share a site on a public URL through a tunnel",
+	Long: `Expose a site on a public URL through a cloudflared quick tunnel or ngrok.
+The tunnel runs until interrupted (Ctrl+C); the public URL is printed by
+the tunnel tool.
+
+--tool picks the tool; by default the first one installed is used
+(cloudflared, then ngrok).
+
+Local sites use mkcert certificates, which are only trusted on this
+machine, so the tunnel skips verifying them.
+
+Examples:
+  srv share mysite
+  srv share mysite --tool ngrok`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			_ = cmd.Help()
+			return ui.UsageError("srv share SITE", "a site name is required")
+		}
+		if len(args) > 1 {
+			return ui.UsageError("srv share SITE", "too many arguments — expected a single site name, got %d", len(args))
+		}
+		if shareFlags.tool != "" && !slices.Contains(tunnel.Tools, shareFlags.tool) {
+			return ui.UsageError("srv share SITE", "invalid --tool %q (expected %s)", shareFlags.tool, strings.Join(tunnel.Tools, " or "))
+		}
+		return nil
+	},
+	RunE: runShare,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return GetSiteNames(), cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+func init() {
+	shareCmd.Flags().StringVar(&shareFlags.tool, "tool", "", "Tunnel tool: cloudflared or ngrok (default: the first installed)")
+	_ = shareCmd.RegisterFlagCompletionFunc("tool", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return tunnel.Tools, cobra.ShellCompDirectiveNoFileComp
+	})
+	shareCmd.GroupID = GroupSites
+	RootCmd.AddCommand(shareCmd)
+}
+
+func runShare(cmd *cobra.Command, args []string) error {
+	s, err := site.GetByName(args[0])
+	if err != nil {
+		return err
+	}
+	if s.IsBroken {
+		return fmt.Errorf("site %s is broken: its project directory is missing", s.Name)
+	}
+	if s.Domain() == "" {
+		return fmt.Errorf("site %s has no domain", s.Name)
+	}
+
+	tool := shareFlags.tool
+	if tool == "" {
+		if tool, err = tunnel.Detect(); err != nil {
+			return err
+		}
+	}
+
+	if s.IsLocal && s.ServesHTTPS() {
+		ui.Warn("%s uses a mkcert certificate, which is only trusted on this machine; the tunnel won't verify it", s.Name)
+	}
+	if s.Status != constants.StatusRunning {
+		ui.Warn("%s is not running; the tunnel will return errors until you run 'srv start %s'", s.Name, s.Name)
+	}
+
+	ui.Info("Sharing %s via %s (Ctrl+C to stop)", s.URL(), tool)
+	return tunnel.Share(tool, s.URL(), s.IsLocal)
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stubbedev/srv/internal/shell"
+	"github.com/stubbedev/srv/internal/shell/shelltest"
+	"github.com/stubbedev/srv/internal/site"
+)
+
+func TestRunShare(t *testing.T) {
+	root := setupSrvRoot(t)
+	writeTestSite(t, "blog", site.SiteMetadata{
+		Type:        site.SiteTypeStatic,
+		Domains:     []string{"blog.test"},
+		ProjectPath: root,
+		IsLocal:     true,
+	})
+	fake := &shelltest.Fake{Default: shelltest.Response{Exists: true}}
+	t.Cleanup(shell.SwapDefault(fake))
+	t.Cleanup(func() { shareFlags.tool = "" })
+
+	shareFlags.tool = "cloudflared"
+	if err := runShare(nil, []string{"blog"}); err != nil {
+		t.Fatal(err)
+	}
+	calls := fake.Snapshot()
+	last := calls[len(calls)-1]
+	want := []string{"tunnel", "--url", "https://blog.test", "--http-host-header", "blog.test", "--no-tls-verify"}
+	if last.Name != "cloudflared" || !slices.Equal(last.Args, want) {
+		t.Errorf("ran %s %v, want cloudflared %v", last.Name, last.Args, want)
+	}
+}
+
+func TestRunShareErrors(t *testing.T) {
+	root := setupSrvRoot(t)
+	writeTestSite(t, "gone", site.SiteMetadata{
+		Type:        site.SiteTypeStatic,
+		Domains:     []string{"gone.test"},
+		ProjectPath: filepath.Join(root, "missing"),
+	})
+	t.Cleanup(shell.SwapDefault(shelltest.New(nil)))
+
+	if err := runShare(nil, []string{"ghost"}); err == nil {
+		t.Error("expected an error for an unknown site")
+	}
+	if err := runShare(nil, []string{"gone"}); err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("err = %v, want a broken-site error", err)
+	}
+}
+
+func TestShareToolFlag(t *testing.T) {
+	t.Cleanup(func() { shareFlags.tool = "" })
+	shareFlags.tool = "localtunnel"
+	if err := shareCmd.Args(shareCmd, []string{"blog"}); err == nil {
+		t.Error("expected an error for an unknown --tool")
+	}
+}
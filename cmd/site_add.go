@@ -5,13 +5,18 @@ package cmd
 
 import (
 	"fmt"
+	"net/http"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/stubbedev/srv/internal/config"
 	"github.com/stubbedev/srv/internal/constants"
+	"github.com/stubbedev/srv/internal/preset"
 	"github.com/stubbedev/srv/internal/site"
+	"github.com/stubbedev/srv/internal/traefik"
 	"github.com/stubbedev/srv/internal/ui"
+	"github.com/stubbedev/srv/internal/validate"
 )
 
 // =============================================================================
@@ -25,19 +30,64 @@ var addFlags struct {
 	name           string
 	service        string
 	local          bool
+	staging        bool
 	wildcard       bool
 	internalHTTP   bool
 	force          bool
 	skipValidation bool
 	typeOverride   string // Force site type: dockerfile/static/compose
 	// Static site options
-	spa   bool
-	cache bool
-	cors  bool
+	spa        bool
+	cache      bool
+	cors       bool
+	brotli     bool
+	dirListing bool
+	nginxExtra string
+	errorPages string
 	// Compose profile selection
 	profile string
+	// Compose override file layered over the project's compose file
+	override string
 	// Extra mounts
 	volumes []string
+	// Custom Traefik middlewares
+	middlewares []string
+	// WebSocket upgrade headers + long idle timeout (compose sites)
+	websocket bool
+	// Traefik health check against the container (compose sites)
+	healthCheck    string
+	healthInterval string
+	// Client CIDRs allowed to reach the site
+	allowIPs []string
+	// Plain HTTP: instead of HTTPS, or alongside it without the redirect
+	httpOnly   bool
+	noRedirect bool
+	// Response header edits
+	addHeaders    []string
+	removeHeaders []string
+	// Shell commands run from the project dir around `docker compose up`
+	preStart  []string
+	postStart []string
+	// Traefik compression, rate limit and middleware preset
+	compress  bool
+	rateLimit string
+	preset    string
+	// Path-based routing: only route requests under this path, optionally
+	// stripping it (mutually exclusive, like srv proxy add)
+	pathPrefix  string
+	stripPrefix string
+	// Retries of requests that fail to reach the container
+	retry         int
+	retryInterval string
+	// Request and response body size limits, e.g. 10MB
+	maxRequestBody  string
+	maxResponseBody string
+	// Let's Encrypt DNS-01 challenge: provider and its credentials
+	acmeDNSChallenge bool
+	acmeDNSProvider  string
+	acmeDNSEnv       []string
+	// Register the site without bringing its containers up
+	noStart bool
 }
 
 var addCmd = &cobra.Command{
@@ -56,11 +106,37 @@ SSL certificates:
   - Use --local to generate a local certificate with mkcert
   - Without --local, Let's Encrypt will be used for production SSL
 
+The site name defaults to the domain with dots turned into hyphens
+(myapp.test -> myapp-test). An existing site of that name needs --force to
+be replaced; --name auto instead picks the first free name by appending a
+number (myapp-test-2, myapp-test-3, ...).
+
+The site is started right away unless --no-start is given; it is then only
+registered (config, certificate and DNS) until 'srv start NAME'. With
+--no-start, a compose project may also omit --domain: it is registered as a
+local site at NAME.test, like 'srv park --auto-add' does.
+
+--acme-dns-challenge makes Let's Encrypt verify domains over DNS-01 instead
+of HTTP-01, for hosts whose port 80 is firewalled. --acme-dns-provider names
+Traefik's DNS provider (cloudflare, route53, ...) and --acme-dns-env passes
+the credentials it reads. They are stored in ~/.config/srv/env.traefik and
+apply to every production site; Traefik is recreated to load them.
+
 Examples:
   srv add /path/to/site --domain example.com          # Production with Let's Encrypt
   srv add /path/to/site --domain myapp.test --local   # Local dev with mkcert
-  srv add . --domain example.com --start              # Add and start immediately
-  srv add /path/to/static --domain site.test --local  # Static files with nginx`,
+  srv add . --domain example.com --no-start           # Register now, start later
+  srv add ./api --no-start                            # Compose project as local api.test
+  srv add /path/to/static --domain site.test --local  # Static files with nginx
+  srv add . --domain api.test --local --no-redirect   # Serve both HTTP and HTTPS
+  srv add . --domain app.test --local --override compose.dev.yml  # Layer an override file
+  srv add . --domain app.test --local --middleware-preset api     # Apply a middleware preset
+  srv add . --domain api.test --local --healthcheck /health       # Stop routing to a failing container
+  srv add . --domain api.test --local --max-request-body 10MB     # Reject larger uploads with 413
+  srv add ./api --domain app.test --local --strip-prefix /api     # Mount at app.test/api, prefix removed
+  srv add . --domain api.test --local --retry 3                   # Ride out a container that is still starting
+  srv add . --domain example.com --acme-dns-challenge --acme-dns-provider cloudflare --acme-dns-env CF_DNS_API_TOKEN=xxx
+  srv add . --domain app.test --local --name auto                 # Pick a free name if app-test is taken`,
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) == 0 {
 			_ = cmd.Help()
@@ -72,7 +148,7 @@ Examples:
 		return nil
 	},
 	PreRunE: func(cmd *cobra.Command, args []string) error {
-		if addFlags.domain == "" {
+		if addFlags.domain == "" && !canDefaultAddDomain(args[0]) {
 			_ = cmd.Help()
 			return ui.UsageError("srv add PATH --domain DOMAIN", "--domain is required (e.g. --domain myapp.test or --domain example.com)")
 		}
@@ -88,24 +164,67 @@ func init() {
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	})
 	addCmd.Flags().IntVarP(&addFlags.port, "port", "p", constants.DefaultContainerPort, "Container port")
-	addCmd.Flags().StringVarP(&addFlags.name, "name", "n", "", "Site name (default: directory name)")
+	addCmd.Flags().StringVarP(&addFlags.name, "name", "n", "", "Site name (default: derived from the domain; 'auto' appends a number when that is taken)")
 	addCmd.Flags().StringVar(&addFlags.service, "service", "", "Container name to route to")
 	addCmd.Flags().BoolVarP(&addFlags.local, "local", "l", false, "Use local SSL via mkcert (otherwise Let's Encrypt)")
+	addCmd.Flags().BoolVar(&addFlags.staging, "staging", false, "Use the Let's Encrypt staging CA (untrusted certs, no rate limits); production sites only")
 	addCmd.Flags().BoolVar(&addFlags.wildcard, "wildcard", false, "Also match one-level subdomains (e.g. *.foo.test); local sites only")
 	addCmd.Flags().BoolVar(&addFlags.internalHTTP, "internal-http", false, "Expose the site on the internal plain-HTTP entrypoint (port 88) in addition to HTTPS")
+	addCmd.Flags().BoolVar(&addFlags.httpOnly, "http-only", false, "Serve the site over plain HTTP on port 80 without TLS (for apps that terminate TLS themselves, or testing)")
+	addCmd.Flags().BoolVar(&addFlags.noRedirect, "no-redirect", false, "Serve the site over both HTTP and HTTPS instead of redirecting HTTP to HTTPS")
 	addCmd.Flags().BoolVarP(&addFlags.force, "force", "f", false, "Overwrite existing configuration")
+	addCmd.Flags().BoolVar(&addFlags.noStart, "no-start", false, "Register the site without starting its containers (start later with 'srv start NAME')")
 	addCmd.Flags().BoolVar(&addFlags.skipValidation, "skip-validation", false, "Skip compose file validation")
 	// Static site options
 	addCmd.Flags().BoolVar(&addFlags.spa, "spa", true, "Enable SPA mode (fallback to index.html)")
 	addCmd.Flags().BoolVar(&addFlags.cache, "cache", true, "Enable caching headers for static assets")
 	addCmd.Flags().BoolVar(&addFlags.cors, "cors", false, "Enable CORS headers (allow all origins)")
+	addCmd.Flags().BoolVar(&addFlags.brotli, "brotli", false, "Enable brotli compression (uses an nginx image with the brotli module)")
+	addCmd.Flags().BoolVar(&addFlags.dirListing, "directory-listing", false, "List directory contents when no index file exists (disables --spa)")
+	addCmd.Flags().StringVar(&addFlags.nginxExtra, "nginx-extra", "", "nginx snippet file embedded in the static site's server block")
+	addCmd.Flags().StringVar(&addFlags.errorPages, "error-pages", "", "Directory with custom 404.html (and optional 50x.html) for a static site")
 	// Compose profile (required when the selected service has multiple)
 	addCmd.Flags().StringVar(&addFlags.profile, "profile", "", "Docker Compose profile (required when the selected service declares multiple)")
+	addCmd.Flags().StringVar(&addFlags.override, "override", "", "Compose override file layered over the project's compose file (compose sites)")
 	// Extra bind-mounts
 	addCmd.Flags().StringSliceVar(&addFlags.volumes, "volume", nil, "Extra bind-mount in HOST:CONTAINER[:ro] form; repeatable")
 	_ = addCmd.RegisterFlagCompletionFunc("volume", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return nil, cobra.ShellCompDirectiveDefault
 	})
+	// Custom Traefik middlewares (defined in the dynamic config)
+	addCmd.Flags().StringSliceVar(&addFlags.middlewares, "middleware", nil, "Traefik middleware to attach to the site's router, e.g. compress,retry (max 10)")
+	_ = addCmd.RegisterFlagCompletionFunc("middleware", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	})
+	addCmd.Flags().StringSliceVar(&addFlags.allowIPs, "allow-ip", nil, "Only allow clients from these CIDRs, e.g. 192.168.1.0/24,10.0.0.0/8 (others get 403)")
+	_ = addCmd.RegisterFlagCompletionFunc("allow-ip", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	})
+	// StringArray: header values routinely contain commas
+	addCmd.Flags().StringArrayVar(&addFlags.addHeaders, "add-header", nil, `Response header to set, as "Name: Value" (repeatable)`)
+	addCmd.Flags().StringSliceVar(&addFlags.removeHeaders, "remove-header", nil, "Response header to strip from the site's responses, e.g. X-Powered-By (repeatable)")
+	addCmd.Flags().BoolVar(&addFlags.compress, "compress", false, "Compress responses at Traefik (gzip or brotli, per the client); compose and dockerfile sites, nginx already compresses static ones")
+	addCmd.Flags().StringVar(&addFlags.maxRequestBody, "max-request-body", "", "Largest request body to accept, e.g. 10MB (KB, MB or GB; 1KB to 10GB); larger requests get 413")
+	addCmd.Flags().StringVar(&addFlags.maxResponseBody, "max-response-body", "", "Largest response body to pass on, e.g. 50MB (KB, MB or GB; 1KB to 10GB)")
+	addCmd.Flags().StringVar(&addFlags.rateLimit, "rate-limit", "", "Per-client rate limit as AVERAGE-UNIT, e.g. 100-S (100 requests per second); units S, M, H")
+	addCmd.Flags().StringVar(&addFlags.pathPrefix, "path-prefix", "", "Only route requests under this path (e.g. /api), so several sites can share a domain")
+	addCmd.Flags().StringVar(&addFlags.stripPrefix, "strip-prefix", "", "Route requests under this path and strip it before forwarding (e.g. /api)")
+	addCmd.MarkFlagsMutuallyExclusive("path-prefix", "strip-prefix")
+	addCmd.Flags().IntVar(&addFlags.retry, "retry", 0, "Retry a request that fails to reach the container up to this many times (1-10), e.g. while it starts")
+	addCmd.Flags().StringVar(&addFlags.retryInterval, "retry-interval", "", "Initial back-off between --retry attempts, e.g. 250ms (default 100ms)")
+	addCmd.Flags().BoolVar(&addFlags.acmeDNSChallenge, "acme-dns-challenge", false, "Verify Let's Encrypt domains over DNS-01 instead of HTTP-01 (production SSL only; needs --acme-dns-provider)")
+	addCmd.Flags().StringVar(&addFlags.acmeDNSProvider, "acme-dns-provider", "", "Traefik DNS provider for --acme-dns-challenge, e.g. cloudflare or route53")
+	addCmd.Flags().StringArrayVar(&addFlags.acmeDNSEnv, "acme-dns-env", nil, "DNS provider credential as KEY=VALUE, e.g. CF_DNS_API_TOKEN=... (repeatable; saved to env.traefik)")
+	addCmd.Flags().StringVar(&addFlags.preset, "middleware-preset", "", "Apply the settings of a middleware preset (see 'srv preset'); flags given alongside take precedence")
+	_ = addCmd.RegisterFlagCompletionFunc("middleware-preset", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return preset.Names(), cobra.ShellCompDirectiveNoFileComp
+	})
+	addCmd.Flags().BoolVar(&addFlags.websocket, "websocket", false, "Inject WebSocket upgrade headers and keep idle connections open for an hour; compose sites only")
+	addCmd.Flags().StringVar(&addFlags.healthCheck, "healthcheck", "", "Path Traefik probes on the container, e.g. /health; a failing container stops getting traffic. Compose sites only")
+	addCmd.Flags().StringVar(&addFlags.healthInterval, "healthcheck-interval", "", "How often to probe --healthcheck, e.g. 30s (default 10s)")
+	// Start hooks (StringArray: commands routinely contain commas)
+	addCmd.Flags().StringArrayVar(&addFlags.preStart, "pre-start", nil, "Shell command to run from the project dir before every start; a failure aborts the start (repeatable)")
+	addCmd.Flags().StringArrayVar(&addFlags.postStart, "post-start", nil, "Shell command to run from the project dir after every start (repeatable)")
 	// Type override
 	addCmd.Flags().StringVar(&addFlags.typeOverride, "type", "", "Force site type: dockerfile, static, compose")
 	_ = addCmd.RegisterFlagCompletionFunc("type", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -127,25 +246,81 @@ func runAdd(cmd *cobra.Command, args []string) error {
 		}
 		mounts = append(mounts, m)
 	}
+	addHeaders, err := parseHeaderSpecs(addFlags.addHeaders)
+	if err != nil {
+		return err
+	}
+	removeHeaders := canonicalHeaderNames(addFlags.removeHeaders)
+
+	// --spa defaults to on, so only an explicit --spa conflicts with
+	// --directory-listing; otherwise the listing just switches SPA off.
+	spa := addFlags.spa
+	if addFlags.dirListing {
+		if cmd.Flags().Changed("spa") && spa {
+			return ui.UsageError(cmd.UseLine(), "--spa and --directory-listing are mutually exclusive")
+		}
+		spa = false
+	}
+
+	pathPrefix := addFlags.pathPrefix
+	if addFlags.stripPrefix != "" {
+		pathPrefix = addFlags.stripPrefix
+	}
+
+	name, domain, local := addFlags.name, addFlags.domain, addFlags.local
+	if domain == "" && canDefaultAddDomain(args[0]) {
+		name, domain = defaultAddDomain(args[0])
+		local = true
+	}
+	dnsCredentials, err := parseACMEDNSChallenge(cmd, local)
+	if err != nil {
+		return err
+	}
 
 	res, err := site.Add(site.AddOptions{
-		Path:         args[0],
-		TypeOverride: addFlags.typeOverride,
-		Name:         addFlags.name,
-		Domain:       addFlags.domain,
-		Aliases:      addFlags.aliases,
-		Port:         addFlags.port,
-		Local:        addFlags.local,
-		Wildcard:     addFlags.wildcard,
-		InternalHTTP: addFlags.internalHTTP,
-		Service:      addFlags.service,
-		Profile:      addFlags.profile,
-		SPA:          addFlags.spa,
-		Cache:        addFlags.cache,
-		CORS:         addFlags.cors,
-		Volumes:      mounts,
-		Force:        addFlags.force,
-		Start:        true,
+		Path:             args[0],
+		TypeOverride:     addFlags.typeOverride,
+		Name:             name,
+		Domain:           domain,
+		Aliases:          addFlags.aliases,
+		Port:             addFlags.port,
+		Local:            local,
+		Staging:          addFlags.staging,
+		Wildcard:         addFlags.wildcard,
+		InternalHTTP:     addFlags.internalHTTP,
+		Service:          addFlags.service,
+		Profile:          addFlags.profile,
+		Override:         addFlags.override,
+		SPA:              spa,
+		Cache:            addFlags.cache,
+		CORS:             addFlags.cors,
+		Brotli:           addFlags.brotli,
+		DirectoryListing: addFlags.dirListing,
+		NginxExtra:       addFlags.nginxExtra,
+		ErrorPages:       addFlags.errorPages,
+		Volumes:          mounts,
+		Middlewares:      addFlags.middlewares,
+		WebSocket:        addFlags.websocket,
+		HealthCheck:      addFlags.healthCheck,
+		HealthInterval:   addFlags.healthInterval,
+		AllowIPs:         addFlags.allowIPs,
+		AddHeaders:       addHeaders,
+		RemoveHeaders:    removeHeaders,
+		Compress:         addFlags.compress,
+		RateLimit:        addFlags.rateLimit,
+		PathPrefix:       pathPrefix,
+		StripPrefix:      addFlags.stripPrefix != "",
+		RetryAttempts:    addFlags.retry,
+		RetryInterval:    addFlags.retryInterval,
+		MaxRequestBody:   addFlags.maxRequestBody,
+		MaxResponseBody:  addFlags.maxResponseBody,
+		Preset:           addFlags.preset,
+		HTTPOnly:         addFlags.httpOnly,
+		NoRedirect:       addFlags.noRedirect,
+		PreStart:         addFlags.preStart,
+		PostStart:        addFlags.postStart,
+		Force:            addFlags.force,
+		Start:            !addFlags.noStart,
 	})
 	if err != nil {
 		return err
@@ -153,14 +328,139 @@ func runAdd(cmd *cobra.Command, args []string) error {
 	for _, w := range res.Warnings {
 		ui.Warn("%s", w)
 	}
+	if addFlags.acmeDNSChallenge {
+		if err := applyACMEDNSChallenge(addFlags.acmeDNSProvider, dnsCredentials); err != nil {
+			return fmt.Errorf("site '%s' added, but %w", res.Name, err)
+		}
+	}
 
 	ui.Success("Site '%s' added successfully!", res.Name)
-	ui.Dim("Domain: %s (%s, %s)", res.Domain, res.Type, ui.Highlight(TypeLabel(res.IsLocal)))
+	ui.Dim("Domain: %s (%s, %s)", res.Domain, res.Type, ui.Highlight(SSLLabel(res.IsLocal, addFlags.staging)))
 	if cfg, err := config.Load(); err == nil {
 		ui.Dim("Config: %s/sites/%s/ (no project files modified)", cfg.Root, res.Name)
 	}
-	if res.IsLocal {
+	switch {
+	case addFlags.noStart:
+		ui.Info("Site registered. Run 'srv start %s' to start.", res.Name)
+	case res.IsLocal:
 		ui.Success("Site is running at https://%s", res.Domain)
 	}
 	return nil
 }
+
+// parseACMEDNSChallenge validates the --acme-dns-* flags and returns the
+// provider credentials from --acme-dns-env. Nothing is written until the site
+// has been added; see applyACMEDNSChallenge.
+func parseACMEDNSChallenge(cmd *cobra.Command, local bool) (map[string]string, error) {
+	if !addFlags.acmeDNSChallenge {
+		if addFlags.acmeDNSProvider != "" || len(addFlags.acmeDNSEnv) > 0 {
+			return nil, ui.UsageError(cmd.UseLine(), "--acme-dns-provider and --acme-dns-env only apply with --acme-dns-challenge")
+		}
+		return nil, nil
+	}
+	if local {
+		return nil, ui.UsageError(cmd.UseLine(), "--acme-dns-challenge is for Let's Encrypt certificates; local sites use mkcert")
+	}
+	if addFlags.acmeDNSProvider == "" {
+		return nil, ui.UsageError(cmd.UseLine(), "--acme-dns-challenge needs --acme-dns-provider (e.g. cloudflare or route53)")
+	}
+	credentials := make(map[string]string, len(addFlags.acmeDNSEnv))
+	for _, kv := range addFlags.acmeDNSEnv {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			return nil, ui.UsageError(cmd.UseLine(), "invalid --acme-dns-env %q (expected KEY=VALUE)", kv)
+		}
+		credentials[key] = value
+	}
+	if err := traefik.ValidateACMEDNSChallenge(addFlags.acmeDNSProvider, credentials); err != nil {
+		return nil, err
+	}
+	return credentials, nil
+}
+
+// applyACMEDNSChallenge switches Let's Encrypt to the DNS-01 challenge once
+// the site has been added. The resolver is shared by every production site;
+// a running Traefik is recreated to load the provider's credentials, and
+// then requests any missing certificate over DNS.
+func applyACMEDNSChallenge(provider string, credentials map[string]string) error {
+	changed, err := traefik.SetACMEDNSChallenge(provider, credentials)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return nil
+	}
+	ui.Info("Let's Encrypt now uses the DNS-01 challenge via %s", provider)
+	if traefik.IsRunning() {
+		ui.Info("Recreating Traefik to load the DNS provider credentials...")
+		if err := traefik.RecreateTraefik(); err != nil {
+			return fmt.Errorf("failed to recreate Traefik: %w", err)
+		}
+	}
+	return nil
+}
+
+// canDefaultAddDomain reports whether `srv add` may run without --domain:
+// only with --no-start, for a directory holding a compose file.
+func canDefaultAddDomain(path string) bool {
+	if !addFlags.noStart {
+		return false
+	}
+	dir, err := site.ResolvePath(path)
+	if err != nil {
+		return false
+	}
+	_, err = site.FindComposeFile(dir)
+	return err == nil
+}
+
+// defaultAddDomain returns the site name and local domain a project added
+// without --domain gets: --name (or the directory name) at NAME.test. With
+// --name auto the domain comes from the directory name and the site name is
+// left for Add to pick.
+func defaultAddDomain(path string) (name, domain string) {
+	name = addFlags.name
+	if name == "" || name == site.AutoName {
+		dir, err := site.ResolvePath(path)
+		if err != nil {
+			dir = path
+		}
+		name = site.SanitizeName(dir)
+	}
+	domain = name + "." + traefik.LocalDomains[0]
+	if addFlags.name == site.AutoName {
+		name = site.AutoName
+	}
+	return name, domain
+}
+
+// parseHeaderSpecs parses --add-header values of the form "Name: Value" into
+// a map keyed by the canonical header name. A repeated name keeps the last
+// value.
+func parseHeaderSpecs(specs []string) (map[string]string, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+	headers := make(map[string]string, len(specs))
+	for _, spec := range specs {
+		name, value, ok := strings.Cut(spec, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf(`invalid --add-header %q: expected "Name: Value"`, spec)
+		}
+		if err := validate.HeaderName(name); err != nil {
+			return nil, fmt.Errorf("invalid --add-header %q: %w", spec, err)
+		}
+		headers[http.CanonicalHeaderKey(name)] = strings.TrimSpace(value)
+	}
+	return headers, nil
+}
+
+// canonicalHeaderNames canonicalises --remove-header values.
+func canonicalHeaderNames(names []string) []string {
+	out := make([]string, 0, len(names))
+	for _, h := range names {
+		out = append(out, http.CanonicalHeaderKey(strings.TrimSpace(h)))
+	}
+	return out
+}
@@ -36,9 +36,37 @@ func resetAddFlags() {
 	addFlags.cors = false
 	addFlags.typeOverride = ""
 	addFlags.aliases = nil
+	addFlags.allowIPs = nil
+	addFlags.addHeaders = nil
+	addFlags.removeHeaders = nil
+	addFlags.httpOnly = false
+	addFlags.noRedirect = false
+	addFlags.override = ""
+	addFlags.compress = false
+	addFlags.rateLimit = ""
+	addFlags.preset = ""
+	addFlags.noStart = false
 }
 
 // writeFile2 writes content to path with default perms (test convenience).
 func writeFile2(path, content string) error {
 	return os.WriteFile(path, []byte(content), 0o644)
 }
+
+func TestParseHeaderSpecs(t *testing.T) {
+	got, err := parseHeaderSpecs([]string{"x-environment: staging", "X-Robots-Tag:noindex, nofollow"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["X-Environment"] != "staging" || got["X-Robots-Tag"] != "noindex, nofollow" {
+		t.Errorf("got %v", got)
+	}
+	for _, spec := range []string{"no-colon", ": value", "bad name: v"} {
+		if _, err := parseHeaderSpecs([]string{spec}); err == nil {
+			t.Errorf("parseHeaderSpecs(%q) = nil error", spec)
+		}
+	}
+	if got, err := parseHeaderSpecs(nil); err != nil || got != nil {
+		t.Errorf("empty input: %v, %v", got, err)
+	}
+}
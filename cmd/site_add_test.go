@@ -4,10 +4,12 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stubbedev/srv/internal/docker"
 	"github.com/stubbedev/srv/internal/mkcert"
+	"github.com/stubbedev/srv/internal/site"
 )
 
 func TestRunAddDockerDown(t *testing.T) {
@@ -48,3 +50,186 @@ func TestRunAddStaticHappy(t *testing.T) {
 		t.Errorf("err: %v", err)
 	}
 }
+
+func TestRunAddHTTPOnly(t *testing.T) {
+	root := setupSrvRoot(t)
+	projectDir := filepath.Join(root, "blog")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := mustLoadConfig(t)
+	t.Cleanup(docker.SwapNewClientWithNetwork(cfg.NetworkName))
+	t.Cleanup(docker.SwapComposeExec(func(string, bool, ...string) error { return nil }))
+	t.Cleanup(mkcert.SwapRunner(stubMkcertRunner{}))
+
+	resetAddFlags()
+	addFlags.domain = "blog.local"
+	addFlags.name = "blog"
+	addFlags.local = true
+	addFlags.typeOverride = "static"
+	addFlags.httpOnly = true
+	defer resetAddFlags()
+
+	if err := runAdd(nil, []string{projectDir}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	meta, err := site.ReadSiteMetadata("blog")
+	if err != nil || meta == nil {
+		t.Fatalf("metadata: %v", err)
+	}
+	if strings.Join(meta.EntryPoints, ",") != "web" {
+		t.Errorf("EntryPoints = %v, want [web]", meta.EntryPoints)
+	}
+}
+
+func TestRunAddHTTPOnlyWithNoRedirect(t *testing.T) {
+	root := setupSrvRoot(t)
+	projectDir := filepath.Join(root, "blog")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := mustLoadConfig(t)
+	t.Cleanup(docker.SwapNewClientWithNetwork(cfg.NetworkName))
+
+	resetAddFlags()
+	addFlags.domain = "blog.local"
+	addFlags.local = true
+	addFlags.typeOverride = "static"
+	addFlags.httpOnly = true
+	addFlags.noRedirect = true
+	defer resetAddFlags()
+
+	if err := runAdd(nil, []string{projectDir}); err == nil || !strings.Contains(err.Error(), "mutually exclusive") {
+		t.Errorf("err = %v, want mutually exclusive", err)
+	}
+}
+
+func TestRunAddNoStart(t *testing.T) {
+	root := setupSrvRoot(t)
+	projectDir := filepath.Join(root, "blog")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := mustLoadConfig(t)
+	t.Cleanup(docker.SwapNewClientWithNetwork(cfg.NetworkName))
+	var composeRuns []string
+	t.Cleanup(docker.SwapComposeExec(func(_ string, _ bool, args ...string) error {
+		composeRuns = append(composeRuns, strings.Join(args, " "))
+		return nil
+	}))
+	t.Cleanup(mkcert.SwapRunner(stubMkcertRunner{}))
+
+	resetAddFlags()
+	addFlags.domain = "blog.local"
+	addFlags.name = "blog"
+	addFlags.local = true
+	addFlags.typeOverride = "static"
+	addFlags.noStart = true
+	defer resetAddFlags()
+
+	if err := runAdd(nil, []string{projectDir}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	for _, run := range composeRuns {
+		if strings.Contains(run, "up") {
+			t.Errorf("--no-start ran compose %q", run)
+		}
+	}
+	meta, err := site.ReadSiteMetadata("blog")
+	if err != nil || meta == nil {
+		t.Fatalf("metadata: %v", err)
+	}
+	if len(meta.Domains) == 0 || meta.Domains[0] != "blog.local" || meta.ProjectPath != projectDir || !meta.IsLocal {
+		t.Errorf("metadata = %+v", meta)
+	}
+}
+
+// TestRunAddNoStartDefaultDomain: --no-start on a compose project may omit
+// --domain; the site is registered locally at NAME.test.
+func TestRunAddNoStartDefaultDomain(t *testing.T) {
+	root := setupSrvRoot(t)
+	projectDir := filepath.Join(root, "Api")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(projectDir, "compose.yml"), []byte("services:\n  web:\n    image: nginx\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := mustLoadConfig(t)
+	t.Cleanup(docker.SwapNewClientWithNetwork(cfg.NetworkName))
+	t.Cleanup(docker.SwapComposeExec(func(string, bool, ...string) error { return nil }))
+	t.Cleanup(mkcert.SwapRunner(stubMkcertRunner{}))
+	resetAddFlags()
+	defer resetAddFlags()
+
+	if err := addCmd.PreRunE(addCmd, []string{projectDir}); err == nil {
+		t.Error("PreRunE: expected --domain to be required without --no-start")
+	}
+	addFlags.noStart = true
+	if err := addCmd.PreRunE(addCmd, []string{projectDir}); err != nil {
+		t.Fatalf("PreRunE: %v", err)
+	}
+	if err := addCmd.PreRunE(addCmd, []string{root}); err == nil {
+		t.Error("PreRunE: expected --domain to be required for a directory without a compose file")
+	}
+
+	if err := runAdd(nil, []string{projectDir}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	meta, err := site.ReadSiteMetadata("api")
+	if err != nil || meta == nil {
+		t.Fatalf("metadata: %v", err)
+	}
+	if len(meta.Domains) == 0 || meta.Domains[0] != "api.test" || !meta.IsLocal {
+		t.Errorf("metadata = %+v", meta)
+	}
+}
+
+func TestParseACMEDNSChallenge(t *testing.T) {
+	setupSrvRoot(t)
+	t.Cleanup(func() {
+		addFlags.acmeDNSChallenge, addFlags.acmeDNSProvider, addFlags.acmeDNSEnv = false, "", nil
+	})
+	tests := []struct {
+		name      string
+		challenge bool
+		provider  string
+		env       []string
+		local     bool
+		want      string
+	}{
+		{"provider without challenge", false, "cloudflare", nil, false, "only apply with --acme-dns-challenge"},
+		{"local site", true, "cloudflare", nil, true, "local sites use mkcert"},
+		{"missing provider", true, "", nil, false, "needs --acme-dns-provider"},
+		{"malformed env", true, "cloudflare", []string{"CF_DNS_API_TOKEN"}, false, "expected KEY=VALUE"},
+		{"invalid provider", true, "cloud flare", nil, false, "invalid DNS provider"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addFlags.acmeDNSChallenge, addFlags.acmeDNSProvider, addFlags.acmeDNSEnv = tt.challenge, tt.provider, tt.env
+			_, err := parseACMEDNSChallenge(addCmd, tt.local)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("parseACMEDNSChallenge() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunAddFailureLeavesACMEDNSChallengeUnset(t *testing.T) {
+	setupSrvRoot(t)
+	cfg := mustLoadConfig(t)
+	t.Cleanup(docker.SwapNewClientErr(errors.New("offline")))
+	addFlags.acmeDNSChallenge, addFlags.acmeDNSProvider = true, "cloudflare"
+	addFlags.acmeDNSEnv = []string{"CF_DNS_API_TOKEN=secret"}
+	addFlags.domain = "app.example.com"
+	t.Cleanup(func() {
+		addFlags.acmeDNSChallenge, addFlags.acmeDNSProvider, addFlags.acmeDNSEnv = false, "", nil
+		addFlags.domain = ""
+	})
+	if err := runAdd(addCmd, []string{t.TempDir()}); err == nil {
+		t.Fatal("expected err: docker down")
+	}
+	if _, err := os.Stat(cfg.EnvTraefikPath()); !os.IsNotExist(err) {
+		t.Errorf("env.traefik written for a site that was not added (stat err = %v)", err)
+	}
+}
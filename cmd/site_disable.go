@@ -0,0 +1,132 @@
+// Package cmd — site_disable.go implements `srv disable` and `srv enable`,
+// which take a site out of Traefik routing and put it back without removing
+// its registration.
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/stubbedev/srv/internal/site"
+	"github.com/stubbedev/srv/internal/ui"
+)
+
+// =============================================================================
+// disable / enable commands
+// =============================================================================
+
+var disableCmd = &cobra.Command{
+	Use:   "disable SITE",
+	Short: "Take a site out of routing without removing it",
+	Long: `Stop Traefik routing requests to a site while keeping its registration,
+certificates and configuration.
+
+A compose site's containers keep running; its Traefik config is renamed to
+*.disabled, which Traefik ignores. Static and dockerfile sites are routed by
+their container, so they are taken down. A disabled site shows as "disabled"
+in 'srv list' and isn't started by 'srv start' until it is enabled again.
+
+Examples:
+  srv disable mysite
+  srv enable mysite`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		return requireSiteName(cmd, args, "srv disable SITE")
+	},
+	RunE: runDisable,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return siteNamesByDisabled(false), cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+var enableCmd = &cobra.Command{
+	Use:   "enable SITE",
+	Short: "Route to a disabled site again",
+	Long: `Undo 'srv disable': restore the site's Traefik config and, for static and
+dockerfile sites, start the container again.
+
+Examples:
+  srv enable mysite`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		return requireSiteName(cmd, args, "srv enable SITE")
+	},
+	RunE: runEnable,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return siteNamesByDisabled(true), cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+func init() {
+	disableCmd.GroupID = GroupSites
+	enableCmd.GroupID = GroupSites
+	RootCmd.AddCommand(disableCmd, enableCmd)
+}
+
+// requireSiteName checks that args is exactly one site name.
+func requireSiteName(cmd *cobra.Command, args []string, usage string) error {
+	if len(args) == 0 {
+		_ = cmd.Help()
+		return ui.UsageError(usage, "a site name is required")
+	}
+	if len(args) > 1 {
+		return ui.UsageError(usage, "too many arguments — expected a single site name, got %d", len(args))
+	}
+	return nil
+}
+
+// siteNamesByDisabled returns the registered sites that are disabled (or
+// enabled), for completion.
+func siteNamesByDisabled(disabled bool) []string {
+	sites, err := site.List()
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, s := range sites {
+		if s.Disabled == disabled {
+			names = append(names, s.Name)
+		}
+	}
+	return names
+}
+
+func runDisable(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	warnings, err := site.DisableSite(name)
+	if err != nil {
+		return err
+	}
+	for _, w := range warnings {
+		ui.Warn("%s", w)
+	}
+	ui.Success("Site '%s' disabled", name)
+	ui.Dim("Run 'srv enable %s' to route to it again", name)
+	return nil
+}
+
+func runEnable(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	warnings, err := site.EnableSite(name)
+	if err != nil {
+		return err
+	}
+	for _, w := range warnings {
+		ui.Warn("%s", w)
+	}
+	meta, err := site.ReadSiteMetadata(name)
+	if err != nil {
+		return err
+	}
+	if meta.Type == site.SiteTypeStatic || meta.Type == site.SiteTypeDockerfile {
+		ui.Info("Starting %s...", name)
+		if err := site.StartSite(name, false); err != nil {
+			return err
+		}
+	}
+	ui.Success("Site '%s' enabled", name)
+	return nil
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stubbedev/srv/internal/docker"
+	"github.com/stubbedev/srv/internal/site"
+	"github.com/stubbedev/srv/internal/traefik"
+)
+
+func TestDisableEnableComposeSite(t *testing.T) {
+	root := setupSrvRoot(t)
+	cfg := mustLoadConfig(t)
+	projectDir := filepath.Join(root, "app")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestSite(t, "app", site.SiteMetadata{
+		Type:        site.SiteTypeCompose,
+		Domains:     []string{"app.test"},
+		ProjectPath: projectDir,
+		ServiceName: "app",
+		Port:        8080,
+		NetworkName: cfg.NetworkName,
+	})
+	if _, err := site.Reload("app"); err != nil {
+		t.Fatal(err)
+	}
+	routeFile := traefik.SiteRouteConfigPath(cfg, "app")
+	var composeCalls [][]string
+	t.Cleanup(docker.SwapComposeExec(func(_ string, _ bool, args ...string) error {
+		composeCalls = append(composeCalls, args)
+		return nil
+	}))
+
+	if err := runDisable(nil, []string{"app"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(routeFile); !os.IsNotExist(err) {
+		t.Error("route config still active after disable")
+	}
+	if _, err := os.Stat(routeFile + ".disabled"); err != nil {
+		t.Errorf("parked route config missing: %v", err)
+	}
+	if len(composeCalls) != 0 {
+		t.Errorf("disabling a compose site ran compose %v", composeCalls)
+	}
+
+	// A metadata reload while disabled must not bring the route back.
+	if _, err := site.ForceReload("app"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(routeFile); !os.IsNotExist(err) {
+		t.Error("reload re-enabled the route config")
+	}
+
+	s, err := site.GetByName("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := listSiteStatus(*s); got != "disabled" {
+		t.Errorf("list status = %q, want disabled", got)
+	}
+	if err := runDisable(nil, []string{"app"}); err == nil || !strings.Contains(err.Error(), "already disabled") {
+		t.Errorf("second disable err = %v", err)
+	}
+
+	t.Cleanup(docker.SwapNewClientWithNetwork(cfg.NetworkName))
+	if err := runStart(nil, []string{"app"}); err == nil || !strings.Contains(err.Error(), "--enable-first") {
+		t.Errorf("start of disabled site err = %v", err)
+	}
+
+	if err := runEnable(nil, []string{"app"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(routeFile); err != nil {
+		t.Errorf("route config missing after enable: %v", err)
+	}
+	if _, err := os.Stat(routeFile + ".disabled"); !os.IsNotExist(err) {
+		t.Error("parked route config left behind after enable")
+	}
+	if err := runEnable(nil, []string{"app"}); err == nil || !strings.Contains(err.Error(), "not disabled") {
+		t.Errorf("second enable err = %v", err)
+	}
+}
+
+func TestDisableStaticSiteTakesItDown(t *testing.T) {
+	root := setupSrvRoot(t)
+	cfg := mustLoadConfig(t)
+	projectDir := filepath.Join(root, "blog")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestSite(t, "blog", site.SiteMetadata{
+		Type:        site.SiteTypeStatic,
+		Domains:     []string{"blog.local"},
+		ProjectPath: projectDir,
+		Port:        80,
+		NetworkName: cfg.NetworkName,
+	})
+	t.Cleanup(docker.SwapNewClientWithNetwork(cfg.NetworkName))
+	var composeCalls [][]string
+	t.Cleanup(docker.SwapComposeExec(func(_ string, _ bool, args ...string) error {
+		composeCalls = append(composeCalls, args)
+		return nil
+	}))
+
+	if err := runDisable(nil, []string{"blog"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(composeCalls) == 0 || !slices.Contains(composeCalls[len(composeCalls)-1], "down") {
+		t.Errorf("compose calls = %v, want a down", composeCalls)
+	}
+
+	// --all skips the disabled site.
+	composeCalls = nil
+	startFlags.all = true
+	t.Cleanup(func() { startFlags.all = false })
+	if err := runStart(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(composeCalls) != 0 {
+		t.Errorf("start --all started a disabled site: %v", composeCalls)
+	}
+	startFlags.all = false
+
+	startFlags.enableFirst = true
+	t.Cleanup(func() { startFlags.enableFirst = false })
+	if err := runStart(nil, []string{"blog"}); err != nil {
+		t.Fatal(err)
+	}
+	meta, err := site.ReadSiteMetadata("blog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Disabled {
+		t.Error("--enable-first left the site disabled")
+	}
+	if !slices.ContainsFunc(composeCalls, func(args []string) bool { return slices.Contains(args, "up") }) {
+		t.Errorf("compose calls = %v, want an up", composeCalls)
+	}
+}
@@ -0,0 +1,79 @@
+// Package cmd — site_edit.go implements `srv edit`, which changes a site's
+// settings in place instead of removing and re-adding it.
+package cmd
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stubbedev/srv/internal/site"
+	"github.com/stubbedev/srv/internal/ui"
+)
+
+// =============================================================================
+// edit command
+// =============================================================================
+
+var editFlags struct {
+	allowIPs []string
+}
+
+var editCmd = &cobra.Command{
+	Use:   "edit SITE",
+	Short: "Change a site's settings",
+	Long: `Change settings of an existing site without recreating it.
+
+--allow-ip replaces the list of client CIDRs allowed to reach the site;
+everyone else gets a 403. Pass an empty value to allow all clients again.
+Compose sites pick the change up immediately; static and dockerfile sites
+need a restart.
+
+Examples:
+  srv edit mysite --allow-ip 192.168.1.0/24,10.0.0.0/8
+  srv edit mysite --allow-ip ""`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			_ = cmd.Help()
+			return ui.UsageError("srv edit SITE --allow-ip CIDR,...", "expected a single site name, got %d argument(s)", len(args))
+		}
+		return nil
+	},
+	RunE: runEdit,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return GetSiteNames(), cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+func init() {
+	editCmd.Flags().StringSliceVar(&editFlags.allowIPs, "allow-ip", nil, "Only allow clients from these CIDRs (empty to allow all)")
+	editCmd.GroupID = GroupSites
+	RootCmd.AddCommand(editCmd)
+}
+
+func runEdit(cmd *cobra.Command, args []string) error {
+	siteName := args[0]
+	if !cmd.Flags().Changed("allow-ip") {
+		return ui.UsageError("srv edit SITE --allow-ip CIDR,...", "nothing to change — pass --allow-ip")
+	}
+
+	needsRestart, warnings, err := site.SetAllowIPs(siteName, editFlags.allowIPs)
+	if err != nil {
+		return err
+	}
+	for _, w := range warnings {
+		ui.Warn("%s", w)
+	}
+	if len(editFlags.allowIPs) == 0 {
+		ui.Success("Removed the IP allowlist from %s", siteName)
+	} else {
+		ui.Success("%s now only allows %s", siteName, strings.Join(editFlags.allowIPs, ", "))
+	}
+	if needsRestart {
+		ui.Dim("Run 'srv restart %s' for the change to take effect.", siteName)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stubbedev/srv/internal/site"
+)
+
+func TestRunEditAllowIPs(t *testing.T) {
+	setupSrvRoot(t)
+	writeTestSite(t, "blog", site.SiteMetadata{
+		Type:        site.SiteTypeCompose,
+		Domains:     []string{"blog.test"},
+		ProjectPath: t.TempDir(),
+		ServiceName: "web",
+		Port:        80,
+	})
+	t.Cleanup(func() {
+		editFlags.allowIPs = nil
+		editCmd.Flags().Lookup("allow-ip").Changed = false
+	})
+
+	if err := runEdit(editCmd, []string{"blog"}); err == nil {
+		t.Error("expected usage error without --allow-ip")
+	}
+	if err := editCmd.Flags().Set("allow-ip", "192.168.1.0/24,10.0.0.0/8"); err != nil {
+		t.Fatal(err)
+	}
+	if err := runEdit(editCmd, []string{"blog"}); err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+	meta, _ := site.ReadSiteMetadata("blog")
+	if len(meta.AllowIPs) != 2 || meta.AllowIPs[1] != "10.0.0.0/8" {
+		t.Errorf("allow_ips = %v", meta.AllowIPs)
+	}
+
+	editFlags.allowIPs = []string{"not-a-cidr"}
+	if err := runEdit(editCmd, []string{"blog"}); err == nil {
+		t.Error("expected error for an invalid CIDR")
+	}
+}
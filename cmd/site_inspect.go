@@ -1,11 +1,14 @@
 // Package cmd — site_inspect.go bundles the read-only site commands:
-// `srv list`, `srv info`, and `srv logs`.
+// `srv list`, `srv info`, `srv logs` and `srv ps`.
 package cmd
 
 import (
 	"fmt"
+	"regexp"
+	"slices"
 	"sort"
-	"sync"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -21,116 +24,343 @@ import (
 // list command
 // =============================================================================
 
+var listFlags struct {
+	filters        []string
+	tag            string
+	sort           string
+	parked         bool
+	registeredOnly bool
+}
+
 var listCmd = &cobra.Command{
 	Use:     "list",
 	Aliases: []string{"ls"},
 	Short:   "List all sites",
-	RunE:    runList,
+	Long: `List registered sites, optionally filtered and sorted.
+
+Filters (--filter KEY=VALUE, repeatable; all must match):
+  status  running, stopped, broken, partial or disabled
+  type    compose, static or dockerfile
+  ssl     local, production, missing or expired (the last two check the
+          mkcert certificate of local sites)
+  domain  substring of any of the site's domains
+
+--tag lists only the sites carrying a tag (see 'srv tag').
+
+--parked also lists the projects in parked directories (see 'srv park') that
+aren't registered yet, with status "parked": compose projects and static
+sites (a directory with an index.html). --registered-only lists registered
+sites alone, the default.
+
+Examples:
+  srv list --filter status=running
+  srv list --filter type=static --filter ssl=expired
+  srv list --filter domain=.test --sort status
+  srv list --tag production
+  srv list --parked
+  srv list --json`,
+	RunE: runList,
+}
+
+// listFilterValues lists the accepted values per --filter key; domain takes
+// any substring and so has no entry.
+var listFilterValues = map[string][]string{
+	"status": {constants.StatusRunning, constants.StatusStopped, constants.StatusBroken, constants.StatusPartial, constants.StatusDisabled, constants.StatusParked},
+	"type":   {string(site.SiteTypeCompose), string(site.SiteTypeStatic), string(site.SiteTypeDockerfile)},
+	"ssl":    {"local", "production", string(traefik.CertStatusMissing), string(traefik.CertStatusExpired)},
 }
 
+// listSortKeys are the accepted --sort values.
+var listSortKeys = []string{"name", "domain", "status", "type"}
+
 func init() {
+	listCmd.Flags().StringArrayVar(&listFlags.filters, "filter", nil, "Only show sites matching KEY=VALUE (status, type, ssl, domain); repeatable")
+	listCmd.Flags().StringVar(&listFlags.tag, "tag", "", "Only show sites carrying this tag")
+	_ = listCmd.RegisterFlagCompletionFunc("tag", completeTags)
+	listCmd.Flags().StringVar(&listFlags.sort, "sort", "name", "Sort by: name, domain, status or type")
+	listCmd.Flags().BoolVar(&listFlags.parked, "parked", false, "Also list unregistered projects in parked directories")
+	listCmd.Flags().BoolVar(&listFlags.registeredOnly, "registered-only", false, "List registered sites only (the default)")
+	listCmd.MarkFlagsMutuallyExclusive("parked", "registered-only")
+	addJSONFlag(listCmd)
+	_ = listCmd.RegisterFlagCompletionFunc("filter", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var out []string
+		for _, key := range []string{"status", "type", "ssl"} {
+			for _, v := range listFilterValues[key] {
+				out = append(out, key+"="+v)
+			}
+		}
+		return append(out, "domain="), cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	})
+	_ = listCmd.RegisterFlagCompletionFunc("sort", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return listSortKeys, cobra.ShellCompDirectiveNoFileComp
+	})
 	listCmd.GroupID = GroupSites
 	RootCmd.AddCommand(listCmd)
 }
 
-// listSiteRow is the json shape for one site under `srv list --format json`.
-type listSiteRow struct {
-	Name    string   `json:"name"`
-	Domains []string `json:"domains"`
-	Target  string   `json:"target"`
-	Type    string   `json:"type"`
-	SSL     string   `json:"ssl"`
-	Status  string   `json:"status"`
-	Local   bool     `json:"local"`
-	Broken  bool     `json:"broken"`
-}
-
 func runList(cmd *cobra.Command, args []string) error {
+	filters, err := parseListFilters(listFlags.filters)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(listSortKeys, listFlags.sort) {
+		return fmt.Errorf("invalid --sort %q (expected one of: %s)", listFlags.sort, strings.Join(listSortKeys, ", "))
+	}
+
 	sites, err := site.List()
 	if err != nil {
 		return err
 	}
+	if listFlags.parked && !listFlags.registeredOnly {
+		projects, err := getParkedProjects()
+		if err != nil {
+			return err
+		}
+		sites = append(sites, projects...)
+	}
 
 	if len(sites) == 0 {
 		if jsonOutput() {
-			return ui.PrintJSON([]listSiteRow{})
+			return ui.PrintJSON([]site.SiteView{})
+		}
+		if csvOutput() {
+			return ui.PrintCSV(listTableHeaders, nil)
 		}
 		ui.Dim("No sites registered. Use 'srv add PATH' to add a site.")
 		return nil
 	}
 
-	sort.Slice(sites, func(i, j int) bool { return sites[i].Name < sites[j].Name })
+	sites = filterSites(sites, filters)
+	if listFlags.tag != "" {
+		sites = sitesWithTag(sites, listFlags.tag)
+	}
+	sortSites(sites, listFlags.sort)
 
 	if jsonOutput() {
-		out := make([]listSiteRow, 0, len(sites))
+		out := make([]site.SiteView, 0, len(sites))
 		for _, s := range sites {
-			status := s.Status
-			if s.IsBroken {
-				status = constants.StatusBroken
-			}
-			out = append(out, listSiteRow{
-				Name:    s.Name,
-				Domains: append([]string(nil), s.Domains...),
-				Target:  s.Dir,
-				Type:    plainSiteTypeLabel(s),
-				SSL:     plainSSLStatus(s),
-				Status:  status,
-				Local:   s.IsLocal,
-				Broken:  s.IsBroken,
-			})
+			out = append(out, s.View())
 		}
 		return ui.PrintJSON(out)
 	}
 
-	headers := []string{"NAME", "DOMAIN", "TARGET", "TYPE", "SSL", "STATUS"}
+	if csvOutput() {
+		return ui.PrintCSV(listTableHeaders, listTableRows(sites, nil))
+	}
+	if len(sites) == 0 {
+		ui.Dim("No sites match the given filters.")
+		return nil
+	}
+
+	ui.PrintTable(listTableHeaders, listTableRows(sites, nil))
+	if slices.ContainsFunc(sites, func(s site.Site) bool { return s.Status == constants.StatusParked }) {
+		ui.Blank()
+		ui.Dim("Add a parked project with 'srv add PATH --domain DOMAIN'")
+	}
+	return nil
+}
+
+// getParkedProjects returns the unregistered projects in parked directories
+// as sites with status "parked", named and typed as `srv add` would add them.
+// A parked directory that can't be read is reported and skipped.
+func getParkedProjects() ([]site.Site, error) {
+	paths, err := parkedPaths()
+	if err != nil {
+		return nil, err
+	}
+	registered, err := site.RegisteredProjects()
+	if err != nil {
+		return nil, err
+	}
+	depth := site.ParkDepth()
+	var sites []site.Site
+	for _, dir := range paths {
+		projects, err := site.ScanParkedSites(dir, depth, registered)
+		if err != nil {
+			ui.Warn("Skipping parked directory %s: %v", dir, err)
+			continue
+		}
+		for _, p := range unregisteredProjects(projects) {
+			sites = append(sites, site.Site{
+				Name:       p.Name,
+				Dir:        p.Path,
+				ComposeDir: p.Path,
+				Type:       site.SiteType(p.Type),
+				Status:     constants.StatusParked,
+			})
+		}
+	}
+	return sites, nil
+}
+
+// listTableHeaders are the columns of the `srv list` table, shared with the
+// `srv status` dashboard.
+var listTableHeaders = []string{"NAME", "DOMAIN", "TARGET", "TYPE", "SSL", "HEALTH", "STATUS"}
+
+// listTableRows renders sites as `srv list` table rows. Sites named in flash
+// get their status cell highlighted.
+func listTableRows(sites []site.Site, flash map[string]bool) [][]string {
 	rows := make([][]string, 0, len(sites))
 	for _, s := range sites {
-		status := s.Status
-		if s.IsBroken {
-			status = constants.StatusBroken
-		}
 		target := s.Dir
 		if s.IsBroken {
 			target = ui.DimText("-")
 		}
+		domains := formatDomainsForList(s.Domains)
+		if s.Status == constants.StatusParked {
+			domains = ui.DimText("-")
+		}
+		status := ui.StatusColor(listSiteStatus(s))
+		if flash[s.Name] {
+			status = ui.FlashText(listSiteStatus(s))
+		}
 		rows = append(rows, []string{
 			s.Name,
-			formatDomainsForList(s.Domains),
+			domains,
 			target,
 			getSiteTypeLabel(s),
 			getSSLStatus(s),
-			ui.StatusColor(status),
+			siteHealth(s),
+			status,
 		})
 	}
-	ui.PrintTable(headers, rows)
-	return nil
+	return rows
 }
 
-// plainSiteTypeLabel is the json-friendly counterpart of getSiteTypeLabel —
-// returns a bare string with no colour codes.
-func plainSiteTypeLabel(s site.Site) string {
-	if s.IsBroken {
-		return ""
+// siteHealth is the HEALTH cell of a site: the state Traefik's health check
+// reports for a running site that has one, "-" otherwise.
+func siteHealth(s site.Site) string {
+	if s.HealthCheckPath == "" || s.Status != constants.StatusRunning {
+		return ui.DimText("-")
 	}
-	switch s.Type {
-	case site.SiteTypeStatic:
-		return "static"
-	case site.SiteTypeDockerfile:
-		return "dockerfile"
+	switch health := traefik.ServiceHealth(s.Name); health {
+	case traefik.HealthHealthy:
+		return ui.SuccessText(health)
+	case traefik.HealthUnhealthy:
+		return ui.ErrorText(health)
 	default:
-		return "compose"
+		return ui.WarnText(health)
+	}
+}
+
+// listFilter is one parsed --filter KEY=VALUE.
+type listFilter struct {
+	key, value string
+}
+
+// parseListFilters validates --filter values up front so a typo fails loudly
+// instead of silently matching nothing.
+func parseListFilters(raw []string) ([]listFilter, error) {
+	filters := make([]listFilter, 0, len(raw))
+	for _, f := range raw {
+		key, value, ok := strings.Cut(f, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		if !ok || value == "" {
+			return nil, fmt.Errorf("invalid --filter %q (expected KEY=VALUE)", f)
+		}
+		if key == "domain" {
+			filters = append(filters, listFilter{key: key, value: strings.ToLower(value)})
+			continue
+		}
+		allowed, known := listFilterValues[key]
+		if !known {
+			return nil, fmt.Errorf("invalid --filter key %q (expected status, type, ssl or domain)", key)
+		}
+		value = strings.ToLower(value)
+		if !slices.Contains(allowed, value) {
+			return nil, fmt.Errorf("invalid --filter %s value %q (expected one of: %s)", key, value, strings.Join(allowed, ", "))
+		}
+		filters = append(filters, listFilter{key: key, value: value})
+	}
+	return filters, nil
+}
+
+// filterSites keeps the sites that match every filter.
+func filterSites(sites []site.Site, filters []listFilter) []site.Site {
+	if len(filters) == 0 {
+		return sites
+	}
+	out := sites[:0]
+	for _, s := range sites {
+		if matchesListFilters(s, filters) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func matchesListFilters(s site.Site, filters []listFilter) bool {
+	for _, f := range filters {
+		if !matchesListFilter(s, f) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesListFilter(s site.Site, f listFilter) bool {
+	switch f.key {
+	case "status":
+		// Partially running sites report "partial (n/m)"; match on the prefix.
+		status := listSiteStatus(s)
+		if f.value == constants.StatusPartial {
+			return strings.HasPrefix(status, constants.StatusPartial)
+		}
+		return status == f.value
+	case "type":
+		return !s.IsBroken && s.TypeLabel() == f.value
+	case "ssl":
+		switch f.value {
+		case "local":
+			return s.IsLocal
+		case "production":
+			return !s.IsLocal
+		default:
+			return s.IsLocal && !s.IsBroken && s.SSLStatus() == f.value
+		}
+	case "domain":
+		for _, d := range s.Domains {
+			if strings.Contains(strings.ToLower(d), f.value) {
+				return true
+			}
+		}
 	}
+	return false
 }
 
-// plainSSLStatus mirrors getSSLStatus but returns the raw value for json.
-func plainSSLStatus(s site.Site) string {
+// sortSites orders sites by key, falling back to the name so ties are stable.
+func sortSites(sites []site.Site, key string) {
+	field := func(s site.Site) string {
+		switch key {
+		case "domain":
+			return s.Domain()
+		case "status":
+			return listSiteStatus(s)
+		case "type":
+			return s.TypeLabel()
+		default:
+			return s.Name
+		}
+	}
+	sort.SliceStable(sites, func(i, j int) bool {
+		a, b := field(sites[i]), field(sites[j])
+		if a != b {
+			return a < b
+		}
+		return sites[i].Name < sites[j].Name
+	})
+}
+
+// listSiteStatus is the status `srv list` shows: broken sites report broken
+// regardless of what Docker says.
+func listSiteStatus(s site.Site) string {
 	if s.IsBroken {
-		return ""
+		return constants.StatusBroken
 	}
-	if !s.IsLocal {
-		return "auto"
+	if s.Disabled {
+		return constants.StatusDisabled
 	}
-	return string(traefik.GetLocalCertInfo(s.Name, s.Domain()).Status())
+	return s.Status
 }
 
 // formatDomainsForList renders a site's domains for the `srv list` table.
@@ -162,25 +392,43 @@ func getSiteTypeLabel(s site.Site) string {
 	}
 }
 
-// getSSLStatus returns a formatted SSL status string for a site
+// getSSLStatus returns a formatted SSL status string for a site. HTTP-only
+// sites show "http"; sites that also answer plain HTTP get a "+http" suffix.
 func getSSLStatus(s site.Site) string {
-	if s.IsBroken {
+	if s.IsBroken || s.Status == constants.StatusParked {
 		return ui.DimText("-")
 	}
 
-	if s.IsLocal {
-		// Local site - check mkcert certificate (named after the primary domain)
-		return ui.StatusColor(string(traefik.GetLocalCertInfo(s.Name, s.Domain()).Status()))
+	// Plain HTTP only - no certificate involved
+	if !s.ServesHTTPS() {
+		return ui.WarnText("http")
 	}
 
-	// Production site - Let's Encrypt (auto-managed)
-	return ui.DimText("auto")
+	var status string
+	switch {
+	case s.IsLocal:
+		// Local site - check mkcert certificate (named after the primary domain)
+		status = ui.StatusColor(string(traefik.GetLocalCertInfo(s.Name, s.Domain()).Status()))
+	case s.Staging:
+		// Production site - Let's Encrypt (auto-managed)
+		status = ui.WarnText(constants.TypeLabelStaging)
+	default:
+		status = ui.DimText("auto")
+	}
+	if s.ServesHTTP() {
+		status += ui.DimText("+http")
+	}
+	return status
 }
 
 // =============================================================================
 // info command
 // =============================================================================
 
+var infoFlags struct {
+	resources bool
+}
+
 var infoCmd = &cobra.Command{
 	Use:   "info SITE",
 	Short: "Show site info",
@@ -188,7 +436,12 @@ var infoCmd = &cobra.Command{
   - Site name and path
   - Domain and type (local/production)
   - Container status
-  - SSL certificate status (for local sites)`,
+  - SSL certificate status (for local sites)
+
+With --resources, the primary container's CPU, memory and network usage
+(sampled once via docker stats) are shown below the status.
+
+--json prints the site in the same shape as one entry of 'srv list --json'.`,
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) == 0 {
 			_ = cmd.Help()
@@ -206,6 +459,8 @@ var infoCmd = &cobra.Command{
 }
 
 func init() {
+	infoCmd.Flags().BoolVar(&infoFlags.resources, "resources", false, "Show the container's CPU, memory and network usage")
+	addJSONFlag(infoCmd)
 	infoCmd.GroupID = GroupSites
 	RootCmd.AddCommand(infoCmd)
 }
@@ -215,6 +470,9 @@ func runInfo(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	if jsonOutput() {
+		return ui.PrintJSON(s.View())
+	}
 
 	ui.Blank()
 	ui.Bold("Site: %s", s.Name)
@@ -228,11 +486,27 @@ func runInfo(cmd *cobra.Command, args []string) error {
 			ui.Print("  Alias:   %s", alias)
 		}
 	}
-	ui.Print("  SSL:     %s", ui.TypeColor(s.IsLocal))
+	if !s.ServesHTTPS() {
+		ui.Print("  SSL:     %s (plain HTTP only)", ui.WarnText("none"))
+	} else if s.Staging && !s.IsLocal {
+		ui.Print("  SSL:     %s (resolver: %s, untrusted certs)", ui.WarnText(constants.TypeLabelStaging), constants.CertResolverLetsEncryptStaging)
+	} else if !s.IsLocal {
+		ui.Print("  SSL:     %s (resolver: %s)", ui.TypeColor(false), constants.CertResolverLetsEncrypt)
+	} else {
+		ui.Print("  SSL:     %s", ui.TypeColor(true))
+	}
+	if s.ServesHTTPS() && s.ServesHTTP() {
+		ui.Print("  HTTP:    served too, not redirected to HTTPS")
+	}
+	if len(s.Domains) > 0 {
+		ui.Print("  Rule:    %s", s.Rule())
+	}
+	if s.PathPrefix != "" && s.StripPrefix {
+		ui.Print("  Path:    %s (stripped before forwarding)", s.PathPrefix)
+	}
 
 	// Site type info
 	meta, _ := site.ReadSiteMetadata(s.Name)
-	_ = meta // metadata is still used elsewhere by callers; keep reference live.
 	switch s.Type {
 	case site.SiteTypeStatic:
 		ui.Print("  Type:    %s", "static (nginx)")
@@ -249,6 +523,63 @@ func runInfo(cmd *cobra.Command, args []string) error {
 		if s.Port != 0 {
 			ui.Print("  Port:    %d", s.Port)
 		}
+		if s.OverridePath != "" {
+			ui.Print("  Override: %s", s.OverridePath)
+		}
+	}
+
+	if len(s.Tags) > 0 {
+		ui.Print("  Tags:    %s", strings.Join(s.Tags, ", "))
+	}
+	if len(s.DependsOn) > 0 {
+		ui.Print("  Depends on: %s", strings.Join(s.DependsOn, ", "))
+	}
+	if meta != nil && meta.HealthCheckPath != "" {
+		interval := meta.HealthCheckInterval
+		if interval == "" {
+			interval = traefik.DefaultHealthCheckInterval
+		}
+		ui.Print("  Health:  %s every %s", meta.HealthCheckPath, interval)
+	}
+	switch {
+	case s.Type == site.SiteTypeStatic && meta != nil && meta.Brotli:
+		ui.Print("  Compression: nginx (gzip, brotli)")
+	case s.Type == site.SiteTypeStatic:
+		ui.Print("  Compression: nginx (gzip)")
+	case meta != nil && meta.Compress:
+		ui.Print("  Compression: traefik")
+	}
+	if meta != nil && meta.RetryAttempts > 0 {
+		interval := meta.RetryInterval
+		if interval == "" {
+			interval = traefik.DefaultRetryInterval
+		}
+		ui.Print("  Retry:   %d attempts, backing off from %s", meta.RetryAttempts, interval)
+	}
+	if meta != nil && len(meta.Middlewares) > 0 {
+		ui.Print("  Middlewares: %s", strings.Join(meta.Middlewares, ", "))
+	}
+	if meta != nil && len(meta.AddHeaders) > 0 {
+		names := make([]string, 0, len(meta.AddHeaders))
+		for name := range meta.AddHeaders {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			ui.Print("  Header:  %s: %s", name, meta.AddHeaders[name])
+		}
+	}
+	if meta != nil && len(meta.RemoveHeaders) > 0 {
+		ui.Print("  Strips:  %s", strings.Join(meta.RemoveHeaders, ", "))
+	}
+	if meta != nil && meta.MaxRequestBodySize != "" {
+		ui.Print("  Max request body:  %s", meta.MaxRequestBodySize)
+	}
+	if meta != nil && meta.MaxResponseBodySize != "" {
+		ui.Print("  Max response body: %s", meta.MaxResponseBodySize)
+	}
+	if meta != nil && len(meta.AllowIPs) > 0 {
+		ui.Print("  Allow IPs: %s", strings.Join(meta.AllowIPs, ", "))
 	}
 
 	cfg, _ := config.Load()
@@ -263,35 +594,66 @@ func runInfo(cmd *cobra.Command, args []string) error {
 	} else {
 		ui.Print("  Status:  %s", ui.StatusColor(s.Status))
 	}
+	if meta != nil && !meta.LastPulled.IsZero() {
+		ui.Print("  Pulled:  %s", meta.LastPulled.Local().Format(time.DateTime))
+	}
+	if infoFlags.resources {
+		showResourceUsage(s)
+	}
 
 	ui.Blank()
 
 	// SSL certificate info for local sites
-	if s.IsLocal && s.Domain() != "" {
-		showCertInfo(s.Domain())
+	if s.IsLocal && s.ServesHTTPS() && s.Domain() != "" {
+		showCertInfo(s.Domain(), s.CustomCert)
 	}
 
 	// Show URL if running
 	if s.Status == constants.StatusRunning && s.Domain() != "" {
 		ui.Blank()
-		ui.Info("URL: https://%s", s.Domain())
+		ui.Info("URL: %s", s.URL())
 	}
 
 	ui.Blank()
 	return nil
 }
 
-// showCertInfo displays SSL certificate information for a domain
-func showCertInfo(domain string) {
+// showResourceUsage prints the primary container's resource usage, or dashes
+// when the site isn't running or docker stats can't sample it.
+func showResourceUsage(s *site.Site) {
+	stats := &docker.ContainerStats{CPUPercent: "-", MemUsage: "-", MemLimit: "-", NetIO: "-"}
+	if s.Status == constants.StatusRunning {
+		if sample, err := docker.GetContainerStats(s.PrimaryContainer()); err == nil {
+			stats = sample
+		} else {
+			ui.VerboseLog("info: %v", err)
+		}
+	}
+	ui.Print("  CPU:     %s", stats.CPUPercent)
+	ui.Print("  Memory:  %s / %s", stats.MemUsage, stats.MemLimit)
+	ui.Print("  Net I/O: %s", stats.NetIO)
+}
+
+// showCertInfo displays SSL certificate information for a domain; custom
+// marks a certificate imported with 'srv cert import'.
+func showCertInfo(domain string, custom bool) {
 	certs := traefik.ListLocalCerts()
 	for _, cert := range certs {
 		if cert.Domain == domain {
 			ui.Bold("SSL Certificate")
 			ui.Print("  Domain:  %s", cert.Domain)
 
-			if cert.IsExpired {
+			if custom {
+				// Imported certificates are never renewed by srv, so a
+				// countdown to renewal would mislead.
+				status := "custom"
+				if cert.IsExpired {
+					status += " (" + ui.StatusColor("expired") + ")"
+				}
+				ui.Print("  Status:  %s", status)
+			} else if cert.IsExpired {
 				ui.Print("  Status:  %s", ui.StatusColor("expired"))
-			} else if cert.DaysLeft <= constants.CertExpiryWarningDays {
+			} else if cert.DaysLeft <= traefik.CertWarningDays() {
 				ui.Print("  Status:  %s (%d days left)", ui.StatusColor("expiring"), cert.DaysLeft)
 			} else {
 				ui.Print("  Status:  %s (%d days left)", ui.StatusColor("valid"), cert.DaysLeft)
@@ -304,7 +666,11 @@ func showCertInfo(domain string) {
 
 	ui.Bold("SSL Certificate")
 	ui.Dim("  No certificate found for %s", domain)
-	ui.IndentedDim(1, "Certificate will be generated on 'srv start'")
+	if custom {
+		ui.IndentedDim(1, "Import one with 'srv cert import'")
+	} else {
+		ui.IndentedDim(1, "Certificate will be generated on 'srv start'")
+	}
 }
 
 // =============================================================================
@@ -312,22 +678,102 @@ func showCertInfo(domain string) {
 // =============================================================================
 
 var logsFlags struct {
-	follow bool
-	all    bool
-	tail   string
-	since  string
+	follow   bool
+	all      bool
+	mergeAll bool
+	sites    []string
+	access   bool
+	tail     string
+	since    string
+	// --since-start / --last-n-starts: logs since the container's (Nth)
+	// most recent start
+	sinceStart  bool
+	lastNStarts int
+	status      string
+	filter      string
+	invert      bool
 }
 
+// logsFilter is --filter compiled by the logs Args check; nil when unset.
+var logsFilter *regexp.Regexp
+
 var logsCmd = &cobra.Command{
 	Use:   "logs [SITE]",
 	Short: "Show site logs",
+	Long: `Show a site's container logs (docker compose logs).
+
+With --access, show the requests Traefik served for the site's domains
+instead, read from Traefik's JSON access log: time, client IP, method,
+status, duration and path. --status narrows them to a class (4xx, 5xx) or
+a single code.
+
+--filter shows only the lines matching a regular expression (Go syntax);
+--invert shows the lines that don't match instead. --tail and --since are
+applied by docker compose first, so --tail 100 --filter ERROR shows the
+errors among the last 100 lines.
+
+--since-start shows only what the site's container logged since it last
+started. --last-n-starts N goes back to its Nth most recent start instead,
+found in the last 7 days of docker events (the daemon keeps a limited
+history, so older starts may be missing).
+
+--merge-all (or --all) streams every running site's logs at once, each line
+prefixed with its site name in a colour of its own; --sites limits it to the
+named sites. With --follow, a site that stops mid-stream is reported as
+exited and the others keep streaming.
+
+Examples:
+  srv logs mysite -f
+  srv logs --merge-all -f
+  srv logs --merge-all --sites api,web,worker -f
+  srv logs --all --since 10m
+  srv logs mysite -f --filter 'ERROR|WARN'
+  srv logs mysite --filter healthcheck --invert
+  srv logs mysite --since-start
+  srv logs mysite --last-n-starts 2
+  srv logs mysite --access --tail 50
+  srv logs mysite --access --status 5xx -f`,
 	Args: func(cmd *cobra.Command, args []string) error {
-		if logsFlags.all {
+		if logsFlags.status != "" && !logsFlags.access {
+			return ui.UsageError("srv logs SITE --access --status CLASS", "--status only applies to --access logs")
+		}
+		if logsFlags.invert && logsFlags.filter == "" {
+			return ui.UsageError("srv logs SITE --filter REGEX --invert", "--invert needs a --filter pattern")
+		}
+		logsFilter = nil
+		if logsFlags.filter != "" {
+			if logsFlags.access {
+				return ui.UsageError("srv logs SITE --access --status CLASS", "--filter applies to container logs — use --status with --access")
+			}
+			re, err := regexp.Compile(logsFlags.filter)
+			if err != nil {
+				return ui.UsageError("srv logs SITE --filter REGEX", "invalid --filter pattern: %v", err)
+			}
+			logsFilter = re
+		}
+		if logsFlags.lastNStarts < 0 {
+			return ui.UsageError("srv logs SITE --last-n-starts N", "--last-n-starts must be at least 1, got %d", logsFlags.lastNStarts)
+		}
+		fromStart := logsFlags.sinceStart || logsFlags.lastNStarts > 0
+		if fromStart && logsFlags.access {
+			return ui.UsageError("srv logs SITE --since-start", "--since-start and --last-n-starts apply to container logs — use --since with --access")
+		}
+		merged := logsFlags.all || logsFlags.mergeAll
+		if fromStart && merged {
+			return ui.UsageError("srv logs SITE --since-start", "--since-start and --last-n-starts follow one site's container — drop --merge-all")
+		}
+		if len(logsFlags.sites) > 0 && !merged {
+			return ui.UsageError("srv logs --merge-all --sites a,b,c", "--sites needs --merge-all")
+		}
+		if merged {
+			if logsFlags.access {
+				return ui.UsageError("srv logs SITE --access", "--access shows one site's requests — drop --merge-all")
+			}
 			return cobra.NoArgs(cmd, args)
 		}
 		if len(args) == 0 {
 			_ = cmd.Help()
-			return ui.UsageError("srv logs SITE", "a site name is required (or pass --all)")
+			return ui.UsageError("srv logs SITE", "a site name is required (or pass --merge-all)")
 		}
 		if len(args) > 1 {
 			return ui.UsageError("srv logs SITE", "too many arguments — expected a single site name, got %d", len(args))
@@ -342,20 +788,35 @@ var logsCmd = &cobra.Command{
 
 func init() {
 	logsCmd.Flags().BoolVarP(&logsFlags.follow, "follow", "f", false, "Follow log output")
-	logsCmd.Flags().BoolVarP(&logsFlags.all, "all", "a", false, "Multiplex logs from every running site (colour-prefixed)")
+	logsCmd.Flags().BoolVar(&logsFlags.mergeAll, "merge-all", false, "Stream logs from every running site, interleaved with colour-coded site prefixes")
+	logsCmd.Flags().BoolVarP(&logsFlags.all, "all", "a", false, "Same as --merge-all")
+	logsCmd.Flags().StringSliceVar(&logsFlags.sites, "sites", nil, "With --merge-all, only stream these sites (comma-separated)")
 	logsCmd.Flags().StringVar(&logsFlags.tail, "tail", "", "Number of lines to show from the end")
 	logsCmd.Flags().StringVar(&logsFlags.since, "since", "", "Show logs since timestamp (e.g., 10m, 1h)")
+	logsCmd.Flags().BoolVar(&logsFlags.sinceStart, "since-start", false, "Show logs since the site's container last started")
+	logsCmd.Flags().IntVar(&logsFlags.lastNStarts, "last-n-starts", 0, "Show logs since the container's Nth most recent start (1 is the last one)")
+	logsCmd.MarkFlagsMutuallyExclusive("since", "since-start", "last-n-starts")
+	logsCmd.Flags().BoolVar(&logsFlags.access, "access", false, "Show the site's requests from Traefik's access log")
+	logsCmd.Flags().StringVar(&logsFlags.status, "status", "", "With --access, only show responses of this status class or code (e.g. 4xx, 5xx, 404)")
+	logsCmd.Flags().StringVar(&logsFlags.filter, "filter", "", "Only show log lines matching this regular expression")
+	logsCmd.Flags().BoolVar(&logsFlags.invert, "invert", false, "With --filter, show the lines that don't match instead")
+	_ = logsCmd.RegisterFlagCompletionFunc("sites", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return GetSiteNames(), cobra.ShellCompDirectiveNoFileComp
+	})
 	logsCmd.GroupID = GroupSites
 	RootCmd.AddCommand(logsCmd)
 }
 
 func runLogs(cmd *cobra.Command, args []string) error {
+	if logsFlags.access {
+		return runAccessLogs(args[0])
+	}
 	if err := docker.EnsureRunning(); err != nil {
 		return err
 	}
 
-	if logsFlags.all {
-		return runLogsAll()
+	if logsFlags.all || logsFlags.mergeAll {
+		return runLogsMerged(logsFlags.sites)
 	}
 
 	s, err := site.GetByName(args[0])
@@ -367,8 +828,23 @@ func runLogs(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("site '%s' is broken (target directory missing)", s.Name)
 	}
 
-	// Build args
-	composeArgs := []string{"logs"}
+	if logsFlags.sinceStart || logsFlags.lastNStarts > 0 {
+		since, err := logsSinceStart(s, max(logsFlags.lastNStarts, 1))
+		if err != nil {
+			return err
+		}
+		logsFlags.since = since
+	}
+
+	if logsFilter != nil {
+		return docker.ComposeFiltered(s.ComposeDir, "", keepLogLine, logsComposeArgs(s)...)
+	}
+	return docker.Compose(s.ComposeDir, logsComposeArgs(s)...)
+}
+
+// logsComposeArgs builds the `docker compose logs` args for s from the flags.
+func logsComposeArgs(s *site.Site) []string {
+	composeArgs := append(docker.ComposeFileArgs(s.ComposeFiles()), "logs")
 	if logsFlags.follow {
 		composeArgs = append(composeArgs, "-f")
 	}
@@ -378,53 +854,143 @@ func runLogs(cmd *cobra.Command, args []string) error {
 	if logsFlags.since != "" {
 		composeArgs = append(composeArgs, "--since", logsFlags.since)
 	}
+	return composeArgs
+}
 
-	return docker.Compose(s.ComposeDir, composeArgs...)
+// logsStartsWindow is how far back --last-n-starts looks for start events.
+const logsStartsWindow = 7 * 24 * time.Hour
+
+// logsSinceStart returns the --since value covering s's container since its
+// nth most recent start: the time since then, rounded up to the second so
+// the first line after the start is included.
+func logsSinceStart(s *site.Site, nth int) (string, error) {
+	name := s.PrimaryContainer()
+	if name == "" {
+		return "", fmt.Errorf("site '%s' has no service recorded to find the start of", s.Name)
+	}
+	var started time.Time
+	if nth == 1 {
+		t, err := docker.ContainerStartedAt(name)
+		if err != nil {
+			return "", err
+		}
+		started = t
+	} else {
+		starts, err := docker.ContainerStarts(name, logsStartsWindow)
+		if err != nil {
+			return "", err
+		}
+		if len(starts) < nth {
+			return "", fmt.Errorf("%s started %d time(s) in the last 7 days; can't go back %d starts", name, len(starts), nth)
+		}
+		started = starts[nth-1]
+	}
+	return (time.Since(started).Truncate(time.Second) + time.Second).String(), nil
 }
 
-// runLogsAll multiplexes `docker compose logs` for every non-broken site,
-// prefixing each output line with the site name. Stops when stdin closes
-// (Ctrl-C) or when --follow is off and every per-site tail completes.
-func runLogsAll() error {
-	sites, err := site.List()
+// keepLogLine applies --filter (and --invert) to one log line.
+func keepLogLine(line string) bool {
+	return logsFilter.MatchString(line) != logsFlags.invert
+}
+
+// =============================================================================
+// ps command
+// =============================================================================
+
+var psFlags struct {
+	all bool
+}
+
+var psCmd = &cobra.Command{
+	Use:   "ps SITE",
+	Short: "List a site's containers",
+	Long: `List the containers of a site's compose project (docker compose ps):
+service, container name, status and published ports.
+
+Static and dockerfile sites list their single generated container. Stopped
+containers are only shown with --all.
+
+Examples:
+  srv ps mysite
+  srv ps mysite --all
+  srv ps mysite --format json`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			_ = cmd.Help()
+			return ui.UsageError("srv ps SITE", "a site name is required")
+		}
+		if len(args) > 1 {
+			return ui.UsageError("srv ps SITE", "too many arguments — expected a single site name, got %d", len(args))
+		}
+		return nil
+	},
+	RunE: runPS,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return GetSiteNames(), cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+func init() {
+	psCmd.Flags().BoolVarP(&psFlags.all, "all", "a", false, "Include stopped containers")
+	psCmd.GroupID = GroupSites
+	RootCmd.AddCommand(psCmd)
+}
+
+func runPS(cmd *cobra.Command, args []string) error {
+	if err := docker.EnsureRunning(); err != nil {
+		return err
+	}
+	s, err := site.GetByName(args[0])
 	if err != nil {
 		return err
 	}
-	var running []site.Site
-	for _, s := range sites {
-		if !s.IsBroken {
-			running = append(running, s)
-		}
+	if s.IsBroken {
+		return fmt.Errorf("site '%s' is broken (target directory missing)", s.Name)
+	}
+
+	containers, err := docker.ComposePS(s.ComposeDir, s.Profile, psFlags.all)
+	if err != nil {
+		return fmt.Errorf("list containers for %s: %w", s.Name, err)
 	}
-	if len(running) == 0 {
-		ui.Dim("No sites registered")
+	if jsonOutput() {
+		return ui.PrintJSON(containers)
+	}
+	if len(containers) == 0 {
+		if psFlags.all {
+			ui.Dim("No containers for %s", s.Name)
+		} else {
+			ui.Dim("No running containers for %s (--all includes stopped ones)", s.Name)
+		}
 		return nil
 	}
 
-	var wg sync.WaitGroup
-	for _, s := range running {
-		s := s
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			composeArgs := []string{"logs"}
-			if logsFlags.follow {
-				composeArgs = append(composeArgs, "-f")
-			}
-			if logsFlags.tail != "" {
-				composeArgs = append(composeArgs, "--tail", logsFlags.tail)
-			}
-			if logsFlags.since != "" {
-				composeArgs = append(composeArgs, "--since", logsFlags.since)
-			}
-			// Prefix every line with the site name. ComposePrefixed shells out
-			// to `docker compose logs` and streams output through a writer that
-			// stamps each line.
-			if err := docker.ComposePrefixed(s.ComposeDir, s.Name, composeArgs...); err != nil {
-				ui.Warn("[%s] log stream ended: %v", s.Name, err)
-			}
-		}()
+	rows := make([][]string, 0, len(containers))
+	for _, c := range containers {
+		rows = append(rows, []string{c.Service, c.Name, psStatusColor(c), c.Ports})
 	}
-	wg.Wait()
+	ui.PrintTable([]string{"SERVICE", "CONTAINER", "STATUS", "PORTS"}, rows)
 	return nil
 }
+
+// psStatusColor tints a container's status text by its state: green when
+// running, yellow while it's coming up or paused, red when it died.
+func psStatusColor(c docker.ComposeContainer) string {
+	switch c.State {
+	case "running":
+		return ui.SuccessText(c.Status)
+	case "restarting", "paused", "created":
+		return ui.WarnText(c.Status)
+	case "dead":
+		return ui.ErrorText(c.Status)
+	case "exited":
+		if strings.Contains(c.Status, "(0)") {
+			return ui.DimText(c.Status)
+		}
+		return ui.ErrorText(c.Status)
+	default:
+		return c.Status
+	}
+}
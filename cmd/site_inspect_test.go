@@ -1,14 +1,25 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/stubbedev/srv/internal/constants"
 	"github.com/stubbedev/srv/internal/docker"
 	"github.com/stubbedev/srv/internal/site"
+	"github.com/stubbedev/srv/internal/traefik"
+	"github.com/stubbedev/srv/internal/ui"
 )
 
 func TestRunListEmpty(t *testing.T) {
@@ -105,6 +116,23 @@ func TestGetSSLStatusLocalMissing(t *testing.T) {
 	}
 }
 
+func TestGetSSLStatusEntryPoints(t *testing.T) {
+	httpOnly := site.Site{EntryPoints: []string{"web"}}
+	if got := stripAnsiCmd(getSSLStatus(httpOnly)); got != "http" {
+		t.Errorf("http-only: got %q, want http", got)
+	}
+	if got := httpOnly.SSLStatus(); got != "http" {
+		t.Errorf("http-only plain: got %q, want http", got)
+	}
+	both := site.Site{EntryPoints: []string{"websecure", "web"}}
+	if got := stripAnsiCmd(getSSLStatus(both)); got != "auto+http" {
+		t.Errorf("no-redirect: got %q, want auto+http", got)
+	}
+	if got := both.SSLStatus(); got != "auto+http" {
+		t.Errorf("no-redirect plain: got %q, want auto+http", got)
+	}
+}
+
 func TestRunLogsDockerDown(t *testing.T) {
 	setupSrvRoot(t)
 	t.Cleanup(docker.SwapNewClientErr(errors.New("offline")))
@@ -115,7 +143,7 @@ func TestRunLogsDockerDown(t *testing.T) {
 
 func TestRunLogsAllEmpty(t *testing.T) {
 	setupSrvRoot(t)
-	if err := runLogsAll(); err != nil {
+	if err := runLogsMerged(nil); err != nil {
 		t.Errorf("err: %v", err)
 	}
 }
@@ -140,13 +168,64 @@ func TestRunLogsHappy(t *testing.T) {
 	}
 }
 
+func TestRunPS(t *testing.T) {
+	root := setupSrvRoot(t)
+	projectDir := filepath.Join(root, "p")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestSite(t, "blog", site.SiteMetadata{
+		Type:        site.SiteTypeStatic,
+		Domains:     []string{"blog.local"},
+		ProjectPath: projectDir,
+		Port:        80,
+		NetworkName: "n",
+	})
+	t.Cleanup(docker.SwapNewClientOK())
+	var dir, args string
+	t.Cleanup(docker.SwapComposePSJSON(func(d string, a ...string) ([]byte, error) {
+		dir, args = d, strings.Join(a, " ")
+		return []byte(`{"Name":"srv_static_blog","Service":"nginx","State":"running","Status":"Up 1 minute"}`), nil
+	}))
+	psFlags.all = true
+	t.Cleanup(func() { psFlags.all = false })
+
+	if err := runPS(nil, []string{"blog"}); err != nil {
+		t.Fatal(err)
+	}
+	cfg := mustLoadConfig(t)
+	if dir != site.SiteConfigDir(cfg, "blog") {
+		t.Errorf("compose dir = %q, want the generated static config dir", dir)
+	}
+	if args != "ps --format json --all" {
+		t.Errorf("args = %q", args)
+	}
+}
+
+func TestPSStatusColor(t *testing.T) {
+	cases := []struct {
+		c    docker.ComposeContainer
+		want string
+	}{
+		{docker.ComposeContainer{State: "running", Status: "Up 1 minute"}, ui.SuccessText("Up 1 minute")},
+		{docker.ComposeContainer{State: "exited", Status: "Exited (0) 1 hour ago"}, ui.DimText("Exited (0) 1 hour ago")},
+		{docker.ComposeContainer{State: "exited", Status: "Exited (137) 1 hour ago"}, ui.ErrorText("Exited (137) 1 hour ago")},
+		{docker.ComposeContainer{State: "restarting", Status: "Restarting (1)"}, ui.WarnText("Restarting (1)")},
+	}
+	for _, c := range cases {
+		if got := psStatusColor(c.c); got != c.want {
+			t.Errorf("psStatusColor(%+v) = %q, want %q", c.c, got, c.want)
+		}
+	}
+}
+
 func TestSetupColoredHelp(t *testing.T) {
 	setupColoredHelp()
 }
 
 func TestShowCertInfoNoCerts(t *testing.T) {
 	setupSrvRoot(t)
-	showCertInfo("missing.local")
+	showCertInfo("missing.local", false)
 }
 
 func TestRunInfoBroken(t *testing.T) {
@@ -183,6 +262,30 @@ func TestRunInfoDockerfile(t *testing.T) {
 	}
 }
 
+func TestRunInfoResourcesStopped(t *testing.T) {
+	root := setupSrvRoot(t)
+	projectDir := filepath.Join(root, "p")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestSite(t, "blog", site.SiteMetadata{
+		Type:        site.SiteTypeStatic,
+		Domains:     []string{"blog.local"},
+		ProjectPath: projectDir,
+		Port:        80,
+		NetworkName: "n",
+	})
+	infoFlags.resources = true
+	t.Cleanup(func() { infoFlags.resources = false })
+	t.Cleanup(docker.SwapContainerStatsOutput(func(context.Context, string) ([]byte, error) {
+		t.Error("docker stats should not run for a stopped site")
+		return nil, nil
+	}))
+	if err := runInfo(nil, []string{"blog"}); err != nil {
+		t.Errorf("err: %v", err)
+	}
+}
+
 // stripAnsiCmd is a tiny ANSI stripper for cmd tests (cmd pkg has no public one).
 func stripAnsiCmd(s string) string {
 	var b strings.Builder
@@ -246,3 +349,389 @@ func TestGetSiteTypeLabel(t *testing.T) {
 		}
 	}
 }
+
+func TestParseListFilters(t *testing.T) {
+	cases := []struct {
+		in      []string
+		want    []listFilter
+		wantErr string
+	}{
+		{nil, []listFilter{}, ""},
+		{[]string{"status=running"}, []listFilter{{"status", "running"}}, ""},
+		{[]string{"Type=STATIC", "domain=Blog"}, []listFilter{{"type", "static"}, {"domain", "blog"}}, ""},
+		{[]string{"ssl=expired"}, []listFilter{{"ssl", "expired"}}, ""},
+		{[]string{"status"}, nil, "expected KEY=VALUE"},
+		{[]string{"status="}, nil, "expected KEY=VALUE"},
+		{[]string{"owner=me"}, nil, `invalid --filter key "owner"`},
+		{[]string{"status=paused"}, nil, "expected one of: running, stopped, broken, partial"},
+		{[]string{"ssl=valid"}, nil, `invalid --filter ssl value "valid"`},
+	}
+	for _, c := range cases {
+		got, err := parseListFilters(c.in)
+		if c.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+				t.Errorf("parseListFilters(%v) err = %v, want %q", c.in, err, c.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseListFilters(%v): %v", c.in, err)
+			continue
+		}
+		if len(got) != len(c.want) {
+			t.Errorf("parseListFilters(%v) = %v, want %v", c.in, got, c.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Errorf("parseListFilters(%v)[%d] = %v, want %v", c.in, i, got[i], c.want[i])
+			}
+		}
+	}
+}
+
+// listTestSites is a fixed set of sites covering every filter dimension.
+func listTestSites() []site.Site {
+	return []site.Site{
+		{Name: "shop", Domains: []string{"shop.example.com"}, Type: site.SiteTypeCompose, Status: "running"},
+		{Name: "blog", Domains: []string{"blog.test", "www.blog.test"}, Type: site.SiteTypeStatic, IsLocal: true, Status: "stopped"},
+		{Name: "api", Domains: []string{"api.test"}, Type: site.SiteTypeDockerfile, IsLocal: true, Status: "partial (1/2)"},
+		{Name: "old", Domains: []string{"old.test"}, Type: site.SiteTypeStatic, IsLocal: true, IsBroken: true},
+	}
+}
+
+func siteNames(sites []site.Site) string {
+	names := make([]string, len(sites))
+	for i, s := range sites {
+		names[i] = s.Name
+	}
+	return strings.Join(names, ",")
+}
+
+func TestFilterSites(t *testing.T) {
+	setupSrvRoot(t) // no certs on disk: every local site's cert is missing
+	cases := []struct {
+		filters []string
+		want    string
+	}{
+		{nil, "shop,blog,api,old"},
+		{[]string{"status=running"}, "shop"},
+		{[]string{"status=stopped"}, "blog"},
+		{[]string{"status=partial"}, "api"},
+		{[]string{"status=broken"}, "old"},
+		{[]string{"type=static"}, "blog"},
+		{[]string{"type=compose"}, "shop"},
+		{[]string{"type=dockerfile"}, "api"},
+		{[]string{"ssl=production"}, "shop"},
+		{[]string{"ssl=local"}, "blog,api,old"},
+		{[]string{"ssl=missing"}, "blog,api"},
+		{[]string{"ssl=expired"}, ""},
+		{[]string{"domain=www."}, "blog"},
+		{[]string{"domain=.TEST"}, "blog,api,old"},
+		{[]string{"domain=.test", "status=partial"}, "api"},
+		{[]string{"ssl=local", "type=compose"}, ""},
+	}
+	for _, c := range cases {
+		filters, err := parseListFilters(c.filters)
+		if err != nil {
+			t.Fatalf("parseListFilters(%v): %v", c.filters, err)
+		}
+		if got := siteNames(filterSites(listTestSites(), filters)); got != c.want {
+			t.Errorf("filter %v = %q, want %q", c.filters, got, c.want)
+		}
+	}
+}
+
+func TestSortSites(t *testing.T) {
+	cases := []struct {
+		key  string
+		want string
+	}{
+		{"name", "api,blog,old,shop"},
+		{"domain", "api,blog,old,shop"},
+		{"status", "old,api,shop,blog"},
+		{"type", "old,shop,api,blog"}, // broken sites have no type
+	}
+	for _, c := range cases {
+		sites := listTestSites()
+		sortSites(sites, c.key)
+		if got := siteNames(sites); got != c.want {
+			t.Errorf("sortSites(%q) = %q, want %q", c.key, got, c.want)
+		}
+	}
+}
+
+func TestRunListFilterFlags(t *testing.T) {
+	setupSrvRoot(t)
+	t.Cleanup(func() { listFlags.filters, listFlags.sort = nil, "name" })
+
+	listFlags.filters = []string{"colour=red"}
+	if err := runList(nil, nil); err == nil || !strings.Contains(err.Error(), "invalid --filter key") {
+		t.Errorf("bad filter key: err = %v", err)
+	}
+	listFlags.filters = nil
+	listFlags.sort = "size"
+	if err := runList(nil, nil); err == nil || !strings.Contains(err.Error(), "invalid --sort") {
+		t.Errorf("bad sort key: err = %v", err)
+	}
+}
+
+func TestLogsFilterFlags(t *testing.T) {
+	t.Cleanup(func() {
+		logsFlags.filter, logsFlags.invert, logsFlags.access = "", false, false
+		logsFilter = nil
+	})
+	check := func() error { return logsCmd.Args(logsCmd, []string{"blog"}) }
+
+	logsFlags.filter = "ERROR|WARN"
+	if err := check(); err != nil || logsFilter == nil {
+		t.Fatalf("valid pattern: %v", err)
+	}
+	if !keepLogLine("web-1 | ERROR boom") || keepLogLine("web-1 | GET /") {
+		t.Error("--filter kept the wrong lines")
+	}
+	logsFlags.invert = true
+	if keepLogLine("web-1 | ERROR boom") || !keepLogLine("web-1 | GET /") {
+		t.Error("--invert kept the wrong lines")
+	}
+
+	logsFlags.filter = "ERROR("
+	if err := check(); err == nil || !strings.Contains(err.Error(), "invalid --filter") {
+		t.Errorf("err = %v, want invalid pattern", err)
+	}
+	logsFlags.filter = ""
+	if err := check(); err == nil || !strings.Contains(err.Error(), "--invert needs") {
+		t.Errorf("err = %v, want --invert without --filter rejected", err)
+	}
+	logsFlags.filter, logsFlags.invert, logsFlags.access = "x", false, true
+	if err := check(); err == nil {
+		t.Error("expected --filter with --access to be rejected")
+	}
+}
+
+func TestRunLogsFiltered(t *testing.T) {
+	root := setupSrvRoot(t)
+	writeTestSite(t, "blog", site.SiteMetadata{
+		Type:        site.SiteTypeStatic,
+		Domains:     []string{"blog.local"},
+		ProjectPath: root,
+		Port:        80,
+		NetworkName: "n",
+	})
+	t.Cleanup(docker.SwapNewClientOK())
+	var args string
+	t.Cleanup(docker.SwapComposeStreamExec(func(_ string, w io.Writer, a ...string) error {
+		args = strings.Join(a, " ")
+		_, err := io.WriteString(w, "nginx-1 | GET /\n")
+		return err
+	}))
+	logsFlags.filter, logsFlags.tail = "GET", "10"
+	t.Cleanup(func() {
+		logsFlags.filter, logsFlags.tail = "", ""
+		logsFilter = nil
+	})
+	if err := logsCmd.Args(logsCmd, []string{"blog"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := runLogs(logsCmd, []string{"blog"}); err != nil {
+		t.Fatal(err)
+	}
+	if args != "logs --tail 10" {
+		t.Errorf("compose args = %q, want the --tail passed through", args)
+	}
+}
+
+func TestRunLogsLastNStarts(t *testing.T) {
+	root := setupSrvRoot(t)
+	writeTestSite(t, "blog", site.SiteMetadata{
+		Type:        site.SiteTypeStatic,
+		Domains:     []string{"blog.local"},
+		ProjectPath: root,
+		Port:        80,
+		NetworkName: "n",
+	})
+	t.Cleanup(docker.SwapNewClientOK())
+	now := time.Now()
+	t.Cleanup(docker.SwapContainerStartEventsOutput(func(_ context.Context, name string, _, _ time.Time) ([]byte, error) {
+		if !strings.HasPrefix(name, constants.StaticContainerPrefix) {
+			t.Errorf("events container = %q, want the static container", name)
+		}
+		var out string
+		for _, ago := range []time.Duration{2 * time.Hour, 10 * time.Minute, time.Hour} {
+			out += fmt.Sprintf("%d\n", now.Add(-ago).UnixNano())
+		}
+		return []byte(out), nil
+	}))
+	var args []string
+	t.Cleanup(docker.SwapComposeExec(func(_ string, _ bool, a ...string) error {
+		args = a
+		return nil
+	}))
+	saved := logsFlags
+	t.Cleanup(func() { logsFlags = saved })
+
+	logsFlags.lastNStarts = 2
+	if err := runLogs(logsCmd, []string{"blog"}); err != nil {
+		t.Fatal(err)
+	}
+	// The second most recent start was an hour ago.
+	if len(args) != 3 || args[1] != "--since" {
+		t.Fatalf("compose args = %q", args)
+	}
+	if d, err := time.ParseDuration(args[2]); err != nil || d < time.Hour || d > time.Hour+5*time.Second {
+		t.Errorf("--since = %q, want just over 1h", args[2])
+	}
+
+	logsFlags.lastNStarts = 4
+	if err := runLogs(logsCmd, []string{"blog"}); err == nil || !strings.Contains(err.Error(), "started 3 time(s)") {
+		t.Errorf("err = %v, want too few starts reported", err)
+	}
+
+	logsFlags.lastNStarts, logsFlags.access = 0, true
+	logsFlags.sinceStart = true
+	if err := logsCmd.Args(logsCmd, []string{"blog"}); err == nil {
+		t.Error("expected --since-start with --access to be rejected")
+	}
+}
+
+// runJSONCommand runs srv with args through the root command and decodes its
+// stdout into v.
+func runJSONCommand(t *testing.T, v any, args ...string) {
+	t.Helper()
+	stdout, _ := executeRoot(t, args...)
+	if err := json.Unmarshal([]byte(stdout), v); err != nil {
+		t.Fatalf("srv %s printed invalid JSON: %v\n%s", strings.Join(args, " "), err, stdout)
+	}
+}
+
+func TestListAndInfoJSON(t *testing.T) {
+	root := setupSrvRoot(t)
+	projectDir := filepath.Join(root, "p")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestSite(t, "blog", site.SiteMetadata{
+		Type:        site.SiteTypeStatic,
+		Domains:     []string{"blog.test", "www.blog.test"},
+		ProjectPath: projectDir,
+		Port:        80,
+		IsLocal:     true,
+		NetworkName: "n",
+	})
+
+	var list []site.SiteView
+	runJSONCommand(t, &list, "list", "--json")
+	if len(list) != 1 {
+		t.Fatalf("list = %+v, want one site", list)
+	}
+	got := list[0]
+	if got.Name != "blog" || got.Type != "static" || got.Target != projectDir || !got.Local {
+		t.Errorf("list[0] = %+v", got)
+	}
+	if len(got.Domains) != 2 || got.URL != "https://blog.test" {
+		t.Errorf("domains = %v, url = %q", got.Domains, got.URL)
+	}
+	if got.SSL != "missing" || got.CertDaysLeft != nil {
+		t.Errorf("ssl = %q, certDaysLeft = %v; want missing, none", got.SSL, got.CertDaysLeft)
+	}
+
+	var info site.SiteView
+	runJSONCommand(t, &info, "info", "blog", "--json")
+	if info.Name != got.Name || info.URL != got.URL || info.Port != 80 {
+		t.Errorf("info = %+v, want it to match %+v", info, got)
+	}
+}
+
+func TestListParked(t *testing.T) {
+	root := setupSrvRoot(t)
+	t.Cleanup(func() { listFlags.parked, listFlags.registeredOnly = false, false })
+	parkedDir := filepath.Join(root, "code")
+	for _, f := range []string{"blog/index.html", "api/compose.yml"} {
+		path := filepath.Join(parkedDir, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTestSite(t, "blog", site.SiteMetadata{
+		Type:        site.SiteTypeStatic,
+		Domains:     []string{"blog.test"},
+		ProjectPath: filepath.Join(parkedDir, "blog"),
+		Port:        80,
+		NetworkName: "n",
+	})
+	if err := mustLoadConfig(t).SetParkedPaths([]string{parkedDir}); err != nil {
+		t.Fatal(err)
+	}
+
+	var list []site.SiteView
+	runJSONCommand(t, &list, "list", "--parked", "--json")
+	if len(list) != 2 || list[0].Name != "api" || list[1].Name != "blog" {
+		t.Fatalf("list = %+v, want api (parked) and blog", list)
+	}
+	if api := list[0]; api.Status != constants.StatusParked || api.Type != "compose" || api.SSL != "" || api.URL != "" {
+		t.Errorf("parked project = %+v", api)
+	}
+
+	var stdout bytes.Buffer
+	t.Cleanup(ui.SwapStdout(&stdout))
+	listFlags.parked, listFlags.registeredOnly = false, true
+	if err := runList(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(stdout.String(), "api") {
+		t.Errorf("--registered-only listed a parked project:\n%s", stdout.String())
+	}
+}
+
+func TestListOutputCSV(t *testing.T) {
+	root := setupSrvRoot(t)
+	projectDir := filepath.Join(root, "my,blog")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestSite(t, "blog", site.SiteMetadata{
+		Type:        site.SiteTypeStatic,
+		Domains:     []string{"blog.local"},
+		ProjectPath: projectDir,
+		Port:        80,
+		IsLocal:     true,
+		NetworkName: "n",
+	})
+	stdout, _ := executeRoot(t, "list", "--output", "csv")
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 2 || lines[0] != "NAME,DOMAIN,TARGET,TYPE,SSL,HEALTH,STATUS" {
+		t.Fatalf("csv output:\n%s", stdout)
+	}
+	if !strings.HasPrefix(lines[1], "blog,blog.local,") || !strings.Contains(lines[1], `"`+projectDir+`"`) {
+		t.Errorf("row = %q, want the comma-bearing path quoted", lines[1])
+	}
+	if strings.Contains(stdout, "\x1b[") {
+		t.Errorf("csv output contains ANSI codes: %q", stdout)
+	}
+}
+
+func TestSiteHealth(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"serverStatus":{"http://srv-api-web:80":"DOWN"}}`))
+	}))
+	defer srv.Close()
+	orig := traefik.APIURL
+	traefik.APIURL = srv.URL + "/api"
+	defer func() { traefik.APIURL = orig }()
+
+	running := site.Site{Name: "api", Status: constants.StatusRunning, HealthCheckPath: "/health"}
+	if got := siteHealth(running); !strings.Contains(got, traefik.HealthUnhealthy) {
+		t.Errorf("running site with a health check = %q, want unhealthy", got)
+	}
+	stopped := running
+	stopped.Status = constants.StatusStopped
+	if got := siteHealth(stopped); !strings.Contains(got, "-") {
+		t.Errorf("stopped site = %q, want -", got)
+	}
+	if got := siteHealth(site.Site{Name: "web", Status: constants.StatusRunning}); !strings.Contains(got, "-") {
+		t.Errorf("site without a health check = %q, want -", got)
+	}
+}
@@ -1,11 +1,13 @@
 // Package cmd — site_lifecycle.go implements the lifecycle commands
-// (`srv start`, `srv stop`, `srv restart`) and the shared
-// runBatchSiteOperation helper used by them and by `srv install`.
+// (`srv start`, `srv stop`, `srv restart`, `srv rebuild`, `srv pull`) and
+// the shared runBatchSiteOperation helper used by them and by `srv install`.
 package cmd
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -25,8 +27,11 @@ import (
 // =============================================================================
 
 var startFlags struct {
-	all   bool
-	build bool
+	all         bool
+	tag         string
+	build       bool
+	profile     string
+	enableFirst bool
 }
 
 var startCmd = &cobra.Command{
@@ -34,12 +39,35 @@ var startCmd = &cobra.Command{
 	Short: "Start a site",
 	Long: `Start a site's containers.
 
-Use --all to start all registered sites in parallel.`,
+Use --all to start all registered sites in parallel, or --tag to start the
+sites carrying a tag (see 'srv tag'). Sites with dependencies (see 'srv
+dependency') start after the sites they depend on are running; starting a
+single site starts its stopped dependencies first.
+
+--profile starts a compose site under another Docker Compose profile this
+once; 'srv profile set' changes it for good.
+
+A site taken out of routing with 'srv disable' isn't started; --enable-first
+enables it first. --all and --tag skip disabled sites.
+
+Examples:
+  srv start mysite
+  srv start mysite --profile debug
+  srv start mysite --enable-first
+  srv start --all
+  srv start --tag production`,
 	Args: func(cmd *cobra.Command, args []string) error {
-		if len(args) == 0 && !startFlags.all {
+		batch := startFlags.all || startFlags.tag != ""
+		if len(args) == 0 && !batch {
 			_ = cmd.Help()
 			return ui.UsageError("srv start SITE", "a site name is required (or use --all to start every site)")
 		}
+		if len(args) > 0 && batch {
+			return ui.UsageError("srv start SITE", "pass either a site name or --all/--tag, not both")
+		}
+		if batch && cmd.Flags().Changed("profile") {
+			return ui.UsageError("srv start SITE --profile PROFILE", "--profile applies to a single site, not --all or --tag")
+		}
 		return nil
 	},
 	RunE: runStart,
@@ -50,7 +78,19 @@ Use --all to start all registered sites in parallel.`,
 
 func init() {
 	startCmd.Flags().BoolVarP(&startFlags.all, "all", "a", false, "Start all sites")
+	startCmd.Flags().StringVar(&startFlags.tag, "tag", "", "Start the sites carrying this tag")
+	startCmd.MarkFlagsMutuallyExclusive("all", "tag")
+	_ = startCmd.RegisterFlagCompletionFunc("tag", completeTags)
 	startCmd.Flags().BoolVar(&startFlags.build, "build", false, "Rebuild images before starting")
+	startCmd.Flags().StringVar(&startFlags.profile, "profile", "", "Docker Compose profile to start a compose site under, instead of its stored one")
+	startCmd.Flags().BoolVar(&startFlags.enableFirst, "enable-first", false, "Enable a disabled site (see 'srv enable') before starting it")
+	_ = startCmd.RegisterFlagCompletionFunc("profile", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		profiles, _ := site.ComposeProfiles(args[0])
+		return profiles, cobra.ShellCompDirectiveNoFileComp
+	})
 	startCmd.GroupID = GroupSites
 	RootCmd.AddCommand(startCmd)
 }
@@ -79,6 +119,9 @@ func runStart(cmd *cobra.Command, args []string) error {
 	if startFlags.all {
 		return startAllSites()
 	}
+	if startFlags.tag != "" {
+		return startTaggedSites(startFlags.tag)
+	}
 
 	s, err := site.GetByName(args[0])
 	if err != nil {
@@ -88,9 +131,36 @@ func runStart(cmd *cobra.Command, args []string) error {
 	if s.IsBroken {
 		return fmt.Errorf("site '%s' is broken (target directory missing)", s.Name)
 	}
+	if s.Disabled {
+		if !startFlags.enableFirst {
+			return fmt.Errorf("site '%s' is disabled; run 'srv enable %s' or pass --enable-first", s.Name, s.Name)
+		}
+		warnings, err := site.EnableSite(s.Name)
+		if err != nil {
+			return err
+		}
+		for _, w := range warnings {
+			ui.Warn("%s", w)
+		}
+		ui.Dim("Enabled %s", s.Name)
+		s.Disabled = false
+	}
+	if err := startDependencies(s.Name); err != nil {
+		return err
+	}
+	if startFlags.profile != "" {
+		warnings, err := site.CheckProfile(s.Name, startFlags.profile)
+		if err != nil {
+			return err
+		}
+		for _, w := range warnings {
+			ui.Warn("%s", w)
+		}
+		s.Profile = startFlags.profile
+	}
 
 	// Renew local SSL cert if needed
-	if s.IsLocal && len(s.Domains) > 0 {
+	if s.IsLocal && !s.CustomCert && len(s.Domains) > 0 {
 		renewLocalCertIfNeeded(s.Name, s.Domains, s.Wildcard)
 	}
 
@@ -98,20 +168,25 @@ func runStart(cmd *cobra.Command, args []string) error {
 	// metadata edits since the last write are reflected in docker-compose.yml
 	// and the per-site Dockerfile. Reload short-circuits when the metadata
 	// hash matches the last apply so this stays cheap on hot paths.
-	if _, err := site.Reload(s.Name); err != nil {
+	res, err := site.Reload(s.Name)
+	if err != nil {
 		return fmt.Errorf("reload site before start: %w", err)
 	}
+	for _, w := range res.Warnings {
+		ui.Warn("%s", w)
+	}
+
+	if err := site.RunPreStartHooks(s.Name); err != nil {
+		return err
+	}
 
 	ui.Info("Starting %s...", s.Name)
-	// Use ComposeDir which is set correctly for both static and compose sites
-	var startErr error
-	if startFlags.build {
-		startErr = docker.ComposeUpBuildWithProfile(s.ComposeDir, s.Profile)
-	} else {
-		startErr = docker.ComposeUpWithProfile(s.ComposeDir, s.Profile)
+	// ComposeUp layers the site's `srv env` overrides over its compose file
+	if err := site.ComposeUp(s, startFlags.build); err != nil {
+		return fmt.Errorf("failed to start site: %w", err)
 	}
-	if startErr != nil {
-		return fmt.Errorf("failed to start site: %w", startErr)
+	if err := site.RestartStaticIfReloaded(s, res); err != nil {
+		return err
 	}
 
 	// For compose sites, connect service to traefik network after starting
@@ -130,6 +205,10 @@ func runStart(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if err := site.RunPostStartHooks(s.Name); err != nil {
+		ui.Warn("%v", err)
+	}
+
 	ui.Success("Site '%s' started", s.Name)
 	if d := s.Domain(); d != "" {
 		ui.Info("https://%s", d)
@@ -148,6 +227,74 @@ func startAllSites() error {
 		ui.Dim("No sites registered")
 		return nil
 	}
+	return startSiteBatch(sites)
+}
+
+// withoutDisabled drops the disabled sites from a batch start, noting each.
+func withoutDisabled(sites []site.Site) []site.Site {
+	return slices.DeleteFunc(sites, func(s site.Site) bool {
+		if s.Disabled {
+			ui.Dim("Skipping disabled site: %s", s.Name)
+		}
+		return s.Disabled
+	})
+}
+
+// startDependencies starts the stopped sites name depends on, directly or
+// transitively, and waits for them to report running.
+func startDependencies(name string) error {
+	deps, err := site.DependencyClosure(name)
+	if err != nil || len(deps) == 0 {
+		return err
+	}
+	sites, err := site.List()
+	if err != nil {
+		return err
+	}
+	var stopped []site.Site
+	for _, dep := range deps {
+		i := slices.IndexFunc(sites, func(s site.Site) bool { return s.Name == dep })
+		switch {
+		case i < 0:
+			ui.Warn("Dependency '%s' of %s is not registered", dep, name)
+		case sites[i].Disabled:
+			ui.Warn("Dependency '%s' of %s is disabled", dep, name)
+		case sites[i].Status != constants.StatusRunning:
+			stopped = append(stopped, sites[i])
+		}
+	}
+	if len(stopped) == 0 {
+		return nil
+	}
+	if err := startSiteBatch(stopped); err != nil {
+		return fmt.Errorf("start dependencies of %s: %w", name, err)
+	}
+	for _, dep := range stopped {
+		if err := site.WaitRunning(dep, constants.DependencyStartTimeout); err != nil {
+			ui.Warn("%v; starting %s anyway", err, name)
+		}
+	}
+	return nil
+}
+
+// startTaggedSites starts the sites carrying tag in parallel.
+func startTaggedSites(tag string) error {
+	sites, err := site.List()
+	if err != nil {
+		return err
+	}
+	sites = sitesWithTag(sites, tag)
+	if len(sites) == 0 {
+		return fmt.Errorf("no sites are tagged %q (see 'srv tag list')", tag)
+	}
+	return startSiteBatch(sites)
+}
+
+// startSiteBatch starts sites in parallel, skipping disabled ones.
+func startSiteBatch(sites []site.Site) error {
+	if sites = withoutDisabled(sites); len(sites) == 0 {
+		return nil
+	}
 
 	cfg, err := config.Load()
 	if err != nil {
@@ -156,21 +303,38 @@ func startAllSites() error {
 
 	// Renew any expiring local certs before starting
 	for _, s := range sites {
-		if s.IsLocal && len(s.Domains) > 0 && !s.IsBroken {
+		if s.IsLocal && !s.CustomCert && len(s.Domains) > 0 && !s.IsBroken {
 			renewLocalCertIfNeeded(s.Name, s.Domains, s.Wildcard)
 		}
 	}
 
+	// Clear endpoints left behind by containers removed while attached, so
+	// the connects below don't fail with "endpoint with name ... already
+	// exists".
+	if removed, err := docker.RemoveStaleEndpoints(cfg.NetworkName); err != nil {
+		ui.Warn("Failed to clean up stale network endpoints: %v", err)
+	} else if len(removed) > 0 {
+		ui.Dim("Removed stale network endpoints: %s", strings.Join(removed, ", "))
+	}
+
 	ui.Info("Starting %d site(s)...", len(sites))
-	if err := runBatchSiteOperation(sites, "start", func(s *site.Site) error {
+	if err := runOrderedSiteOperation(sites, "start", false, func(s *site.Site) error {
 		// Reload per-site artifacts before compose up so label/Dockerfile
 		// edits land. Cheap when nothing changed (metadata-hash short-circuit).
 		if _, err := site.Reload(s.Name); err != nil {
 			return fmt.Errorf("reload: %w", err)
 		}
+		if err := site.RunPreStartHooks(s.Name); err != nil {
+			return err
+		}
 		// Use ComposeDir for docker operations with profile if set
 		// Include --remove-orphans to clean up stale containers that may reference non-existent networks
-		if err := docker.ComposeQuietWithProfile(s.ComposeDir, s.Profile, "up", "-d", "--remove-orphans"); err != nil {
+		files, cleanup, err := site.EnvOverlay(s)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		if err := docker.ComposeQuietWithProfile(s.ComposeDir, s.Profile, append(docker.ComposeFileArgs(files), "up", "-d", "--remove-orphans")...); err != nil {
 			return err
 		}
 		// Connect compose sites to traefik network
@@ -182,11 +346,14 @@ func startAllSites() error {
 				}
 			}
 		}
+		if err := site.RunPostStartHooks(s.Name); err != nil {
+			ui.SafeWarn("%s: %v", s.Name, err)
+		}
 		return nil
 	}); err != nil {
 		return err
 	}
-	ui.Success("All sites started")
+	ui.Success("Started %d site(s)", len(sites))
 	return nil
 }
 
@@ -195,7 +362,8 @@ func startAllSites() error {
 // =============================================================================
 
 var stopFlags struct {
-	all bool
+	all    bool
+	dryRun bool
 }
 
 var stopCmd = &cobra.Command{
@@ -203,7 +371,9 @@ var stopCmd = &cobra.Command{
 	Short: "Stop a site",
 	Long: `Stop a site's containers.
 
-Use --all to stop all registered sites in parallel.`,
+Use --all to stop all registered sites in parallel, sites that others depend
+on (see 'srv dependency') last. Use --dry-run to list the containers that would
+be stopped without stopping them.`,
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) == 0 && !stopFlags.all {
 			_ = cmd.Help()
@@ -219,17 +389,25 @@ Use --all to stop all registered sites in parallel.`,
 
 func init() {
 	stopCmd.Flags().BoolVarP(&stopFlags.all, "all", "a", false, "Stop all sites")
+	stopCmd.Flags().BoolVar(&stopFlags.dryRun, "dry-run", false, "Show which containers would be stopped without stopping them")
 	stopCmd.GroupID = GroupSites
 	RootCmd.AddCommand(stopCmd)
 }
 
 func runStop(cmd *cobra.Command, args []string) error {
+	if stopFlags.dryRun {
+		if stopFlags.all {
+			return stopAllSites(previewContainersExec, true)
+		}
+		return previewSite(args[0], "stop")
+	}
+
 	if err := docker.EnsureRunning(); err != nil {
 		return err
 	}
 
 	if stopFlags.all {
-		return stopAllSites()
+		return stopAllSites(stopSiteExec, false)
 	}
 
 	s, err := site.GetByName(args[0])
@@ -242,7 +420,7 @@ func runStop(cmd *cobra.Command, args []string) error {
 	}
 
 	ui.Info("Stopping %s...", s.Name)
-	if err := docker.ComposeStop(s.ComposeDir); err != nil {
+	if err := docker.ComposeStop(s.ComposeDir, s.ComposeFiles()...); err != nil {
 		return fmt.Errorf("failed to stop site: %w", err)
 	}
 
@@ -250,8 +428,9 @@ func runStop(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-// stopAllSites stops all registered sites in parallel
-func stopAllSites() error {
+// stopAllSites runs execFn against every registered site: in parallel for a
+// real stop, sequentially (so the listing reads in order) for a dry run.
+func stopAllSites(execFn siteExecFn, dryRun bool) error {
 	sites, err := site.List()
 	if err != nil {
 		return err
@@ -262,23 +441,32 @@ func stopAllSites() error {
 		return nil
 	}
 
+	if dryRun {
+		ui.Info("Dry run: stopping %d site(s) would stop:", len(sites))
+		return previewBatchSiteOperation(sites, execFn)
+	}
+
 	ui.Info("Stopping %d site(s)...", len(sites))
-	if err := runBatchSiteOperation(sites, "stop", func(s *site.Site) error {
-		return docker.ComposeStop(s.ComposeDir)
-	}); err != nil {
+	if err := runOrderedSiteOperation(sites, "stop", true, execFn); err != nil {
 		return err
 	}
 	ui.Success("All sites stopped")
 	return nil
 }
 
+// stopSiteExec stops one site's containers.
+func stopSiteExec(s *site.Site) error {
+	return docker.ComposeStop(s.ComposeDir, s.ComposeFiles()...)
+}
+
 // =============================================================================
 // restart command
 // =============================================================================
 
 var restartFlags struct {
-	all   bool
-	build bool
+	all    bool
+	build  bool
+	dryRun bool
 }
 
 var restartCmd = &cobra.Command{
@@ -286,7 +474,8 @@ var restartCmd = &cobra.Command{
 	Short: "Restart a site",
 	Long: `Restart a site's containers.
 
-Use --all to restart all registered sites in parallel.`,
+Use --all to restart all registered sites in parallel. Use --dry-run to list
+the containers that would be restarted without restarting them.`,
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) == 0 && !restartFlags.all {
 			_ = cmd.Help()
@@ -303,11 +492,19 @@ Use --all to restart all registered sites in parallel.`,
 func init() {
 	restartCmd.Flags().BoolVarP(&restartFlags.all, "all", "a", false, "Restart all sites")
 	restartCmd.Flags().BoolVar(&restartFlags.build, "build", false, "Rebuild images before restarting")
+	restartCmd.Flags().BoolVar(&restartFlags.dryRun, "dry-run", false, "Show which containers would be restarted without restarting them")
 	restartCmd.GroupID = GroupSites
 	RootCmd.AddCommand(restartCmd)
 }
 
 func runRestart(cmd *cobra.Command, args []string) error {
+	if restartFlags.dryRun {
+		if restartFlags.all {
+			return restartAllSites(previewContainersExec, true)
+		}
+		return previewSite(args[0], "restart")
+	}
+
 	if err := docker.EnsureRunning(); err != nil {
 		return err
 	}
@@ -321,7 +518,7 @@ func runRestart(cmd *cobra.Command, args []string) error {
 	}
 
 	if restartFlags.all {
-		return restartAllSites()
+		return restartAllSites(restartSiteExec, false)
 	}
 
 	s, err := site.GetByName(args[0])
@@ -339,11 +536,11 @@ func runRestart(cmd *cobra.Command, args []string) error {
 
 	ui.Info("Restarting %s...", s.Name)
 	if restartFlags.build {
-		if err := docker.ComposeUpBuildWithProfile(s.ComposeDir, s.Profile); err != nil {
+		if err := site.ComposeUp(s, true); err != nil {
 			return fmt.Errorf("failed to rebuild and restart site: %w", err)
 		}
 	} else {
-		if err := docker.ComposeRestart(s.ComposeDir); err != nil {
+		if err := docker.ComposeRestart(s.ComposeDir, s.ComposeFiles()...); err != nil {
 			return fmt.Errorf("failed to restart site: %w", err)
 		}
 	}
@@ -352,8 +549,9 @@ func runRestart(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-// restartAllSites restarts all registered sites in parallel
-func restartAllSites() error {
+// restartAllSites runs execFn against every registered site: in parallel for
+// a real restart, sequentially for a dry run.
+func restartAllSites(execFn siteExecFn, dryRun bool) error {
 	sites, err := site.List()
 	if err != nil {
 		return err
@@ -364,24 +562,331 @@ func restartAllSites() error {
 		return nil
 	}
 
+	if dryRun {
+		ui.Info("Dry run: restarting %d site(s) would restart:", len(sites))
+		return previewBatchSiteOperation(sites, execFn)
+	}
+
 	ui.Info("Restarting %d site(s)...", len(sites))
-	if err := runBatchSiteOperation(sites, "restart", func(s *site.Site) error {
-		return docker.ComposeRestart(s.ComposeDir)
-	}); err != nil {
+	if err := runBatchSiteOperation(sites, "restart", execFn); err != nil {
 		return err
 	}
 	ui.Success("All sites restarted")
 	return nil
 }
 
+// restartSiteExec restarts one site's containers.
+func restartSiteExec(s *site.Site) error {
+	return docker.ComposeRestart(s.ComposeDir, s.ComposeFiles()...)
+}
+
+// =============================================================================
+// rebuild command
+// =============================================================================
+
+var rebuildFlags struct {
+	service string
+}
+
+var rebuildCmd = &cobra.Command{
+	Use:   "rebuild SITE",
+	Short: "Rebuild a site's images and recreate its containers",
+	Long: `Take a site's containers down, rebuild its images and bring it back up
+(docker compose down, then up -d --build --remove-orphans). Use after
+editing a Dockerfile.
+
+--service NAME rebuilds only that service of a compose site; the rest of the
+project keeps running. Static sites have no image to build, so their
+container is just restarted.
+
+Examples:
+  srv rebuild myapp
+  srv rebuild myapp --service worker`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			_ = cmd.Help()
+			return ui.UsageError("srv rebuild SITE", "a site name is required")
+		}
+		if len(args) > 1 {
+			return ui.UsageError("srv rebuild SITE", "too many arguments — expected a single site name, got %d", len(args))
+		}
+		return nil
+	},
+	RunE: runRebuild,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return GetSiteNames(), cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+func init() {
+	rebuildCmd.Flags().StringVar(&rebuildFlags.service, "service", "", "Rebuild only this compose service")
+	rebuildCmd.GroupID = GroupSites
+	RootCmd.AddCommand(rebuildCmd)
+}
+
+func runRebuild(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	target := name
+	if rebuildFlags.service != "" {
+		target = fmt.Sprintf("%s (%s)", name, rebuildFlags.service)
+	}
+	spinner := ui.NewSpinner("Rebuilding %s...", target)
+	spinner.Start()
+	rebuilt, err := site.RebuildSite(name, rebuildFlags.service)
+	spinner.Stop()
+	if err != nil {
+		return err
+	}
+	if !rebuilt {
+		ui.Dim("Static sites have no image to build; restarted the container instead.")
+		ui.Success("Site '%s' restarted", name)
+		return nil
+	}
+	ui.Success("Site '%s' rebuilt", target)
+	return nil
+}
+
+// =============================================================================
+// pull command
+// =============================================================================
+
+var pullFlags struct {
+	all     bool
+	restart bool
+}
+
+var pullCmd = &cobra.Command{
+	Use:   "pull SITE",
+	Short: "Pull updated images for a site",
+	Long: `Pull the latest images for a site's services (docker compose pull), e.g.
+to refresh :latest tags. Static sites pull their nginx image; dockerfile
+sites build theirs locally, so there's nothing to pull (use 'srv rebuild').
+
+Running containers keep the old images until they're recreated: pass
+--restart to recreate them on the pulled images (docker compose up -d).
+
+Use --all to pull every registered site in parallel. The time of the last
+pull is shown by 'srv info'.
+
+Examples:
+  srv pull mysite
+  srv pull mysite --restart
+  srv pull --all`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if pullFlags.all {
+			return cobra.NoArgs(cmd, args)
+		}
+		if len(args) == 0 {
+			_ = cmd.Help()
+			return ui.UsageError("srv pull SITE", "a site name is required (or use --all to pull every site)")
+		}
+		if len(args) > 1 {
+			return ui.UsageError("srv pull SITE", "too many arguments — expected a single site name, got %d", len(args))
+		}
+		return nil
+	},
+	RunE: runPull,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 || pullFlags.all {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return GetSiteNames(), cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+func init() {
+	pullCmd.Flags().BoolVarP(&pullFlags.all, "all", "a", false, "Pull images for all sites")
+	pullCmd.Flags().BoolVar(&pullFlags.restart, "restart", false, "Recreate the site's containers on the pulled images")
+	pullCmd.GroupID = GroupSites
+	RootCmd.AddCommand(pullCmd)
+}
+
+func runPull(cmd *cobra.Command, args []string) error {
+	if err := docker.EnsureRunning(); err != nil {
+		return err
+	}
+	if pullFlags.all {
+		sites, err := site.List()
+		if err != nil {
+			return err
+		}
+		if len(sites) == 0 {
+			ui.Dim("No sites registered")
+			return nil
+		}
+		ui.Info("Pulling images for %d %s...", len(sites), plural(len(sites), "site", "sites"))
+		if err := runBatchSiteOperation(sites, "pull", pullSiteExec); err != nil {
+			return err
+		}
+		ui.Success("Pulled images for all sites")
+		return nil
+	}
+
+	ui.Info("Pulling images for %s...", args[0])
+	if err := site.PullSite(args[0], false); err != nil {
+		return err
+	}
+	if !pullFlags.restart {
+		ui.Success("Pulled images for %s", args[0])
+		ui.Dim("Run 'srv pull %s --restart' to recreate its containers on them", args[0])
+		return nil
+	}
+	ui.Info("Recreating %s...", args[0])
+	if err := site.StartSite(args[0], false); err != nil {
+		return err
+	}
+	ui.Success("Site '%s' recreated on the pulled images", args[0])
+	return nil
+}
+
+// pullSiteExec is the --all pull step: a quiet pull, followed by a start
+// (which recreates containers whose image changed) when --restart is set.
+func pullSiteExec(s *site.Site) error {
+	if err := site.PullSite(s.Name, true); err != nil {
+		return err
+	}
+	if pullFlags.restart {
+		return site.StartSite(s.Name, false)
+	}
+	return nil
+}
+
 // =============================================================================
 // Batch operations helper
 // =============================================================================
 
+// maxWorkers is how many sites batch operations handle at once: the
+// max-workers setting, or constants.MaxWorkers.
+func maxWorkers() int {
+	return cmp.Or(config.UserSettings().MaxWorkers, constants.MaxWorkers)
+}
+
+// siteExecFn performs one lifecycle step against a site. The batch commands
+// take it as a parameter so --dry-run can swap the docker-backed step for
+// one that only reports what it would do, and so tests can drive the batch
+// loops without a Docker daemon.
+type siteExecFn func(s *site.Site) error
+
+// previewContainersExec is the --dry-run siteExecFn: it prints the
+// containers a lifecycle step would act on and touches nothing.
+func previewContainersExec(s *site.Site) error {
+	containers := s.Containers()
+	if len(containers) == 0 {
+		ui.Print("  %s: %s", s.Name, ui.DimText("(no containers)"))
+		return nil
+	}
+	ui.Print("  %s: %s", s.Name, strings.Join(containers, ", "))
+	return nil
+}
+
+// previewSite is the single-site --dry-run path for `srv stop|restart`.
+func previewSite(name, opName string) error {
+	s, err := site.GetByName(name)
+	if err != nil {
+		return err
+	}
+	if s.IsBroken {
+		return fmt.Errorf("site '%s' is broken (target directory missing)", s.Name)
+	}
+	ui.Info("Dry run: %s %s would act on:", opName, s.Name)
+	if err := previewContainersExec(s); err != nil {
+		return err
+	}
+	ui.Dim("Nothing was changed (--dry-run)")
+	return nil
+}
+
+// previewBatchSiteOperation is the dry-run counterpart of
+// runBatchSiteOperation: it runs execFn sequentially so the listing is
+// stable, skipping broken sites the same way.
+func previewBatchSiteOperation(sites []site.Site, execFn siteExecFn) error {
+	for i := range sites {
+		if sites[i].IsBroken {
+			ui.Print("  %s: %s", sites[i].Name, ui.DimText("(broken, skipped)"))
+			continue
+		}
+		if err := execFn(&sites[i]); err != nil {
+			return err
+		}
+	}
+	ui.Dim("Nothing was changed (--dry-run)")
+	return nil
+}
+
 // runBatchSiteOperation runs an operation on multiple sites in parallel.
 // Each failure is printed inline as it happens; the returned error names the
 // failing sites so callers and tests can act on the set rather than just a count.
 func runBatchSiteOperation(sites []site.Site, opName string, op func(*site.Site) error) error {
+	return batchFailure(opName, batchSiteOperation(sites, opName, op))
+}
+
+// runOrderedSiteOperation is runBatchSiteOperation for sites with
+// dependencies (`srv dependency`): it runs one dependency level at a time,
+// each level in parallel. Starts go dependencies first, waiting for the sites
+// others depend on to report running, and skip sites whose dependencies
+// failed; stops (reverse) go dependents first.
+func runOrderedSiteOperation(sites []site.Site, opName string, reverse bool, op func(*site.Site) error) error {
+	levels, err := site.DependencyLevels(sites)
+	if err != nil {
+		return err
+	}
+	if len(levels) <= 1 {
+		return runBatchSiteOperation(sites, opName, op)
+	}
+	if reverse {
+		slices.Reverse(levels)
+	}
+	needed := map[string]bool{}
+	for _, s := range sites {
+		for _, dep := range s.DependsOn {
+			needed[dep] = true
+		}
+	}
+
+	var failed []string
+	for _, level := range levels {
+		run := make([]site.Site, 0, len(level))
+		for _, s := range level {
+			if i := slices.IndexFunc(s.DependsOn, func(d string) bool { return slices.Contains(failed, d) }); !reverse && i >= 0 {
+				ui.Warn("Skipping %s: dependency %s failed to %s", s.Name, s.DependsOn[i], opName)
+				failed = append(failed, s.Name)
+				continue
+			}
+			run = append(run, s)
+		}
+		levelFailed := batchSiteOperation(run, opName, op)
+		failed = append(failed, levelFailed...)
+		if reverse {
+			continue
+		}
+		for _, s := range run {
+			if !needed[s.Name] || s.IsBroken || slices.Contains(levelFailed, s.Name) {
+				continue
+			}
+			if err := site.WaitRunning(s, constants.DependencyStartTimeout); err != nil {
+				ui.Warn("%v; starting its dependents anyway", err)
+			}
+		}
+	}
+	return batchFailure(opName, failed)
+}
+
+// batchFailure is the error naming the sites a batch operation failed on, or
+// nil when none did.
+func batchFailure(opName string, failed []string) error {
+	if len(failed) == 0 {
+		return nil
+	}
+	sort.Strings(failed)
+	return fmt.Errorf("failed to %s: %s", opName, strings.Join(failed, ", "))
+}
+
+// batchSiteOperation runs op on sites in parallel with a worker pool,
+// skipping broken ones, and returns the names of the sites it failed on.
+func batchSiteOperation(sites []site.Site, opName string, op func(*site.Site) error) []string {
 	// Filter out broken sites
 	validSites := make([]site.Site, 0, len(sites))
 	for _, s := range sites {
@@ -397,11 +902,11 @@ func runBatchSiteOperation(sites []site.Site, opName string, op func(*site.Site)
 	}
 
 	// Run operations in parallel with a worker pool
-	workers := min(constants.MaxWorkers, len(validSites))
+	workers := min(maxWorkers(), len(validSites))
 
 	var wg sync.WaitGroup
 	var failMu sync.Mutex
-	failed := make([]string, 0)
+	var failed []string
 	siteChan := make(chan site.Site, len(validSites))
 
 	// Start workers
@@ -429,10 +934,5 @@ func runBatchSiteOperation(sites []site.Site, opName string, op func(*site.Site)
 
 	// Wait for all workers to complete
 	wg.Wait()
-
-	if len(failed) > 0 {
-		sort.Strings(failed)
-		return fmt.Errorf("failed to %s: %s", opName, strings.Join(failed, ", "))
-	}
-	return nil
+	return failed
 }
@@ -4,9 +4,12 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
 
+	"github.com/stubbedev/srv/internal/constants"
 	"github.com/stubbedev/srv/internal/docker"
 	"github.com/stubbedev/srv/internal/site"
 )
@@ -54,6 +57,77 @@ func TestRunBatchSiteOperationCollectsFailures(t *testing.T) {
 	}
 }
 
+func TestRunOrderedSiteOperation(t *testing.T) {
+	t.Cleanup(site.SwapStatus(func(site.Site) string { return constants.StatusRunning }))
+	sites := []site.Site{
+		{Name: "web", DependsOn: []string{"api"}},
+		{Name: "api", DependsOn: []string{"db"}},
+		{Name: "db"},
+		{Name: "docs"},
+	}
+	var mu sync.Mutex
+	var order []string
+	record := func(s *site.Site) error {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, s.Name)
+		return nil
+	}
+	if err := runOrderedSiteOperation(sites, "start", false, record); err != nil {
+		t.Fatal(err)
+	}
+	pos := func(name string) int { return slices.Index(order, name) }
+	if !(pos("db") < pos("api") && pos("api") < pos("web")) {
+		t.Errorf("start order = %v, want db before api before web", order)
+	}
+
+	order = nil
+	if err := runOrderedSiteOperation(sites, "stop", true, record); err != nil {
+		t.Fatal(err)
+	}
+	if !(pos("web") < pos("api") && pos("api") < pos("db")) {
+		t.Errorf("stop order = %v, want web before api before db", order)
+	}
+
+	// A failed dependency skips its dependents.
+	order = nil
+	err := runOrderedSiteOperation(sites, "start", false, func(s *site.Site) error {
+		if s.Name == "db" {
+			return errors.New("boom")
+		}
+		return record(s)
+	})
+	if err == nil || !strings.Contains(err.Error(), "api, db, web") {
+		t.Errorf("err = %v, want db and its dependents named", err)
+	}
+	if slices.Contains(order, "api") || slices.Contains(order, "web") {
+		t.Errorf("dependents of a failed site were started: %v", order)
+	}
+
+	// Cycle.
+	sites[2].DependsOn = []string{"web"}
+	if err := runOrderedSiteOperation(sites, "start", false, record); err == nil {
+		t.Error("expected a cycle error")
+	}
+}
+
+func TestDependencyCommands(t *testing.T) {
+	setupSrvRoot(t)
+	writeTestSite(t, "db", site.SiteMetadata{Type: site.SiteTypeCompose, Domains: []string{"db.test"}, ProjectPath: t.TempDir()})
+	writeTestSite(t, "api", site.SiteMetadata{Type: site.SiteTypeCompose, Domains: []string{"api.test"}, ProjectPath: t.TempDir()})
+	t.Cleanup(func() { dependencyFlags.dependsOn = nil })
+
+	executeRoot(t, "dependency", "add", "api", "--depends-on", "db")
+	if meta, _ := site.ReadSiteMetadata("api"); strings.Join(meta.DependsOn, ",") != "db" {
+		t.Errorf("DependsOn = %v", meta.DependsOn)
+	}
+	dependencyFlags.dependsOn = nil
+	executeRoot(t, "dependency", "remove", "api", "--depends-on", "db")
+	if meta, _ := site.ReadSiteMetadata("api"); len(meta.DependsOn) != 0 {
+		t.Errorf("DependsOn after remove = %v", meta.DependsOn)
+	}
+}
+
 func TestRunRemoveMissingSite(t *testing.T) {
 	setupSrvRoot(t)
 	if err := runRemove(nil, []string{"ghost"}); err == nil {
@@ -85,6 +159,66 @@ func TestRunRemoveCompleteFlow(t *testing.T) {
 	}
 }
 
+func TestRunRemoveDryRunKeepsSite(t *testing.T) {
+	root := setupSrvRoot(t)
+	projectDir := filepath.Join(root, "p")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestSite(t, "blog", site.SiteMetadata{
+		Type:        site.SiteTypeStatic,
+		Domains:     []string{"blog.test"},
+		ProjectPath: projectDir,
+		Port:        80,
+		IsLocal:     true,
+		NetworkName: "n",
+	})
+	removeFlags.dryRun = true
+	t.Cleanup(func() { removeFlags.dryRun = false })
+	// No compose stub: a dry run must never reach docker compose.
+	if err := runRemove(nil, []string{"blog"}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !site.HasSiteMetadata("blog") {
+		t.Error("dry run must not remove metadata")
+	}
+}
+
+func TestStopAllSitesDryRunUsesExecFn(t *testing.T) {
+	root := setupSrvRoot(t)
+	for _, name := range []string{"a", "b"} {
+		projectDir := filepath.Join(root, name)
+		if err := os.MkdirAll(projectDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		writeTestSite(t, name, site.SiteMetadata{
+			Type:        site.SiteTypeStatic,
+			Domains:     []string{name + ".test"},
+			ProjectPath: projectDir,
+			Port:        80,
+			NetworkName: "n",
+		})
+	}
+	var seen []string
+	record := func(s *site.Site) error {
+		seen = append(seen, s.Name)
+		return nil
+	}
+	if err := stopAllSites(record, true); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(seen, ",") != "a,b" {
+		t.Errorf("dry run should visit sites in order, got %v", seen)
+	}
+	seen = nil
+	if err := restartAllSites(record, false); err != nil {
+		t.Fatal(err)
+	}
+	if len(seen) != 2 {
+		t.Errorf("batch restart should visit every site, got %v", seen)
+	}
+}
+
 func TestRunStartDockerDown(t *testing.T) {
 	setupSrvRoot(t)
 	t.Cleanup(docker.SwapNewClientErr(errors.New("offline")))
@@ -129,14 +263,14 @@ func TestStartAllSitesEmpty(t *testing.T) {
 
 func TestStopAllSitesEmpty(t *testing.T) {
 	setupSrvRoot(t)
-	if err := stopAllSites(); err != nil {
+	if err := stopAllSites(stopSiteExec, false); err != nil {
 		t.Errorf("err: %v", err)
 	}
 }
 
 func TestRestartAllSitesEmpty(t *testing.T) {
 	setupSrvRoot(t)
-	if err := restartAllSites(); err != nil {
+	if err := restartAllSites(restartSiteExec, false); err != nil {
 		t.Errorf("err: %v", err)
 	}
 }
@@ -187,6 +321,55 @@ func TestRunStartAllHappy(t *testing.T) {
 	}
 }
 
+func TestRunStartTag(t *testing.T) {
+	root := setupSrvRoot(t)
+	cfg := mustLoadConfig(t)
+	for _, name := range []string{"blog", "shop"} {
+		projectDir := filepath.Join(root, name)
+		if err := os.MkdirAll(projectDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		meta := site.SiteMetadata{
+			Type:        site.SiteTypeStatic,
+			Domains:     []string{name + ".local"},
+			ProjectPath: projectDir,
+			Port:        80,
+			NetworkName: cfg.NetworkName,
+		}
+		if name == "shop" {
+			meta.Tags = []string{"production"}
+		}
+		writeTestSite(t, name, meta)
+	}
+	t.Cleanup(docker.SwapNewClientWithNetwork(cfg.NetworkName))
+	var mu sync.Mutex
+	var dirs []string
+	t.Cleanup(docker.SwapComposeExec(func(dir string, _ bool, _ ...string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		dirs = append(dirs, dir)
+		return nil
+	}))
+	startFlags.tag = "production"
+	defer func() { startFlags.tag = "" }()
+	if err := runStart(nil, nil); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	for _, dir := range dirs {
+		if strings.Contains(dir, "blog") {
+			t.Errorf("untagged site started (compose in %s)", dir)
+		}
+	}
+	if len(dirs) == 0 {
+		t.Error("tagged site not started")
+	}
+
+	startFlags.tag = "staging"
+	if err := runStart(nil, nil); err == nil {
+		t.Error("expected an error for a tag no site carries")
+	}
+}
+
 func TestRunStopHappy(t *testing.T) {
 	root := setupSrvRoot(t)
 	projectDir := filepath.Join(root, "p")
@@ -322,3 +505,126 @@ func TestRunRestartBuild(t *testing.T) {
 		t.Errorf("err: %v", err)
 	}
 }
+
+func TestRunPullRestart(t *testing.T) {
+	root := setupSrvRoot(t)
+	projectDir := filepath.Join(root, "p")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := mustLoadConfig(t)
+	writeTestSite(t, "blog", site.SiteMetadata{
+		Type:        site.SiteTypeStatic,
+		Domains:     []string{"blog.local"},
+		ProjectPath: projectDir,
+		Port:        80,
+		NetworkName: cfg.NetworkName,
+	})
+	t.Cleanup(docker.SwapNewClientWithNetwork(cfg.NetworkName))
+	var calls []string
+	t.Cleanup(docker.SwapComposeExec(func(_ string, _ bool, args ...string) error {
+		calls = append(calls, args[len(args)-1])
+		return nil
+	}))
+	pullFlags.restart = true
+	t.Cleanup(func() { pullFlags.restart = false })
+	if err := runPull(nil, []string{"blog"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) < 2 || calls[0] != "pull" {
+		t.Errorf("compose calls = %v, want a pull then up", calls)
+	}
+	if meta, _ := site.ReadSiteMetadata("blog"); meta == nil || meta.LastPulled.IsZero() {
+		t.Error("LastPulled not recorded")
+	}
+}
+
+func TestRunStartProfileOverride(t *testing.T) {
+	root := setupSrvRoot(t)
+	projectDir := filepath.Join(root, "p")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	compose := "services:\n  web:\n    image: nginx\n    profiles: [dev, debug]\n  worker:\n    image: busybox\n    profiles: [jobs]\n"
+	if err := os.WriteFile(filepath.Join(projectDir, "docker-compose.yml"), []byte(compose), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := mustLoadConfig(t)
+	writeTestSite(t, "app", site.SiteMetadata{
+		Type:               site.SiteTypeCompose,
+		Domains:            []string{"app.test"},
+		ProjectPath:        projectDir,
+		ServiceName:        "p-web-1",
+		ComposeServiceName: "web",
+		Profile:            "dev",
+		Port:               80,
+		NetworkName:        cfg.NetworkName,
+	})
+	t.Cleanup(docker.SwapNewClientWithNetwork(cfg.NetworkName))
+	var ups []string
+	t.Cleanup(docker.SwapComposeExec(func(_ string, _ bool, args ...string) error {
+		ups = append(ups, strings.Join(args, " "))
+		return nil
+	}))
+	t.Cleanup(func() { startFlags.profile = "" })
+
+	startFlags.profile = "prod"
+	if err := runStart(nil, []string{"app"}); err == nil || !strings.Contains(err.Error(), "debug, dev, jobs") {
+		t.Errorf("err = %v, want the available profiles", err)
+	}
+
+	startFlags.profile = "debug"
+	if err := runStart(nil, []string{"app"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(ups) == 0 || !strings.Contains(ups[len(ups)-1], "--profile debug") {
+		t.Errorf("compose runs = %v, want --profile debug", ups)
+	}
+	if meta, _ := site.ReadSiteMetadata("app"); meta.Profile != "dev" {
+		t.Errorf("stored profile = %q, want dev unchanged", meta.Profile)
+	}
+}
+
+func TestStartRestartsRunningStaticSiteAfterReload(t *testing.T) {
+	root := setupSrvRoot(t)
+	cfg := mustLoadConfig(t)
+	projectDir := filepath.Join(root, "blog")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestSite(t, "blog", site.SiteMetadata{
+		Type:        site.SiteTypeStatic,
+		Domains:     []string{"blog.local"},
+		ProjectPath: projectDir,
+		Port:        80,
+		NetworkName: cfg.NetworkName,
+		ServiceName: "blog-web",
+	})
+	t.Cleanup(docker.SwapNewClientWithNetworkAndContainer(cfg.NetworkName, "blog-web", true))
+	var composeCalls [][]string
+	t.Cleanup(docker.SwapComposeExec(func(_ string, _ bool, args ...string) error {
+		composeCalls = append(composeCalls, args)
+		return nil
+	}))
+	restarted := func() bool {
+		return slices.ContainsFunc(composeCalls, func(args []string) bool { return slices.Contains(args, "restart") })
+	}
+
+	// The first start regenerates the site's artifacts, so the running
+	// container must be restarted to pick up the new nginx.conf.
+	if err := runStart(nil, []string{"blog"}); err != nil {
+		t.Fatal(err)
+	}
+	if !restarted() {
+		t.Errorf("compose calls = %v, want a restart", composeCalls)
+	}
+
+	// Unchanged metadata: Reload is skipped and nothing is restarted.
+	composeCalls = nil
+	if err := runStart(nil, []string{"blog"}); err != nil {
+		t.Fatal(err)
+	}
+	if restarted() {
+		t.Errorf("compose calls = %v, want no restart", composeCalls)
+	}
+}
@@ -0,0 +1,72 @@
+// Package cmd — site_move.go implements `srv move`: point a site at its
+// project directory's new location after the directory was moved on disk.
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/stubbedev/srv/internal/site"
+	"github.com/stubbedev/srv/internal/ui"
+)
+
+// =============================================================================
+// move command
+// =============================================================================
+
+var moveCmd = &cobra.Command{
+	Use:   "move SITE NEWPATH",
+	Short: "Update a site's project path after moving its directory",
+	Long: `Point a site at its project directory's new location. Use it after moving
+or renaming the directory, when the site shows as broken in 'srv list'.
+
+NEWPATH must hold the same kind of project: a docker-compose file that still
+defines the site's service, or a Dockerfile; any directory will do for a
+static site. The site's generated config is rewritten for the new path, and
+a site that was running is started again from it.
+
+Examples:
+  srv move blog ~/code/blog
+  srv move shop ../projects/shop`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			_ = cmd.Help()
+			return ui.UsageError("srv move SITE NEWPATH", "expected a site name and a new path, got %d argument(s)", len(args))
+		}
+		return nil
+	},
+	RunE: runMove,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		switch len(args) {
+		case 0:
+			return GetSiteNames(), cobra.ShellCompDirectiveNoFileComp
+		case 1:
+			return nil, cobra.ShellCompDirectiveFilterDirs
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+func init() {
+	moveCmd.GroupID = GroupSites
+	RootCmd.AddCommand(moveCmd)
+}
+
+func runMove(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	restarted, warnings, err := site.MoveSite(name, args[1])
+	for _, w := range warnings {
+		ui.Warn("%s", w)
+	}
+	if err != nil {
+		return err
+	}
+	meta, err := site.ReadSiteMetadata(name)
+	if err != nil {
+		return err
+	}
+	ui.Success("Site '%s' now points at %s", name, meta.ProjectPath)
+	if restarted {
+		ui.Success("Restarted '%s'", name)
+	}
+	return nil
+}
@@ -3,6 +3,8 @@
 package cmd
 
 import (
+	"strings"
+
 	"github.com/spf13/cobra"
 
 	"github.com/stubbedev/srv/internal/site"
@@ -13,11 +15,18 @@ import (
 // remove command
 // =============================================================================
 
+var removeFlags struct {
+	dryRun bool
+}
+
 var removeCmd = &cobra.Command{
 	Use:     "remove SITE",
 	Aliases: []string{"rm"},
 	Short:   "Remove a site",
-	Long:    `Stop a site's containers and remove it from srv.`,
+	Long: `Stop a site's containers and remove it from srv.
+
+Use --dry-run to list the containers, config files, and DNS records that
+would be removed without touching anything.`,
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) == 0 {
 			_ = cmd.Help()
@@ -35,22 +44,58 @@ var removeCmd = &cobra.Command{
 }
 
 func init() {
+	removeCmd.Flags().BoolVar(&removeFlags.dryRun, "dry-run", false, "Show what would be removed without removing anything")
 	removeCmd.GroupID = GroupSites
 	RootCmd.AddCommand(removeCmd)
 }
 
+// removeExecFn removes (or previews removing) one site by name.
+type removeExecFn func(name string) error
+
 func runRemove(cmd *cobra.Command, args []string) error {
-	siteName := args[0]
+	var execFn removeExecFn = removeSiteExec
+	if removeFlags.dryRun {
+		execFn = previewRemoveExec
+	}
+	return execFn(args[0])
+}
 
-	// Orchestration is shared with the MCP remove_site tool (internal/site).
-	ui.Info("Removing %s...", siteName)
-	warnings, err := site.RemoveSite(siteName)
+// removeSiteExec is the real removal. Orchestration is shared with the MCP
+// remove_site tool (internal/site).
+func removeSiteExec(name string) error {
+	ui.Info("Removing %s...", name)
+	warnings, err := site.RemoveSite(name)
 	if err != nil {
 		return err
 	}
 	for _, w := range warnings {
 		ui.Warn("%s", w)
 	}
-	ui.Success("Site '%s' removed", siteName)
+	ui.Success("Site '%s' removed", name)
+	return nil
+}
+
+// previewRemoveExec prints the removal plan for a site and changes nothing.
+func previewRemoveExec(name string) error {
+	plan, err := site.PlanRemoveSite(name)
+	if err != nil {
+		return err
+	}
+	ui.Info("Dry run: removing %s would", name)
+	printPlanSection("stop and remove containers", plan.Containers)
+	printPlanSection("delete files", plan.Files)
+	printPlanSection("unregister DNS records", plan.DNS)
+	ui.Dim("Nothing was changed (--dry-run)")
 	return nil
 }
+
+// printPlanSection prints one titled list of a dry-run plan, or a dim
+// "(none)" when the step would not touch anything.
+func printPlanSection(title string, items []string) {
+	if len(items) == 0 {
+		ui.Print("  %s: %s", title, ui.DimText("(none)"))
+		return
+	}
+	ui.Print("  %s:", title)
+	ui.Print("    %s", strings.Join(items, "\n    "))
+}
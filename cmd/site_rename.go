@@ -0,0 +1,66 @@
+// Package cmd — site_rename.go implements `srv rename`: move a site's
+// registration (metadata, certs, Traefik config) to a new name in place.
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/stubbedev/srv/internal/site"
+	"github.com/stubbedev/srv/internal/ui"
+)
+
+// =============================================================================
+// rename command
+// =============================================================================
+
+var renameFlags struct {
+	force bool
+}
+
+var renameCmd = &cobra.Command{
+	Use:   "rename SITE NEWNAME",
+	Short: "Rename a site",
+	Long: `Rename a site without removing and re-adding it.
+
+Metadata, local certificates, and Traefik config move to the new name; the
+site's domains are unchanged. A running site must be stopped first unless
+--force is given (srv-managed static and dockerfile sites are taken down,
+since their containers are named after the site).`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			_ = cmd.Help()
+			return ui.UsageError("srv rename SITE NEWNAME", "expected a site name and a new name, got %d argument(s)", len(args))
+		}
+		return nil
+	},
+	RunE: runRename,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return GetSiteNames(), cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+func init() {
+	renameCmd.Flags().BoolVarP(&renameFlags.force, "force", "f", false, "Rename even if the site is running")
+	renameCmd.GroupID = GroupSites
+	RootCmd.AddCommand(renameCmd)
+}
+
+func runRename(cmd *cobra.Command, args []string) error {
+	oldName, newName := args[0], args[1]
+	if err := ValidateSiteName(newName); err != nil {
+		return err
+	}
+
+	warnings, err := site.RenameSite(oldName, newName, renameFlags.force)
+	if err != nil {
+		return err
+	}
+	for _, w := range warnings {
+		ui.Warn("%s", w)
+	}
+	ui.Success("Site '%s' renamed to '%s'", oldName, newName)
+	return nil
+}
@@ -1,14 +1,20 @@
 // Package cmd — site_shell.go implements the interactive site commands:
-// `srv shell` (open a shell in the site's container) and `srv open`
-// (open the site's URL in the system browser).
+// `srv shell` (open a shell in the site's container), `srv exec` (run a
+// command in it), `srv compose` (run docker compose in the site's project)
+// and `srv open` (open the site's URL in the system browser).
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"slices"
+	"sort"
+	"strings"
 
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
 
 	"github.com/stubbedev/srv/internal/docker"
@@ -24,32 +30,44 @@ var shellFlags struct {
 	service string
 }
 
+// shellProbe starts bash when the image has it and sh otherwise.
+var shellProbe = []string{"/bin/sh", "-c", "if [ -x /bin/bash ]; then exec /bin/bash; fi; exec /bin/sh"}
+
 var shellCmd = &cobra.Command{
-	Use:   "shell SITE",
+	Use:   "shell SITE [SERVICE]",
 	Short: "Open an interactive shell in a site's container",
-	Long: `Open an interactive shell (sh) in the primary container for a site.
+	Long: `Open an interactive shell (bash, falling back to sh) in the primary
+container for a site.
 
 For static and dockerfile sites the single container is used.
 
-For compose sites the first service container is used; pass --service to
-pick a different one.
+For compose sites the routed service's container is used; name another
+service of the compose project as SERVICE to shell into that one instead
+(via docker compose exec), or pass --service with a container name.
 
 Examples:
-  srv site shell mysite
-  srv site shell mysite --service api`,
+  srv shell mysite
+  srv shell mysite worker
+  srv shell mysite --service mysite-api-1`,
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) == 0 {
 			_ = cmd.Help()
-			return ui.UsageError("srv site shell SITE", "a site name is required")
+			return ui.UsageError("srv shell SITE [SERVICE]", "a site name is required")
 		}
-		if len(args) > 1 {
-			return ui.UsageError("srv site shell SITE", "too many arguments — expected a single site name, got %d", len(args))
+		if len(args) > 2 {
+			return ui.UsageError("srv shell SITE [SERVICE]", "too many arguments — expected a site name and optional service, got %d", len(args))
 		}
 		return nil
 	},
 	RunE: runShell,
 	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		return GetSiteNames(), cobra.ShellCompDirectiveNoFileComp
+		switch len(args) {
+		case 0:
+			return GetSiteNames(), cobra.ShellCompDirectiveNoFileComp
+		case 1:
+			return siteComposeServiceNames(args[0]), cobra.ShellCompDirectiveNoFileComp
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
 	},
 }
 
@@ -74,6 +92,13 @@ func runShell(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("site '%s' is broken (target directory missing)", s.Name)
 	}
 
+	if len(args) > 1 {
+		if shellFlags.service != "" {
+			return ui.UsageError(cmd.UseLine(), "pass the service as an argument or with --service, not both")
+		}
+		return shellIntoService(s, args[1])
+	}
+
 	// Determine the container to shell into.
 	containerName := shellFlags.service
 	if containerName == "" {
@@ -84,36 +109,281 @@ func runShell(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("cannot determine container for site '%s' — use --service to specify one", siteName)
 	}
 
-	if !docker.ContainerExists(containerName) {
+	if !docker.IsContainerRunning(containerName) {
 		return fmt.Errorf("container '%s' is not running — start the site first with: srv start %s", containerName, siteName)
 	}
 
 	ui.Dim("Connecting to container: %s", containerName)
-	execArgs := []string{"exec", "-it", containerName, "sh"}
-	c := exec.Command("docker", execArgs...) //nolint:gosec
+	return shellExitError(dockerExecAttached(containerName, shellProbe))
+}
+
+// shellIntoService opens a shell in one service of a compose site through
+// `docker compose exec`, so any service of the project can be reached, not
+// just the routed one.
+func shellIntoService(s *site.Site, service string) error {
+	if s.Type != site.SiteTypeCompose && s.Type != "" {
+		return fmt.Errorf("site '%s' is a %s site with a single container — drop the SERVICE argument", s.Name, s.Type)
+	}
+	services := siteComposeServiceNames(s.Name)
+	if !slices.Contains(services, service) {
+		return fmt.Errorf("service '%s' not found in %s's compose file (available: %s)", service, s.Name, strings.Join(services, ", "))
+	}
+	if _, err := docker.ComposeServiceContainerID(s.ComposeDir, service); err != nil {
+		return fmt.Errorf("service '%s' is not running — start the site first with: srv start %s", service, s.Name)
+	}
+
+	args := docker.ComposeFileArgs(s.ComposeFiles())
+	if s.Profile != "" {
+		args = append(args, "--profile", s.Profile)
+	}
+	args = append(args, "exec")
+	if !isatty.IsTerminal(os.Stdin.Fd()) {
+		args = append(args, "-T")
+	}
+	args = append(args, service)
+	ui.Dim("Connecting to service: %s", service)
+	return shellExitError(docker.ComposeAttached(s.ComposeDir, append(args, shellProbe...)...))
+}
+
+// shellExitError treats a non-zero exit from the shell as normal (the user
+// typed `exit N`); only failures to run docker are reported.
+func shellExitError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() != 0 {
+		return nil
+	}
+	return fmt.Errorf("docker exec failed: %w", err)
+}
+
+// siteComposeServiceNames lists the services in a compose site's compose
+// file, sorted; nil for other site types or when the file can't be parsed.
+func siteComposeServiceNames(siteName string) []string {
+	meta, err := site.ReadSiteMetadata(siteName)
+	if err != nil || meta == nil || (meta.Type != site.SiteTypeCompose && meta.Type != "") {
+		return nil
+	}
+	composePath, err := site.FindComposeFile(meta.ProjectPath)
+	if err != nil {
+		return nil
+	}
+	infos, err := site.GetServiceInfos(composePath)
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(infos))
+	for _, info := range infos {
+		names = append(names, info.ServiceName)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// dockerExecAttached runs `docker exec -i[t] container command...` with the
+// caller's stdio attached. A TTY is only requested when stdin is a terminal so
+// piped input (`echo x | srv exec ...`) still works.
+func dockerExecAttached(containerName string, command []string) error {
+	flags := "-i"
+	if isatty.IsTerminal(os.Stdin.Fd()) {
+		flags = "-it"
+	}
+	execArgs := append([]string{"exec", flags, containerName}, command...)
+	c := docker.Command(context.Background(), execArgs...)
 	c.Stdin = os.Stdin
 	c.Stdout = os.Stdout
 	c.Stderr = os.Stderr
-	if err := c.Run(); err != nil {
-		// Exit code != 0 from the shell is normal (user typed exit N), don't wrap it as an error.
+	return c.Run()
+}
+
+// siteShellContainer returns the container name to shell into for a given
+// site: the generated nginx container for static sites, the srv-built app
+// container for dockerfile sites, the routed service's container otherwise.
+func siteShellContainer(s site.Site) string {
+	return s.PrimaryContainer()
+}
+
+// =============================================================================
+// exec command
+// =============================================================================
+
+var execFlags struct {
+	shell string
+}
+
+var execCmd = &cobra.Command{
+	Use:   "exec SITE [-- COMMAND [ARGS...]]",
+	Short: "Run a command in a site's primary container",
+	Long: `Run a command inside the container that serves a site, with stdin and
+stdout attached.
+
+The container is resolved from the site: the generated container for static
+and dockerfile sites, the routed service's container for compose sites (looked
+up via docker compose when the service uses a profile). With no command, the
+--shell program is started.
+
+Examples:
+  srv exec mysite -- php artisan migrate
+  srv exec mysite --shell /bin/bash`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			_ = cmd.Help()
+			return ui.UsageError("srv exec SITE [-- COMMAND [ARGS...]]", "a site name is required")
+		}
+		return nil
+	},
+	RunE: runExec,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+		return GetSiteNames(), cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+func init() {
+	execCmd.Flags().StringVar(&execFlags.shell, "shell", "/bin/sh", "Program to run when no command is given")
+	execCmd.GroupID = GroupSites
+	RootCmd.AddCommand(execCmd)
+}
+
+func runExec(cmd *cobra.Command, args []string) error {
+	if err := docker.EnsureRunning(); err != nil {
+		return err
+	}
+
+	siteName := args[0]
+	s, err := site.GetByName(siteName)
+	if err != nil {
+		return err
+	}
+	if s.IsBroken {
+		return fmt.Errorf("site '%s' is broken (target directory missing)", s.Name)
+	}
+
+	containerName, err := siteExecContainer(s)
+	if err != nil {
+		return err
+	}
+
+	command := args[1:]
+	if len(command) == 0 {
+		command = []string{execFlags.shell}
+	}
+
+	if err := dockerExecAttached(containerName, command); err != nil {
 		var exitErr *exec.ExitError
-		if errors.As(err, &exitErr) && exitErr.ExitCode() != 0 {
-			return nil
+		if errors.As(err, &exitErr) {
+			return fmt.Errorf("command exited with status %d", exitErr.ExitCode())
 		}
 		return fmt.Errorf("docker exec failed: %w", err)
 	}
 	return nil
 }
 
-// siteShellContainer returns the container name to shell into for a given site.
-func siteShellContainer(s site.Site) string {
-	switch s.Type {
-	case site.SiteTypeDockerfile:
-		return "srv-" + s.Name + "-app"
-	default:
-		// Compose sites: use the stored service name (container name).
-		return s.ServiceName
+// siteExecContainer resolves the container `srv exec` runs in. Services behind
+// a compose profile don't get a predictable container name, so they are
+// resolved through `docker compose ps -q` instead.
+func siteExecContainer(s *site.Site) (string, error) {
+	if s.Profile != "" && s.ComposeServiceName != "" {
+		id, err := docker.ComposeServiceContainerID(s.ComposeDir, s.ComposeServiceName)
+		if err != nil {
+			return "", fmt.Errorf("service '%s' is not running — start the site first with: srv start %s", s.ComposeServiceName, s.Name)
+		}
+		return id, nil
+	}
+
+	containerName := s.PrimaryContainer()
+	if containerName == "" {
+		return "", fmt.Errorf("cannot determine container for site '%s'", s.Name)
+	}
+	if !docker.IsContainerRunning(containerName) {
+		return "", fmt.Errorf("container '%s' is not running — start the site first with: srv start %s", containerName, s.Name)
+	}
+	return containerName, nil
+}
+
+// =============================================================================
+// compose command
+// =============================================================================
+
+var composeCmd = &cobra.Command{
+	Use:   "compose SITE -- ARGS...",
+	Short: "Run a docker compose command for a site",
+	Long: `Run any docker compose subcommand in a site's project, without cd-ing
+into it. Everything after -- is passed to docker compose, with stdin and
+stdout attached; the site's compose profile and 'srv env' overrides are
+applied as they are by 'srv start'.
+
+Static and dockerfile sites run against the compose file srv generated for
+them.
+
+Examples:
+  srv compose mysite -- top
+  srv compose mysite -- run --rm web php artisan tinker
+  srv compose mysite -- events`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		const usage = "srv compose SITE -- ARGS..."
+		dash := cmd.ArgsLenAtDash()
+		switch {
+		case len(args) == 0:
+			_ = cmd.Help()
+			return ui.UsageError(usage, "a site name is required")
+		case dash == -1:
+			return ui.UsageError(usage, "put the compose arguments after --, e.g. srv compose %s -- ps", args[0])
+		case dash != 1:
+			return ui.UsageError(usage, "expected a single site name before --, got %d arguments", dash)
+		case len(args) == dash:
+			return ui.UsageError(usage, "no compose command given after --")
+		}
+		return nil
+	},
+	RunE: runCompose,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+		return GetSiteNames(), cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+func init() {
+	composeCmd.GroupID = GroupSites
+	RootCmd.AddCommand(composeCmd)
+}
+
+func runCompose(cmd *cobra.Command, args []string) error {
+	if err := docker.EnsureRunning(); err != nil {
+		return err
 	}
+	s, err := site.GetByName(args[0])
+	if err != nil {
+		return err
+	}
+	if s.IsBroken {
+		return fmt.Errorf("site '%s' is broken (target directory missing)", s.Name)
+	}
+
+	files, cleanup, err := site.EnvOverlay(s)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	var composeArgs []string
+	if s.Profile != "" {
+		composeArgs = append(composeArgs, "--profile", s.Profile)
+	}
+	composeArgs = slices.Concat(composeArgs, docker.ComposeFileArgs(files), args[1:])
+
+	if err := docker.ComposeAttached(s.ComposeDir, composeArgs...); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return fmt.Errorf("docker compose exited with status %d", exitErr.ExitCode())
+		}
+		return fmt.Errorf("docker compose failed: %w", err)
+	}
+	return nil
 }
 
 // =============================================================================
@@ -157,8 +427,13 @@ func runOpen(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("site '%s' has no domain configured", siteName)
 	}
 
-	url := "https://" + primary
+	url := s.URL()
 	ui.Dim("Opening %s...", url)
+	return openURL(url)
+}
+
+// openURL opens url in the system default browser. Tests swap it.
+var openURL = func(url string) error {
 	c := exec.Command("xdg-open", url) //nolint:gosec
 	c.Stdout = os.Stdout
 	c.Stderr = os.Stderr
@@ -1,9 +1,17 @@
 package cmd
 
 import (
+	"context"
 	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 
+	"github.com/spf13/cobra"
+
+	"github.com/stubbedev/srv/internal/constants"
 	"github.com/stubbedev/srv/internal/docker"
 	"github.com/stubbedev/srv/internal/site"
 )
@@ -35,6 +43,13 @@ func TestSiteShellContainer(t *testing.T) {
 	}
 }
 
+func TestSiteShellContainerStatic(t *testing.T) {
+	got := siteShellContainer(site.Site{Name: "s", Type: site.SiteTypeStatic})
+	if !strings.HasPrefix(got, constants.StaticContainerPrefix) {
+		t.Errorf("siteShellContainer(static) = %q, want the generated %s* container", got, constants.StaticContainerPrefix)
+	}
+}
+
 func TestRunShellDockerDown(t *testing.T) {
 	setupSrvRoot(t)
 	t.Cleanup(dockerSwapNewClientErrShell())
@@ -51,6 +66,183 @@ func TestRunShellMissingSite(t *testing.T) {
 	}
 }
 
+// writeComposeShellSite registers a compose site whose project defines the
+// web and worker services.
+func writeComposeShellSite(t *testing.T) *site.Site {
+	t.Helper()
+	dir := t.TempDir()
+	compose := "services:\n  worker:\n    image: busybox\n  web:\n    image: nginx\n"
+	if err := os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte(compose), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	writeTestSite(t, "app", site.SiteMetadata{Type: site.SiteTypeCompose, ProjectPath: dir, Port: 80, NetworkName: "n"})
+	return &site.Site{Name: "app", Type: site.SiteTypeCompose, ComposeDir: dir, Profile: "jobs"}
+}
+
+func TestSiteComposeServiceNames(t *testing.T) {
+	setupSrvRoot(t)
+	writeComposeShellSite(t)
+	if got := siteComposeServiceNames("app"); !slices.Equal(got, []string{"web", "worker"}) {
+		t.Errorf("siteComposeServiceNames = %v, want [web worker]", got)
+	}
+	if got := siteComposeServiceNames("ghost"); got != nil {
+		t.Errorf("siteComposeServiceNames(ghost) = %v, want nil", got)
+	}
+}
+
+func TestShellIntoService(t *testing.T) {
+	setupSrvRoot(t)
+	s := writeComposeShellSite(t)
+	t.Cleanup(docker.SwapComposeServiceIDLookup(func(context.Context, string, string) (string, error) {
+		return "abc123", nil
+	}))
+	var gotDir string
+	var gotArgs []string
+	t.Cleanup(docker.SwapComposeAttachedExec(func(dir string, args ...string) error {
+		gotDir, gotArgs = dir, args
+		return nil
+	}))
+
+	if err := shellIntoService(s, "worker"); err != nil {
+		t.Fatalf("shellIntoService: %v", err)
+	}
+	if gotDir != s.ComposeDir {
+		t.Errorf("dir = %q, want %q", gotDir, s.ComposeDir)
+	}
+	// Test stdin is never a terminal, so -T is always passed here.
+	want := append([]string{"--profile", "jobs", "exec", "-T", "worker"}, shellProbe...)
+	if !slices.Equal(gotArgs, want) {
+		t.Errorf("args = %q, want %q", gotArgs, want)
+	}
+
+	if err := shellIntoService(s, "db"); err == nil {
+		t.Error("expected err for a service not in the compose file")
+	}
+}
+
+func TestShellIntoServiceNotRunning(t *testing.T) {
+	setupSrvRoot(t)
+	s := writeComposeShellSite(t)
+	t.Cleanup(docker.SwapComposeServiceIDLookup(func(context.Context, string, string) (string, error) {
+		return "", nil
+	}))
+	t.Cleanup(docker.SwapComposeAttachedExec(func(string, ...string) error {
+		t.Error("compose exec should not run for a stopped service")
+		return nil
+	}))
+	if err := shellIntoService(s, "web"); err == nil {
+		t.Error("expected err: service not running")
+	}
+}
+
+func TestShellIntoServiceRejectsSingleContainerSites(t *testing.T) {
+	s := &site.Site{Name: "x", Type: site.SiteTypeStatic}
+	if err := shellIntoService(s, "web"); err == nil {
+		t.Error("expected err: static sites have no services")
+	}
+}
+
+func TestSiteExecContainerProfile(t *testing.T) {
+	t.Cleanup(docker.SwapComposeServiceIDLookup(func(_ context.Context, dir, svc string) (string, error) {
+		if dir != "/proj" || svc != "worker" {
+			t.Errorf("lookup(%q, %q)", dir, svc)
+		}
+		return "abc123\ndef456", nil
+	}))
+	s := &site.Site{Name: "x", Type: site.SiteTypeCompose, ComposeDir: "/proj", ComposeServiceName: "worker", Profile: "jobs"}
+	got, err := siteExecContainer(s)
+	if err != nil || got != "abc123" {
+		t.Errorf("siteExecContainer = %q, %v; want first container ID", got, err)
+	}
+
+	t.Cleanup(docker.SwapComposeServiceIDLookup(func(context.Context, string, string) (string, error) {
+		return "", nil
+	}))
+	if _, err := siteExecContainer(s); err == nil {
+		t.Error("expected err when the profiled service has no container")
+	}
+}
+
+func TestSiteExecContainerNotRunning(t *testing.T) {
+	t.Cleanup(dockerSwapNewClientErrShell())
+	s := &site.Site{Name: "x", Type: site.SiteTypeStatic}
+	if _, err := siteExecContainer(s); err == nil {
+		t.Error("expected err: container not running")
+	}
+}
+
+func TestSiteExecContainerStopped(t *testing.T) {
+	s := &site.Site{Name: "blog", Type: site.SiteTypeCompose, ServiceName: "blog-web"}
+	t.Cleanup(docker.SwapNewClientWithContainer("blog-web", false))
+	if _, err := siteExecContainer(s); err == nil || !strings.Contains(err.Error(), "is not running") {
+		t.Errorf("stopped container: err = %v, want 'is not running'", err)
+	}
+
+	t.Cleanup(docker.SwapNewClientWithContainer("blog-web", true))
+	if got, err := siteExecContainer(s); err != nil || got != "blog-web" {
+		t.Errorf("running container: siteExecContainer = %q, %v", got, err)
+	}
+}
+
+func TestRunExecMissingSite(t *testing.T) {
+	setupSrvRoot(t)
+	t.Cleanup(dockerSwapNewClientOKShell())
+	if err := runExec(nil, []string{"ghost", "ls"}); err == nil {
+		t.Error("expected err: site missing")
+	}
+}
+
+func TestComposeArgsRequireDash(t *testing.T) {
+	cases := []struct {
+		argv    []string
+		wantErr string
+	}{
+		{[]string{"app", "--", "top"}, ""},
+		{[]string{"app", "top"}, "after --"},
+		{[]string{"app", "web", "--", "top"}, "single site name"},
+		{[]string{"app", "--"}, "no compose command"},
+	}
+	for _, c := range cases {
+		cmd := &cobra.Command{}
+		if err := cmd.Flags().Parse(c.argv); err != nil {
+			t.Fatal(err)
+		}
+		err := composeCmd.Args(cmd, cmd.Flags().Args())
+		if c.wantErr == "" && err != nil {
+			t.Errorf("%v: unexpected err %v", c.argv, err)
+		}
+		if c.wantErr != "" && (err == nil || !strings.Contains(err.Error(), c.wantErr)) {
+			t.Errorf("%v: err = %v, want %q", c.argv, err, c.wantErr)
+		}
+	}
+}
+
+func TestRunCompose(t *testing.T) {
+	setupSrvRoot(t)
+	t.Cleanup(dockerSwapNewClientOKShell())
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte("services:\n  web:\n    image: nginx\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	writeTestSite(t, "app", site.SiteMetadata{Type: site.SiteTypeCompose, ProjectPath: dir, Profile: "jobs", Port: 80, NetworkName: "n"})
+	var gotDir string
+	var gotArgs []string
+	t.Cleanup(docker.SwapComposeAttachedExec(func(dir string, args ...string) error {
+		gotDir, gotArgs = dir, args
+		return nil
+	}))
+
+	if err := runCompose(nil, []string{"app", "top"}); err != nil {
+		t.Fatal(err)
+	}
+	if gotDir != dir {
+		t.Errorf("dir = %q, want %q", gotDir, dir)
+	}
+	if want := []string{"--profile", "jobs", "top"}; !slices.Equal(gotArgs, want) {
+		t.Errorf("args = %q, want %q", gotArgs, want)
+	}
+}
+
 func TestRunOpenMissingSite(t *testing.T) {
 	setupSrvRoot(t)
 	if err := runOpen(nil, []string{"ghost"}); err == nil {
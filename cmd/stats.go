@@ -0,0 +1,156 @@
+// Package cmd — stats.go implements `srv stats SITE`: one site's request
+// count, request rate, latency and bytes transferred, read from Traefik's
+// Prometheus endpoint.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/metrics"
+	"github.com/stubbedev/srv/internal/site"
+	"github.com/stubbedev/srv/internal/ui"
+)
+
+var statsFlags struct {
+	reset  bool
+	window time.Duration
+}
+
+var statsCmd = &cobra.Command{
+	Use:   "stats SITE",
+	Short: "Show a site's request count, latency and bandwidth",
+	Long: `Read Traefik's Prometheus endpoint (` + metrics.EndpointURL + `) and
+show one site's traffic: total requests, requests per minute, average and
+95th percentile response time, and bytes received and sent.
+
+Totals count from Traefik's start. Prometheus counters can't be reset, so
+--reset stores the current counters as a baseline instead
+(~/.config/srv/sites/SITE/stats-baseline.json) and later runs count from it,
+until Traefik restarts. Requests per minute come from two scrapes --window
+apart (1m by default). Traefik's exporter must be on: 'srv metrics enable'
+turns it on.
+
+Examples:
+  srv stats blog
+  srv stats blog --window 10s
+  srv stats blog --reset
+  srv stats blog --json`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			_ = cmd.Help()
+			return ui.UsageError("srv stats SITE", "a site name is required")
+		}
+		if len(args) > 1 {
+			return ui.UsageError("srv stats SITE", "too many arguments — expected a single site name, got %d", len(args))
+		}
+		return nil
+	},
+	RunE: runStats,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return GetSiteNames(), cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+func init() {
+	statsCmd.Flags().BoolVar(&statsFlags.reset, "reset", false, "Count from now on: store the current counters as the site's baseline")
+	statsCmd.Flags().DurationVar(&statsFlags.window, "window", time.Minute, "Time between the two scrapes requests per minute are measured over")
+	addJSONFlag(statsCmd)
+	statsCmd.GroupID = GroupSites
+	RootCmd.AddCommand(statsCmd)
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	if statsFlags.window <= 0 {
+		return ui.UsageError("srv stats SITE --window DURATION", "--window must be positive, got %s", statsFlags.window)
+	}
+	s, err := site.GetByName(args[0])
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	names := GetSiteNames()
+	group := func(router string) string { return metricsGroup(router, names, s.Name) }
+	baselinePath := site.StatsBaselinePath(cfg, s.Name)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	prev, err := metrics.Scrape(ctx)
+	if err != nil {
+		return err
+	}
+	if statsFlags.reset {
+		if err := metrics.WriteBaseline(baselinePath, prev.NewBaseline(s.Name, group)); err != nil {
+			return fmt.Errorf("save stats baseline: %w", err)
+		}
+		ui.Success("Stats for '%s' reset; totals now count from %s", s.Name, prev.At.Format(time.DateTime))
+		return nil
+	}
+	base, err := metrics.ReadBaseline(baselinePath)
+	if err != nil {
+		return err
+	}
+
+	if !jsonOutput() {
+		ui.Dim("Sampling for %s...", statsFlags.window)
+	}
+	select {
+	case <-ctx.Done():
+		return nil
+	case <-time.After(statsFlags.window):
+	}
+	cur, err := metrics.Scrape(ctx)
+	if err != nil {
+		return err
+	}
+	st := metrics.SiteStats(prev, cur, s.Name, group, base)
+	if jsonOutput() {
+		return ui.PrintJSON(st)
+	}
+
+	ui.Blank()
+	ui.Bold("Stats: %s", s.Name)
+	ui.Blank()
+	if st.Since != nil {
+		ui.Print("  Since:         %s (srv stats --reset)", st.Since.Local().Format(time.DateTime))
+	} else {
+		ui.Print("  Since:         Traefik started")
+	}
+	ui.Print("  Requests:      %.0f", st.Requests)
+	ui.Print("  Requests/min:  %.1f (over the last %s)", st.RequestsPerMin, statsFlags.window)
+	ui.Print("  Avg response:  %s", formatLatency(st.AvgMS))
+	ui.Print("  P95 latency:   %s", formatLatency(st.P95MS))
+	ui.Print("  Transferred:   %s in, %s out", formatBytes(st.RequestBytes), formatBytes(st.ResponseBytes))
+	if base != nil && st.Since == nil {
+		ui.Dim("Traefik restarted since the last --reset, so totals count from its start.")
+	}
+	return nil
+}
+
+// formatBytes renders a byte count with a binary unit, e.g. 1.5 MiB.
+func formatBytes(n float64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%.0f B", n)
+	}
+	exp := 0
+	for n >= unit*unit && exp < 4 {
+		n /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", n/unit, "KMGTP"[exp])
+}
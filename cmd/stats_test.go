@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stubbedev/srv/internal/metrics"
+	"github.com/stubbedev/srv/internal/site"
+)
+
+func TestRunStatsResetAndJSON(t *testing.T) {
+	root := setupSrvRoot(t)
+	writeTestSite(t, "blog", site.SiteMetadata{
+		Type:        site.SiteTypeStatic,
+		Domains:     []string{"blog.test"},
+		ProjectPath: root,
+		Port:        80,
+		NetworkName: "n",
+	})
+	requests := 40
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("traefik_router_requests_total{code=\"200\",router=\"blog@docker\"} " + strconv.Itoa(requests) + "\n" +
+			"traefik_router_responses_bytes_total{code=\"200\",router=\"blog@docker\"} 4096\n"))
+	}))
+	defer srv.Close()
+	prevURL, prevWindow := metrics.EndpointURL, statsFlags.window
+	metrics.EndpointURL, statsFlags.window = srv.URL, time.Millisecond
+	t.Cleanup(func() { metrics.EndpointURL, statsFlags.window, statsFlags.reset = prevURL, prevWindow, false })
+
+	var st metrics.Stats
+	runJSONCommand(t, &st, "stats", "blog", "--window", "1ms", "--json")
+	if st.Name != "blog" || st.Requests != 40 || st.ResponseBytes != 4096 || st.Since != nil {
+		t.Errorf("stats = %+v", st)
+	}
+
+	executeRoot(t, "stats", "blog", "--reset")
+	if _, err := os.Stat(filepath.Join(root, "sites", "blog", "stats-baseline.json")); err != nil {
+		t.Fatalf("baseline not stored: %v", err)
+	}
+	statsFlags.reset = false
+	requests = 55
+	st = metrics.Stats{}
+	runJSONCommand(t, &st, "stats", "blog", "--window", "1ms", "--json")
+	if st.Requests != 15 || st.Since == nil {
+		t.Errorf("stats after reset = %+v, want 15 requests since the baseline", st)
+	}
+}
@@ -0,0 +1,254 @@
+// Package cmd — status.go implements `srv status`, a live dashboard that
+// re-renders the `srv list` table every few seconds.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+
+	"github.com/stubbedev/srv/internal/constants"
+	"github.com/stubbedev/srv/internal/site"
+	"github.com/stubbedev/srv/internal/ui"
+)
+
+// Terminal control sequences for the dashboard screen.
+const (
+	ansiAltScreenOn  = "\033[?1049h"
+	ansiAltScreenOff = "\033[?1049l"
+	ansiCursorHide   = "\033[?25l"
+	ansiCursorShow   = "\033[?25h"
+	ansiClearHome    = "\033[H\033[2J"
+)
+
+// minStatusInterval keeps --interval from hammering the Docker daemon.
+const minStatusInterval = 500 * time.Millisecond
+
+// statusFlashDuration is how long a changed status stays highlighted.
+const statusFlashDuration = time.Second
+
+var statusFlags struct {
+	interval time.Duration
+	noTUI    bool
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Live dashboard of all sites and their container states",
+	Long: `Show the 'srv list' table full-screen and refresh it in place every
+--interval. Statuses that changed since the previous refresh flash briefly.
+Press q or Ctrl-C to exit.
+
+When stdin/stdout aren't a terminal, or with --no-tui, the table is printed
+again on every refresh instead, each one headed by its timestamp.
+
+Examples:
+  srv status
+  srv status --interval 5s
+  srv status --no-tui > status.log`,
+	Args: cobra.NoArgs,
+	RunE: runStatus,
+}
+
+func init() {
+	statusCmd.Flags().DurationVar(&statusFlags.interval, "interval", 2*time.Second, "Refresh interval")
+	statusCmd.Flags().BoolVar(&statusFlags.noTUI, "no-tui", false, "Print the table periodically instead of a full-screen dashboard")
+	statusCmd.GroupID = GroupSites
+	RootCmd.AddCommand(statusCmd)
+}
+
+func runStatus(cmd *cobra.Command, args []string) error {
+	if statusFlags.interval < minStatusInterval {
+		return fmt.Errorf("--interval must be at least %s", minStatusInterval)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if statusFlags.noTUI || !isatty.IsTerminal(os.Stdin.Fd()) || !isatty.IsTerminal(os.Stdout.Fd()) {
+		return runStatusPlain(ctx, statusFlags.interval)
+	}
+	restore, err := enterCbreakMode()
+	if err != nil {
+		ui.VerboseLog("status: falling back to plain output: %v", err)
+		return runStatusPlain(ctx, statusFlags.interval)
+	}
+	defer restore()
+	return runStatusTUI(ctx, os.Stdout, readStatusKeys(os.Stdin), statusFlags.interval)
+}
+
+// runStatusPlain prints the list table every interval until ctx is done.
+func runStatusPlain(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		sites, err := site.List()
+		if err != nil {
+			return err
+		}
+		sortSites(sites, "name")
+		ui.Print("Last updated %s", time.Now().Format(time.TimeOnly))
+		if len(sites) == 0 {
+			ui.Dim("No sites registered. Use 'srv add PATH' to add a site.")
+		} else {
+			ui.PrintTable(listTableHeaders, listTableRows(sites, nil))
+		}
+		ui.Blank()
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// runStatusTUI draws the dashboard on the alternate screen until ctx is done
+// or q / Ctrl-C arrives on keys. The caller owns the terminal mode; the
+// screen and cursor are restored here.
+func runStatusTUI(ctx context.Context, out io.Writer, keys <-chan byte, interval time.Duration) error {
+	fmt.Fprint(out, ansiAltScreenOn+ansiCursorHide)
+	defer fmt.Fprint(out, ansiCursorShow+ansiAltScreenOff)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	flash := time.NewTimer(statusFlashDuration)
+	flash.Stop()
+	defer flash.Stop()
+
+	var (
+		sites   []site.Site
+		prev    map[string]string
+		listErr error
+	)
+	draw := func(changed map[string]bool) {
+		fmt.Fprint(out, ansiClearHome+renderStatusFrame(sites, changed, listErr, time.Now(), interval))
+	}
+	refresh := func() {
+		var latest []site.Site
+		latest, listErr = site.List()
+		if listErr == nil {
+			sites = latest
+			sortSites(sites, "name")
+		}
+		changed := statusChanges(prev, sites)
+		prev = siteStatuses(sites)
+		draw(changed)
+		if len(changed) > 0 {
+			flash.Reset(statusFlashDuration)
+		}
+	}
+
+	refresh()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case k, ok := <-keys:
+			if !ok || k == 'q' || k == 'Q' || k == 0x03 {
+				return nil
+			}
+		case <-ticker.C:
+			refresh()
+		case <-flash.C:
+			draw(nil)
+		}
+	}
+}
+
+// renderStatusFrame builds one full dashboard screen.
+func renderStatusFrame(sites []site.Site, changed map[string]bool, listErr error, now time.Time, interval time.Duration) string {
+	running := 0
+	for _, s := range sites {
+		if listSiteStatus(s) == constants.StatusRunning {
+			running++
+		}
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s  %d sites, %d running  %s\n\n",
+		ui.AccentText("srv status"), len(sites), running,
+		ui.DimText("Last updated "+now.Format(time.TimeOnly)))
+	if listErr != nil {
+		b.WriteString(ui.ErrorText("refresh failed: "+listErr.Error()) + "\n\n")
+	}
+	if len(sites) == 0 {
+		b.WriteString(ui.DimText("No sites registered. Use 'srv add PATH' to add a site.") + "\n")
+	} else {
+		b.WriteString(ui.RenderTable(listTableHeaders, listTableRows(sites, changed)))
+	}
+	fmt.Fprintf(&b, "\n%s\n", ui.DimText(fmt.Sprintf("Refreshing every %s. Press q or Ctrl-C to quit.", interval)))
+	return b.String()
+}
+
+// siteStatuses maps site name to its displayed status.
+func siteStatuses(sites []site.Site) map[string]string {
+	out := make(map[string]string, len(sites))
+	for _, s := range sites {
+		out[s.Name] = listSiteStatus(s)
+	}
+	return out
+}
+
+// statusChanges names the sites whose status differs from prev, including
+// sites added since. The first refresh (nil prev) reports nothing.
+func statusChanges(prev map[string]string, sites []site.Site) map[string]bool {
+	if prev == nil {
+		return nil
+	}
+	changed := map[string]bool{}
+	for _, s := range sites {
+		if old, ok := prev[s.Name]; !ok || old != listSiteStatus(s) {
+			changed[s.Name] = true
+		}
+	}
+	return changed
+}
+
+// readStatusKeys streams bytes from r until it fails. The goroutine outlives
+// the dashboard when the read blocks, which is harmless as srv exits next.
+func readStatusKeys(r io.Reader) <-chan byte {
+	keys := make(chan byte)
+	go func() {
+		defer close(keys)
+		buf := make([]byte, 1)
+		for {
+			if _, err := r.Read(buf); err != nil {
+				return
+			}
+			keys <- buf[0]
+		}
+	}()
+	return keys
+}
+
+// enterCbreakMode switches the terminal on stdin to unbuffered, no-echo input
+// so single key presses reach srv. Signal generation is turned off too: Ctrl-C
+// arrives as a byte, letting the dashboard restore the screen before exiting
+// instead of main's SIGINT handler exiting underneath it.
+func enterCbreakMode() (func(), error) {
+	saved, err := stty("-g")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stty("-icanon", "-echo", "-isig", "min", "1"); err != nil {
+		return nil, err
+	}
+	return func() { _, _ = stty(strings.TrimSpace(saved)) }, nil
+}
+
+func stty(args ...string) (string, error) {
+	c := exec.Command("stty", args...)
+	c.Stdin = os.Stdin
+	out, err := c.Output()
+	if err != nil {
+		return "", fmt.Errorf("stty %s: %w", strings.Join(args, " "), err)
+	}
+	return string(out), nil
+}
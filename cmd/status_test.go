@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stubbedev/srv/internal/site"
+)
+
+func TestStatusChanges(t *testing.T) {
+	sites := []site.Site{
+		{Name: "a", Status: "running"},
+		{Name: "b", Status: "stopped"},
+		{Name: "c", IsBroken: true},
+	}
+	cases := []struct {
+		name string
+		prev map[string]string
+		want string
+	}{
+		{"first refresh", nil, ""},
+		{"unchanged", map[string]string{"a": "running", "b": "stopped", "c": "broken"}, ""},
+		{"status flipped", map[string]string{"a": "stopped", "b": "stopped", "c": "broken"}, "a"},
+		{"new site", map[string]string{"a": "running", "c": "broken"}, "b"},
+		{"became broken", map[string]string{"a": "running", "b": "stopped", "c": "running"}, "c"},
+	}
+	for _, c := range cases {
+		changed := statusChanges(c.prev, sites)
+		var got []string
+		for _, s := range sites {
+			if changed[s.Name] {
+				got = append(got, s.Name)
+			}
+		}
+		if strings.Join(got, ",") != c.want {
+			t.Errorf("%s: changed = %v, want %q", c.name, got, c.want)
+		}
+	}
+}
+
+func TestRenderStatusFrame(t *testing.T) {
+	now := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	sites := []site.Site{
+		{Name: "api", Domains: []string{"api.test"}, Status: "running"},
+		{Name: "blog", Domains: []string{"blog.test"}, Status: "stopped"},
+	}
+	frame := stripAnsiCmd(renderStatusFrame(sites, nil, nil, now, 2*time.Second))
+	for _, want := range []string{"2 sites, 1 running", "Last updated 15:04:05", "api.test", "blog.test", "every 2s", "q or Ctrl-C"} {
+		if !strings.Contains(frame, want) {
+			t.Errorf("frame missing %q:\n%s", want, frame)
+		}
+	}
+
+	empty := renderStatusFrame(nil, nil, errors.New("docker down"), now, time.Second)
+	if !strings.Contains(empty, "refresh failed: docker down") || !strings.Contains(empty, "No sites registered") {
+		t.Errorf("empty frame = %q", empty)
+	}
+}
+
+func TestRunStatusTUIQuitKey(t *testing.T) {
+	setupSrvRoot(t)
+	keys := make(chan byte, 1)
+	keys <- 'q'
+	var out bytes.Buffer
+	if err := runStatusTUI(context.Background(), &out, keys, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+	if !strings.HasPrefix(got, ansiAltScreenOn) || !strings.HasSuffix(got, ansiCursorShow+ansiAltScreenOff) {
+		t.Errorf("screen not entered/restored: %q", got)
+	}
+	if !strings.Contains(got, "No sites registered") {
+		t.Errorf("initial frame not drawn: %q", got)
+	}
+}
+
+func TestRunStatusTUIContextDone(t *testing.T) {
+	setupSrvRoot(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var out bytes.Buffer
+	if err := runStatusTUI(ctx, &out, make(chan byte), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(out.String(), ansiAltScreenOff) {
+		t.Error("alternate screen not restored on cancel")
+	}
+}
+
+func TestRunStatusPlain(t *testing.T) {
+	setupSrvRoot(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := runStatusPlain(ctx, time.Hour); err != nil {
+		t.Errorf("err: %v", err)
+	}
+}
+
+func TestRunStatusIntervalTooShort(t *testing.T) {
+	t.Cleanup(func() { statusFlags.interval = 2 * time.Second })
+	statusFlags.interval = 10 * time.Millisecond
+	if err := runStatus(nil, nil); err == nil || !strings.Contains(err.Error(), "--interval") {
+		t.Errorf("err = %v", err)
+	}
+}
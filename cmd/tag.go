@@ -0,0 +1,209 @@
+// Package cmd — tag.go implements `srv tag`, which labels sites with tags
+// (production, staging, experimental) stored in their metadata. `srv list
+// --tag` and `srv start --tag` act on the sites carrying a tag.
+package cmd
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stubbedev/srv/internal/site"
+	"github.com/stubbedev/srv/internal/ui"
+)
+
+var tagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "Group sites with tags",
+	Long: `Label sites with tags to group them, e.g. production, staging or
+experimental. Tags are lowercase letters, digits and hyphens, at most 32
+characters.
+
+'srv list --tag TAG' shows the sites carrying a tag and 'srv start --tag TAG'
+starts them.`,
+}
+
+var tagAddCmd = &cobra.Command{
+	Use:   "add SITE TAG...",
+	Short: "Tag a site",
+	Long: `Add one or more tags to a site. Tags the site already has are skipped.
+
+Examples:
+  srv tag add api production
+  srv tag add blog staging team-web`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 2 {
+			return ui.UsageError("srv tag add SITE TAG...", "expected a site name and at least one tag, got %d argument(s)", len(args))
+		}
+		return nil
+	},
+	RunE:              runTagAdd,
+	ValidArgsFunction: completeTagSite,
+}
+
+var tagRemoveCmd = &cobra.Command{
+	Use:     "remove SITE TAG...",
+	Aliases: []string{"rm"},
+	Short:   "Remove tags from a site",
+	Long: `Remove one or more tags from a site.
+
+Examples:
+  srv tag remove api production`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 2 {
+			return ui.UsageError("srv tag remove SITE TAG...", "expected a site name and at least one tag, got %d argument(s)", len(args))
+		}
+		return nil
+	},
+	RunE: runTagRemove,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return GetSiteNames(), cobra.ShellCompDirectiveNoFileComp
+		}
+		meta, _ := site.ReadSiteMetadata(args[0])
+		if meta == nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return meta.Tags, cobra.ShellCompDirectiveNoFileComp
+	},
+}
+
+var tagListCmd = &cobra.Command{
+	Use:   "list [SITE]",
+	Short: "List tags",
+	Long: `List every tag with the sites carrying it, or the tags of one site.
+
+Examples:
+  srv tag list
+  srv tag list api
+  srv tag list --json`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 1 {
+			return ui.UsageError("srv tag list [SITE]", "expected at most one site name, got %d argument(s)", len(args))
+		}
+		return nil
+	},
+	RunE:              runTagList,
+	ValidArgsFunction: completeTagSite,
+}
+
+func init() {
+	addJSONFlag(tagListCmd)
+	tagCmd.GroupID = GroupSites
+	tagCmd.AddCommand(tagAddCmd, tagRemoveCmd, tagListCmd)
+	RootCmd.AddCommand(tagCmd)
+}
+
+// completeTagSite completes the SITE argument and nothing after it.
+func completeTagSite(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return GetSiteNames(), cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+func runTagAdd(cmd *cobra.Command, args []string) error {
+	tags, err := site.AddTags(args[0], args[1:])
+	if err != nil {
+		return err
+	}
+	ui.Success("Site '%s' tagged: %s", args[0], strings.Join(tags, ", "))
+	return nil
+}
+
+func runTagRemove(cmd *cobra.Command, args []string) error {
+	tags, err := site.RemoveTags(args[0], args[1:])
+	if err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		ui.Success("Site '%s' has no tags left", args[0])
+		return nil
+	}
+	ui.Success("Site '%s' tagged: %s", args[0], strings.Join(tags, ", "))
+	return nil
+}
+
+// tagSites is one row of `srv tag list`.
+type tagSites struct {
+	Tag   string   `json:"tag"`
+	Sites []string `json:"sites"`
+}
+
+func runTagList(cmd *cobra.Command, args []string) error {
+	if len(args) == 1 {
+		s, err := site.GetByName(args[0])
+		if err != nil {
+			return err
+		}
+		if jsonOutput() {
+			return ui.PrintJSON(append([]string{}, s.Tags...))
+		}
+		if len(s.Tags) == 0 {
+			ui.Dim("Site '%s' has no tags; add one with 'srv tag add %s TAG'", s.Name, s.Name)
+			return nil
+		}
+		for _, tag := range s.Tags {
+			ui.Print("%s", tag)
+		}
+		return nil
+	}
+
+	sites, err := site.List()
+	if err != nil {
+		return err
+	}
+	byTag := map[string][]string{}
+	for _, s := range sites {
+		for _, tag := range s.Tags {
+			byTag[tag] = append(byTag[tag], s.Name)
+		}
+	}
+	out := make([]tagSites, 0, len(byTag))
+	for tag, names := range byTag {
+		slices.Sort(names)
+		out = append(out, tagSites{Tag: tag, Sites: names})
+	}
+	slices.SortFunc(out, func(a, b tagSites) int { return strings.Compare(a.Tag, b.Tag) })
+
+	if jsonOutput() {
+		return ui.PrintJSON(out)
+	}
+	if len(out) == 0 {
+		ui.Dim("No tags; add one with 'srv tag add SITE TAG'")
+		return nil
+	}
+	rows := make([][]string, 0, len(out))
+	for _, t := range out {
+		rows = append(rows, []string{t.Tag, strings.Join(t.Sites, ", ")})
+	}
+	ui.PrintTable([]string{"TAG", "SITES"}, rows)
+	return nil
+}
+
+// completeTags completes a --tag value with the tags in use.
+func completeTags(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	sites, _ := site.List()
+	var tags []string
+	for _, s := range sites {
+		for _, tag := range s.Tags {
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	slices.Sort(tags)
+	return tags, cobra.ShellCompDirectiveNoFileComp
+}
+
+// sitesWithTag returns the sites carrying tag.
+func sitesWithTag(sites []site.Site, tag string) []site.Site {
+	var out []site.Site
+	for _, s := range sites {
+		if slices.Contains(s.Tags, tag) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
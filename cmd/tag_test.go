@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stubbedev/srv/internal/site"
+)
+
+func TestTagCommands(t *testing.T) {
+	root := setupSrvRoot(t)
+	for _, name := range []string{"api", "blog"} {
+		projectDir := filepath.Join(root, name)
+		if err := os.MkdirAll(projectDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		writeTestSite(t, name, site.SiteMetadata{
+			Type:        site.SiteTypeStatic,
+			Domains:     []string{name + ".test"},
+			ProjectPath: projectDir,
+			Port:        80,
+			NetworkName: "n",
+		})
+	}
+	t.Cleanup(func() { listFlags.tag = "" })
+
+	executeRoot(t, "tag", "add", "api", "production", "team-a")
+	executeRoot(t, "tag", "add", "blog", "production")
+	if err := runTagAdd(nil, []string{"blog", "Bad_Tag"}); err == nil {
+		t.Error("expected an error for an invalid tag")
+	}
+
+	var tags []tagSites
+	runJSONCommand(t, &tags, "tag", "list", "--json")
+	if len(tags) != 2 || tags[0].Tag != "production" || len(tags[0].Sites) != 2 || tags[1].Tag != "team-a" {
+		t.Errorf("tag list = %+v", tags)
+	}
+
+	executeRoot(t, "tag", "remove", "blog", "production")
+	var views []site.SiteView
+	runJSONCommand(t, &views, "list", "--tag", "production", "--json")
+	if len(views) != 1 || views[0].Name != "api" || len(views[0].Tags) != 2 {
+		t.Errorf("list --tag production = %+v", views)
+	}
+}
@@ -0,0 +1,215 @@
+// Package cmd — traefik.go implements `srv traefik`, commands for the shared
+// Traefik container itself rather than any one site: `srv traefik logs`
+// shows its container logs, `srv traefik dashboard` points at its dashboard
+// and `srv traefik config show` prints its static config.
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/docker"
+	"github.com/stubbedev/srv/internal/traefik"
+	"github.com/stubbedev/srv/internal/ui"
+)
+
+var traefikCmd = &cobra.Command{
+	Use:   "traefik",
+	Short: "Inspect the Traefik reverse proxy",
+}
+
+var traefikLogsFlags struct {
+	follow bool
+	tail   string
+	since  string
+}
+
+var traefikLogsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Show Traefik's container logs",
+	Long: `Show the Traefik container's own logs (docker compose logs traefik):
+router and certificate errors, config reloads and ACME activity. For the
+requests a site served, use 'srv logs SITE --access'.
+
+Examples:
+  srv traefik logs --tail 100
+  srv traefik logs -f --since 10m`,
+	Args: cobra.NoArgs,
+	RunE: runTraefikLogs,
+}
+
+var traefikDashboardFlags struct {
+	open bool
+}
+
+var traefikDashboardCmd = &cobra.Command{
+	Use:   "dashboard",
+	Short: "Print the Traefik dashboard URL",
+	Long: `Print the URL of Traefik's dashboard, which lists every router, service
+and middleware Traefik has loaded. --open opens it in the default browser.
+
+Examples:
+  srv traefik dashboard
+  srv traefik dashboard --open`,
+	Args: cobra.NoArgs,
+	RunE: runTraefikDashboard,
+}
+
+var traefikConfigShowFlags struct {
+	diff bool
+}
+
+var traefikConfigCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect Traefik's static configuration",
+}
+
+var traefikConfigShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the static traefik.yml Traefik runs with",
+	Long: `Print the static traefik.yml, the result of merging your edits into srv's
+template, with keys, values and comments coloured.
+
+--diff shows the file side by side with the one a fresh install would write,
+then lists each top-level section: customized and added sections are kept by
+'srv install', overwritten ones are sections srv owns (providers,
+certificatesResolvers and the web/websecure entry points), where edits are
+replaced on the next install.
+
+Examples:
+  srv traefik config show
+  srv traefik config show --diff`,
+	Args: cobra.NoArgs,
+	RunE: runTraefikConfigShow,
+}
+
+func init() {
+	traefikLogsCmd.Flags().BoolVarP(&traefikLogsFlags.follow, "follow", "f", false, "Follow log output")
+	traefikLogsCmd.Flags().StringVar(&traefikLogsFlags.tail, "tail", "", "Number of lines to show from the end")
+	traefikLogsCmd.Flags().StringVar(&traefikLogsFlags.since, "since", "", "Show logs since timestamp (e.g., 10m, 1h)")
+	traefikDashboardCmd.Flags().BoolVar(&traefikDashboardFlags.open, "open", false, "Open the dashboard in the default browser")
+	traefikConfigShowCmd.Flags().BoolVar(&traefikConfigShowFlags.diff, "diff", false, "Compare with the traefik.yml a fresh install would write")
+	traefikConfigCmd.AddCommand(traefikConfigShowCmd)
+	traefikCmd.GroupID = GroupSystem
+	traefikCmd.AddCommand(traefikLogsCmd, traefikDashboardCmd, traefikConfigCmd)
+	RootCmd.AddCommand(traefikCmd)
+}
+
+func runTraefikLogs(cmd *cobra.Command, args []string) error {
+	if err := docker.EnsureRunning(); err != nil {
+		return err
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	composeArgs := []string{"logs"}
+	if traefikLogsFlags.follow {
+		composeArgs = append(composeArgs, "-f")
+	}
+	if traefikLogsFlags.tail != "" {
+		composeArgs = append(composeArgs, "--tail", traefikLogsFlags.tail)
+	}
+	if traefikLogsFlags.since != "" {
+		composeArgs = append(composeArgs, "--since", traefikLogsFlags.since)
+	}
+	return docker.Compose(cfg.TraefikDir, append(composeArgs, "traefik")...)
+}
+
+func runTraefikDashboard(cmd *cobra.Command, args []string) error {
+	url := traefik.DashboardURL()
+	ui.Print("%s", url)
+	if !traefikDashboardFlags.open {
+		return nil
+	}
+	if !traefik.IsRunning() {
+		ui.Warn("Traefik is not running — run 'srv doctor --fix' to start it")
+	}
+	ui.Dim("Opening %s...", url)
+	return openURL(url)
+}
+
+func runTraefikConfigShow(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	path := traefik.StaticConfigPath(cfg)
+	stored, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%s not found; run 'srv install' to create it", path)
+	}
+	if err != nil {
+		return err
+	}
+	if !traefikConfigShowFlags.diff {
+		for _, line := range strings.Split(strings.TrimRight(string(stored), "\n"), "\n") {
+			ui.Print("%s", highlightYAML(line))
+		}
+		return nil
+	}
+
+	fresh, err := traefik.FreshStaticConfig(cfg)
+	if err != nil {
+		return err
+	}
+	sections, err := traefik.CompareStaticConfig(stored, fresh)
+	if err != nil {
+		return err
+	}
+	ui.Print("%s", strings.TrimRight(ui.RenderSideBySide("stored traefik.yml", "fresh install",
+		strings.Split(strings.TrimRight(string(stored), "\n"), "\n"),
+		strings.Split(strings.TrimRight(string(fresh), "\n"), "\n")), "\n"))
+	ui.Blank()
+	rows := make([][]string, 0, len(sections))
+	for _, s := range sections {
+		rows = append(rows, sectionRow(s))
+	}
+	ui.PrintTable([]string{"SECTION", "STATUS", "ON REINSTALL"}, rows)
+	return nil
+}
+
+// sectionRow renders one traefik.yml section for the --diff summary.
+func sectionRow(s traefik.SectionDiff) []string {
+	status := string(s.Status)
+	var note string
+	switch s.Status {
+	case traefik.SectionCustomized, traefik.SectionAdded:
+		status, note = ui.InfoText(status), "kept"
+	case traefik.SectionOverwritten:
+		status, note = ui.WarnText(status), "your edits are replaced by srv's template"
+	case traefik.SectionMissing:
+		status, note = ui.DimText(status), "restored from the template"
+	default:
+		note = ui.DimText("unchanged")
+	}
+	return []string{s.Key, status, note}
+}
+
+// highlightYAML colours one line of YAML: comments dim, keys blue, values
+// plain.
+func highlightYAML(line string) string {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" {
+		return line
+	}
+	if strings.HasPrefix(trimmed, "#") {
+		return ui.DimText(line)
+	}
+	indent := line[:len(line)-len(strings.TrimLeft(line, " "))]
+	rest := line[len(indent):]
+	if item, ok := strings.CutPrefix(rest, "- "); ok {
+		indent += "- "
+		rest = item
+	}
+	key, value, ok := strings.Cut(rest, ":")
+	if !ok || strings.ContainsAny(key, "\"' ") || (value != "" && !strings.HasPrefix(value, " ")) {
+		return line
+	}
+	return indent + ui.InfoText(key) + ":" + value
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stubbedev/srv/internal/docker"
+	"github.com/stubbedev/srv/internal/traefik"
+)
+
+func TestTraefikLogs(t *testing.T) {
+	setupSrvRoot(t)
+	cfg := mustLoadConfig(t)
+	t.Cleanup(docker.SwapNewClientOK())
+	var gotDir string
+	var gotArgs []string
+	t.Cleanup(docker.SwapComposeExec(func(dir string, quiet bool, args ...string) error {
+		gotDir, gotArgs = dir, args
+		return nil
+	}))
+	t.Cleanup(func() {
+		traefikLogsFlags.follow, traefikLogsFlags.tail, traefikLogsFlags.since = false, "", ""
+	})
+
+	executeRoot(t, "traefik", "logs", "-f", "--tail", "50", "--since", "10m")
+	if gotDir != cfg.TraefikDir {
+		t.Errorf("compose dir = %q, want %q", gotDir, cfg.TraefikDir)
+	}
+	want := []string{"logs", "-f", "--tail", "50", "--since", "10m", "traefik"}
+	if !slices.Equal(gotArgs, want) {
+		t.Errorf("compose args = %v, want %v", gotArgs, want)
+	}
+}
+
+func TestTraefikDashboard(t *testing.T) {
+	setupSrvRoot(t)
+	var opened []string
+	prev := openURL
+	openURL = func(url string) error { opened = append(opened, url); return nil }
+	t.Cleanup(func() { openURL = prev; traefikDashboardFlags.open = false })
+
+	stdout, _ := executeRoot(t, "traefik", "dashboard")
+	if strings.TrimSpace(stdout) != traefik.DashboardURL() {
+		t.Errorf("stdout = %q, want %q", stdout, traefik.DashboardURL())
+	}
+	if len(opened) != 0 {
+		t.Errorf("opened %v without --open", opened)
+	}
+
+	executeRoot(t, "traefik", "dashboard", "--open")
+	if !slices.Equal(opened, []string{traefik.DashboardURL()}) {
+		t.Errorf("opened = %v", opened)
+	}
+}
+
+func TestTraefikConfigShow(t *testing.T) {
+	setupSrvRoot(t)
+	cfg := mustLoadConfig(t)
+	t.Cleanup(func() { traefikConfigShowFlags.diff = false })
+
+	fresh, err := traefik.FreshStaticConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored := strings.Replace(string(fresh), "level: INFO", "level: DEBUG", 1)
+	stored = strings.Replace(stored, "watch: true", "watch: false", 1)
+	if err := os.MkdirAll(cfg.TraefikConfDir(), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(traefik.StaticConfigPath(cfg), []byte(stored), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, _ := executeRoot(t, "traefik", "config", "show")
+	if !strings.Contains(stdout, "level: DEBUG") {
+		t.Errorf("show output missing stored content:\n%s", stdout)
+	}
+
+	stdout, _ = executeRoot(t, "traefik", "config", "show", "--diff")
+	for _, want := range []string{"fresh install", "level: INFO", "customized", "overwritten"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("--diff output missing %q:\n%s", want, stdout)
+		}
+	}
+}
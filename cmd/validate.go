@@ -1,5 +1,6 @@
-// Package cmd — validate.go implements `srv validate` which parses + checks
-// a site's metadata.yml without applying anything.
+// Package cmd — validate.go implements `srv validate` which checks a site's
+// metadata.yml, compose file, and generated Traefik / nginx configs without
+// applying or starting anything.
 package cmd
 
 import (
@@ -17,8 +18,17 @@ var validateFlags struct {
 
 var validateCmd = &cobra.Command{
 	Use:   "validate [SITE]",
-	Short: "Validate a site's metadata.yml without applying changes",
-	RunE:  runValidate,
+	Short: "Validate a site's configuration without applying changes",
+	Long: `Check a site's configuration without starting anything:
+
+  - metadata.yml structure
+  - compose file syntax, then 'docker compose config' (when docker is installed)
+  - generated Traefik configs (site and extra routes)
+  - a static site's nginx.conf via 'nginx -t' (when nginx is installed)
+
+Each problem is reported as FILE:LINE: MESSAGE. Use --format json for a
+machine-readable report.`,
+	RunE: runValidate,
 	Args: func(cmd *cobra.Command, args []string) error {
 		if validateFlags.all {
 			return cobra.NoArgs(cmd, args)
@@ -39,6 +49,14 @@ func init() {
 	RootCmd.AddCommand(validateCmd)
 }
 
+// validateReport is the json shape for one site under `srv validate --format json`.
+type validateReport struct {
+	Site   string             `json:"site"`
+	OK     bool               `json:"ok"`
+	Issues []site.ConfigIssue `json:"issues,omitempty"`
+	Error  string             `json:"error,omitempty"`
+}
+
 func runValidate(cmd *cobra.Command, args []string) error {
 	var names []string
 	if validateFlags.all {
@@ -48,27 +66,42 @@ func runValidate(cmd *cobra.Command, args []string) error {
 	}
 
 	failed := 0
+	reports := make([]validateReport, 0, len(names))
 	for _, name := range names {
-		if err := validateOne(name); err != nil {
-			ui.Warn("%s: %v", name, err)
+		report := validateReport{Site: name}
+		issues, err := site.CheckSiteConfig(name)
+		switch {
+		case err != nil:
+			report.Error = err.Error()
+			if !jsonOutput() {
+				ui.Warn("%s: %v", name, err)
+			}
+		case len(issues) > 0:
+			report.Issues = issues
+			if !jsonOutput() {
+				ui.Warn("%s: %d issue(s)", name, len(issues))
+				for _, issue := range issues {
+					ui.IndentedError(1, "%s", issue)
+				}
+			}
+		default:
+			report.OK = true
+			if !jsonOutput() {
+				ui.Success("%s: ok", name)
+			}
+		}
+		if !report.OK {
 			failed++
-			continue
 		}
-		ui.Success("%s: ok", name)
+		reports = append(reports, report)
+	}
+	if jsonOutput() {
+		if err := ui.PrintJSON(reports); err != nil {
+			return err
+		}
 	}
 	if failed > 0 {
 		return fmt.Errorf("%d site(s) failed validation", failed)
 	}
 	return nil
 }
-
-func validateOne(name string) error {
-	meta, err := site.ReadSiteMetadata(name)
-	if err != nil {
-		return err
-	}
-	if meta == nil {
-		return fmt.Errorf("site not found")
-	}
-	return site.ValidateMetadata(meta)
-}
@@ -8,7 +8,7 @@ import (
 
 func TestValidateOneMissingSite(t *testing.T) {
 	setupSrvRoot(t)
-	if err := validateOne("ghost"); err == nil {
+	if _, err := site.CheckSiteConfig("ghost"); err == nil {
 		t.Error("expected err")
 	}
 }
@@ -22,8 +22,8 @@ func TestValidateOneOK(t *testing.T) {
 		Port:        80,
 		NetworkName: "n",
 	})
-	if err := validateOne("blog"); err != nil {
-		t.Errorf("err: %v", err)
+	if issues, err := site.CheckSiteConfig("blog"); err != nil || len(issues) > 0 {
+		t.Errorf("err: %v, issues: %v", err, issues)
 	}
 }
 
@@ -36,8 +36,8 @@ func TestValidateOneInvalid(t *testing.T) {
 		Port:        80,
 		NetworkName: "n",
 	})
-	if err := validateOne("bad"); err == nil {
-		t.Error("expected err")
+	if issues, err := site.CheckSiteConfig("bad"); err != nil || len(issues) == 0 {
+		t.Errorf("expected issues, got %v (err %v)", issues, err)
 	}
 }
 
@@ -0,0 +1,175 @@
+// Package cmd — version_check.go backs `srv version --check`: it asks the
+// GitHub releases API for the latest srv release, caching the answer under
+// the srv root for a day, and compares it with the running version.
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/constants"
+	"github.com/stubbedev/srv/internal/fsutil"
+)
+
+// releasesPageURL is where users download a release.
+const releasesPageURL = "https://github.com/stubbedev/srv/releases/latest"
+
+// latestReleaseURL is the GitHub API endpoint for the latest release. Tests
+// swap it.
+var latestReleaseURL = "https://api.github.com/repos/stubbedev/srv/releases/latest"
+
+// updateCheckTTL is how long a cached latest release is trusted.
+const updateCheckTTL = 24 * time.Hour
+
+// updateCheckTimeout bounds the request to the GitHub API.
+const updateCheckTimeout = 10 * time.Second
+
+// updateCheck is the cached answer of the last release lookup.
+type updateCheck struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Latest    string    `json:"latest"`
+}
+
+// latestRelease returns the tag of the latest srv release, from the cache
+// when it is less than a day old and from the GitHub API otherwise.
+func latestRelease() (string, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return "", err
+	}
+	cachePath := filepath.Join(cfg.Root, constants.UpdateCheckFile)
+	if data, err := os.ReadFile(cachePath); err == nil {
+		var cached updateCheck
+		if json.Unmarshal(data, &cached) == nil && cached.Latest != "" && time.Since(cached.CheckedAt) < updateCheckTTL {
+			return cached.Latest, nil
+		}
+	}
+
+	latest, err := fetchLatestRelease()
+	if err != nil {
+		return "", err
+	}
+	// A failed cache write only costs another API call next time.
+	if data, err := json.Marshal(updateCheck{CheckedAt: time.Now(), Latest: latest}); err == nil {
+		_ = fsutil.AtomicWriteFile(cachePath, data, constants.FilePermDefault)
+	}
+	return latest, nil
+}
+
+// fetchLatestRelease reads tag_name from the GitHub latest-release endpoint.
+func fetchLatestRelease() (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), updateCheckTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, latestReleaseURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GitHub API answered %s", resp.Status)
+	}
+	var release struct {
+		TagName string `json:"tag_name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return "", fmt.Errorf("read GitHub API response: %w", err)
+	}
+	if release.TagName == "" {
+		return "", fmt.Errorf("GitHub API response has no tag_name")
+	}
+	return release.TagName, nil
+}
+
+// isNewerVersion reports whether semantic version latest is newer than
+// current. Both may carry a leading v.
+func isNewerVersion(latest, current string) (bool, error) {
+	l, err := parseSemver(latest)
+	if err != nil {
+		return false, err
+	}
+	c, err := parseSemver(current)
+	if err != nil {
+		return false, err
+	}
+	for i := range 3 {
+		if l.core[i] != c.core[i] {
+			return l.core[i] > c.core[i], nil
+		}
+	}
+	// A release outranks its pre-releases (v1.2.0 > v1.2.0-rc.1).
+	switch {
+	case l.pre == c.pre:
+		return false, nil
+	case l.pre == "":
+		return true, nil
+	case c.pre == "":
+		return false, nil
+	}
+	return comparePrerelease(l.pre, c.pre) > 0, nil
+}
+
+// semver is a parsed MAJOR.MINOR.PATCH[-PRERELEASE] version; build metadata
+// is dropped.
+type semver struct {
+	core [3]int
+	pre  string
+}
+
+func parseSemver(v string) (semver, error) {
+	s := strings.TrimPrefix(strings.TrimSpace(v), "v")
+	s, _, _ = strings.Cut(s, "+")
+	core, pre, _ := strings.Cut(s, "-")
+	parts := strings.Split(core, ".")
+	if len(parts) > 3 || parts[0] == "" {
+		return semver{}, fmt.Errorf("invalid version %q", v)
+	}
+	var out semver
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return semver{}, fmt.Errorf("invalid version %q", v)
+		}
+		out.core[i] = n
+	}
+	out.pre = pre
+	return out, nil
+}
+
+// comparePrerelease orders pre-release identifiers the semver way: dot
+// separated, numeric ones numerically and below alphanumeric ones, and a
+// shorter list first when it is a prefix of the other.
+func comparePrerelease(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				return an - bn
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return len(as) - len(bs)
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stubbedev/srv/internal/constants"
+)
+
+func TestIsNewerVersion(t *testing.T) {
+	cases := []struct {
+		latest, current string
+		want            bool
+	}{
+		{"v1.3.0", "v1.2.9", true},
+		{"v1.2.10", "1.2.9", true},
+		{"v2.0.0", "v1.99.0", true},
+		{"v1.2.0", "v1.2.0", false},
+		{"v1.2.0", "v1.3.0", false},
+		{"v1.2.0", "v1.2.0-rc.1", true},
+		{"v1.2.0-rc.2", "v1.2.0-rc.10", false},
+		{"v1.2.0-rc.1", "v1.2.0", false},
+		{"v1.2.0+build.5", "v1.2.0", false},
+	}
+	for _, tc := range cases {
+		got, err := isNewerVersion(tc.latest, tc.current)
+		if err != nil || got != tc.want {
+			t.Errorf("isNewerVersion(%q, %q) = %v, %v; want %v", tc.latest, tc.current, got, err, tc.want)
+		}
+	}
+	if _, err := isNewerVersion("latest", "v1.0.0"); err == nil {
+		t.Error("expected error for a non-semver tag")
+	}
+}
+
+func TestVersionCheck(t *testing.T) {
+	root := setupSrvRoot(t)
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		fmt.Fprint(w, `{"tag_name":"v1.5.0"}`)
+	}))
+	t.Cleanup(srv.Close)
+	prevURL, prevVersion := latestReleaseURL, Version
+	t.Cleanup(func() { latestReleaseURL, Version, versionFlags.check = prevURL, prevVersion, false })
+	latestReleaseURL = srv.URL
+	Version = "v1.4.2"
+
+	var info versionInfo
+	runJSONCommand(t, &info, "version", "--check", "--json")
+	if info.Latest != "v1.5.0" || !info.UpdateAvailable {
+		t.Errorf("info = %+v, want v1.5.0 available", info)
+	}
+	if _, err := os.Stat(filepath.Join(root, constants.UpdateCheckFile)); err != nil {
+		t.Errorf("cache not written: %v", err)
+	}
+
+	// The second check is answered from the cache.
+	Version = "v1.5.0"
+	info = versionInfo{}
+	runJSONCommand(t, &info, "version", "--check", "--json")
+	if info.UpdateAvailable || hits != 1 {
+		t.Errorf("info = %+v after %d API hits, want up to date from the cache", info, hits)
+	}
+
+	// Development builds skip the check.
+	Version = constants.DefaultVersion
+	info = versionInfo{}
+	runJSONCommand(t, &info, "version", "--check", "--json")
+	if info.Latest != "" || hits != 1 {
+		t.Errorf("dev build checked for updates: %+v", info)
+	}
+}
@@ -127,7 +127,7 @@ func NewRoot(t *testing.T) string {
 func TraefikUp(t *testing.T, root string) {
 	t.Helper()
 
-	if err := traefik.EnsureConfig(""); err != nil {
+	if _, err := traefik.EnsureConfig(""); err != nil {
 		t.Fatalf("traefik.EnsureConfig: %v", err)
 	}
 
@@ -0,0 +1,378 @@
+// Package backup snapshots the srv config directory into a .tar.gz for
+// `srv backup` and restores it for `srv restore`. Archives carry a manifest
+// with the srv version that wrote them and a checksum of every file, which
+// restore verifies before touching the config dir.
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stubbedev/srv/internal/constants"
+	"github.com/stubbedev/srv/internal/daemon"
+)
+
+// ManifestFile is the manifest's name inside the archive.
+const ManifestFile = "srv-backup.json"
+
+// Manifest describes a backup archive.
+type Manifest struct {
+	Version   string    `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Files     int       `json:"files"`
+	// Checksum is the sha256 over every archived file's path and content,
+	// in path order.
+	Checksum string `json:"checksum"`
+}
+
+// excluded lists config-dir paths (slash-separated, relative to the root)
+// that are never archived, and that restore leaves in place: logs, runtime
+// state such as the running daemon's PID file and the update-check cache,
+// and the ACME stores, whose certificates and account keys belong to this
+// host's domains.
+var excluded = []string{
+	constants.LogsSubdir,
+	daemon.LogFile,
+	daemon.PidFile,
+	constants.UpdateCheckFile,
+	path.Join(constants.TraefikSubdir, constants.LogsSubdir),
+	path.Join(constants.TraefikSubdir, constants.CertsSubdir, constants.ACMEJSONFile),
+	path.Join(constants.TraefikSubdir, constants.CertsSubdir, constants.ACMEStagingJSONFile),
+}
+
+// isExcluded reports whether rel (slash-separated) is or lies under an
+// excluded path.
+func isExcluded(rel string) bool {
+	for _, ex := range excluded {
+		if rel == ex || strings.HasPrefix(rel, ex+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Create archives root into out, writing to a temp file beside out and
+// renaming it into place once complete. out must lie outside root.
+func Create(root, out, version string) (*Manifest, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	absOut, err := filepath.Abs(out)
+	if err != nil {
+		return nil, err
+	}
+	if rel, err := filepath.Rel(absRoot, absOut); err == nil && !strings.HasPrefix(rel, "..") {
+		return nil, fmt.Errorf("backup file %s must be outside the config directory %s", out, root)
+	}
+	files, err := listFiles(absRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(absOut), ".srv-backup-*"+constants.ExtTmp)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	manifest, err := writeArchive(tmp, absRoot, files, version)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), absOut); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// listFiles returns the slash-separated paths of the regular files under
+// root that belong in a backup, sorted.
+func listFiles(root string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == root {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if isExcluded(rel) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// Sockets, symlinks and in-flight temp files aren't config.
+		if d.Type().IsRegular() && !strings.HasSuffix(rel, constants.ExtTmp) {
+			files = append(files, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan %s: %w", root, err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// writeArchive writes files (relative to root) and then the manifest as a
+// gzipped tar to w.
+func writeArchive(w io.Writer, root string, files []string, version string) (*Manifest, error) {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	sum := sha256.New()
+	for _, rel := range files {
+		if err := addFile(tw, sum, root, rel); err != nil {
+			return nil, err
+		}
+	}
+
+	manifest := &Manifest{
+		Version:   version,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+		Files:     len(files),
+		Checksum:  hex.EncodeToString(sum.Sum(nil)),
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	hdr := &tar.Header{Name: ManifestFile, Mode: 0o644, Size: int64(len(data)), ModTime: manifest.CreatedAt, Typeflag: tar.TypeReg}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return nil, err
+	}
+	if _, err := tw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// addFile appends one file to the archive and the running checksum.
+func addFile(tw *tar.Writer, sum io.Writer, root, rel string) error {
+	f, err := os.Open(filepath.Join(root, filepath.FromSlash(rel))) //nolint:gosec // walking srv's own config dir
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	hdr := &tar.Header{
+		Name:     rel,
+		Mode:     int64(info.Mode().Perm()),
+		Size:     info.Size(),
+		ModTime:  info.ModTime(),
+		Typeflag: tar.TypeReg,
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tw, h), f); err != nil {
+		return fmt.Errorf("archive %s: %w", rel, err)
+	}
+	writeChecksumEntry(sum, rel, h.Sum(nil))
+	return nil
+}
+
+// writeChecksumEntry folds one file into the archive checksum.
+func writeChecksumEntry(sum io.Writer, rel string, fileSum []byte) {
+	_, _ = io.WriteString(sum, rel+"\x00"+hex.EncodeToString(fileSum)+"\n")
+}
+
+// Staged is a verified backup extracted next to the config dir, ready to
+// Apply. Call Cleanup when done with it.
+type Staged struct {
+	Dir      string
+	Manifest *Manifest
+	files    []string
+}
+
+// Cleanup removes the staging directory.
+func (s *Staged) Cleanup() {
+	_ = os.RemoveAll(s.Dir)
+}
+
+// Extract unpacks the archive at in into a staging directory beside root
+// (so Apply can rename files into place) and verifies it against its
+// manifest. Nothing under root is changed.
+func Extract(in, root string) (*Staged, error) {
+	f, err := os.Open(in) //nolint:gosec // user-named backup file
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	parent := filepath.Dir(filepath.Clean(root))
+	if err := os.MkdirAll(parent, constants.DirPermDefault); err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp(parent, ".srv-restore-")
+	if err != nil {
+		return nil, err
+	}
+	staged := &Staged{Dir: dir}
+	if err := staged.extract(f); err != nil {
+		staged.Cleanup()
+		return nil, fmt.Errorf("%s: %w", in, err)
+	}
+	return staged, nil
+}
+
+func (s *Staged) extract(r io.Reader) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("not a gzip archive: %w", err)
+	}
+	defer func() { _ = gz.Close() }()
+
+	sums := map[string][]byte{}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("read archive: %w", err)
+		}
+		if hdr.Name == ManifestFile {
+			var m Manifest
+			if err := json.NewDecoder(tr).Decode(&m); err != nil {
+				return fmt.Errorf("invalid manifest: %w", err)
+			}
+			s.Manifest = &m
+			continue
+		}
+		if hdr.Typeflag == tar.TypeDir {
+			continue
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			return fmt.Errorf("unsupported entry %q", hdr.Name)
+		}
+		rel := path.Clean(hdr.Name)
+		if !fs.ValidPath(rel) || rel == "." {
+			return fmt.Errorf("unsafe path %q", hdr.Name)
+		}
+		if isExcluded(rel) {
+			continue
+		}
+		fileSum, err := s.writeFile(rel, fs.FileMode(hdr.Mode).Perm(), tr)
+		if err != nil {
+			return err
+		}
+		sums[rel] = fileSum
+		s.files = append(s.files, rel)
+	}
+
+	if s.Manifest == nil {
+		return fmt.Errorf("no %s manifest — not an srv backup", ManifestFile)
+	}
+	sort.Strings(s.files)
+	sum := sha256.New()
+	for _, rel := range s.files {
+		writeChecksumEntry(sum, rel, sums[rel])
+	}
+	if got := hex.EncodeToString(sum.Sum(nil)); got != s.Manifest.Checksum || len(s.files) != s.Manifest.Files {
+		return errors.New("checksum mismatch — the archive is corrupt or was modified")
+	}
+	return nil
+}
+
+// writeFile writes one archive entry under the staging dir and returns its
+// sha256.
+func (s *Staged) writeFile(rel string, perm fs.FileMode, r io.Reader) ([]byte, error) {
+	dst := filepath.Join(s.Dir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(dst), constants.DirPermDefault); err != nil {
+		return nil, err
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, perm) //nolint:gosec // dst is validated to stay in the staging dir
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(out, h), r)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("extract %s: %w", rel, err)
+	}
+	return h.Sum(nil), nil
+}
+
+// Apply makes root match the staged backup: every archived file is renamed
+// into place, and files the backup doesn't have are removed, except the
+// excluded ones. Files are replaced one by one rather than swapping the whole
+// directory so Traefik's bind mounts of the conf and certs dirs stay valid.
+func (s *Staged) Apply(root string) error {
+	root = filepath.Clean(root)
+	keep := make(map[string]bool, len(s.files))
+	for _, rel := range s.files {
+		keep[rel] = true
+		src := filepath.Join(s.Dir, filepath.FromSlash(rel))
+		dst := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(dst), constants.DirPermDefault); err != nil {
+			return err
+		}
+		if err := os.Rename(src, dst); err != nil {
+			return fmt.Errorf("restore %s: %w", rel, err)
+		}
+	}
+
+	current, err := listFiles(root)
+	if err != nil {
+		return err
+	}
+	for _, rel := range current {
+		if keep[rel] {
+			continue
+		}
+		dst := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.Remove(dst); err != nil {
+			return fmt.Errorf("remove %s: %w", rel, err)
+		}
+		removeEmptyParents(root, filepath.Dir(dst))
+	}
+	return nil
+}
+
+// removeEmptyParents deletes dir and its parents up to (not including) root
+// while they're empty, e.g. the config dir of a site the backup doesn't have.
+func removeEmptyParents(root, dir string) {
+	for dir != root && strings.HasPrefix(dir, root) {
+		if os.Remove(dir) != nil { // fails unless empty
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
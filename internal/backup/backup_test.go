@@ -0,0 +1,172 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func readFile(t *testing.T, p string) string {
+	t.Helper()
+	data, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestCreateRestoreRoundTrip(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "srv")
+	writeFiles(t, root, map[string]string{
+		"config.yml":                      "local_tlds: []\n",
+		"sites/blog/metadata.yml":         "type: static\n",
+		"traefik/conf/traefik.yml":        "api: {}\n",
+		"traefik/certs/acme.json":         `{"old":true}`,
+		"traefik/logs/access.log":         "old log\n",
+		"daemon.log":                      "old daemon log\n",
+		"daemon.pid":                      "100\n",
+		"update-check.json":               "{}",
+		"traefik/conf/routes.yml.tmp":     "in flight",
+		"local-domains.txt":               "blog.test\n",
+		"proxies/api/metadata.yml":        "port: 3000\n",
+		"sites/blog/env-override.env":     "APP_DEBUG=true\n",
+		"traefik/certs/blog.test.crt":     "cert",
+		"traefik/conf/sites/blog.yml":     "http: {}\n",
+		"sites/blog/docker-compose.yml":   "services: {}\n",
+		"traefik/docker-compose.yml":      "services: {}\n",
+		"traefik/certs/acme-staging.json": "{}",
+	})
+	out := filepath.Join(t.TempDir(), "backup.tar.gz")
+	m, err := Create(root, out, "1.2.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Version != "1.2.3" || m.Files != 10 || m.Checksum == "" {
+		t.Errorf("manifest = %+v", m)
+	}
+
+	// Drift after the backup: an edit, a new site, a fresh log and cert store.
+	writeFiles(t, root, map[string]string{
+		"config.yml":              "changed\n",
+		"sites/new/metadata.yml":  "type: static\n",
+		"traefik/logs/access.log": "new log\n",
+		"traefik/certs/acme.json": `{"new":true}`,
+		"daemon.pid":              "4242\n",
+	})
+
+	staged, err := Extract(out, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer staged.Cleanup()
+	if staged.Manifest.Checksum != m.Checksum {
+		t.Errorf("staged manifest = %+v", staged.Manifest)
+	}
+	if err := staged.Apply(root); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := readFile(t, filepath.Join(root, "config.yml")); got != "local_tlds: []\n" {
+		t.Errorf("config.yml = %q, want the backed-up content", got)
+	}
+	if _, err := os.Stat(filepath.Join(root, "sites", "new")); !os.IsNotExist(err) {
+		t.Errorf("site added after the backup should be removed: %v", err)
+	}
+	if got := readFile(t, filepath.Join(root, "traefik/certs/acme.json")); got != `{"new":true}` {
+		t.Errorf("acme.json = %q, should be left alone", got)
+	}
+	if got := readFile(t, filepath.Join(root, "traefik/logs/access.log")); got != "new log\n" {
+		t.Errorf("access.log = %q, should be left alone", got)
+	}
+	if got := readFile(t, filepath.Join(root, "daemon.pid")); got != "4242\n" {
+		t.Errorf("daemon.pid = %q, the running daemon's PID file should be left alone", got)
+	}
+	info, err := os.Stat(filepath.Join(root, "sites/blog/env-override.env"))
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("env-override.env mode = %v, %v; want 0600 kept", info, err)
+	}
+}
+
+func TestCreateRejectsOutputInsideRoot(t *testing.T) {
+	root := t.TempDir()
+	if _, err := Create(root, filepath.Join(root, "b.tar.gz"), "dev"); err == nil {
+		t.Error("expected err")
+	}
+}
+
+// writeArchiveEntries writes a gzipped tar with the given entries as-is.
+func writeArchiveEntries(t *testing.T, entries map[string]string) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), "b.tar.gz")
+	f, err := os.Create(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for name, content := range entries {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		_, _ = tw.Write([]byte(content))
+	}
+	_ = tw.Close()
+	_ = gz.Close()
+	_ = f.Close()
+	return p
+}
+
+func TestExtractRejectsBadArchives(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "srv")
+	writeFiles(t, root, map[string]string{"config.yml": "a\n"})
+	good := filepath.Join(t.TempDir(), "good.tar.gz")
+	m, err := Create(root, good, "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := `{"version":"dev","files":1,"checksum":"` + m.Checksum + `"}`
+
+	cases := map[string]string{
+		"no manifest": writeArchiveEntries(t, map[string]string{"config.yml": "a\n"}),
+		"tampered":    writeArchiveEntries(t, map[string]string{"config.yml": "b\n", ManifestFile: manifest}),
+		"unsafe path": writeArchiveEntries(t, map[string]string{"../escape": "x", ManifestFile: manifest}),
+	}
+	for name, archive := range cases {
+		staged, err := Extract(archive, root)
+		if err == nil {
+			staged.Cleanup()
+			t.Errorf("%s: expected err", name)
+		}
+	}
+	if got := readFile(t, filepath.Join(root, "config.yml")); got != "a\n" {
+		t.Errorf("config.yml changed to %q by a rejected restore", got)
+	}
+	if entries, _ := filepath.Glob(filepath.Join(filepath.Dir(root), ".srv-restore-*")); len(entries) != 0 {
+		t.Errorf("staging dirs left behind: %v", entries)
+	}
+
+	if _, err := Extract(filepath.Join(t.TempDir(), "missing.tar.gz"), root); err == nil {
+		t.Error("expected err for a missing archive")
+	}
+	notGzip := filepath.Join(t.TempDir(), "plain.tar.gz")
+	_ = os.WriteFile(notGzip, []byte(strings.Repeat("x", 64)), 0o644)
+	if _, err := Extract(notGzip, root); err == nil {
+		t.Error("expected err for a non-gzip file")
+	}
+}
@@ -26,6 +26,15 @@ type Config struct {
 type UserConfig struct {
 	ParkedPaths []string `yaml:"parked_paths,omitempty" jsonschema:"description=Directories that 'srv park' watches for new sites."`
 	UpstreamDNS []string `yaml:"upstream_dns,omitempty" jsonschema:"description=Upstream resolvers written into dnsmasq.conf. Defaults to Google DNS (8.8.8.8 8.8.4.4) when empty."`
+	LocalTLDs   []string `yaml:"local_tlds,omitempty" jsonschema:"description=Extra TLDs treated as local (mkcert + dnsmasq) in addition to test, local and localhost."`
+	// Zero values below mean "use the built-in default".
+	MaxWorkers       int    `yaml:"max_workers,omitempty" jsonschema:"description=How many sites batch operations (start/stop/pull --all) handle in parallel. Defaults to 4."`
+	CertWarningDays  int    `yaml:"cert_warning_days,omitempty" jsonschema:"description=Days before expiry a local certificate is reported as expiring. Defaults to 30."`
+	TraefikImage     string `yaml:"traefik_image,omitempty" jsonschema:"description=Docker image for the Traefik container. Defaults to traefik:latest."`
+	DNSImage         string `yaml:"dns_image,omitempty" jsonschema:"description=Docker image for the dnsmasq container. Defaults to jpillora/dnsmasq:latest."`
+	DaemonHealthPort int    `yaml:"daemon_health_port,omitempty" jsonschema:"description=Loopback port the daemon serves GET /health on. Defaults to 7777."`
+	DisableDaemon    bool   `yaml:"disable_daemon,omitempty" jsonschema:"description=Never install the daemon service during 'srv install' (CI runners, Docker-in-Docker)."`
+	ParkDepth        int    `yaml:"park_depth,omitempty" jsonschema:"description=How many directory levels below a parked path are scanned for compose projects. Defaults to 1."`
 }
 
 var (
@@ -99,6 +108,17 @@ func getSrvRoot() (string, error) {
 	return srvRoot, nil
 }
 
+// RootSource returns the environment variable that chose the srv root,
+// following getSrvRoot's priority, or "" when it is the built-in default.
+func RootSource() string {
+	for _, env := range []string{constants.EnvSrvRoot, constants.EnvXDGConfigHome} {
+		if os.Getenv(env) != "" {
+			return env
+		}
+	}
+	return ""
+}
+
 // generateNetworkName creates a unique network name based on hostname.
 // Format: {fnv64(hostname)[:12]}_traefik
 // Uses hash/fnv (non-cryptographic) instead of crypto/md5 to avoid triggering
@@ -128,6 +148,11 @@ func (c *Config) TraefikConfDir() string {
 	return filepath.Join(c.TraefikDir, constants.ConfSubdir)
 }
 
+// TraefikAccessLogPath returns the path to traefik's access log.
+func (c *Config) TraefikAccessLogPath() string {
+	return filepath.Join(c.TraefikDir, constants.LogsSubdir, constants.AccessLogFile)
+}
+
 // SiteCertsDir returns the path to a site's SSL certificates directory.
 func (c *Config) SiteCertsDir(siteName string) string {
 	return filepath.Join(c.SitesDir, siteName, constants.CertsSubdir)
@@ -180,6 +205,17 @@ func (c *Config) SaveUserConfig(userCfg *UserConfig) error {
 	return fsutil.AtomicWriteFile(configPath, append([]byte(header), data...), constants.FilePermDefault)
 }
 
+// UserSettings returns the settings in config.yml, or empty settings when it
+// can't be read, for callers that fall back to built-in defaults.
+func UserSettings() *UserConfig {
+	if cfg, err := Load(); err == nil {
+		if userCfg, err := cfg.LoadUserConfig(); err == nil {
+			return userCfg
+		}
+	}
+	return &UserConfig{}
+}
+
 // GetParkedPaths returns the list of parked directories from config.yml.
 func (c *Config) GetParkedPaths() ([]string, error) {
 	userCfg, err := c.LoadUserConfig()
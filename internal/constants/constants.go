@@ -50,6 +50,10 @@ const (
 	StatusBroken = "broken"
 	// StatusPartial indicates partial status.
 	StatusPartial = "partial"
+	// StatusParked marks a project in a parked directory that isn't a site yet.
+	StatusParked = "parked"
+	// StatusDisabled marks a site or proxy taken out of routing with `srv disable`.
+	StatusDisabled = "disabled"
 )
 
 // Container status strings.
@@ -76,6 +80,9 @@ const (
 	SpinnerTimeout = 10 * time.Minute
 	// SpinnerInterval is the animation interval for spinners.
 	SpinnerInterval = 100 * time.Millisecond
+	// DependencyStartTimeout is how long a start waits for a site's
+	// dependencies to report running before starting it anyway.
+	DependencyStartTimeout = 60 * time.Second
 )
 
 // =============================================================================
@@ -87,6 +94,8 @@ const (
 	MaxWorkers = 4
 	// MaxStatusWorkers is the maximum number of workers for status checks.
 	MaxStatusWorkers = 8
+	// ParkDepth is how many directory levels `srv park` scans for projects.
+	ParkDepth = 1
 )
 
 // =============================================================================
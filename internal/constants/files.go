@@ -44,12 +44,28 @@ const (
 	UserConfigFile = "config.yml"
 	// EnvTraefikFile is the Traefik environment file.
 	EnvTraefikFile = "env.traefik"
+	// EnvOverrideFile holds a site's `srv env` overrides, in its config dir.
+	EnvOverrideFile = "env-override.env"
+	// AccessLogFile is Traefik's access log, in its logs dir.
+	AccessLogFile = "access.log"
+	// PresetsDir holds `srv preset` middleware presets, under the srv root.
+	PresetsDir = "presets"
+	// UpdateCheckFile caches the latest release `srv version --check` found,
+	// under the srv root.
+	UpdateCheckFile = "update-check.json"
+	// StatsBaselineFile holds the counters `srv stats --reset` recorded, in a
+	// site's config dir.
+	StatsBaselineFile = "stats-baseline.json"
+	// ParkedStateFile records the projects the last park scan found.
+	ParkedStateFile = "parked.yml"
 	// LocalDomainsFile is the local domains registry file.
 	LocalDomainsFile = "local-domains.txt"
 	// RootCAFile is the mkcert root CA filename.
 	RootCAFile = "rootCA.pem"
 	// ACMEJSONFile is the ACME certificate storage file.
 	ACMEJSONFile = "acme.json"
+	// ACMEStagingJSONFile is the certificate storage for the staging resolver.
+	ACMEStagingJSONFile = "acme-staging.json"
 	// DnsmasqConfFile is the dnsmasq configuration file.
 	DnsmasqConfFile = "dnsmasq.conf"
 	// DnsmasqHostsDir is the directory dnsmasq watches (via the hostsdir=
@@ -71,6 +87,9 @@ const (
 	ExtYAML = ".yml"
 	// ExtTmp is the temporary file extension.
 	ExtTmp = ".tmp"
+	// ExtDisabled is appended to a Traefik config file to take it out of the
+	// file provider's view without deleting it.
+	ExtDisabled = ".disabled"
 )
 
 // =============================================================================
@@ -107,6 +126,9 @@ const (
 	EnvDNSHTTPUser = "DNS_HTTP_USER"
 	// EnvDNSHTTPPass is the environment variable for the dnsmasq HTTP password.
 	EnvDNSHTTPPass = "DNS_HTTP_PASS"
+	// EnvACMEDNSProvider is the environment variable for the Let's Encrypt
+	// DNS-01 challenge provider.
+	EnvACMEDNSProvider = "ACME_DNS_PROVIDER"
 	// EnvMCPHTTPAddr overrides the listen address for `srv mcp --http`.
 	EnvMCPHTTPAddr = "SRV_MCP_HTTP_ADDR"
 	// EnvMCPHTTPPath overrides the endpoint path for `srv mcp --http`.
@@ -148,6 +170,11 @@ const (
 	NetworkManagerConfigPath = "/etc/NetworkManager/dnsmasq.d/srv-local.conf"
 	// MacOSResolverDir is the macOS resolver directory.
 	MacOSResolverDir = "/etc/resolver"
+	// WSLConfPath is the per-distribution WSL settings file; srv turns off
+	// its resolv.conf generation under WSL2.
+	WSLConfPath = "/etc/wsl.conf"
+	// ResolvConfPath is the resolver config srv writes under WSL2.
+	ResolvConfPath = "/etc/resolv.conf"
 )
 
 // =============================================================================
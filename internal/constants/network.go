@@ -8,6 +8,8 @@ const (
 	// LocalhostIP is the localhost IP address.
 	LocalhostIP    = "127.0.0.1"
 	LocalhostAlias = "localhost"
+	// LocalhostIPv6 is the IPv6 loopback address.
+	LocalhostIPv6 = "::1"
 	// DockerHostInternal is the hostname for reaching the host from inside a Docker container.
 	DockerHostInternal = "host.docker.internal"
 )
@@ -29,6 +31,8 @@ const (
 	PortInternal = 88
 	// PortDNS is the DNS server port.
 	PortDNS = 53
+	// PortDaemonHealth is the loopback port the daemon serves GET /health on.
+	PortDaemonHealth = 7777
 	// PortMin is the minimum valid port number.
 	PortMin = 1
 	// PortMax is the maximum valid port number.
@@ -77,4 +81,6 @@ const (
 	MaxDomainLabelLength = 63
 	// MaxSiteNameLength is the maximum length of a site name.
 	MaxSiteNameLength = 63
+	// MaxTagLength is the maximum length of a site tag.
+	MaxTagLength = 32
 )
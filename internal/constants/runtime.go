@@ -17,10 +17,15 @@ const (
 	// it drops the perl/njs/geoip/image-filter/xslt modules but keeps the core
 	// proxy, http_ssl, and resolver directives the fallback sidecar needs.
 	ImageNginxAlpineSlim = "nginx:alpine-slim"
+	// ImageNginxBrotli is an nginx build with the brotli module, used by static
+	// sites that enable brotli compression (stock nginx:alpine lacks it).
+	ImageNginxBrotli = "fholzer/nginx-brotli:latest"
 	// NginxPort is the default nginx listen port.
 	NginxPort = 80
 	// NginxHTMLPath is the nginx static files path.
 	NginxHTMLPath = "/usr/share/nginx/html"
+	// NginxErrorPagesPath is where a static site's custom error pages are mounted.
+	NginxErrorPagesPath = "/usr/share/nginx/errors"
 	// NginxDefaultConfPath is the nginx default configuration path.
 	NginxDefaultConfPath = "/etc/nginx/conf.d/default.conf"
 	// RestartUnlessStopped is the Docker restart policy.
@@ -15,12 +15,24 @@ const (
 	// ListenerInternal is the metadata.yml `listeners` entry that maps to the
 	// internal entrypoint.
 	ListenerInternal = "internal"
+	// MaxSiteMiddlewares caps the custom Traefik middlewares a site may chain.
+	MaxSiteMiddlewares = 10
+	// MaxRetryAttempts caps the retry middleware's attempts per request.
+	MaxRetryAttempts = 10
 	// CertResolverLetsEncrypt is the Let's Encrypt certificate resolver name.
 	CertResolverLetsEncrypt = "letsencrypt"
+	// CertResolverLetsEncryptStaging issues untrusted certs from the Let's
+	// Encrypt staging CA, which has far higher rate limits.
+	CertResolverLetsEncryptStaging = "letsencrypt-staging"
 	// SiteConfigPrefix is the prefix for site configuration files.
 	SiteConfigPrefix = "site-"
 	// ProxyConfigPrefix is the prefix for proxy configuration files.
 	ProxyConfigPrefix = "proxy-"
+	// TCPProxyConfigPrefix is the prefix for TCP proxy configuration files.
+	TCPProxyConfigPrefix = "proxy-tcp-"
+	// TCPEntryPointPrefix names the Traefik entrypoint a TCP proxy listens on:
+	// tcp-<port>.
+	TCPEntryPointPrefix = "tcp-"
 	// RedirectConfigPrefix is the prefix for redirect configuration files.
 	RedirectConfigPrefix = "redirect-"
 	// RoutesConfigPrefix is the prefix for per-site extra route configuration files.
@@ -77,6 +89,9 @@ const (
 const (
 	// CertExpiryWarningDays is the number of days before expiry to show warnings.
 	CertExpiryWarningDays = 30
+	// RenewThresholdDays is the number of days before expiry at which a local
+	// certificate is renewed, on `srv start` and by the daemon.
+	RenewThresholdDays = 30
 	// HoursPerDay is the number of hours in a day.
 	HoursPerDay = 24
 )
@@ -90,6 +105,8 @@ const (
 	TypeLabelLocal = "local"
 	// TypeLabelProduction is the label for production SSL.
 	TypeLabelProduction = "production"
+	// TypeLabelStaging is the label for Let's Encrypt staging SSL.
+	TypeLabelStaging = "staging"
 )
 
 // =============================================================================
@@ -0,0 +1,97 @@
+// Package daemon — certs.go renews local (mkcert) certificates before they
+// expire. `srv start` renews a site's certificate as it starts, but a site
+// that stays up for months never passes through that path, so the daemon
+// checks every site once per CertCheckInterval.
+package daemon
+
+import (
+	"time"
+
+	"github.com/stubbedev/srv/internal/site"
+	"github.com/stubbedev/srv/internal/traefik"
+)
+
+// DefaultCertCheckInterval is how often the daemon checks local certificates
+// when neither --cert-check-interval nor daemon.yml sets it.
+const DefaultCertCheckInterval = 24 * time.Hour
+
+// MinCertCheckInterval is the shortest check interval accepted, so a typo
+// like 1s doesn't keep the daemon re-reading every certificate.
+const MinCertCheckInterval = time.Minute
+
+// Certificate seams, swapped by tests to avoid mkcert.
+var (
+	localCertInfo       = traefik.GetLocalCertInfo
+	generateLocalCert   = traefik.GenerateLocalCert
+	updateDynamicConfig = traefik.UpdateDynamicConfig
+)
+
+// startCertRenewer checks certificates once at startup and then every
+// CertCheckInterval until the daemon's context is cancelled.
+func (d *Daemon) startCertRenewer() {
+	interval := d.CertCheckInterval
+	if interval <= 0 {
+		interval = DefaultCertCheckInterval
+	}
+	d.logger.Info("Certificate renewal check every %s", interval)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			d.renewExpiringCerts()
+			select {
+			case <-d.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// renewExpiringCerts regenerates the certificate of every local-SSL site whose
+// certificate needs renewal (see traefik.CertInfo.NeedsRenewal), then
+// rewrites the Traefik dynamic config once if anything was renewed. Sites
+// without a certificate are left for `srv start` to issue; imported
+// certificates and broken sites are never touched. Returns the number of
+// certificates renewed.
+func (d *Daemon) renewExpiringCerts() int {
+	sites, err := site.ListWithoutStatus()
+	if err != nil {
+		d.logger.Warn("certificate check skipped: %v", err)
+		return 0
+	}
+	renewed := 0
+	for _, s := range sites {
+		if d.ctx.Err() != nil {
+			break
+		}
+		if !s.IsLocal || s.CustomCert || s.IsBroken || len(s.Domains) == 0 {
+			continue
+		}
+		primary := s.Domains[0]
+		cert := localCertInfo(s.Name, primary)
+		if cert.Status() == traefik.CertStatusMissing || !cert.NeedsRenewal() {
+			continue
+		}
+		log := d.logger.With("site", s.Name)
+		if err := generateLocalCert(s.Name, s.Domains, s.Wildcard); err != nil {
+			log.Error("failed to renew certificate for %s: %v", primary, err)
+			continue
+		}
+		switch {
+		case cert.Corrupt:
+			log.Info("Renewed unreadable certificate for %s", primary)
+		case cert.IsExpired:
+			log.Info("Renewed expired certificate for %s", primary)
+		default:
+			log.Info("Renewed certificate for %s (expires in %d days)", primary, cert.DaysLeft)
+		}
+		renewed++
+	}
+	if renewed > 0 {
+		if err := updateDynamicConfig(); err != nil {
+			d.logger.Error("failed to update Traefik dynamic config after renewing certificates: %v", err)
+		}
+	}
+	return renewed
+}
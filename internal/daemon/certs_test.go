@@ -0,0 +1,96 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stubbedev/srv/internal/constants"
+	"github.com/stubbedev/srv/internal/site"
+	"github.com/stubbedev/srv/internal/traefik"
+)
+
+func TestRenewExpiringCerts(t *testing.T) {
+	d, err := newDaemonForTest(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	projectDir := filepath.Join(d.cfg.Root, "p")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, local := range map[string]bool{"fresh": true, "soon": true, "expired": true, "corrupt": true, "missing": true, "imported": true, "public": false} {
+		if err := site.WriteSiteMetadata(name, site.SiteMetadata{
+			CustomCert:  name == "imported",
+			Type:        site.SiteTypeStatic,
+			Domains:     []string{name + ".test"},
+			ProjectPath: projectDir,
+			Port:        80,
+			NetworkName: "n",
+			IsLocal:     local,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	infos := map[string]traefik.CertInfo{
+		"fresh":    {Exists: true, DaysLeft: constants.RenewThresholdDays + 1},
+		"soon":     {Exists: true, DaysLeft: constants.RenewThresholdDays - 5},
+		"expired":  {Exists: true, IsExpired: true},
+		"corrupt":  {Corrupt: true},
+		"imported": {Exists: true, DaysLeft: constants.RenewThresholdDays - 5},
+		"public":   {Exists: true, IsExpired: true},
+	}
+	var renewed []string
+	updates := 0
+	prevInfo, prevGen, prevUpdate := localCertInfo, generateLocalCert, updateDynamicConfig
+	localCertInfo = func(siteName, domain string) traefik.CertInfo { return infos[siteName] }
+	generateLocalCert = func(siteName string, domains []string, wildcard bool) error {
+		renewed = append(renewed, siteName)
+		return nil
+	}
+	updateDynamicConfig = func() error { updates++; return nil }
+	t.Cleanup(func() { localCertInfo, generateLocalCert, updateDynamicConfig = prevInfo, prevGen, prevUpdate })
+
+	if n := d.renewExpiringCerts(); n != 3 {
+		t.Errorf("renewed %d certificates, want 3", n)
+	}
+	slices.Sort(renewed)
+	if !slices.Equal(renewed, []string{"corrupt", "expired", "soon"}) {
+		t.Errorf("renewed %v, want [corrupt expired soon]", renewed)
+	}
+	if slices.Contains(renewed, "imported") {
+		t.Error("an imported certificate was replaced with an mkcert one")
+	}
+	if updates != 1 {
+		t.Errorf("dynamic config updated %d times, want 1", updates)
+	}
+
+	renewed, updates = nil, 0
+	infos = map[string]traefik.CertInfo{"fresh": {Exists: true, DaysLeft: 90}}
+	if n := d.renewExpiringCerts(); n != 0 || updates != 0 {
+		t.Errorf("nothing due: renewed %d, updates %d", n, updates)
+	}
+}
+
+func TestNewDaemonCertCheckInterval(t *testing.T) {
+	setupSrvRoot(t)
+	d, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.CertCheckInterval != DefaultCertCheckInterval {
+		t.Errorf("default interval = %s, want %s", d.CertCheckInterval, DefaultCertCheckInterval)
+	}
+	if err := SaveSettings(d.cfg, &Settings{CertCheckInterval: 6 * time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	d, err = New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.CertCheckInterval != 6*time.Hour {
+		t.Errorf("interval from daemon.yml = %s, want 6h", d.CertCheckInterval)
+	}
+}
@@ -3,6 +3,7 @@
 package daemon
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"os"
@@ -15,12 +16,12 @@ import (
 	cerrdefs "github.com/containerd/errdefs"
 	dockerevents "github.com/docker/docker/api/types/events"
 	dockerfilters "github.com/docker/docker/api/types/filters"
-	dockerclient "github.com/docker/docker/client"
 
 	"github.com/stubbedev/srv/internal/config"
 	"github.com/stubbedev/srv/internal/constants"
 	"github.com/stubbedev/srv/internal/docker"
 	"github.com/stubbedev/srv/internal/site"
+	"github.com/stubbedev/srv/internal/traefik"
 )
 
 // LogFile is the name of the daemon log file.
@@ -30,20 +31,33 @@ const LogFile = "daemon.log"
 // refreshes triggered by untracked container start events.
 const refreshCooldown = 5 * time.Second
 
-// Daemon watches Docker events and auto-connects containers to the srv network.
+// mappingRefreshInterval is how often the event loop rebuilds the container
+// mapping, picking up sites and proxies added since the last refresh.
+const mappingRefreshInterval = 30 * time.Second
+
+// Daemon watches Docker events, connecting site containers to the srv network
+// when they start and disconnecting them when they stop.
 type Daemon struct {
-	cfg         *config.Config
-	networkName string
-	containers  map[string]string // container name -> site name mapping
-	ctx         context.Context
-	cancel      context.CancelFunc
-	logMu       sync.Mutex // serialises concurrent log() writes from the
-	// signal, metadata-watcher, and Docker-event goroutines.
-	logFile         *os.File
+	cfg             *config.Config
+	networkName     string
+	containers      map[string]string // container name -> site name mapping
+	ctx             context.Context
+	cancel          context.CancelFunc
+	logger          Logger
+	settingsMu      sync.Mutex // guards settings, which the settings watcher replaces
+	settings        *Settings
 	lastRefreshTime time.Time // guards against refresh storms
+	stats           healthStats
 	// WatchMetadata controls whether the daemon also watches site metadata.yml
 	// files and hot-reloads them. Set via `srv daemon start --no-watch=false`.
 	WatchMetadata bool
+	// LogFormat is the log format Run writes ("text" or "json"). New sets it
+	// from daemon.yml; `srv daemon start --log-format` overrides it.
+	LogFormat string
+	// CertCheckInterval is how often local certificates are checked for
+	// renewal. New sets it from daemon.yml; `srv daemon start
+	// --cert-check-interval` overrides it.
+	CertCheckInterval time.Duration
 }
 
 // New creates a new daemon instance.
@@ -53,15 +67,25 @@ func New() (*Daemon, error) {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
+	settings, err := LoadSettings(cfg)
+	if err != nil {
+		return nil, err
+	}
+	docker.SetTimeouts(settings.DockerTimeouts())
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &Daemon{
-		cfg:           cfg,
-		networkName:   cfg.NetworkName,
-		containers:    make(map[string]string),
-		ctx:           ctx,
-		cancel:        cancel,
-		WatchMetadata: true,
+		cfg:               cfg,
+		networkName:       cfg.NetworkName,
+		containers:        make(map[string]string),
+		ctx:               ctx,
+		cancel:            cancel,
+		logger:            NewLogger(settings.LogFormat, nil),
+		settings:          settings,
+		WatchMetadata:     true,
+		LogFormat:         settings.LogFormat,
+		CertCheckInterval: cmp.Or(settings.CertCheckInterval, DefaultCertCheckInterval),
 	}, nil
 }
 
@@ -95,14 +119,21 @@ func (d *Daemon) Run() error {
 	if err != nil {
 		return fmt.Errorf("failed to open log file: %w", err)
 	}
-	d.logFile = logFile
 	defer func() { _ = logFile.Close() }()
+	d.logger = NewLogger(d.LogFormat, logFile)
+
+	d.stats.started = time.Now()
+	d.logger.Info("Daemon started, watching for container events on network %s", d.networkName)
 
-	d.log("Daemon started, watching for container events on network %s", d.networkName)
+	removePid, err := writePidFile(d.cfg)
+	if err != nil {
+		d.logger.Warn("failed to write PID file: %v", err)
+	}
+	defer removePid()
 
 	// Build initial container mapping from registered sites
 	if err := d.refreshContainerMapping(); err != nil {
-		d.log("Warning: failed to load site mappings: %v", err)
+		d.logger.Warn("failed to load site mappings: %v", err)
 	}
 
 	// Set up signal handling
@@ -113,51 +144,74 @@ func (d *Daemon) Run() error {
 	go func() {
 		select {
 		case <-sigChan:
-			d.log("Received shutdown signal")
+			d.logger.Info("Received shutdown signal")
 			d.cancel()
 		case <-d.ctx.Done():
 		}
 	}()
 
+	// SIGUSR1 asks for a health dump in the log (see `srv daemon health`).
+	healthChan := make(chan os.Signal, 1)
+	notifyHealthSignal(healthChan)
+	defer signal.Stop(healthChan)
+	go func() {
+		for {
+			select {
+			case <-healthChan:
+				d.dumpHealth()
+			case <-d.ctx.Done():
+				return
+			}
+		}
+	}()
+
+	if err := d.startSettingsWatcher(); err != nil {
+		d.logger.Warn("%s watcher disabled: %v", SettingsFile, err)
+	}
+
+	if err := d.startHealthServer(HealthPort()); err != nil {
+		d.logger.Warn("health endpoint disabled: %v", err)
+	}
+
 	// Watch metadata.yml writes (P3 hot-reload) unless disabled.
 	if d.WatchMetadata {
 		if _, err := d.startMetadataWatcher(); err != nil {
-			d.log("Metadata watcher disabled: %v", err)
+			d.logger.Warn("metadata watcher disabled: %v", err)
 		}
 	} else {
-		d.log("Metadata watcher disabled by --no-watch")
+		d.logger.Info("Metadata watcher disabled by --no-watch")
 	}
 
+	d.startCertRenewer()
+
 	// Watch Docker events
 	return d.watchEvents()
 }
 
-// log writes a timestamped message to the log file.
-func (d *Daemon) log(format string, args ...any) {
-	msg := fmt.Sprintf(format, args...)
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
-	d.logMu.Lock()
-	defer d.logMu.Unlock()
-	if d.logFile != nil {
-		_, _ = fmt.Fprintf(d.logFile, "[%s] %s\n", timestamp, msg)
-	}
-}
-
 // refreshContainerMapping rebuilds the container name to site name mapping.
+// Containers that proxies forward to are tracked under the proxy's name, so
+// a proxy added after the daemon started is picked up on the next refresh;
+// a site's own container wins over a proxy targeting it. Only metadata is
+// read — no container status — since this runs every mappingRefreshInterval.
 func (d *Daemon) refreshContainerMapping() error {
-	sites, err := site.List()
+	sites, err := site.ListWithoutStatus()
 	if err != nil {
 		return err
 	}
 
-	d.containers = make(map[string]string)
+	containers, err := traefik.ProxyContainers(d.cfg)
+	if err != nil {
+		d.logger.Warn("failed to read proxy configs: %v", err)
+		containers = make(map[string]string)
+	}
 	for _, s := range sites {
 		if s.ServiceName != "" && s.Type == site.SiteTypeCompose {
-			d.containers[s.ServiceName] = s.Name
+			containers[s.ServiceName] = s.Name
 		}
 	}
+	d.containers = containers
 
-	d.log("Loaded %d container mappings", len(d.containers))
+	d.logger.Debug("Loaded %d container mappings", len(d.containers))
 	return nil
 }
 
@@ -167,6 +221,10 @@ var isDockerAvailable = func() bool {
 	return docker.EnsureRunning() == nil
 }
 
+// disconnectStopped detaches a stopped container from the srv network. Tests
+// swap it to avoid a Docker daemon.
+var disconnectStopped = docker.DisconnectStoppedContainer
+
 // waitForDocker waits for Docker daemon to become available with exponential backoff.
 func (d *Daemon) waitForDocker() error {
 	backoff := time.Second
@@ -183,7 +241,7 @@ func (d *Daemon) waitForDocker() error {
 			return nil
 		}
 
-		d.log("Docker daemon not running, retrying in %v...", backoff)
+		d.logger.Warn("Docker daemon not running, retrying in %v...", backoff)
 
 		select {
 		case <-d.ctx.Done():
@@ -200,6 +258,8 @@ func (d *Daemon) waitForDocker() error {
 
 // watchEvents watches Docker events and handles container starts.
 func (d *Daemon) watchEvents() error {
+	ticker := time.NewTicker(mappingRefreshInterval)
+	defer ticker.Stop()
 	for {
 		select {
 		case <-d.ctx.Done():
@@ -212,11 +272,11 @@ func (d *Daemon) watchEvents() error {
 			return err
 		}
 
-		d.log("Docker is available, starting event watcher")
+		d.logger.Info("Docker is available, starting event watcher")
 
-		err := d.runEventLoop()
+		err := d.runEventLoop(ticker.C)
 		if err != nil && d.ctx.Err() == nil {
-			d.log("Event loop error: %v, restarting in 5s...", err)
+			d.logger.Error("event loop error: %v, restarting in 5s...", err)
 			select {
 			case <-d.ctx.Done():
 				return nil
@@ -226,9 +286,10 @@ func (d *Daemon) watchEvents() error {
 	}
 }
 
-// runEventLoop runs a single event watching session using the Docker SDK.
-func (d *Daemon) runEventLoop() error {
-	cli, err := dockerclient.NewClientWithOpts(dockerclient.FromEnv, dockerclient.WithAPIVersionNegotiation())
+// runEventLoop runs a single event watching session using the Docker SDK,
+// rebuilding the container mapping on every tick of refresh.
+func (d *Daemon) runEventLoop(refresh <-chan time.Time) error {
+	cli, err := docker.NewEventsClient()
 	if err != nil {
 		return fmt.Errorf("failed to create Docker client: %w", err)
 	}
@@ -236,7 +297,9 @@ func (d *Daemon) runEventLoop() error {
 
 	f := dockerfilters.NewArgs(
 		dockerfilters.Arg("type", string(dockerevents.ContainerEventType)),
-		dockerfilters.Arg("event", "start"),
+		dockerfilters.Arg("event", string(dockerevents.ActionStart)),
+		dockerfilters.Arg("event", string(dockerevents.ActionStop)),
+		dockerfilters.Arg("event", string(dockerevents.ActionDie)),
 	)
 
 	eventCh, errCh := cli.Events(d.ctx, dockerevents.ListOptions{Filters: f})
@@ -247,8 +310,18 @@ func (d *Daemon) runEventLoop() error {
 			return nil
 		case err := <-errCh:
 			return fmt.Errorf("error reading Docker events: %w", err)
+		case <-refresh:
+			if err := d.refreshContainerMapping(); err != nil {
+				d.logger.Warn("failed to refresh container mappings: %v", err)
+			}
+			d.lastRefreshTime = time.Now()
 		case event := <-eventCh:
-			d.handleContainerStart(event)
+			switch event.Action {
+			case dockerevents.ActionStart:
+				d.handleContainerStart(event)
+			case dockerevents.ActionStop, dockerevents.ActionDie:
+				d.handleContainerStop(event)
+			}
 		}
 	}
 }
@@ -256,9 +329,10 @@ func (d *Daemon) runEventLoop() error {
 // handleContainerStart processes a container start event.
 func (d *Daemon) handleContainerStart(event dockerevents.Message) {
 	containerName := event.Actor.Attributes["name"]
-	if containerName == "" {
+	if containerName == "" || d.settingsSnapshot().Ignores(containerName) {
 		return
 	}
+	d.recordEvent("start " + containerName)
 
 	// Check if this container is one we're tracking
 	siteName, tracked := d.containers[containerName]
@@ -275,16 +349,43 @@ func (d *Daemon) handleContainerStart(event dockerevents.Message) {
 		}
 	}
 
-	d.log("Container %s started (site: %s), connecting to network %s", containerName, siteName, d.networkName)
+	log := d.logger.With("container", containerName).With("site", siteName)
+	log.Info("Container %s started (site: %s), connecting to network %s", containerName, siteName, d.networkName)
 
 	// Connect the container to our network
 	if err := docker.ConnectContainerToNetwork(containerName, d.networkName, containerName); err != nil {
 		// docker.ConnectContainerToNetwork already swallows "already connected"
 		// conflicts; anything that reaches us here is a real failure worth logging.
 		if !cerrdefs.IsConflict(err) {
-			d.log("Failed to connect %s to network: %v", containerName, err)
+			log.Error("failed to connect %s to network: %v", containerName, err)
 		}
 	} else {
-		d.log("Successfully connected %s to network %s", containerName, d.networkName)
+		log.Info("Successfully connected %s to network %s", containerName, d.networkName)
+	}
+}
+
+// handleContainerStop processes a container stop or die event. The tracked
+// container is disconnected from the network so its endpoint doesn't linger
+// (a stale endpoint makes the next connect fail with "endpoint with name ...
+// already exists"); handleContainerStart reconnects it on the next start.
+// Docker sends die then stop for a stop, so the second one finds nothing to do.
+func (d *Daemon) handleContainerStop(event dockerevents.Message) {
+	containerName := event.Actor.Attributes["name"]
+	if containerName == "" || d.settingsSnapshot().Ignores(containerName) {
+		return
+	}
+	d.recordEvent(string(event.Action) + " " + containerName)
+
+	siteName, tracked := d.containers[containerName]
+	if !tracked {
+		return
+	}
+	log := d.logger.With("container", containerName).With("site", siteName)
+	disconnected, err := disconnectStopped(containerName, d.networkName)
+	switch {
+	case err != nil:
+		log.Error("failed to disconnect %s from network %s: %v", containerName, d.networkName, err)
+	case disconnected:
+		log.Info("Container %s stopped (site: %s), disconnected from network %s", containerName, siteName, d.networkName)
 	}
 }
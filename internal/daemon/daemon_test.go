@@ -12,6 +12,7 @@ import (
 
 	"github.com/stubbedev/srv/internal/config"
 	"github.com/stubbedev/srv/internal/site"
+	"github.com/stubbedev/srv/internal/traefik"
 )
 
 func setupSrvRoot(t *testing.T) string {
@@ -59,8 +60,8 @@ func TestDaemonLogWritesTimestamped(t *testing.T) {
 		t.Fatal(err)
 	}
 	defer f.Close()
-	d.logFile = f
-	d.log("hello %s", "world")
+	d.logger = NewLogger(LogFormatText, f)
+	d.logger.Info("hello %s", "world")
 	data, _ := os.ReadFile(logPath)
 	body := string(data)
 	if !contains(body, "hello world") {
@@ -69,13 +70,13 @@ func TestDaemonLogWritesTimestamped(t *testing.T) {
 }
 
 func TestDaemonLogNilFileNoCrash(t *testing.T) {
-	d := &Daemon{}
-	d.log("safe %d", 1)
+	d := &Daemon{logger: NewLogger(LogFormatText, nil)}
+	d.logger.Info("safe %d", 1)
 }
 
-// TestDaemonLogConcurrent confirms log() is safe under concurrent callers
+// TestDaemonLogConcurrent confirms the logger is safe under concurrent callers
 // (signal, metadata-watcher, and event goroutines all call it). Run with
-// `go test -race` this fails without the logMu guard; it also asserts every
+// `go test -race` this fails without the logOutput mutex; it also asserts every
 // line lands intact (no interleaving/truncation).
 func TestDaemonLogConcurrent(t *testing.T) {
 	root := setupSrvRoot(t)
@@ -86,7 +87,7 @@ func TestDaemonLogConcurrent(t *testing.T) {
 		t.Fatal(err)
 	}
 	defer f.Close()
-	d.logFile = f
+	d.logger = NewLogger(LogFormatText, f)
 
 	const goroutines, perGoroutine = 8, 50
 	var wg sync.WaitGroup
@@ -95,7 +96,7 @@ func TestDaemonLogConcurrent(t *testing.T) {
 		go func(id int) {
 			defer wg.Done()
 			for i := 0; i < perGoroutine; i++ {
-				d.log("g%d-line%d", id, i)
+				d.logger.Info("g%d-line%d", id, i)
 			}
 		}(g)
 	}
@@ -133,7 +134,7 @@ func TestHandleContainerStartUntrackedNoop(t *testing.T) {
 	logPath := filepath.Join(root, "x.log")
 	f, _ := os.Create(logPath)
 	defer f.Close()
-	d.logFile = f
+	d.logger = NewLogger(LogFormatText, f)
 	d.lastRefreshTime = time.Now() // suppress refresh attempt
 	d.handleContainerStart(dockerevents.Message{
 		Actor: dockerevents.Actor{Attributes: map[string]string{"name": "ghost"}},
@@ -201,7 +202,7 @@ func TestHandleContainerStartTracked(t *testing.T) {
 	}
 	f, _ := os.Create(filepath.Join(root, "x.log"))
 	defer f.Close()
-	d.logFile = f
+	d.logger = NewLogger(LogFormatText, f)
 	d.lastRefreshTime = time.Now()
 	d.handleContainerStart(dockerevents.Message{
 		Actor: dockerevents.Actor{Attributes: map[string]string{"name": "web"}},
@@ -328,6 +329,26 @@ func TestRefreshContainerMappingWithSites(t *testing.T) {
 	if d.containers["blog-web"] != "blog" {
 		t.Errorf("got %v", d.containers)
 	}
+
+	// A proxy added later is tracked on the next refresh; the site keeps
+	// its own container.
+	if err := os.MkdirAll(d.cfg.TraefikConfDir(), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []traefik.ProxyRoute{
+		{Name: "api", Domain: "api.test", TargetURL: "http://api-web:8080"},
+		{Name: "blog-alt", Domain: "blog-alt.test", TargetURL: "http://blog-web:80"},
+	} {
+		if err := traefik.WriteProxyConfig(d.cfg, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := d.refreshContainerMapping(); err != nil {
+		t.Fatal(err)
+	}
+	if d.containers["api-web"] != "api" || d.containers["blog-web"] != "blog" {
+		t.Errorf("got %v", d.containers)
+	}
 }
 
 func newDaemonForTest(t *testing.T) (*Daemon, error) {
@@ -335,3 +356,41 @@ func newDaemonForTest(t *testing.T) (*Daemon, error) {
 	setupSrvRoot(t)
 	return New()
 }
+
+func TestHandleContainerStop(t *testing.T) {
+	root := setupSrvRoot(t)
+	d := &Daemon{
+		cfg:         &config.Config{Root: root},
+		networkName: "n",
+		containers:  map[string]string{"web": "blog"},
+	}
+	f, _ := os.Create(filepath.Join(root, "x.log"))
+	defer f.Close()
+	d.logger = NewLogger(LogFormatText, f)
+
+	var calls []string
+	prev := disconnectStopped
+	disconnectStopped = func(name, network string) (bool, error) {
+		calls = append(calls, name+"@"+network)
+		return len(calls) == 1, nil // the stop after die finds nothing attached
+	}
+	t.Cleanup(func() { disconnectStopped = prev })
+
+	for _, ev := range []dockerevents.Message{
+		{Action: dockerevents.ActionDie, Actor: dockerevents.Actor{Attributes: map[string]string{"name": "web"}}},
+		{Action: dockerevents.ActionStop, Actor: dockerevents.Actor{Attributes: map[string]string{"name": "web"}}},
+		{Action: dockerevents.ActionStop, Actor: dockerevents.Actor{Attributes: map[string]string{"name": "ghost"}}},
+	} {
+		d.handleContainerStop(ev)
+	}
+	if len(calls) != 2 || calls[0] != "web@n" {
+		t.Errorf("disconnect calls = %v, want web twice and no untracked container", calls)
+	}
+	data, _ := os.ReadFile(filepath.Join(root, "x.log"))
+	if n := strings.Count(string(data), "disconnected from network n"); n != 1 {
+		t.Errorf("logged %d disconnections, want 1: %q", n, string(data))
+	}
+	if h := d.Health(); h.EventsProcessed != 3 {
+		t.Errorf("EventsProcessed = %d, want 3", h.EventsProcessed)
+	}
+}
@@ -0,0 +1,225 @@
+// Package daemon — health.go tracks what the daemon has done since it started
+// and reports it two ways: GET /health on a loopback port, and (on SIGUSR1)
+// as a line in the daemon log, which `srv daemon health` reads back.
+package daemon
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/constants"
+)
+
+// PidFile is the name of the file the running daemon records its PID in.
+const PidFile = "daemon.pid"
+
+// healthLogPrefix starts the message of the health dumps SIGUSR1 writes to
+// the log.
+const healthLogPrefix = "Health: "
+
+// logTimeLayout is the timestamp format of daemon log lines.
+const logTimeLayout = "2006-01-02 15:04:05"
+
+// Health is the daemon's self-report, served as JSON by GET /health.
+type Health struct {
+	Status          string `json:"status"`
+	Uptime          string `json:"uptime"`
+	EventsProcessed int64  `json:"eventsProcessed"`
+	LastEvent       string `json:"lastEvent"`
+}
+
+// healthStats counts the Docker events the daemon handled. Guarded by mu:
+// the event loop writes while the HTTP and signal handlers read.
+type healthStats struct {
+	mu        sync.Mutex
+	started   time.Time
+	events    int64
+	lastEvent string
+}
+
+// PidPath returns the path to the daemon's PID file.
+func PidPath(cfg *config.Config) string {
+	return filepath.Join(cfg.Root, PidFile)
+}
+
+// HealthPort returns the port the daemon serves /health on: the
+// daemon_health_port setting, or constants.PortDaemonHealth.
+func HealthPort() int {
+	return cmp.Or(config.UserSettings().DaemonHealthPort, constants.PortDaemonHealth)
+}
+
+// HealthURL returns the daemon's health endpoint.
+func HealthURL() string {
+	return "http://" + net.JoinHostPort(constants.LocalhostIP, strconv.Itoa(HealthPort())) + "/health"
+}
+
+// recordEvent counts a handled Docker event.
+func (d *Daemon) recordEvent(desc string) {
+	d.stats.mu.Lock()
+	defer d.stats.mu.Unlock()
+	d.stats.events++
+	d.stats.lastEvent = time.Now().Format(logTimeLayout) + " " + desc
+}
+
+// Health reports the daemon's uptime and event counters.
+func (d *Daemon) Health() Health {
+	d.stats.mu.Lock()
+	defer d.stats.mu.Unlock()
+	h := Health{Status: "ok", EventsProcessed: d.stats.events, LastEvent: d.stats.lastEvent}
+	if !d.stats.started.IsZero() {
+		h.Uptime = time.Since(d.stats.started).Round(time.Second).String()
+	}
+	return h
+}
+
+// serveHealth answers GET /health with the daemon's Health as JSON.
+func (d *Daemon) serveHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(d.Health())
+}
+
+// startHealthServer serves /health on the loopback port until the daemon
+// stops.
+func (d *Daemon) startHealthServer(port int) error {
+	ln, err := net.Listen("tcp", net.JoinHostPort(constants.LocalhostIP, strconv.Itoa(port)))
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", d.serveHealth)
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() { _ = srv.Serve(ln) }()
+	go func() {
+		<-d.ctx.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = srv.Shutdown(ctx)
+	}()
+	d.logger.Info("Health endpoint listening on %s", ln.Addr())
+	return nil
+}
+
+// dumpHealth writes the daemon's Health to the log for `srv daemon health`.
+func (d *Daemon) dumpHealth() {
+	data, err := json.Marshal(d.Health())
+	if err != nil {
+		return
+	}
+	d.logger.Info("%s%s", healthLogPrefix, data)
+}
+
+// writePidFile records the daemon's PID; the returned func removes it.
+func writePidFile(cfg *config.Config) (func(), error) {
+	path := PidPath(cfg)
+	if err := os.WriteFile(path, []byte(strconv.Itoa(os.Getpid())+"\n"), constants.FilePermDefault); err != nil {
+		return func() {}, err
+	}
+	return func() { _ = os.Remove(path) }, nil
+}
+
+// ReadPid returns the PID recorded by the running daemon.
+func ReadPid(cfg *config.Config) (int, error) {
+	data, err := os.ReadFile(PidPath(cfg))
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, fmt.Errorf("invalid PID file %s", PidPath(cfg))
+	}
+	return pid, nil
+}
+
+// ErrNotRunning is returned by RequestHealth when no daemon process is alive.
+var ErrNotRunning = errors.New("daemon is not running")
+
+// RequestHealth signals the running daemon to dump its health to the log and
+// returns the dump, waiting up to timeout for it to appear.
+func RequestHealth(cfg *config.Config, timeout time.Duration) (Health, time.Time, error) {
+	pid, err := ReadPid(cfg)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && !processAlive(pid)) {
+		return Health{}, time.Time{}, ErrNotRunning
+	}
+	if err != nil {
+		return Health{}, time.Time{}, err
+	}
+
+	// Only a dump written after the signal counts.
+	var offset int64
+	if info, err := os.Stat(LogPath(cfg)); err == nil {
+		offset = info.Size()
+	}
+	if err := requestHealthDump(pid); err != nil {
+		return Health{}, time.Time{}, fmt.Errorf("failed to signal daemon (pid %d): %w", pid, err)
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		h, at, err := latestHealth(LogPath(cfg), offset)
+		if err == nil || time.Now().After(deadline) {
+			return h, at, err
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// LatestHealth returns the most recent health dump in the daemon log and
+// when it was written.
+func LatestHealth(cfg *config.Config) (Health, time.Time, error) {
+	return latestHealth(LogPath(cfg), 0)
+}
+
+// latestHealth scans the log from offset for the last health dump.
+func latestHealth(path string, offset int64) (Health, time.Time, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Health{}, time.Time{}, err
+	}
+	defer func() { _ = f.Close() }()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return Health{}, time.Time{}, err
+	}
+
+	var (
+		body  string
+		at    time.Time
+		found bool
+	)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if stamp, msg, ok := parseLogLine(scanner.Text()); ok && strings.HasPrefix(msg, healthLogPrefix) {
+			body, at, found = strings.TrimPrefix(msg, healthLogPrefix), stamp, true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Health{}, time.Time{}, err
+	}
+	if !found {
+		return Health{}, time.Time{}, errors.New("no health report in the daemon log")
+	}
+
+	var h Health
+	if err := json.Unmarshal([]byte(body), &h); err != nil {
+		return Health{}, time.Time{}, fmt.Errorf("malformed health report: %w", err)
+	}
+	return h, at, nil
+}
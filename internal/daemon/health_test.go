@@ -0,0 +1,133 @@
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stubbedev/srv/internal/config"
+)
+
+func TestServeHealth(t *testing.T) {
+	d := &Daemon{}
+	d.stats.started = time.Now().Add(-90 * time.Second)
+	d.recordEvent("start blog-web")
+
+	rec := httptest.NewRecorder()
+	d.serveHealth(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	var h Health
+	if err := json.Unmarshal(rec.Body.Bytes(), &h); err != nil {
+		t.Fatalf("body %q: %v", rec.Body.String(), err)
+	}
+	if h.Status != "ok" || h.EventsProcessed != 1 || h.Uptime != "1m30s" {
+		t.Errorf("health = %+v", h)
+	}
+	if len(h.LastEvent) < len("start blog-web") || h.LastEvent[len(h.LastEvent)-len("start blog-web"):] != "start blog-web" {
+		t.Errorf("lastEvent = %q", h.LastEvent)
+	}
+
+	rec = httptest.NewRecorder()
+	d.serveHealth(rec, httptest.NewRequest(http.MethodPost, "/health", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST status = %d, want 405", rec.Code)
+	}
+}
+
+func TestLatestHealthReadsLastDump(t *testing.T) {
+	root := setupSrvRoot(t)
+	cfg := &config.Config{Root: root}
+	f, err := os.Create(LogPath(cfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	d := &Daemon{cfg: cfg, logger: NewLogger(LogFormatText, f)}
+
+	if _, _, err := LatestHealth(cfg); err == nil {
+		t.Error("expected an error before any dump")
+	}
+	d.dumpHealth()
+	d.recordEvent("start blog-web")
+	d.logger.Info("unrelated line")
+	d.dumpHealth()
+
+	h, at, err := LatestHealth(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.EventsProcessed != 1 {
+		t.Errorf("EventsProcessed = %d, want the latest dump's 1", h.EventsProcessed)
+	}
+	if time.Since(at) > time.Minute {
+		t.Errorf("dump time = %v", at)
+	}
+}
+
+func TestReadPid(t *testing.T) {
+	root := setupSrvRoot(t)
+	cfg := &config.Config{Root: root}
+	if _, _, err := RequestHealth(cfg, 0); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("RequestHealth without a PID file = %v, want ErrNotRunning", err)
+	}
+	remove, err := writePidFile(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pid, err := ReadPid(cfg); err != nil || pid != os.Getpid() {
+		t.Errorf("ReadPid = %d, %v", pid, err)
+	}
+	remove()
+	if _, err := os.Stat(PidPath(cfg)); !os.IsNotExist(err) {
+		t.Errorf("PID file not removed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, PidFile), []byte("nope"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadPid(cfg); err == nil {
+		t.Error("expected error for a malformed PID file")
+	}
+}
+
+func TestRequestHealthSignalsDaemon(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no SIGUSR1 on windows")
+	}
+	root := setupSrvRoot(t)
+	cfg := &config.Config{Root: root}
+	f, err := os.Create(LogPath(cfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	d := &Daemon{cfg: cfg, logger: NewLogger(LogFormatText, f)}
+	d.stats.started = time.Now()
+	d.dumpHealth() // a stale dump that must not be returned
+
+	if err := os.WriteFile(PidPath(cfg), []byte(strconv.Itoa(os.Getpid())), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ch := make(chan os.Signal, 1)
+	notifyHealthSignal(ch)
+	defer signal.Stop(ch)
+	go func() {
+		<-ch
+		d.recordEvent("start blog-web")
+		d.dumpHealth()
+	}()
+
+	h, _, err := RequestHealth(cfg, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.EventsProcessed != 1 {
+		t.Errorf("EventsProcessed = %d, want the fresh dump's 1", h.EventsProcessed)
+	}
+}
@@ -0,0 +1,22 @@
+//go:build unix
+
+package daemon
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyHealthSignal relays SIGUSR1, the health dump request, to ch.
+func notifyHealthSignal(ch chan<- os.Signal) { signal.Notify(ch, syscall.SIGUSR1) }
+
+// requestHealthDump asks the daemon process to dump its health to the log.
+func requestHealthDump(pid int) error { return syscall.Kill(pid, syscall.SIGUSR1) }
+
+// processAlive reports whether a process with the PID exists.
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,22 @@
+//go:build !unix
+
+package daemon
+
+import (
+	"errors"
+	"os"
+)
+
+// notifyHealthSignal is a no-op: there is no SIGUSR1 to listen for.
+func notifyHealthSignal(chan<- os.Signal) {}
+
+// requestHealthDump is unsupported without SIGUSR1; use the /health endpoint.
+func requestHealthDump(int) error {
+	return errors.New("health dumps need SIGUSR1; query " + HealthURL() + " instead")
+}
+
+// processAlive reports whether a process with the PID exists.
+func processAlive(pid int) bool {
+	_, err := os.FindProcess(pid)
+	return err == nil
+}
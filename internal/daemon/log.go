@@ -0,0 +1,160 @@
+// Package daemon — log.go defines the daemon's Logger and its two formats:
+// text, the "[timestamp] message" lines the daemon has always written, and
+// JSON, one object per line for shipping the log to Loki, Datadog and the
+// like. The format comes from daemon.yml or `srv daemon start --log-format`.
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Log formats accepted by NewLogger.
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// LogFormats lists the supported log formats, for flag help and validation.
+var LogFormats = []string{LogFormatText, LogFormatJSON}
+
+// Log levels, as written in JSON entries.
+const (
+	levelDebug = "debug"
+	levelInfo  = "info"
+	levelWarn  = "warn"
+	levelError = "error"
+)
+
+// Logger writes leveled daemon log entries. Messages are printf-style and
+// self-contained; fields added with With are structured context (the
+// container or site an entry is about) that only the JSON format records.
+type Logger interface {
+	Debug(format string, args ...any)
+	Info(format string, args ...any)
+	Warn(format string, args ...any)
+	Error(format string, args ...any)
+	// With returns a Logger that adds key=value to every entry.
+	With(key, value string) Logger
+}
+
+// ValidLogFormat reports whether format is one of LogFormats.
+func ValidLogFormat(format string) bool {
+	return slices.Contains(LogFormats, format)
+}
+
+// NewLogger returns a Logger writing format ("text" or "json"; "" means
+// text) to w. A nil w discards entries.
+func NewLogger(format string, w io.Writer) Logger {
+	out := &logOutput{w: w}
+	if format == LogFormatJSON {
+		return jsonLogger{out: out}
+	}
+	return textLogger{out: out}
+}
+
+// logOutput serialises writes from the signal, metadata-watcher and
+// Docker-event goroutines so lines never interleave.
+type logOutput struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (o *logOutput) writeLine(line []byte) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.w != nil {
+		_, _ = o.w.Write(append(line, '\n'))
+	}
+}
+
+// textLogger writes "[2006-01-02 15:04:05] message" lines; warnings, errors
+// and debug entries are prefixed with their level.
+type textLogger struct {
+	out *logOutput
+}
+
+func (l textLogger) Debug(format string, args ...any) { l.write("Debug: ", format, args) }
+func (l textLogger) Info(format string, args ...any)  { l.write("", format, args) }
+func (l textLogger) Warn(format string, args ...any)  { l.write("Warning: ", format, args) }
+func (l textLogger) Error(format string, args ...any) { l.write("Error: ", format, args) }
+
+// With returns l unchanged: text messages already name what they are about.
+func (l textLogger) With(key, value string) Logger { return l }
+
+func (l textLogger) write(prefix, format string, args []any) {
+	line := fmt.Sprintf("[%s] %s%s", time.Now().Format(logTimeLayout), prefix, fmt.Sprintf(format, args...))
+	l.out.writeLine([]byte(line))
+}
+
+// jsonLogger writes {"level":…,"ts":…,"msg":…} objects followed by any
+// With fields.
+type jsonLogger struct {
+	out    *logOutput
+	fields []logField
+}
+
+// logField is one With key/value pair; a slice keeps their order stable.
+type logField struct {
+	key, value string
+}
+
+func (l jsonLogger) Debug(format string, args ...any) { l.write(levelDebug, format, args) }
+func (l jsonLogger) Info(format string, args ...any)  { l.write(levelInfo, format, args) }
+func (l jsonLogger) Warn(format string, args ...any)  { l.write(levelWarn, format, args) }
+func (l jsonLogger) Error(format string, args ...any) { l.write(levelError, format, args) }
+
+func (l jsonLogger) With(key, value string) Logger {
+	return jsonLogger{out: l.out, fields: append(slices.Clip(l.fields), logField{key, value})}
+}
+
+func (l jsonLogger) write(level, format string, args []any) {
+	line := []byte(`{"level":`)
+	line = appendJSONString(line, level)
+	line = append(line, `,"ts":`...)
+	line = appendJSONString(line, time.Now().Format(time.RFC3339))
+	line = append(line, `,"msg":`...)
+	line = appendJSONString(line, fmt.Sprintf(format, args...))
+	for _, f := range l.fields {
+		line = append(line, ',')
+		line = appendJSONString(line, f.key)
+		line = append(line, ':')
+		line = appendJSONString(line, f.value)
+	}
+	l.out.writeLine(append(line, '}'))
+}
+
+// appendJSONString appends s as a JSON string literal.
+func appendJSONString(b []byte, s string) []byte {
+	quoted, _ := json.Marshal(s)
+	return append(b, quoted...)
+}
+
+// jsonLogEntry is the part of a JSON log line the daemon reads back.
+type jsonLogEntry struct {
+	TS  string `json:"ts"`
+	Msg string `json:"msg"`
+}
+
+// parseLogLine splits a log line in either format into its time and message.
+func parseLogLine(line string) (time.Time, string, bool) {
+	if strings.HasPrefix(line, "{") {
+		var e jsonLogEntry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			return time.Time{}, "", false
+		}
+		at, _ := time.Parse(time.RFC3339, e.TS)
+		return at, e.Msg, true
+	}
+	stamp, msg, ok := strings.Cut(line, "] ")
+	if !ok || !strings.HasPrefix(stamp, "[") {
+		return time.Time{}, "", false
+	}
+	at, _ := time.ParseInLocation(logTimeLayout, stamp[1:], time.Local)
+	return at, msg, true
+}
@@ -0,0 +1,82 @@
+package daemon
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stubbedev/srv/internal/config"
+)
+
+func TestTextLoggerLevels(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger(LogFormatText, &buf)
+	l.Info("started %d", 1)
+	l.With("container", "web").Warn("slow")
+	l.Error("failed")
+	l.Debug("detail")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := []string{"started 1", "Warning: slow", "Error: failed", "Debug: detail"}
+	if len(lines) != len(want) {
+		t.Fatalf("lines = %q", lines)
+	}
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "[") || !strings.HasSuffix(line, "] "+want[i]) {
+			t.Errorf("line %d = %q, want timestamped %q", i, line, want[i])
+		}
+	}
+}
+
+func TestJSONLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger(LogFormatJSON, &buf)
+	web := l.With("container", "web")
+	web.With("site", "blog").Error("failed to connect %s", "web")
+	web.Info("plain")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("lines = %q", lines)
+	}
+	if !strings.HasPrefix(lines[0], `{"level":"error","ts":`) {
+		t.Errorf("field order: %s", lines[0])
+	}
+	var entry map[string]string
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry["msg"] != "failed to connect web" || entry["container"] != "web" || entry["site"] != "blog" || entry["ts"] == "" {
+		t.Errorf("entry = %v", entry)
+	}
+	entry = nil
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry["level"] != "info" || entry["container"] != "web" || entry["site"] != "" {
+		t.Errorf("With leaked fields into its parent: %v", entry)
+	}
+}
+
+func TestLatestHealthReadsJSONLog(t *testing.T) {
+	root := setupSrvRoot(t)
+	cfg := &config.Config{Root: root}
+	f, err := os.Create(LogPath(cfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	d := &Daemon{cfg: cfg, logger: NewLogger(LogFormatJSON, f)}
+	d.recordEvent("start blog-web")
+	d.dumpHealth()
+
+	h, at, err := LatestHealth(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.EventsProcessed != 1 || at.IsZero() {
+		t.Errorf("health = %+v at %v", h, at)
+	}
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/stubbedev/srv/internal/config"
 	"github.com/stubbedev/srv/internal/constants"
+	"github.com/stubbedev/srv/internal/fsutil"
 	"github.com/stubbedev/srv/internal/platform"
 	"github.com/stubbedev/srv/internal/shell"
 )
@@ -200,7 +201,7 @@ func installSystemd() error {
 		return err
 	}
 
-	if err := os.WriteFile(servicePath, []byte(serviceContent), constants.FilePermDefault); err != nil {
+	if err := fsutil.AtomicWriteFile(servicePath, []byte(serviceContent), constants.FilePermDefault); err != nil {
 		return fmt.Errorf("failed to write service file: %w", err)
 	}
 
@@ -360,7 +361,7 @@ func installLaunchd() error {
 	}
 
 	// Write plist before unloading so a write failure leaves the old service intact.
-	if err := os.WriteFile(plistPath, []byte(plistContent), constants.FilePermACME); err != nil {
+	if err := fsutil.AtomicWriteFile(plistPath, []byte(plistContent), constants.FilePermACME); err != nil {
 		return fmt.Errorf("failed to write plist file: %w", err)
 	}
 
@@ -0,0 +1,153 @@
+// Package daemon — settings.go reads and writes daemon.yml, the daemon's own
+// settings file next to config.yml. The service manager starts the daemon
+// without flags, so settings that must survive a restart live here. The
+// running daemon watches the file and picks up ignore_containers changes
+// without a restart.
+package daemon
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"gopkg.in/yaml.v3"
+
+	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/constants"
+	"github.com/stubbedev/srv/internal/docker"
+	"github.com/stubbedev/srv/internal/fsutil"
+)
+
+// SettingsFile is the name of the daemon's settings file.
+const SettingsFile = "daemon.yml"
+
+// Settings is the content of daemon.yml.
+type Settings struct {
+	// LogFormat is "text" (the default) or "json".
+	LogFormat string `yaml:"log_format,omitempty"`
+	// IgnoreContainers lists container names (filepath.Match globs) the
+	// daemon leaves alone when they start or stop, such as health-check
+	// sidecars.
+	IgnoreContainers []string `yaml:"ignore_containers,omitempty"`
+	// ConnectTimeout and ResponseTimeout override the Docker timeouts the
+	// way --connect-timeout and --response-timeout do; zero keeps the
+	// default.
+	ConnectTimeout  time.Duration `yaml:"connect_timeout,omitempty"`
+	ResponseTimeout time.Duration `yaml:"response_timeout,omitempty"`
+	// CertCheckInterval is how often the daemon renews expiring local
+	// certificates; zero means DefaultCertCheckInterval.
+	CertCheckInterval time.Duration `yaml:"cert_check_interval,omitempty"`
+}
+
+// DockerTimeouts returns the Docker timeouts the daemon runs with: flags
+// already applied to the docker package win over daemon.yml.
+func (s *Settings) DockerTimeouts() docker.Timeouts {
+	t := docker.CurrentTimeouts()
+	t.Connect = cmp.Or(t.Connect, s.ConnectTimeout)
+	t.Response = cmp.Or(t.Response, s.ResponseTimeout)
+	return t
+}
+
+// Ignores reports whether the container name matches an IgnoreContainers
+// pattern.
+func (s *Settings) Ignores(name string) bool {
+	for _, pattern := range s.IgnoreContainers {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// SettingsPath returns the path to daemon.yml.
+func SettingsPath(cfg *config.Config) string {
+	return filepath.Join(cfg.Root, SettingsFile)
+}
+
+// LoadSettings reads daemon.yml; a missing file yields empty settings.
+func LoadSettings(cfg *config.Config) (*Settings, error) {
+	data, err := os.ReadFile(SettingsPath(cfg))
+	if os.IsNotExist(err) {
+		return &Settings{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", SettingsFile, err)
+	}
+	var s Settings
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", SettingsFile, err)
+	}
+	return &s, nil
+}
+
+// SaveSettings writes daemon.yml.
+func SaveSettings(cfg *config.Config, s *Settings) error {
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", SettingsFile, err)
+	}
+	return fsutil.AtomicWriteFile(SettingsPath(cfg), append([]byte("# srv daemon settings\n"), data...), constants.FilePermDefault)
+}
+
+// settingsSnapshot returns the settings the daemon currently applies.
+func (d *Daemon) settingsSnapshot() *Settings {
+	d.settingsMu.Lock()
+	defer d.settingsMu.Unlock()
+	if d.settings == nil {
+		return &Settings{}
+	}
+	return d.settings
+}
+
+// reloadSettings re-reads daemon.yml, keeping the previous settings when it
+// can't be read.
+func (d *Daemon) reloadSettings() {
+	s, err := LoadSettings(d.cfg)
+	if err != nil {
+		d.logger.Warn("keeping previous daemon settings: %v", err)
+		return
+	}
+	d.settingsMu.Lock()
+	d.settings = s
+	d.settingsMu.Unlock()
+	d.logger.Info("Reloaded %s (ignoring %d container pattern(s))", SettingsFile, len(s.IgnoreContainers))
+}
+
+// startSettingsWatcher reloads daemon.yml whenever it changes. The directory
+// is watched rather than the file because SaveSettings replaces the file by
+// rename, which would end a watch on the file itself.
+func (d *Daemon) startSettingsWatcher() error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	if err := w.Add(d.cfg.Root); err != nil {
+		_ = w.Close()
+		return err
+	}
+	go func() {
+		defer func() { _ = w.Close() }()
+		for {
+			select {
+			case <-d.ctx.Done():
+				return
+			case err, ok := <-w.Errors:
+				if !ok {
+					return
+				}
+				d.logger.Error("settings watcher error: %v", err)
+			case event, ok := <-w.Events:
+				if !ok {
+					return
+				}
+				if filepath.Base(event.Name) == SettingsFile && event.Op&(fsnotify.Create|fsnotify.Write|fsnotify.Remove|fsnotify.Rename) != 0 {
+					d.reloadSettings()
+				}
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,89 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	dockerevents "github.com/docker/docker/api/types/events"
+
+	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/docker"
+)
+
+func TestSettingsRoundTrip(t *testing.T) {
+	cfg := &config.Config{Root: t.TempDir()}
+	s, err := LoadSettings(cfg)
+	if err != nil || s.LogFormat != "" {
+		t.Fatalf("missing daemon.yml: %+v, %v", s, err)
+	}
+	if err := SaveSettings(cfg, &Settings{LogFormat: LogFormatJSON}); err != nil {
+		t.Fatal(err)
+	}
+	if s, err = LoadSettings(cfg); err != nil || s.LogFormat != LogFormatJSON {
+		t.Errorf("reloaded = %+v, %v", s, err)
+	}
+	if err := SaveSettings(cfg, &Settings{ResponseTimeout: 2 * time.Minute}); err != nil {
+		t.Fatal(err)
+	}
+	if s, err = LoadSettings(cfg); err != nil || s.ResponseTimeout != 2*time.Minute {
+		t.Errorf("reloaded = %+v, %v", s, err)
+	}
+}
+
+func TestSettingsDockerTimeouts(t *testing.T) {
+	t.Cleanup(func() { docker.SetTimeouts(docker.Timeouts{}) })
+	s := &Settings{ConnectTimeout: time.Minute, ResponseTimeout: 2 * time.Minute}
+	docker.SetTimeouts(docker.Timeouts{Response: 3 * time.Minute})
+	got := s.DockerTimeouts()
+	if got.Connect != time.Minute || got.Response != 3*time.Minute {
+		t.Errorf("DockerTimeouts = %+v, want daemon.yml connect and flag response", got)
+	}
+}
+
+func TestSettingsIgnores(t *testing.T) {
+	s := &Settings{IgnoreContainers: []string{"healthcheck-*", "test-db"}}
+	for name, want := range map[string]bool{
+		"healthcheck-web": true,
+		"test-db":         true,
+		"test-db-2":       false,
+		"blog-web":        false,
+	} {
+		if got := s.Ignores(name); got != want {
+			t.Errorf("Ignores(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestReloadSettingsIgnoresContainer(t *testing.T) {
+	root := setupSrvRoot(t)
+	cfg := &config.Config{Root: root}
+	d := &Daemon{
+		cfg:        cfg,
+		logger:     NewLogger(LogFormatText, nil),
+		containers: map[string]string{"blog-web": "blog"},
+	}
+	if err := SaveSettings(cfg, &Settings{IgnoreContainers: []string{"blog-*"}}); err != nil {
+		t.Fatal(err)
+	}
+	d.reloadSettings()
+	d.handleContainerStart(dockerevents.Message{
+		Actor: dockerevents.Actor{Attributes: map[string]string{"name": "blog-web"}},
+	})
+	if h := d.Health(); h.EventsProcessed != 0 {
+		t.Errorf("ignored container was handled: %+v", h)
+	}
+
+	prev := disconnectStopped
+	disconnectStopped = func(name, network string) (bool, error) {
+		t.Errorf("ignored container %s was disconnected from %s", name, network)
+		return false, nil
+	}
+	t.Cleanup(func() { disconnectStopped = prev })
+	d.handleContainerStop(dockerevents.Message{
+		Action: dockerevents.ActionStop,
+		Actor:  dockerevents.Actor{Attributes: map[string]string{"name": "blog-web"}},
+	})
+	if h := d.Health(); h.EventsProcessed != 0 {
+		t.Errorf("ignored container stop was handled: %+v", h)
+	}
+}
@@ -14,7 +14,6 @@ import (
 	"github.com/fsnotify/fsnotify"
 
 	"github.com/stubbedev/srv/internal/constants"
-	"github.com/stubbedev/srv/internal/docker"
 	"github.com/stubbedev/srv/internal/site"
 )
 
@@ -55,9 +54,9 @@ func (d *Daemon) startMetadataWatcher() (*fsnotify.Watcher, error) {
 
 	state := &watchState{timers: make(map[string]*time.Timer)}
 	if err := state.addExistingSites(w, d.cfg.SitesDir); err != nil {
-		d.log("Warning: failed to seed metadata watcher: %v", err)
+		d.logger.Warn("failed to seed metadata watcher: %v", err)
 	}
-	d.log("Metadata watcher started (watching %d site dirs)", state.count)
+	d.logger.Info("Metadata watcher started (watching %d site dirs)", state.count)
 
 	go d.watchLoop(w, state)
 	return w, nil
@@ -94,7 +93,7 @@ func (d *Daemon) watchLoop(w *fsnotify.Watcher, state *watchState) {
 			if !ok {
 				return
 			}
-			d.log("Watcher error: %v", err)
+			d.logger.Error("watcher error: %v", err)
 		case event, ok := <-w.Events:
 			if !ok {
 				return
@@ -181,10 +180,11 @@ func (s *watchState) forgetSite(siteName string) {
 // Failures are logged but do not crash the daemon — the previously running
 // site stays up.
 func (d *Daemon) reloadSite(state *watchState, siteName string) {
+	log := d.logger.With("site", siteName)
 	muAny, _ := state.reloadMu.LoadOrStore(siteName, &sync.Mutex{})
 	mu, ok := muAny.(*sync.Mutex)
 	if !ok {
-		d.log("Reload %s: unexpected mutex type", siteName)
+		log.Error("Reload %s: unexpected mutex type", siteName)
 		return
 	}
 	mu.Lock()
@@ -192,7 +192,7 @@ func (d *Daemon) reloadSite(state *watchState, siteName string) {
 
 	res, err := site.Reload(siteName)
 	if err != nil {
-		d.log("Reload %s: %v", siteName, err)
+		log.Error("Reload %s: %v", siteName, err)
 		return
 	}
 	if res.Skipped {
@@ -200,7 +200,7 @@ func (d *Daemon) reloadSite(state *watchState, siteName string) {
 		return
 	}
 	for _, w := range res.Warnings {
-		d.log("Reload %s warning: %s", siteName, w)
+		log.Warn("Reload %s: %s", siteName, w)
 	}
 
 	// Auto-restart on label/compose changes. `docker compose up -d` is
@@ -209,16 +209,16 @@ func (d *Daemon) reloadSite(state *watchState, siteName string) {
 	if res.NeedsRestart {
 		s, err := site.GetByName(siteName)
 		if err != nil || s == nil || s.IsBroken {
-			d.log("Reload %s: container restart skipped (site missing or broken)", siteName)
+			log.Warn("Reload %s: container restart skipped (site missing or broken)", siteName)
 			return
 		}
-		if err := docker.ComposeUpWithProfile(s.ComposeDir, s.Profile); err != nil {
-			d.log("Reload %s: docker compose up failed: %v", siteName, err)
+		if err := site.ComposeUp(s, false); err != nil {
+			log.Error("Reload %s: docker compose up failed: %v", siteName, err)
 			return
 		}
-		d.log("Reload %s: artifacts regenerated and applied via compose up", siteName)
+		log.Info("Reload %s: artifacts regenerated and applied via compose up", siteName)
 	} else {
-		d.log("Reload %s: routing refreshed", siteName)
+		log.Info("Reload %s: routing refreshed", siteName)
 	}
 }
 
@@ -59,7 +59,7 @@ func TestStartMetadataWatcher(t *testing.T) {
 	logPath := filepath.Join(d.cfg.Root, "test.log")
 	f, _ := os.Create(logPath)
 	defer f.Close()
-	d.logFile = f
+	d.logger = NewLogger(LogFormatText, f)
 
 	if err := os.MkdirAll(d.cfg.SitesDir, 0o755); err != nil {
 		t.Fatal(err)
@@ -161,7 +161,7 @@ func TestReloadSiteMissing(t *testing.T) {
 	logPath := filepath.Join(d.cfg.Root, "x.log")
 	f, _ := os.Create(logPath)
 	defer f.Close()
-	d.logFile = f
+	d.logger = NewLogger(LogFormatText, f)
 	state := &watchState{timers: map[string]*time.Timer{}}
 	d.reloadSite(state, "ghost")
 }
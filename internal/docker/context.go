@@ -0,0 +1,140 @@
+// Package docker — context.go tracks the Docker context srv targets. By
+// default every `docker` subprocess and SDK client uses the CLI's current
+// context; `--docker-context NAME` (or DOCKER_CONTEXT) points srv at another
+// endpoint, e.g. a remote daemon over SSH.
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RemoteInfoTimeout replaces the default InfoTimeout for SSH contexts, where
+// the daemon check pays for an SSH handshake. --connect-timeout overrides
+// both.
+const RemoteInfoTimeout = 30 * time.Second
+
+// activeContext is the selected Docker context; "" uses the CLI default.
+var activeContext string
+
+// contextHostLookup resolves a context name to its daemon endpoint. Tests
+// swap it to avoid shelling out to `docker context inspect`.
+var contextHostLookup = defaultContextHostLookup
+
+func defaultContextHostLookup(name string) (string, error) {
+	out, err := exec.Command("docker", "context", "inspect", name, "--format", "{{.Endpoints.docker.Host}}").Output()
+	if err != nil {
+		return "", fmt.Errorf("docker context %q not found: %w", name, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// resolvedContextHost resolves the selected context's endpoint once; every
+// SDK client and provider check after that reuses the answer instead of
+// running `docker context inspect` again.
+var resolvedContextHost = newContextHostResolver()
+
+func newContextHostResolver() func() (string, error) {
+	return sync.OnceValues(func() (string, error) {
+		return contextHostLookup(activeContext)
+	})
+}
+
+// SetContext selects the Docker context for every later docker call. An
+// empty name restores the CLI default.
+func SetContext(name string) {
+	activeContext = name
+	resolvedContextHost = newContextHostResolver()
+}
+
+// ActiveContext returns the selected Docker context, or "" for the default.
+func ActiveContext() string {
+	return activeContext
+}
+
+// ContextHost returns the daemon endpoint of the selected context (e.g.
+// ssh://user@host), or "" when the default context is in use.
+func ContextHost() (string, error) {
+	if activeContext == "" {
+		return "", nil
+	}
+	return resolvedContextHost()
+}
+
+// IsRemoteContext reports whether the selected context reaches its daemon
+// over SSH.
+func IsRemoteContext() bool {
+	host, err := ContextHost()
+	return err == nil && strings.HasPrefix(host, "ssh://")
+}
+
+// CLIArgs prefixes args with `--context NAME` when a context is selected, for
+// callers that shell out to the docker CLI.
+func CLIArgs(args ...string) []string {
+	if activeContext == "" {
+		return args
+	}
+	return append([]string{"--context", activeContext}, args...)
+}
+
+// infoTimeout returns the daemon-check timeout for the selected context.
+func infoTimeout() time.Duration {
+	if timeouts.Connect == 0 && IsRemoteContext() {
+		return RemoteInfoTimeout
+	}
+	return InfoTimeout
+}
+
+// dialStdio connects to the selected context's daemon through `docker system
+// dial-stdio`, which is how the docker CLI itself tunnels to SSH endpoints.
+func dialStdio(_ context.Context, _, _ string) (net.Conn, error) {
+	cmd := exec.Command("docker", CLIArgs("system", "dial-stdio")...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("dial docker context %q: %w", activeContext, err)
+	}
+	return &stdioConn{cmd: cmd, stdin: stdin, stdout: stdout}, nil
+}
+
+// stdioConn adapts a dial-stdio subprocess to net.Conn. Deadlines are not
+// supported; the SDK bounds calls with contexts instead.
+type stdioConn struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout io.ReadCloser
+}
+
+func (c *stdioConn) Read(p []byte) (int, error)  { return c.stdout.Read(p) }
+func (c *stdioConn) Write(p []byte) (int, error) { return c.stdin.Write(p) }
+
+func (c *stdioConn) Close() error {
+	_ = c.stdin.Close()
+	_ = c.cmd.Process.Kill()
+	_ = c.cmd.Wait()
+	return nil
+}
+
+func (c *stdioConn) LocalAddr() net.Addr              { return stdioAddr{} }
+func (c *stdioConn) RemoteAddr() net.Addr             { return stdioAddr{} }
+func (c *stdioConn) SetDeadline(time.Time) error      { return nil }
+func (c *stdioConn) SetReadDeadline(time.Time) error  { return nil }
+func (c *stdioConn) SetWriteDeadline(time.Time) error { return nil }
+
+// stdioAddr is the placeholder address of a stdioConn.
+type stdioAddr struct{}
+
+func (stdioAddr) Network() string { return "stdio" }
+func (stdioAddr) String() string  { return "stdio" }
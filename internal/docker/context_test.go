@@ -0,0 +1,103 @@
+package docker
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// useContext selects a Docker context whose endpoint resolves to host (or
+// fails with err) for the duration of the test.
+func useContext(t *testing.T, name, host string, err error) {
+	t.Helper()
+	prevCtx, prevLookup := activeContext, contextHostLookup
+	t.Cleanup(func() {
+		contextHostLookup = prevLookup
+		SetContext(prevCtx)
+	})
+	contextHostLookup = func(string) (string, error) { return host, err }
+	SetContext(name)
+}
+
+func TestContextHostResolvedOnce(t *testing.T) {
+	useContext(t, "prod", "", nil)
+	lookups := 0
+	contextHostLookup = func(string) (string, error) {
+		lookups++
+		return "ssh://deploy@prod.example.com", nil
+	}
+	SetContext("prod")
+	for range 3 {
+		if host, err := ContextHost(); host != "ssh://deploy@prod.example.com" || err != nil {
+			t.Fatalf("ContextHost = %q, %v", host, err)
+		}
+	}
+	if lookups != 1 {
+		t.Errorf("docker context inspect ran %d times, want once", lookups)
+	}
+}
+
+func TestCLIArgsDefaultContext(t *testing.T) {
+	useContext(t, "", "", nil)
+	if got := strings.Join(CLIArgs("compose", "ps"), " "); got != "compose ps" {
+		t.Errorf("CLIArgs = %q", got)
+	}
+	if host, err := ContextHost(); host != "" || err != nil {
+		t.Errorf("ContextHost = %q, %v", host, err)
+	}
+	if infoTimeout() != InfoTimeout {
+		t.Error("default context should use InfoTimeout")
+	}
+}
+
+func TestCLIArgsRemoteContext(t *testing.T) {
+	useContext(t, "prod", "ssh://deploy@prod.example.com", nil)
+	if got := strings.Join(CLIArgs("compose", "ps"), " "); got != "--context prod compose ps" {
+		t.Errorf("CLIArgs = %q", got)
+	}
+	if !IsRemoteContext() {
+		t.Error("ssh:// endpoint should be remote")
+	}
+	if infoTimeout() != RemoteInfoTimeout {
+		t.Error("remote context should use RemoteInfoTimeout")
+	}
+}
+
+func TestSetTimeouts(t *testing.T) {
+	t.Cleanup(func() { SetTimeouts(Timeouts{}) })
+	useContext(t, "prod", "ssh://deploy@prod.example.com", nil)
+
+	SetTimeouts(Timeouts{Connect: time.Minute, Response: 2 * time.Minute})
+	if infoTimeout() != time.Minute {
+		t.Errorf("infoTimeout = %v, want --connect-timeout over RemoteInfoTimeout", infoTimeout())
+	}
+	if StatusTimeout != 2*time.Minute || ComposeTimeout != DefaultComposeTimeout {
+		t.Errorf("StatusTimeout = %v, ComposeTimeout = %v", StatusTimeout, ComposeTimeout)
+	}
+	SetTimeouts(Timeouts{Response: 10 * time.Minute})
+	if ComposeTimeout != 10*time.Minute || InfoTimeout != DefaultInfoTimeout {
+		t.Errorf("ComposeTimeout = %v, InfoTimeout = %v", ComposeTimeout, InfoTimeout)
+	}
+	SetTimeouts(Timeouts{})
+	if StatusTimeout != DefaultStatusTimeout || infoTimeout() != RemoteInfoTimeout {
+		t.Errorf("defaults not restored: StatusTimeout = %v, infoTimeout = %v", StatusTimeout, infoTimeout())
+	}
+}
+
+func TestIsRemoteContextLocalEndpoint(t *testing.T) {
+	useContext(t, "colima", "unix:///Users/me/.colima/default/docker.sock", nil)
+	if IsRemoteContext() {
+		t.Error("unix socket endpoint should not be remote")
+	}
+}
+
+func TestNewClientUnknownContext(t *testing.T) {
+	useContext(t, "ghost", "", errors.New("no such context"))
+	if _, err := newClientFn(); err == nil {
+		t.Error("expected error for an unknown context")
+	}
+	if IsRemoteContext() {
+		t.Error("unknown context should not be remote")
+	}
+}
@@ -2,18 +2,26 @@
 package docker
 
 import (
+	"bufio"
+	"bytes"
+	"cmp"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	cerrdefs "github.com/containerd/errdefs"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/network"
@@ -24,25 +32,70 @@ import (
 )
 
 // notRunningErr renders the "docker is not running" message with a platform-
-// appropriate hint. macOS has no `systemctl`, so we point at Docker Desktop /
-// `colima start` instead.
+// appropriate hint. A recognised provider (OrbStack, Colima, Docker Desktop)
+// gets its own start command; otherwise macOS, which has no `systemctl`, is
+// pointed at Docker Desktop / `colima start` (or `podman machine start`).
 func notRunningErr() error {
+	if ActiveRuntime() == RuntimePodman {
+		if platform.IsDarwin() {
+			return fmt.Errorf("podman is not running.\n  Start it with: podman machine start")
+		}
+		return fmt.Errorf("podman's API service is not running.\n  Start it with: systemctl --user start podman.socket")
+	}
+	if err := providerNotRunningErr(DetectDockerProvider()); err != nil {
+		return err
+	}
 	if platform.IsDarwin() {
 		return fmt.Errorf("docker is not running or not installed.\n  Start Docker Desktop, or run `colima start` if you're on Colima")
 	}
 	return fmt.Errorf("docker is not running or not installed.\n  Start Docker Desktop or run: sudo systemctl start docker")
 }
 
-// Timeout constants for Docker operations.
+// Default timeouts for Docker operations.
 const (
+	DefaultInfoTimeout    = 10 * time.Second
+	DefaultStatusTimeout  = 30 * time.Second
+	DefaultComposeTimeout = 5 * time.Minute
+)
+
+// Timeouts for Docker operations. SetTimeouts overrides them.
+var (
 	// InfoTimeout is for quick daemon-availability checks.
-	InfoTimeout = 10 * time.Second
+	InfoTimeout = DefaultInfoTimeout
 	// StatusTimeout is for status/inspect operations.
-	StatusTimeout = 30 * time.Second
+	StatusTimeout = DefaultStatusTimeout
 	// ComposeTimeout is for compose operations (image pulls, builds, etc.).
-	ComposeTimeout = 5 * time.Minute
+	ComposeTimeout = DefaultComposeTimeout
 )
 
+// Timeouts overrides the Docker operation timeouts, from --connect-timeout
+// and --response-timeout or the daemon's settings. A zero field keeps the
+// default.
+type Timeouts struct {
+	// Connect bounds daemon-availability checks, local or over SSH.
+	Connect time.Duration
+	// Response bounds status and inspect calls. Compose operations get at
+	// least this long, and never less than DefaultComposeTimeout.
+	Response time.Duration
+}
+
+// timeouts holds the overrides SetTimeouts applied.
+var timeouts Timeouts
+
+// SetTimeouts applies t to InfoTimeout, StatusTimeout and ComposeTimeout.
+// SetTimeouts(Timeouts{}) restores the defaults.
+func SetTimeouts(t Timeouts) {
+	timeouts = t
+	InfoTimeout = cmp.Or(t.Connect, DefaultInfoTimeout)
+	StatusTimeout = cmp.Or(t.Response, DefaultStatusTimeout)
+	ComposeTimeout = max(t.Response, DefaultComposeTimeout)
+}
+
+// CurrentTimeouts returns the overrides in effect.
+func CurrentTimeouts() Timeouts {
+	return timeouts
+}
+
 // Image constants for Docker images used by the application.
 const (
 	// ImageTraefik is the Traefik reverse proxy image used for routing.
@@ -68,16 +121,45 @@ type sdkClient interface {
 	NetworkCreate(ctx context.Context, name string, opts network.CreateOptions) (network.CreateResponse, error)
 	NetworkRemove(ctx context.Context, name string) error
 	NetworkConnect(ctx context.Context, networkID, containerID string, cfg *network.EndpointSettings) error
+	NetworkDisconnect(ctx context.Context, networkID, containerID string, force bool) error
 	ContainerInspect(ctx context.Context, name string) (container.InspectResponse, error)
 	ContainerList(ctx context.Context, opts container.ListOptions) ([]container.Summary, error)
 	ImagePull(ctx context.Context, ref string, opts image.PullOptions) (io.ReadCloser, error)
+	Events(ctx context.Context, opts events.ListOptions) (<-chan events.Message, <-chan error)
+	Close() error
+}
+
+// EventsClient streams Docker events. The daemon's event loop holds one for
+// the length of a watch session.
+type EventsClient interface {
+	Events(ctx context.Context, opts events.ListOptions) (<-chan events.Message, <-chan error)
 	Close() error
 }
 
 // newClientFn produces an sdkClient. Tests swap this to install a fake. By
-// default it dials the daemon described by the standard Docker env vars.
+// default it dials the daemon described by the standard Docker env vars, or
+// the selected context's endpoint (SSH endpoints tunnel via dial-stdio).
 var newClientFn = func() (sdkClient, error) {
-	return dockerclient.NewClientWithOpts(dockerclient.FromEnv, dockerclient.WithAPIVersionNegotiation())
+	opts := []dockerclient.Opt{dockerclient.FromEnv, dockerclient.WithAPIVersionNegotiation()}
+	host, err := ContextHost()
+	if err != nil {
+		return nil, err
+	}
+	if host == "" && ActiveRuntime() == RuntimePodman && os.Getenv("DOCKER_HOST") == "" {
+		// dial-stdio is a docker CLI feature, so a remote (ssh://) Podman
+		// service is left to DOCKER_HOST.
+		if h := podmanHost(); !strings.HasPrefix(h, "ssh://") {
+			host = h
+		}
+	}
+	switch {
+	case strings.HasPrefix(host, "ssh://"):
+		// The host only names the HTTP requests; dialStdio carries the bytes.
+		opts = append(opts, dockerclient.WithHost("http://docker.example.com"), dockerclient.WithDialContext(dialStdio))
+	case host != "":
+		opts = append(opts, dockerclient.WithHost(host))
+	}
+	return dockerclient.NewClientWithOpts(opts...)
 }
 
 // SwapNewClient replaces the SDK client factory and returns a function that
@@ -111,6 +193,42 @@ func SwapNewClientWithNetwork(name string) func() {
 	})
 }
 
+// SwapNewClientWithContainer returns a factory whose ContainerInspect
+// reports one container, running or stopped. Convenience helper for tests
+// that need to tell a stopped container from a missing one.
+func SwapNewClientWithContainer(name string, running bool) func() {
+	return SwapNewClient(func() (sdkClient, error) {
+		return containerFakeSDK{sdkClient: noopSDK{}, name: name, running: running}, nil
+	})
+}
+
+// SwapNewClientWithNetworkAndContainer combines SwapNewClientWithNetwork and
+// SwapNewClientWithContainer, for tests that need EnsureInitialized to pass
+// and a site's container to report a status.
+func SwapNewClientWithNetworkAndContainer(networkName, name string, running bool) func() {
+	return SwapNewClient(func() (sdkClient, error) {
+		return containerFakeSDK{sdkClient: networkFakeSDK{networkName: networkName}, name: name, running: running}, nil
+	})
+}
+
+// containerFakeSDK wraps another fake (noopSDK or networkFakeSDK) and
+// reports one container.
+type containerFakeSDK struct {
+	sdkClient
+	name    string
+	running bool
+}
+
+func (f containerFakeSDK) ContainerInspect(ctx context.Context, name string) (container.InspectResponse, error) {
+	if name != f.name {
+		return f.sdkClient.ContainerInspect(ctx, name)
+	}
+	return container.InspectResponse{ContainerJSONBase: &container.ContainerJSONBase{
+		Name:  "/" + name,
+		State: &container.State{Running: f.running},
+	}}, nil
+}
+
 // networkFakeSDK is a noopSDK that reports one network as existing.
 type networkFakeSDK struct {
 	noopSDK
@@ -127,9 +245,15 @@ func newClient() (sdkClient, error) {
 	return newClientFn()
 }
 
+// NewEventsClient returns a client for the selected Docker context, built
+// like every other SDK client srv uses. The caller closes it.
+func NewEventsClient() (EventsClient, error) {
+	return newClient()
+}
+
 // EnsureRunning checks that Docker is available and running.
 func EnsureRunning() error {
-	ctx, cancel := context.WithTimeout(context.Background(), InfoTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), infoTimeout())
 	defer cancel()
 
 	cli, err := newClient()
@@ -238,31 +362,46 @@ func ComposeUpForceRecreate(dir string) error {
 }
 
 // ComposeUpWithProfile runs docker compose up -d with a specific profile.
-// See ComposeUp for why --remove-orphans is deliberately omitted.
-func ComposeUpWithProfile(dir, profile string) error {
-	args := []string{"up", "-d"}
+// files, when given, replace the directory's default compose file lookup and
+// are layered in order (see ComposeFileArgs). See ComposeUp for why
+// --remove-orphans is deliberately omitted.
+func ComposeUpWithProfile(dir, profile string, files ...string) error {
+	args := append(ComposeFileArgs(files), "up", "-d")
 	if profile != "" {
 		return Compose(dir, append([]string{"--profile", profile}, args...)...)
 	}
 	return Compose(dir, args...)
 }
 
-// ComposeUpBuildWithProfile runs docker compose up -d --build with a specific profile.
-func ComposeUpBuildWithProfile(dir, profile string) error {
-	args := []string{"up", "-d", "--build"}
+// ComposeUpBuildWithProfile runs docker compose up -d --build with a specific
+// profile, layering files like ComposeUpWithProfile.
+func ComposeUpBuildWithProfile(dir, profile string, files ...string) error {
+	args := append(ComposeFileArgs(files), "up", "-d", "--build")
 	if profile != "" {
 		return Compose(dir, append([]string{"--profile", profile}, args...)...)
 	}
 	return Compose(dir, args...)
 }
 
+// ComposeFileArgs turns compose files into `-f FILE` flags. Later files
+// override earlier ones; passing any disables compose's own lookup of
+// docker-compose.yml / docker-compose.override.yml in the directory.
+func ComposeFileArgs(files []string) []string {
+	args := make([]string, 0, 2*len(files))
+	for _, f := range files {
+		args = append(args, "-f", f)
+	}
+	return args
+}
+
 // ComposeDown runs docker compose down in the specified directory. It does NOT
 // pass --remove-orphans: under the shared "srv" compose project that would tear
 // down every other stack's containers (other sites + metrics), not just this
 // one's. Down already removes the containers/networks defined in this dir's
-// compose file, which is the intended scope.
-func ComposeDown(dir string) error {
-	return Compose(dir, "down")
+// compose file, which is the intended scope. files are layered like
+// ComposeUpWithProfile's.
+func ComposeDown(dir string, files ...string) error {
+	return Compose(dir, append(ComposeFileArgs(files), "down")...)
 }
 
 // RemoveComposeProjectContainers force-removes every container belonging to the
@@ -272,7 +411,7 @@ func ComposeDown(dir string) error {
 // reuse their fixed container_names without a name conflict. No-op when none
 // match.
 func RemoveComposeProjectContainers(project string) error {
-	out, err := exec.Command("docker", "ps", "-aq", "--filter", "label=com.docker.compose.project="+project).Output()
+	out, err := Command(context.Background(), "ps", "-aq", "--filter", "label=com.docker.compose.project="+project).Output()
 	if err != nil {
 		return fmt.Errorf("list project %q containers: %w", project, err)
 	}
@@ -280,7 +419,7 @@ func RemoveComposeProjectContainers(project string) error {
 	if len(ids) == 0 {
 		return nil
 	}
-	if err := exec.Command("docker", append([]string{"rm", "-f"}, ids...)...).Run(); err != nil {
+	if err := Command(context.Background(), append([]string{"rm", "-f"}, ids...)...).Run(); err != nil {
 		return fmt.Errorf("remove project %q containers: %w", project, err)
 	}
 	return nil
@@ -290,7 +429,7 @@ func RemoveComposeProjectContainers(project string) error {
 var composePrefixedExec = defaultComposePrefixedExec
 
 func defaultComposePrefixedExec(dir, prefix string, args ...string) error {
-	cmd := exec.Command("docker", append([]string{"compose"}, args...)...)
+	cmd := composeCommand(context.Background(), args...)
 	cmd.Dir = dir
 	cmd.Stdout = newPrefixWriter(os.Stdout, prefix)
 	cmd.Stderr = newPrefixWriter(os.Stderr, prefix)
@@ -305,12 +444,80 @@ func SwapComposePrefixedExec(fn func(dir, prefix string, args ...string) error)
 }
 
 // ComposePrefixed runs `docker compose <args...>` in dir and pipes stdout +
-// stderr through a writer that prefixes every line with `[prefix] `. Used by
-// `srv logs --all` to multiplex many sites into one terminal.
+// stderr through a writer that prefixes every line with `[prefix] `, so the
+// output of several stacks can share one terminal.
 func ComposePrefixed(dir, prefix string, args ...string) error {
 	return composePrefixedExec(dir, prefix, args...)
 }
 
+// composeStreamExec is the seam behind ComposeFiltered: it runs compose in
+// dir with stdout written to w and stderr attached.
+var composeStreamExec = defaultComposeStreamExec
+
+func defaultComposeStreamExec(dir string, w io.Writer, args ...string) error {
+	cmd := composeCommand(context.Background(), args...)
+	cmd.Dir = dir
+	cmd.Stdout = w
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// SwapComposeStreamExec replaces the ComposeFiltered invoker. Returns a
+// restore func suitable for t.Cleanup.
+func SwapComposeStreamExec(fn func(dir string, w io.Writer, args ...string) error) func() {
+	prev := composeStreamExec
+	composeStreamExec = fn
+	return func() { composeStreamExec = prev }
+}
+
+// ComposeStream runs `docker compose <args...>` in dir with stdout written
+// to w and stderr attached, for callers that post-process the output as it
+// arrives (e.g. `srv logs --merge-all` through an io.Pipe).
+func ComposeStream(dir string, w io.Writer, args ...string) error {
+	return composeStreamExec(dir, w, args...)
+}
+
+// ComposeFiltered runs `docker compose <args...>` in dir and prints only the
+// stdout lines keep accepts, stamped with `[prefix] ` when prefix is set (as
+// ComposePrefixed does). Output streams through an io.Pipe into a filtering
+// goroutine, so it works for `logs -f` as well as one-shot output. Stderr is
+// not filtered, so compose's own errors still show.
+func ComposeFiltered(dir, prefix string, keep func(line string) bool, args ...string) error {
+	var out io.Writer = os.Stdout
+	if prefix != "" {
+		out = newPrefixWriter(os.Stdout, prefix)
+	}
+	pr, pw := io.Pipe()
+	done := make(chan error, 1)
+	go func() { done <- filterLines(pr, out, keep) }()
+	err := composeStreamExec(dir, pw, args...)
+	_ = pw.Close()
+	if ferr := <-done; err == nil {
+		err = ferr
+	}
+	return err
+}
+
+// filterLines copies the lines of r that keep accepts to w. On a read error
+// the rest of r is drained so the writing side never blocks.
+func filterLines(r io.Reader, w io.Writer, keep func(string) bool) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if line := scanner.Text(); keep(line) {
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				_, _ = io.Copy(io.Discard, r)
+				return err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		_, _ = io.Copy(io.Discard, r)
+		return err
+	}
+	return nil
+}
+
 // prefixWriter prefixes every newline-terminated chunk it sees with "[name] ".
 // Partial lines are buffered until the terminating \n arrives so each prefix
 // lands at the start of a real line.
@@ -350,14 +557,16 @@ func indexByte(b []byte, c byte) int {
 	return -1
 }
 
-// ComposeStop runs docker compose stop in the specified directory.
-func ComposeStop(dir string) error {
-	return Compose(dir, "stop")
+// ComposeStop runs docker compose stop in the specified directory, layering
+// files like ComposeUpWithProfile.
+func ComposeStop(dir string, files ...string) error {
+	return Compose(dir, append(ComposeFileArgs(files), "stop")...)
 }
 
-// ComposeRestart runs docker compose restart in the specified directory.
-func ComposeRestart(dir string) error {
-	return Compose(dir, "restart")
+// ComposeRestart runs docker compose restart in the specified directory,
+// layering files like ComposeUpWithProfile.
+func ComposeRestart(dir string, files ...string) error {
+	return Compose(dir, append(ComposeFileArgs(files), "restart")...)
 }
 
 // dockerExec is the swappable seam for Exec / ExecNonInteractive[At]. mode
@@ -365,7 +574,7 @@ func ComposeRestart(dir string) error {
 var dockerExec = defaultDockerExec
 
 func defaultDockerExec(interactive bool, args ...string) error {
-	cmd := exec.Command("docker", args...)
+	cmd := Command(context.Background(), args...)
 	if interactive {
 		cmd.Stdin = os.Stdin
 	}
@@ -415,7 +624,7 @@ func defaultComposeExec(dir string, quiet bool, args ...string) error {
 	if quiet {
 		ctx, cancel := context.WithTimeout(context.Background(), ComposeTimeout)
 		defer cancel()
-		cmd := exec.CommandContext(ctx, "docker", append([]string{"compose"}, args...)...)
+		cmd := composeCommand(ctx, args...)
 		cmd.Dir = dir
 		cmd.Stdin = nil
 		err := cmd.Run()
@@ -424,13 +633,39 @@ func defaultComposeExec(dir string, quiet bool, args ...string) error {
 		}
 		return err
 	}
-	cmd := exec.Command("docker", append([]string{"compose"}, args...)...)
+	cmd := composeCommand(context.Background(), args...)
 	cmd.Dir = dir
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
 }
 
+// composeAttachedExec is the seam behind ComposeAttached.
+var composeAttachedExec = defaultComposeAttachedExec
+
+func defaultComposeAttachedExec(dir string, args ...string) error {
+	cmd := composeCommand(context.Background(), args...)
+	cmd.Dir = dir
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// SwapComposeAttachedExec replaces the ComposeAttached implementation.
+func SwapComposeAttachedExec(fn func(dir string, args ...string) error) func() {
+	prev := composeAttachedExec
+	composeAttachedExec = fn
+	return func() { composeAttachedExec = prev }
+}
+
+// ComposeAttached is Compose with stdin attached as well, for interactive
+// commands such as `docker compose exec`. Compose keeps stdin detached so
+// `srv mcp` (which speaks its protocol over stdin) can't lose input to it.
+func ComposeAttached(dir string, args ...string) error {
+	return composeAttachedExec(dir, args...)
+}
+
 // SwapComposeExec replaces the compose subprocess invoker. Returns a restore
 // func suitable for t.Cleanup. Use this to assert on the args a compose call
 // was made with.
@@ -461,6 +696,30 @@ func ComposeQuietWithProfile(dir, profile string, args ...string) error {
 	return ComposeQuiet(dir, append([]string{"--profile", profile}, args...)...)
 }
 
+// composeOutputExec is the seam behind ComposeOutput.
+var composeOutputExec = defaultComposeOutputExec
+
+func defaultComposeOutputExec(dir string, args ...string) ([]byte, error) {
+	cmd := composeCommand(context.Background(), args...)
+	cmd.Dir = dir
+	return cmd.CombinedOutput()
+}
+
+// SwapComposeOutputExec replaces the ComposeOutput implementation. Returns a
+// restore func.
+func SwapComposeOutputExec(fn func(dir string, args ...string) ([]byte, error)) func() {
+	prev := composeOutputExec
+	composeOutputExec = fn
+	return func() { composeOutputExec = prev }
+}
+
+// ComposeOutput runs docker compose and returns its combined output instead
+// of attaching it, for long runs such as image builds shown behind a spinner.
+// Unlike ComposeQuiet there's no timeout: a cold build can take far longer.
+func ComposeOutput(dir string, args ...string) ([]byte, error) {
+	return composeOutputExec(dir, args...)
+}
+
 // composePSOutput is the seam tests override to provide canned `docker compose
 // ps` output without spawning a subprocess.
 var composePSOutput = defaultComposePSOutput
@@ -468,7 +727,7 @@ var composePSOutput = defaultComposePSOutput
 func defaultComposePSOutput(dir string) ([]byte, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), StatusTimeout)
 	defer cancel()
-	cmd := exec.CommandContext(ctx, "docker", "compose", "ps", "--format", constants.ComposeStatusFormat)
+	cmd := composeCommand(ctx, "ps", "--format", constants.ComposeStatusFormat)
 	cmd.Dir = dir
 	return cmd.Output()
 }
@@ -481,6 +740,43 @@ func SwapComposePSOutput(fn func(dir string) ([]byte, error)) func() {
 	return func() { composePSOutput = prev }
 }
 
+// composeConfigCheck is the seam behind ComposeConfigCheck; tests swap it to
+// skip the docker subprocess.
+var composeConfigCheck = defaultComposeConfigCheck
+
+func defaultComposeConfigCheck(file string) error {
+	if _, err := exec.LookPath(ComposeCmd()[0]); err != nil {
+		return nil // nothing to check against; callers still parse the YAML
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), StatusTimeout)
+	defer cancel()
+	cmd := composeCommand(ctx, "-f", file, "config", "--quiet")
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return errors.New(msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// SwapComposeConfigCheck replaces the compose config validator. Returns a
+// restore func for t.Cleanup.
+func SwapComposeConfigCheck(fn func(file string) error) func() {
+	prev := composeConfigCheck
+	composeConfigCheck = fn
+	return func() { composeConfigCheck = prev }
+}
+
+// ComposeConfigCheck runs `docker compose -f FILE config --quiet`, which
+// resolves and validates the compose file without starting anything. Returns
+// compose's own error text on failure; a no-op when the compose CLI is absent.
+func ComposeConfigCheck(file string) error {
+	return composeConfigCheck(file)
+}
+
 // ContainerStatus returns the status of containers in a compose project directory.
 // Returns "running", "stopped", or "partial (n/m)".
 func ContainerStatus(dir string) string {
@@ -525,6 +821,139 @@ func aggregateStatus(running, total int) string {
 	}
 }
 
+// ComposeContainer is one container of a compose project as `srv ps` shows it.
+type ComposeContainer struct {
+	Service string `json:"service"`
+	Name    string `json:"name"`
+	State   string `json:"state"`
+	Status  string `json:"status"`
+	Ports   string `json:"ports"`
+}
+
+// composePSEntry is the subset of `docker compose ps --format json` srv reads.
+// Older compose releases omit Ports and only report Publishers.
+type composePSEntry struct {
+	Service    string
+	Name       string
+	State      string
+	Status     string
+	Ports      string
+	Publishers []struct {
+		URL           string
+		TargetPort    int
+		PublishedPort int
+		Protocol      string
+	}
+}
+
+// composePSJSON is the seam behind ComposePS; tests swap it to return canned
+// `docker compose ps --format json` output.
+var composePSJSON = defaultComposePSJSON
+
+func defaultComposePSJSON(dir string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), StatusTimeout)
+	defer cancel()
+	cmd := composeCommand(ctx, args...)
+	cmd.Dir = dir
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, errors.New(msg)
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+// SwapComposePSJSON replaces the compose ps JSON provider used by ComposePS.
+// Returns a restore func for t.Cleanup.
+func SwapComposePSJSON(fn func(dir string, args ...string) ([]byte, error)) func() {
+	prev := composePSJSON
+	composePSJSON = fn
+	return func() { composePSJSON = prev }
+}
+
+// ComposePS lists the containers of the compose project in dir, sorted by
+// service and name. Stopped containers are only included when all is set;
+// profile selects services that are behind a compose profile.
+func ComposePS(dir, profile string, all bool) ([]ComposeContainer, error) {
+	var args []string
+	if profile != "" {
+		args = append(args, "--profile", profile)
+	}
+	args = append(args, "ps", "--format", "json")
+	if all {
+		args = append(args, "--all")
+	}
+	out, err := composePSJSON(dir, args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseComposePSJSON(out)
+}
+
+// parseComposePSJSON reads `docker compose ps --format json` output, which is
+// one JSON object per line on current compose releases and a single JSON
+// array on older ones.
+func parseComposePSJSON(out []byte) ([]ComposeContainer, error) {
+	out = bytes.TrimSpace(out)
+	var entries []composePSEntry
+	if bytes.HasPrefix(out, []byte("[")) {
+		if err := json.Unmarshal(out, &entries); err != nil {
+			return nil, fmt.Errorf("parse compose ps output: %w", err)
+		}
+	} else {
+		for _, line := range bytes.Split(out, []byte("\n")) {
+			if line = bytes.TrimSpace(line); len(line) == 0 {
+				continue
+			}
+			var e composePSEntry
+			if err := json.Unmarshal(line, &e); err != nil {
+				return nil, fmt.Errorf("parse compose ps output: %w", err)
+			}
+			entries = append(entries, e)
+		}
+	}
+
+	containers := make([]ComposeContainer, 0, len(entries))
+	for _, e := range entries {
+		ports := e.Ports
+		if ports == "" {
+			ports = formatPublishers(e)
+		}
+		containers = append(containers, ComposeContainer{
+			Service: e.Service,
+			Name:    e.Name,
+			State:   e.State,
+			Status:  e.Status,
+			Ports:   ports,
+		})
+	}
+	sort.Slice(containers, func(i, j int) bool {
+		if containers[i].Service != containers[j].Service {
+			return containers[i].Service < containers[j].Service
+		}
+		return containers[i].Name < containers[j].Name
+	})
+	return containers, nil
+}
+
+// formatPublishers renders Publishers the way docker ps shows ports, e.g.
+// "0.0.0.0:8080->80/tcp, 443/tcp".
+func formatPublishers(e composePSEntry) string {
+	parts := make([]string, 0, len(e.Publishers))
+	for _, p := range e.Publishers {
+		if p.PublishedPort == 0 {
+			parts = append(parts, fmt.Sprintf("%d/%s", p.TargetPort, p.Protocol))
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s:%d->%d/%s", p.URL, p.PublishedPort, p.TargetPort, p.Protocol))
+	}
+	return strings.Join(parts, ", ")
+}
+
 // ContainerStatusByName returns the status of a single named container using
 // the Docker SDK (no subprocess). Returns "running", "stopped", or "partial (n/m)".
 // Falls back to ContainerStatus if the SDK call fails.
@@ -582,6 +1011,37 @@ func ContainerStatusByComposeDir(dir string) string {
 	return aggregateStatus(running, total)
 }
 
+// ComposeContainerNames returns the names of every container (running or
+// stopped) belonging to the compose project directory dir, sorted. Uses the
+// same working-dir label lookup as ContainerStatusByComposeDir.
+func ComposeContainerNames(dir string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), StatusTimeout)
+	defer cancel()
+
+	cli, err := newClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Docker: %w", err)
+	}
+	defer func() { _ = cli.Close() }()
+
+	f := filters.NewArgs(
+		filters.Arg("label", "com.docker.compose.project.working_dir="+dir),
+	)
+	containers, err := cli.ContainerList(ctx, container.ListOptions{All: true, Filters: f})
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(containers))
+	for _, c := range containers {
+		if len(c.Names) > 0 {
+			names = append(names, strings.TrimPrefix(c.Names[0], "/"))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
 // IsContainerRunning checks if a container with the given name is currently running.
 func IsContainerRunning(name string) bool {
 	ctx, cancel := context.WithTimeout(context.Background(), StatusTimeout)
@@ -631,7 +1091,7 @@ var ErrServiceNotRunning = errors.New("service not running")
 var composeServiceIDLookup = defaultComposeServiceIDLookup
 
 func defaultComposeServiceIDLookup(ctx context.Context, dir, serviceName string) (string, error) {
-	cmd := exec.CommandContext(ctx, "docker", "compose", "ps", "-q", serviceName)
+	cmd := composeCommand(ctx, "ps", "-q", serviceName)
 	cmd.Dir = dir
 	out, err := cmd.Output()
 	if err != nil {
@@ -648,6 +1108,86 @@ func SwapComposeServiceIDLookup(fn func(ctx context.Context, dir, serviceName st
 	return func() { composeServiceIDLookup = prev }
 }
 
+// ComposeServiceContainerID resolves a compose service in dir to its container
+// ID via `docker compose ps -q`. Returns ErrServiceNotRunning when the service
+// has no container.
+func ComposeServiceContainerID(dir, serviceName string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), StatusTimeout)
+	defer cancel()
+
+	containerID, err := composeServiceIDLookup(ctx, dir, serviceName)
+	if err != nil || containerID == "" {
+		return "", ErrServiceNotRunning
+	}
+	// Scaled services print one ID per line; exec into the first.
+	if i := strings.IndexByte(containerID, '\n'); i >= 0 {
+		containerID = containerID[:i]
+	}
+	return containerID, nil
+}
+
+// ContainerStats is a one-shot resource usage sample of a container, as
+// formatted by `docker stats` (e.g. MemUsage "12.5MiB", NetIO "1.2kB / 0B").
+type ContainerStats struct {
+	CPUPercent string `json:"cpu_percent"`
+	MemUsage   string `json:"mem_usage"`
+	MemLimit   string `json:"mem_limit"`
+	NetIO      string `json:"net_io"`
+}
+
+// containerStatsOutput is the seam behind GetContainerStats. Tests override it
+// to skip the docker subprocess.
+var containerStatsOutput = defaultContainerStatsOutput
+
+func defaultContainerStatsOutput(ctx context.Context, name string) ([]byte, error) {
+	return Command(ctx, "stats", "--no-stream", "--format", "json", name).Output()
+}
+
+// SwapContainerStatsOutput replaces the `docker stats` invoker. Returns a
+// restore func suitable for t.Cleanup.
+func SwapContainerStatsOutput(fn func(ctx context.Context, name string) ([]byte, error)) func() {
+	prev := containerStatsOutput
+	containerStatsOutput = fn
+	return func() { containerStatsOutput = prev }
+}
+
+// GetContainerStats samples a container's CPU, memory and network usage via
+// `docker stats --no-stream`.
+func GetContainerStats(name string) (*ContainerStats, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), StatusTimeout)
+	defer cancel()
+
+	out, err := containerStatsOutput(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("docker stats %s: %w", name, err)
+	}
+	return parseContainerStats(out)
+}
+
+// parseContainerStats decodes the first line of `docker stats --format json`
+// output, splitting "USAGE / LIMIT" into its halves.
+func parseContainerStats(out []byte) (*ContainerStats, error) {
+	line, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	if line == "" {
+		return nil, errors.New("docker stats returned no data")
+	}
+	var raw struct {
+		CPUPerc  string `json:"CPUPerc"`
+		MemUsage string `json:"MemUsage"`
+		NetIO    string `json:"NetIO"`
+	}
+	if err := json.Unmarshal([]byte(line), &raw); err != nil {
+		return nil, fmt.Errorf("parse docker stats: %w", err)
+	}
+	usage, limit, _ := strings.Cut(raw.MemUsage, " / ")
+	return &ContainerStats{
+		CPUPercent: raw.CPUPerc,
+		MemUsage:   strings.TrimSpace(usage),
+		MemLimit:   strings.TrimSpace(limit),
+		NetIO:      raw.NetIO,
+	}, nil
+}
+
 // ConnectServiceToNetwork connects a docker compose service's container(s) to a
 // network with a named alias so Traefik can route to the service by name.
 // Returns ErrServiceNotRunning if the service container is not found.
@@ -681,6 +1221,109 @@ func ContainerExists(name string) bool {
 	return err == nil
 }
 
+// IsContainerOnNetwork reports whether the named container is attached to
+// networkName. It errors when the container can't be inspected.
+func IsContainerOnNetwork(containerName, networkName string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), StatusTimeout)
+	defer cancel()
+
+	cli, err := newClient()
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to Docker: %w", err)
+	}
+	defer func() { _ = cli.Close() }()
+
+	info, err := cli.ContainerInspect(ctx, containerName)
+	if err != nil {
+		return false, fmt.Errorf("failed to inspect container %s: %w", containerName, err)
+	}
+	if info.NetworkSettings == nil {
+		return false, nil
+	}
+	_, ok := info.NetworkSettings.Networks[networkName]
+	return ok, nil
+}
+
+// ContainerStartedAt returns when the named container was last started.
+func ContainerStartedAt(containerName string) (time.Time, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), StatusTimeout)
+	defer cancel()
+
+	cli, err := newClient()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to connect to Docker: %w", err)
+	}
+	defer func() { _ = cli.Close() }()
+
+	info, err := cli.ContainerInspect(ctx, containerName)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to inspect container %s: %w", containerName, err)
+	}
+	if info.ContainerJSONBase == nil || info.State == nil || info.State.StartedAt == "" {
+		return time.Time{}, fmt.Errorf("container %s has never been started", containerName)
+	}
+	started, err := time.Parse(time.RFC3339Nano, info.State.StartedAt)
+	if err != nil || started.IsZero() {
+		return time.Time{}, fmt.Errorf("container %s has never been started", containerName)
+	}
+	return started, nil
+}
+
+// containerStartEventsOutput is the seam behind ContainerStarts. Tests
+// override it to skip the docker subprocess.
+var containerStartEventsOutput = defaultContainerStartEventsOutput
+
+func defaultContainerStartEventsOutput(ctx context.Context, name string, since, until time.Time) ([]byte, error) {
+	return Command(ctx, "events",
+		"--since", strconv.FormatInt(since.Unix(), 10),
+		"--until", strconv.FormatInt(until.Unix(), 10),
+		"--filter", "event=start",
+		"--filter", "container="+name,
+		"--format", "{{.TimeNano}}").Output()
+}
+
+// SwapContainerStartEventsOutput replaces the `docker events` invoker.
+// Returns a restore func suitable for t.Cleanup.
+func SwapContainerStartEventsOutput(fn func(ctx context.Context, name string, since, until time.Time) ([]byte, error)) func() {
+	prev := containerStartEventsOutput
+	containerStartEventsOutput = fn
+	return func() { containerStartEventsOutput = prev }
+}
+
+// ContainerStarts lists when the named container was started within the
+// last window, most recent first, from the daemon's `docker events` history.
+// The daemon only keeps a limited backlog, so old starts may be missing.
+func ContainerStarts(containerName string, window time.Duration) ([]time.Time, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), StatusTimeout)
+	defer cancel()
+
+	now := time.Now()
+	out, err := containerStartEventsOutput(ctx, containerName, now.Add(-window), now)
+	if err != nil {
+		return nil, fmt.Errorf("docker events %s: %w", containerName, err)
+	}
+	return parseStartEvents(out)
+}
+
+// parseStartEvents decodes `docker events --format {{.TimeNano}}` output, one
+// nanosecond timestamp per line, into start times, most recent first.
+func parseStartEvents(out []byte) ([]time.Time, error) {
+	var starts []time.Time
+	for line := range strings.Lines(string(out)) {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		nanos, err := strconv.ParseInt(line, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse docker events: unexpected line %q", line)
+		}
+		starts = append(starts, time.Unix(0, nanos))
+	}
+	slices.SortFunc(starts, func(a, b time.Time) int { return b.Compare(a) })
+	return starts, nil
+}
+
 // GetContainerImageVersion returns the image tag for a running container.
 // Returns an empty string if the container is not found or the image has no tag.
 func GetContainerImageVersion(containerName string) string {
@@ -733,6 +1376,324 @@ func ConnectContainerToNetwork(containerName, networkName, alias string) error {
 	return connectContainerByID(ctx, containerName, networkName, alias)
 }
 
+// DisconnectContainerFromNetwork detaches a container from a network. A
+// container that no longer exists is treated as already disconnected.
+func DisconnectContainerFromNetwork(containerName, networkName string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), StatusTimeout)
+	defer cancel()
+
+	cli, err := newClient()
+	if err != nil {
+		return fmt.Errorf("failed to connect to Docker: %w", err)
+	}
+	defer func() { _ = cli.Close() }()
+
+	if err := cli.NetworkDisconnect(ctx, networkName, containerName, false); err != nil {
+		if cerrdefs.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to disconnect container from network: %w", err)
+	}
+	return nil
+}
+
+// DisconnectStoppedContainer force-detaches a stopped container from a
+// network and reports whether it did. A container that is gone, running again
+// (a restart policy brought it back) or no longer attached is left alone.
+func DisconnectStoppedContainer(containerName, networkName string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), StatusTimeout)
+	defer cancel()
+
+	cli, err := newClient()
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to Docker: %w", err)
+	}
+	defer func() { _ = cli.Close() }()
+
+	info, err := cli.ContainerInspect(ctx, containerName)
+	if err != nil {
+		if cerrdefs.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to inspect container: %w", err)
+	}
+	if info.State != nil && info.State.Running {
+		return false, nil
+	}
+	if info.NetworkSettings == nil || info.NetworkSettings.Networks[networkName] == nil {
+		return false, nil
+	}
+	if err := cli.NetworkDisconnect(ctx, networkName, containerName, true); err != nil {
+		if cerrdefs.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to disconnect container from network: %w", err)
+	}
+	return true, nil
+}
+
+// RemoveStaleEndpoints force-disconnects the endpoints on a network whose
+// container no longer exists, and returns their names. Such leftovers make
+// the next connect of a container with the same name fail with "endpoint
+// with name ... already exists".
+func RemoveStaleEndpoints(networkName string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), StatusTimeout)
+	defer cancel()
+
+	out, err := networkInspectOutput(ctx, networkName)
+	if err != nil {
+		return nil, fmt.Errorf("docker network inspect %s: %w", networkName, err)
+	}
+	detail, err := parseNetworkInspect(out)
+	if err != nil {
+		return nil, err
+	}
+
+	cli, err := newClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Docker: %w", err)
+	}
+	defer func() { _ = cli.Close() }()
+
+	var removed []string
+	var errs []error
+	for _, ep := range detail.Containers {
+		if _, err := cli.ContainerInspect(ctx, ep.Name); !cerrdefs.IsNotFound(err) {
+			continue
+		}
+		if err := cli.NetworkDisconnect(ctx, networkName, ep.Name, true); err != nil && !cerrdefs.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("%s: %w", ep.Name, err))
+			continue
+		}
+		removed = append(removed, ep.Name)
+	}
+	return removed, errors.Join(errs...)
+}
+
+// NetworkContainer is a container attached to a Docker network.
+type NetworkContainer struct {
+	Name   string
+	Labels map[string]string
+}
+
+// NetworkContainers lists the containers, running or stopped, attached to
+// networkName, sorted by name.
+func NetworkContainers(networkName string) ([]NetworkContainer, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), StatusTimeout)
+	defer cancel()
+
+	cli, err := newClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Docker: %w", err)
+	}
+	defer func() { _ = cli.Close() }()
+
+	f := filters.NewArgs(filters.Arg("network", networkName))
+	containers, err := cli.ContainerList(ctx, container.ListOptions{All: true, Filters: f})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers on %s: %w", networkName, err)
+	}
+	out := make([]NetworkContainer, 0, len(containers))
+	for _, c := range containers {
+		if len(c.Names) == 0 {
+			continue
+		}
+		out = append(out, NetworkContainer{Name: strings.TrimPrefix(c.Names[0], "/"), Labels: c.Labels})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// NetworkInfo is one Docker network as `srv network list` shows it.
+type NetworkInfo struct {
+	Name   string `json:"name"`
+	ID     string `json:"id"`
+	Driver string `json:"driver"`
+	Scope  string `json:"scope"`
+}
+
+// ListNetworks returns every Docker network, sorted by name.
+func ListNetworks() ([]NetworkInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), StatusTimeout)
+	defer cancel()
+
+	cli, err := newClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Docker: %w", err)
+	}
+	defer func() { _ = cli.Close() }()
+
+	networks, err := cli.NetworkList(ctx, network.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list networks: %w", err)
+	}
+	out := make([]NetworkInfo, 0, len(networks))
+	for _, n := range networks {
+		out = append(out, NetworkInfo{Name: n.Name, ID: n.ID, Driver: n.Driver, Scope: n.Scope})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// NetworkEndpoint is a container attached to a network, as reported by
+// `docker network inspect`.
+type NetworkEndpoint struct {
+	Name    string            `json:"name"`
+	IPv4    string            `json:"ipv4,omitempty"`
+	IPv6    string            `json:"ipv6,omitempty"`
+	Aliases []string          `json:"aliases,omitempty"`
+	Labels  map[string]string `json:"-"`
+}
+
+// NetworkDetail is the parsed `docker network inspect` output for one network.
+type NetworkDetail struct {
+	Name       string            `json:"name"`
+	Driver     string            `json:"driver"`
+	Containers []NetworkEndpoint `json:"containers"`
+}
+
+// networkInspectOutput is the seam behind InspectNetwork. Tests override it
+// to skip the docker subprocess.
+var networkInspectOutput = defaultNetworkInspectOutput
+
+func defaultNetworkInspectOutput(ctx context.Context, name string) ([]byte, error) {
+	cmd := Command(ctx, "network", "inspect", name, "--format", "json")
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, errors.New(msg)
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+// SwapNetworkInspectOutput replaces the `docker network inspect` invoker.
+// Returns a restore func suitable for t.Cleanup.
+func SwapNetworkInspectOutput(fn func(ctx context.Context, name string) ([]byte, error)) func() {
+	prev := networkInspectOutput
+	networkInspectOutput = fn
+	return func() { networkInspectOutput = prev }
+}
+
+// InspectNetwork returns a network's attached containers (running ones only;
+// Docker doesn't list stopped containers as endpoints) with their addresses,
+// sorted by name. Network inspect doesn't carry aliases or labels, so each
+// container is inspected for those too; that part is best-effort.
+func InspectNetwork(name string) (*NetworkDetail, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), StatusTimeout)
+	defer cancel()
+
+	out, err := networkInspectOutput(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("docker network inspect %s: %w", name, err)
+	}
+	detail, err := parseNetworkInspect(out)
+	if err != nil {
+		return nil, err
+	}
+
+	cli, err := newClient()
+	if err != nil {
+		return detail, nil
+	}
+	defer func() { _ = cli.Close() }()
+	for i := range detail.Containers {
+		ep := &detail.Containers[i]
+		info, err := cli.ContainerInspect(ctx, ep.Name)
+		if err != nil {
+			continue
+		}
+		if info.Config != nil {
+			ep.Labels = info.Config.Labels
+		}
+		if info.NetworkSettings != nil {
+			if settings := info.NetworkSettings.Networks[detail.Name]; settings != nil {
+				ep.Aliases = networkAliases(settings, ep.Name)
+			}
+		}
+	}
+	return detail, nil
+}
+
+// networkAliases merges an endpoint's aliases and DNS names, dropping the
+// container's own name and ID-prefix entries Docker adds to every endpoint.
+func networkAliases(settings *network.EndpointSettings, containerName string) []string {
+	seen := map[string]bool{containerName: true}
+	if len(settings.EndpointID) >= 12 {
+		seen[settings.EndpointID[:12]] = true
+	}
+	var aliases []string
+	for _, a := range slices.Concat(settings.Aliases, settings.DNSNames) {
+		if seen[a] || isShortContainerID(a) {
+			continue
+		}
+		seen[a] = true
+		aliases = append(aliases, a)
+	}
+	return aliases
+}
+
+// isShortContainerID reports whether s looks like the 12-hex-char container ID
+// Docker adds as an alias.
+func isShortContainerID(s string) bool {
+	if len(s) != 12 {
+		return false
+	}
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseNetworkInspect decodes `docker network inspect --format json` output:
+// a single object on current Docker releases, a one-element array on older
+// ones. Addresses lose their prefix length ("172.18.0.2/16" -> "172.18.0.2").
+func parseNetworkInspect(out []byte) (*NetworkDetail, error) {
+	type rawNetwork struct {
+		Name       string
+		Driver     string
+		Containers map[string]struct {
+			Name        string
+			IPv4Address string
+			IPv6Address string
+		}
+	}
+	out = bytes.TrimSpace(out)
+	var raw rawNetwork
+	if bytes.HasPrefix(out, []byte("[")) {
+		var list []rawNetwork
+		if err := json.Unmarshal(out, &list); err != nil {
+			return nil, fmt.Errorf("parse docker network inspect: %w", err)
+		}
+		if len(list) == 0 {
+			return nil, errors.New("docker network inspect returned no data")
+		}
+		raw = list[0]
+	} else if err := json.Unmarshal(out, &raw); err != nil {
+		return nil, fmt.Errorf("parse docker network inspect: %w", err)
+	}
+
+	detail := &NetworkDetail{Name: raw.Name, Driver: raw.Driver, Containers: []NetworkEndpoint{}}
+	for _, c := range raw.Containers {
+		ipv4, _, _ := strings.Cut(c.IPv4Address, "/")
+		ipv6, _, _ := strings.Cut(c.IPv6Address, "/")
+		detail.Containers = append(detail.Containers, NetworkEndpoint{Name: c.Name, IPv4: ipv4, IPv6: ipv6})
+	}
+	sort.Slice(detail.Containers, func(i, j int) bool { return detail.Containers[i].Name < detail.Containers[j].Name })
+	return detail, nil
+}
+
+// Prune runs `docker <kind> prune -f` (kind is "image" or "volume"),
+// streaming its report to stdout.
+func Prune(kind string) error {
+	return dockerExec(false, kind, "prune", "-f")
+}
+
 // connectContainerByID is the shared implementation for network connect calls.
 func connectContainerByID(ctx context.Context, containerID, networkName, alias string) error {
 	cli, err := newClient()
@@ -775,6 +1736,9 @@ func (noopSDK) NetworkRemove(context.Context, string) error { return nil }
 func (noopSDK) NetworkConnect(context.Context, string, string, *network.EndpointSettings) error {
 	return nil
 }
+func (noopSDK) NetworkDisconnect(context.Context, string, string, bool) error {
+	return nil
+}
 func (noopSDK) ContainerInspect(context.Context, string) (container.InspectResponse, error) {
 	return container.InspectResponse{}, errors.New("noopSDK: not found")
 }
@@ -784,4 +1748,9 @@ func (noopSDK) ContainerList(context.Context, container.ListOptions) ([]containe
 func (noopSDK) ImagePull(context.Context, string, image.PullOptions) (io.ReadCloser, error) {
 	return io.NopCloser(strings.NewReader("")), nil
 }
+func (noopSDK) Events(context.Context, events.ListOptions) (<-chan events.Message, <-chan error) {
+	errCh := make(chan error, 1)
+	errCh <- errors.New("noopSDK: events not supported")
+	return nil, errCh
+}
 func (noopSDK) Close() error { return nil }
@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"io"
 	"strings"
 	"testing"
+	"time"
 
 	cerrdefs "github.com/containerd/errdefs"
 	"github.com/docker/docker/api/types/container"
@@ -123,6 +125,122 @@ func TestParseComposeStatusOutput(t *testing.T) {
 	}
 }
 
+func TestParseComposePSJSON(t *testing.T) {
+	ndjson := `{"Name":"blog-worker-1","Service":"worker","State":"exited","Status":"Exited (1) 2 minutes ago","Ports":""}
+{"Name":"blog-web-1","Service":"web","State":"running","Status":"Up 5 minutes","Ports":"0.0.0.0:8080->80/tcp"}
+`
+	array := `[{"Name":"blog-web-1","Service":"web","State":"running","Status":"Up 5 minutes","Publishers":[{"URL":"0.0.0.0","TargetPort":80,"PublishedPort":8080,"Protocol":"tcp"},{"URL":"","TargetPort":443,"PublishedPort":0,"Protocol":"tcp"}]}]`
+
+	got, err := parseComposePSJSON([]byte(ndjson))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Service != "web" || got[0].Ports != "0.0.0.0:8080->80/tcp" || got[1].State != "exited" {
+		t.Errorf("ndjson = %+v", got)
+	}
+
+	got, err = parseComposePSJSON([]byte(array))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Ports != "0.0.0.0:8080->80/tcp, 443/tcp" {
+		t.Errorf("array = %+v", got)
+	}
+
+	if got, err := parseComposePSJSON([]byte("\n")); err != nil || len(got) != 0 {
+		t.Errorf("empty = %v, %v", got, err)
+	}
+	if _, err := parseComposePSJSON([]byte("not json")); err == nil {
+		t.Error("expected err for malformed output")
+	}
+}
+
+func TestComposePSArgs(t *testing.T) {
+	var got string
+	t.Cleanup(SwapComposePSJSON(func(_ string, args ...string) ([]byte, error) {
+		got = strings.Join(args, " ")
+		return nil, nil
+	}))
+	if _, err := ComposePS("/src/blog", "dev", true); err != nil {
+		t.Fatal(err)
+	}
+	if got != "--profile dev ps --format json --all" {
+		t.Errorf("args = %q", got)
+	}
+}
+
+func TestParseContainerStats(t *testing.T) {
+	out := `{"BlockIO":"0B / 0B","CPUPerc":"1.25%","MemPerc":"0.16%","MemUsage":"12.5MiB / 7.6GiB","Name":"srv-x-app","NetIO":"1.2kB / 648B","PIDs":"3"}` + "\n"
+	got, err := parseContainerStats([]byte(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ContainerStats{CPUPercent: "1.25%", MemUsage: "12.5MiB", MemLimit: "7.6GiB", NetIO: "1.2kB / 648B"}
+	if *got != want {
+		t.Errorf("got %+v, want %+v", *got, want)
+	}
+
+	for _, bad := range []string{"", "  \n", "not json"} {
+		if _, err := parseContainerStats([]byte(bad)); err == nil {
+			t.Errorf("parseContainerStats(%q): expected err", bad)
+		}
+	}
+}
+
+func TestGetContainerStats(t *testing.T) {
+	t.Cleanup(SwapContainerStatsOutput(func(_ context.Context, name string) ([]byte, error) {
+		if name != "srv-x-app" {
+			t.Errorf("name = %q", name)
+		}
+		return []byte(`{"CPUPerc":"0.00%","MemUsage":"1MiB / 2GiB","NetIO":"0B / 0B"}`), nil
+	}))
+	got, err := GetContainerStats("srv-x-app")
+	if err != nil || got.MemLimit != "2GiB" {
+		t.Errorf("GetContainerStats = %+v, %v", got, err)
+	}
+
+	t.Cleanup(SwapContainerStatsOutput(func(context.Context, string) ([]byte, error) {
+		return nil, errors.New("no such container")
+	}))
+	if _, err := GetContainerStats("srv-x-app"); err == nil {
+		t.Error("expected err")
+	}
+}
+
+func TestContainerStartedAt(t *testing.T) {
+	swap(t, &fakeSDK{inspect: map[string]container.InspectResponse{
+		"x":     {ContainerJSONBase: &container.ContainerJSONBase{State: &container.State{StartedAt: "2026-03-01T10:00:00.5Z"}}},
+		"fresh": {ContainerJSONBase: &container.ContainerJSONBase{State: &container.State{StartedAt: "0001-01-01T00:00:00Z"}}},
+		"bare":  {},
+	}})
+	got, err := ContainerStartedAt("x")
+	if err != nil || !got.Equal(time.Date(2026, 3, 1, 10, 0, 0, 5e8, time.UTC)) {
+		t.Errorf("ContainerStartedAt = %v, %v", got, err)
+	}
+	for _, name := range []string{"fresh", "bare", "missing"} {
+		if _, err := ContainerStartedAt(name); err == nil {
+			t.Errorf("ContainerStartedAt(%q): expected err", name)
+		}
+	}
+}
+
+func TestContainerStarts(t *testing.T) {
+	t.Cleanup(SwapContainerStartEventsOutput(func(_ context.Context, name string, since, until time.Time) ([]byte, error) {
+		if name != "srv-x-app" || until.Sub(since) != time.Hour {
+			t.Errorf("name = %q, window = %v", name, until.Sub(since))
+		}
+		return []byte("1700000000000000000\n1700000600000000000\n\n"), nil
+	}))
+	got, err := ContainerStarts("srv-x-app", time.Hour)
+	if err != nil || len(got) != 2 || got[0].Unix() != 1700000600 || got[1].Unix() != 1700000000 {
+		t.Errorf("ContainerStarts = %v, %v; want most recent first", got, err)
+	}
+
+	if _, err := parseStartEvents([]byte("yesterday\n")); err == nil {
+		t.Error("parseStartEvents: expected err for a non-numeric line")
+	}
+}
+
 func TestAggregateStatus(t *testing.T) {
 	cases := []struct {
 		running, total int
@@ -317,6 +435,102 @@ func TestContainerExistsMissing(t *testing.T) {
 	}
 }
 
+func TestIsContainerOnNetwork(t *testing.T) {
+	swap(t, &fakeSDK{inspect: map[string]container.InspectResponse{
+		"app": {NetworkSettings: &container.NetworkSettings{Networks: map[string]*network.EndpointSettings{"traefik": {}}}},
+		"db":  {NetworkSettings: &container.NetworkSettings{Networks: map[string]*network.EndpointSettings{"bridge": {}}}},
+	}})
+	if on, err := IsContainerOnNetwork("app", "traefik"); err != nil || !on {
+		t.Errorf("app: got %v, %v; want true", on, err)
+	}
+	if on, err := IsContainerOnNetwork("db", "traefik"); err != nil || on {
+		t.Errorf("db: got %v, %v; want false", on, err)
+	}
+	if _, err := IsContainerOnNetwork("missing", "traefik"); err == nil {
+		t.Error("expected error for missing container")
+	}
+}
+
+func TestNetworkContainers(t *testing.T) {
+	swap(t, &fakeSDK{listContainers: []container.Summary{
+		{Names: []string{"/srv_static_blog"}, Labels: map[string]string{"dev.srv.site": "blog"}},
+		{Names: []string{"/app"}},
+		{},
+	}})
+	got, err := NetworkContainers("traefik")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Name != "app" || got[1].Name != "srv_static_blog" {
+		t.Fatalf("got %+v", got)
+	}
+	if got[1].Labels["dev.srv.site"] != "blog" {
+		t.Errorf("labels = %v", got[1].Labels)
+	}
+
+	swap(t, &fakeSDK{listContainersErr: errors.New("boom")})
+	if _, err := NetworkContainers("traefik"); err == nil {
+		t.Error("expected list error")
+	}
+}
+
+func TestInspectNetwork(t *testing.T) {
+	out := `{"Name":"traefik","Driver":"bridge","Containers":{
+"abc":{"Name":"srv-traefik","IPv4Address":"172.18.0.2/16","IPv6Address":""},
+"def":{"Name":"blog-web-1","IPv4Address":"172.18.0.3/16","IPv6Address":""}}}`
+	t.Cleanup(SwapNetworkInspectOutput(func(_ context.Context, name string) ([]byte, error) {
+		if name != "traefik" {
+			t.Errorf("inspect %q", name)
+		}
+		return []byte(out), nil
+	}))
+	swap(t, &fakeSDK{inspect: map[string]container.InspectResponse{
+		"blog-web-1": {
+			Config: &container.Config{Labels: map[string]string{"dev.srv.site": "blog"}},
+			NetworkSettings: &container.NetworkSettings{Networks: map[string]*network.EndpointSettings{
+				"traefik": {EndpointID: "0123456789abcdef", Aliases: []string{"blog-web-1", "web"}, DNSNames: []string{"web", "3f2a1b4c5d6e"}},
+			}},
+		},
+	}})
+
+	got, err := InspectNetwork("traefik")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Containers) != 2 || got.Containers[0].Name != "blog-web-1" || got.Containers[1].IPv4 != "172.18.0.2" {
+		t.Fatalf("containers = %+v", got.Containers)
+	}
+	if web := got.Containers[0]; strings.Join(web.Aliases, ",") != "web" || web.Labels["dev.srv.site"] != "blog" {
+		t.Errorf("blog-web-1 = %+v", web)
+	}
+}
+
+func TestParseNetworkInspectArray(t *testing.T) {
+	got, err := parseNetworkInspect([]byte(`[{"Name":"n","Driver":"bridge","Containers":{}}]`))
+	if err != nil || got.Name != "n" || len(got.Containers) != 0 {
+		t.Errorf("got %+v, %v", got, err)
+	}
+	for _, bad := range []string{"[]", "nope"} {
+		if _, err := parseNetworkInspect([]byte(bad)); err == nil {
+			t.Errorf("parseNetworkInspect(%q): expected err", bad)
+		}
+	}
+}
+
+func TestPrune(t *testing.T) {
+	var got []string
+	t.Cleanup(SwapDockerExec(func(_ bool, args ...string) error {
+		got = args
+		return nil
+	}))
+	if err := Prune("volume"); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(got, " ") != "volume prune -f" {
+		t.Errorf("args = %v", got)
+	}
+}
+
 func TestGetContainerImageVersion(t *testing.T) {
 	swap(t, &fakeSDK{inspect: map[string]container.InspectResponse{
 		"x": {Config: &container.Config{Image: "nginx:1.25"}},
@@ -402,6 +616,25 @@ func TestConnectContainerToNetworkClientErr(t *testing.T) {
 	}
 }
 
+func TestDisconnectContainerFromNetwork(t *testing.T) {
+	f := &fakeSDK{}
+	swap(t, f)
+	if err := DisconnectContainerFromNetwork("c", "n"); err != nil {
+		t.Errorf("err: %v", err)
+	}
+	if f.disconnectCount != 1 {
+		t.Errorf("disconnectCount = %d", f.disconnectCount)
+	}
+	swap(t, &fakeSDK{disconnectErr: cerrdefs.ErrNotFound})
+	if err := DisconnectContainerFromNetwork("c", "n"); err != nil {
+		t.Errorf("not-found should be no-op, got %v", err)
+	}
+	swap(t, &fakeSDK{disconnectErr: errors.New("boom")})
+	if err := DisconnectContainerFromNetwork("c", "n"); err == nil {
+		t.Error("expected propagated err")
+	}
+}
+
 func TestContainerStatusByNameRunning(t *testing.T) {
 	swap(t, &fakeSDK{inspect: map[string]container.InspectResponse{
 		"x": {ContainerJSONBase: &container.ContainerJSONBase{State: &container.State{Running: true}}},
@@ -792,6 +1025,9 @@ func TestSwapNewClientOK(t *testing.T) {
 	if err := cli.NetworkConnect(ctx, "n", "c", nil); err != nil {
 		t.Errorf("NetworkConnect err: %v", err)
 	}
+	if err := cli.NetworkDisconnect(ctx, "n", "c", false); err != nil {
+		t.Errorf("NetworkDisconnect err: %v", err)
+	}
 	if _, err := cli.ContainerInspect(ctx, "c"); err == nil {
 		t.Error("expected ContainerInspect err")
 	}
@@ -866,3 +1102,79 @@ func TestComposeUpDownNoRemoveOrphans(t *testing.T) {
 		}
 	}
 }
+
+func TestDisconnectStoppedContainer(t *testing.T) {
+	attached := &container.NetworkSettings{Networks: map[string]*network.EndpointSettings{"traefik": {}}}
+	f := &fakeSDK{
+		inspect: map[string]container.InspectResponse{
+			"stopped": {ContainerJSONBase: &container.ContainerJSONBase{State: &container.State{Status: "exited"}}, NetworkSettings: attached},
+			"running": {ContainerJSONBase: &container.ContainerJSONBase{State: &container.State{Running: true}}, NetworkSettings: attached},
+			"detached": {ContainerJSONBase: &container.ContainerJSONBase{State: &container.State{Status: "exited"}},
+				NetworkSettings: &container.NetworkSettings{}},
+		},
+		inspectErr: map[string]error{"gone": cerrdefs.ErrNotFound},
+	}
+	swap(t, f)
+
+	for name, want := range map[string]bool{"stopped": true, "running": false, "detached": false, "gone": false} {
+		f.disconnectCount = 0
+		got, err := DisconnectStoppedContainer(name, "traefik")
+		if err != nil || got != want {
+			t.Errorf("DisconnectStoppedContainer(%s) = %v, %v, want %v", name, got, err, want)
+		}
+		if want != (f.disconnectCount == 1) {
+			t.Errorf("%s: %d disconnect calls", name, f.disconnectCount)
+		}
+	}
+}
+
+func TestRemoveStaleEndpoints(t *testing.T) {
+	out := `{"Name":"traefik","Driver":"bridge","Containers":{
+"abc":{"Name":"blog-web-1","IPv4Address":"172.18.0.3/16"},
+"ep-def":{"Name":"old-web-1","IPv4Address":"172.18.0.4/16"}}}`
+	t.Cleanup(SwapNetworkInspectOutput(func(context.Context, string) ([]byte, error) { return []byte(out), nil }))
+	f := &fakeSDK{
+		inspect:    map[string]container.InspectResponse{"blog-web-1": {}},
+		inspectErr: map[string]error{"old-web-1": cerrdefs.ErrNotFound},
+	}
+	swap(t, f)
+
+	removed, err := RemoveStaleEndpoints("traefik")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(removed, ",") != "old-web-1" || f.disconnectCount != 1 {
+		t.Errorf("removed = %v (%d disconnects), want [old-web-1]", removed, f.disconnectCount)
+	}
+}
+
+func TestFilterLines(t *testing.T) {
+	var out bytes.Buffer
+	in := strings.NewReader("web-1  | GET /\nweb-1  | ERROR boom\nweb-1  | GET /health\nworker-1  | ERROR again")
+	if err := filterLines(in, &out, func(l string) bool { return strings.Contains(l, "ERROR") }); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "web-1  | ERROR boom\nworker-1  | ERROR again\n" {
+		t.Errorf("filtered = %q", out.String())
+	}
+}
+
+func TestComposeFilteredStreams(t *testing.T) {
+	var gotArgs string
+	t.Cleanup(SwapComposeStreamExec(func(dir string, w io.Writer, args ...string) error {
+		gotArgs = dir + ": " + strings.Join(args, " ")
+		_, err := io.WriteString(w, strings.Repeat("line\n", 10000)) // more than the pipe buffers
+		return err
+	}))
+	if err := ComposeFiltered("/p", "", func(string) bool { return false }, "logs", "-f"); err != nil {
+		t.Fatal(err)
+	}
+	if gotArgs != "/p: logs -f" {
+		t.Errorf("compose = %q", gotArgs)
+	}
+
+	t.Cleanup(SwapComposeStreamExec(func(string, io.Writer, ...string) error { return errors.New("exit status 1") }))
+	if err := ComposeFiltered("/p", "blog", func(string) bool { return true }, "logs"); err == nil {
+		t.Error("expected the compose error")
+	}
+}
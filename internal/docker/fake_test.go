@@ -8,6 +8,7 @@ import (
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/network"
 )
@@ -28,6 +29,9 @@ type fakeSDK struct {
 	connectErr   error
 	connectCount int
 
+	disconnectErr   error
+	disconnectCount int
+
 	inspect    map[string]container.InspectResponse
 	inspectErr map[string]error
 
@@ -64,6 +68,11 @@ func (f *fakeSDK) NetworkConnect(ctx context.Context, networkID, containerID str
 	return f.connectErr
 }
 
+func (f *fakeSDK) NetworkDisconnect(ctx context.Context, networkID, containerID string, force bool) error {
+	f.disconnectCount++
+	return f.disconnectErr
+}
+
 func (f *fakeSDK) ContainerInspect(ctx context.Context, name string) (container.InspectResponse, error) {
 	if err, ok := f.inspectErr[name]; ok {
 		return container.InspectResponse{}, err
@@ -89,6 +98,12 @@ func (f *fakeSDK) ImagePull(ctx context.Context, ref string, opts image.PullOpti
 	return io.NopCloser(strings.NewReader("pull progress\n")), nil
 }
 
+func (f *fakeSDK) Events(ctx context.Context, opts events.ListOptions) (<-chan events.Message, <-chan error) {
+	errCh := make(chan error, 1)
+	errCh <- errors.New("not supported")
+	return nil, errCh
+}
+
 func (f *fakeSDK) Close() error {
 	f.closed = true
 	return nil
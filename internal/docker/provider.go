@@ -0,0 +1,134 @@
+// Package docker — provider.go works out which application provides the
+// Docker daemon (Docker Desktop, OrbStack or Colima), so a daemon that
+// doesn't answer gets a "start OrbStack" hint rather than a generic one.
+package docker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/stubbedev/srv/internal/platform"
+)
+
+// Provider names the application running the Docker daemon.
+type Provider int
+
+const (
+	ProviderUnknown Provider = iota
+	ProviderDockerDesktop
+	ProviderOrbStack
+	ProviderColima
+)
+
+// String returns the provider's product name.
+func (p Provider) String() string {
+	switch p {
+	case ProviderDockerDesktop:
+		return "Docker Desktop"
+	case ProviderOrbStack:
+		return "OrbStack"
+	case ProviderColima:
+		return "Colima"
+	default:
+		return "unknown"
+	}
+}
+
+// providerSocket is the socket whose symlink target names the provider.
+// Tests point it elsewhere.
+var providerSocket = defaultDockerSocket
+
+// providerStatusTimeout bounds `orbctl status` and `colima status`.
+const providerStatusTimeout = 5 * time.Second
+
+// DetectDockerProvider works out the Docker provider from, in order:
+// DOCKER_HOST, the selected context's endpoint, where /var/run/docker.sock
+// links to, and which provider CLIs are installed.
+func DetectDockerProvider() Provider {
+	if p := providerFromPath(os.Getenv("DOCKER_HOST")); p != ProviderUnknown {
+		return p
+	}
+	if host, err := ContextHost(); err == nil {
+		if p := providerFromPath(host); p != ProviderUnknown {
+			return p
+		}
+	}
+	if target, err := filepath.EvalSymlinks(providerSocket); err == nil {
+		if p := providerFromPath(target); p != ProviderUnknown {
+			return p
+		}
+	}
+	if _, err := exec.LookPath("orbctl"); err == nil {
+		return ProviderOrbStack
+	}
+	if _, err := exec.LookPath("colima"); err == nil {
+		return ProviderColima
+	}
+	if platform.IsDarwin() {
+		if _, err := os.Stat("/Applications/Docker.app"); err == nil {
+			return ProviderDockerDesktop
+		}
+	}
+	return ProviderUnknown
+}
+
+// providerFromPath recognises a provider by the socket path or endpoint each
+// one uses: ~/.orbstack/run/docker.sock, ~/.colima/<profile>/docker.sock and
+// Docker Desktop's ~/.docker/run/docker.sock (docker.raw.sock on macOS).
+func providerFromPath(path string) Provider {
+	switch {
+	case path == "":
+		return ProviderUnknown
+	case strings.Contains(path, "/.orbstack/"), strings.Contains(path, "/OrbStack/"):
+		return ProviderOrbStack
+	case strings.Contains(path, "/.colima/"):
+		return ProviderColima
+	case strings.Contains(path, "/.docker/run/"), strings.Contains(path, "docker.raw.sock"),
+		strings.Contains(path, "/.docker/desktop/"):
+		return ProviderDockerDesktop
+	}
+	return ProviderUnknown
+}
+
+// providerRunning asks the provider's CLI whether its VM is up. It reports
+// false when the CLI says stopped, fails, or isn't installed.
+func providerRunning(p Provider) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), providerStatusTimeout)
+	defer cancel()
+	switch p {
+	case ProviderOrbStack:
+		out, err := exec.CommandContext(ctx, "orbctl", "status").Output()
+		return err == nil && strings.EqualFold(strings.TrimSpace(string(out)), "running")
+	case ProviderColima:
+		// colima status exits non-zero when the VM is stopped.
+		return exec.CommandContext(ctx, "colima", "status").Run() == nil
+	}
+	return false
+}
+
+// providerNotRunningErr renders the "daemon didn't answer" message for a
+// known provider, or nil when the provider isn't known.
+func providerNotRunningErr(p Provider) error {
+	if p == ProviderOrbStack || p == ProviderColima {
+		if providerRunning(p) {
+			return fmt.Errorf("%s is running, but its Docker engine didn't answer.\n  Check that DOCKER_HOST or the docker context points at it (docker context ls)", p)
+		}
+	}
+	var hint string
+	switch p {
+	case ProviderOrbStack:
+		hint = "Start it from the menu bar or run: orbctl start"
+	case ProviderColima:
+		hint = "Start it with: colima start"
+	case ProviderDockerDesktop:
+		hint = "Start it from the Applications folder or the menu bar"
+	default:
+		return nil
+	}
+	return fmt.Errorf("%s is not running.\n  %s", p, hint)
+}
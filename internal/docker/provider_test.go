@@ -0,0 +1,98 @@
+package docker
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// noProviderSocket points providerSocket at a path that doesn't exist.
+func noProviderSocket(t *testing.T) {
+	t.Helper()
+	prev := providerSocket
+	providerSocket = filepath.Join(t.TempDir(), "docker.sock")
+	t.Cleanup(func() { providerSocket = prev })
+}
+
+// fakeCLI writes an executable script called name into PATH's first
+// directory that prints out and exits with code.
+func fakeCLI(t *testing.T, name, out string, code int) {
+	t.Helper()
+	dir := strings.Split(os.Getenv("PATH"), string(os.PathListSeparator))[0]
+	script := "#!/bin/sh\necho '" + out + "'\nexit " + strconv.Itoa(code) + "\n"
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProviderFromPath(t *testing.T) {
+	for path, want := range map[string]Provider{
+		"unix:///Users/dev/.orbstack/run/docker.sock":                          ProviderOrbStack,
+		"unix:///Users/dev/.colima/default/docker.sock":                        ProviderColima,
+		"unix:///Users/dev/.docker/run/docker.sock":                            ProviderDockerDesktop,
+		"/Users/dev/Library/Containers/com.docker.docker/Data/docker.raw.sock": ProviderDockerDesktop,
+		"unix:///var/run/docker.sock":                                          ProviderUnknown,
+		"":                                                                     ProviderUnknown,
+	} {
+		if got := providerFromPath(path); got != want {
+			t.Errorf("providerFromPath(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestDetectDockerProvider(t *testing.T) {
+	useContext(t, "", "", nil)
+	noProviderSocket(t)
+
+	fakePATH(t)
+	t.Setenv("DOCKER_HOST", "unix:///home/dev/.colima/default/docker.sock")
+	if got := DetectDockerProvider(); got != ProviderColima {
+		t.Errorf("DOCKER_HOST colima socket -> %v", got)
+	}
+
+	t.Setenv("DOCKER_HOST", "")
+	target := filepath.Join(t.TempDir(), ".orbstack", "run", "docker.sock")
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(target, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, providerSocket); err != nil {
+		t.Fatal(err)
+	}
+	if got := DetectDockerProvider(); got != ProviderOrbStack {
+		t.Errorf("socket linked into ~/.orbstack -> %v", got)
+	}
+
+	noProviderSocket(t)
+	fakePATH(t, "colima")
+	if got := DetectDockerProvider(); got != ProviderColima {
+		t.Errorf("colima on PATH -> %v", got)
+	}
+}
+
+func TestProviderNotRunningErr(t *testing.T) {
+	fakePATH(t)
+	fakeCLI(t, "orbctl", "Stopped", 0)
+	err := providerNotRunningErr(ProviderOrbStack)
+	if err == nil || !strings.Contains(err.Error(), "OrbStack is not running") || !strings.Contains(err.Error(), "orbctl start") {
+		t.Errorf("stopped OrbStack: %v", err)
+	}
+
+	fakeCLI(t, "orbctl", "Running", 0)
+	if err := providerNotRunningErr(ProviderOrbStack); err == nil || !strings.Contains(err.Error(), "didn't answer") {
+		t.Errorf("running OrbStack: %v", err)
+	}
+
+	fakeCLI(t, "colima", "colima is not running", 1)
+	if err := providerNotRunningErr(ProviderColima); err == nil || !strings.Contains(err.Error(), "colima start") {
+		t.Errorf("stopped Colima: %v", err)
+	}
+
+	if err := providerNotRunningErr(ProviderUnknown); err != nil {
+		t.Errorf("unknown provider: %v", err)
+	}
+}
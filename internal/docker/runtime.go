@@ -0,0 +1,125 @@
+// Package docker — runtime.go detects the container runtime srv drives.
+// Docker is the default; Podman (with podman-compose) is used when docker
+// isn't installed, or when CONTAINER_HOST points srv at a Podman service.
+// Podman serves the Docker API, so the SDK client works against either; only
+// the CLI subprocesses differ.
+package docker
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/stubbedev/srv/internal/platform"
+)
+
+// Runtime names a container engine CLI.
+type Runtime string
+
+const (
+	// RuntimeDocker is Docker Engine with the compose plugin.
+	RuntimeDocker Runtime = "docker"
+	// RuntimePodman is Podman, with podman-compose (or `podman compose`).
+	RuntimePodman Runtime = "podman"
+)
+
+var (
+	runtimeOnce   sync.Once
+	activeRuntime Runtime
+)
+
+// DetectRuntime picks the container runtime: Podman when CONTAINER_HOST is
+// set and podman is installed, Docker when docker is installed, Podman when
+// only podman is, and Docker otherwise (so errors name the usual tool).
+func DetectRuntime() Runtime {
+	_, podmanErr := exec.LookPath("podman")
+	hasPodman := podmanErr == nil
+	if os.Getenv("CONTAINER_HOST") != "" && hasPodman {
+		return RuntimePodman
+	}
+	if _, err := exec.LookPath("docker"); err == nil {
+		return RuntimeDocker
+	}
+	if hasPodman {
+		return RuntimePodman
+	}
+	return RuntimeDocker
+}
+
+// ActiveRuntime returns the runtime srv drives, detecting it on first use.
+func ActiveRuntime() Runtime {
+	runtimeOnce.Do(func() { activeRuntime = DetectRuntime() })
+	return activeRuntime
+}
+
+// SwapRuntime pins the runtime, skipping detection. Returns a restore func
+// for t.Cleanup.
+func SwapRuntime(r Runtime) func() {
+	prev := ActiveRuntime()
+	activeRuntime = r
+	return func() { activeRuntime = prev }
+}
+
+// ComposeCmd returns the command prefix for compose operations: `docker
+// compose` (after any --context flag), `podman-compose`, or `podman compose`
+// when podman-compose isn't installed.
+func ComposeCmd() []string {
+	if ActiveRuntime() == RuntimePodman {
+		if _, err := exec.LookPath("podman-compose"); err == nil {
+			return []string{"podman-compose"}
+		}
+		return []string{"podman", "compose"}
+	}
+	return append([]string{"docker"}, CLIArgs("compose")...)
+}
+
+// Command builds a runtime CLI invocation (`docker ARGS` or `podman ARGS`).
+// Docker contexts only apply to docker; Podman picks its service from
+// CONTAINER_HOST.
+func Command(ctx context.Context, args ...string) *exec.Cmd {
+	if ActiveRuntime() == RuntimePodman {
+		return exec.CommandContext(ctx, string(RuntimePodman), args...)
+	}
+	return exec.CommandContext(ctx, string(RuntimeDocker), CLIArgs(args...)...)
+}
+
+// composeCommand builds a compose invocation with ComposeCmd's prefix.
+func composeCommand(ctx context.Context, args ...string) *exec.Cmd {
+	prefix := ComposeCmd()
+	return exec.CommandContext(ctx, prefix[0], append(prefix[1:], args...)...)
+}
+
+// podmanHost returns the Docker-API endpoint of the Podman service:
+// CONTAINER_HOST when set, else the rootless socket under XDG_RUNTIME_DIR,
+// else the rootful one. On macOS the podman machine forwards the Docker
+// socket, so "" (the SDK default) is returned there.
+func podmanHost() string {
+	if host := os.Getenv("CONTAINER_HOST"); host != "" {
+		return host
+	}
+	if platform.IsDarwin() {
+		return ""
+	}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" && os.Geteuid() != 0 {
+		return "unix://" + filepath.Join(dir, "podman", "podman.sock")
+	}
+	return "unix:///run/podman/podman.sock"
+}
+
+// defaultDockerSocket is the Docker API socket on the host.
+const defaultDockerSocket = "/var/run/docker.sock"
+
+// SocketPath returns the host path of the runtime's API socket, which Traefik
+// mounts to watch container labels. Podman's socket is used when it is a
+// local unix socket; otherwise the Docker default.
+func SocketPath() string {
+	if ActiveRuntime() == RuntimePodman {
+		if path, ok := strings.CutPrefix(podmanHost(), "unix://"); ok {
+			return path
+		}
+	}
+	return defaultDockerSocket
+}
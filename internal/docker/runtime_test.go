@@ -0,0 +1,91 @@
+package docker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakePATH points PATH at a directory holding empty executables with the
+// given names.
+func fakePATH(t *testing.T, bins ...string) {
+	t.Helper()
+	dir := t.TempDir()
+	for _, bin := range bins {
+		if err := os.WriteFile(filepath.Join(dir, bin), []byte("#!/bin/sh\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir)
+}
+
+func TestDetectRuntime(t *testing.T) {
+	cases := []struct {
+		name          string
+		bins          []string
+		containerHost string
+		want          Runtime
+	}{
+		{"docker only", []string{"docker"}, "", RuntimeDocker},
+		{"both prefers docker", []string{"docker", "podman"}, "", RuntimeDocker},
+		{"podman only", []string{"podman"}, "", RuntimePodman},
+		{"container host selects podman", []string{"docker", "podman"}, "unix:///run/user/1000/podman/podman.sock", RuntimePodman},
+		{"container host without podman", []string{"docker"}, "unix:///run/podman/podman.sock", RuntimeDocker},
+		{"neither", nil, "", RuntimeDocker},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fakePATH(t, c.bins...)
+			t.Setenv("CONTAINER_HOST", c.containerHost)
+			if got := DetectRuntime(); got != c.want {
+				t.Errorf("DetectRuntime = %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestComposeCmd(t *testing.T) {
+	useContext(t, "", "", nil)
+	t.Cleanup(SwapRuntime(RuntimeDocker))
+	if got := strings.Join(ComposeCmd(), " "); got != "docker compose" {
+		t.Errorf("docker: %q", got)
+	}
+
+	t.Cleanup(SwapRuntime(RuntimePodman))
+	fakePATH(t, "podman")
+	if got := strings.Join(ComposeCmd(), " "); got != "podman compose" {
+		t.Errorf("podman without podman-compose: %q", got)
+	}
+	fakePATH(t, "podman", "podman-compose")
+	if got := strings.Join(ComposeCmd(), " "); got != "podman-compose" {
+		t.Errorf("podman-compose: %q", got)
+	}
+}
+
+func TestCommandSkipsContextForPodman(t *testing.T) {
+	useContext(t, "prod", "ssh://deploy@prod.example.com", nil)
+	t.Cleanup(SwapRuntime(RuntimePodman))
+	cmd := Command(context.Background(), "ps")
+	if got := strings.Join(cmd.Args, " "); got != "podman ps" {
+		t.Errorf("args = %q", got)
+	}
+	t.Cleanup(SwapRuntime(RuntimeDocker))
+	cmd = Command(context.Background(), "ps")
+	if got := strings.Join(cmd.Args, " "); got != "docker --context prod ps" {
+		t.Errorf("args = %q", got)
+	}
+}
+
+func TestSocketPathPodman(t *testing.T) {
+	t.Cleanup(SwapRuntime(RuntimePodman))
+	t.Setenv("CONTAINER_HOST", "unix:///run/user/1000/podman/podman.sock")
+	if got := SocketPath(); got != "/run/user/1000/podman/podman.sock" {
+		t.Errorf("SocketPath = %q", got)
+	}
+	t.Setenv("CONTAINER_HOST", "ssh://core@host/run/podman/podman.sock")
+	if got := SocketPath(); got != defaultDockerSocket {
+		t.Errorf("SocketPath for a remote host = %q, want the docker default", got)
+	}
+}
@@ -5,11 +5,25 @@
 package fsutil
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"os"
 
 	"github.com/stubbedev/srv/internal/constants"
 )
 
+// rename is os.Rename. Tests swap it to simulate a crash between the temp
+// write and the rename.
+var rename = os.Rename
+
+// SwapRename replaces the rename step of AtomicWriteFile. Returns a restore
+// func suitable for t.Cleanup.
+func SwapRename(fn func(oldpath, newpath string) error) func() {
+	prev := rename
+	rename = fn
+	return func() { rename = prev }
+}
+
 // AtomicWriteFile writes data to path atomically: it writes to a sibling
 // "<path>.tmp" first, then renames it over path. A reader watching path
 // therefore never observes a truncated or half-written file, and a crash
@@ -20,9 +34,25 @@ func AtomicWriteFile(path string, data []byte, perm os.FileMode) error {
 	if err := os.WriteFile(tmp, data, perm); err != nil {
 		return err
 	}
-	if err := os.Rename(tmp, path); err != nil {
+	if err := rename(tmp, path); err != nil {
 		_ = os.Remove(tmp)
 		return err
 	}
 	return nil
 }
+
+// WriteFileIfChanged writes data to path with AtomicWriteFile unless the file
+// already holds exactly data (same sha256), so watchers such as Traefik don't
+// reload for a no-op rewrite. Returns whether the file was written.
+func WriteFileIfChanged(path string, data []byte, perm os.FileMode) (bool, error) {
+	if existing, err := os.ReadFile(path); err == nil {
+		old, cur := sha256.Sum256(existing), sha256.Sum256(data)
+		if bytes.Equal(old[:], cur[:]) {
+			return false, nil
+		}
+	}
+	if err := AtomicWriteFile(path, data, perm); err != nil {
+		return false, err
+	}
+	return true, nil
+}
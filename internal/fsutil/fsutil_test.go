@@ -71,3 +71,46 @@ func TestAtomicWriteFileRenameFails(t *testing.T) {
 		t.Errorf("tmp file remains after failed rename: %v", err)
 	}
 }
+
+// TestAtomicWriteFileCrashKeepsOriginal simulates a crash after the temp file
+// is written but before the rename: the original must be untouched.
+func TestAtomicWriteFileCrashKeepsOriginal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "f")
+	if err := os.WriteFile(path, []byte("original"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(SwapRename(func(string, string) error { panic("crash") }))
+
+	func() {
+		defer func() { _ = recover() }()
+		_ = AtomicWriteFile(path, []byte("half-written"), 0o644)
+		t.Error("expected the simulated crash")
+	}()
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "original" {
+		t.Errorf("contents = %q, want the original", data)
+	}
+}
+
+// TestWriteFileIfChanged: identical content is not rewritten; new content is.
+func TestWriteFileIfChanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "f")
+	if changed, err := WriteFileIfChanged(path, []byte("a"), 0o644); err != nil || !changed {
+		t.Fatalf("create: changed=%v err=%v", changed, err)
+	}
+	rename := SwapRename(func(string, string) error {
+		t.Error("unchanged content was rewritten")
+		return nil
+	})
+	if changed, err := WriteFileIfChanged(path, []byte("a"), 0o644); err != nil || changed {
+		t.Errorf("same content: changed=%v err=%v", changed, err)
+	}
+	rename()
+	if changed, err := WriteFileIfChanged(path, []byte("b"), 0o644); err != nil || !changed {
+		t.Fatalf("new content: changed=%v err=%v", changed, err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "b" {
+		t.Errorf("contents = %q, want b", data)
+	}
+}
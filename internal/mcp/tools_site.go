@@ -126,10 +126,11 @@ type addSiteIn struct {
 	Path         string          `json:"path" jsonschema:"project directory to register"`
 	Domain       string          `json:"domain" jsonschema:"canonical hostname (required)"`
 	Type         string          `json:"type,omitempty" jsonschema:"force site type: compose, dockerfile, or static (default: auto-detect)"`
-	Name         string          `json:"name,omitempty" jsonschema:"site name; derived from domain when omitted"`
+	Name         string          `json:"name,omitempty" jsonschema:"site name; derived from domain when omitted, or 'auto' to append a number when that name is taken"`
 	Aliases      []string        `json:"aliases,omitempty" jsonschema:"extra hostnames mapped to the same site"`
 	Port         int             `json:"port,omitempty" jsonschema:"container port (default 80)"`
 	Local        bool            `json:"local,omitempty" jsonschema:"use local mkcert TLS instead of Let's Encrypt"`
+	Staging      bool            `json:"staging,omitempty" jsonschema:"use the Let's Encrypt staging CA (non-local sites only)"`
 	Wildcard     bool            `json:"wildcard,omitempty" jsonschema:"match one-level subdomains (local only)"`
 	InternalHTTP bool            `json:"internal_http,omitempty" jsonschema:"also expose on the internal plain-HTTP entrypoint"`
 	Service      string          `json:"service,omitempty" jsonschema:"compose service to route to (multi-service projects)"`
@@ -137,7 +138,13 @@ type addSiteIn struct {
 	SPA          bool            `json:"spa,omitempty" jsonschema:"static sites: SPA fallback to index.html"`
 	Cache        bool            `json:"cache,omitempty" jsonschema:"static sites: asset caching headers"`
 	CORS         bool            `json:"cors,omitempty" jsonschema:"static sites: permissive CORS headers"`
+	DirListing   bool            `json:"directory_listing,omitempty" jsonschema:"static sites: list directory contents (excludes spa)"`
+	Brotli       bool            `json:"brotli,omitempty" jsonschema:"static sites: brotli compression (uses an nginx image with the brotli module)"`
 	Volumes      []addSiteVolume `json:"volumes,omitempty" jsonschema:"extra host bind-mounts"`
+	Middlewares  []string        `json:"middlewares,omitempty" jsonschema:"Traefik middlewares (from the dynamic config) to attach to the site's router"`
+	NginxExtra   string          `json:"nginx_extra,omitempty" jsonschema:"static sites: nginx snippet file embedded in the server block"`
+	ErrorPages   string          `json:"error_pages,omitempty" jsonschema:"static sites: directory with custom 404.html / 50x.html"`
+	WebSocket    bool            `json:"websocket,omitempty" jsonschema:"compose sites: inject WebSocket upgrade headers and keep idle connections open"`
 	Force        bool            `json:"force,omitempty" jsonschema:"overwrite an existing site"`
 	Start        *bool           `json:"start,omitempty" jsonschema:"start the containers after adding (default true)"`
 }
@@ -174,24 +181,39 @@ func addSiteTool(ctx context.Context, req *mcpsdk.CallToolRequest, in addSiteIn)
 	for _, v := range in.Volumes {
 		mounts = append(mounts, site.VolumeMount{Source: anchorPath(ctx, req, v.Source), Target: v.Target, ReadOnly: v.ReadOnly})
 	}
+	nginxExtra := in.NginxExtra
+	if nginxExtra != "" {
+		nginxExtra = anchorPath(ctx, req, nginxExtra)
+	}
+	errorPages := in.ErrorPages
+	if errorPages != "" {
+		errorPages = anchorPath(ctx, req, errorPages)
+	}
 	res, err := site.Add(site.AddOptions{
-		Path:         in.Path,
-		TypeOverride: in.Type,
-		Name:         in.Name,
-		Domain:       in.Domain,
-		Aliases:      in.Aliases,
-		Port:         in.Port,
-		Local:        in.Local,
-		Wildcard:     in.Wildcard,
-		InternalHTTP: in.InternalHTTP,
-		Service:      in.Service,
-		Profile:      in.Profile,
-		SPA:          in.SPA,
-		Cache:        in.Cache,
-		CORS:         in.CORS,
-		Volumes:      mounts,
-		Force:        in.Force,
-		Start:        start,
+		Path:             in.Path,
+		TypeOverride:     in.Type,
+		Name:             in.Name,
+		Domain:           in.Domain,
+		Aliases:          in.Aliases,
+		Port:             in.Port,
+		Local:            in.Local,
+		Staging:          in.Staging,
+		Wildcard:         in.Wildcard,
+		InternalHTTP:     in.InternalHTTP,
+		Service:          in.Service,
+		Profile:          in.Profile,
+		SPA:              in.SPA,
+		Cache:            in.Cache,
+		CORS:             in.CORS,
+		Brotli:           in.Brotli,
+		DirectoryListing: in.DirListing,
+		Volumes:          mounts,
+		Middlewares:      in.Middlewares,
+		NginxExtra:       nginxExtra,
+		ErrorPages:       errorPages,
+		WebSocket:        in.WebSocket,
+		Force:            in.Force,
+		Start:            start,
 	})
 	if err != nil {
 		return nil, addSiteOut{Error: err.Error()}, nil //nolint:nilerr // surfaced in payload
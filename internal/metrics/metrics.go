@@ -13,6 +13,7 @@ import (
 
 	"github.com/stubbedev/srv/internal/config"
 	"github.com/stubbedev/srv/internal/constants"
+	"github.com/stubbedev/srv/internal/fsutil"
 	"github.com/stubbedev/srv/internal/platform"
 )
 
@@ -214,7 +215,7 @@ func WriteTraefikConfig(cfg *config.Config) error {
 	}
 
 	body := marshalYAML("# Generated by srv — metrics HTTPS routers\n", doc)
-	return os.WriteFile(TraefikConfigPath(cfg), []byte(body), constants.FilePermDefault)
+	return fsutil.AtomicWriteFile(TraefikConfigPath(cfg), []byte(body), constants.FilePermDefault)
 }
 
 // RemoveTraefikConfig deletes the file-provider yaml. Idempotent.
@@ -234,13 +235,13 @@ func WriteStack(cfg *config.Config) error {
 		return fmt.Errorf("create metrics dir: %w", err)
 	}
 
-	if err := os.WriteFile(filepath.Join(dir, "prometheus.yml"), []byte(prometheusYAML()), constants.FilePermDefault); err != nil {
+	if err := fsutil.AtomicWriteFile(filepath.Join(dir, "prometheus.yml"), []byte(prometheusYAML()), constants.FilePermDefault); err != nil {
 		return fmt.Errorf("write prometheus.yml: %w", err)
 	}
-	if err := os.WriteFile(filepath.Join(provDir, "prometheus.yml"), []byte(grafanaDatasourceYAML()), constants.FilePermDefault); err != nil {
+	if err := fsutil.AtomicWriteFile(filepath.Join(provDir, "prometheus.yml"), []byte(grafanaDatasourceYAML()), constants.FilePermDefault); err != nil {
 		return fmt.Errorf("write grafana datasource: %w", err)
 	}
-	if err := os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte(composeYAML(cfg.NetworkName)), constants.FilePermDefault); err != nil {
+	if err := fsutil.AtomicWriteFile(filepath.Join(dir, "docker-compose.yml"), []byte(composeYAML(cfg.NetworkName)), constants.FilePermDefault); err != nil {
 		return fmt.Errorf("write compose: %w", err)
 	}
 	return nil
@@ -0,0 +1,381 @@
+// Package metrics — scrape.go reads Traefik's Prometheus endpoint directly
+// for `srv metrics`, without the Prometheus container: a small parser for the
+// text exposition format, and the per-router request rate, error rate and
+// latency percentiles computed from two snapshots.
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stubbedev/srv/internal/constants"
+)
+
+// Traefik metric names read by Summarize, SiteStats and OpenConnections.
+// Traefik v2 reports open connections per entrypoint; v3 renamed the metric.
+const (
+	metricRouterRequests      = "traefik_router_requests_total"
+	metricRouterDurationBkt   = "traefik_router_request_duration_seconds_bucket"
+	metricRouterDurationSum   = "traefik_router_request_duration_seconds_sum"
+	metricRouterDurationCount = "traefik_router_request_duration_seconds_count"
+	metricRouterRequestBytes  = "traefik_router_requests_bytes_total"
+	metricRouterResponseBytes = "traefik_router_responses_bytes_total"
+	metricOpenConnections     = "traefik_open_connections"
+	metricOpenConnectionsV2   = "traefik_entrypoint_open_connections"
+)
+
+// traefikAPIEntryPoint serves the dashboard and /metrics itself; its
+// connections aren't site traffic.
+const traefikAPIEntryPoint = "traefik"
+
+const (
+	// scrapeTimeout bounds one fetch of the endpoint.
+	scrapeTimeout = 5 * time.Second
+	// maxScrapeBytes caps how much of the response is read.
+	maxScrapeBytes = 32 << 20
+)
+
+// EndpointURL is Traefik's Prometheus endpoint, on the API/dashboard port.
+// Tests swap it.
+var EndpointURL = fmt.Sprintf("%s%s:%d/metrics", constants.SchemeHTTPPrefix, constants.LocalhostIP, constants.PortDashboard)
+
+// Sample is one line of the text exposition format.
+type Sample struct {
+	Name   string
+	Labels map[string]string
+	Value  float64
+}
+
+// Snapshot is one scrape of the endpoint.
+type Snapshot struct {
+	At      time.Time
+	Samples []Sample
+}
+
+// Scrape fetches and parses EndpointURL. A 404 means Traefik's Prometheus
+// exporter is off.
+func Scrape(ctx context.Context) (*Snapshot, error) {
+	ctx, cancel := context.WithTimeout(ctx, scrapeTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, EndpointURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not reach Traefik's metrics endpoint: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("traefik's Prometheus exporter is off (run 'srv metrics enable')")
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("traefik's metrics endpoint returned %s", resp.Status)
+	}
+	samples, err := ParseText(io.LimitReader(resp.Body, maxScrapeBytes))
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{At: time.Now(), Samples: samples}, nil
+}
+
+// ParseText parses the Prometheus text exposition format. Comments, HELP and
+// TYPE lines are skipped, as is the optional timestamp after a value.
+func ParseText(r io.Reader) ([]Sample, error) {
+	var samples []Sample
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		s, err := parseSampleLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("metrics line %d: %w", n, err)
+		}
+		samples = append(samples, s)
+	}
+	return samples, sc.Err()
+}
+
+// parseSampleLine parses `name{k="v",...} value [timestamp]`.
+func parseSampleLine(line string) (Sample, error) {
+	s := Sample{Labels: map[string]string{}}
+	end := strings.IndexAny(line, "{ \t")
+	if end <= 0 {
+		return s, fmt.Errorf("malformed sample %q", line)
+	}
+	s.Name, line = line[:end], line[end:]
+	if strings.HasPrefix(line, "{") {
+		rest, err := parseLabels(line[1:], s.Labels)
+		if err != nil {
+			return s, err
+		}
+		line = rest
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return s, fmt.Errorf("sample %s has no value", s.Name)
+	}
+	v, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return s, fmt.Errorf("sample %s: invalid value %q", s.Name, fields[0])
+	}
+	s.Value = v
+	return s, nil
+}
+
+// parseLabels reads `k="v",...}` into labels and returns what follows the
+// closing brace. Values may contain the escapes \\, \" and \n.
+func parseLabels(in string, labels map[string]string) (string, error) {
+	for {
+		in = strings.TrimLeft(in, " ,")
+		if strings.HasPrefix(in, "}") {
+			return in[1:], nil
+		}
+		eq := strings.IndexByte(in, '=')
+		if eq <= 0 || len(in) < eq+2 || in[eq+1] != '"' {
+			return "", fmt.Errorf("malformed labels near %q", in)
+		}
+		key := strings.TrimSpace(in[:eq])
+		in = in[eq+2:]
+		var val strings.Builder
+		closed := false
+		for i := 0; i < len(in); i++ {
+			c := in[i]
+			if c == '\\' && i+1 < len(in) {
+				i++
+				switch in[i] {
+				case 'n':
+					val.WriteByte('\n')
+				default:
+					val.WriteByte(in[i])
+				}
+				continue
+			}
+			if c == '"' {
+				in = in[i+1:]
+				closed = true
+				break
+			}
+			val.WriteByte(c)
+		}
+		if !closed {
+			return "", fmt.Errorf("unterminated label value for %s", key)
+		}
+		labels[key] = val.String()
+	}
+}
+
+// routerStats accumulates one router's request counters, byte counters and
+// latency histogram (cumulative bucket counts keyed by upper bound, plus the
+// sum and count of all durations).
+type routerStats struct {
+	requests, status4xx, status5xx float64
+	durationSum, durationCount     float64
+	requestBytes, responseBytes    float64
+	buckets                        map[float64]float64
+}
+
+func newRouterStats() *routerStats {
+	return &routerStats{buckets: map[float64]float64{}}
+}
+
+// sub returns s minus an earlier o. Counters that went down mean Traefik
+// restarted in between, so s is returned whole.
+func (s *routerStats) sub(o *routerStats) *routerStats {
+	if o == nil || s.requests < o.requests {
+		return s
+	}
+	d := &routerStats{
+		requests:      s.requests - o.requests,
+		status4xx:     s.status4xx - o.status4xx,
+		status5xx:     s.status5xx - o.status5xx,
+		durationSum:   s.durationSum - o.durationSum,
+		durationCount: s.durationCount - o.durationCount,
+		requestBytes:  s.requestBytes - o.requestBytes,
+		responseBytes: s.responseBytes - o.responseBytes,
+		buckets:       make(map[float64]float64, len(s.buckets)),
+	}
+	for le, n := range s.buckets {
+		d.buckets[le] = n - o.buckets[le]
+	}
+	return d
+}
+
+// quantile estimates the q-quantile from the histogram the way Prometheus'
+// histogram_quantile does: linear interpolation within the bucket holding
+// it, and the highest finite bound when it falls in the +Inf bucket.
+func (s *routerStats) quantile(q float64) float64 {
+	bounds := make([]float64, 0, len(s.buckets))
+	for le := range s.buckets {
+		bounds = append(bounds, le)
+	}
+	sort.Float64s(bounds)
+	if len(bounds) == 0 {
+		return math.NaN()
+	}
+	total := s.buckets[bounds[len(bounds)-1]]
+	if total <= 0 {
+		return math.NaN()
+	}
+	rank := q * total
+	lower, below := 0.0, 0.0
+	for _, le := range bounds {
+		count := s.buckets[le]
+		if count >= rank {
+			if math.IsInf(le, 1) {
+				return lower
+			}
+			if count == below {
+				return le
+			}
+			return lower + (le-lower)*(rank-below)/(count-below)
+		}
+		lower, below = le, count
+	}
+	return lower
+}
+
+// routers groups the snapshot's router metrics by the name group returns for
+// each router label; routers it maps to "" are dropped.
+func (s *Snapshot) routers(group func(router string) string) map[string]*routerStats {
+	out := map[string]*routerStats{}
+	get := func(router string) *routerStats {
+		name := group(router)
+		if name == "" {
+			return nil
+		}
+		if out[name] == nil {
+			out[name] = newRouterStats()
+		}
+		return out[name]
+	}
+	for _, smp := range s.Samples {
+		switch smp.Name {
+		case metricRouterRequests:
+			st := get(smp.Labels["router"])
+			if st == nil {
+				continue
+			}
+			st.requests += smp.Value
+			switch code := smp.Labels["code"]; {
+			case strings.HasPrefix(code, "4"):
+				st.status4xx += smp.Value
+			case strings.HasPrefix(code, "5"):
+				st.status5xx += smp.Value
+			}
+		case metricRouterDurationBkt:
+			st := get(smp.Labels["router"])
+			if st == nil {
+				continue
+			}
+			le, err := strconv.ParseFloat(smp.Labels["le"], 64)
+			if err != nil {
+				continue
+			}
+			st.buckets[le] += smp.Value
+		case metricRouterDurationSum, metricRouterDurationCount, metricRouterRequestBytes, metricRouterResponseBytes:
+			st := get(smp.Labels["router"])
+			if st == nil {
+				continue
+			}
+			switch smp.Name {
+			case metricRouterDurationSum:
+				st.durationSum += smp.Value
+			case metricRouterDurationCount:
+				st.durationCount += smp.Value
+			case metricRouterRequestBytes:
+				st.requestBytes += smp.Value
+			default:
+				st.responseBytes += smp.Value
+			}
+		}
+	}
+	return out
+}
+
+// OpenConnections returns the connections open across the entrypoints sites
+// are served on, leaving out Traefik's own API entrypoint.
+func (s *Snapshot) OpenConnections() float64 {
+	var n float64
+	for _, smp := range s.Samples {
+		if (smp.Name == metricOpenConnections || smp.Name == metricOpenConnectionsV2) &&
+			smp.Labels["entrypoint"] != traefikAPIEntryPoint {
+			n += smp.Value
+		}
+	}
+	return n
+}
+
+// Summary is the traffic of one site (or other router group) in a window.
+type Summary struct {
+	Name string `json:"name"`
+	// Requests counts the requests the rates and latencies describe: those
+	// in the window, or every request since Traefik started when the window
+	// had none.
+	Requests       float64 `json:"requests"`
+	RequestsPerSec float64 `json:"requests_per_sec"`
+	// ErrorRate4xx and ErrorRate5xx are percentages of Requests.
+	ErrorRate4xx float64 `json:"error_rate_4xx"`
+	ErrorRate5xx float64 `json:"error_rate_5xx"`
+	// P50, P95 and P99 are latencies in milliseconds; -1 when unknown.
+	P50 float64 `json:"p50_ms"`
+	P95 float64 `json:"p95_ms"`
+	P99 float64 `json:"p99_ms"`
+}
+
+// Summarize reports the traffic between prev and cur per group (see
+// routers), sorted by name. With no prev, or no requests in the window, the
+// error rates and latencies cover everything since Traefik started and the
+// request rate is 0.
+func Summarize(prev, cur *Snapshot, group func(router string) string) []Summary {
+	now := cur.routers(group)
+	var before map[string]*routerStats
+	var elapsed float64
+	if prev != nil {
+		before = prev.routers(group)
+		elapsed = cur.At.Sub(prev.At).Seconds()
+	}
+
+	out := make([]Summary, 0, len(now))
+	for name, total := range now {
+		sum := Summary{Name: name}
+		stats := total
+		if prev != nil {
+			window := total.sub(before[name])
+			if elapsed > 0 {
+				sum.RequestsPerSec = window.requests / elapsed
+			}
+			if window.requests > 0 {
+				stats = window
+			}
+		}
+		sum.Requests = stats.requests
+		if stats.requests > 0 {
+			sum.ErrorRate4xx = 100 * stats.status4xx / stats.requests
+			sum.ErrorRate5xx = 100 * stats.status5xx / stats.requests
+		}
+		sum.P50, sum.P95, sum.P99 = millis(stats.quantile(0.5)), millis(stats.quantile(0.95)), millis(stats.quantile(0.99))
+		out = append(out, sum)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// millis converts seconds to milliseconds, mapping NaN to -1.
+func millis(sec float64) float64 {
+	if math.IsNaN(sec) {
+		return -1
+	}
+	return sec * 1000
+}
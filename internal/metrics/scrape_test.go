@@ -0,0 +1,144 @@
+package metrics
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleText = `# HELP traefik_router_requests_total How many HTTP requests are processed on a router.
+# TYPE traefik_router_requests_total counter
+traefik_router_requests_total{code="200",method="GET",protocol="http",router="site-blog@file",service="site-blog@file"} 90
+traefik_router_requests_total{code="404",method="GET",protocol="http",router="site-blog@file",service="site-blog@file"} 6
+traefik_router_requests_total{code="502",method="GET",protocol="http",router="site-blog@file",service="site-blog@file"} 4 1700000000000
+traefik_router_request_duration_seconds_bucket{code="200",router="site-blog@file",le="0.1"} 50
+traefik_router_request_duration_seconds_bucket{code="200",router="site-blog@file",le="0.3"} 80
+traefik_router_request_duration_seconds_bucket{code="200",router="site-blog@file",le="1.2"} 90
+traefik_router_request_duration_seconds_bucket{code="200",router="site-blog@file",le="+Inf"} 90
+traefik_router_request_duration_seconds_bucket{code="404",router="site-blog@file",le="0.1"} 10
+traefik_router_request_duration_seconds_bucket{code="404",router="site-blog@file",le="0.3"} 10
+traefik_router_request_duration_seconds_bucket{code="404",router="site-blog@file",le="1.2"} 10
+traefik_router_request_duration_seconds_bucket{code="404",router="site-blog@file",le="+Inf"} 10
+traefik_open_connections{entrypoint="websecure",protocol="TCP"} 3
+traefik_open_connections{entrypoint="traefik",protocol="TCP"} 1
+traefik_build_info{version="3.1.0",note="a \"quoted\" \\ value"} 1
+`
+
+func TestParseText(t *testing.T) {
+	samples, err := ParseText(strings.NewReader(sampleText))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 14 {
+		t.Fatalf("got %d samples, want 14", len(samples))
+	}
+	if s := samples[2]; s.Value != 4 || s.Labels["code"] != "502" || s.Labels["router"] != "site-blog@file" {
+		t.Errorf("sample with timestamp = %+v", s)
+	}
+	if got := samples[13].Labels["note"]; got != `a "quoted" \ value` {
+		t.Errorf("escaped label = %q", got)
+	}
+
+	for _, bad := range []string{"{code=\"200\"} 1", "name{code=\"200\" 1", "name{code=200} 1", "name", "name abc"} {
+		if _, err := ParseText(strings.NewReader(bad)); err == nil {
+			t.Errorf("ParseText(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func snapshot(t *testing.T, at time.Time, text string) *Snapshot {
+	t.Helper()
+	samples, err := ParseText(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Snapshot{At: at, Samples: samples}
+}
+
+func TestSummarize(t *testing.T) {
+	group := func(router string) string { return strings.TrimSuffix(router, "@file") }
+	now := time.Now()
+	cur := snapshot(t, now, sampleText)
+
+	lifetime := Summarize(nil, cur, group)
+	if len(lifetime) != 1 {
+		t.Fatalf("got %+v, want one row", lifetime)
+	}
+	got := lifetime[0]
+	if got.Name != "site-blog" || got.Requests != 100 || got.RequestsPerSec != 0 {
+		t.Errorf("lifetime = %+v", got)
+	}
+	if got.ErrorRate4xx != 6 || got.ErrorRate5xx != 4 {
+		t.Errorf("error rates = %v, %v; want 6, 4", got.ErrorRate4xx, got.ErrorRate5xx)
+	}
+	// Cumulative buckets: 60 ≤ 0.1s, 90 ≤ 0.3s, 100 ≤ 1.2s.
+	if want := 0.1 * 50 / 60 * 1000; abs(got.P50-want) > 0.01 {
+		t.Errorf("p50 = %v, want %v", got.P50, want)
+	}
+	if want := (0.3 + 0.9*9/10) * 1000; abs(got.P99-want) > 0.01 {
+		t.Errorf("p99 = %v, want %v", got.P99, want)
+	}
+	if n := cur.OpenConnections(); n != 3 {
+		t.Errorf("open connections = %v, want 3 (API entrypoint excluded)", n)
+	}
+
+	prev := snapshot(t, now.Add(-2*time.Second), strings.ReplaceAll(sampleText, "} 90", "} 80"))
+	windowed := Summarize(prev, cur, group)[0]
+	if windowed.RequestsPerSec != 5 || windowed.Requests != 10 {
+		t.Errorf("windowed = %+v, want 10 requests at 5/s", windowed)
+	}
+	if windowed.ErrorRate4xx != 0 {
+		t.Errorf("windowed 4xx = %v, want 0", windowed.ErrorRate4xx)
+	}
+
+	// Nothing new in the window: rates fall back to the lifetime counters.
+	idle := Summarize(cur, snapshot(t, now.Add(time.Second), sampleText), group)[0]
+	if idle.RequestsPerSec != 0 || idle.Requests != 100 {
+		t.Errorf("idle = %+v, want lifetime stats at 0/s", idle)
+	}
+}
+
+func abs(f float64) float64 {
+	if f < 0 {
+		return -f
+	}
+	return f
+}
+
+func TestQuantileInfBucket(t *testing.T) {
+	s := &routerStats{buckets: map[float64]float64{0.1: 1, 5: 2, math.Inf(1): 10}}
+	if got := s.quantile(0.99); got != 5 {
+		t.Errorf("quantile in +Inf bucket = %v, want 5 (highest finite bound)", got)
+	}
+	if got := newRouterStats().quantile(0.5); !math.IsNaN(got) {
+		t.Errorf("quantile of empty histogram = %v, want NaN", got)
+	}
+}
+
+func TestScrape(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/metrics" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(sampleText))
+	}))
+	defer srv.Close()
+	prev := EndpointURL
+	t.Cleanup(func() { EndpointURL = prev })
+
+	EndpointURL = srv.URL + "/metrics"
+	snap, err := Scrape(context.Background())
+	if err != nil || len(snap.Samples) != 14 {
+		t.Fatalf("Scrape() = %v, %v", snap, err)
+	}
+
+	EndpointURL = srv.URL + "/missing"
+	if _, err := Scrape(context.Background()); err == nil || !strings.Contains(err.Error(), "srv metrics enable") {
+		t.Errorf("404 err = %v, want a hint to enable the exporter", err)
+	}
+}
@@ -0,0 +1,136 @@
+// Package metrics — stats.go computes one site's traffic totals for
+// `srv stats`: requests, request rate, average and p95 latency and bytes
+// transferred, optionally counted from a stored baseline, since Prometheus
+// counters can't be reset.
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/stubbedev/srv/internal/constants"
+	"github.com/stubbedev/srv/internal/fsutil"
+)
+
+// Stats is one site's traffic since Traefik started, or since its baseline.
+type Stats struct {
+	Name string `json:"name"`
+	// Since is when the baseline was taken; nil counts from Traefik's start.
+	Since          *time.Time `json:"since,omitempty"`
+	Requests       float64    `json:"requests"`
+	RequestsPerMin float64    `json:"requests_per_min"`
+	// AvgMS and P95MS are latencies in milliseconds; -1 when unknown.
+	AvgMS         float64 `json:"avg_ms"`
+	P95MS         float64 `json:"p95_ms"`
+	RequestBytes  float64 `json:"request_bytes"`
+	ResponseBytes float64 `json:"response_bytes"`
+}
+
+// Baseline is a copy of a site's counters that `srv stats --reset` stores,
+// so later stats count from it.
+type Baseline struct {
+	At            time.Time          `json:"at"`
+	Requests      float64            `json:"requests"`
+	DurationSum   float64            `json:"duration_seconds_sum"`
+	DurationCount float64            `json:"duration_seconds_count"`
+	RequestBytes  float64            `json:"request_bytes"`
+	ResponseBytes float64            `json:"response_bytes"`
+	Buckets       map[string]float64 `json:"duration_buckets"`
+}
+
+// NewBaseline records name's counters in s (see routers for group).
+func (s *Snapshot) NewBaseline(name string, group func(router string) string) Baseline {
+	st := s.routers(group)[name]
+	if st == nil {
+		st = newRouterStats()
+	}
+	b := Baseline{
+		At:            s.At,
+		Requests:      st.requests,
+		DurationSum:   st.durationSum,
+		DurationCount: st.durationCount,
+		RequestBytes:  st.requestBytes,
+		ResponseBytes: st.responseBytes,
+		Buckets:       make(map[string]float64, len(st.buckets)),
+	}
+	for le, n := range st.buckets {
+		b.Buckets[strconv.FormatFloat(le, 'g', -1, 64)] = n
+	}
+	return b
+}
+
+// stats converts b back into routerStats for subtraction.
+func (b *Baseline) stats() *routerStats {
+	st := &routerStats{
+		requests:      b.Requests,
+		durationSum:   b.DurationSum,
+		durationCount: b.DurationCount,
+		requestBytes:  b.RequestBytes,
+		responseBytes: b.ResponseBytes,
+		buckets:       make(map[float64]float64, len(b.Buckets)),
+	}
+	for key, n := range b.Buckets {
+		if le, err := strconv.ParseFloat(key, 64); err == nil {
+			st.buckets[le] = n
+		}
+	}
+	return st
+}
+
+// SiteStats reports name's traffic in cur, counted from base when it's set
+// and still applies (a Traefik restart resets the counters below it). The
+// request rate covers the window from prev to cur.
+func SiteStats(prev, cur *Snapshot, name string, group func(router string) string, base *Baseline) Stats {
+	out := Stats{Name: name, AvgMS: -1, P95MS: -1}
+	total := cur.routers(group)[name]
+	if total == nil {
+		total = newRouterStats()
+	}
+	if prev != nil {
+		window := total.sub(prev.routers(group)[name])
+		if elapsed := cur.At.Sub(prev.At).Minutes(); elapsed > 0 {
+			out.RequestsPerMin = window.requests / elapsed
+		}
+	}
+	if base != nil && total.requests >= base.Requests {
+		total = total.sub(base.stats())
+		at := base.At
+		out.Since = &at
+	}
+	out.Requests = total.requests
+	out.RequestBytes = total.requestBytes
+	out.ResponseBytes = total.responseBytes
+	if total.durationCount > 0 {
+		out.AvgMS = millis(total.durationSum / total.durationCount)
+	}
+	out.P95MS = millis(total.quantile(0.95))
+	return out
+}
+
+// ReadBaseline loads the baseline stored at path; nil when there is none.
+func ReadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("parse stats baseline %s: %w", path, err)
+	}
+	return &b, nil
+}
+
+// WriteBaseline stores b at path.
+func WriteBaseline(path string, b Baseline) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsutil.AtomicWriteFile(path, append(data, '\n'), constants.FilePermDefault)
+}
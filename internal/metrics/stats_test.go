@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const statsText = `traefik_router_requests_total{code="200",router="site-blog@file"} 100
+traefik_router_request_duration_seconds_bucket{code="200",router="site-blog@file",le="0.1"} 60
+traefik_router_request_duration_seconds_bucket{code="200",router="site-blog@file",le="0.5"} 100
+traefik_router_request_duration_seconds_bucket{code="200",router="site-blog@file",le="+Inf"} 100
+traefik_router_request_duration_seconds_sum{code="200",router="site-blog@file"} 10
+traefik_router_request_duration_seconds_count{code="200",router="site-blog@file"} 100
+traefik_router_requests_bytes_total{code="200",router="site-blog@file"} 2048
+traefik_router_responses_bytes_total{code="200",router="site-blog@file"} 65536
+`
+
+func statsSnapshot(t *testing.T, at time.Time, text string) *Snapshot {
+	t.Helper()
+	samples, err := ParseText(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Snapshot{At: at, Samples: samples}
+}
+
+func TestSiteStats(t *testing.T) {
+	group := func(router string) string { return strings.TrimSuffix(strings.TrimPrefix(router, "site-"), "@file") }
+	at := time.Now()
+	prev := statsSnapshot(t, at, strings.ReplaceAll(statsText, "} 100\n", "} 70\n"))
+	cur := statsSnapshot(t, at.Add(30*time.Second), statsText)
+
+	st := SiteStats(prev, cur, "blog", group, nil)
+	if st.Requests != 100 || st.RequestsPerMin != 60 || st.Since != nil {
+		t.Errorf("requests = %v, per min = %v, since = %v", st.Requests, st.RequestsPerMin, st.Since)
+	}
+	if st.AvgMS != 100 || st.RequestBytes != 2048 || st.ResponseBytes != 65536 {
+		t.Errorf("avg = %v ms, bytes = %v in, %v out", st.AvgMS, st.RequestBytes, st.ResponseBytes)
+	}
+	if st.P95MS < 100 || st.P95MS > 500 {
+		t.Errorf("p95 = %v ms, want within the 0.1-0.5s bucket", st.P95MS)
+	}
+
+	// Counting from a baseline taken at prev leaves only the later traffic.
+	base := prev.NewBaseline("blog", group)
+	path := filepath.Join(t.TempDir(), "stats-baseline.json")
+	if err := WriteBaseline(path, base); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadBaseline(path)
+	if err != nil || read == nil {
+		t.Fatalf("ReadBaseline = %v, %v", read, err)
+	}
+	st = SiteStats(prev, cur, "blog", group, read)
+	if st.Requests != 30 || st.Since == nil || !st.Since.Equal(prev.At) {
+		t.Errorf("since baseline: requests = %v, since = %v", st.Requests, st.Since)
+	}
+
+	// A baseline above the current counters predates a Traefik restart.
+	st = SiteStats(nil, prev, "blog", group, &Baseline{Requests: 500})
+	if st.Requests != 70 || st.Since != nil {
+		t.Errorf("stale baseline: requests = %v, since = %v", st.Requests, st.Since)
+	}
+
+	if b, err := ReadBaseline(filepath.Join(t.TempDir(), "missing.json")); b != nil || err != nil {
+		t.Errorf("missing baseline = %v, %v", b, err)
+	}
+}
@@ -136,6 +136,10 @@ type InstallResult struct {
 	NewCA              bool   // A fresh local CA was created during this run
 	SudoDenied         bool   // sudo password prompt failed or was refused; CA install aborted
 	RawOutput          string // Captured combined output (for debugging)
+	// WSL2 only, set by InstallWindows.
+	WindowsTrustOK       bool  // CA installed in the Windows certificate store
+	WindowsMkcertMissing bool  // mkcert.exe not found on the Windows PATH
+	WindowsErr           error // why the Windows install failed otherwise
 }
 
 // Install runs `mkcert -install` and parses its output into an InstallResult.
@@ -0,0 +1,105 @@
+package mkcert
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// windowsBinary is the Windows build of mkcert, reached through WSL interop.
+const windowsBinary = "mkcert.exe"
+
+// hostCommand runs name with extra environment variables and returns its
+// combined output. Tests swap it with SwapHostCommand.
+var hostCommand = func(env []string, name string, args ...string) ([]byte, error) {
+	path, err := lookPath(name)
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(path, args...)
+	cmd.Env = append(os.Environ(), env...)
+	return cmd.CombinedOutput()
+}
+
+// SwapHostCommand replaces the runner InstallWindows uses and returns a
+// restore func. For tests.
+func SwapHostCommand(fn func(env []string, name string, args ...string) ([]byte, error)) func() {
+	prev := hostCommand
+	hostCommand = fn
+	return func() { hostCommand = prev }
+}
+
+// InstallWindows trusts the local CA in the Windows certificate store, for
+// browsers running on the Windows side of WSL2. rootCA.pem (never the key) is
+// copied to %LOCALAPPDATA%\srv\mkcert and the Windows mkcert.exe runs
+// `-install` with CAROOT pointing there; WSLENV=CAROOT/p translates the path
+// for it. The outcome is recorded in res's Windows fields.
+func InstallWindows(res *InstallResult) {
+	if _, err := lookPath(windowsBinary); err != nil {
+		res.WindowsMkcertMissing = true
+		return
+	}
+	caRoot := filepath.Dir(res.CARootPath)
+	if res.CARootPath == "" {
+		dir, err := caRootDir()
+		if err != nil {
+			res.WindowsErr = fmt.Errorf("locate the local CA: %w", err)
+			return
+		}
+		caRoot = dir
+	}
+	winCARoot, err := windowsCARoot()
+	if err != nil {
+		res.WindowsErr = err
+		return
+	}
+	rootCA, err := os.ReadFile(filepath.Join(caRoot, "rootCA.pem"))
+	if err != nil {
+		res.WindowsErr = fmt.Errorf("read the local CA: %w", err)
+		return
+	}
+	if err := os.MkdirAll(winCARoot, 0o755); err != nil {
+		res.WindowsErr = err
+		return
+	}
+	if err := os.WriteFile(filepath.Join(winCARoot, "rootCA.pem"), rootCA, 0o644); err != nil {
+		res.WindowsErr = err
+		return
+	}
+
+	out, err := hostCommand([]string{"CAROOT=" + winCARoot, "WSLENV=" + wslenvWith("CAROOT/p")}, windowsBinary, "-install")
+	res.RawOutput += string(out)
+	if err != nil {
+		res.WindowsErr = fmt.Errorf("%s -install: %w", windowsBinary, err)
+		return
+	}
+	res.WindowsTrustOK = parseInstallOutput(string(out)).SystemTrustOK
+}
+
+// windowsCARoot returns the WSL path of %LOCALAPPDATA%\srv\mkcert in the
+// Windows user profile.
+func windowsCARoot() (string, error) {
+	out, err := hostCommand(nil, "cmd.exe", "/c", "echo %LOCALAPPDATA%")
+	if err != nil {
+		return "", fmt.Errorf("read %%LOCALAPPDATA%% from Windows: %w", err)
+	}
+	winPath := strings.TrimSpace(string(out))
+	if winPath == "" || strings.Contains(winPath, "%") {
+		return "", fmt.Errorf("%%LOCALAPPDATA%% is not set on Windows")
+	}
+	out, err = hostCommand(nil, "wslpath", "-u", winPath)
+	if err != nil {
+		return "", fmt.Errorf("convert %s to a WSL path: %w", winPath, err)
+	}
+	return filepath.Join(strings.TrimSpace(string(out)), "srv", "mkcert"), nil
+}
+
+// wslenvWith appends entry to the WSLENV variables shared with Windows.
+func wslenvWith(entry string) string {
+	if current := os.Getenv("WSLENV"); current != "" {
+		return current + ":" + entry
+	}
+	return entry
+}
@@ -0,0 +1,63 @@
+package mkcert
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestInstallWindows(t *testing.T) {
+	caRoot := t.TempDir()
+	if err := os.WriteFile(filepath.Join(caRoot, "rootCA.pem"), []byte("CA"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	localAppData := t.TempDir()
+	t.Setenv("WSLENV", "USERPROFILE/p")
+	t.Cleanup(SwapLookPath(func(name string) (string, error) { return "/mnt/c/bin/" + name, nil }))
+	var installEnv []string
+	t.Cleanup(SwapHostCommand(func(env []string, name string, args ...string) ([]byte, error) {
+		switch name {
+		case "cmd.exe":
+			return []byte("C:\\Users\\dev\\AppData\\Local\r\n"), nil
+		case "wslpath":
+			return []byte(localAppData + "\n"), nil
+		case windowsBinary:
+			installEnv = env
+			return []byte("The local CA is now installed in the system trust store! ⚡️\n"), nil
+		}
+		return nil, errors.New("unexpected command " + name)
+	}))
+
+	res := InstallResult{CARootPath: filepath.Join(caRoot, "rootCA.pem")}
+	InstallWindows(&res)
+	if !res.WindowsTrustOK || res.WindowsErr != nil {
+		t.Fatalf("result = %+v", res)
+	}
+	winCARoot := filepath.Join(localAppData, "srv", "mkcert")
+	if data, err := os.ReadFile(filepath.Join(winCARoot, "rootCA.pem")); err != nil || string(data) != "CA" {
+		t.Errorf("copied CA = %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(winCARoot, "rootCA-key.pem")); err == nil {
+		t.Error("the CA key must not be copied to Windows")
+	}
+	if !slices.Contains(installEnv, "CAROOT="+winCARoot) || !slices.Contains(installEnv, "WSLENV=USERPROFILE/p:CAROOT/p") {
+		t.Errorf("mkcert.exe env = %v", installEnv)
+	}
+}
+
+func TestInstallWindowsMissingMkcert(t *testing.T) {
+	t.Cleanup(SwapLookPath(func(name string) (string, error) {
+		if strings.HasSuffix(name, ".exe") {
+			return "", errors.New("not found")
+		}
+		return "/usr/bin/" + name, nil
+	}))
+	var res InstallResult
+	InstallWindows(&res)
+	if !res.WindowsMkcertMissing || res.WindowsTrustOK {
+		t.Errorf("result = %+v", res)
+	}
+}
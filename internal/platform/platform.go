@@ -7,7 +7,9 @@ package platform
 
 import (
 	"fmt"
+	"os"
 	"runtime"
+	"strings"
 )
 
 // IsLinux reports whether srv is running on Linux.
@@ -20,6 +22,32 @@ func IsDarwin() bool {
 	return runtime.GOOS == "darwin"
 }
 
+// procVersion is the kernel version file IsWSL reads. Tests swap it with
+// SwapProcVersion.
+var procVersion = "/proc/version"
+
+// IsWSL reports whether srv is running under Windows Subsystem for Linux,
+// whose kernel version string names Microsoft (WSL1) or WSL (WSL2).
+func IsWSL() bool {
+	if !IsLinux() {
+		return false
+	}
+	data, err := os.ReadFile(procVersion)
+	if err != nil {
+		return false
+	}
+	version := string(data)
+	return strings.Contains(strings.ToLower(version), "microsoft") || strings.Contains(version, "WSL")
+}
+
+// SwapProcVersion points IsWSL at another kernel version file and returns a
+// restore func. For tests.
+func SwapProcVersion(path string) func() {
+	prev := procVersion
+	procVersion = path
+	return func() { procVersion = prev }
+}
+
 // OS returns the current operating system identifier (runtime.GOOS).
 // Use this when you need the raw value for an error message or log line.
 func OS() string {
@@ -1,6 +1,8 @@
 package platform
 
 import (
+	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"testing"
@@ -53,3 +55,26 @@ func TestExactlyOneOfLinuxOrDarwinOrOther(t *testing.T) {
 		t.Errorf("IsLinux and IsDarwin both true for GOOS=%q", runtime.GOOS)
 	}
 }
+
+func TestIsWSL(t *testing.T) {
+	dir := t.TempDir()
+	for name, want := range map[string]bool{
+		"Linux version 5.15.153.1-microsoft-standard-WSL2 (root@65c757a075e2)": true,
+		"Linux version 4.4.0-19041-Microsoft (Microsoft@Microsoft.com)":        true,
+		"Linux version 6.8.0-45-generic (buildd@lcy02-amd64-075)":              false,
+	} {
+		path := filepath.Join(dir, "version")
+		if err := os.WriteFile(path, []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		restore := SwapProcVersion(path)
+		if got := IsWSL(); got != (want && runtime.GOOS == "linux") {
+			t.Errorf("IsWSL() with %q = %v, want %v", name, got, want)
+		}
+		restore()
+	}
+	t.Cleanup(SwapProcVersion(filepath.Join(dir, "missing")))
+	if IsWSL() {
+		t.Error("IsWSL() = true without a readable version file")
+	}
+}
@@ -0,0 +1,263 @@
+// Package preset stores middleware presets: named bundles of the Traefik
+// middleware settings `srv add` takes (compression, rate limit, response
+// headers, IP allowlist, custom middlewares), so sites sharing a stack don't
+// repeat the flags. `srv preset create` writes one to
+// ~/.config/srv/presets/<name>.yml; `srv add --middleware-preset` copies its
+// settings into the new site's metadata, so later edits to the preset don't
+// change existing sites.
+package preset
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/constants"
+	"github.com/stubbedev/srv/internal/fsutil"
+	"github.com/stubbedev/srv/internal/traefik"
+	"github.com/stubbedev/srv/internal/validate"
+)
+
+// fileExt is the extension of preset files.
+const fileExt = ".yml"
+
+// Preset is one presets/<name>.yml file. Fields mirror the site metadata
+// fields of the same names.
+type Preset struct {
+	// Name is the file's base name; not stored in the file.
+	Name string `yaml:"-" json:"name"`
+	// Compress compresses responses at Traefik.
+	Compress bool `yaml:"compress,omitempty" json:"compress,omitempty"`
+	// RateLimit is a per-client rate limit written AVERAGE-UNIT, e.g. 100-S.
+	RateLimit string `yaml:"rate_limit,omitempty" json:"rate_limit,omitempty"`
+	// AddHeaders and RemoveHeaders edit every response.
+	AddHeaders    map[string]string `yaml:"add_headers,omitempty" json:"add_headers,omitempty"`
+	RemoveHeaders []string          `yaml:"remove_headers,omitempty" json:"remove_headers,omitempty"`
+	// AllowIPs lists the client CIDRs allowed in; empty allows everyone.
+	AllowIPs []string `yaml:"allow_ips,omitempty" json:"allow_ips,omitempty"`
+	// Middlewares are Traefik middlewares from the dynamic config, appended
+	// to the site's router in order.
+	Middlewares []string `yaml:"middlewares,omitempty" json:"middlewares,omitempty"`
+}
+
+// Validate checks the preset's settings the way `srv add` checks its flags.
+func (p *Preset) Validate() error {
+	if p.RateLimit != "" {
+		if _, _, err := traefik.ParseRateLimit(p.RateLimit); err != nil {
+			return err
+		}
+	}
+	if err := validate.ResponseHeaders(p.AddHeaders, p.RemoveHeaders); err != nil {
+		return err
+	}
+	if err := validate.CIDRs(p.AllowIPs); err != nil {
+		return err
+	}
+	return validate.Middlewares(p.Middlewares)
+}
+
+// Empty reports whether the preset sets nothing.
+func (p *Preset) Empty() bool {
+	return !p.Compress && p.RateLimit == "" && len(p.AddHeaders) == 0 &&
+		len(p.RemoveHeaders) == 0 && len(p.AllowIPs) == 0 && len(p.Middlewares) == 0
+}
+
+// Summary describes the preset's settings in one line, e.g.
+// "compress, rate limit 100-S, 1 header".
+func (p *Preset) Summary() string {
+	var parts []string
+	if p.Compress {
+		parts = append(parts, "compress")
+	}
+	if p.RateLimit != "" {
+		parts = append(parts, "rate limit "+p.RateLimit)
+	}
+	if n := len(p.AddHeaders) + len(p.RemoveHeaders); n > 0 {
+		parts = append(parts, plural(n, "header"))
+	}
+	if n := len(p.AllowIPs); n > 0 {
+		parts = append(parts, plural(n, "allowed CIDR"))
+	}
+	if len(p.Middlewares) > 0 {
+		parts = append(parts, "middlewares "+strings.Join(p.Middlewares, ","))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// dir returns ~/.config/srv/presets.
+func dir(cfg *config.Config) string {
+	return filepath.Join(cfg.Root, constants.PresetsDir)
+}
+
+func path(cfg *config.Config, name string) string {
+	return filepath.Join(dir(cfg), name+fileExt)
+}
+
+// Read returns the named preset, or an error naming it when it doesn't
+// exist.
+func Read(name string) (*Preset, error) {
+	if err := validate.SiteName(name); err != nil {
+		return nil, fmt.Errorf("invalid preset name: %w", err)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path(cfg, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("preset %q not found (see 'srv preset list')", name)
+		}
+		return nil, err
+	}
+	var p Preset
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("parse preset %s: %w", name, err)
+	}
+	p.Name = name
+	return &p, nil
+}
+
+// Write validates and saves p. An existing preset of the same name is only
+// replaced when force is set.
+func Write(p Preset, force bool) error {
+	if err := validate.SiteName(p.Name); err != nil {
+		return fmt.Errorf("invalid preset name: %w", err)
+	}
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	target := path(cfg, p.Name)
+	if _, err := os.Stat(target); err == nil && !force {
+		return fmt.Errorf("preset %q already exists (use --force to replace it)", p.Name)
+	}
+	if err := os.MkdirAll(dir(cfg), constants.DirPermDefault); err != nil {
+		return fmt.Errorf("create presets dir: %w", err)
+	}
+	data, err := yaml.Marshal(&p)
+	if err != nil {
+		return err
+	}
+	header := "# Middleware preset — generated by srv; apply with 'srv add --middleware-preset " + p.Name + "'\n"
+	return fsutil.AtomicWriteFile(target, append([]byte(header), data...), constants.FilePermDefault)
+}
+
+// Delete removes the named preset.
+func Delete(name string) error {
+	if err := validate.SiteName(name); err != nil {
+		return fmt.Errorf("invalid preset name: %w", err)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path(cfg, name)); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("preset %q not found", name)
+		}
+		return err
+	}
+	return nil
+}
+
+// List returns every preset, sorted by name. Files that fail to parse are
+// skipped.
+func List() ([]Preset, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir(cfg))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var out []Preset
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), fileExt)
+		if e.IsDir() || !ok {
+			continue
+		}
+		if p, err := Read(name); err == nil {
+			out = append(out, *p)
+		}
+	}
+	slices.SortFunc(out, func(a, b Preset) int { return strings.Compare(a.Name, b.Name) })
+	return out, nil
+}
+
+// Names returns the names of every preset, for shell completion.
+func Names() []string {
+	presets, _ := List()
+	names := make([]string, 0, len(presets))
+	for _, p := range presets {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// Settings are the site settings a preset fills in.
+type Settings struct {
+	Compress      bool
+	RateLimit     string
+	AddHeaders    map[string]string
+	RemoveHeaders []string
+	AllowIPs      []string
+	Middlewares   []string
+}
+
+// Apply merges the preset into a site's settings: compress and the rate
+// limit are taken from the preset unless already set, preset headers and
+// middlewares come before the site's own (which win on conflicts), and
+// allowlists and removed headers are combined.
+func (p *Preset) Apply(s *Settings) {
+	s.Compress = s.Compress || p.Compress
+	if s.RateLimit == "" {
+		s.RateLimit = p.RateLimit
+	}
+	if len(p.AddHeaders) > 0 {
+		merged := make(map[string]string, len(p.AddHeaders)+len(s.AddHeaders))
+		for k, v := range p.AddHeaders {
+			merged[k] = v
+		}
+		for k, v := range s.AddHeaders {
+			merged[k] = v
+		}
+		s.AddHeaders = merged
+	}
+	s.RemoveHeaders = union(p.RemoveHeaders, s.RemoveHeaders)
+	s.AllowIPs = union(p.AllowIPs, s.AllowIPs)
+	s.Middlewares = union(p.Middlewares, s.Middlewares)
+}
+
+// union returns a followed by the entries of b not in a.
+func union(a, b []string) []string {
+	if len(a) == 0 {
+		return b
+	}
+	out := slices.Clone(a)
+	for _, v := range b {
+		if !slices.Contains(out, v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
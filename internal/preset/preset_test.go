@@ -0,0 +1,107 @@
+package preset
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/stubbedev/srv/internal/config"
+)
+
+func withSRVRoot(t *testing.T) {
+	t.Helper()
+	t.Setenv("SRV_ROOT", t.TempDir())
+	config.ResetCache()
+	t.Cleanup(func() { config.ResetCache() })
+}
+
+func TestWriteReadListDelete(t *testing.T) {
+	withSRVRoot(t)
+	api := Preset{Name: "api", Compress: true, RateLimit: "100-S", AddHeaders: map[string]string{"X-Env": "dev"}}
+	if err := Write(api, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(Preset{Name: "office", AllowIPs: []string{"10.0.0.0/8"}}, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(api, false); err == nil {
+		t.Error("expected an error overwriting a preset without force")
+	}
+	if err := Write(api, true); err != nil {
+		t.Errorf("force overwrite: %v", err)
+	}
+
+	got, err := Read("api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "api" || !got.Compress || got.RateLimit != "100-S" || got.AddHeaders["X-Env"] != "dev" {
+		t.Errorf("Read = %+v", got)
+	}
+	if names := Names(); !slices.Equal(names, []string{"api", "office"}) {
+		t.Errorf("Names = %v", names)
+	}
+
+	if err := Delete("api"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Read("api"); err == nil {
+		t.Error("expected an error reading a deleted preset")
+	}
+	if err := Delete("api"); err == nil {
+		t.Error("expected an error deleting a missing preset")
+	}
+}
+
+func TestWriteValidates(t *testing.T) {
+	withSRVRoot(t)
+	for _, p := range []Preset{
+		{Name: "a", RateLimit: "fast"},
+		{Name: "a", AllowIPs: []string{"not-a-cidr"}},
+		{Name: "a", AddHeaders: map[string]string{"bad name": "v"}},
+		{Name: "../a", Compress: true},
+	} {
+		if err := Write(p, false); err == nil {
+			t.Errorf("Write(%+v) = nil error", p)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	p := Preset{
+		Compress:      true,
+		RateLimit:     "100-S",
+		AddHeaders:    map[string]string{"X-Env": "dev", "X-Team": "web"},
+		RemoveHeaders: []string{"Server"},
+		Middlewares:   []string{"retry"},
+	}
+	s := Settings{
+		RateLimit:     "10-M",
+		AddHeaders:    map[string]string{"X-Env": "prod"},
+		RemoveHeaders: []string{"X-Powered-By", "Server"},
+		Middlewares:   []string{"auth"},
+	}
+	p.Apply(&s)
+	if !s.Compress || s.RateLimit != "10-M" {
+		t.Errorf("compress/rate limit = %v/%q", s.Compress, s.RateLimit)
+	}
+	if want := map[string]string{"X-Env": "prod", "X-Team": "web"}; !maps.Equal(s.AddHeaders, want) {
+		t.Errorf("AddHeaders = %v, want %v", s.AddHeaders, want)
+	}
+	if want := []string{"Server", "X-Powered-By"}; !slices.Equal(s.RemoveHeaders, want) {
+		t.Errorf("RemoveHeaders = %v, want %v", s.RemoveHeaders, want)
+	}
+	if want := []string{"retry", "auth"}; !slices.Equal(s.Middlewares, want) {
+		t.Errorf("Middlewares = %v, want %v", s.Middlewares, want)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	p := Preset{Compress: true, RateLimit: "100-S", AddHeaders: map[string]string{"X-Env": "dev"}, AllowIPs: []string{"10.0.0.0/8", "192.168.0.0/16"}}
+	if got, want := p.Summary(), "compress, rate limit 100-S, 1 header, 2 allowed CIDRs"; got != want {
+		t.Errorf("Summary = %q, want %q", got, want)
+	}
+	if !(&Preset{}).Empty() || p.Empty() {
+		t.Error("Empty mismatch")
+	}
+}
@@ -0,0 +1,73 @@
+// Package proxy — disable.go implements `srv proxy disable/enable`. A
+// disabled proxy keeps every file it owns; its Traefik configs are renamed
+// to *.yml.disabled, which the file provider doesn't load, so routing stops
+// until they are renamed back.
+package proxy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/constants"
+	"github.com/stubbedev/srv/internal/traefik"
+)
+
+// ConfigPath returns the Traefik config file of the named proxy while it is
+// enabled: proxy-tcp-<name>.yml for a TCP proxy, proxy-<name>.yml otherwise.
+func ConfigPath(cfg *config.Config, name string) string {
+	if IsTCP(name) {
+		return traefik.TCPProxyConfigPath(cfg, name)
+	}
+	return filepath.Join(cfg.TraefikConfDir(), constants.ProxyConfigPrefix+name+constants.ExtYAML)
+}
+
+// routingFiles returns the Traefik files that route to the named proxy: its
+// config and its extra routes file.
+func routingFiles(cfg *config.Config, name string) []string {
+	return []string{ConfigPath(cfg, name), traefik.RoutesConfigPath(cfg, name)}
+}
+
+// IsDisabled reports whether the named proxy has been disabled.
+func IsDisabled(cfg *config.Config, name string) bool {
+	path := ConfigPath(cfg, name)
+	if _, err := os.Stat(path); err == nil {
+		return false
+	}
+	_, err := os.Stat(path + constants.ExtDisabled)
+	return err == nil
+}
+
+// Disable stops Traefik routing to the named proxy by renaming its config
+// and routes files to *.disabled.
+func Disable(cfg *config.Config, name string) error {
+	if IsDisabled(cfg, name) {
+		return fmt.Errorf("proxy %q is already disabled", name)
+	}
+	if _, err := os.Stat(ConfigPath(cfg, name)); err != nil {
+		return fmt.Errorf("proxy %q not found", name)
+	}
+	for _, path := range routingFiles(cfg, name) {
+		if err := os.Rename(path, path+constants.ExtDisabled); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("disable %s: %w", filepath.Base(path), err)
+		}
+	}
+	return nil
+}
+
+// Enable reverses Disable.
+func Enable(cfg *config.Config, name string) error {
+	if !IsDisabled(cfg, name) {
+		if _, err := os.Stat(ConfigPath(cfg, name)); err == nil {
+			return fmt.Errorf("proxy %q is not disabled", name)
+		}
+		return fmt.Errorf("proxy %q not found", name)
+	}
+	for _, path := range routingFiles(cfg, name) {
+		if err := os.Rename(path+constants.ExtDisabled, path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("enable %s: %w", filepath.Base(path), err)
+		}
+	}
+	return nil
+}
@@ -16,13 +16,15 @@ import (
 
 	"github.com/stubbedev/srv/internal/config"
 	"github.com/stubbedev/srv/internal/constants"
+	"github.com/stubbedev/srv/internal/fsutil"
 	"github.com/stubbedev/srv/internal/site"
 	"github.com/stubbedev/srv/internal/traefik"
 )
 
 // Metadata captures everything `srv route` needs to know about a proxy:
-// the canonical domain (+ aliases), local-CA flag, wildcard flag, and any
-// attached routes. Stored at ~/.config/srv/proxies/<name>/metadata.yml.
+// the canonical domain (+ aliases), local-CA flag, wildcard flag, path
+// prefix, and any attached routes. Stored at
+// ~/.config/srv/proxies/<name>/metadata.yml.
 type Metadata struct {
 	// metadata.yml schema version (1 = current).
 	SchemaVersion int `yaml:"schema_version,omitempty"`
@@ -34,6 +36,26 @@ type Metadata struct {
 	Wildcard bool `yaml:"wildcard,omitempty"`
 	// Use a locally-issued (mkcert) SSL certificate instead of Let's Encrypt.
 	IsLocal bool `yaml:"is_local,omitempty"`
+	// Only route requests under this path (e.g. /api); empty routes the whole host.
+	PathPrefix string `yaml:"path_prefix,omitempty"`
+	// Remove path_prefix from the request path before forwarding upstream.
+	StripPrefix bool `yaml:"strip_prefix,omitempty"`
+	// Time to wait for upstream response headers (Go duration, e.g. 5m).
+	Timeout string `yaml:"timeout,omitempty"`
+	// Idle time on an HTTP/2 upstream connection before a health ping (Go duration).
+	ReadTimeout string `yaml:"read_timeout,omitempty"`
+	// Time allowed to establish the upstream connection (Go duration).
+	DialTimeout string `yaml:"dial_timeout,omitempty"`
+	// Inject WebSocket upgrade headers and keep idle upstream connections open.
+	WebSocket bool `yaml:"websocket,omitempty"`
+	// Forward to a gRPC upstream over HTTP/2: h2c (cleartext) or tls.
+	GRPC string `yaml:"grpc,omitempty"`
+	// Forward raw TCP (proxy-tcp-<name>.yml) instead of HTTP, routed by SNI.
+	TCP bool `yaml:"tcp,omitempty"`
+	// Port of the tcp-<port> entrypoint a TCP proxy listens on.
+	EntryPointPort int `yaml:"entrypoint_port,omitempty"`
+	// Forward a TCP proxy's TLS stream untouched instead of terminating it.
+	Passthrough bool `yaml:"passthrough,omitempty"`
 	// Extra Traefik routers (path-prefix / regex-rewrite splits) attached via `srv route`.
 	Routes []site.Route `yaml:"routes,omitempty"`
 }
@@ -103,7 +125,7 @@ func Write(meta Metadata) error {
 	}
 	header := "# yaml-language-server: $schema=" + constants.ProxyMetadataSchemaURL + "\n" +
 		"# Proxy metadata — generated by srv\n"
-	return os.WriteFile(metadataPath(cfg, meta.Name), append([]byte(header), data...), constants.FilePermDefault)
+	return fsutil.AtomicWriteFile(metadataPath(cfg, meta.Name), append([]byte(header), data...), constants.FilePermDefault)
 }
 
 // Remove deletes the proxy's metadata directory. Safe to call when the
@@ -143,6 +165,39 @@ func ListNames() []string {
 	return out
 }
 
+// IsTCP reports whether the named proxy is a TCP proxy.
+func IsTCP(name string) bool {
+	meta, err := Read(name)
+	return err == nil && meta != nil && meta.TCP
+}
+
+// CheckHTTPName rejects names an HTTP proxy can't take: a TCP proxy's, and
+// tcp-<name>, whose proxy-tcp-<name>.yml would clash with the TCP proxy
+// <name>'s config file.
+func CheckHTTPName(name string) error {
+	if IsTCP(name) {
+		return fmt.Errorf("proxy %q is a TCP proxy; remove it first or pick another name", name)
+	}
+	if strings.HasPrefix(name, constants.TCPEntryPointPrefix) {
+		return fmt.Errorf("proxy names starting with %q are reserved for TCP proxy config files", constants.TCPEntryPointPrefix)
+	}
+	return nil
+}
+
+// TCPPortInUse reports whether a TCP proxy other than except listens on the
+// tcp-<port> entrypoint.
+func TCPPortInUse(port int, except string) bool {
+	for _, name := range ListNames() {
+		if name == except {
+			continue
+		}
+		if meta, err := Read(name); err == nil && meta != nil && meta.TCP && meta.EntryPointPort == port {
+			return true
+		}
+	}
+	return false
+}
+
 // Reload re-renders the routes-<name>.yml Traefik file for a proxy from its
 // metadata.Routes. Called after `srv route add/remove` to push the routes
 // out without touching the base proxy-<name>.yml Traefik config. Returns
@@ -186,9 +241,21 @@ func Reload(name string) error {
 			InsecureSkipVerify: r.Upstream.InsecureSkipVerify,
 		})
 	}
+	disabled := IsDisabled(cfg, name)
 	if err := traefik.WriteRoutesConfig(cfg, set); err != nil {
 		return err
 	}
+	if disabled {
+		// Keep a disabled proxy's routes parked with the rest of its config.
+		path := traefik.RoutesConfigPath(cfg, name)
+		if err := os.Remove(path + constants.ExtDisabled); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err := os.Rename(path, path+constants.ExtDisabled); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
 	return traefik.UpdateDynamicConfig()
 }
 
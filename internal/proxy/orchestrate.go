@@ -60,6 +60,9 @@ func Add(cfg *config.Config, spec AddSpec) (*AddResult, error) {
 			return nil, fmt.Errorf("proxy %q already exists (set force to overwrite)", name)
 		}
 	}
+	if IsDisabled(cfg, name) {
+		return nil, fmt.Errorf("proxy %q exists but is disabled (enable or remove it first)", name)
+	}
 
 	if _, err := traefik.EnsureResourceCert(certSiteName(name), spec.Domain, spec.Wildcard); err != nil {
 		return nil, err
@@ -123,15 +126,28 @@ func RemoveProxy(cfg *config.Config, name string) (warnings []string, err error)
 	// Domain is needed to remove the matching cert + DNS registration. Prefer
 	// the metadata sidecar; it is the canonical record of the proxy's domain.
 	var domain string
-	if pmeta, _ := Read(name); pmeta != nil && len(pmeta.Domains) > 0 {
+	pmeta, _ := Read(name)
+	if pmeta != nil && len(pmeta.Domains) > 0 {
 		domain = pmeta.Domains[0]
 	}
+	tcpPort := 0
+	if pmeta != nil && pmeta.TCP {
+		proxyFile = traefik.TCPProxyConfigPath(cfg, name)
+		tcpPort = pmeta.EntryPointPort
+	}
 
-	if rmErr := os.Remove(proxyFile); rmErr != nil {
-		if os.IsNotExist(rmErr) {
-			return nil, fmt.Errorf("proxy %q not found", name)
+	// A disabled proxy's config sits under *.disabled; remove whichever
+	// copies exist.
+	removed := false
+	for _, path := range []string{proxyFile, proxyFile + constants.ExtDisabled} {
+		if rmErr := os.Remove(path); rmErr == nil {
+			removed = true
+		} else if !os.IsNotExist(rmErr) {
+			return nil, fmt.Errorf("remove proxy config: %w", rmErr)
 		}
-		return nil, fmt.Errorf("remove proxy config: %w", rmErr)
+	}
+	if !removed {
+		return nil, fmt.Errorf("proxy %q not found", name)
 	}
 
 	if domain != "" {
@@ -145,9 +161,22 @@ func RemoveProxy(cfg *config.Config, name string) (warnings []string, err error)
 	if err := traefik.RemoveRoutesConfig(cfg, name); err != nil {
 		warnings = append(warnings, fmt.Sprintf("remove routes config: %v", err))
 	}
+	if err := os.Remove(traefik.RoutesConfigPath(cfg, name) + constants.ExtDisabled); err != nil && !os.IsNotExist(err) {
+		warnings = append(warnings, fmt.Sprintf("remove disabled routes config: %v", err))
+	}
 	if err := Remove(name); err != nil {
 		warnings = append(warnings, fmt.Sprintf("remove proxy metadata: %v", err))
 	}
+	// Drop the TCP entrypoint once no other TCP proxy listens on it.
+	if tcpPort != 0 && !TCPPortInUse(tcpPort, name) {
+		if changed, err := traefik.SetTCPEntryPoint(tcpPort, false); err != nil {
+			warnings = append(warnings, fmt.Sprintf("remove TCP entrypoint: %v", err))
+		} else if changed {
+			if err := traefik.ApplyTCPEntryPoints(); err != nil {
+				warnings = append(warnings, fmt.Sprintf("recreate Traefik: %v", err))
+			}
+		}
+	}
 	if err := traefik.UpdateDynamicConfig(); err != nil {
 		warnings = append(warnings, fmt.Sprintf("update Traefik config: %v", err))
 	}
@@ -189,6 +218,9 @@ func validateAddSpec(spec AddSpec) (name, containerName, containerPort string, i
 	if err := validate.ProxyName(name); err != nil {
 		return "", "", "", false, fmt.Errorf("invalid proxy name: %w", err)
 	}
+	if err := CheckHTTPName(name); err != nil {
+		return "", "", "", false, err
+	}
 	return name, containerName, containerPort, isContainer, nil
 }
 
@@ -0,0 +1,44 @@
+// Package proxy — view.go defines ProxyView, the stable JSON shape of a proxy
+// that `srv proxy list --json` prints. Field names are part of srv's
+// scripting interface: add fields, but don't rename or remove them.
+package proxy
+
+// ProxyView is the machine-readable form of a `srv proxy` entry.
+type ProxyView struct {
+	// Name is the proxy's name.
+	Name string `json:"name"`
+	// Domain is the hostname the proxy answers on.
+	Domain string `json:"domain"`
+	// Target is the primary upstream URL.
+	Target string `json:"target"`
+	// Type is "localhost" or "container".
+	Type string `json:"type"`
+	// Container is the upstream container for container proxies.
+	Container string `json:"container,omitempty"`
+	// Backends lists the load-balanced upstreams after Target.
+	Backends []string `json:"backends,omitempty"`
+	// PathPrefix limits the proxy to requests under this path.
+	PathPrefix string `json:"path_prefix,omitempty"`
+	// StripPrefix is true when PathPrefix is removed before forwarding.
+	StripPrefix bool `json:"strip_prefix,omitempty"`
+	// Timeout, ReadTimeout and DialTimeout are the --timeout,
+	// --read-timeout and --dial-timeout overrides (Go durations).
+	Timeout     string `json:"timeout,omitempty"`
+	ReadTimeout string `json:"read_timeout,omitempty"`
+	DialTimeout string `json:"dial_timeout,omitempty"`
+	// WebSocket is true when upgrade headers are injected.
+	WebSocket bool `json:"websocket,omitempty"`
+	// GRPC is the gRPC upstream mode, "h2c" or "tls"; empty for HTTP.
+	GRPC string `json:"grpc,omitempty"`
+	// TCP is true for TCP proxies, which listen on EntryPointPort and route
+	// by TLS SNI; Passthrough is true when TLS is left to the upstream.
+	TCP            bool `json:"tcp,omitempty"`
+	EntryPointPort int  `json:"entrypoint_port,omitempty"`
+	Passthrough    bool `json:"passthrough,omitempty"`
+	// SSL is the local certificate status ("valid", "expiring", "expired",
+	// "missing", "corrupt").
+	SSL string `json:"ssl"`
+	// Status is "disabled" for a disabled proxy, else "active" while Traefik
+	// is running and "inactive" otherwise.
+	Status string `json:"status"`
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/stubbedev/srv/internal/config"
 	"github.com/stubbedev/srv/internal/constants"
 	"github.com/stubbedev/srv/internal/docker"
+	"github.com/stubbedev/srv/internal/preset"
 	"github.com/stubbedev/srv/internal/traefik"
 	"github.com/stubbedev/srv/internal/validate"
 )
@@ -22,21 +23,48 @@ import (
 type AddOptions struct {
 	Path         string   // project path (resolved against cwd / parked roots)
 	TypeOverride string   // "", "compose", "dockerfile", or "static"
-	Name         string   // site name; derived from Domain when empty
+	Name         string   // site name; derived from Domain when empty, made unique when AutoName
 	Domain       string   // canonical hostname (required)
 	Aliases      []string // extra hostnames
 	Port         int      // container port; 0 → DefaultContainerPort
 	Local        bool     // local mkcert TLS (otherwise Let's Encrypt)
+	Staging      bool     // Let's Encrypt staging CA (non-local only)
 	Wildcard     bool     // match one-level subdomains (local only)
 	InternalHTTP bool     // also expose on the internal plain-HTTP entrypoint
 	Service      string   // compose service selector (compose sites)
 	Profile      string   // compose profile selector
+	Override     string   // compose override file layered over the compose file
 	SPA          bool     // static-site options
 	Cache        bool
 	CORS         bool
-	Volumes      []VolumeMount // extra bind-mounts
-	Force        bool          // overwrite an existing site
-	Start        bool          // bring containers up after adding
+	Brotli       bool
+	// DirectoryListing enables nginx autoindex; mutually exclusive with SPA.
+	DirectoryListing bool
+	Volumes          []VolumeMount     // extra bind-mounts
+	Middlewares      []string          // custom Traefik middlewares for the site's router
+	WebSocket        bool              // WebSocket upgrade headers + long idle timeout (compose sites)
+	HealthCheck      string            // path Traefik probes on the container (compose sites)
+	HealthInterval   string            // probe interval, a Go duration; empty means 10s
+	AllowIPs         []string          // client CIDRs allowed to reach the site
+	AddHeaders       map[string]string // response headers to set
+	RemoveHeaders    []string          // response headers to strip
+	RateLimit        string            // per-client rate limit, AVERAGE-UNIT (e.g. 100-S)
+	PathPrefix       string            // only route requests under this path (e.g. /api)
+	StripPrefix      bool              // remove PathPrefix before forwarding
+	RetryAttempts    int               // retry requests that fail to reach the container; 0 disables
+	RetryInterval    string            // initial retry back-off, a Go duration; empty means Traefik's default
+	Compress         bool              // compress responses at Traefik
+	MaxRequestBody   string            // request body size limit, e.g. 10MB
+	MaxResponseBody  string            // response body size limit, e.g. 50MB
+	Preset           string            // middleware preset merged into the options above
+	HTTPOnly         bool              // serve plain HTTP on the web entrypoint only, no TLS
+	NoRedirect       bool              // serve both HTTP and HTTPS instead of redirecting HTTP
+	PreStart         []string          // shell commands run from the project dir before start
+	PostStart        []string          // shell commands run from the project dir after start
+	NginxExtra       string            // nginx snippet file for static sites
+	ErrorPages       string            // custom error pages dir for static sites
+	Force            bool              // overwrite an existing site
+	Start            bool              // bring containers up after adding
 }
 
 // AddResult reports what Add produced.
@@ -56,6 +84,7 @@ type addSetup struct {
 	serviceName        string
 	composeServiceName string
 	profile            string
+	overridePath       string
 	siteName           string
 	domain             string
 	aliases            []string
@@ -64,6 +93,8 @@ type addSetup struct {
 	isStatic           bool
 	isDockerfile       bool
 	dockerfileInfo     *DockerfileSiteInfo
+	nginxExtra         string
+	errorPages         string
 }
 
 func (s *addSetup) allDomains() []string {
@@ -119,6 +150,30 @@ func Add(opts AddOptions) (*AddResult, error) {
 	return res, nil
 }
 
+// applyPreset merges the middleware preset opts.Preset into opts.
+func applyPreset(opts *AddOptions) error {
+	p, err := preset.Read(opts.Preset)
+	if err != nil {
+		return err
+	}
+	settings := preset.Settings{
+		Compress:      opts.Compress,
+		RateLimit:     opts.RateLimit,
+		AddHeaders:    opts.AddHeaders,
+		RemoveHeaders: opts.RemoveHeaders,
+		AllowIPs:      opts.AllowIPs,
+		Middlewares:   opts.Middlewares,
+	}
+	p.Apply(&settings)
+	opts.Compress = settings.Compress
+	opts.RateLimit = settings.RateLimit
+	opts.AddHeaders = settings.AddHeaders
+	opts.RemoveHeaders = settings.RemoveHeaders
+	opts.AllowIPs = settings.AllowIPs
+	opts.Middlewares = settings.Middlewares
+	return nil
+}
+
 // resolveAddSetup detects the project type and assembles + validates the config.
 func resolveAddSetup(opts AddOptions) (*addSetup, error) {
 	sitePath, err := ResolvePath(opts.Path)
@@ -129,6 +184,15 @@ func resolveAddSetup(opts AddOptions) (*addSetup, error) {
 		return nil, fmt.Errorf("path does not exist: %s", sitePath)
 	}
 
+	// Only an explicit compress is rejected for static sites; one merged in
+	// from a preset is dropped, since nginx already compresses their files.
+	compressRequested := opts.Compress
+	if opts.Preset != "" {
+		if err := applyPreset(&opts); err != nil {
+			return nil, err
+		}
+	}
+
 	port := opts.Port
 	if port == 0 {
 		port = constants.DefaultContainerPort
@@ -139,12 +203,35 @@ func resolveAddSetup(opts AddOptions) (*addSetup, error) {
 		return nil, err
 	}
 
+	if s.isStatic && s.opts.Compress {
+		if compressRequested {
+			return nil, fmt.Errorf("compress doesn't apply to static sites: nginx already gzips their files (set brotli to add brotli)")
+		}
+		s.opts.Compress = false
+	}
+
 	// Compose sites need a service selected (and possibly a profile).
 	if !s.isStatic && !s.isDockerfile {
 		if err := selectComposeService(s, opts.Service, opts.Profile); err != nil {
 			return nil, err
 		}
 	}
+	if opts.Override != "" {
+		if s.isStatic || s.isDockerfile {
+			return nil, fmt.Errorf("override files only apply to compose sites")
+		}
+		overridePath, err := ResolvePath(opts.Override)
+		if err != nil {
+			return nil, fmt.Errorf("invalid override file path: %w", err)
+		}
+		if _, err := os.Stat(overridePath); err != nil {
+			return nil, fmt.Errorf("override file does not exist: %s", overridePath)
+		}
+		if _, err := ParseComposeFile(overridePath); err != nil {
+			return nil, fmt.Errorf("invalid override file: %w", err)
+		}
+		s.overridePath = overridePath
+	}
 
 	if opts.Domain == "" {
 		return nil, fmt.Errorf("domain is required")
@@ -155,8 +242,11 @@ func resolveAddSetup(opts AddOptions) (*addSetup, error) {
 	s.domain = opts.Domain
 
 	s.siteName = opts.Name
-	if s.siteName == "" {
+	switch s.siteName {
+	case "":
 		s.siteName = SanitizeName(opts.Domain)
+	case AutoName:
+		s.siteName = UniqueName(SanitizeName(opts.Domain))
 	}
 	if err := validate.SiteName(s.siteName); err != nil {
 		return nil, err
@@ -165,6 +255,9 @@ func resolveAddSetup(opts AddOptions) (*addSetup, error) {
 		return nil, fmt.Errorf("site %q already exists (set force to overwrite)", s.siteName)
 	}
 
+	if opts.Staging && opts.Local {
+		return nil, fmt.Errorf("staging only applies to Let's Encrypt sites, not local ones")
+	}
 	if opts.Wildcard && !opts.Local {
 		return nil, fmt.Errorf("wildcard requires local (Let's Encrypt cannot issue local wildcard certs)")
 	}
@@ -180,6 +273,86 @@ func resolveAddSetup(opts AddOptions) (*addSetup, error) {
 	if err := validate.Port(s.port); err != nil {
 		return nil, err
 	}
+	if err := validate.Middlewares(opts.Middlewares); err != nil {
+		return nil, err
+	}
+	if err := validate.CIDRs(opts.AllowIPs); err != nil {
+		return nil, err
+	}
+	if err := validate.ResponseHeaders(opts.AddHeaders, opts.RemoveHeaders); err != nil {
+		return nil, err
+	}
+	for _, size := range []string{opts.MaxRequestBody, opts.MaxResponseBody} {
+		if size == "" {
+			continue
+		}
+		if _, err := traefik.ParseBodySize(size); err != nil {
+			return nil, err
+		}
+	}
+	if opts.RateLimit != "" {
+		if _, _, err := traefik.ParseRateLimit(opts.RateLimit); err != nil {
+			return nil, err
+		}
+	}
+	if opts.PathPrefix != "" {
+		prefix, err := validate.PathPrefix(opts.PathPrefix)
+		if err != nil {
+			return nil, err
+		}
+		s.opts.PathPrefix = prefix
+	} else if opts.StripPrefix {
+		return nil, fmt.Errorf("strip prefix requires a path prefix")
+	}
+	if opts.HTTPOnly && opts.NoRedirect {
+		return nil, fmt.Errorf("http-only and no-redirect are mutually exclusive")
+	}
+	if opts.SPA && opts.DirectoryListing {
+		return nil, fmt.Errorf("spa and directory listing are mutually exclusive")
+	}
+	if opts.NginxExtra != "" {
+		if !s.isStatic {
+			return nil, fmt.Errorf("nginx snippets only apply to static sites")
+		}
+		extraPath, err := ResolvePath(opts.NginxExtra)
+		if err != nil {
+			return nil, fmt.Errorf("invalid nginx snippet path: %w", err)
+		}
+		if _, err := ReadNginxExtra(extraPath); err != nil {
+			return nil, err
+		}
+		s.nginxExtra = extraPath
+	}
+	if opts.ErrorPages != "" {
+		if !s.isStatic {
+			return nil, fmt.Errorf("error pages only apply to static sites")
+		}
+		dir, err := ResolvePath(opts.ErrorPages)
+		if err != nil {
+			return nil, fmt.Errorf("invalid error pages path: %w", err)
+		}
+		if err := ValidateErrorPages(dir); err != nil {
+			return nil, err
+		}
+		s.errorPages = dir
+	}
+	if opts.WebSocket && (s.isStatic || s.isDockerfile) {
+		return nil, fmt.Errorf("websocket only applies to compose sites")
+	}
+	if err := validateRetry(opts.RetryAttempts, opts.RetryInterval); err != nil {
+		return nil, err
+	}
+	if err := validateHealthCheck(opts.HealthCheck, opts.HealthInterval); err != nil {
+		return nil, err
+	}
+	if opts.HealthCheck != "" && (s.isStatic || s.isDockerfile) {
+		return nil, fmt.Errorf("healthcheck only applies to compose sites")
+	}
+	for _, h := range append(append([]string(nil), opts.PreStart...), opts.PostStart...) {
+		if strings.TrimSpace(h) == "" {
+			return nil, fmt.Errorf("start hooks cannot be empty commands")
+		}
+	}
 	return s, nil
 }
 
@@ -313,21 +486,49 @@ func writeAddFiles(cfg *config.Config, s *addSetup) error {
 	}
 
 	meta := SiteMetadata{
-		Type:               siteType,
-		Domains:            s.allDomains(),
-		ProjectPath:        s.sitePath,
-		ServiceName:        s.serviceName,
-		ComposeServiceName: s.composeServiceName,
-		Profile:            s.profile,
-		Port:               port,
-		IsLocal:            s.opts.Local,
-		Wildcard:           s.opts.Wildcard,
-		NetworkName:        cfg.NetworkName,
-		Listeners:          s.listeners,
-		SPA:                s.opts.SPA,
-		Cache:              s.opts.Cache,
-		CORS:               s.opts.CORS,
-		Volumes:            s.opts.Volumes,
+		Type:                siteType,
+		Domains:             s.allDomains(),
+		ProjectPath:         s.sitePath,
+		ServiceName:         s.serviceName,
+		ComposeServiceName:  s.composeServiceName,
+		Profile:             s.profile,
+		OverridePath:        s.overridePath,
+		Port:                port,
+		IsLocal:             s.opts.Local,
+		Staging:             s.opts.Staging,
+		Wildcard:            s.opts.Wildcard,
+		NetworkName:         cfg.NetworkName,
+		Listeners:           s.listeners,
+		SPA:                 s.opts.SPA,
+		Cache:               s.opts.Cache,
+		CORS:                s.opts.CORS,
+		Brotli:              s.opts.Brotli,
+		DirectoryListing:    s.opts.DirectoryListing,
+		Volumes:             s.opts.Volumes,
+		Middlewares:         s.opts.Middlewares,
+		WebSocket:           s.opts.WebSocket,
+		HealthCheckPath:     s.opts.HealthCheck,
+		HealthCheckInterval: s.opts.HealthInterval,
+		AllowIPs:            s.opts.AllowIPs,
+		AddHeaders:          s.opts.AddHeaders,
+		RemoveHeaders:       s.opts.RemoveHeaders,
+		RateLimit:           s.opts.RateLimit,
+		PathPrefix:          s.opts.PathPrefix,
+		StripPrefix:         s.opts.StripPrefix,
+		RetryAttempts:       s.opts.RetryAttempts,
+		RetryInterval:       s.opts.RetryInterval,
+		Compress:            s.opts.Compress,
+		MaxRequestBodySize:  s.opts.MaxRequestBody,
+		MaxResponseBodySize: s.opts.MaxResponseBody,
+		Preset:              s.opts.Preset,
+		NoHTTPSRedirect:     s.opts.NoRedirect,
+		PreStart:            s.opts.PreStart,
+		PostStart:           s.opts.PostStart,
+		NginxExtra:          s.nginxExtra,
+		ErrorPagesPath:      s.errorPages,
+	}
+	if s.opts.HTTPOnly {
+		meta.EntryPoints = []string{constants.EntryPointWeb}
 	}
 	if s.isDockerfile && s.dockerfileInfo != nil {
 		meta.DockerfilePort = s.dockerfileInfo.Port
@@ -348,15 +549,7 @@ func writeAddFiles(cfg *config.Config, s *addSetup) error {
 			return fmt.Errorf("write static site config: %w", err)
 		}
 	default:
-		if err := traefik.WriteSiteRouteConfig(cfg, traefik.SiteRouteConfig{
-			Name:        s.siteName,
-			Domains:     s.allDomains(),
-			ServiceName: s.serviceName,
-			Port:        s.port,
-			IsLocal:     s.opts.Local,
-			Wildcard:    s.opts.Wildcard,
-			Listeners:   meta.Listeners,
-		}); err != nil {
+		if err := traefik.WriteSiteRouteConfig(cfg, routeConfigFromMeta(s.siteName, &meta)); err != nil {
 			return fmt.Errorf("write traefik config: %w", err)
 		}
 	}
@@ -400,7 +593,24 @@ func startAfterAdd(cfg *config.Config, s *addSetup) (warnings []string) {
 	if s.isStatic || s.isDockerfile {
 		composeDir = SiteConfigDir(cfg, s.siteName)
 	}
-	if err := docker.ComposeUpWithProfile(composeDir, s.profile); err != nil {
+	if err := runHooks("pre-start", s.sitePath, s.opts.PreStart); err != nil {
+		return append(warnings, fmt.Sprintf("site not started: %v", err))
+	}
+	started := &Site{
+		Name:               s.siteName,
+		Type:               SiteTypeCompose,
+		ComposeDir:         composeDir,
+		ComposeServiceName: s.composeServiceName,
+		Profile:            s.profile,
+		OverridePath:       s.overridePath,
+	}
+	switch {
+	case s.isStatic:
+		started.Type = SiteTypeStatic
+	case s.isDockerfile:
+		started.Type = SiteTypeDockerfile
+	}
+	if err := ComposeUp(started, false); err != nil {
 		return append(warnings, fmt.Sprintf("start site: %v", err))
 	}
 	if !s.isStatic && !s.isDockerfile && s.composeServiceName != "" {
@@ -408,6 +618,9 @@ func startAfterAdd(cfg *config.Config, s *addSetup) (warnings []string) {
 			warnings = append(warnings, fmt.Sprintf("connect service to traefik network: %v", err))
 		}
 	}
+	if err := runHooks("post-start", s.sitePath, s.opts.PostStart); err != nil {
+		warnings = append(warnings, err.Error())
+	}
 	return warnings
 }
 
@@ -4,6 +4,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/stubbedev/srv/internal/preset"
 )
 
 func TestNormalizeAddAliases(t *testing.T) {
@@ -90,6 +92,41 @@ func TestResolveAddSetupValidation(t *testing.T) {
 	if _, err := resolveAddSetup(AddOptions{Path: "/no/such/dir/srv-test", Domain: "x.test"}); err == nil {
 		t.Error("expected error for missing path")
 	}
+	// Negative: staging on a local site.
+	if _, err := resolveAddSetup(AddOptions{Path: dir, Domain: "x.test", Local: true, Staging: true}); err == nil {
+		t.Error("expected error for staging with local")
+	}
+	// Negative: SPA fallback and directory listing together.
+	if _, err := resolveAddSetup(AddOptions{Path: dir, Domain: "x.test", SPA: true, DirectoryListing: true}); err == nil {
+		t.Error("expected error for spa with directory listing")
+	}
+	// Negative: websocket on a static site.
+	if _, err := resolveAddSetup(AddOptions{Path: dir, Domain: "x.test", WebSocket: true}); err == nil {
+		t.Error("expected error for websocket on a static site")
+	}
+	// Negative: health check on a static site.
+	if _, err := resolveAddSetup(AddOptions{Path: dir, Domain: "x.test", HealthCheck: "/health"}); err == nil {
+		t.Error("expected error for a health check on a static site")
+	}
+	// Negative: body size out of range.
+	if _, err := resolveAddSetup(AddOptions{Path: dir, Domain: "x.test", MaxRequestBody: "20GB"}); err == nil {
+		t.Error("expected error for a request body limit over 10GB")
+	}
+	// Negative: Traefik compression on a static site (nginx compresses).
+	if _, err := resolveAddSetup(AddOptions{Path: dir, Domain: "x.test", Compress: true}); err == nil {
+		t.Error("expected error for compress on a static site")
+	}
+	// Negative: path prefix without a leading slash, strip without a prefix.
+	if _, err := resolveAddSetup(AddOptions{Path: dir, Domain: "x.test", PathPrefix: "api"}); err == nil {
+		t.Error("expected error for a path prefix without a leading /")
+	}
+	if _, err := resolveAddSetup(AddOptions{Path: dir, Domain: "x.test", StripPrefix: true}); err == nil {
+		t.Error("expected error for strip prefix without a path prefix")
+	}
+	// Negative: blank start hook.
+	if _, err := resolveAddSetup(AddOptions{Path: dir, Domain: "x.test", PostStart: []string{"  "}}); err == nil {
+		t.Error("expected error for an empty post-start hook")
+	}
 
 	// Positive: static site, name derived from domain.
 	s, err := resolveAddSetup(AddOptions{Path: dir, Domain: "app.test", Local: true})
@@ -100,3 +137,103 @@ func TestResolveAddSetupValidation(t *testing.T) {
 		t.Errorf("setup = name:%q static:%v", s.siteName, s.isStatic)
 	}
 }
+
+func TestResolveAddSetupPresetCompressStatic(t *testing.T) {
+	withSRVRoot(t)
+	if err := preset.Write(preset.Preset{Name: "fast", Compress: true}, false); err != nil {
+		t.Fatal(err)
+	}
+	s, err := resolveAddSetup(AddOptions{Path: t.TempDir(), Domain: "app.test", Local: true, Preset: "fast"})
+	if err != nil {
+		t.Fatalf("preset compress should be dropped for a static site: %v", err)
+	}
+	if s.opts.Compress {
+		t.Error("static site kept the preset's Traefik compression")
+	}
+}
+
+func TestValidateHealthCheck(t *testing.T) {
+	cases := []struct {
+		path, interval string
+		ok             bool
+	}{
+		{"", "", true},
+		{"/health", "", true},
+		{"/health", "30s", true},
+		{"health", "", false},
+		{"", "30s", false},
+		{"/health", "often", false},
+		{"/health", "0s", false},
+	}
+	for _, tc := range cases {
+		if err := validateHealthCheck(tc.path, tc.interval); (err == nil) != tc.ok {
+			t.Errorf("validateHealthCheck(%q, %q) = %v, want ok=%v", tc.path, tc.interval, err, tc.ok)
+		}
+	}
+}
+
+func TestValidateRetry(t *testing.T) {
+	cases := []struct {
+		attempts int
+		interval string
+		ok       bool
+	}{
+		{0, "", true},
+		{1, "", true},
+		{10, "250ms", true},
+		{11, "", false},
+		{-1, "", false},
+		{0, "250ms", false},
+		{3, "soon", false},
+		{3, "0s", false},
+	}
+	for _, tc := range cases {
+		if err := validateRetry(tc.attempts, tc.interval); (err == nil) != tc.ok {
+			t.Errorf("validateRetry(%d, %q) = %v, want ok=%v", tc.attempts, tc.interval, err, tc.ok)
+		}
+	}
+}
+
+func TestResolveAddSetupOverride(t *testing.T) {
+	withSRVRoot(t)
+	project := t.TempDir()
+	writeFiles(t, project, map[string]string{
+		"docker-compose.yml": "services:\n  web:\n    image: nginx\n",
+		"compose.dev.yml":    "services:\n  web:\n    ports: [\"8080:80\"]\n",
+		"broken.yml":         "services: [\n",
+	})
+
+	s, err := resolveAddSetup(AddOptions{Path: project, Domain: "app.test", Override: filepath.Join(project, "compose.dev.yml")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.overridePath != filepath.Join(project, "compose.dev.yml") {
+		t.Errorf("overridePath = %q", s.overridePath)
+	}
+	for name, override := range map[string]string{
+		"missing":  filepath.Join(project, "nope.yml"),
+		"unparsed": filepath.Join(project, "broken.yml"),
+	} {
+		if _, err := resolveAddSetup(AddOptions{Path: project, Domain: "app.test", Override: override}); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if _, err := resolveAddSetup(AddOptions{Path: t.TempDir(), Domain: "docs.test", Override: filepath.Join(project, "compose.dev.yml")}); err == nil {
+		t.Error("expected an error for an override on a static site")
+	}
+}
+
+func TestResolveAddSetupAutoName(t *testing.T) {
+	withSRVRoot(t)
+	dir := t.TempDir()
+	if err := WriteSiteMetadata("app-test", SiteMetadata{Type: SiteTypeStatic, Domains: []string{"app.test"}, ProjectPath: dir, Port: 80, NetworkName: "n"}); err != nil {
+		t.Fatal(err)
+	}
+	s, err := resolveAddSetup(AddOptions{Path: dir, Domain: "app.test", Local: true, Name: AutoName})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.siteName != "app-test-2" {
+		t.Errorf("siteName = %q, want app-test-2", s.siteName)
+	}
+}
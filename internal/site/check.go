@@ -0,0 +1,174 @@
+// Package site — check.go backs `srv validate`: it inspects a site's on-disk
+// configuration (metadata, compose file, generated Traefik and nginx configs)
+// without starting anything, and reports each problem with its file and line.
+package site
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/constants"
+	"github.com/stubbedev/srv/internal/docker"
+	"github.com/stubbedev/srv/internal/traefik"
+	"github.com/stubbedev/srv/internal/validate"
+)
+
+// ConfigIssue is one problem found by CheckSiteConfig. Line is 1-based, or 0
+// when the problem can't be pinned to a line.
+type ConfigIssue struct {
+	File    string `json:"file"`
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+// String renders the issue as file:line: message.
+func (i ConfigIssue) String() string {
+	if i.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", i.File, i.Line, i.Message)
+	}
+	return fmt.Sprintf("%s: %s", i.File, i.Message)
+}
+
+// nginxTest is the seam behind the `nginx -t` check; tests swap it to avoid
+// depending on a host nginx.
+var nginxTest = defaultNginxTest
+
+// nginxErrLocRegex finds the "in FILE:LINE" nginx appends to config errors.
+var nginxErrLocRegex = regexp.MustCompile(` in (\S+):(\d+)`)
+
+// defaultNginxTest runs `nginx -t` on a static site's server block, wrapped in
+// a minimal main config so it parses standalone. Returns nil without checking
+// when nginx isn't installed.
+func defaultNginxTest(confPath string) error {
+	bin, err := exec.LookPath("nginx")
+	if err != nil {
+		return nil
+	}
+	tmp, err := os.MkdirTemp("", "srv-nginx-check-")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+	wrapper := filepath.Join(tmp, constants.NginxConfFile)
+	wrapperConf := fmt.Sprintf("pid %s;\nevents {}\nhttp {\n    include %s;\n}\n", filepath.Join(tmp, "nginx.pid"), confPath)
+	if err := os.WriteFile(wrapper, []byte(wrapperConf), constants.FilePermDefault); err != nil {
+		return err
+	}
+	out, err := exec.Command(bin, "-t", "-q", "-e", "stderr", "-p", tmp, "-c", wrapper).CombinedOutput() //nolint:gosec
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%s", msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// CheckSiteConfig validates a site's configuration without starting anything:
+// metadata.yml, the compose file (YAML syntax, then `docker compose config`),
+// any generated Traefik dynamic configs, and a static site's nginx.conf
+// (`nginx -t`). Checks that need a missing tool are skipped. Returns an error
+// only when the site doesn't exist.
+func CheckSiteConfig(name string) ([]ConfigIssue, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	metaFile := metadataPath(cfg, name)
+	if _, err := os.Stat(metaFile); err != nil {
+		return nil, fmt.Errorf("site %q not found", name)
+	}
+	meta, err := ReadSiteMetadata(name)
+	if err != nil {
+		line, msg := validate.YAMLError(err)
+		return []ConfigIssue{{File: metaFile, Line: line, Message: msg}}, nil
+	}
+
+	var issues []ConfigIssue
+	if err := ValidateMetadata(meta); err != nil {
+		issues = append(issues, ConfigIssue{File: metaFile, Message: err.Error()})
+	}
+	issues = append(issues, checkComposeFile(cfg, name, meta)...)
+
+	traefikFiles := []string{traefik.RoutesConfigPath(cfg, name)}
+	if meta.Type == SiteTypeCompose {
+		traefikFiles = append([]string{traefik.SiteRouteConfigPath(cfg, name)}, traefikFiles...)
+	}
+	for _, path := range traefikFiles {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue // not generated yet (or no extra routes)
+		}
+		for _, e := range traefik.ValidateDynConfig(data) {
+			issues = append(issues, ConfigIssue{File: path, Line: e.Line, Message: e.Msg})
+		}
+	}
+
+	// Hosts rarely have the brotli module, so a brotli config would fail
+	// `nginx -t` for reasons unrelated to the site.
+	if meta.Type == SiteTypeStatic && !meta.Brotli {
+		confPath := filepath.Join(SiteConfigDir(cfg, name), constants.NginxConfFile)
+		if _, err := os.Stat(confPath); err == nil {
+			if err := nginxTest(confPath); err != nil {
+				issues = append(issues, nginxIssue(confPath, err))
+			}
+		}
+	}
+	return issues, nil
+}
+
+// checkComposeFile parses the site's compose file for YAML errors, then asks
+// docker compose to resolve it. srv-managed sites keep theirs in the site
+// config dir; compose sites use the project's own file.
+func checkComposeFile(cfg *config.Config, name string, meta *SiteMetadata) []ConfigIssue {
+	var path string
+	if meta.Type == SiteTypeCompose {
+		found, err := FindComposeFile(meta.ProjectPath)
+		if err != nil {
+			return []ConfigIssue{{File: meta.ProjectPath, Message: err.Error()}}
+		}
+		path = found
+	} else {
+		path = filepath.Join(SiteConfigDir(cfg, name), constants.DockerComposeFile)
+		if _, err := os.Stat(path); err != nil {
+			return nil // not generated until the first reload
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []ConfigIssue{{File: path, Message: err.Error()}}
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		line, msg := validate.YAMLError(err)
+		return []ConfigIssue{{File: path, Line: line, Message: msg}}
+	}
+	if err := docker.ComposeConfigCheck(path); err != nil {
+		line, msg := validate.YAMLError(err)
+		return []ConfigIssue{{File: path, Line: line, Message: msg}}
+	}
+	return nil
+}
+
+// nginxIssue turns `nginx -t` output into a ConfigIssue, keeping the first
+// error line and its location when nginx reports one.
+func nginxIssue(confPath string, err error) ConfigIssue {
+	msg := strings.SplitN(err.Error(), "\n", 2)[0]
+	issue := ConfigIssue{File: confPath, Message: msg}
+	if m := nginxErrLocRegex.FindStringSubmatch(msg); m != nil {
+		issue.File = m[1]
+		issue.Line, _ = strconv.Atoi(m[2])
+		issue.Message = strings.TrimSpace(strings.Replace(msg, m[0], "", 1))
+	}
+	issue.Message = strings.TrimPrefix(issue.Message, "nginx: ")
+	return issue
+}
@@ -0,0 +1,118 @@
+package site
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/docker"
+	"github.com/stubbedev/srv/internal/traefik"
+)
+
+func TestCheckSiteConfigComposeSite(t *testing.T) {
+	withSRVRoot(t)
+	t.Cleanup(docker.SwapComposeConfigCheck(func(string) error { return nil }))
+	cfg, _ := config.Load()
+	project := t.TempDir()
+	compose := filepath.Join(project, "docker-compose.yml")
+	if err := os.WriteFile(compose, []byte("services:\n  web:\n    image: nginx\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	meta := SiteMetadata{Type: SiteTypeCompose, Domains: []string{"app.test"}, ProjectPath: project, ServiceName: "web", Port: 80, NetworkName: "n"}
+	if err := WriteSiteMetadata("app", meta); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(cfg.TraefikConfDir(), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := traefik.WriteSiteRouteConfig(cfg, traefik.SiteRouteConfig{Name: "app", Domains: meta.Domains, ServiceName: "web", Port: 80, IsLocal: true}); err != nil {
+		t.Fatal(err)
+	}
+	issues, err := CheckSiteConfig("app")
+	if err != nil || len(issues) != 0 {
+		t.Fatalf("clean site: issues %v, err %v", issues, err)
+	}
+
+	// Broken YAML in the compose file is reported with its line.
+	if err := os.WriteFile(compose, []byte("services:\n  web:\n    image: nginx\n   bad: [\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	issues, _ = CheckSiteConfig("app")
+	if len(issues) != 1 || issues[0].File != compose || issues[0].Line == 0 {
+		t.Errorf("compose syntax: %v", issues)
+	}
+
+	// docker compose config failures are passed through.
+	_ = os.WriteFile(compose, []byte("services:\n  web:\n    image: nginx\n"), 0o644)
+	t.Cleanup(docker.SwapComposeConfigCheck(func(string) error { return errors.New("service \"web\" has neither an image nor a build context") }))
+	issues, _ = CheckSiteConfig("app")
+	if len(issues) != 1 || !strings.Contains(issues[0].Message, "neither an image") {
+		t.Errorf("compose config: %v", issues)
+	}
+}
+
+func TestCheckSiteConfigTraefikFile(t *testing.T) {
+	withSRVRoot(t)
+	t.Cleanup(docker.SwapComposeConfigCheck(func(string) error { return nil }))
+	cfg, _ := config.Load()
+	project := t.TempDir()
+	_ = os.WriteFile(filepath.Join(project, "compose.yml"), []byte("services:\n  web:\n    image: nginx\n"), 0o644)
+	if err := WriteSiteMetadata("app", SiteMetadata{Type: SiteTypeCompose, Domains: []string{"app.test"}, ProjectPath: project, Port: 80, NetworkName: "n"}); err != nil {
+		t.Fatal(err)
+	}
+	_ = os.MkdirAll(cfg.TraefikConfDir(), 0o755)
+	bad := "http:\n  routers:\n    site-app:\n      rule: Host(`app.test`\n      entryPoints: [websecure]\n      service: site-ghost\n  services: {}\n"
+	path := traefik.SiteRouteConfigPath(cfg, "app")
+	if err := os.WriteFile(path, []byte(bad), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	issues, err := CheckSiteConfig("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 2 {
+		t.Fatalf("want rule + service issues, got %v", issues)
+	}
+	for _, issue := range issues {
+		if issue.File != path || issue.Line != 3 {
+			t.Errorf("issue location = %s", issue)
+		}
+	}
+}
+
+func TestCheckSiteConfigStaticNginx(t *testing.T) {
+	withSRVRoot(t)
+	cfg, _ := config.Load()
+	if err := WriteSiteMetadata("docs", SiteMetadata{Type: SiteTypeStatic, Domains: []string{"docs.test"}, ProjectPath: t.TempDir(), Port: 80, NetworkName: "n"}); err != nil {
+		t.Fatal(err)
+	}
+	conf := filepath.Join(SiteConfigDir(cfg, "docs"), "nginx.conf")
+	if err := os.WriteFile(conf, []byte("server {\n  bogus on;\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	prev := nginxTest
+	t.Cleanup(func() { nginxTest = prev })
+	nginxTest = func(string) error {
+		return errors.New(`nginx: [emerg] unknown directive "bogus" in ` + conf + ":2\nnginx: configuration file test failed")
+	}
+	issues, err := CheckSiteConfig("docs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 || issues[0].File != conf || issues[0].Line != 2 || issues[0].Message != `[emerg] unknown directive "bogus"` {
+		t.Errorf("nginx issue = %v", issues)
+	}
+	if got := issues[0].String(); got != conf+`:2: [emerg] unknown directive "bogus"` {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestCheckSiteConfigMissing(t *testing.T) {
+	withSRVRoot(t)
+	if _, err := CheckSiteConfig("ghost"); err == nil {
+		t.Error("expected error for a missing site")
+	}
+}
@@ -0,0 +1,236 @@
+// Package site — depends.go records start-order dependencies between sites
+// (`srv dependency`) and turns them into the order batch start and stop
+// operations run in.
+package site
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/constants"
+)
+
+// AddDependency records that siteName depends on dep, so dep is started
+// before it and stopped after it. Returns changed=false (no error) when the
+// dependency is already recorded; a dependency that would close a cycle is
+// an error.
+func AddDependency(siteName, dep string) (changed bool, err error) {
+	if siteName == dep {
+		return false, fmt.Errorf("site %q can't depend on itself", siteName)
+	}
+	meta, err := requireMeta(siteName)
+	if err != nil {
+		return false, err
+	}
+	if !HasSiteMetadata(dep) {
+		return false, fmt.Errorf("site %q not found", dep)
+	}
+	if slices.Contains(meta.DependsOn, dep) {
+		return false, nil
+	}
+	graph, err := dependencyGraph()
+	if err != nil {
+		return false, err
+	}
+	graph[siteName] = append(graph[siteName], dep)
+	if cycle := findCycle(graph); cycle != nil {
+		return false, fmt.Errorf("dependency cycle: %s", strings.Join(cycle, " -> "))
+	}
+	meta.DependsOn = append(meta.DependsOn, dep)
+	if err := WriteSiteMetadata(siteName, *meta); err != nil {
+		return false, fmt.Errorf("write metadata: %w", err)
+	}
+	return true, nil
+}
+
+// RemoveDependency drops dep from siteName's dependencies. Removing a
+// dependency the site doesn't have is an error.
+func RemoveDependency(siteName, dep string) error {
+	meta, err := requireMeta(siteName)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(meta.DependsOn, dep) {
+		return fmt.Errorf("site %q doesn't depend on %q", siteName, dep)
+	}
+	meta.DependsOn = slices.DeleteFunc(meta.DependsOn, func(d string) bool { return d == dep })
+	if err := WriteSiteMetadata(siteName, *meta); err != nil {
+		return fmt.Errorf("write metadata: %w", err)
+	}
+	return nil
+}
+
+// dependencyGraph reads every site's dependencies from its metadata.
+func dependencyGraph() (map[string][]string, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(cfg.SitesDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	graph := map[string][]string{}
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), "_") {
+			continue
+		}
+		if meta, _ := ReadSiteMetadata(entry.Name()); meta != nil {
+			graph[entry.Name()] = append([]string(nil), meta.DependsOn...)
+		}
+	}
+	return graph, nil
+}
+
+// findCycle returns the sites of a dependency cycle in graph, first site
+// repeated at the end, or nil when there is none.
+func findCycle(graph map[string][]string) []string {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := map[string]int{}
+	var path []string
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		switch state[name] {
+		case visiting:
+			start := slices.Index(path, name)
+			return append(slices.Clone(path[start:]), name)
+		case done:
+			return nil
+		}
+		state[name] = visiting
+		path = append(path, name)
+		for _, dep := range graph[name] {
+			if cycle := visit(dep); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = done
+		return nil
+	}
+
+	names := make([]string, 0, len(graph))
+	for name := range graph {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if cycle := visit(name); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// DependencyLevels groups sites into the order they start in: every site's
+// dependencies among sites sit in an earlier level, so the sites of one
+// level can start in parallel once the previous level is up. Dependencies
+// outside sites are ignored. Sites within a level are sorted by name; a
+// cycle is an error.
+func DependencyLevels(sites []Site) ([][]Site, error) {
+	byName := make(map[string]Site, len(sites))
+	graph := make(map[string][]string, len(sites))
+	for _, s := range sites {
+		byName[s.Name] = s
+	}
+	for _, s := range sites {
+		for _, dep := range s.DependsOn {
+			if _, ok := byName[dep]; ok {
+				graph[s.Name] = append(graph[s.Name], dep)
+			}
+		}
+	}
+	if cycle := findCycle(graph); cycle != nil {
+		return nil, fmt.Errorf("dependency cycle: %s", strings.Join(cycle, " -> "))
+	}
+
+	placed := make(map[string]bool, len(sites))
+	var levels [][]Site
+	for len(placed) < len(byName) {
+		var level []Site
+		for name, s := range byName {
+			if placed[name] {
+				continue
+			}
+			ready := true
+			for _, dep := range graph[name] {
+				if !placed[dep] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				level = append(level, s)
+			}
+		}
+		slices.SortFunc(level, func(a, b Site) int { return strings.Compare(a.Name, b.Name) })
+		for _, s := range level {
+			placed[s.Name] = true
+		}
+		levels = append(levels, level)
+	}
+	return levels, nil
+}
+
+// DependencyClosure returns the names of every site name depends on,
+// directly or through other sites, in start order.
+func DependencyClosure(name string) ([]string, error) {
+	graph, err := dependencyGraph()
+	if err != nil {
+		return nil, err
+	}
+	if cycle := findCycle(graph); cycle != nil {
+		return nil, fmt.Errorf("dependency cycle: %s", strings.Join(cycle, " -> "))
+	}
+	var out []string
+	var visit func(n string)
+	visit = func(n string) {
+		for _, dep := range graph[n] {
+			if dep == name || slices.Contains(out, dep) {
+				continue
+			}
+			visit(dep)
+			out = append(out, dep)
+		}
+	}
+	visit(name)
+	return out, nil
+}
+
+// statusOf reports a site's container status. Tests swap it with
+// SwapStatus.
+var statusOf = siteContainerStatus
+
+// SwapStatus replaces the container status probe WaitRunning polls and
+// returns a restore func. For tests.
+func SwapStatus(fn func(Site) string) func() {
+	prev := statusOf
+	statusOf = fn
+	return func() { statusOf = prev }
+}
+
+// dependencyPollInterval is how often WaitRunning checks a site's status.
+var dependencyPollInterval = 500 * time.Millisecond
+
+// WaitRunning waits up to timeout for s to report running.
+func WaitRunning(s Site, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		status := statusOf(s)
+		if status == constants.StatusRunning {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("site %q is %s after %s", s.Name, status, timeout)
+		}
+		time.Sleep(dependencyPollInterval)
+	}
+}
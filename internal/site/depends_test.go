@@ -0,0 +1,102 @@
+package site
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stubbedev/srv/internal/constants"
+)
+
+func TestAddRemoveDependency(t *testing.T) {
+	withSRVRoot(t)
+	seedSite(t, "db", []string{"db.test"})
+	seedSite(t, "api", []string{"api.test"})
+	seedSite(t, "web", []string{"web.test"})
+
+	if changed, err := AddDependency("api", "db"); err != nil || !changed {
+		t.Fatalf("add api->db: changed=%v err=%v", changed, err)
+	}
+	if changed, err := AddDependency("api", "db"); err != nil || changed {
+		t.Errorf("re-add: changed=%v err=%v, want idempotent", changed, err)
+	}
+	if _, err := AddDependency("web", "api"); err != nil {
+		t.Fatal(err)
+	}
+	if deps, err := DependencyClosure("web"); err != nil || strings.Join(deps, ",") != "db,api" {
+		t.Errorf("DependencyClosure(web) = %v, %v; want [db api]", deps, err)
+	}
+
+	// Negative: cycle, self, missing site.
+	if _, err := AddDependency("db", "web"); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("db->web should close a cycle, got %v", err)
+	}
+	if _, err := AddDependency("db", "db"); err == nil {
+		t.Error("expected error for a self-dependency")
+	}
+	if _, err := AddDependency("db", "ghost"); err == nil {
+		t.Error("expected error for a missing dependency")
+	}
+
+	if err := RemoveDependency("api", "db"); err != nil {
+		t.Fatal(err)
+	}
+	if meta, _ := ReadSiteMetadata("api"); len(meta.DependsOn) != 0 {
+		t.Errorf("stored DependsOn = %v", meta.DependsOn)
+	}
+	if err := RemoveDependency("api", "db"); err == nil {
+		t.Error("expected error removing a dependency the site doesn't have")
+	}
+}
+
+func TestDependencyLevels(t *testing.T) {
+	sites := []Site{
+		{Name: "web", DependsOn: []string{"api"}},
+		{Name: "api", DependsOn: []string{"db", "cache"}},
+		{Name: "db"},
+		{Name: "cache"},
+		{Name: "docs", DependsOn: []string{"elsewhere"}},
+	}
+	levels, err := DependencyLevels(sites)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, level := range levels {
+		var names []string
+		for _, s := range level {
+			names = append(names, s.Name)
+		}
+		got = append(got, strings.Join(names, ","))
+	}
+	if want := "cache,db,docs|api|web"; strings.Join(got, "|") != want {
+		t.Errorf("levels = %s, want %s", strings.Join(got, "|"), want)
+	}
+
+	sites[2].DependsOn = []string{"web"}
+	if _, err := DependencyLevels(sites); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("expected a cycle error, got %v", err)
+	}
+}
+
+func TestWaitRunning(t *testing.T) {
+	defer func(prev time.Duration) { dependencyPollInterval = prev }(dependencyPollInterval)
+	dependencyPollInterval = time.Millisecond
+
+	calls := 0
+	t.Cleanup(SwapStatus(func(Site) string {
+		calls++
+		if calls < 3 {
+			return constants.StatusStopped
+		}
+		return constants.StatusRunning
+	}))
+	if err := WaitRunning(Site{Name: "db"}, time.Second); err != nil {
+		t.Errorf("WaitRunning: %v", err)
+	}
+
+	t.Cleanup(SwapStatus(func(Site) string { return constants.StatusStopped }))
+	if err := WaitRunning(Site{Name: "db"}, 5*time.Millisecond); err == nil {
+		t.Error("expected a timeout error")
+	}
+}
@@ -0,0 +1,128 @@
+// Package site — disable.go implements `srv disable` and `srv enable`. A
+// disabled site is flagged in metadata.yml and drops out of Traefik routing:
+// a compose site's route files are renamed to *.yml.disabled, which the file
+// provider ignores, while its containers keep running; a static or
+// dockerfile site, routed by its container's labels, is taken down. Reload
+// and `srv start` respect the flag until the site is enabled again.
+package site
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/constants"
+	"github.com/stubbedev/srv/internal/docker"
+	"github.com/stubbedev/srv/internal/traefik"
+)
+
+// DisabledError is the error for starting a disabled site.
+func DisabledError(name string) error {
+	return fmt.Errorf("site %q is disabled; run 'srv enable %s' first", name, name)
+}
+
+// routeFiles returns the Traefik file-provider configs that route to a
+// site: its route config and its extra-routes config.
+func routeFiles(cfg *config.Config, name string) []string {
+	return []string{traefik.SiteRouteConfigPath(cfg, name), traefik.RoutesConfigPath(cfg, name)}
+}
+
+// parkRouteFiles renames a site's route files to *.disabled, replacing
+// older parked copies.
+func parkRouteFiles(cfg *config.Config, name string) error {
+	for _, path := range routeFiles(cfg, name) {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		if err := os.Rename(path, path+constants.ExtDisabled); err != nil {
+			return fmt.Errorf("disable %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// unparkRouteFiles reverses parkRouteFiles.
+func unparkRouteFiles(cfg *config.Config, name string) error {
+	for _, path := range routeFiles(cfg, name) {
+		if err := os.Rename(path+constants.ExtDisabled, path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("enable %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// DisableSite takes a site out of Traefik routing and records it as
+// disabled. Static and dockerfile sites are brought down, since their
+// routing lives on the container.
+func DisableSite(name string) (warnings []string, err error) {
+	meta, err := requireMeta(name)
+	if err != nil {
+		return nil, err
+	}
+	if meta.Disabled {
+		return nil, fmt.Errorf("site %q is already disabled", name)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	if meta.Type == SiteTypeStatic || meta.Type == SiteTypeDockerfile {
+		if err := docker.EnsureRunning(); err != nil {
+			return nil, err
+		}
+		s, err := requireSite(name)
+		if err != nil {
+			return nil, err
+		}
+		if err := docker.ComposeDown(s.ComposeDir, s.ComposeFiles()...); err != nil {
+			return nil, fmt.Errorf("stop site: %w", err)
+		}
+	}
+	if err := parkRouteFiles(cfg, name); err != nil {
+		return nil, err
+	}
+	meta.Disabled = true
+	if err := WriteSiteMetadata(name, *meta); err != nil {
+		return nil, err
+	}
+	if err := traefik.UpdateDynamicConfig(); err != nil {
+		warnings = append(warnings, fmt.Sprintf("update Traefik config: %v", err))
+	}
+	return warnings, nil
+}
+
+// EnableSite reverses DisableSite: it clears the flag and restores the
+// site's route files. Static and dockerfile sites have to be started again
+// to route; the caller does that.
+func EnableSite(name string) (warnings []string, err error) {
+	meta, err := requireMeta(name)
+	if err != nil {
+		return nil, err
+	}
+	if !meta.Disabled {
+		return nil, fmt.Errorf("site %q is not disabled", name)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := unparkRouteFiles(cfg, name); err != nil {
+		return nil, err
+	}
+	meta.Disabled = false
+	if err := WriteSiteMetadata(name, *meta); err != nil {
+		return nil, err
+	}
+	// Metadata edits made while disabled weren't applied to the parked files.
+	res, err := Reload(name)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, res.Warnings...)
+	if err := traefik.UpdateDynamicConfig(); err != nil {
+		warnings = append(warnings, fmt.Sprintf("update Traefik config: %v", err))
+	}
+	return warnings, nil
+}
@@ -82,6 +82,16 @@ func WriteDockerfileSiteConfig(name string, meta SiteMetadata, info *DockerfileS
 	if HasListener(meta.Listeners, constants.ListenerInternal) {
 		addInternalListenerLabels(labels, name, meta.Domains, meta.Wildcard)
 	}
+	addStagingLabels(labels, name, meta.Staging)
+	addMiddlewareLabels(labels, name, meta.Middlewares)
+	addRetryLabels(labels, name, meta.RetryAttempts, meta.RetryInterval)
+	addPathPrefixLabels(labels, name, meta.PathPrefix, meta.StripPrefix)
+	addCompressLabels(labels, name, meta.Compress)
+	addHeaderLabels(labels, name, meta.AddHeaders, meta.RemoveHeaders)
+	addBufferingLabels(labels, name, meta.MaxRequestBodySize, meta.MaxResponseBodySize)
+	addRateLimitLabels(labels, name, meta.RateLimit)
+	addAllowIPLabels(labels, name, meta.AllowIPs)
+	addEntryPointLabels(labels, name, meta.ServedEntryPoints())
 	StampSrvLabels(labels, name, string(meta.Type))
 
 	cf := composeFile{
@@ -0,0 +1,245 @@
+// Package site — env.go manages per-site environment overrides (`srv env`).
+// Overrides live in the site's config dir as env-override.env, so the
+// project's own .env is never touched. Whenever srv brings a site up it layers
+// them on with a throwaway compose file that sets `environment:` on the
+// routed service, and deletes that file once compose exits.
+package site
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-envparse"
+	"gopkg.in/yaml.v3"
+
+	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/constants"
+	"github.com/stubbedev/srv/internal/docker"
+	"github.com/stubbedev/srv/internal/fsutil"
+)
+
+var (
+	// envKeyRegex matches POSIX-portable environment variable names.
+	envKeyRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	// envPlainValueRegex matches values safe to write without quotes.
+	envPlainValueRegex = regexp.MustCompile(`^[A-Za-z0-9_./:@,+=-]*$`)
+)
+
+// EnvOverridePath returns the path of a site's env override file.
+func EnvOverridePath(cfg *config.Config, name string) string {
+	return filepath.Join(SiteConfigDir(cfg, name), constants.EnvOverrideFile)
+}
+
+// ValidateEnvKey checks that key is a usable environment variable name.
+func ValidateEnvKey(key string) error {
+	if !envKeyRegex.MatchString(key) {
+		return fmt.Errorf("invalid environment variable name %q (letters, digits and _; must not start with a digit)", key)
+	}
+	return nil
+}
+
+// ReadEnvOverrides returns a site's env overrides; an empty map when none are
+// set.
+func ReadEnvOverrides(name string) (map[string]string, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(EnvOverridePath(cfg, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]string{}, nil
+		}
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	env, err := envparse.Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", constants.EnvOverrideFile, err)
+	}
+	return env, nil
+}
+
+// SetEnvOverride stores KEY=VALUE for a site, replacing any previous value.
+func SetEnvOverride(name, key, value string) error {
+	if !HasSiteMetadata(name) {
+		return fmt.Errorf("site not found: %s", name)
+	}
+	if err := ValidateEnvKey(key); err != nil {
+		return err
+	}
+	env, err := ReadEnvOverrides(name)
+	if err != nil {
+		return err
+	}
+	env[key] = value
+	return writeEnvOverrides(name, env)
+}
+
+// UnsetEnvOverride removes key from a site's overrides. Returns false when
+// the key wasn't set.
+func UnsetEnvOverride(name, key string) (bool, error) {
+	if !HasSiteMetadata(name) {
+		return false, fmt.Errorf("site not found: %s", name)
+	}
+	env, err := ReadEnvOverrides(name)
+	if err != nil {
+		return false, err
+	}
+	if _, ok := env[key]; !ok {
+		return false, nil
+	}
+	delete(env, key)
+	return true, writeEnvOverrides(name, env)
+}
+
+// writeEnvOverrides persists env sorted by key, 0600 since overrides often
+// carry credentials. An empty map removes the file.
+func writeEnvOverrides(name string, env map[string]string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	path := EnvOverridePath(cfg, name)
+	if len(env) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	var b strings.Builder
+	for _, k := range sortedEnvKeys(env) {
+		fmt.Fprintf(&b, "%s=%s\n", k, quoteEnvValue(env[k]))
+	}
+	return fsutil.AtomicWriteFile(path, []byte(b.String()), constants.FilePermACME)
+}
+
+// quoteEnvValue leaves simple values bare and JSON-quotes the rest, which is
+// the escape syntax envparse reads back for double-quoted values.
+func quoteEnvValue(v string) string {
+	if envPlainValueRegex.MatchString(v) {
+		return v
+	}
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(v)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func sortedEnvKeys(env map[string]string) []string {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// composeEnvOverlay is the throwaway compose file EnvOverlay writes.
+type composeEnvOverlay struct {
+	Services map[string]composeEnvService `yaml:"services"`
+}
+
+type composeEnvService struct {
+	Environment map[string]string `yaml:"environment"`
+}
+
+// EnvOverlay writes a compose file that sets the site's env overrides on its
+// routed service and returns the compose files to pass with -f (the site's
+// own file(s) first, then the overlay) plus a cleanup func that deletes the
+// overlay. With no overrides it returns just the site's ComposeFiles. cleanup
+// is never nil.
+func EnvOverlay(s *Site) (files []string, cleanup func(), err error) {
+	cleanup = func() {}
+	env, err := ReadEnvOverrides(s.Name)
+	if err != nil {
+		return nil, cleanup, err
+	}
+	if len(env) == 0 {
+		return s.ComposeFiles(), cleanup, nil
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, cleanup, err
+	}
+
+	var service string
+	switch s.Type {
+	case SiteTypeStatic:
+		service = "web"
+		files = []string{SiteComposePath(cfg, s.Name)}
+	case SiteTypeDockerfile:
+		service = "app"
+		files = []string{SiteComposePath(cfg, s.Name)}
+	default:
+		service = s.ComposeServiceName
+		if files = s.ComposeFiles(); files != nil {
+			break
+		}
+		base, err := FindComposeFile(s.ComposeDir)
+		if err != nil {
+			return nil, cleanup, err
+		}
+		files = append([]string{base}, composeOverrideFiles(base)...)
+	}
+	if service == "" {
+		return nil, cleanup, fmt.Errorf("site %s has env overrides but no compose service to apply them to", s.Name)
+	}
+
+	// Compose interpolates $VAR in the file itself; $$ keeps values literal.
+	escaped := make(map[string]string, len(env))
+	for k, v := range env {
+		escaped[k] = strings.ReplaceAll(v, "$", "$$")
+	}
+	data, err := yaml.Marshal(composeEnvOverlay{Services: map[string]composeEnvService{service: {Environment: escaped}}})
+	if err != nil {
+		return nil, cleanup, err
+	}
+	f, err := os.CreateTemp(SiteConfigDir(cfg, s.Name), ".env-overlay-*.yml")
+	if err != nil {
+		return nil, cleanup, fmt.Errorf("create env overlay: %w", err)
+	}
+	path := f.Name()
+	_, werr := f.Write(data)
+	if cerr := f.Close(); werr == nil {
+		werr = cerr
+	}
+	if werr != nil {
+		_ = os.Remove(path)
+		return nil, cleanup, fmt.Errorf("write env overlay: %w", werr)
+	}
+	return append(files, path), func() { _ = os.Remove(path) }, nil
+}
+
+// composeOverrideFiles returns the docker-compose.override.yml-style sibling
+// of base when it exists. Compose only loads it automatically when no -f is
+// given, so EnvOverlay has to pass it explicitly.
+func composeOverrideFiles(base string) []string {
+	ext := filepath.Ext(base)
+	override := strings.TrimSuffix(base, ext) + ".override" + ext
+	if _, err := os.Stat(override); err == nil {
+		return []string{override}
+	}
+	return nil
+}
+
+// ComposeUp brings a site's containers up (`docker compose up -d`, with
+// --build when build is set) with its env overrides layered on.
+func ComposeUp(s *Site, build bool) error {
+	files, cleanup, err := EnvOverlay(s)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if build {
+		return docker.ComposeUpBuildWithProfile(s.ComposeDir, s.Profile, files...)
+	}
+	return docker.ComposeUpWithProfile(s.ComposeDir, s.Profile, files...)
+}
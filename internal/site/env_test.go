@@ -0,0 +1,217 @@
+package site
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/docker"
+)
+
+func writeEnvTestSite(t *testing.T, name string, meta SiteMetadata) {
+	t.Helper()
+	if meta.Domains == nil {
+		meta.Domains = []string{name + ".test"}
+	}
+	if err := WriteSiteMetadata(name, meta); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEnvOverrideRoundTrip(t *testing.T) {
+	withSRVRoot(t)
+	writeEnvTestSite(t, "app", SiteMetadata{Type: SiteTypeStatic, ProjectPath: t.TempDir()})
+
+	values := map[string]string{
+		"PLAIN":   "mysql01:3306",
+		"SPACES":  "hello world",
+		"QUOTES":  `say "hi" & it's <fine>`,
+		"DOLLAR":  "pa$$word",
+		"NEWLINE": "line1\nline2\ttab",
+		"UNICODE": "café ☕",
+		"EMPTY":   "",
+	}
+	for k, v := range values {
+		if err := SetEnvOverride("app", k, v); err != nil {
+			t.Fatalf("set %s: %v", k, err)
+		}
+	}
+	got, err := ReadEnvOverrides("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(values) {
+		t.Fatalf("got %d keys, want %d: %v", len(got), len(values), got)
+	}
+	for k, v := range values {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+
+	cfg, _ := config.Load()
+	info, err := os.Stat(EnvOverridePath(cfg, "app"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("override file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	for k := range values {
+		if removed, err := UnsetEnvOverride("app", k); err != nil || !removed {
+			t.Fatalf("unset %s: removed=%v err=%v", k, removed, err)
+		}
+	}
+	if removed, _ := UnsetEnvOverride("app", "PLAIN"); removed {
+		t.Error("unsetting a missing key reported removal")
+	}
+	if _, err := os.Stat(EnvOverridePath(cfg, "app")); !os.IsNotExist(err) {
+		t.Errorf("empty override file should be removed, stat err = %v", err)
+	}
+}
+
+func TestSetEnvOverrideValidation(t *testing.T) {
+	withSRVRoot(t)
+	if err := SetEnvOverride("ghost", "A", "1"); err == nil {
+		t.Error("expected error for a missing site")
+	}
+	writeEnvTestSite(t, "app", SiteMetadata{Type: SiteTypeStatic, ProjectPath: t.TempDir()})
+	for _, key := range []string{"", "1ABC", "A-B", "A B", "A=B"} {
+		if err := SetEnvOverride("app", key, "x"); err == nil {
+			t.Errorf("key %q should be rejected", key)
+		}
+	}
+}
+
+// readOverlay returns the environment the overlay sets, by service.
+func readOverlay(t *testing.T, path string) map[string]map[string]string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var overlay composeEnvOverlay
+	if err := yaml.Unmarshal(data, &overlay); err != nil {
+		t.Fatalf("overlay is not valid YAML: %v\n%s", err, data)
+	}
+	out := map[string]map[string]string{}
+	for name, svc := range overlay.Services {
+		out[name] = svc.Environment
+	}
+	return out
+}
+
+func TestEnvOverlayNoOverrides(t *testing.T) {
+	withSRVRoot(t)
+	writeEnvTestSite(t, "app", SiteMetadata{Type: SiteTypeStatic, ProjectPath: t.TempDir()})
+	files, cleanup, err := EnvOverlay(&Site{Name: "app", Type: SiteTypeStatic})
+	if err != nil || files != nil || cleanup == nil {
+		t.Fatalf("files=%v cleanup-nil=%v err=%v", files, cleanup == nil, err)
+	}
+	cleanup()
+}
+
+func TestEnvOverlayStaticSite(t *testing.T) {
+	withSRVRoot(t)
+	cfg, _ := config.Load()
+	writeEnvTestSite(t, "docs", SiteMetadata{Type: SiteTypeStatic, ProjectPath: t.TempDir()})
+	if err := SetEnvOverride("docs", "GREETING", "cost: $5"); err != nil {
+		t.Fatal(err)
+	}
+
+	files, cleanup, err := EnvOverlay(&Site{Name: "docs", Type: SiteTypeStatic, ComposeDir: SiteConfigDir(cfg, "docs")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || files[0] != SiteComposePath(cfg, "docs") {
+		t.Fatalf("files = %v", files)
+	}
+	overlay := readOverlay(t, files[1])
+	if got := overlay["web"]["GREETING"]; got != "cost: $$5" {
+		t.Errorf("web GREETING = %q, want $ escaped for compose", got)
+	}
+	info, _ := os.Stat(files[1])
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("overlay mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	cleanup()
+	if _, err := os.Stat(files[1]); !os.IsNotExist(err) {
+		t.Errorf("overlay not removed by cleanup: %v", err)
+	}
+}
+
+func TestEnvOverlayComposeSite(t *testing.T) {
+	withSRVRoot(t)
+	project := t.TempDir()
+	for _, f := range []string{"compose.yaml", "compose.override.yaml"} {
+		if err := os.WriteFile(filepath.Join(project, f), []byte("services: {}\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeEnvTestSite(t, "app", SiteMetadata{Type: SiteTypeCompose, ProjectPath: project, ComposeServiceName: "php"})
+	if err := SetEnvOverride("app", "APP_DEBUG", "true"); err != nil {
+		t.Fatal(err)
+	}
+
+	files, cleanup, err := EnvOverlay(&Site{Name: "app", Type: SiteTypeCompose, ComposeDir: project, ComposeServiceName: "php"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	want := []string{filepath.Join(project, "compose.yaml"), filepath.Join(project, "compose.override.yaml")}
+	if len(files) != 3 || files[0] != want[0] || files[1] != want[1] {
+		t.Fatalf("files = %v, want %v + overlay", files, want)
+	}
+	if got := readOverlay(t, files[2])["php"]["APP_DEBUG"]; got != "true" {
+		t.Errorf("php APP_DEBUG = %q", got)
+	}
+
+	// Without a service there is nothing to apply the overrides to.
+	if _, _, err := EnvOverlay(&Site{Name: "app", Type: SiteTypeCompose, ComposeDir: project}); err == nil {
+		t.Error("expected error when the compose site has no service")
+	}
+}
+
+func TestComposeUpLayersAndCleansUpOverlay(t *testing.T) {
+	withSRVRoot(t)
+	cfg, _ := config.Load()
+	writeEnvTestSite(t, "docs", SiteMetadata{Type: SiteTypeStatic, ProjectPath: t.TempDir()})
+	if err := SetEnvOverride("docs", "A", "1"); err != nil {
+		t.Fatal(err)
+	}
+	dir := SiteConfigDir(cfg, "docs")
+
+	var gotArgs []string
+	var overlayExisted bool
+	t.Cleanup(docker.SwapComposeExec(func(d string, quiet bool, args ...string) error {
+		gotArgs = args
+		if len(args) > 5 {
+			_, err := os.Stat(args[5])
+			overlayExisted = err == nil
+		}
+		return nil
+	}))
+	if err := ComposeUp(&Site{Name: "docs", Type: SiteTypeStatic, ComposeDir: dir, Profile: "web"}, true); err != nil {
+		t.Fatal(err)
+	}
+	joined := strings.Join(gotArgs, " ")
+	if !strings.HasPrefix(joined, "--profile web -f "+SiteComposePath(cfg, "docs")+" -f ") || !strings.HasSuffix(joined, " up -d --build") {
+		t.Errorf("compose args = %q", joined)
+	}
+	if len(gotArgs) < 6 {
+		t.Fatalf("compose args = %q", joined)
+	}
+	overlay := gotArgs[5]
+	if !overlayExisted {
+		t.Error("overlay missing while compose ran")
+	}
+	if _, err := os.Stat(overlay); !os.IsNotExist(err) {
+		t.Errorf("overlay %s left behind after compose exited", overlay)
+	}
+}
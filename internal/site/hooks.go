@@ -0,0 +1,55 @@
+// Package site — hooks.go runs a site's pre_start / post_start commands
+// around `docker compose up` (migrations, cache warming, asset builds).
+package site
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// hookRunner runs one hook command in dir. Tests swap it to record calls
+// instead of spawning a shell.
+var hookRunner = defaultHookRunner
+
+// defaultHookRunner runs command through `sh -c`. Output goes to stderr so it
+// never interleaves with result output (or the MCP protocol on stdout).
+func defaultHookRunner(dir, command string) error {
+	c := exec.Command("sh", "-c", command) //nolint:gosec // user-configured hook
+	c.Dir = dir
+	c.Stdin = nil
+	c.Stdout = os.Stderr
+	c.Stderr = os.Stderr
+	return c.Run()
+}
+
+// RunPreStartHooks runs the site's pre_start commands in order from its
+// project directory, stopping at the first failure. Callers abort the start
+// on error.
+func RunPreStartHooks(name string) error {
+	meta, err := ReadSiteMetadata(name)
+	if err != nil || meta == nil {
+		return err
+	}
+	return runHooks("pre-start", meta.ProjectPath, meta.PreStart)
+}
+
+// RunPostStartHooks runs the site's post_start commands in order from its
+// project directory, stopping at the first failure. The containers are
+// already up by then, so callers report the error without undoing the start.
+func RunPostStartHooks(name string) error {
+	meta, err := ReadSiteMetadata(name)
+	if err != nil || meta == nil {
+		return err
+	}
+	return runHooks("post-start", meta.ProjectPath, meta.PostStart)
+}
+
+func runHooks(phase, dir string, commands []string) error {
+	for _, command := range commands {
+		if err := hookRunner(dir, command); err != nil {
+			return fmt.Errorf("%s hook %q failed: %w", phase, command, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package site
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// recordHooks swaps hookRunner for one that records "dir: command" and fails
+// any command listed in fail.
+func recordHooks(t *testing.T, fail ...string) *[]string {
+	t.Helper()
+	var calls []string
+	prev := hookRunner
+	t.Cleanup(func() { hookRunner = prev })
+	hookRunner = func(dir, command string) error {
+		calls = append(calls, dir+": "+command)
+		for _, f := range fail {
+			if f == command {
+				return errors.New("exit status 1")
+			}
+		}
+		return nil
+	}
+	return &calls
+}
+
+func TestRunStartHooks(t *testing.T) {
+	withSRVRoot(t)
+	if err := WriteSiteMetadata("app", SiteMetadata{
+		Type:        SiteTypeCompose,
+		Domains:     []string{"app.test"},
+		ProjectPath: "/srv/app",
+		PreStart:    []string{"make assets", "php artisan migrate"},
+		PostStart:   []string{"curl -fsS localhost/warm"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := recordHooks(t)
+	if err := RunPreStartHooks("app"); err != nil {
+		t.Fatal(err)
+	}
+	if err := RunPostStartHooks("app"); err != nil {
+		t.Fatal(err)
+	}
+	want := "/srv/app: make assets|/srv/app: php artisan migrate|/srv/app: curl -fsS localhost/warm"
+	if got := strings.Join(*calls, "|"); got != want {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+
+	// The first failure stops the chain and names the hook.
+	calls = recordHooks(t, "make assets")
+	err := RunPreStartHooks("app")
+	if err == nil || !strings.Contains(err.Error(), `pre-start hook "make assets" failed`) {
+		t.Errorf("err = %v", err)
+	}
+	if len(*calls) != 1 {
+		t.Errorf("hooks after a failure still ran: %v", *calls)
+	}
+}
+
+func TestRunStartHooksNoMetadata(t *testing.T) {
+	withSRVRoot(t)
+	calls := recordHooks(t)
+	if err := RunPreStartHooks("ghost"); err != nil {
+		t.Errorf("err = %v", err)
+	}
+	if len(*calls) != 0 {
+		t.Errorf("unexpected hook calls: %v", *calls)
+	}
+}
+
+func TestDefaultHookRunner(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	dir := t.TempDir()
+	if err := defaultHookRunner(dir, "echo ok > marker"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "marker")); err != nil {
+		t.Errorf("hook did not run in the project dir: %v", err)
+	}
+	if err := defaultHookRunner(dir, "exit 3"); err == nil {
+		t.Error("non-zero exit should fail")
+	}
+}
+
+func TestValidateMetadataEmptyHook(t *testing.T) {
+	meta := &SiteMetadata{Domains: []string{"a.test"}, PreStart: []string{"make", " "}}
+	if err := ValidateMetadata(meta); err == nil || !strings.Contains(err.Error(), "pre_start") {
+		t.Errorf("err = %v", err)
+	}
+	meta = &SiteMetadata{Domains: []string{"a.test"}, PostStart: []string{""}}
+	if err := ValidateMetadata(meta); err == nil || !strings.Contains(err.Error(), "post_start") {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -1,5 +1,6 @@
-// Package site — lifecycle.go holds headless start/stop/restart for a single
-// site, shared by the `srv start|stop|restart` CLI and the MCP lifecycle tools.
+// Package site — lifecycle.go holds headless start/stop/restart/rebuild/pull
+// for a single site, shared by the lifecycle CLI commands and the MCP
+// lifecycle tools.
 // The CLI keeps its --all batch handling and progress UI on top; the core
 // container choreography lives here so both surfaces behave identically.
 package site
@@ -7,16 +8,24 @@ package site
 import (
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/constants"
 	"github.com/stubbedev/srv/internal/docker"
 	"github.com/stubbedev/srv/internal/traefik"
+	"github.com/stubbedev/srv/internal/validate"
 )
 
 // StartSite brings a single site's containers up. It ensures Docker + the srv
 // network are ready, renews the local cert if needed, regenerates per-site
-// artifacts (Reload), then `docker compose up` (with --build when build=true)
-// and connects a compose service to the srv network.
+// artifacts (Reload), runs the pre_start hooks, then `docker compose up` (with
+// --build when build=true), connects a compose service to the srv network and
+// runs the post_start hooks.
 func StartSite(name string, build bool) error {
 	if err := docker.EnsureRunning(); err != nil {
 		return err
@@ -32,28 +41,51 @@ func StartSite(name string, build bool) error {
 	if err != nil {
 		return err
 	}
+	if s.Disabled {
+		return DisabledError(s.Name)
+	}
 
-	if s.IsLocal && len(s.Domains) > 0 {
+	if s.IsLocal && !s.CustomCert && len(s.Domains) > 0 {
 		// Best-effort: a renewal failure should not block start.
 		_, _ = traefik.EnsureLocalCert(s.Name, s.Domains, s.Wildcard)
 	}
-	if _, err := Reload(s.Name); err != nil {
+	res, err := Reload(s.Name)
+	if err != nil {
 		return fmt.Errorf("reload site before start: %w", err)
 	}
+	if err := RunPreStartHooks(s.Name); err != nil {
+		return err
+	}
 
-	if build {
-		if err := docker.ComposeUpBuildWithProfile(s.ComposeDir, s.Profile); err != nil {
-			return fmt.Errorf("start site: %w", err)
-		}
-	} else if err := docker.ComposeUpWithProfile(s.ComposeDir, s.Profile); err != nil {
+	if err := ComposeUp(s, build); err != nil {
 		return fmt.Errorf("start site: %w", err)
 	}
 
+	if err := RestartStaticIfReloaded(s, res); err != nil {
+		return err
+	}
+
 	if s.Type == SiteTypeCompose && s.ComposeServiceName != "" {
 		if err := docker.ConnectServiceToNetwork(s.Dir, s.ComposeServiceName, cfg.NetworkName); err != nil && !errors.Is(err, docker.ErrServiceNotRunning) {
 			return fmt.Errorf("connect service to network: %w", err)
 		}
 	}
+	if err := RunPostStartHooks(s.Name); err != nil {
+		return fmt.Errorf("site started, but %w", err)
+	}
+	return nil
+}
+
+// RestartStaticIfReloaded restarts a running static site's container when res
+// shows Reload regenerated its artifacts. The container keeps serving its old
+// nginx.conf otherwise (e.g. after an edited nginx snippet).
+func RestartStaticIfReloaded(s *Site, res *ReloadResult) error {
+	if s.Type != SiteTypeStatic || res.Skipped || s.Status != constants.StatusRunning {
+		return nil
+	}
+	if err := docker.ComposeRestart(s.ComposeDir, s.ComposeFiles()...); err != nil {
+		return fmt.Errorf("restart site to apply nginx config: %w", err)
+	}
 	return nil
 }
 
@@ -66,7 +98,7 @@ func StopSite(name string) error {
 	if err != nil {
 		return err
 	}
-	if err := docker.ComposeStop(s.ComposeDir); err != nil {
+	if err := docker.ComposeStop(s.ComposeDir, s.ComposeFiles()...); err != nil {
 		return fmt.Errorf("stop site: %w", err)
 	}
 	return nil
@@ -92,15 +124,167 @@ func RestartSite(name string, build bool) error {
 		return fmt.Errorf("reload site before restart: %w", err)
 	}
 	if build {
-		if err := docker.ComposeUpBuildWithProfile(s.ComposeDir, s.Profile); err != nil {
+		if err := ComposeUp(s, true); err != nil {
 			return fmt.Errorf("rebuild and restart site: %w", err)
 		}
-	} else if err := docker.ComposeRestart(s.ComposeDir); err != nil {
+	} else if err := docker.ComposeRestart(s.ComposeDir, s.ComposeFiles()...); err != nil {
 		return fmt.Errorf("restart site: %w", err)
 	}
 	return nil
 }
 
+// rebuildOutputLines is how much of a failed build's output RebuildSite
+// returns in its error.
+const rebuildOutputLines = 20
+
+// RebuildSite takes a site's containers down and brings them back up with
+// freshly built images: `docker compose down` (with service set, only that
+// service's container is stopped and removed), then `up -d --build
+// --remove-orphans`, then the routed service is reconnected to the srv
+// network. Every site has its own compose project, so --remove-orphans only
+// clears this site's leftovers. Static sites have nothing to build (nginx:alpine comes from the
+// registry), so their container is only restarted; rebuilt reports whether
+// images were built. Compose output is captured rather than streamed, so
+// callers can show progress; a failed build's error ends with its last lines.
+func RebuildSite(name, service string) (rebuilt bool, err error) {
+	if err := docker.EnsureRunning(); err != nil {
+		return false, err
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return false, err
+	}
+	if err := docker.EnsureInitialized(cfg.NetworkName); err != nil {
+		return false, err
+	}
+	s, err := requireSite(name)
+	if err != nil {
+		return false, err
+	}
+	if service != "" {
+		if s.Type != SiteTypeCompose {
+			return false, fmt.Errorf("--service only applies to compose sites; %s is a %s site", s.Name, s.Type)
+		}
+		if err := requireComposeService(s, service); err != nil {
+			return false, err
+		}
+	}
+	if _, err := Reload(s.Name); err != nil {
+		return false, fmt.Errorf("reload site before rebuild: %w", err)
+	}
+
+	if s.Type == SiteTypeStatic {
+		if err := docker.ComposeQuiet(s.ComposeDir, "restart"); err != nil {
+			return false, fmt.Errorf("restart site: %w", err)
+		}
+		return false, nil
+	}
+
+	files, cleanup, err := EnvOverlay(s)
+	if err != nil {
+		return false, err
+	}
+	defer cleanup()
+	fileArgs := docker.ComposeFileArgs(files)
+
+	down := slices.Concat(fileArgs, []string{"down"})
+	if service != "" {
+		down = slices.Concat(fileArgs, []string{"rm", "--stop", "--force", service})
+	}
+	if err := docker.ComposeQuietWithProfile(s.ComposeDir, s.Profile, down...); err != nil {
+		return false, fmt.Errorf("stop site: %w", err)
+	}
+
+	up := slices.Concat(fileArgs, []string{"up", "-d", "--build", "--remove-orphans"})
+	if service != "" {
+		up = append(up, service)
+	}
+	if s.Profile != "" {
+		up = append([]string{"--profile", s.Profile}, up...)
+	}
+	if out, err := docker.ComposeOutput(s.ComposeDir, up...); err != nil {
+		return false, fmt.Errorf("rebuild site: %w\n%s", err, lastLines(string(out), rebuildOutputLines))
+	}
+
+	if s.Type == SiteTypeCompose && s.ComposeServiceName != "" && (service == "" || service == s.ComposeServiceName) {
+		if err := docker.ConnectServiceToNetwork(s.Dir, s.ComposeServiceName, cfg.NetworkName); err != nil && !errors.Is(err, docker.ErrServiceNotRunning) {
+			return true, fmt.Errorf("connect service to network: %w", err)
+		}
+	}
+	return true, nil
+}
+
+// PullSite pulls the latest images for a site (`docker compose pull`, with the
+// site's profile and env overlay) and records the time in metadata's
+// last_pulled. Static sites pull their nginx image through the generated
+// compose file; dockerfile sites have no image to pull, as theirs is built
+// locally. Running containers keep their old image until they're recreated.
+// With quiet set compose output is discarded, for batch pulls.
+func PullSite(name string, quiet bool) error {
+	if err := docker.EnsureRunning(); err != nil {
+		return err
+	}
+	s, err := requireSite(name)
+	if err != nil {
+		return err
+	}
+	files, cleanup, err := EnvOverlay(s)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	args := slices.Concat(docker.ComposeFileArgs(files), []string{"pull"})
+	if s.Profile != "" {
+		args = append([]string{"--profile", s.Profile}, args...)
+	}
+	if quiet {
+		err = docker.ComposeQuiet(s.ComposeDir, args...)
+	} else {
+		err = docker.Compose(s.ComposeDir, args...)
+	}
+	if err != nil {
+		return fmt.Errorf("pull images: %w", err)
+	}
+
+	meta, err := requireMeta(s.Name)
+	if err != nil {
+		return err
+	}
+	meta.LastPulled = time.Now().UTC().Truncate(time.Second)
+	return WriteSiteMetadata(s.Name, *meta)
+}
+
+// requireComposeService errors unless service is defined in the site's
+// compose file.
+func requireComposeService(s *Site, service string) error {
+	composePath, err := FindComposeFile(s.ComposeDir)
+	if err != nil {
+		return err
+	}
+	infos, err := GetServiceInfos(composePath)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(infos))
+	for _, info := range infos {
+		if info.ServiceName == service {
+			return nil
+		}
+		names = append(names, info.ServiceName)
+	}
+	slices.Sort(names)
+	return fmt.Errorf("service %q not found in %s (services: %s)", service, composePath, strings.Join(names, ", "))
+}
+
+// lastLines returns the last n lines of s, without a trailing newline.
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
 // RemoveSite stops a site's containers and deletes all of its derived state:
 // Traefik route config, extra-routes config, local cert + DNS registrations,
 // and the metadata directory. Shared by `srv remove` and the MCP remove_site
@@ -120,7 +304,7 @@ func RemoveSite(name string) (warnings []string, err error) {
 	}
 
 	if !s.IsBroken {
-		if err := docker.ComposeDown(s.ComposeDir); err != nil {
+		if err := docker.ComposeDown(s.ComposeDir, s.ComposeFiles()...); err != nil {
 			warnings = append(warnings, fmt.Sprintf("stop containers: %v", err))
 		}
 		if s.Type == SiteTypeCompose {
@@ -132,6 +316,11 @@ func RemoveSite(name string) (warnings []string, err error) {
 			warnings = append(warnings, fmt.Sprintf("remove routes config: %v", err))
 		}
 	}
+	for _, path := range routeFiles(cfg, name) {
+		if err := os.Remove(path + constants.ExtDisabled); err != nil && !os.IsNotExist(err) {
+			warnings = append(warnings, fmt.Sprintf("remove disabled route config: %v", err))
+		}
+	}
 
 	if s.IsLocal && len(s.Domains) > 0 {
 		if err := traefik.RemoveLocalCerts(name, s.Domains[0]); err != nil {
@@ -153,6 +342,223 @@ func RemoveSite(name string) (warnings []string, err error) {
 	return warnings, nil
 }
 
+// RemovePlan lists what RemoveSite would touch for one site, without touching
+// any of it. Used by `srv remove --dry-run`.
+type RemovePlan struct {
+	Name       string
+	Containers []string // containers `docker compose down` would stop and remove
+	Files      []string // config files and directories that would be deleted
+	DNS        []string // local DNS registrations that would be dropped
+}
+
+// PlanRemoveSite mirrors RemoveSite step for step and reports what each step
+// would remove. Only paths that currently exist are listed.
+func PlanRemoveSite(name string) (*RemovePlan, error) {
+	s, err := GetByName(name)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return nil, fmt.Errorf("site %q not found", name)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &RemovePlan{Name: name}
+	addFile := func(path string) {
+		if _, err := os.Stat(path); err == nil {
+			plan.Files = append(plan.Files, path)
+		}
+	}
+
+	if !s.IsBroken {
+		plan.Containers = s.Containers()
+		if s.Type == SiteTypeCompose {
+			addFile(traefik.SiteRouteConfigPath(cfg, name))
+		}
+		addFile(traefik.RoutesConfigPath(cfg, name))
+	}
+	if s.IsLocal && len(s.Domains) > 0 {
+		plan.DNS = append(plan.DNS, s.Domains...)
+	}
+	addFile(SiteConfigDir(cfg, name))
+	return plan, nil
+}
+
+// RenameSite re-registers a site under newName: metadata and certs move to the
+// new site config dir, the Traefik route files are renamed, and the old dir is
+// removed. Refuses while the site's containers are up unless force is set;
+// srv-managed (static/dockerfile) projects are named after the site, so a
+// forced rename takes their containers down first rather than orphaning them.
+// Domains carry over unchanged, so DNS registrations stay valid. Non-fatal
+// issues are returned as warnings.
+func RenameSite(oldName, newName string, force bool) (warnings []string, err error) {
+	if err := validate.SiteName(newName); err != nil {
+		return nil, err
+	}
+	if oldName == newName {
+		return nil, fmt.Errorf("site %q already has that name", oldName)
+	}
+	meta, err := requireMeta(oldName)
+	if err != nil {
+		return nil, err
+	}
+	if HasSiteMetadata(newName) {
+		return nil, fmt.Errorf("site %q already exists", newName)
+	}
+	s, err := GetByName(oldName)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	running := !s.IsBroken && s.Status != "" && s.Status != constants.StatusStopped
+	if running && !force {
+		return nil, fmt.Errorf("site %q is running; stop it first or use --force", oldName)
+	}
+	if running && s.Type != SiteTypeCompose {
+		if err := docker.ComposeDown(s.ComposeDir, s.ComposeFiles()...); err != nil {
+			warnings = append(warnings, fmt.Sprintf("stop containers: %v", err))
+		}
+	}
+
+	if err := WriteSiteMetadata(newName, *meta); err != nil {
+		return warnings, err
+	}
+	moves := [][2]string{
+		{cfg.SiteCertsDir(oldName), cfg.SiteCertsDir(newName)},
+		{traefik.SiteRouteConfigPath(cfg, oldName), traefik.SiteRouteConfigPath(cfg, newName)},
+		{traefik.RoutesConfigPath(cfg, oldName), traefik.RoutesConfigPath(cfg, newName)},
+	}
+	// A disabled site's route files are parked under *.disabled.
+	oldFiles, newFiles := routeFiles(cfg, oldName), routeFiles(cfg, newName)
+	for i := range oldFiles {
+		moves = append(moves, [2]string{oldFiles[i] + constants.ExtDisabled, newFiles[i] + constants.ExtDisabled})
+	}
+	for _, m := range moves {
+		if err := os.Rename(m[0], m[1]); err != nil && !os.IsNotExist(err) {
+			warnings = append(warnings, fmt.Sprintf("move %s: %v", m[0], err))
+		}
+	}
+	if err := RemoveSiteMetadata(oldName); err != nil {
+		return warnings, err
+	}
+
+	// The moved route files still carry the old router names; regenerate
+	// them (and any srv-managed compose artifacts) under the new name.
+	if !s.IsBroken {
+		res, err := Reload(newName)
+		if err != nil {
+			return warnings, fmt.Errorf("reload renamed site: %w", err)
+		}
+		warnings = append(warnings, res.Warnings...)
+	}
+	return warnings, nil
+}
+
+// MoveSite points a site at its project directory's new location after the
+// directory was moved on disk. The new path must hold the same kind of
+// project: a compose file still defining the site's compose service, or a
+// Dockerfile. Generated artifacts (the static site's bind mount, the
+// dockerfile build context, routing) are regenerated, and a site that was
+// running is started again from the new path. Non-fatal issues are returned
+// as warnings.
+func MoveSite(name, newPath string) (restarted bool, warnings []string, err error) {
+	meta, err := requireMeta(name)
+	if err != nil {
+		return false, nil, err
+	}
+	path, err := ResolvePath(newPath)
+	if err != nil {
+		return false, nil, err
+	}
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		return false, nil, fmt.Errorf("%s is not a directory", path)
+	}
+	if path == meta.ProjectPath {
+		return false, nil, fmt.Errorf("site %q already points at %s", name, path)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return false, nil, err
+	}
+
+	// Probe the containers the old metadata describes: the site reads as
+	// broken (and unprobed) once its old directory is gone.
+	old := Site{Name: name, Type: meta.Type, ServiceName: meta.ServiceName, ComposeDir: meta.ProjectPath}
+	if meta.Type != SiteTypeCompose {
+		old.ComposeDir = SiteConfigDir(cfg, name)
+	}
+	status := siteContainerStatus(old)
+	running := status != "" && status != constants.StatusStopped
+
+	var dockerfileInfo *DockerfileSiteInfo
+	switch meta.Type {
+	case SiteTypeCompose:
+		composePath, err := FindComposeFile(path)
+		if err != nil {
+			return false, nil, fmt.Errorf("no docker-compose file in %s", path)
+		}
+		services, err := GetServiceInfos(composePath)
+		if err != nil {
+			return false, nil, fmt.Errorf("parse compose file: %w", err)
+		}
+		service := meta.ComposeServiceName
+		if service == "" {
+			service = meta.ServiceName
+		}
+		i := slices.IndexFunc(services, func(si ServiceInfo) bool { return si.ServiceName == service })
+		if i < 0 {
+			return false, nil, fmt.Errorf("service %q not found in %s", service, composePath)
+		}
+		// Compose derives container names from the directory name, so a
+		// renamed directory renames the container Traefik routes to.
+		meta.ServiceName = services[i].ContainerName
+		if oldProject := strings.ToLower(filepath.Base(meta.ProjectPath)); running && oldProject != strings.ToLower(filepath.Base(path)) {
+			warnings = append(warnings, fmt.Sprintf("containers of the old compose project %q may still be running; stop them with 'docker compose -p %s down'", oldProject, oldProject))
+		}
+	case SiteTypeDockerfile:
+		dockerfileInfo, err = DetectDockerfileSite(path)
+		if err != nil {
+			return false, nil, fmt.Errorf("could not check for Dockerfile: %w", err)
+		}
+		if dockerfileInfo == nil {
+			return false, nil, fmt.Errorf("no Dockerfile in %s", path)
+		}
+	}
+
+	meta.ProjectPath = path
+	if err := WriteSiteMetadata(name, *meta); err != nil {
+		return false, warnings, fmt.Errorf("write metadata: %w", err)
+	}
+	if meta.Type == SiteTypeDockerfile {
+		// Reload leaves dockerfile compose files alone; rewrite the build context.
+		if err := WriteDockerfileSiteConfig(name, *meta, dockerfileInfo, true); err != nil {
+			return false, warnings, fmt.Errorf("regenerate dockerfile config: %w", err)
+		}
+	}
+	// Reload rewrites the static site's compose file (with the new bind
+	// mount) and the compose site's routing.
+	res, err := Reload(name)
+	if err != nil {
+		return false, warnings, fmt.Errorf("reload moved site: %w", err)
+	}
+	warnings = append(warnings, res.Warnings...)
+
+	if !running {
+		return false, warnings, nil
+	}
+	if err := StartSite(name, meta.Type == SiteTypeDockerfile); err != nil {
+		return false, warnings, fmt.Errorf("restart site: %w", err)
+	}
+	return true, warnings, nil
+}
+
 // requireSite loads a site by name and rejects missing or broken sites with a
 // clear error — the common preamble for every lifecycle op.
 func requireSite(name string) (*Site, error) {
@@ -0,0 +1,171 @@
+package site
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/docker"
+)
+
+// seedComposeSite registers a compose site routed to its "web" service, with
+// a "worker" service alongside.
+func seedComposeSite(t *testing.T, name string) string {
+	t.Helper()
+	root := withSRVRoot(t)
+	cfg, _ := config.Load()
+	if err := os.MkdirAll(cfg.TraefikConfDir(), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	project := filepath.Join(root, "src", name)
+	writeFiles(t, project, map[string]string{
+		"docker-compose.yml": "services:\n  web:\n    build: .\n  worker:\n    build: .\n",
+	})
+	if err := WriteSiteMetadata(name, SiteMetadata{
+		Type:               SiteTypeCompose,
+		Domains:            []string{name + ".test"},
+		ProjectPath:        project,
+		ServiceName:        name + "-web",
+		ComposeServiceName: "web",
+		Port:               80,
+		IsLocal:            true,
+		NetworkName:        cfg.NetworkName,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(docker.SwapNewClientWithNetwork(cfg.NetworkName))
+	t.Cleanup(docker.SwapComposeServiceIDLookup(func(context.Context, string, string) (string, error) { return "", nil }))
+	return project
+}
+
+func TestRebuildSite(t *testing.T) {
+	seedComposeSite(t, "app")
+	var quiet []string
+	t.Cleanup(docker.SwapComposeExec(func(_ string, _ bool, args ...string) error {
+		quiet = append(quiet, strings.Join(args, " "))
+		return nil
+	}))
+	var up string
+	t.Cleanup(docker.SwapComposeOutputExec(func(_ string, args ...string) ([]byte, error) {
+		up = strings.Join(args, " ")
+		return nil, nil
+	}))
+
+	rebuilt, err := RebuildSite("app", "")
+	if err != nil || !rebuilt {
+		t.Fatalf("RebuildSite = %v, %v", rebuilt, err)
+	}
+	if strings.Join(quiet, ";") != "down" {
+		t.Errorf("compose calls = %v, want [down]", quiet)
+	}
+	if up != "up -d --build --remove-orphans" {
+		t.Errorf("up args = %q", up)
+	}
+
+	quiet = nil
+	if _, err := RebuildSite("app", "worker"); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(quiet, ";") != "rm --stop --force worker" {
+		t.Errorf("compose calls = %v", quiet)
+	}
+	if up != "up -d --build --remove-orphans worker" {
+		t.Errorf("up args = %q", up)
+	}
+}
+
+func TestRebuildSiteUnknownService(t *testing.T) {
+	seedComposeSite(t, "app")
+	_, err := RebuildSite("app", "cron")
+	if err == nil || !strings.Contains(err.Error(), "web, worker") {
+		t.Errorf("err = %v, want the service list", err)
+	}
+}
+
+func TestRebuildSiteBuildFailureShowsOutput(t *testing.T) {
+	seedComposeSite(t, "app")
+	t.Cleanup(docker.SwapComposeExec(func(string, bool, ...string) error { return nil }))
+	t.Cleanup(docker.SwapComposeOutputExec(func(string, ...string) ([]byte, error) {
+		return []byte("step 1\nERROR: failed to solve: missing go.sum\n"), errors.New("exit status 17")
+	}))
+	_, err := RebuildSite("app", "")
+	if err == nil || !strings.Contains(err.Error(), "missing go.sum") {
+		t.Errorf("err = %v, want build output", err)
+	}
+}
+
+func TestPullSite(t *testing.T) {
+	seedComposeSite(t, "app")
+	var calls []string
+	t.Cleanup(docker.SwapComposeExec(func(_ string, quiet bool, args ...string) error {
+		calls = append(calls, fmt.Sprintf("%v %s", quiet, strings.Join(args, " ")))
+		return nil
+	}))
+	if err := PullSite("app", true); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(calls, ";") != "true pull" {
+		t.Errorf("compose calls = %v, want [true pull]", calls)
+	}
+	meta, err := ReadSiteMetadata("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.LastPulled.IsZero() {
+		t.Error("LastPulled not recorded")
+	}
+}
+
+func TestPullSiteFailureKeepsLastPulled(t *testing.T) {
+	seedComposeSite(t, "app")
+	t.Cleanup(docker.SwapComposeExec(func(string, bool, ...string) error { return errors.New("manifest unknown") }))
+	if err := PullSite("app", false); err == nil || !strings.Contains(err.Error(), "manifest unknown") {
+		t.Fatalf("err = %v", err)
+	}
+	meta, _ := ReadSiteMetadata("app")
+	if !meta.LastPulled.IsZero() {
+		t.Error("a failed pull should not be recorded")
+	}
+}
+
+func TestLastLines(t *testing.T) {
+	if got := lastLines("a\nb\nc\n", 2); got != "b\nc" {
+		t.Errorf("lastLines = %q", got)
+	}
+	if got := lastLines("a", 5); got != "a" {
+		t.Errorf("lastLines = %q", got)
+	}
+}
+
+func TestComposeOverrideFileLayered(t *testing.T) {
+	project := seedComposeSite(t, "app")
+	override := filepath.Join(project, "compose.debug.yml")
+	writeFiles(t, project, map[string]string{"compose.debug.yml": "services:\n  web:\n    environment:\n      DEBUG: \"1\"\n"})
+	meta, _ := ReadSiteMetadata("app")
+	meta.OverridePath = override
+	if err := WriteSiteMetadata("app", *meta); err != nil {
+		t.Fatal(err)
+	}
+	var calls []string
+	t.Cleanup(docker.SwapComposeExec(func(_ string, _ bool, args ...string) error {
+		calls = append(calls, strings.Join(args, " "))
+		return nil
+	}))
+
+	if err := StartSite("app", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := StopSite("app"); err != nil {
+		t.Fatal(err)
+	}
+	files := "-f " + filepath.Join(project, "docker-compose.yml") + " -f " + override
+	want := []string{files + " up -d", files + " stop"}
+	if strings.Join(calls, ";") != strings.Join(want, ";") {
+		t.Errorf("compose calls = %q, want %q", calls, want)
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"time"
 
 	"gopkg.in/yaml.v3"
 
@@ -73,25 +75,69 @@ const CurrentMetadataSchema = 1
 // SiteMetadata holds all configuration for a site.
 // This is stored in ~/.config/srv/sites/{name}/metadata.yml
 type SiteMetadata struct {
-	SchemaVersion      int           `yaml:"schema_version,omitempty" jsonschema:"description=metadata.yml schema version (1 = current)."`
-	Type               SiteType      `yaml:"type" jsonschema:"enum=compose,enum=static,enum=dockerfile,description=Site runtime type."`
-	Domains            []string      `yaml:"domains,omitempty" jsonschema:"description=All hostnames; the first entry is canonical."`
-	ProjectPath        string        `yaml:"project_path" jsonschema:"description=Absolute path to the project on disk."`
-	ServiceName        string        `yaml:"service_name,omitempty" jsonschema:"description=Container name used for Traefik routing."`
-	ComposeServiceName string        `yaml:"compose_service_name,omitempty" jsonschema:"description=docker-compose service name (for compose commands)."`
-	Profile            string        `yaml:"profile,omitempty" jsonschema:"description=docker-compose profile (if the service uses profiles)."`
-	Port               int           `yaml:"port" jsonschema:"description=Port the service listens on inside the container."`
-	IsLocal            bool          `yaml:"is_local" jsonschema:"description=Whether to use a locally-issued (mkcert) SSL certificate."`
-	Wildcard           bool          `yaml:"wildcard,omitempty" jsonschema:"description=Match apex + one-level subdomains (*.example.com)."`
-	NetworkName        string        `yaml:"network_name" jsonschema:"description=Docker network the site joins."`
-	ExtraNetworks      []string      `yaml:"extra_networks,omitempty" jsonschema:"description=Extra external Docker networks the site joins (for reaching user-managed containers like mysql01)."`
-	Volumes            []VolumeMount `yaml:"volumes,omitempty" jsonschema:"description=Extra host bind-mounts attached to the site's container (e.g. ~/.nix-profile, TEMP dirs)."`
-	Listeners          []string      `yaml:"listeners,omitempty" jsonschema:"description=Extra Traefik entrypoints (e.g. 'internal' for plain HTTP on :88)."`
-	Routes             []Route       `yaml:"routes,omitempty" jsonschema:"description=Extra Traefik routers (path-prefix / regex-rewrite splits)."`
+	SchemaVersion      int               `yaml:"schema_version,omitempty" jsonschema:"description=metadata.yml schema version (1 = current)."`
+	Type               SiteType          `yaml:"type" jsonschema:"enum=compose,enum=static,enum=dockerfile,description=Site runtime type."`
+	Domains            []string          `yaml:"domains,omitempty" jsonschema:"description=All hostnames; the first entry is canonical."`
+	ProjectPath        string            `yaml:"project_path" jsonschema:"description=Absolute path to the project on disk."`
+	ServiceName        string            `yaml:"service_name,omitempty" jsonschema:"description=Container name used for Traefik routing."`
+	ComposeServiceName string            `yaml:"compose_service_name,omitempty" jsonschema:"description=docker-compose service name (for compose commands)."`
+	Profile            string            `yaml:"profile,omitempty" jsonschema:"description=docker-compose profile (if the service uses profiles)."`
+	OverridePath       string            `yaml:"override_path,omitempty" jsonschema:"description=Compose override file layered over the project's compose file (compose sites)."`
+	Port               int               `yaml:"port" jsonschema:"description=Port the service listens on inside the container."`
+	IsLocal            bool              `yaml:"is_local" jsonschema:"description=Whether to use a locally-issued (mkcert) SSL certificate."`
+	CustomCert         bool              `yaml:"custom_cert,omitempty" jsonschema:"description=Serve a certificate imported with srv cert import instead of issuing one with mkcert (local sites)."`
+	Staging            bool              `yaml:"staging,omitempty" jsonschema:"description=Issue certificates from the Let's Encrypt staging CA (production sites only)."`
+	Wildcard           bool              `yaml:"wildcard,omitempty" jsonschema:"description=Match apex + one-level subdomains (*.example.com)."`
+	NetworkName        string            `yaml:"network_name" jsonschema:"description=Docker network the site joins."`
+	Tags               []string          `yaml:"tags,omitempty" jsonschema:"description=Labels grouping the site (srv tag), e.g. production; lowercase alphanumeric with hyphens."`
+	DependsOn          []string          `yaml:"depends_on,omitempty" jsonschema:"description=Sites started before this one and stopped after it (srv dependency)."`
+	ExtraNetworks      []string          `yaml:"extra_networks,omitempty" jsonschema:"description=Extra external Docker networks the site joins (for reaching user-managed containers like mysql01)."`
+	Volumes            []VolumeMount     `yaml:"volumes,omitempty" jsonschema:"description=Extra host bind-mounts attached to the site's container (e.g. ~/.nix-profile, TEMP dirs)."`
+	Listeners          []string          `yaml:"listeners,omitempty" jsonschema:"description=Extra Traefik entrypoints (e.g. 'internal' for plain HTTP on :88)."`
+	Routes             []Route           `yaml:"routes,omitempty" jsonschema:"description=Extra Traefik routers (path-prefix / regex-rewrite splits)."`
+	Middlewares        []string          `yaml:"middlewares,omitempty" jsonschema:"description=Traefik middlewares (defined in the dynamic config) appended to the site's router, in order."`
+	WebSocket          bool              `yaml:"websocket,omitempty" jsonschema:"description=Inject WebSocket upgrade headers and keep idle upstream connections open (compose sites)."`
+	AddHeaders         map[string]string `yaml:"add_headers,omitempty" jsonschema:"description=Response headers to set on every response (e.g. X-Robots-Tag: noindex)."`
+	RemoveHeaders      []string          `yaml:"remove_headers,omitempty" jsonschema:"description=Response headers to strip from every response (e.g. X-Powered-By)."`
+	AllowIPs           []string          `yaml:"allow_ips,omitempty" jsonschema:"description=Client CIDRs allowed to reach the site (e.g. 192.168.1.0/24); everyone else gets 403. Empty allows all."`
+	RateLimit          string            `yaml:"rate_limit,omitempty" jsonschema:"description=Per-client rate limit as AVERAGE-UNIT (unit S, M or H), e.g. 100-S for 100 requests a second."`
+	Compress           bool              `yaml:"compress,omitempty" jsonschema:"description=Compress responses at Traefik (compose and dockerfile sites; nginx compresses static sites)."`
+	// Body size limits, NUMBER+UNIT (KB, MB or GB), e.g. 10MB.
+	MaxRequestBodySize  string   `yaml:"max_request_body_size,omitempty" jsonschema:"description=Largest request body Traefik accepts, e.g. 10MB (units KB, MB, GB; 1KB to 10GB); larger requests get 413."`
+	MaxResponseBodySize string   `yaml:"max_response_body_size,omitempty" jsonschema:"description=Largest response body Traefik passes on, e.g. 50MB (units KB, MB, GB; 1KB to 10GB)."`
+	Preset              string   `yaml:"preset,omitempty" jsonschema:"description=Middleware preset the site was added with (srv preset); its settings are copied into this file."`
+	EntryPoints         []string `yaml:"entrypoints,omitempty" jsonschema:"description=Traefik entrypoints the site's router serves: websecure (HTTPS) and/or web (plain HTTP). Empty means websecure."`
+	NoHTTPSRedirect     bool     `yaml:"no_https_redirect,omitempty" jsonschema:"description=Also serve the site over plain HTTP on the web entrypoint instead of redirecting to HTTPS."`
+	// Path-based routing: the site only answers requests under PathPrefix.
+	PathPrefix  string `yaml:"path_prefix,omitempty" jsonschema:"description=Only route requests under this path (e.g. /api) to the site, so several sites can share a domain."`
+	StripPrefix bool   `yaml:"strip_prefix,omitempty" jsonschema:"description=Remove path_prefix from the request path before forwarding."`
+	// Retries of requests that fail to reach the container, e.g. while it starts.
+	RetryAttempts int    `yaml:"retry_attempts,omitempty" jsonschema:"description=Retry a request that fails to reach the container up to this many times (1-10), e.g. while it is still starting. 0 disables retries."`
+	RetryInterval string `yaml:"retry_interval,omitempty" jsonschema:"description=Initial back-off between retries as a Go duration (e.g. 100ms); empty uses Traefik's default."`
+	// Health check Traefik runs against the container (compose sites).
+	HealthCheckPath     string `yaml:"healthcheck_path,omitempty" jsonschema:"description=Path Traefik probes on the site's container (e.g. /health); a failing container stops getting traffic (compose sites)."`
+	HealthCheckInterval string `yaml:"healthcheck_interval,omitempty" jsonschema:"description=How often Traefik probes healthcheck_path, as a Go duration (e.g. 30s). Empty means 10s."`
+	// LastPulled is stamped by `srv pull`.
+	LastPulled time.Time `yaml:"last_pulled,omitempty" jsonschema:"description=When srv pull last pulled the site's images (set by srv)."`
+	// Disabled is set by `srv disable`.
+	Disabled bool `yaml:"disabled,omitempty" jsonschema:"description=Site is out of Traefik routing and won't start until srv enable (set by srv disable)."`
+	// Hooks run via `sh -c` from the project directory on every start.
+	PreStart  []string `yaml:"pre_start,omitempty" jsonschema:"description=Shell commands run from the project directory before the containers start; a failure aborts the start."`
+	PostStart []string `yaml:"post_start,omitempty" jsonschema:"description=Shell commands run from the project directory after the containers start."`
 	// Static site options
 	SPA   bool `yaml:"spa,omitempty" jsonschema:"description=Single-page-app mode (fall back to /index.html)."`
 	Cache bool `yaml:"cache,omitempty" jsonschema:"description=Emit aggressive caching headers for static assets."`
 	CORS  bool `yaml:"cors,omitempty" jsonschema:"description=Emit permissive CORS headers."`
+	// Brotli switches the static container to an nginx image with the brotli module.
+	Brotli bool `yaml:"brotli,omitempty" jsonschema:"description=Enable brotli compression (uses the fholzer/nginx-brotli image)."`
+	// DirectoryListing turns on nginx autoindex for directories without an index file.
+	DirectoryListing bool `yaml:"directory_listing,omitempty" jsonschema:"description=List directory contents when no index file exists (excludes spa)."`
+	// ErrorPagesPath is a host directory with 404.html (and optionally 50x.html),
+	// mounted into the container. A missing directory is skipped with a warning.
+	ErrorPagesPath string `yaml:"error_pages,omitempty" jsonschema:"description=Directory holding custom 404.html / 50x.html error pages for a static site."`
+	// NginxExtra is re-read on every reload, so edits to the snippet are
+	// picked up by the next `srv start`.
+	NginxExtra string `yaml:"nginx_extra,omitempty" jsonschema:"description=Path to an nginx snippet embedded verbatim in the static site's server block."`
 	// Dockerfile site options
 	DockerfilePort int `yaml:"dockerfile_port,omitempty" jsonschema:"description=Port discovered from the Dockerfile EXPOSE directive."`
 }
@@ -105,6 +151,19 @@ func (m *SiteMetadata) PrimaryDomain() string {
 	return m.Domains[0]
 }
 
+// ServedEntryPoints returns the entrypoints the site's router serves:
+// EntryPoints, or websecure when unset, plus web when NoHTTPSRedirect is set.
+func (m *SiteMetadata) ServedEntryPoints() []string {
+	eps := []string{constants.EntryPointWebsecure}
+	if len(m.EntryPoints) > 0 {
+		eps = append([]string(nil), m.EntryPoints...)
+	}
+	if m.NoHTTPSRedirect && !slices.Contains(eps, constants.EntryPointWeb) {
+		eps = append(eps, constants.EntryPointWeb)
+	}
+	return eps
+}
+
 // SiteConfigDir returns the path to a site's configuration directory.
 func SiteConfigDir(cfg *config.Config, name string) string {
 	return filepath.Join(cfg.SitesDir, name)
@@ -120,6 +179,12 @@ func SiteComposePath(cfg *config.Config, name string) string {
 	return filepath.Join(SiteConfigDir(cfg, name), constants.DockerComposeFile)
 }
 
+// StatsBaselinePath returns the path of the counters `srv stats --reset`
+// stored for a site.
+func StatsBaselinePath(cfg *config.Config, name string) string {
+	return filepath.Join(SiteConfigDir(cfg, name), constants.StatsBaselineFile)
+}
+
 // SiteNginxConfPath returns the path to a site's nginx.conf (for static sites).
 func SiteNginxConfPath(cfg *config.Config, name string) string {
 	return filepath.Join(SiteConfigDir(cfg, name), constants.NginxConfFile)
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/fsutil"
 )
 
 // withSRVRoot points SRV_ROOT at a fresh tempdir and resets the config cache.
@@ -148,3 +149,26 @@ func TestRemoveSiteMetadataReadOnlyParent(t *testing.T) {
 		t.Errorf("err: %v", err)
 	}
 }
+
+// TestWriteSiteMetadataCrashKeepsOriginal simulates a crash between the temp
+// write and the rename: the previous metadata must still read back.
+func TestWriteSiteMetadataCrashKeepsOriginal(t *testing.T) {
+	withSRVRoot(t)
+	if err := WriteSiteMetadata("blog", SiteMetadata{Type: SiteTypeStatic, Domains: []string{"blog.local"}}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(fsutil.SwapRename(func(string, string) error { panic("crash") }))
+	func() {
+		defer func() { _ = recover() }()
+		_ = WriteSiteMetadata("blog", SiteMetadata{Type: SiteTypeStatic, Domains: []string{"other.local"}})
+		t.Error("expected the simulated crash")
+	}()
+
+	got, err := ReadSiteMetadata("blog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.PrimaryDomain() != "blog.local" {
+		t.Errorf("domain = %q, want the original blog.local", got.PrimaryDomain())
+	}
+}
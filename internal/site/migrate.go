@@ -0,0 +1,103 @@
+// Package site — migrate.go upgrades metadata.yml files written by older srv
+// versions to CurrentMetadataSchema. Each breaking schema change bumps
+// CurrentMetadataSchema and registers one step in migrations.
+package site
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/stubbedev/srv/internal/config"
+)
+
+// metadataMigration upgrades metadata from fromVersion to fromVersion+1.
+type metadataMigration struct {
+	fromVersion int
+	migrate     func(*SiteMetadata) error
+}
+
+// migrations run in order, each upgrading one schema version.
+var migrations = []metadataMigration{
+	// Files written before schema_version existed. ReadSiteMetadata folds the
+	// scalar `domain:` key into Domains; stamping the version persists that.
+	{fromVersion: 0, migrate: func(meta *SiteMetadata) error {
+		if len(meta.Domains) == 0 {
+			return errors.New("no domain recorded")
+		}
+		return nil
+	}},
+}
+
+// MigrateMetadata applies the migrations meta needs, in place, and reports
+// whether any ran. Metadata from a newer srv is an error.
+func MigrateMetadata(meta *SiteMetadata) (bool, error) {
+	from := meta.SchemaVersion
+	if from > CurrentMetadataSchema {
+		return false, fmt.Errorf("schema version %d is newer than this srv supports (%d); upgrade srv", from, CurrentMetadataSchema)
+	}
+	for _, m := range migrations {
+		if m.fromVersion != meta.SchemaVersion {
+			continue
+		}
+		if err := m.migrate(meta); err != nil {
+			return false, fmt.Errorf("migrating from schema version %d: %w", m.fromVersion, err)
+		}
+		meta.SchemaVersion = m.fromVersion + 1
+	}
+	if meta.SchemaVersion != CurrentMetadataSchema {
+		return false, fmt.Errorf("no migration from schema version %d", meta.SchemaVersion)
+	}
+	return meta.SchemaVersion != from, nil
+}
+
+// SiteMigration reports a site whose metadata needed upgrading.
+type SiteMigration struct {
+	Name  string `json:"name"`
+	From  int    `json:"from"`
+	To    int    `json:"to"`
+	Error string `json:"error,omitempty"`
+}
+
+// MigrateSites upgrades every site's metadata.yml to CurrentMetadataSchema
+// and returns the sites that needed it, including those whose migration
+// failed (Error set). With check set nothing is written.
+func MigrateSites(check bool) ([]SiteMigration, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(cfg.SitesDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var results []SiteMigration
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), "_") {
+			continue
+		}
+		meta, err := ReadSiteMetadata(entry.Name())
+		if err != nil || meta == nil {
+			continue // broken sites are `srv clean`'s job
+		}
+		result := SiteMigration{Name: entry.Name(), From: meta.SchemaVersion, To: CurrentMetadataSchema}
+		migrated, err := MigrateMetadata(meta)
+		switch {
+		case err != nil:
+			result.Error = err.Error()
+		case !migrated:
+			continue
+		case !check:
+			if err := WriteSiteMetadata(entry.Name(), *meta); err != nil {
+				result.Error = err.Error()
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
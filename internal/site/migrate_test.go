@@ -0,0 +1,73 @@
+package site
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stubbedev/srv/internal/config"
+)
+
+// writeRawMetadata writes a site's metadata.yml verbatim, bypassing the
+// schema stamp WriteSiteMetadata adds.
+func writeRawMetadata(t *testing.T, name, content string) {
+	t.Helper()
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, SiteConfigDir(cfg, name), map[string]string{filepath.Base(metadataPath(cfg, name)): content})
+}
+
+func TestMigrateMetadata(t *testing.T) {
+	meta := &SiteMetadata{Domains: []string{"old.test"}}
+	migrated, err := MigrateMetadata(meta)
+	if err != nil || !migrated || meta.SchemaVersion != CurrentMetadataSchema {
+		t.Errorf("MigrateMetadata = %v, %v (schema %d)", migrated, err, meta.SchemaVersion)
+	}
+	if migrated, err := MigrateMetadata(meta); err != nil || migrated {
+		t.Errorf("current metadata: MigrateMetadata = %v, %v, want false, nil", migrated, err)
+	}
+	if _, err := MigrateMetadata(&SiteMetadata{}); err == nil {
+		t.Error("expected error for legacy metadata without a domain")
+	}
+	if _, err := MigrateMetadata(&SiteMetadata{SchemaVersion: CurrentMetadataSchema + 1}); err == nil || !strings.Contains(err.Error(), "upgrade srv") {
+		t.Errorf("err = %v, want newer-schema error", err)
+	}
+}
+
+func TestMigrateSites(t *testing.T) {
+	root := withSRVRoot(t)
+	writeRawMetadata(t, "old", "type: static\ndomain: old.test\nproject_path: "+root+"\n")
+	if err := WriteSiteMetadata("new", SiteMetadata{Type: SiteTypeStatic, Domains: []string{"new.test"}, ProjectPath: root}); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := MigrateSites(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Name != "old" || results[0].From != 0 || results[0].To != CurrentMetadataSchema {
+		t.Fatalf("check results = %+v", results)
+	}
+	cfg, _ := config.Load()
+	data, _ := os.ReadFile(metadataPath(cfg, "old"))
+	if strings.Contains(string(data), "schema_version") {
+		t.Error("--check rewrote metadata")
+	}
+
+	if _, err := MigrateSites(false); err != nil {
+		t.Fatal(err)
+	}
+	meta, err := ReadSiteMetadata("old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.SchemaVersion != CurrentMetadataSchema || strings.Join(meta.Domains, ",") != "old.test" {
+		t.Errorf("migrated metadata = %+v", meta)
+	}
+	if results, _ := MigrateSites(true); len(results) != 0 {
+		t.Errorf("after migrating, results = %+v", results)
+	}
+}
@@ -1,11 +1,12 @@
 // Package site — mutate.go holds headless metadata mutators (aliases, the
-// internal listener, volumes) shared by the `srv alias|internal|volume` CLI and
-// the MCP tools. Each reads metadata, edits it, writes it back, syncs the
+// internal listener, volumes, the IP allowlist, tags) shared by the
+// `srv alias|internal|volume|edit|tag` CLI and the MCP tools. Each reads metadata, edits it, writes it back, syncs the
 // derived DNS/cert/routing state, and returns non-fatal issues as warnings.
 package site
 
 import (
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 
@@ -18,24 +19,47 @@ import (
 
 // regenerateRouting rewrites a compose site's Traefik route config from its
 // current metadata. No-op for non-compose sites (static/dockerfile route config
-// is produced by Reload).
+// is produced by Reload) and for disabled ones, which `srv enable` reloads.
 func regenerateRouting(siteName string, meta *SiteMetadata) error {
-	if meta.Type != SiteTypeCompose {
+	if meta.Type != SiteTypeCompose || meta.Disabled {
 		return nil
 	}
 	cfg, err := config.Load()
 	if err != nil {
 		return err
 	}
-	return traefik.WriteSiteRouteConfig(cfg, traefik.SiteRouteConfig{
-		Name:        siteName,
-		Domains:     meta.Domains,
-		ServiceName: meta.ServiceName,
-		Port:        meta.Port,
-		IsLocal:     meta.IsLocal,
-		Wildcard:    meta.Wildcard,
-		Listeners:   meta.Listeners,
-	})
+	return traefik.WriteSiteRouteConfig(cfg, routeConfigFromMeta(siteName, meta))
+}
+
+// routeConfigFromMeta builds a compose site's Traefik route config from its
+// metadata.
+func routeConfigFromMeta(siteName string, meta *SiteMetadata) traefik.SiteRouteConfig {
+	return traefik.SiteRouteConfig{
+		Name:                siteName,
+		Domains:             meta.Domains,
+		ServiceName:         meta.ServiceName,
+		Port:                meta.Port,
+		IsLocal:             meta.IsLocal,
+		Staging:             meta.Staging,
+		Wildcard:            meta.Wildcard,
+		Listeners:           meta.Listeners,
+		Middlewares:         meta.Middlewares,
+		WebSocket:           meta.WebSocket,
+		AllowIPs:            meta.AllowIPs,
+		RateLimit:           meta.RateLimit,
+		Compress:            meta.Compress,
+		MaxRequestBody:      meta.MaxRequestBodySize,
+		MaxResponseBody:     meta.MaxResponseBodySize,
+		AddHeaders:          meta.AddHeaders,
+		RemoveHeaders:       meta.RemoveHeaders,
+		EntryPoints:         meta.ServedEntryPoints(),
+		HealthCheckPath:     meta.HealthCheckPath,
+		HealthCheckInterval: meta.HealthCheckInterval,
+		PathPrefix:          meta.PathPrefix,
+		StripPrefix:         meta.StripPrefix,
+		RetryAttempts:       meta.RetryAttempts,
+		RetryInterval:       meta.RetryInterval,
+	}
 }
 
 // refreshLocalCert re-issues a local site's cert to cover its current domain
@@ -48,6 +72,12 @@ func refreshLocalCert(siteName string, meta *SiteMetadata) (warnings []string) {
 			warnings = append(warnings, fmt.Sprintf("register DNS for %s: %v", d, err))
 		}
 	}
+	if meta.CustomCert {
+		if !traefik.LocalCertCovers(siteName, meta.Domains, meta.Wildcard) {
+			warnings = append(warnings, "the imported certificate does not cover every domain; import one that does with 'srv cert import'")
+		}
+		return warnings
+	}
 	if renewed, err := traefik.EnsureLocalCert(siteName, meta.Domains, meta.Wildcard); err != nil {
 		warnings = append(warnings, fmt.Sprintf("refresh certificate: %v", err))
 	} else if renewed {
@@ -172,6 +202,45 @@ func SetInternalListener(siteName string, enable bool) (changed bool, warnings [
 	return true, warnings, nil
 }
 
+// SetAllowIPs replaces a site's client IP allowlist; an empty list lets
+// everyone through again. Compose sites pick the change up from Traefik's file
+// provider; static and dockerfile sites carry it in container labels, so
+// needsRestart reports that the container must be restarted.
+func SetAllowIPs(siteName string, cidrs []string) (needsRestart bool, warnings []string, err error) {
+	if err := validate.CIDRs(cidrs); err != nil {
+		return false, nil, err
+	}
+	meta, err := requireMeta(siteName)
+	if err != nil {
+		return false, nil, err
+	}
+	meta.AllowIPs = cidrs
+	if err := WriteSiteMetadata(siteName, *meta); err != nil {
+		return false, nil, fmt.Errorf("write metadata: %w", err)
+	}
+	switch meta.Type {
+	case SiteTypeCompose:
+		if err := regenerateRouting(siteName, meta); err != nil {
+			warnings = append(warnings, fmt.Sprintf("refresh routing config: %v", err))
+		}
+		return false, warnings, nil
+	case SiteTypeDockerfile:
+		// Reload leaves dockerfile compose files alone, so rewrite the labels here.
+		port := meta.DockerfilePort
+		if port == 0 {
+			port = constants.DockerfileDefaultPort
+		}
+		if err := WriteDockerfileSiteConfig(siteName, *meta, &DockerfileSiteInfo{Port: port}, true); err != nil {
+			warnings = append(warnings, fmt.Sprintf("refresh site config: %v", err))
+		}
+	default:
+		if _, err := Reload(siteName); err != nil {
+			warnings = append(warnings, fmt.Sprintf("refresh site config: %v", err))
+		}
+	}
+	return true, warnings, nil
+}
+
 // AddVolume attaches an extra bind-mount to a site's container. Rejects a target
 // that collides with an existing mount or overlaps the project bind at /app.
 func AddVolume(siteName string, mount VolumeMount) (warnings []string, err error) {
@@ -286,3 +355,49 @@ func RemoveVolume(siteName, target string) (warnings []string, err error) {
 	}
 	return warnings, nil
 }
+
+// AddTags adds tags to a site, skipping ones it already has, and returns the
+// site's tags afterwards.
+func AddTags(siteName string, tags []string) ([]string, error) {
+	for _, tag := range tags {
+		if err := validate.Tag(tag); err != nil {
+			return nil, err
+		}
+	}
+	meta, err := requireMeta(siteName)
+	if err != nil {
+		return nil, err
+	}
+	before := len(meta.Tags)
+	for _, tag := range tags {
+		if !slices.Contains(meta.Tags, tag) {
+			meta.Tags = append(meta.Tags, tag)
+		}
+	}
+	if len(meta.Tags) == before {
+		return meta.Tags, nil
+	}
+	if err := WriteSiteMetadata(siteName, *meta); err != nil {
+		return nil, fmt.Errorf("write metadata: %w", err)
+	}
+	return meta.Tags, nil
+}
+
+// RemoveTags removes tags from a site and returns the site's tags afterwards.
+// Removing a tag the site doesn't have is an error.
+func RemoveTags(siteName string, tags []string) ([]string, error) {
+	meta, err := requireMeta(siteName)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range tags {
+		if !slices.Contains(meta.Tags, tag) {
+			return nil, fmt.Errorf("site %q has no tag %q", siteName, tag)
+		}
+	}
+	meta.Tags = slices.DeleteFunc(meta.Tags, func(t string) bool { return slices.Contains(tags, t) })
+	if err := WriteSiteMetadata(siteName, *meta); err != nil {
+		return nil, fmt.Errorf("write metadata: %w", err)
+	}
+	return meta.Tags, nil
+}
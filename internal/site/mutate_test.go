@@ -1,6 +1,15 @@
 package site
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/docker"
+	"github.com/stubbedev/srv/internal/traefik"
+)
 
 // seedSite writes a non-local static site so mutators exercise the
 // metadata/routing path without touching mkcert or DNS.
@@ -86,6 +95,80 @@ func TestSetInternalListener(t *testing.T) {
 	}
 }
 
+func TestAddRemoveTags(t *testing.T) {
+	withSRVRoot(t)
+	seedSite(t, "blog", []string{"blog.test"})
+
+	tags, err := AddTags("blog", []string{"production", "team-a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Idempotent: tags the site has are skipped.
+	if tags, err = AddTags("blog", []string{"production"}); err != nil || strings.Join(tags, ",") != "production,team-a" {
+		t.Errorf("re-add: tags=%v err=%v", tags, err)
+	}
+	if tags, err = RemoveTags("blog", []string{"production"}); err != nil || strings.Join(tags, ",") != "team-a" {
+		t.Errorf("remove: tags=%v err=%v", tags, err)
+	}
+	meta, _ := ReadSiteMetadata("blog")
+	if strings.Join(meta.Tags, ",") != "team-a" {
+		t.Errorf("stored tags = %v", meta.Tags)
+	}
+
+	// Negative: invalid tag, unknown tag, missing site.
+	if _, err := AddTags("blog", []string{"Prod"}); err == nil {
+		t.Error("expected error for an invalid tag")
+	}
+	if _, err := RemoveTags("blog", []string{"staging"}); err == nil {
+		t.Error("expected error removing a tag the site doesn't have")
+	}
+	if _, err := AddTags("ghost", []string{"x"}); err == nil {
+		t.Error("expected error for missing site")
+	}
+}
+
+func TestSetAllowIPs(t *testing.T) {
+	withSRVRoot(t)
+	seedSite(t, "blog", []string{"blog.test"})
+
+	needsRestart, _, err := SetAllowIPs("blog", []string{"192.168.1.0/24"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !needsRestart {
+		t.Error("static site should need a restart")
+	}
+	meta, _ := ReadSiteMetadata("blog")
+	if len(meta.AllowIPs) != 1 || meta.AllowIPs[0] != "192.168.1.0/24" {
+		t.Errorf("allow_ips = %v", meta.AllowIPs)
+	}
+	cfg, _ := config.Load()
+	compose, err := os.ReadFile(SiteComposePath(cfg, "blog"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(compose), "192.168.1.0/24") {
+		t.Error("compose labels missing the allow list")
+	}
+
+	// Clearing lifts the restriction.
+	if _, _, err := SetAllowIPs("blog", nil); err != nil {
+		t.Fatal(err)
+	}
+	meta, _ = ReadSiteMetadata("blog")
+	if len(meta.AllowIPs) != 0 {
+		t.Errorf("allow_ips = %v, want empty", meta.AllowIPs)
+	}
+
+	// Negative: bad CIDR and missing site.
+	if _, _, err := SetAllowIPs("blog", []string{"10.0.0.1"}); err == nil {
+		t.Error("expected error for a bare IP")
+	}
+	if _, _, err := SetAllowIPs("ghost", []string{"10.0.0.0/8"}); err == nil {
+		t.Error("expected error for missing site")
+	}
+}
+
 func TestRemoveSite(t *testing.T) {
 	withSRVRoot(t)
 	// A site whose project dir does not exist is "broken", so RemoveSite skips
@@ -113,6 +196,55 @@ func TestRemoveSite(t *testing.T) {
 	}
 }
 
+func TestRenameSite(t *testing.T) {
+	withSRVRoot(t)
+	// Broken (missing project dir) keeps the rename hermetic: no status probe
+	// and no Reload of the renamed site.
+	if err := WriteSiteMetadata("blog", SiteMetadata{
+		Type:        SiteTypeCompose,
+		Domains:     []string{"blog.test"},
+		ProjectPath: "/no/such/project/dir",
+		Port:        80,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, cfg.SiteCertsDir("blog"), map[string]string{"blog.test.crt": "cert"})
+	writeFiles(t, cfg.TraefikConfDir(), map[string]string{"site-blog.yml": "http: {}\n"})
+
+	if _, err := RenameSite("blog", "journal", false); err != nil {
+		t.Fatalf("RenameSite: %v", err)
+	}
+	if HasSiteMetadata("blog") {
+		t.Error("old metadata should be gone")
+	}
+	meta, _ := ReadSiteMetadata("journal")
+	if meta == nil || meta.PrimaryDomain() != "blog.test" {
+		t.Fatalf("renamed metadata = %+v", meta)
+	}
+	if _, err := os.Stat(filepath.Join(cfg.SiteCertsDir("journal"), "blog.test.crt")); err != nil {
+		t.Errorf("certs should move: %v", err)
+	}
+	if _, err := os.Stat(traefik.SiteRouteConfigPath(cfg, "journal")); err != nil {
+		t.Errorf("route config should move: %v", err)
+	}
+
+	// Negative: invalid target, existing target, missing source.
+	seedSite(t, "other", []string{"other.test"})
+	if _, err := RenameSite("journal", "bad name", false); err == nil {
+		t.Error("expected error for invalid name")
+	}
+	if _, err := RenameSite("journal", "other", false); err == nil {
+		t.Error("expected error when target exists")
+	}
+	if _, err := RenameSite("ghost", "spirit", false); err == nil {
+		t.Error("expected error for missing site")
+	}
+}
+
 func TestAddRemoveVolume(t *testing.T) {
 	withSRVRoot(t)
 	seedSite(t, "blog", []string{"blog.test"})
@@ -135,3 +267,78 @@ func TestAddRemoveVolume(t *testing.T) {
 		t.Error("expected error removing absent volume")
 	}
 }
+
+func TestMoveSite(t *testing.T) {
+	withSRVRoot(t)
+	t.Cleanup(docker.SwapNewClientErr(os.ErrNotExist)) // every container reads as stopped
+
+	// Static: any directory will do; the compose file gets the new bind mount.
+	newDir := t.TempDir()
+	if err := WriteSiteMetadata("docs", SiteMetadata{
+		Type:        SiteTypeStatic,
+		Domains:     []string{"docs.test"},
+		ProjectPath: "/no/such/old/dir",
+		ServiceName: "srv-docs",
+		Port:        80,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	restarted, _, err := MoveSite("docs", newDir)
+	if err != nil {
+		t.Fatalf("MoveSite(static): %v", err)
+	}
+	if restarted {
+		t.Error("a stopped site should not be restarted")
+	}
+	if meta, _ := ReadSiteMetadata("docs"); meta.ProjectPath != newDir {
+		t.Errorf("project_path = %q, want %q", meta.ProjectPath, newDir)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	compose, err := os.ReadFile(SiteComposePath(cfg, "docs"))
+	if err != nil || !strings.Contains(string(compose), newDir) {
+		t.Errorf("static compose file not rewritten with the new path (%v):\n%s", err, compose)
+	}
+	if s, err := GetByName("docs"); err != nil || s.IsBroken {
+		t.Errorf("moved site still broken: %+v, %v", s, err)
+	}
+
+	// Compose: the service must still exist; its container name follows the
+	// new directory name.
+	if err := os.MkdirAll(cfg.TraefikConfDir(), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	appDir := filepath.Join(t.TempDir(), "shop-v2")
+	writeFiles(t, appDir, map[string]string{"docker-compose.yml": "services:\n  web:\n    image: nginx\n"})
+	if err := WriteSiteMetadata("shop", SiteMetadata{
+		Type:               SiteTypeCompose,
+		Domains:            []string{"shop.test"},
+		ProjectPath:        "/no/such/shop",
+		ServiceName:        "shop-web-1",
+		ComposeServiceName: "web",
+		Port:               80,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := MoveSite("shop", appDir); err != nil {
+		t.Fatalf("MoveSite(compose): %v", err)
+	}
+	if meta, _ := ReadSiteMetadata("shop"); meta.ProjectPath != appDir || meta.ServiceName != "shop-v2-web-1" {
+		t.Errorf("compose metadata = %+v", meta)
+	}
+
+	// Negative: missing service, missing compose file, missing directory.
+	otherDir := t.TempDir()
+	writeFiles(t, otherDir, map[string]string{"docker-compose.yml": "services:\n  api:\n    image: nginx\n"})
+	if _, _, err := MoveSite("shop", otherDir); err == nil || !strings.Contains(err.Error(), `service "web" not found`) {
+		t.Errorf("expected missing-service error, got %v", err)
+	}
+	if _, _, err := MoveSite("shop", t.TempDir()); err == nil {
+		t.Error("expected error for a directory without a compose file")
+	}
+	if _, _, err := MoveSite("docs", "/no/such/new/dir"); err == nil {
+		t.Error("expected error for a missing directory")
+	}
+}
@@ -0,0 +1,229 @@
+// Package site — park.go discovers compose projects under parked directories
+// for `srv park` and `srv parked`. A parked directory is scanned ParkDepth
+// levels deep; every subdirectory holding a compose file is a project, and
+// the scan doesn't descend into a project once found. `srv list --parked`
+// also counts static sites (a directory with an index.html). The projects
+// the last scan saw are kept in parked.yml so `srv parked refresh` can report
+// what appeared or disappeared since.
+package site
+
+import (
+	"cmp"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/constants"
+	"github.com/stubbedev/srv/internal/fsutil"
+)
+
+// ParkedProject is a project found under a parked directory.
+type ParkedProject struct {
+	// Path is the project directory.
+	Path string `json:"path"`
+	// Name is the site name the project would be added under.
+	Name string `json:"name"`
+	// Type is "compose" or "static".
+	Type string `json:"type"`
+	// Parked is the parked directory the project was found in.
+	Parked string `json:"parked"`
+	// Site is the registered site serving Path; "" when unregistered.
+	Site string `json:"site,omitempty"`
+}
+
+// ParkDepth returns how many levels below a parked directory are scanned:
+// the park-depth setting, or constants.ParkDepth.
+func ParkDepth() int {
+	return cmp.Or(config.UserSettings().ParkDepth, constants.ParkDepth)
+}
+
+// DiscoverProjects returns the directories up to depth levels below dir that
+// contain a compose file, sorted. Hidden directories are skipped, and so are
+// subdirectories that can't be read.
+func DiscoverProjects(dir string, depth int) ([]string, error) {
+	return discover(dir, depth, hasComposeFile)
+}
+
+// DiscoverSites is DiscoverProjects that also finds static sites:
+// directories with an index.html.
+func DiscoverSites(dir string, depth int) ([]string, error) {
+	return discover(dir, depth, func(sub string) bool {
+		if hasComposeFile(sub) {
+			return true
+		}
+		info, err := os.Stat(filepath.Join(sub, staticIndexFile))
+		return err == nil && !info.IsDir()
+	})
+}
+
+// staticIndexFile marks a directory as a static site for DiscoverSites.
+const staticIndexFile = "index.html"
+
+func hasComposeFile(dir string) bool {
+	_, err := FindComposeFile(dir)
+	return err == nil
+}
+
+func discover(dir string, depth int, isProject func(string) bool) ([]string, error) {
+	var projects []string
+	if err := discoverProjects(dir, depth, isProject, &projects, true); err != nil {
+		return nil, err
+	}
+	slices.Sort(projects)
+	return projects, nil
+}
+
+func discoverProjects(dir string, depth int, isProject func(string) bool, projects *[]string, top bool) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if top {
+			return err
+		}
+		return nil
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		sub := filepath.Join(dir, entry.Name())
+		if isProject(sub) {
+			*projects = append(*projects, sub)
+			continue
+		}
+		if depth > 1 {
+			_ = discoverProjects(sub, depth-1, isProject, projects, false)
+		}
+	}
+	return nil
+}
+
+// RegisteredProjects maps each registered site's project directory to the
+// site's name.
+func RegisteredProjects() (map[string]string, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(cfg.SitesDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	projects := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), "_") {
+			continue
+		}
+		if meta, err := ReadSiteMetadata(entry.Name()); err == nil && meta != nil && meta.ProjectPath != "" {
+			projects[meta.ProjectPath] = entry.Name()
+		}
+	}
+	return projects, nil
+}
+
+// ScanParked discovers the compose projects under the parked directory dir
+// and marks the ones registered (a key of registered, as from
+// RegisteredProjects).
+func ScanParked(dir string, depth int, registered map[string]string) ([]ParkedProject, error) {
+	paths, err := DiscoverProjects(dir, depth)
+	if err != nil {
+		return nil, err
+	}
+	return parkedProjects(dir, paths, registered), nil
+}
+
+// ScanParkedSites is ScanParked counting static sites too (see DiscoverSites).
+func ScanParkedSites(dir string, depth int, registered map[string]string) ([]ParkedProject, error) {
+	paths, err := DiscoverSites(dir, depth)
+	if err != nil {
+		return nil, err
+	}
+	return parkedProjects(dir, paths, registered), nil
+}
+
+func parkedProjects(dir string, paths []string, registered map[string]string) []ParkedProject {
+	projects := make([]ParkedProject, 0, len(paths))
+	for _, p := range paths {
+		typ := string(SiteTypeStatic)
+		if hasComposeFile(p) {
+			typ = string(SiteTypeCompose)
+		}
+		projects = append(projects, ParkedProject{
+			Path:   p,
+			Name:   SanitizeName(p),
+			Type:   typ,
+			Parked: dir,
+			Site:   registered[p],
+		})
+	}
+	return projects
+}
+
+// parkedState is the parked.yml file.
+type parkedState struct {
+	Projects []string `yaml:"projects"`
+}
+
+func parkedStatePath() (string, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cfg.Root, constants.ParkedStateFile), nil
+}
+
+// LoadParkedProjects returns the project directories the last scan recorded;
+// none when nothing has been scanned yet.
+func LoadParkedProjects() ([]string, error) {
+	path, err := parkedStatePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var state parkedState
+	if err := yaml.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	return state.Projects, nil
+}
+
+// SaveParkedProjects records the project directories a scan found.
+func SaveParkedProjects(projects []string) error {
+	path, err := parkedStatePath()
+	if err != nil {
+		return err
+	}
+	state := parkedState{Projects: slices.Sorted(slices.Values(projects))}
+	data, err := yaml.Marshal(&state)
+	if err != nil {
+		return err
+	}
+	return fsutil.AtomicWriteFile(path, data, constants.FilePermDefault)
+}
+
+// DiffProjects returns the entries of current missing from previous (added)
+// and of previous missing from current (removed), each sorted.
+func DiffProjects(previous, current []string) (added, removed []string) {
+	for _, p := range current {
+		if !slices.Contains(previous, p) {
+			added = append(added, p)
+		}
+	}
+	for _, p := range previous {
+		if !slices.Contains(current, p) {
+			removed = append(removed, p)
+		}
+	}
+	slices.Sort(added)
+	slices.Sort(removed)
+	return added, removed
+}
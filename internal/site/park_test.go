@@ -0,0 +1,127 @@
+package site
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestDiscoverProjects(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"api/docker-compose.yml":           "services: {}\n",
+		"api/nested/compose.yml":           "services: {}\n",
+		"group/web/compose.yaml":           "services: {}\n",
+		"group/deeper/app/compose.yml":     "services: {}\n",
+		".hidden/docker-compose.yml":       "services: {}\n",
+		"notes/readme.md":                  "x\n",
+		"docker-compose.yml":               "services: {}\n",
+		"group/deeper/docs/readme.md":      "x\n",
+		"group/web/sub/docker-compose.yml": "services: {}\n",
+	})
+
+	tests := []struct {
+		depth int
+		want  []string
+	}{
+		{1, []string{"api"}},
+		{2, []string{"api", "group/web"}},
+		{3, []string{"api", "group/deeper/app", "group/web"}},
+	}
+	for _, tt := range tests {
+		got, err := DiscoverProjects(dir, tt.depth)
+		if err != nil {
+			t.Fatalf("DiscoverProjects(depth %d): %v", tt.depth, err)
+		}
+		want := make([]string, len(tt.want))
+		for i, rel := range tt.want {
+			want[i] = filepath.Join(dir, rel)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("depth %d: got %v, want %v", tt.depth, got, want)
+		}
+	}
+
+	if _, err := DiscoverProjects(filepath.Join(dir, "missing"), 1); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
+
+func TestDiscoverSitesFindsStaticSites(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"api/compose.yml":       "services: {}\n",
+		"docs/index.html":       "<h1>docs</h1>\n",
+		"notes/readme.md":       "x\n",
+		"site/index.html/x.txt": "not a file\n",
+	})
+	got, err := DiscoverSites(dir, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(dir, "api"), filepath.Join(dir, "docs")}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	projects := parkedProjects(dir, got, nil)
+	if projects[0].Type != "compose" || projects[1].Type != "static" {
+		t.Errorf("types = %q, %q; want compose, static", projects[0].Type, projects[1].Type)
+	}
+}
+
+func TestScanParkedMarksRegistered(t *testing.T) {
+	withSRVRoot(t)
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"Blog.App/docker-compose.yml": "services: {}\n",
+		"shop/compose.yml":            "services: {}\n",
+	})
+	if err := WriteSiteMetadata("shop", SiteMetadata{
+		Type:        SiteTypeCompose,
+		Domains:     []string{"shop.test"},
+		ProjectPath: filepath.Join(dir, "shop"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	registered, err := RegisteredProjects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	projects, err := ScanParked(dir, 1, registered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ParkedProject{
+		{Path: filepath.Join(dir, "Blog.App"), Name: "blog-app", Type: "compose", Parked: dir},
+		{Path: filepath.Join(dir, "shop"), Name: "shop", Type: "compose", Parked: dir, Site: "shop"},
+	}
+	if !slices.Equal(projects, want) {
+		t.Errorf("got %+v, want %+v", projects, want)
+	}
+}
+
+func TestParkedProjectsRoundTrip(t *testing.T) {
+	withSRVRoot(t)
+	got, err := LoadParkedProjects()
+	if err != nil || got != nil {
+		t.Fatalf("before any scan: got %v, %v; want nil, nil", got, err)
+	}
+	if err := SaveParkedProjects([]string{"/b", "/a"}); err != nil {
+		t.Fatal(err)
+	}
+	got, err = LoadParkedProjects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got, []string{"/a", "/b"}) {
+		t.Errorf("got %v, want [/a /b]", got)
+	}
+}
+
+func TestDiffProjects(t *testing.T) {
+	added, removed := DiffProjects([]string{"/a", "/b"}, []string{"/c", "/b"})
+	if !slices.Equal(added, []string{"/c"}) || !slices.Equal(removed, []string{"/a"}) {
+		t.Errorf("added %v, removed %v; want [/c], [/a]", added, removed)
+	}
+}
@@ -0,0 +1,104 @@
+// Package site — profile.go lists and switches the Docker Compose profile a
+// compose site runs under, shared by `srv profile` and `srv start --profile`.
+package site
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ComposeProfiles returns every profile declared across the services of a
+// compose site's compose file, sorted.
+func ComposeProfiles(siteName string) ([]string, error) {
+	services, err := composeServices(siteName)
+	if err != nil {
+		return nil, err
+	}
+	return profilesOf(services), nil
+}
+
+// CheckProfile validates profile for a compose site: it must be declared by
+// one of the site's services (or be "", for no profile). A profile that
+// leaves the routed service out is returned as a warning.
+func CheckProfile(siteName, profile string) (warnings []string, err error) {
+	services, err := composeServices(siteName)
+	if err != nil {
+		return nil, err
+	}
+	if profile == "" {
+		return nil, nil
+	}
+	profiles := profilesOf(services)
+	if !slices.Contains(profiles, profile) {
+		if len(profiles) == 0 {
+			return nil, fmt.Errorf("profile %q not found: the compose file declares no profiles", profile)
+		}
+		return nil, fmt.Errorf("profile %q not found (available: %s)", profile, strings.Join(profiles, ", "))
+	}
+
+	meta, err := requireMeta(siteName)
+	if err != nil {
+		return nil, err
+	}
+	for _, svc := range services {
+		if svc.ServiceName == meta.ComposeServiceName && len(svc.Profiles) > 0 && !slices.Contains(svc.Profiles, profile) {
+			warnings = append(warnings, fmt.Sprintf("service %s is not in profile %q and won't start with it", svc.ServiceName, profile))
+		}
+	}
+	return warnings, nil
+}
+
+// SetProfile records profile ("" for none) as the compose profile the site
+// starts with. Nothing generated depends on the profile, so it takes effect
+// on the next start.
+func SetProfile(siteName, profile string) (warnings []string, err error) {
+	warnings, err = CheckProfile(siteName, profile)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := requireMeta(siteName)
+	if err != nil {
+		return nil, err
+	}
+	meta.Profile = profile
+	if err := WriteSiteMetadata(siteName, *meta); err != nil {
+		return nil, fmt.Errorf("write metadata: %w", err)
+	}
+	return warnings, nil
+}
+
+// composeServices parses the services of a compose site's compose file.
+func composeServices(siteName string) ([]ServiceInfo, error) {
+	meta, err := requireMeta(siteName)
+	if err != nil {
+		return nil, err
+	}
+	if meta.Type != SiteTypeCompose {
+		return nil, fmt.Errorf("site %q is a %s site; only compose sites have profiles", siteName, meta.Type)
+	}
+	path, err := FindComposeFile(meta.ProjectPath)
+	if err != nil {
+		return nil, err
+	}
+	services, err := GetServiceInfos(path)
+	if err != nil {
+		return nil, fmt.Errorf("parse compose file: %w", err)
+	}
+	return services, nil
+}
+
+// profilesOf returns the distinct profiles of services, sorted.
+func profilesOf(services []ServiceInfo) []string {
+	var profiles []string
+	for _, svc := range services {
+		for _, p := range svc.Profiles {
+			if !slices.Contains(profiles, p) {
+				profiles = append(profiles, p)
+			}
+		}
+	}
+	sort.Strings(profiles)
+	return profiles
+}
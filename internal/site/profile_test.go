@@ -0,0 +1,83 @@
+package site
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// multiProfileCompose routes web, which runs under dev and debug; the other
+// services add profiles of their own.
+const multiProfileCompose = `services:
+  web:
+    build: .
+    profiles: [dev, debug]
+  worker:
+    build: .
+    profiles: [jobs, dev]
+  db:
+    image: postgres
+`
+
+func seedProfileSite(t *testing.T) {
+	t.Helper()
+	project := seedComposeSite(t, "app")
+	writeFiles(t, project, map[string]string{"docker-compose.yml": multiProfileCompose})
+}
+
+func TestComposeProfiles(t *testing.T) {
+	seedProfileSite(t)
+	got, err := ComposeProfiles("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"debug", "dev", "jobs"}; !slices.Equal(got, want) {
+		t.Errorf("profiles = %v, want %v", got, want)
+	}
+}
+
+func TestCheckProfile(t *testing.T) {
+	seedProfileSite(t)
+	if warnings, err := CheckProfile("app", "debug"); err != nil || len(warnings) != 0 {
+		t.Errorf("debug: warnings %v, err %v", warnings, err)
+	}
+	if warnings, err := CheckProfile("app", "jobs"); err != nil || len(warnings) != 1 || !strings.Contains(warnings[0], "web") {
+		t.Errorf("jobs: warnings %v, err %v; want a warning about web", warnings, err)
+	}
+	if _, err := CheckProfile("app", "prod"); err == nil || !strings.Contains(err.Error(), "debug, dev, jobs") {
+		t.Errorf("prod: err = %v, want the available profiles", err)
+	}
+	if _, err := CheckProfile("ghost", "dev"); err == nil {
+		t.Error("expected an error for an unknown site")
+	}
+}
+
+func TestSetProfile(t *testing.T) {
+	seedProfileSite(t)
+	if _, err := SetProfile("app", "debug"); err != nil {
+		t.Fatal(err)
+	}
+	meta, _ := ReadSiteMetadata("app")
+	if meta.Profile != "debug" {
+		t.Errorf("profile = %q, want debug", meta.Profile)
+	}
+	if _, err := SetProfile("app", "prod"); err == nil {
+		t.Error("expected an error for an undeclared profile")
+	}
+	if _, err := SetProfile("app", ""); err != nil {
+		t.Fatal(err)
+	}
+	if meta, _ := ReadSiteMetadata("app"); meta.Profile != "" {
+		t.Errorf("profile = %q after clearing", meta.Profile)
+	}
+}
+
+func TestComposeProfilesNonCompose(t *testing.T) {
+	withSRVRoot(t)
+	if err := WriteSiteMetadata("docs", SiteMetadata{Type: SiteTypeStatic, Domains: []string{"docs.test"}, ProjectPath: t.TempDir()}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ComposeProfiles("docs"); err == nil || !strings.Contains(err.Error(), "compose") {
+		t.Errorf("err = %v, want a compose-only error", err)
+	}
+}
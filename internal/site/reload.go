@@ -14,12 +14,16 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 
 	"github.com/stubbedev/srv/internal/config"
 	"github.com/stubbedev/srv/internal/constants"
+	"github.com/stubbedev/srv/internal/fsutil"
 	"github.com/stubbedev/srv/internal/traefik"
+	"github.com/stubbedev/srv/internal/validate"
 )
 
 // ReloadResult describes the work Reload performed for a single site.
@@ -47,10 +51,24 @@ func computeMetadataHash(meta *SiteMetadata) string {
 	// pulling in reflect.DeepEqual. The hash is stable as long as the YAML
 	// encoder produces deterministic output for our schema, which yaml.v3
 	// does for non-map fields (maps are stable when keys are strings).
-	data, err := yaml.Marshal(meta)
+	// last_pulled is bookkeeping; a pull alone changes nothing to regenerate.
+	hashed := *meta
+	hashed.LastPulled = time.Time{}
+	data, err := yaml.Marshal(&hashed)
 	if err != nil {
 		return ""
 	}
+	// The nginx snippet lives outside metadata.yml; fold its content in so an
+	// edit to the snippet alone still counts as a change.
+	if meta.NginxExtra != "" {
+		if extra, err := os.ReadFile(meta.NginxExtra); err == nil {
+			data = append(data, extra...)
+		}
+	}
+	// Likewise the error pages mount depends on whether the dir still exists.
+	if meta.ErrorPagesPath != "" && !ErrorPagesAvailable(meta.ErrorPagesPath) {
+		data = append(data, "error-pages-missing"...)
+	}
 	sum := sha256.Sum256(data)
 	return hex.EncodeToString(sum[:])
 }
@@ -64,7 +82,7 @@ func readLastReloadHash(cfg *config.Config, name string) string {
 }
 
 func writeLastReloadHash(cfg *config.Config, name, hash string) {
-	_ = os.WriteFile(reloadStatePath(cfg, name), []byte(hash), constants.FilePermDefault)
+	_ = fsutil.AtomicWriteFile(reloadStatePath(cfg, name), []byte(hash), constants.FilePermDefault)
 }
 
 // Reload reads the site's metadata.yml and re-applies every artifact derivable
@@ -98,6 +116,11 @@ func reload(name string, force bool) (*ReloadResult, error) {
 	}
 
 	res := &ReloadResult{Name: name}
+	// Reported even when the reload short-circuits: every start should say
+	// why the custom error pages aren't being served.
+	if meta.Type == SiteTypeStatic && meta.ErrorPagesPath != "" && !ErrorPagesAvailable(meta.ErrorPagesPath) {
+		res.Warnings = append(res.Warnings, fmt.Sprintf("error pages directory %s not found; serving default error pages", meta.ErrorPagesPath))
+	}
 
 	// Short-circuit when nothing changed since the last apply. Daemon-driven
 	// reloads on the same site fire repeatedly during editor saves; this is
@@ -116,33 +139,34 @@ func reload(name string, force bool) (*ReloadResult, error) {
 		if err := WriteStaticSiteConfig(name, *meta, true); err != nil {
 			return res, fmt.Errorf("regenerate static config: %w", err)
 		}
-		res.NeedsRestart = true
+		// A disabled site stays down until `srv enable`.
+		res.NeedsRestart = !meta.Disabled
 	case SiteTypeDockerfile:
 		// These have their own Write helpers; regenerating their compose
 		// file picks up label changes. Caller restarts the container.
 		// (Skipping explicit per-type re-write here keeps Reload type-agnostic;
 		// a future P-phase introduces a unified WriteSiteConfig dispatcher.)
-		res.NeedsRestart = true
+		res.NeedsRestart = !meta.Disabled
 	case SiteTypeCompose:
+		if meta.Disabled {
+			// `srv enable` regenerates the route config.
+			break
+		}
 		// Compose sites use the Traefik file provider. Refresh that file in place;
 		// no container restart needed for routing changes.
-		if err := traefik.WriteSiteRouteConfig(cfg, traefik.SiteRouteConfig{
-			Name:        name,
-			Domains:     meta.Domains,
-			ServiceName: meta.ServiceName,
-			Port:        meta.Port,
-			IsLocal:     meta.IsLocal,
-			Wildcard:    meta.Wildcard,
-			Listeners:   meta.Listeners,
-		}); err != nil {
+		if err := traefik.WriteSiteRouteConfig(cfg, routeConfigFromMeta(name, meta)); err != nil {
 			return res, fmt.Errorf("refresh traefik routing: %w", err)
 		}
 	}
 
 	// Always refresh the per-site extra-routes Traefik file (or remove it
 	// when meta has no routes). Picked up by Traefik's file provider with
-	// no container restart.
-	if err := traefik.WriteRoutesConfig(cfg, buildRouteSet(name, meta)); err != nil {
+	// no container restart. A disabled site's routing files stay parked.
+	if meta.Disabled {
+		if err := parkRouteFiles(cfg, name); err != nil {
+			res.Warnings = append(res.Warnings, fmt.Sprintf("routes: %v", err))
+		}
+	} else if err := traefik.WriteRoutesConfig(cfg, buildRouteSet(name, meta)); err != nil {
 		res.Warnings = append(res.Warnings, fmt.Sprintf("routes: %v", err))
 	}
 
@@ -156,7 +180,10 @@ func reload(name string, force bool) (*ReloadResult, error) {
 			}
 			res.DNSRegistered++
 		}
-		if err := traefik.CheckMkcert(); err == nil {
+		if meta.CustomCert {
+			// Imported certificates are the user's to renew.
+			res.CertCovered = true
+		} else if err := traefik.CheckMkcert(); err == nil {
 			renewed, certErr := traefik.EnsureLocalCert(name, meta.Domains, meta.Wildcard)
 			if certErr != nil {
 				res.Warnings = append(res.Warnings, fmt.Sprintf("cert: %v", certErr))
@@ -208,6 +235,66 @@ func ValidateMetadata(meta *SiteMetadata) error {
 			return fmt.Errorf("unknown listener %q (supported: %q)", l, constants.ListenerInternal)
 		}
 	}
+	seenEP := make(map[string]bool, len(meta.EntryPoints))
+	for _, ep := range meta.EntryPoints {
+		if ep != constants.EntryPointWeb && ep != constants.EntryPointWebsecure {
+			return fmt.Errorf("unknown entrypoint %q (supported: %q, %q)", ep, constants.EntryPointWeb, constants.EntryPointWebsecure)
+		}
+		if seenEP[ep] {
+			return fmt.Errorf("duplicate entrypoint %q", ep)
+		}
+		seenEP[ep] = true
+	}
+	if err := validate.Middlewares(meta.Middlewares); err != nil {
+		return err
+	}
+	if err := validate.ResponseHeaders(meta.AddHeaders, meta.RemoveHeaders); err != nil {
+		return err
+	}
+	if err := validate.CIDRs(meta.AllowIPs); err != nil {
+		return fmt.Errorf("`allow_ips`: %w", err)
+	}
+	if meta.RateLimit != "" {
+		if _, _, err := traefik.ParseRateLimit(meta.RateLimit); err != nil {
+			return fmt.Errorf("`rate_limit`: %w", err)
+		}
+	}
+	if meta.MaxRequestBodySize != "" {
+		if _, err := traefik.ParseBodySize(meta.MaxRequestBodySize); err != nil {
+			return fmt.Errorf("`max_request_body_size`: %w", err)
+		}
+	}
+	if meta.MaxResponseBodySize != "" {
+		if _, err := traefik.ParseBodySize(meta.MaxResponseBodySize); err != nil {
+			return fmt.Errorf("`max_response_body_size`: %w", err)
+		}
+	}
+	for _, tag := range meta.Tags {
+		if err := validate.Tag(tag); err != nil {
+			return fmt.Errorf("`tags`: %w", err)
+		}
+	}
+	for _, dep := range meta.DependsOn {
+		if err := validate.SiteName(dep); err != nil {
+			return fmt.Errorf("`depends_on`: %w", err)
+		}
+	}
+	if err := validateHealthCheck(meta.HealthCheckPath, meta.HealthCheckInterval); err != nil {
+		return err
+	}
+	if err := validateRetry(meta.RetryAttempts, meta.RetryInterval); err != nil {
+		return err
+	}
+	for _, h := range meta.PreStart {
+		if strings.TrimSpace(h) == "" {
+			return fmt.Errorf("`pre_start` contains an empty command")
+		}
+	}
+	for _, h := range meta.PostStart {
+		if strings.TrimSpace(h) == "" {
+			return fmt.Errorf("`post_start` contains an empty command")
+		}
+	}
 	for i, r := range meta.Routes {
 		if r.ID == "" {
 			return fmt.Errorf("route #%d has no id", i+1)
@@ -238,6 +325,43 @@ func ValidateMetadata(meta *SiteMetadata) error {
 	return nil
 }
 
+// validateHealthCheck checks a health check path and interval: the path is
+// absolute, and the interval, which needs a path, is a positive duration.
+func validateHealthCheck(path, interval string) error {
+	if path != "" && !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("health check path %q must start with /", path)
+	}
+	if interval == "" {
+		return nil
+	}
+	if path == "" {
+		return fmt.Errorf("a health check interval needs a health check path")
+	}
+	if d, err := time.ParseDuration(interval); err != nil || d <= 0 {
+		return fmt.Errorf("invalid health check interval %q (expected a duration like 10s)", interval)
+	}
+	return nil
+}
+
+// validateRetry checks a retry setting: 0 attempts disables retries,
+// otherwise 1 to MaxRetryAttempts, and the interval, which needs attempts, is
+// a positive duration.
+func validateRetry(attempts int, interval string) error {
+	if attempts < 0 || attempts > constants.MaxRetryAttempts {
+		return fmt.Errorf("retry attempts must be between 1 and %d, got %d", constants.MaxRetryAttempts, attempts)
+	}
+	if interval == "" {
+		return nil
+	}
+	if attempts == 0 {
+		return fmt.Errorf("a retry interval needs retry attempts")
+	}
+	if d, err := time.ParseDuration(interval); err != nil || d <= 0 {
+		return fmt.Errorf("invalid retry interval %q (expected a duration like 100ms)", interval)
+	}
+	return nil
+}
+
 var routeIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
 
 // buildRouteSet compiles metadata.Routes into the Traefik-facing RouteSpec
@@ -249,6 +373,7 @@ func buildRouteSet(siteName string, meta *SiteMetadata) traefik.SiteRouteSet {
 		Domains:  meta.Domains,
 		Wildcard: meta.Wildcard,
 		IsLocal:  meta.IsLocal,
+		Staging:  meta.Staging,
 	}
 	for _, r := range meta.Routes {
 		preserve := true
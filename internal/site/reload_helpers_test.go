@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stubbedev/srv/internal/config"
 )
@@ -48,6 +49,30 @@ func TestComputeMetadataHashDiffersOnChange(t *testing.T) {
 	}
 }
 
+func TestComputeMetadataHashIgnoresLastPulled(t *testing.T) {
+	meta := &SiteMetadata{Type: SiteTypeCompose, Domains: []string{"a.local"}}
+	a := computeMetadataHash(meta)
+	meta.LastPulled = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	if b := computeMetadataHash(meta); a != b {
+		t.Error("a pull alone should not force a reload")
+	}
+}
+
+func TestComputeMetadataHashTracksNginxExtra(t *testing.T) {
+	snippet := filepath.Join(t.TempDir(), "extra.conf")
+	if err := os.WriteFile(snippet, []byte("gzip off;\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	meta := &SiteMetadata{Type: SiteTypeStatic, Domains: []string{"x.local"}, NginxExtra: snippet}
+	a := computeMetadataHash(meta)
+	if err := os.WriteFile(snippet, []byte("gzip on;\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if computeMetadataHash(meta) == a {
+		t.Error("editing the nginx snippet should change the hash")
+	}
+}
+
 func TestReadWriteLastReloadHash(t *testing.T) {
 	cfg := newSiteCfg(t)
 	siteDir := SiteConfigDir(cfg, "blog")
@@ -39,6 +39,20 @@ func TestValidateMetadata(t *testing.T) {
 			name: "good listener",
 			meta: &SiteMetadata{Domains: []string{"a.test"}, Listeners: []string{constants.ListenerInternal}},
 		},
+		{
+			name:    "unknown entrypoint",
+			meta:    &SiteMetadata{Domains: []string{"a.test"}, EntryPoints: []string{"internal"}},
+			wantErr: "unknown entrypoint",
+		},
+		{
+			name:    "duplicate entrypoint",
+			meta:    &SiteMetadata{Domains: []string{"a.test"}, EntryPoints: []string{"web", "web"}},
+			wantErr: "duplicate entrypoint",
+		},
+		{
+			name: "http-only entrypoint",
+			meta: &SiteMetadata{Domains: []string{"a.test"}, EntryPoints: []string{constants.EntryPointWeb}},
+		},
 		{
 			name: "route missing id",
 			meta: &SiteMetadata{
@@ -18,6 +18,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -32,6 +34,9 @@ type Site struct {
 	Dir                string   // Resolved directory path (project directory)
 	Domains            []string // All hostnames; Domains[0] is canonical
 	IsLocal            bool     // Whether it uses local SSL
+	CustomCert         bool     // Whether the local cert was imported rather than issued by mkcert
+	Staging            bool     // Whether it uses the Let's Encrypt staging CA
+	EntryPoints        []string // Traefik entrypoints served (web, websecure)
 	Wildcard           bool     // Match apex + one-level subdomains
 	Type               SiteType // compose or static
 	IsBroken           bool     // Whether the project directory exists
@@ -41,6 +46,13 @@ type Site struct {
 	Profile            string   // Docker Compose profile (if service uses profiles)
 	Port               int      // Port (for compose sites)
 	ComposeDir         string   // Directory containing docker-compose.yml (may differ from Dir for static sites)
+	OverridePath       string   // Compose override file layered over the compose file (compose sites)
+	HealthCheckPath    string   // Path Traefik health-checks (compose sites); "" when none
+	Tags               []string // Labels grouping the site (srv tag)
+	DependsOn          []string // Sites started before this one (srv dependency)
+	Disabled           bool     // Taken out of routing with srv disable
+	PathPrefix         string   // Only requests under this path are routed; "" for the whole host
+	StripPrefix        bool     // PathPrefix is removed before forwarding
 }
 
 // Domain returns the canonical (first) hostname for the site, or "" if none.
@@ -51,6 +63,79 @@ func (s *Site) Domain() string {
 	return s.Domains[0]
 }
 
+// ServesHTTPS reports whether the site is served on the websecure
+// entrypoint. Sites without recorded entrypoints are.
+func (s *Site) ServesHTTPS() bool {
+	return len(s.EntryPoints) == 0 || slices.Contains(s.EntryPoints, constants.EntryPointWebsecure)
+}
+
+// ServesHTTP reports whether the site answers plain HTTP on the web
+// entrypoint rather than redirecting it to HTTPS.
+func (s *Site) ServesHTTP() bool {
+	return slices.Contains(s.EntryPoints, constants.EntryPointWeb)
+}
+
+// URL returns the site's canonical URL: https unless the site is HTTP-only.
+func (s *Site) URL() string {
+	if s.ServesHTTPS() {
+		return "https://" + s.Domain()
+	}
+	return "http://" + s.Domain()
+}
+
+// Rule returns the Traefik router rule the site is served under: its host
+// rule, narrowed to PathPrefix when one is set.
+func (s *Site) Rule() string {
+	return traefik.WithPathPrefix(traefik.BuildHostRule(s.Domains, s.Wildcard), s.PathPrefix)
+}
+
+// ComposeFiles returns the compose files to pass with -f: the project's
+// compose file then the override file set with --override. nil for sites
+// without one, so compose keeps its normal file lookup.
+func (s *Site) ComposeFiles() []string {
+	if s.Type != SiteTypeCompose || s.OverridePath == "" {
+		return nil
+	}
+	base, err := FindComposeFile(s.ComposeDir)
+	if err != nil {
+		return nil
+	}
+	return []string{base, s.OverridePath}
+}
+
+// PrimaryContainer returns the name of the container that serves the site's
+// traffic: the generated nginx container for static sites, the srv-built app
+// container for dockerfile sites, and the routed service's container for
+// compose sites. Returns "" when a compose site has no service recorded.
+func (s *Site) PrimaryContainer() string {
+	switch s.Type {
+	case SiteTypeStatic:
+		return generateStaticContainerName(s.Name)
+	case SiteTypeDockerfile:
+		if s.ServiceName != "" {
+			return s.ServiceName
+		}
+		return "srv-" + s.Name + "-app"
+	default:
+		return s.ServiceName
+	}
+}
+
+// Containers returns every container in the site's compose project. Falls
+// back to PrimaryContainer when Docker can't be queried or the project has
+// never been started.
+func (s *Site) Containers() []string {
+	if s.ComposeDir != "" {
+		if names, err := docker.ComposeContainerNames(s.ComposeDir); err == nil && len(names) > 0 {
+			return names
+		}
+	}
+	if c := s.PrimaryContainer(); c != "" {
+		return []string{c}
+	}
+	return nil
+}
+
 // loadSiteFromDir loads site information from a site config directory.
 // Returns the site and whether it needs a status check.
 func loadSiteFromDir(cfg *config.Config, entry os.DirEntry) (Site, bool) {
@@ -68,11 +153,21 @@ func loadSiteFromDir(cfg *config.Config, entry os.DirEntry) (Site, bool) {
 
 	s.Domains = append([]string(nil), meta.Domains...)
 	s.IsLocal = meta.IsLocal
+	s.CustomCert = meta.CustomCert
+	s.Staging = meta.Staging
+	s.EntryPoints = meta.ServedEntryPoints()
 	s.Wildcard = meta.Wildcard
 	s.Type = meta.Type
 	s.ServiceName = meta.ServiceName
 	s.ComposeServiceName = meta.ComposeServiceName
 	s.Profile = meta.Profile
+	s.OverridePath = meta.OverridePath
+	s.HealthCheckPath = meta.HealthCheckPath
+	s.Tags = meta.Tags
+	s.DependsOn = meta.DependsOn
+	s.Disabled = meta.Disabled
+	s.PathPrefix = meta.PathPrefix
+	s.StripPrefix = meta.StripPrefix
 	s.Port = meta.Port
 	s.Dir = meta.ProjectPath
 
@@ -171,20 +266,38 @@ func statusForIndex(sites []Site, i int) (status string) {
 // List returns all registered sites.
 // Container status checks are done in parallel for better performance.
 func List() ([]Site, error) {
-	cfg, err := config.Load()
+	sites, validSiteIndices, err := listSites()
 	if err != nil {
 		return nil, err
 	}
+	fetchSiteStatuses(sites, validSiteIndices)
+	return sites, nil
+}
+
+// ListWithoutStatus returns all registered sites from their metadata alone,
+// leaving Status empty. It makes no Docker calls, so it suits callers that
+// run often or only need names, domains or types.
+func ListWithoutStatus() ([]Site, error) {
+	sites, _, err := listSites()
+	return sites, err
+}
+
+// listSites loads every site config dir, returning the sites and the indices
+// of those healthy enough for a status check.
+func listSites() ([]Site, []int, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, err
+	}
 
 	entries, err := os.ReadDir(cfg.SitesDir)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return []Site{}, nil
+			return []Site{}, nil, nil
 		}
-		return nil, err
+		return nil, nil, err
 	}
 
-	// First pass: collect site metadata (fast, sequential)
 	var sites []Site
 	var validSiteIndices []int // Indices of sites that need status check
 
@@ -201,11 +314,7 @@ func List() ([]Site, error) {
 		}
 		sites = append(sites, site)
 	}
-
-	// Second pass: fetch container status in parallel
-	fetchSiteStatuses(sites, validSiteIndices)
-
-	return sites, nil
+	return sites, validSiteIndices, nil
 }
 
 // Get returns a specific site by name.
@@ -290,9 +399,15 @@ func ResolvePath(path string) (string, error) {
 	return realPath, nil
 }
 
-// IsLocalDomain checks if a domain should use local SSL.
+// IsLocalDomain checks if a domain should use local SSL: its TLD is one of the
+// built-in local TLDs or one the user added via `srv config set local-tlds`.
 func IsLocalDomain(domain string) bool {
-	for _, tld := range traefik.LocalDomains {
+	return isLocalDomainIn(domain, traefik.ActiveLocalTLDs())
+}
+
+// isLocalDomainIn reports whether domain ends in one of tlds.
+func isLocalDomainIn(domain string, tlds []string) bool {
+	for _, tld := range tlds {
 		if strings.HasSuffix(domain, "."+tld) {
 			return true
 		}
@@ -318,6 +433,44 @@ func Exists(name string) bool {
 	return HasSiteMetadata(name)
 }
 
+// AutoName is the site name that asks Add to pick a free one: the
+// domain-derived name, with a numeric suffix when that is taken.
+const AutoName = "auto"
+
+// UniqueName returns base when no site has that name, otherwise the first
+// free base-N (N >= 2) following the highest suffix already in use.
+func UniqueName(base string) string {
+	sites, _ := ListWithoutStatus()
+	names := make([]string, 0, len(sites))
+	for _, s := range sites {
+		names = append(names, s.Name)
+	}
+	return uniqueName(base, names, Exists)
+}
+
+// uniqueName is UniqueName over the given existing names, with taken as the
+// final check for each candidate.
+func uniqueName(base string, names []string, taken func(string) bool) string {
+	if !taken(base) {
+		return base
+	}
+	next := 2
+	for _, name := range names {
+		rest, ok := strings.CutPrefix(name, base+"-")
+		if !ok {
+			continue
+		}
+		if n, err := strconv.Atoi(rest); err == nil && n >= next {
+			next = n + 1
+		}
+	}
+	for ; ; next++ {
+		if candidate := fmt.Sprintf("%s-%d", base, next); !taken(candidate) {
+			return candidate
+		}
+	}
+}
+
 // generateStaticContainerName generates a container name for a static site.
 // Format: srv_static_<short_hash> where hash is derived from the site name.
 func generateStaticContainerName(name string) string {
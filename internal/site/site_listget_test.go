@@ -213,3 +213,32 @@ func TestGenerateStaticContainerName(t *testing.T) {
 		t.Error("different names should hash differently")
 	}
 }
+
+func TestListWithoutStatus(t *testing.T) {
+	root := withSRVRoot(t)
+	projectDir := filepath.Join(root, "project")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteSiteMetadata("blog", SiteMetadata{
+		Type:        SiteTypeStatic,
+		Domains:     []string{"blog.local"},
+		ProjectPath: projectDir,
+		Port:        80,
+		IsLocal:     true,
+		NetworkName: "n",
+		ServiceName: "blog-web",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	sites, err := ListWithoutStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sites) != 1 || sites[0].Name != "blog" || sites[0].ServiceName != "blog-web" {
+		t.Fatalf("got %+v", sites)
+	}
+	if sites[0].Status != "" {
+		t.Errorf("Status = %q, want it left empty (no Docker query)", sites[0].Status)
+	}
+}
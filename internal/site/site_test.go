@@ -5,10 +5,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/stubbedev/srv/internal/traefik"
 )
 
 func TestIsLocalDomain(t *testing.T) {
+	withSRVRoot(t)
 	tests := []struct {
 		domain   string
 		expected bool
@@ -34,6 +38,19 @@ func TestIsLocalDomain(t *testing.T) {
 	}
 }
 
+func TestIsLocalDomainInCustomTLDs(t *testing.T) {
+	tlds := traefik.MergeLocalTLDs([]string{"dev"})
+	if !isLocalDomainIn("myapp.dev", tlds) {
+		t.Error("custom TLD should be local")
+	}
+	if !isLocalDomainIn("myapp.test", tlds) {
+		t.Error("built-in TLDs must stay local")
+	}
+	if isLocalDomainIn("myapp.dev", traefik.LocalDomains) {
+		t.Error(".dev is not local without the custom TLD")
+	}
+}
+
 func TestSanitizeName(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -63,6 +80,37 @@ func TestSanitizeName(t *testing.T) {
 	}
 }
 
+func TestUniqueName(t *testing.T) {
+	existing := map[string]bool{"myapp": true, "myapp-2": true, "myapp-5": true, "myapp-x": true, "other": true}
+	names := []string{"myapp", "myapp-2", "myapp-5", "myapp-x", "other"}
+	var checked []string
+	taken := func(name string) bool {
+		checked = append(checked, name)
+		return existing[name]
+	}
+
+	if got := uniqueName("fresh", names, taken); got != "fresh" {
+		t.Errorf("free base: got %q", got)
+	}
+	// The suffix continues after the highest in use, not in the first gap.
+	if got := uniqueName("myapp", names, taken); got != "myapp-6" {
+		t.Errorf("collision: got %q, want myapp-6", got)
+	}
+	// A name taken but missing from the list (e.g. unreadable metadata) is
+	// still skipped.
+	existing["myapp-6"] = true
+	checked = nil
+	if got := uniqueName("myapp", names, taken); got != "myapp-7" {
+		t.Errorf("unlisted collision: got %q, want myapp-7", got)
+	}
+	if want := []string{"myapp", "myapp-6", "myapp-7"}; strings.Join(checked, ",") != strings.Join(want, ",") {
+		t.Errorf("checked %v, want %v", checked, want)
+	}
+	if got := uniqueName("other", names, taken); got != "other-2" {
+		t.Errorf("no suffixed names: got %q, want other-2", got)
+	}
+}
+
 func TestResolvePath(t *testing.T) {
 	// Test absolute path
 	t.Run("absolute path", func(t *testing.T) {
@@ -335,6 +383,35 @@ func TestSiteMetadata_PrimaryDomain(t *testing.T) {
 	}
 }
 
+func TestSiteMetadata_ServedEntryPoints(t *testing.T) {
+	tests := []struct {
+		meta SiteMetadata
+		want string
+	}{
+		{SiteMetadata{}, "websecure"},
+		{SiteMetadata{NoHTTPSRedirect: true}, "websecure,web"},
+		{SiteMetadata{EntryPoints: []string{"web"}}, "web"},
+		{SiteMetadata{EntryPoints: []string{"web"}, NoHTTPSRedirect: true}, "web"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(tt.meta.ServedEntryPoints(), ","); got != tt.want {
+			t.Errorf("ServedEntryPoints(%+v) = %q, want %q", tt.meta, got, tt.want)
+		}
+	}
+}
+
+func TestSite_URL(t *testing.T) {
+	if got := (&Site{Domains: []string{"x.test"}}).URL(); got != "https://x.test" {
+		t.Errorf("default URL = %q", got)
+	}
+	if got := (&Site{Domains: []string{"x.test"}, EntryPoints: []string{"web"}}).URL(); got != "http://x.test" {
+		t.Errorf("http-only URL = %q", got)
+	}
+	if got := (&Site{Domains: []string{"x.test"}, EntryPoints: []string{"websecure", "web"}}).URL(); got != "https://x.test" {
+		t.Errorf("no-redirect URL = %q", got)
+	}
+}
+
 func TestSite_Domain(t *testing.T) {
 	s := &Site{Domains: []string{"x.test", "y.test"}}
 	if got := s.Domain(); got != "x.test" {
@@ -7,21 +7,107 @@ package site
 import (
 	"fmt"
 	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 
 	"gopkg.in/yaml.v3"
 
 	"github.com/stubbedev/srv/internal/config"
 	"github.com/stubbedev/srv/internal/constants"
+	"github.com/stubbedev/srv/internal/fsutil"
 	"github.com/stubbedev/srv/internal/nginx"
 	"github.com/stubbedev/srv/internal/platform"
 	"github.com/stubbedev/srv/internal/traefik"
 )
 
 type StaticSiteOptions struct {
-	SPA   bool // Enable SPA mode (fallback to index.html)
-	Cache bool // Enable caching headers
-	CORS  bool // Enable CORS headers
+	SPA    bool // Enable SPA mode (fallback to index.html)
+	Cache  bool // Enable caching headers
+	CORS   bool // Enable CORS headers
+	Brotli bool // Enable brotli compression (requires ImageNginxBrotli)
+	// DirectoryListing enables nginx autoindex; overrides SPA.
+	DirectoryListing bool
+	// ErrorPages serves /errors/404.html and /errors/50x.html from the
+	// mounted error pages directory.
+	ErrorPages bool
+	Extra      string // User nginx snippet embedded verbatim in the server block
+	// Headers are added to every response with add_header.
+	Headers map[string]string
+}
+
+// Markers fencing the user snippet inside the generated server block.
+const (
+	nginxExtraBegin = "# BEGIN USER CONFIG"
+	nginxExtraEnd   = "# END USER CONFIG"
+)
+
+// nginxServerBlockPattern matches a `server {` opener, which a snippet must not
+// contain: it is already spliced inside the site's server block.
+var nginxServerBlockPattern = regexp.MustCompile(`(?m)(^|[\s;}])server\s*\{`)
+
+// ReadNginxExtra reads and validates a user nginx snippet file.
+func ReadNginxExtra(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read nginx snippet: %w", err)
+	}
+	content := string(data)
+	if nginxServerBlockPattern.MatchString(content) {
+		return "", fmt.Errorf("nginx snippet %s must not contain a server block; it is inserted inside the site's server { }", path)
+	}
+	return content, nil
+}
+
+// ValidateErrorPages checks that dir is a directory holding at least 404.html.
+func ValidateErrorPages(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("error pages directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("error pages path %s is not a directory", dir)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "404.html")); err != nil {
+		return fmt.Errorf("error pages directory %s must contain 404.html", dir)
+	}
+	return nil
+}
+
+// ErrorPagesAvailable reports whether a previously validated error pages
+// directory still exists.
+func ErrorPagesAvailable(dir string) bool {
+	info, err := os.Stat(dir)
+	return err == nil && info.IsDir()
+}
+
+// injectNginxExtra splices a user snippet, fenced by the BEGIN/END markers,
+// just before the closing brace of the rendered server block. The snippet is
+// user-authored nginx text, so it bypasses the typed nginx model on purpose.
+func injectNginxExtra(conf, extra string) string {
+	extra = strings.TrimRight(extra, "\n")
+	if strings.TrimSpace(extra) == "" {
+		return conf
+	}
+	end := strings.LastIndex(conf, "}")
+	if end < 0 {
+		return conf
+	}
+	var b strings.Builder
+	b.WriteString("\n    " + nginxExtraBegin + "\n")
+	for _, line := range strings.Split(extra, "\n") {
+		if strings.TrimSpace(line) == "" {
+			b.WriteString("\n")
+			continue
+		}
+		b.WriteString("    " + line + "\n")
+	}
+	b.WriteString("    " + nginxExtraEnd + "\n")
+	return conf[:end] + b.String() + conf[end:]
 }
 
 // denyLocation builds a `location <match> { deny all; return 404; }` block used
@@ -33,6 +119,13 @@ func denyLocation(comment string, match ...string) nginx.Directive {
 	).WithComment(comment)
 }
 
+// compressibleTypes are the MIME types gzip (and brotli, when enabled) compress.
+var compressibleTypes = []string{
+	"text/plain", "text/css", "text/xml", "text/javascript",
+	"application/javascript", "application/json", "application/xml",
+	"application/rss+xml", "application/atom+xml", "image/svg+xml",
+}
+
 // generateStaticNginxConf generates nginx configuration based on options.
 func generateStaticNginxConf(opts StaticSiteOptions) string {
 	body := []nginx.Directive{
@@ -44,14 +137,38 @@ func generateStaticNginxConf(opts StaticSiteOptions) string {
 		nginx.Dir("gzip", "on").WithComment("", "Gzip compression"),
 		nginx.Dir("gzip_vary", "on"),
 		nginx.Dir("gzip_min_length", "1024"),
-		nginx.Dir("gzip_types",
-			"text/plain", "text/css", "text/xml", "text/javascript",
-			"application/javascript", "application/json", "application/xml",
-			"application/rss+xml", "application/atom+xml", "image/svg+xml"),
+		nginx.Dir("gzip_types", compressibleTypes...),
+	}
 
+	// Brotli needs the module baked into ImageNginxBrotli; gzip stays on as
+	// the fallback for clients that don't send `Accept-Encoding: br`.
+	if opts.Brotli {
+		body = append(body,
+			nginx.Dir("brotli", "on").WithComment("", "Brotli compression"),
+			nginx.Dir("brotli_comp_level", "6"),
+			nginx.Dir("brotli_types", compressibleTypes...),
+		)
+	}
+
+	body = append(body,
 		nginx.Dir("add_header", "X-Frame-Options", `"SAMEORIGIN"`, "always").WithComment("", "Security headers"),
 		nginx.Dir("add_header", "X-Content-Type-Options", `"nosniff"`, "always"),
 		nginx.Dir("add_header", "X-XSS-Protection", `"1; mode=block"`, "always"),
+	)
+
+	if len(opts.Headers) > 0 {
+		names := make([]string, 0, len(opts.Headers))
+		for name := range opts.Headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for i, name := range names {
+			d := nginx.Dir("add_header", name, nginxQuote(opts.Headers[name]), "always")
+			if i == 0 {
+				d = d.WithComment("", "Custom headers")
+			}
+			body = append(body, d)
+		}
 	}
 
 	if opts.CORS {
@@ -76,15 +193,38 @@ func generateStaticNginxConf(opts StaticSiteOptions) string {
 		denyLocation("Block access to common sensitive directories", "~*", `^/(\.git|node_modules|vendor|\.svn|\.hg)/`),
 	)
 
-	tryFiles := nginx.Dir("try_files", "$uri", "$uri/", "=404")
-	if opts.SPA {
-		tryFiles = nginx.Dir("try_files", "$uri", "$uri/", "/index.html", "=404")
+	// Directory listing and SPA fallback are mutually exclusive: a listing
+	// needs unmatched directories to 404 through to autoindex, not index.html.
+	rootLoc := []nginx.Directive{nginx.Dir("try_files", "$uri", "$uri/", "=404")}
+	if opts.DirectoryListing {
+		rootLoc = append(rootLoc,
+			nginx.Dir("autoindex", "on"),
+			nginx.Dir("autoindex_exact_size", "off"),
+			nginx.Dir("autoindex_localtime", "on"),
+		)
+	} else if opts.SPA {
+		rootLoc[0] = nginx.Dir("try_files", "$uri", "$uri/", "/index.html", "=404")
 	}
 	body = append(body,
-		nginx.Block("location", []string{"/"}, tryFiles).WithComment("Serve static files"),
-		nginx.Dir("error_page", "404", "/404.html").WithComment("", "Custom 404 page"),
-		nginx.Block("location", []string{"=", "/404.html"}, nginx.Dir("internal")),
+		nginx.Block("location", []string{"/"}, rootLoc...).WithComment("Serve static files"),
 	)
+	if opts.ErrorPages {
+		// The error pages dir is mounted at NginxErrorPagesPath, i.e. /errors/
+		// under the parent root.
+		body = append(body,
+			nginx.Dir("error_page", "404", "/errors/404.html").WithComment("", "Custom error pages"),
+			nginx.Dir("error_page", "500", "502", "503", "504", "/errors/50x.html"),
+			nginx.Block("location", []string{"^~", "/errors/"},
+				nginx.Dir("root", path.Dir(constants.NginxErrorPagesPath)),
+				nginx.Dir("internal"),
+			),
+		)
+	} else {
+		body = append(body,
+			nginx.Dir("error_page", "404", "/404.html").WithComment("", "Custom 404 page"),
+			nginx.Block("location", []string{"=", "/404.html"}, nginx.Dir("internal")),
+		)
+	}
 
 	if opts.Cache {
 		body = append(body,
@@ -101,7 +241,7 @@ func generateStaticNginxConf(opts StaticSiteOptions) string {
 		)
 	}
 
-	return nginx.Render(
+	return injectNginxExtra(nginx.Render(
 		nginx.Block("server", nil, body...).WithComment(
 			"Generated by srv - static site nginx config",
 			`This file is yours to edit. "srv site regenerate" will reset it.`,
@@ -110,7 +250,12 @@ func generateStaticNginxConf(opts StaticSiteOptions) string {
 			"#",
 			"  client_max_body_size 100M;     # Increase max upload / request body size",
 		),
-	)
+	), opts.Extra)
+}
+
+// nginxQuote double-quotes s as an nginx string argument.
+func nginxQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
 }
 
 // =============================================================================
@@ -179,11 +324,20 @@ func buildTraefikLabels(name string, domains []string, isLocal, wildcard bool, p
 		fmt.Sprintf("traefik.http.services.%s.loadbalancer.server.port", name): fmt.Sprintf("%d", port),
 	}
 	if !isLocal {
-		labels[fmt.Sprintf("traefik.http.routers.%s.tls.certresolver", name)] = "letsencrypt"
+		labels[fmt.Sprintf("traefik.http.routers.%s.tls.certresolver", name)] = constants.CertResolverLetsEncrypt
 	}
 	return labels
 }
 
+// addStagingLabels points a production site's router at the Let's Encrypt
+// staging resolver. Local sites have no certresolver label and are untouched.
+func addStagingLabels(labels map[string]string, name string, staging bool) {
+	key := fmt.Sprintf("traefik.http.routers.%s.tls.certresolver", name)
+	if _, ok := labels[key]; ok && staging {
+		labels[key] = constants.CertResolverLetsEncryptStaging
+	}
+}
+
 // HasListener reports whether the supplied listener name is enabled on the
 // site. Comparison is case-insensitive.
 func HasListener(listeners []string, name string) bool {
@@ -205,6 +359,181 @@ func addInternalListenerLabels(labels map[string]string, name string, domains []
 	labels[fmt.Sprintf("traefik.http.routers.%s.service", router)] = name
 }
 
+// addRetryLabels defines a retry middleware reissuing requests that fail to
+// reach the container and puts it on the site's routers. Call after
+// addMiddlewareLabels and before addPathPrefixLabels.
+func addRetryLabels(labels map[string]string, name string, attempts int, interval string) {
+	if attempts <= 0 {
+		return
+	}
+	mw := name + "-retry"
+	labels[fmt.Sprintf("traefik.http.middlewares.%s.retry.attempts", mw)] = strconv.Itoa(attempts)
+	if interval != "" {
+		labels[fmt.Sprintf("traefik.http.middlewares.%s.retry.initialinterval", mw)] = interval
+	}
+	prependRouterMiddleware(labels, name, mw)
+}
+
+// addPathPrefixLabels narrows the site's routers to requests under prefix
+// and, with strip, defines a stripPrefix middleware that removes it before
+// forwarding. Call after addInternalListenerLabels and addMiddlewareLabels,
+// and before addCompressLabels.
+func addPathPrefixLabels(labels map[string]string, name, prefix string, strip bool) {
+	if prefix == "" {
+		return
+	}
+	for _, router := range []string{name, name + "-internal"} {
+		key := fmt.Sprintf("traefik.http.routers.%s.rule", router)
+		if rule, ok := labels[key]; ok {
+			labels[key] = traefik.WithPathPrefix(rule, prefix)
+		}
+	}
+	if !strip {
+		return
+	}
+	mw := name + "-stripprefix"
+	labels[fmt.Sprintf("traefik.http.middlewares.%s.stripprefix.prefixes", mw)] = prefix
+	prependRouterMiddleware(labels, name, mw)
+}
+
+// addMiddlewareLabels attaches the site's custom middlewares to its HTTPS
+// router. The middlewares live in Traefik's dynamic config, so each is
+// referenced with the @file provider suffix from the docker provider.
+func addMiddlewareLabels(labels map[string]string, name string, middlewares []string) {
+	if len(middlewares) == 0 {
+		return
+	}
+	refs := make([]string, len(middlewares))
+	for i, m := range middlewares {
+		refs[i] = m + "@file"
+	}
+	labels[fmt.Sprintf("traefik.http.routers.%s.middlewares", name)] = strings.Join(refs, ",")
+}
+
+// addAllowIPLabels defines an ipAllowList middleware for the site's client
+// CIDRs on the docker provider and puts it first on the site's routers, ahead
+// of any custom middlewares, so rejected clients get a 403 straight away.
+// Call after addMiddlewareLabels and addInternalListenerLabels.
+func addAllowIPLabels(labels map[string]string, name string, cidrs []string) {
+	if len(cidrs) == 0 {
+		return
+	}
+	mw := name + "-allowip"
+	labels[fmt.Sprintf("traefik.http.middlewares.%s.ipallowlist.sourcerange", mw)] = strings.Join(cidrs, ",")
+	prependRouterMiddleware(labels, name, mw)
+}
+
+// addHeaderLabels defines a headers middleware that sets and strips the
+// site's response headers and puts it on the site's routers. Setting a header
+// at Traefik replaces any value the container sent. Call before
+// addAllowIPLabels.
+func addHeaderLabels(labels map[string]string, name string, add map[string]string, remove []string) {
+	edits := traefik.ResponseHeaderEdits(add, remove)
+	if edits == nil {
+		return
+	}
+	mw := name + "-headers"
+	for header, value := range edits {
+		labels[fmt.Sprintf("traefik.http.middlewares.%s.headers.customresponseheaders.%s", mw, header)] = value
+	}
+	prependRouterMiddleware(labels, name, mw)
+}
+
+// addRateLimitLabels defines a rateLimit middleware for the site's
+// per-client rate limit (AVERAGE-UNIT, validated with the metadata) and puts
+// it on the site's routers. Call after addBufferingLabels and before
+// addAllowIPLabels.
+func addRateLimitLabels(labels map[string]string, name, spec string) {
+	if spec == "" {
+		return
+	}
+	average, period, err := traefik.ParseRateLimit(spec)
+	if err != nil {
+		return
+	}
+	mw := name + "-ratelimit"
+	labels[fmt.Sprintf("traefik.http.middlewares.%s.ratelimit.average", mw)] = strconv.Itoa(average)
+	labels[fmt.Sprintf("traefik.http.middlewares.%s.ratelimit.period", mw)] = period
+	prependRouterMiddleware(labels, name, mw)
+}
+
+// addBufferingLabels defines a buffering middleware capping the site's
+// request and response body sizes (validated with the metadata) and puts it
+// on the site's routers. Call after addHeaderLabels and before
+// addRateLimitLabels.
+func addBufferingLabels(labels map[string]string, name, maxRequest, maxResponse string) {
+	mw := name + "-buffering"
+	set := false
+	for _, limit := range []struct{ spec, key string }{
+		{maxRequest, "maxrequestbodybytes"},
+		{maxResponse, "maxresponsebodybytes"},
+	} {
+		if limit.spec == "" {
+			continue
+		}
+		size, err := traefik.ParseBodySize(limit.spec)
+		if err != nil {
+			continue
+		}
+		labels[fmt.Sprintf("traefik.http.middlewares.%s.buffering.%s", mw, limit.key)] = strconv.FormatInt(size, 10)
+		set = true
+	}
+	if set {
+		prependRouterMiddleware(labels, name, mw)
+	}
+}
+
+// addCompressLabels defines a compress middleware and puts it on the site's
+// routers, ahead of custom middlewares. Dockerfile sites only: static sites
+// are compressed by nginx. Call before addHeaderLabels.
+func addCompressLabels(labels map[string]string, name string, compress bool) {
+	if !compress {
+		return
+	}
+	mw := name + "-compress"
+	labels[fmt.Sprintf("traefik.http.middlewares.%s.compress", mw)] = "true"
+	prependRouterMiddleware(labels, name, mw)
+}
+
+// prependRouterMiddleware puts mw ahead of any middlewares already on the
+// site's HTTPS router and, when present, its internal router.
+func prependRouterMiddleware(labels map[string]string, name, mw string) {
+	for _, router := range []string{name, name + "-internal"} {
+		if _, ok := labels[fmt.Sprintf("traefik.http.routers.%s.rule", router)]; !ok {
+			continue
+		}
+		key := fmt.Sprintf("traefik.http.routers.%s.middlewares", router)
+		if existing := labels[key]; existing != "" {
+			labels[key] = mw + "," + existing
+		} else {
+			labels[key] = mw
+		}
+	}
+}
+
+// addEntryPointLabels moves the site onto the entrypoints it serves. Serving
+// web alone drops TLS from the site's router; serving both adds a
+// plain-HTTP router sharing its rule, service and middlewares. Call last,
+// after every label that edits the site's router.
+func addEntryPointLabels(labels map[string]string, name string, entryPoints []string) {
+	if !slices.Contains(entryPoints, constants.EntryPointWeb) {
+		return
+	}
+	if !slices.Contains(entryPoints, constants.EntryPointWebsecure) {
+		labels[fmt.Sprintf("traefik.http.routers.%s.entrypoints", name)] = constants.EntryPointWeb
+		delete(labels, fmt.Sprintf("traefik.http.routers.%s.tls", name))
+		delete(labels, fmt.Sprintf("traefik.http.routers.%s.tls.certresolver", name))
+		return
+	}
+	router := name + "-http"
+	labels[fmt.Sprintf("traefik.http.routers.%s.rule", router)] = labels[fmt.Sprintf("traefik.http.routers.%s.rule", name)]
+	labels[fmt.Sprintf("traefik.http.routers.%s.entrypoints", router)] = constants.EntryPointWeb
+	labels[fmt.Sprintf("traefik.http.routers.%s.service", router)] = name
+	if mws := labels[fmt.Sprintf("traefik.http.routers.%s.middlewares", name)]; mws != "" {
+		labels[fmt.Sprintf("traefik.http.routers.%s.middlewares", router)] = mws
+	}
+}
+
 // StampSrvLabels attaches the dev.srv.site / dev.srv.type identity labels onto
 // a container label map. Used by every site generator so `docker ps --filter
 // label=dev.srv.site=<name>` works uniformly.
@@ -214,31 +543,42 @@ func StampSrvLabels(labels map[string]string, siteName, siteType string) {
 }
 
 // buildStaticComposeConfig builds the docker-compose configuration for a static site.
-func buildStaticComposeConfig(project, containerName, projectPath, nginxConfPath, networkName string, labels map[string]string) composeFile {
+// image is ImageNginxAlpine, or ImageNginxBrotli when brotli is enabled;
+// errorPagesPath, when set, is bind-mounted at NginxErrorPagesPath.
+func buildStaticComposeConfig(project, containerName, image, projectPath, nginxConfPath, errorPagesPath, networkName string, labels map[string]string) composeFile {
+	volumes := []composeVolume{
+		{
+			Type:        "bind",
+			Source:      projectPath,
+			Target:      constants.NginxHTMLPath,
+			ReadOnly:    true,
+			Consistency: volumeConsistencyForHost(),
+		},
+		{
+			Type:     "bind",
+			Source:   nginxConfPath,
+			Target:   constants.NginxDefaultConfPath,
+			ReadOnly: true,
+		},
+	}
+	if errorPagesPath != "" {
+		volumes = append(volumes, composeVolume{
+			Type:     "bind",
+			Source:   errorPagesPath,
+			Target:   constants.NginxErrorPagesPath,
+			ReadOnly: true,
+		})
+	}
 	return composeFile{
 		Name: project,
 		Services: map[string]composeService{
 			"web": {
 				ContainerName: containerName,
-				Image:         constants.ImageNginxAlpine,
-				Volumes: []composeVolume{
-					{
-						Type:        "bind",
-						Source:      projectPath,
-						Target:      constants.NginxHTMLPath,
-						ReadOnly:    true,
-						Consistency: volumeConsistencyForHost(),
-					},
-					{
-						Type:     "bind",
-						Source:   nginxConfPath,
-						Target:   constants.NginxDefaultConfPath,
-						ReadOnly: true,
-					},
-				},
-				Labels:   labels,
-				Networks: []string{constants.TraefikSubdir},
-				Restart:  constants.RestartUnlessStopped,
+				Image:         image,
+				Volumes:       volumes,
+				Labels:        labels,
+				Networks:      []string{constants.TraefikSubdir},
+				Restart:       constants.RestartUnlessStopped,
 			},
 		},
 		Networks: map[string]composeNetwork{
@@ -258,7 +598,7 @@ func writeFile(path string, content []byte, force bool) error {
 			return nil // file exists — user may have customized it
 		}
 	}
-	return os.WriteFile(path, content, constants.FilePermDefault)
+	return fsutil.AtomicWriteFile(path, content, constants.FilePermDefault)
 }
 
 // WriteStaticSiteConfig writes the docker-compose.yml and nginx.conf for a static site.
@@ -275,10 +615,28 @@ func WriteStaticSiteConfig(name string, meta SiteMetadata, force bool) error {
 	}
 
 	// Generate and write nginx config
+	var extra string
+	if meta.NginxExtra != "" {
+		if extra, err = ReadNginxExtra(meta.NginxExtra); err != nil {
+			return err
+		}
+	}
+	// A vanished error pages dir must not break start: compose refuses a bind
+	// mount with a missing source, so fall back to the default pages (Reload
+	// reports the warning).
+	errorPages := meta.ErrorPagesPath
+	if errorPages != "" && !ErrorPagesAvailable(errorPages) {
+		errorPages = ""
+	}
 	nginxConf := generateStaticNginxConf(StaticSiteOptions{
-		SPA:   meta.SPA,
-		Cache: meta.Cache,
-		CORS:  meta.CORS,
+		SPA:              meta.SPA,
+		Cache:            meta.Cache,
+		CORS:             meta.CORS,
+		Brotli:           meta.Brotli,
+		DirectoryListing: meta.DirectoryListing,
+		ErrorPages:       errorPages != "",
+		Extra:            extra,
+		Headers:          meta.AddHeaders,
 	})
 	nginxConfPath := SiteNginxConfPath(cfg, name)
 	if err := writeFile(nginxConfPath, []byte(nginxConf), force); err != nil {
@@ -291,8 +649,22 @@ func WriteStaticSiteConfig(name string, meta SiteMetadata, force bool) error {
 	if HasListener(meta.Listeners, constants.ListenerInternal) {
 		addInternalListenerLabels(labels, name, meta.Domains, meta.Wildcard)
 	}
+	addStagingLabels(labels, name, meta.Staging)
+	addMiddlewareLabels(labels, name, meta.Middlewares)
+	addRetryLabels(labels, name, meta.RetryAttempts, meta.RetryInterval)
+	addPathPrefixLabels(labels, name, meta.PathPrefix, meta.StripPrefix)
+	// No compress middleware: nginx already gzips (and brotli-encodes).
+	addHeaderLabels(labels, name, meta.AddHeaders, meta.RemoveHeaders)
+	addBufferingLabels(labels, name, meta.MaxRequestBodySize, meta.MaxResponseBodySize)
+	addRateLimitLabels(labels, name, meta.RateLimit)
+	addAllowIPLabels(labels, name, meta.AllowIPs)
+	addEntryPointLabels(labels, name, meta.ServedEntryPoints())
 	StampSrvLabels(labels, name, string(meta.Type))
-	composeConfig := buildStaticComposeConfig(constants.ComposeProjectFor(name), containerName, meta.ProjectPath, nginxConfPath, meta.NetworkName, labels)
+	image := constants.ImageNginxAlpine
+	if meta.Brotli {
+		image = constants.ImageNginxBrotli
+	}
+	composeConfig := buildStaticComposeConfig(constants.ComposeProjectFor(name), containerName, image, meta.ProjectPath, nginxConfPath, errorPages, meta.NetworkName, labels)
 
 	data, err := yaml.Marshal(&composeConfig)
 	if err != nil {
@@ -1,6 +1,8 @@
 package site
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -35,6 +37,50 @@ func TestGenerateStaticNginxConfNoCORS(t *testing.T) {
 	}
 }
 
+func TestGenerateStaticNginxConfHeaders(t *testing.T) {
+	out := generateStaticNginxConf(StaticSiteOptions{Headers: map[string]string{
+		"X-Robots-Tag":  "noindex",
+		"X-Environment": `say "hi"`,
+	}})
+	if !strings.Contains(out, `add_header X-Robots-Tag "noindex" always;`) {
+		t.Errorf("X-Robots-Tag missing:\n%s", out)
+	}
+	if !strings.Contains(out, `add_header X-Environment "say \"hi\"" always;`) {
+		t.Errorf("quoted value not escaped:\n%s", out)
+	}
+	if strings.Index(out, "X-Environment") > strings.Index(out, "X-Robots-Tag") {
+		t.Error("custom headers should be sorted by name")
+	}
+}
+
+func TestGenerateStaticNginxConfDirectoryListing(t *testing.T) {
+	out := generateStaticNginxConf(StaticSiteOptions{SPA: true, DirectoryListing: true})
+	for _, want := range []string{"autoindex on;", "autoindex_exact_size off;", "autoindex_localtime on;", "try_files $uri $uri/ =404"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "/index.html =404") {
+		t.Error("SPA fallback should be disabled with directory listing")
+	}
+}
+
+func TestGenerateStaticNginxConfBrotli(t *testing.T) {
+	out := generateStaticNginxConf(StaticSiteOptions{Brotli: true})
+	for _, want := range []string{"brotli on;", "brotli_comp_level 6;", "brotli_types text/plain", "gzip on;"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestGenerateStaticNginxConfNoBrotli(t *testing.T) {
+	out := generateStaticNginxConf(StaticSiteOptions{})
+	if strings.Contains(out, "brotli") {
+		t.Error("brotli directives should be absent")
+	}
+}
+
 func TestGenerateStaticNginxConfCacheOn(t *testing.T) {
 	out := generateStaticNginxConf(StaticSiteOptions{Cache: true})
 	if !strings.Contains(out, `Cache-Control "public, immutable"`) {
@@ -49,6 +95,63 @@ func TestGenerateStaticNginxConfCacheOff(t *testing.T) {
 	}
 }
 
+func TestGenerateStaticNginxConfExtra(t *testing.T) {
+	out := generateStaticNginxConf(StaticSiteOptions{Extra: "client_max_body_size 100M;\nlocation /api { return 204; }\n"})
+	begin := strings.Index(out, "# BEGIN USER CONFIG")
+	end := strings.Index(out, "# END USER CONFIG")
+	if begin < 0 || end < begin {
+		t.Fatalf("user config markers missing:\n%s", out)
+	}
+	if !strings.Contains(out[begin:end], "client_max_body_size 100M;") {
+		t.Error("snippet not embedded between markers")
+	}
+	// The snippet must sit inside the server block: only the block's own
+	// closing brace may follow the END marker.
+	if tail := strings.TrimSpace(out[end+len("# END USER CONFIG"):]); tail != "}" {
+		t.Errorf("snippet not inside the server block, trailing %q", tail)
+	}
+	if strings.Contains(generateStaticNginxConf(StaticSiteOptions{}), "USER CONFIG") {
+		t.Error("markers should be absent without a snippet")
+	}
+}
+
+func TestReadNginxExtra(t *testing.T) {
+	dir := t.TempDir()
+	ok := filepath.Join(dir, "ok.conf")
+	bad := filepath.Join(dir, "bad.conf")
+	if err := os.WriteFile(ok, []byte("gzip_comp_level 6;\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(bad, []byte("server {\n  listen 8080;\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ReadNginxExtra(ok); err != nil || got != "gzip_comp_level 6;\n" {
+		t.Errorf("ReadNginxExtra(ok) = %q, %v", got, err)
+	}
+	if _, err := ReadNginxExtra(bad); err == nil {
+		t.Error("expected error for snippet containing a server block")
+	}
+	if _, err := ReadNginxExtra(filepath.Join(dir, "missing.conf")); err == nil {
+		t.Error("expected error for missing snippet")
+	}
+}
+
+func TestValidateErrorPages(t *testing.T) {
+	dir := t.TempDir()
+	if err := ValidateErrorPages(dir); err == nil {
+		t.Error("expected error for dir without 404.html")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "404.html"), []byte("nope"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateErrorPages(dir); err != nil {
+		t.Errorf("ValidateErrorPages: %v", err)
+	}
+	if err := ValidateErrorPages(filepath.Join(dir, "404.html")); err == nil {
+		t.Error("expected error for a file path")
+	}
+}
+
 func TestVolumeConsistencyForHost(t *testing.T) {
 	v := volumeConsistencyForHost()
 	// We can't change runtime.GOOS in a test; just verify it returns either
@@ -85,9 +188,150 @@ func TestBuildTraefikLabels(t *testing.T) {
 	if labels["traefik.http.routers.blog.tls.certresolver"] != "letsencrypt" {
 		t.Error("non-local should have letsencrypt resolver")
 	}
+	addStagingLabels(labels, "blog", true)
+	if labels["traefik.http.routers.blog.tls.certresolver"] != "letsencrypt-staging" {
+		t.Error("staging should switch to the letsencrypt-staging resolver")
+	}
 
 	labels = buildTraefikLabels("api", []string{"api.test"}, true, false, 8080)
 	if labels["traefik.http.services.api.loadbalancer.server.port"] != "8080" {
 		t.Error("custom port should win")
 	}
 }
+
+func TestAddAllowIPLabels(t *testing.T) {
+	labels := buildTraefikLabels("blog", []string{"blog.test"}, true, false, 80)
+	addInternalListenerLabels(labels, "blog", []string{"blog.test"}, false)
+	addMiddlewareLabels(labels, "blog", []string{"compress"})
+	addAllowIPLabels(labels, "blog", []string{"192.168.1.0/24", "10.0.0.0/8"})
+
+	if got := labels["traefik.http.middlewares.blog-allowip.ipallowlist.sourcerange"]; got != "192.168.1.0/24,10.0.0.0/8" {
+		t.Errorf("sourcerange = %q", got)
+	}
+	if got := labels["traefik.http.routers.blog.middlewares"]; got != "blog-allowip,compress@file" {
+		t.Errorf("router middlewares = %q, want allowlist first", got)
+	}
+	if got := labels["traefik.http.routers.blog-internal.middlewares"]; got != "blog-allowip" {
+		t.Errorf("internal router middlewares = %q", got)
+	}
+
+	labels = buildTraefikLabels("api", []string{"api.test"}, true, false, 80)
+	addAllowIPLabels(labels, "api", nil)
+	for k := range labels {
+		if strings.Contains(k, "middlewares") {
+			t.Errorf("unexpected label %q without allow list", k)
+		}
+	}
+}
+
+func TestAddPathPrefixLabels(t *testing.T) {
+	labels := buildTraefikLabels("api", []string{"app.test"}, true, false, 80)
+	addInternalListenerLabels(labels, "api", []string{"app.test"}, false)
+	addMiddlewareLabels(labels, "api", []string{"auth"})
+	addPathPrefixLabels(labels, "api", "/api", true)
+
+	for _, router := range []string{"api", "api-internal"} {
+		if got := labels["traefik.http.routers."+router+".rule"]; got != "(Host(`app.test`)) && PathPrefix(`/api`)" {
+			t.Errorf("%s rule = %q", router, got)
+		}
+	}
+	if got := labels["traefik.http.middlewares.api-stripprefix.stripprefix.prefixes"]; got != "/api" {
+		t.Errorf("stripprefix prefixes = %q", got)
+	}
+	if got := labels["traefik.http.routers.api.middlewares"]; got != "api-stripprefix,auth@file" {
+		t.Errorf("router middlewares = %q", got)
+	}
+
+	labels = buildTraefikLabels("api", []string{"app.test"}, true, false, 80)
+	addPathPrefixLabels(labels, "api", "/api", false)
+	if _, ok := labels["traefik.http.routers.api.middlewares"]; ok {
+		t.Error("unexpected middlewares without --strip-prefix")
+	}
+}
+
+func TestAddRetryLabels(t *testing.T) {
+	labels := buildTraefikLabels("app", []string{"app.test"}, true, false, 80)
+	addMiddlewareLabels(labels, "app", []string{"auth"})
+	addRetryLabels(labels, "app", 3, "250ms")
+	addPathPrefixLabels(labels, "app", "/app", true)
+
+	if got := labels["traefik.http.middlewares.app-retry.retry.attempts"]; got != "3" {
+		t.Errorf("retry attempts = %q", got)
+	}
+	if got := labels["traefik.http.middlewares.app-retry.retry.initialinterval"]; got != "250ms" {
+		t.Errorf("retry initial interval = %q", got)
+	}
+	if got := labels["traefik.http.routers.app.middlewares"]; got != "app-stripprefix,app-retry,auth@file" {
+		t.Errorf("router middlewares = %q", got)
+	}
+}
+
+func TestAddHeaderLabels(t *testing.T) {
+	labels := buildTraefikLabels("blog", []string{"blog.test"}, true, false, 80)
+	addMiddlewareLabels(labels, "blog", []string{"compress"})
+	addHeaderLabels(labels, "blog", map[string]string{"X-Environment": "staging"}, []string{"X-Powered-By"})
+	addAllowIPLabels(labels, "blog", []string{"10.0.0.0/8"})
+
+	if got := labels["traefik.http.middlewares.blog-headers.headers.customresponseheaders.X-Environment"]; got != "staging" {
+		t.Errorf("X-Environment = %q", got)
+	}
+	if got, ok := labels["traefik.http.middlewares.blog-headers.headers.customresponseheaders.X-Powered-By"]; !ok || got != "" {
+		t.Errorf("X-Powered-By = %q (present %v), want empty to strip it", got, ok)
+	}
+	if got := labels["traefik.http.routers.blog.middlewares"]; got != "blog-allowip,blog-headers,compress@file" {
+		t.Errorf("router middlewares = %q", got)
+	}
+}
+
+func TestAddBufferingLabels(t *testing.T) {
+	labels := buildTraefikLabels("blog", []string{"blog.test"}, true, false, 80)
+	addBufferingLabels(labels, "blog", "", "")
+	if _, ok := labels["traefik.http.routers.blog.middlewares"]; ok {
+		t.Errorf("middlewares set without limits: %v", labels)
+	}
+	addBufferingLabels(labels, "blog", "2KB", "")
+	if got := labels["traefik.http.middlewares.blog-buffering.buffering.maxrequestbodybytes"]; got != "2048" {
+		t.Errorf("maxrequestbodybytes = %q", got)
+	}
+	if _, ok := labels["traefik.http.middlewares.blog-buffering.buffering.maxresponsebodybytes"]; ok {
+		t.Error("maxresponsebodybytes set without a response limit")
+	}
+	if got := labels["traefik.http.routers.blog.middlewares"]; got != "blog-buffering" {
+		t.Errorf("router middlewares = %q", got)
+	}
+}
+
+func TestAddEntryPointLabels(t *testing.T) {
+	labels := buildTraefikLabels("blog", []string{"blog.test"}, false, false, 80)
+	addEntryPointLabels(labels, "blog", []string{"web"})
+	if got := labels["traefik.http.routers.blog.entrypoints"]; got != "web" {
+		t.Errorf("entrypoints = %q, want web", got)
+	}
+	for _, k := range []string{"traefik.http.routers.blog.tls", "traefik.http.routers.blog.tls.certresolver"} {
+		if _, ok := labels[k]; ok {
+			t.Errorf("http-only site still has %s", k)
+		}
+	}
+
+	labels = buildTraefikLabels("blog", []string{"blog.test"}, true, false, 80)
+	addAllowIPLabels(labels, "blog", []string{"10.0.0.0/8"})
+	addEntryPointLabels(labels, "blog", []string{"websecure", "web"})
+	if got := labels["traefik.http.routers.blog.tls"]; got != "true" {
+		t.Errorf("https router tls = %q", got)
+	}
+	if got := labels["traefik.http.routers.blog-http.entrypoints"]; got != "web" {
+		t.Errorf("http router entrypoints = %q", got)
+	}
+	if got := labels["traefik.http.routers.blog-http.rule"]; got != labels["traefik.http.routers.blog.rule"] {
+		t.Errorf("http router rule = %q", got)
+	}
+	if got := labels["traefik.http.routers.blog-http.middlewares"]; got != "blog-allowip" {
+		t.Errorf("http router middlewares = %q", got)
+	}
+
+	labels = buildTraefikLabels("api", []string{"api.test"}, true, false, 80)
+	addEntryPointLabels(labels, "api", []string{"websecure"})
+	if _, ok := labels["traefik.http.routers.api-http.rule"]; ok || labels["traefik.http.routers.api.entrypoints"] != "websecure" {
+		t.Errorf("https-only labels changed: %v", labels)
+	}
+}
@@ -0,0 +1,153 @@
+// Package site — view.go defines SiteView, the stable JSON shape of a site
+// that `srv list --json` and `srv info SITE --json` print. Field names are
+// part of srv's scripting interface: add fields, but don't rename or remove
+// them.
+package site
+
+import (
+	"github.com/stubbedev/srv/internal/constants"
+	"github.com/stubbedev/srv/internal/traefik"
+)
+
+// SiteView is the machine-readable form of a Site.
+type SiteView struct {
+	// Name is the site's name (its directory under sites/).
+	Name string `json:"name"`
+	// Domains lists every hostname; Domains[0] is canonical.
+	Domains []string `json:"domains"`
+	// Target is the project directory.
+	Target string `json:"target"`
+	// Type is "compose", "static" or "dockerfile"; "" for broken sites.
+	Type string `json:"type"`
+	// SSL is "http", "auto", "staging" or a local cert status ("valid",
+	// "expiring", "expired", "missing", "corrupt"), with "+http" appended
+	// when plain HTTP is served too; "" for broken sites and parked projects.
+	SSL string `json:"ssl"`
+	// Status is the container status, "broken", "disabled" for a site taken
+	// out of routing (`srv disable`), or "parked" for a project in a parked
+	// directory that isn't registered (`srv list --parked`).
+	Status string `json:"status"`
+	// Local is true for sites using mkcert certificates.
+	Local bool `json:"local"`
+	// CustomCert is true when the local certificate was imported with
+	// `srv cert import` instead of issued by mkcert.
+	CustomCert bool `json:"custom_cert"`
+	// Broken is true when the project directory is missing.
+	Broken bool `json:"broken"`
+	// Disabled is true for a site taken out of routing with `srv disable`.
+	Disabled bool `json:"disabled"`
+	// Staging is true for sites using the Let's Encrypt staging CA.
+	Staging bool `json:"staging"`
+	// EntryPoints lists the Traefik entrypoints served (web, websecure).
+	EntryPoints []string `json:"entry_points"`
+	// Wildcard is true when the site also matches one-level subdomains.
+	Wildcard bool `json:"wildcard"`
+	// PathPrefix limits the site to requests under this path; "" routes
+	// the whole host.
+	PathPrefix string `json:"path_prefix,omitempty"`
+	// StripPrefix is true when PathPrefix is removed before forwarding.
+	StripPrefix bool `json:"strip_prefix,omitempty"`
+	// Rule is the Traefik router rule the site is served under.
+	Rule string `json:"rule,omitempty"`
+	// Service is the container Traefik routes to.
+	Service string `json:"service,omitempty"`
+	// ComposeService is the Docker Compose service name.
+	ComposeService string `json:"compose_service,omitempty"`
+	// Profile is the Docker Compose profile, if the service uses one.
+	Profile string `json:"profile,omitempty"`
+	// Port is the container port Traefik routes to; 0 when unset.
+	Port int `json:"port,omitempty"`
+	// ComposeDir is the directory holding docker-compose.yml.
+	ComposeDir string `json:"compose_dir,omitempty"`
+	// URL is the site's canonical URL.
+	URL string `json:"url"`
+	// Tags are the labels the site is grouped by (`srv tag`).
+	Tags []string `json:"tags,omitempty"`
+	// DependsOn are the sites started before this one (`srv dependency`).
+	DependsOn []string `json:"depends_on,omitempty"`
+	// CertDaysLeft is the number of days until the local certificate
+	// expires (negative once expired); omitted for sites without a readable
+	// local certificate.
+	CertDaysLeft *int `json:"cert_days_left,omitempty"`
+}
+
+// View returns the site's SiteView.
+func (s *Site) View() SiteView {
+	v := SiteView{
+		Name:           s.Name,
+		Domains:        append([]string{}, s.Domains...),
+		Target:         s.Dir,
+		Type:           s.TypeLabel(),
+		SSL:            s.SSLStatus(),
+		Status:         s.Status,
+		Local:          s.IsLocal,
+		CustomCert:     s.CustomCert,
+		Broken:         s.IsBroken,
+		Disabled:       s.Disabled,
+		Staging:        s.Staging,
+		EntryPoints:    append([]string{}, s.EntryPoints...),
+		Wildcard:       s.Wildcard,
+		PathPrefix:     s.PathPrefix,
+		StripPrefix:    s.StripPrefix,
+		Service:        s.ServiceName,
+		ComposeService: s.ComposeServiceName,
+		Profile:        s.Profile,
+		Port:           s.Port,
+		ComposeDir:     s.ComposeDir,
+		Tags:           s.Tags,
+		DependsOn:      s.DependsOn,
+	}
+	switch {
+	case s.IsBroken:
+		v.Status = constants.StatusBroken
+	case s.Disabled:
+		v.Status = constants.StatusDisabled
+	}
+	if s.Domain() != "" {
+		v.URL = s.URL()
+		v.Rule = s.Rule()
+	}
+	if s.IsLocal && !s.IsBroken && s.ServesHTTPS() && s.Domain() != "" {
+		if cert := traefik.GetLocalCertInfo(s.Name, s.Domain()); cert.Exists && !cert.Corrupt {
+			v.CertDaysLeft = &cert.DaysLeft
+		}
+	}
+	return v
+}
+
+// TypeLabel returns the site type as a bare string: "compose", "static" or
+// "dockerfile", or "" for broken sites.
+func (s *Site) TypeLabel() string {
+	if s.IsBroken {
+		return ""
+	}
+	switch s.Type {
+	case SiteTypeStatic:
+		return "static"
+	case SiteTypeDockerfile:
+		return "dockerfile"
+	default:
+		return "compose"
+	}
+}
+
+// SSLStatus returns the site's SSL state as a bare string (see SiteView.SSL).
+func (s *Site) SSLStatus() string {
+	if s.IsBroken || s.Status == constants.StatusParked {
+		return ""
+	}
+	if !s.ServesHTTPS() {
+		return "http"
+	}
+	status := "auto"
+	switch {
+	case s.IsLocal:
+		status = string(traefik.GetLocalCertInfo(s.Name, s.Domain()).Status())
+	case s.Staging:
+		status = constants.TypeLabelStaging
+	}
+	if s.ServesHTTP() {
+		status += "+http"
+	}
+	return status
+}
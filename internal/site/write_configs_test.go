@@ -36,6 +36,63 @@ func TestWriteStaticSiteConfigCreatesFiles(t *testing.T) {
 	}
 }
 
+func TestWriteStaticSiteConfigMiddlewares(t *testing.T) {
+	root := withSRVRoot(t)
+	meta := SiteMetadata{
+		Type:        SiteTypeStatic,
+		Domains:     []string{"blog.local"},
+		ProjectPath: "/srv/blog",
+		Port:        80,
+		IsLocal:     true,
+		NetworkName: "tnet",
+		Middlewares: []string{"compress", "retry"},
+	}
+	if err := WriteStaticSiteConfig("blog", meta, true); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(root, "sites", "blog", "docker-compose.yml"))
+	if !strings.Contains(string(data), "traefik.http.routers.blog.middlewares: compress@file,retry@file") {
+		t.Errorf("compose labels missing middleware chain:\n%s", data)
+	}
+}
+
+func TestWriteStaticSiteConfigErrorPages(t *testing.T) {
+	root := withSRVRoot(t)
+	pages := t.TempDir()
+	meta := SiteMetadata{
+		Type:           SiteTypeStatic,
+		Domains:        []string{"blog.local"},
+		ProjectPath:    "/srv/blog",
+		Port:           80,
+		IsLocal:        true,
+		NetworkName:    "tnet",
+		ErrorPagesPath: pages,
+	}
+	siteDir := filepath.Join(root, "sites", "blog")
+	if err := WriteStaticSiteConfig("blog", meta, true); err != nil {
+		t.Fatal(err)
+	}
+	compose, _ := os.ReadFile(filepath.Join(siteDir, "docker-compose.yml"))
+	if !strings.Contains(string(compose), "target: "+constants.NginxErrorPagesPath) {
+		t.Errorf("compose missing error pages mount:\n%s", compose)
+	}
+	conf, _ := os.ReadFile(filepath.Join(siteDir, "nginx.conf"))
+	if !strings.Contains(string(conf), "error_page 404 /errors/404.html;") {
+		t.Errorf("nginx.conf missing custom error_page:\n%s", conf)
+	}
+
+	// A vanished directory falls back to the default pages instead of
+	// producing a bind mount compose would reject.
+	meta.ErrorPagesPath = filepath.Join(pages, "gone")
+	if err := WriteStaticSiteConfig("blog", meta, true); err != nil {
+		t.Fatal(err)
+	}
+	compose, _ = os.ReadFile(filepath.Join(siteDir, "docker-compose.yml"))
+	if strings.Contains(string(compose), constants.NginxErrorPagesPath) {
+		t.Errorf("compose should not mount a missing error pages dir:\n%s", compose)
+	}
+}
+
 func TestWriteStaticSiteConfigForceFalsePreserves(t *testing.T) {
 	root := withSRVRoot(t)
 	meta := SiteMetadata{
@@ -0,0 +1,208 @@
+// Package traefik — accesslog.go reads Traefik's JSON access log for
+// `srv logs --access`: parsing entries, filtering them by site domain, time
+// and status, and following the file as Traefik appends to it.
+package traefik
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// accessLogPollInterval is how often FollowAccessLog checks for new lines.
+const accessLogPollInterval = 500 * time.Millisecond
+
+// AccessLogEntry is one request from the access log.
+type AccessLogEntry struct {
+	Time     time.Time     `json:"time"`
+	Host     string        `json:"host"`
+	ClientIP string        `json:"client_ip"`
+	Method   string        `json:"method"`
+	Path     string        `json:"path"`
+	Status   int           `json:"status"`
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// rawAccessLogEntry holds the fields srv uses from Traefik's JSON access log
+// format. Duration is in nanoseconds.
+type rawAccessLogEntry struct {
+	StartUTC         time.Time `json:"StartUTC"`
+	RequestHost      string    `json:"RequestHost"`
+	ClientHost       string    `json:"ClientHost"`
+	RequestMethod    string    `json:"RequestMethod"`
+	RequestPath      string    `json:"RequestPath"`
+	DownstreamStatus int       `json:"DownstreamStatus"`
+	Duration         int64     `json:"Duration"`
+}
+
+// ParseAccessLogLine decodes one JSON access log line. Lines in Traefik's
+// default common log format are rejected.
+func ParseAccessLogLine(line []byte) (AccessLogEntry, error) {
+	var raw rawAccessLogEntry
+	if err := json.Unmarshal(line, &raw); err != nil {
+		return AccessLogEntry{}, fmt.Errorf("not a JSON access log line: %w", err)
+	}
+	host := raw.RequestHost
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return AccessLogEntry{
+		Time:     raw.StartUTC,
+		Host:     strings.ToLower(host),
+		ClientIP: raw.ClientHost,
+		Method:   raw.RequestMethod,
+		Path:     raw.RequestPath,
+		Status:   raw.DownstreamStatus,
+		Duration: time.Duration(raw.Duration),
+	}, nil
+}
+
+// AccessLogFilter selects access log entries. Zero fields match everything.
+type AccessLogFilter struct {
+	// Domains are the site's domains; "*.example.test" matches any
+	// subdomain.
+	Domains []string
+	// Since drops entries that started before it.
+	Since time.Time
+	// StatusMin and StatusMax bound the response status, inclusive.
+	StatusMin, StatusMax int
+}
+
+// ParseStatusFilter turns a --status value into an inclusive range: a class
+// such as "4xx", or a single code such as "404".
+func ParseStatusFilter(s string) (lo, hi int, err error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if len(s) == 3 && strings.HasSuffix(s, "xx") && s[0] >= '1' && s[0] <= '5' {
+		class := int(s[0]-'0') * 100
+		return class, class + 99, nil
+	}
+	if code, err := strconv.Atoi(s); err == nil && code >= 100 && code <= 599 {
+		return code, code, nil
+	}
+	return 0, 0, fmt.Errorf("invalid status filter %q (use a class like 4xx or a code like 404)", s)
+}
+
+// Match reports whether e passes the filter.
+func (f AccessLogFilter) Match(e AccessLogEntry) bool {
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if f.StatusMin > 0 && e.Status < f.StatusMin {
+		return false
+	}
+	if f.StatusMax > 0 && e.Status > f.StatusMax {
+		return false
+	}
+	if len(f.Domains) == 0 {
+		return true
+	}
+	for _, d := range f.Domains {
+		d = strings.ToLower(d)
+		if e.Host == d {
+			return true
+		}
+		if suffix, ok := strings.CutPrefix(d, "*"); ok && strings.HasSuffix(e.Host, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadAccessLog returns the entries in r that match f, keeping only the last
+// tail of them when tail > 0. unparsed counts the lines that weren't JSON
+// access log entries.
+func ReadAccessLog(r io.Reader, f AccessLogFilter, tail int) (entries []AccessLogEntry, unparsed int, err error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		e, err := ParseAccessLogLine(line)
+		if err != nil {
+			unparsed++
+			continue
+		}
+		if !f.Match(e) {
+			continue
+		}
+		entries = append(entries, e)
+		if tail > 0 && len(entries) > tail {
+			entries = entries[1:]
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, unparsed, fmt.Errorf("read access log: %w", err)
+	}
+	return entries, unparsed, nil
+}
+
+// FollowAccessLog polls the access log at path from offset on, calling fn
+// for each new entry that matches f until ctx is done. A file that shrinks
+// (rotated or truncated) is read again from the start.
+func FollowAccessLog(ctx context.Context, path string, offset int64, f AccessLogFilter, fn func(AccessLogEntry)) error {
+	ticker := time.NewTicker(accessLogPollInterval)
+	defer ticker.Stop()
+	var partial []byte
+	for {
+		info, err := os.Stat(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err == nil {
+			if info.Size() < offset {
+				offset, partial = 0, nil
+			}
+			if info.Size() > offset {
+				chunk, err := readAccessLogFrom(path, offset, info.Size())
+				if err != nil {
+					return err
+				}
+				offset += int64(len(chunk))
+				partial = append(partial, chunk...)
+				// Only complete lines are parsed; a trailing partial line waits
+				// for the rest of it.
+				for {
+					i := bytes.IndexByte(partial, '\n')
+					if i < 0 {
+						break
+					}
+					line := bytes.TrimSpace(partial[:i])
+					partial = partial[i+1:]
+					if e, err := ParseAccessLogLine(line); err == nil && f.Match(e) {
+						fn(e)
+					}
+				}
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// readAccessLogFrom reads path between the offsets from and to.
+func readAccessLogFrom(path string, from, to int64) ([]byte, error) {
+	file, err := os.Open(path) //nolint:gosec // path is srv's own access log
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+	buf := make([]byte, to-from)
+	n, err := file.ReadAt(buf, from)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	return buf[:n], nil
+}
@@ -0,0 +1,149 @@
+package traefik
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Representative lines from Traefik's JSON access log format.
+const (
+	accessLine200 = `{"ClientAddr":"203.0.113.7:51234","ClientHost":"203.0.113.7","ClientPort":"51234","DownstreamContentSize":612,"DownstreamStatus":200,"Duration":12345678,"OriginStatus":200,"RequestHost":"app.test","RequestMethod":"GET","RequestPath":"/","RequestProtocol":"HTTP/2.0","RouterName":"app@docker","StartUTC":"2026-10-16T09:00:00.123456789Z","entryPointName":"websecure","level":"info","msg":"","time":"2026-10-16T09:00:00Z"}`
+	accessLine404 = `{"ClientHost":"198.51.100.2","DownstreamStatus":404,"Duration":850000,"RequestHost":"api.app.test:443","RequestMethod":"POST","RequestPath":"/missing?x=1","StartUTC":"2026-10-16T09:05:00Z"}`
+	accessLine502 = `{"ClientHost":"198.51.100.2","DownstreamStatus":502,"Duration":3000000000,"RequestHost":"other.test","RequestMethod":"GET","RequestPath":"/health","StartUTC":"2026-10-16T09:10:00Z"}`
+	accessLineCLF = `203.0.113.7 - - [16/Oct/2026:09:00:00 +0000] "GET / HTTP/2.0" 200 612 "-" "curl/8.0" 1 "app@docker" "http://172.18.0.3:80" 12ms`
+)
+
+func TestParseAccessLogLine(t *testing.T) {
+	e, err := ParseAccessLogLine([]byte(accessLine200))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := AccessLogEntry{
+		Time:     time.Date(2026, 10, 16, 9, 0, 0, 123456789, time.UTC),
+		Host:     "app.test",
+		ClientIP: "203.0.113.7",
+		Method:   "GET",
+		Path:     "/",
+		Status:   200,
+		Duration: 12345678 * time.Nanosecond,
+	}
+	if e != want {
+		t.Errorf("got %+v, want %+v", e, want)
+	}
+
+	e, err = ParseAccessLogLine([]byte(accessLine404))
+	if err != nil || e.Host != "api.app.test" {
+		t.Errorf("port should be stripped from the host: %+v, %v", e, err)
+	}
+
+	if _, err := ParseAccessLogLine([]byte(accessLineCLF)); err == nil {
+		t.Error("expected err for a common log format line")
+	}
+}
+
+func TestParseStatusFilter(t *testing.T) {
+	cases := []struct {
+		in     string
+		lo, hi int
+		ok     bool
+	}{
+		{"4xx", 400, 499, true},
+		{"5XX", 500, 599, true},
+		{"404", 404, 404, true},
+		{"6xx", 0, 0, false},
+		{"99", 0, 0, false},
+		{"bad", 0, 0, false},
+	}
+	for _, c := range cases {
+		lo, hi, err := ParseStatusFilter(c.in)
+		if (err == nil) != c.ok || lo != c.lo || hi != c.hi {
+			t.Errorf("ParseStatusFilter(%q) = %d, %d, %v", c.in, lo, hi, err)
+		}
+	}
+}
+
+func TestAccessLogFilterMatch(t *testing.T) {
+	e, _ := ParseAccessLogLine([]byte(accessLine404))
+	cases := []struct {
+		name string
+		f    AccessLogFilter
+		want bool
+	}{
+		{"empty", AccessLogFilter{}, true},
+		{"other domain", AccessLogFilter{Domains: []string{"app.test"}}, false},
+		{"exact domain", AccessLogFilter{Domains: []string{"API.app.test"}}, true},
+		{"wildcard domain", AccessLogFilter{Domains: []string{"*.app.test"}}, true},
+		{"status class", AccessLogFilter{StatusMin: 400, StatusMax: 499}, true},
+		{"other status class", AccessLogFilter{StatusMin: 500, StatusMax: 599}, false},
+		{"since before", AccessLogFilter{Since: e.Time.Add(-time.Minute)}, true},
+		{"since after", AccessLogFilter{Since: e.Time.Add(time.Minute)}, false},
+	}
+	for _, c := range cases {
+		if got := c.f.Match(e); got != c.want {
+			t.Errorf("%s: Match = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestReadAccessLog(t *testing.T) {
+	log := strings.Join([]string{accessLine200, accessLineCLF, "", accessLine404, accessLine502}, "\n")
+	f := AccessLogFilter{Domains: []string{"app.test", "*.app.test"}}
+
+	entries, unparsed, err := ReadAccessLog(strings.NewReader(log), f, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unparsed != 1 {
+		t.Errorf("unparsed = %d, want 1", unparsed)
+	}
+	if len(entries) != 2 || entries[0].Status != 200 || entries[1].Status != 404 {
+		t.Errorf("entries = %+v", entries)
+	}
+
+	entries, _, _ = ReadAccessLog(strings.NewReader(log), f, 1)
+	if len(entries) != 1 || entries[0].Status != 404 {
+		t.Errorf("tail 1 = %+v, want the 404 only", entries)
+	}
+}
+
+func TestFollowAccessLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+	if err := os.WriteFile(path, []byte(accessLine200+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	offset := int64(len(accessLine200) + 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	got := make(chan AccessLogEntry, 4)
+	done := make(chan error, 1)
+	go func() {
+		done <- FollowAccessLog(ctx, path, offset, AccessLogFilter{Domains: []string{"*.app.test"}}, func(e AccessLogEntry) { got <- e })
+	}()
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The 404 is split across writes; only the complete line is delivered.
+	_, _ = file.WriteString(accessLine502 + "\n" + accessLine404[:20])
+	_, _ = file.WriteString(accessLine404[20:] + "\n")
+	_ = file.Close()
+
+	select {
+	case e := <-got:
+		if e.Status != 404 {
+			t.Errorf("got %+v, want the 404", e)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the appended entry")
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("FollowAccessLog: %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+// Package traefik — acme_dns.go switches the Let's Encrypt resolvers from the
+// HTTP-01 to the DNS-01 challenge, for hosts whose port 80 is firewalled. The
+// provider name and its credentials live in env.traefik, which the Traefik
+// container then loads as its environment; Traefik's DNS provider reads its
+// credentials from there (e.g. CF_DNS_API_TOKEN for cloudflare).
+package traefik
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/constants"
+	"github.com/stubbedev/srv/internal/yamlpatch"
+)
+
+var (
+	// acmeDNSProviderRegex matches Traefik DNS provider codes such as
+	// cloudflare, route53 or ovh.
+	acmeDNSProviderRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]*$`)
+	// acmeDNSEnvKeyRegex matches environment variable names.
+	acmeDNSEnvKeyRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// srvEnvKeys are the env.traefik keys srv manages itself; DNS provider
+// credentials can't overwrite them.
+var srvEnvKeys = []string{
+	constants.EnvACMEEmail,
+	constants.EnvDNSHTTPUser,
+	constants.EnvDNSHTTPPass,
+	constants.EnvACMEDNSProvider,
+}
+
+// ACMEDNSProvider returns the DNS provider the Let's Encrypt resolvers use
+// for the DNS-01 challenge, or "" when they use HTTP-01.
+func ACMEDNSProvider(cfg *config.Config) string {
+	return readEnvFile(cfg.EnvTraefikPath())[constants.EnvACMEDNSProvider]
+}
+
+// ValidateACMEDNSChallenge checks a DNS provider code and its credentials.
+func ValidateACMEDNSChallenge(provider string, credentials map[string]string) error {
+	if !acmeDNSProviderRegex.MatchString(provider) {
+		return fmt.Errorf("invalid DNS provider %q (use the provider code from Traefik's docs, e.g. cloudflare or route53)", provider)
+	}
+	for key, value := range credentials {
+		if !acmeDNSEnvKeyRegex.MatchString(key) {
+			return fmt.Errorf("invalid environment variable name %q (letters, digits and _; must not start with a digit)", key)
+		}
+		if slices.Contains(srvEnvKeys, key) {
+			return fmt.Errorf("%s is managed by srv and can't be set as a DNS provider credential", key)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("invalid value for %s: line breaks are not allowed", key)
+		}
+	}
+	return nil
+}
+
+// SetACMEDNSChallenge stores the DNS provider and its credentials in
+// env.traefik and, when Traefik is installed, rewrites traefik.yml and its
+// docker-compose.yml to use them. Credentials already stored are kept unless
+// overwritten. Returns whether anything changed; Traefik must then be
+// recreated to load the new environment and static config.
+func SetACMEDNSChallenge(provider string, credentials map[string]string) (changed bool, err error) {
+	if err := ValidateACMEDNSChallenge(provider, credentials); err != nil {
+		return false, err
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return false, err
+	}
+	envPath := cfg.EnvTraefikPath()
+	envMap := readEnvFile(envPath)
+	updated := maps.Clone(envMap)
+	maps.Copy(updated, credentials)
+	updated[constants.EnvACMEDNSProvider] = provider
+	if !maps.Equal(envMap, updated) {
+		if err := writeEnvFile(envPath, updated); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+	if !IsInstalled(cfg) {
+		return changed, nil
+	}
+
+	traefikPath := StaticConfigPath(cfg)
+	ymlChanged, err := writeOrMergeTraefikYML(traefikPath, cfg.NetworkName, updated[constants.EnvACMEEmail], provider)
+	if err != nil {
+		return false, err
+	}
+	before, _ := os.ReadFile(cfg.TraefikComposePath())
+	if err := writeTraefikCompose(cfg); err != nil {
+		return false, err
+	}
+	after, _ := os.ReadFile(cfg.TraefikComposePath())
+	composeChanged := !bytes.Equal(before, after)
+	return changed || ymlChanged || composeChanged, nil
+}
+
+// setDNSChallenge replaces the httpChallenge of every Let's Encrypt resolver
+// in the parsed static config with a dnsChallenge for provider. Traefik
+// accepts one challenge type per resolver.
+func setDNSChallenge(doc *yaml.Node, provider string) error {
+	for _, resolver := range []string{constants.CertResolverLetsEncrypt, constants.CertResolverLetsEncryptStaging} {
+		acme := "certificatesResolvers." + resolver + ".acme"
+		if err := yamlpatch.SetPath(doc, acme+".dnsChallenge.provider", provider); err != nil {
+			return fmt.Errorf("failed to set dns challenge: %w", err)
+		}
+		deleteMappingKey(mappingAt(doc, strings.Split(acme, ".")...), "httpChallenge")
+	}
+	return nil
+}
+
+// mappingAt walks a YAML document down the given mapping keys, returning nil
+// when one is missing.
+func mappingAt(doc *yaml.Node, keys ...string) *yaml.Node {
+	cur := doc
+	if cur.Kind == yaml.DocumentNode && len(cur.Content) > 0 {
+		cur = cur.Content[0]
+	}
+	for _, key := range keys {
+		if cur == nil || cur.Kind != yaml.MappingNode {
+			return nil
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(cur.Content); i += 2 {
+			if cur.Content[i].Value == key {
+				next = cur.Content[i+1]
+				break
+			}
+		}
+		cur = next
+	}
+	return cur
+}
+
+// deleteMappingKey removes key from a mapping node; a nil node or missing key
+// is a no-op.
+func deleteMappingKey(m *yaml.Node, key string) {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content = append(m.Content[:i], m.Content[i+2:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package traefik
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/constants"
+)
+
+func TestRenderTraefikTemplateDNSChallenge(t *testing.T) {
+	out, err := renderTraefikTemplate("srv-network", "ops@example.com", "cloudflare")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Resolvers map[string]struct {
+			ACME map[string]any `yaml:"acme"`
+		} `yaml:"certificatesResolvers"`
+	}
+	if err := yaml.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{constants.CertResolverLetsEncrypt, constants.CertResolverLetsEncryptStaging} {
+		acme := doc.Resolvers[name].ACME
+		if _, ok := acme["httpChallenge"]; ok {
+			t.Errorf("%s still has httpChallenge", name)
+		}
+		dns, _ := acme["dnsChallenge"].(map[string]any)
+		if dns["provider"] != "cloudflare" {
+			t.Errorf("%s dnsChallenge = %v, want provider cloudflare", name, acme["dnsChallenge"])
+		}
+		if acme["email"] != "ops@example.com" {
+			t.Errorf("%s email = %v", name, acme["email"])
+		}
+	}
+}
+
+func TestValidateACMEDNSChallenge(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		creds    map[string]string
+		wantErr  bool
+	}{
+		{"valid", "route53", map[string]string{"AWS_ACCESS_KEY_ID": "a", "AWS_SECRET_ACCESS_KEY": "b"}, false},
+		{"no credentials", "cloudflare", nil, false},
+		{"empty provider", "", nil, true},
+		{"bad provider", "Cloud Flare", nil, true},
+		{"bad key", "cloudflare", map[string]string{"1TOKEN": "x"}, true},
+		{"srv key", "cloudflare", map[string]string{constants.EnvDNSHTTPPass: "x"}, true},
+		{"newline in value", "cloudflare", map[string]string{"CF_DNS_API_TOKEN": "a\nB=c"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateACMEDNSChallenge(tt.provider, tt.creds); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateACMEDNSChallenge() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSetACMEDNSChallenge(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("SRV_ROOT", root)
+	config.ResetCache()
+	t.Cleanup(config.ResetCache)
+	if err := SaveEmail("ops@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := EnsureConfig("ops@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ACMEDNSProvider(cfg) != "" {
+		t.Fatal("fresh install already has a DNS provider")
+	}
+	if compose, _ := os.ReadFile(cfg.TraefikComposePath()); strings.Contains(string(compose), "env_file") {
+		t.Error("compose loads env.traefik before a DNS provider is configured")
+	}
+
+	changed, err := SetACMEDNSChallenge("cloudflare", map[string]string{"CF_DNS_API_TOKEN": "tok"})
+	if err != nil || !changed {
+		t.Fatalf("SetACMEDNSChallenge() = %v, %v; want changed", changed, err)
+	}
+	env := readEnvFile(cfg.EnvTraefikPath())
+	if env["CF_DNS_API_TOKEN"] != "tok" || env[constants.EnvACMEDNSProvider] != "cloudflare" || env[constants.EnvACMEEmail] != "ops@example.com" {
+		t.Errorf("env.traefik = %v", env)
+	}
+	compose, _ := os.ReadFile(cfg.TraefikComposePath())
+	if !strings.Contains(string(compose), cfg.EnvTraefikPath()) {
+		t.Errorf("compose doesn't load env.traefik:\n%s", compose)
+	}
+	static, _ := os.ReadFile(StaticConfigPath(cfg))
+	if !strings.Contains(string(static), "dnsChallenge") || strings.Contains(string(static), "httpChallenge") || !strings.Contains(string(static), "ops@example.com") {
+		t.Errorf("traefik.yml not switched to DNS-01:\n%s", static)
+	}
+
+	// Re-running with the same settings changes nothing, and a later
+	// EnsureConfig keeps the challenge.
+	if changed, err := SetACMEDNSChallenge("cloudflare", map[string]string{"CF_DNS_API_TOKEN": "tok"}); err != nil || changed {
+		t.Errorf("repeat SetACMEDNSChallenge() = %v, %v; want unchanged", changed, err)
+	}
+	if changed, err := EnsureConfig("ops@example.com"); err != nil || changed {
+		t.Errorf("EnsureConfig() after DNS challenge = %v, %v; want unchanged", changed, err)
+	}
+}
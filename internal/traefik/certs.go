@@ -1,6 +1,8 @@
 package traefik
 
 import (
+	"cmp"
+	"crypto/tls"
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
@@ -15,6 +17,7 @@ import (
 	"github.com/stubbedev/srv/internal/constants"
 	"github.com/stubbedev/srv/internal/fsutil"
 	"github.com/stubbedev/srv/internal/mkcert"
+	"github.com/stubbedev/srv/internal/platform"
 	"github.com/stubbedev/srv/internal/validate"
 )
 
@@ -42,12 +45,17 @@ func IsCAInstalled() bool {
 
 // InstallCA installs the mkcert CA certificate. mkcert's output is captured
 // and returned as a parsed result so callers can render a clean message rather
-// than leaking mkcert's raw multi-line warnings.
+// than leaking mkcert's raw multi-line warnings. Under WSL2 the CA is also
+// trusted in the Windows certificate store, where the browsers run; failing
+// that is reported in the result, not as an error.
 func InstallCA() (mkcert.InstallResult, error) {
 	res, err := mkcert.Install()
 	if err != nil {
 		return res, fmt.Errorf("failed to install mkcert CA: %w", err)
 	}
+	if platform.IsWSL() {
+		mkcert.InstallWindows(&res)
+	}
 	return res, nil
 }
 
@@ -152,8 +160,14 @@ func GenerateLocalCert(siteName string, domains []string, wildcard bool) error {
 	return nil
 }
 
+// CertWarningDays returns how many days before expiry a certificate counts as
+// expiring: the cert_warning_days setting, or constants.CertExpiryWarningDays.
+func CertWarningDays() int {
+	return cmp.Or(config.UserSettings().CertWarningDays, constants.CertExpiryWarningDays)
+}
+
 // RenewThresholdDays is the number of days before expiry to trigger auto-renewal.
-const RenewThresholdDays = constants.CertExpiryWarningDays
+const RenewThresholdDays = constants.RenewThresholdDays
 
 // EnsureResourceCert ensures mkcert is available, the local CA is installed,
 // and a cert exists for (siteName, domain). It is the headless core shared by
@@ -194,7 +208,7 @@ func EnsureLocalCert(siteName string, domains []string, wildcard bool) (bool, er
 	// the cert.Corrupt path catches truncated/damaged files that LocalCertsExist
 	// can't detect by stat alone).
 	cert := GetLocalCertInfo(siteName, primary)
-	if cert.Corrupt || cert.IsExpired || cert.DaysLeft <= RenewThresholdDays {
+	if cert.NeedsRenewal() {
 		return true, GenerateLocalCert(siteName, domains, wildcard)
 	}
 
@@ -206,6 +220,15 @@ func EnsureLocalCert(siteName string, domains []string, wildcard bool) (bool, er
 	return false, nil
 }
 
+// LocalCertCovers reports whether a site's certificate, named after
+// domains[0], covers every domain (and `*.<d>` if wildcard).
+func LocalCertCovers(siteName string, domains []string, wildcard bool) bool {
+	if len(domains) == 0 {
+		return false
+	}
+	return certCoversDomains(siteName, domains[0], domains, wildcard)
+}
+
 // certCoversDomains reports whether the on-disk cert (named after primary)
 // includes every required domain (and `*.<d>` if wildcard) as a SAN.
 func certCoversDomains(siteName, primary string, domains []string, wildcard bool) bool {
@@ -382,6 +405,13 @@ const (
 	CertStatusValid    CertStatus = "valid"
 )
 
+// NeedsRenewal reports whether an existing certificate should be reissued:
+// it is unreadable, expired, or within RenewThresholdDays of expiry. It is
+// the renewal rule shared by EnsureLocalCert and the daemon.
+func (c CertInfo) NeedsRenewal() bool {
+	return c.Corrupt || c.IsExpired || c.DaysLeft <= RenewThresholdDays
+}
+
 // Status classifies the certificate into a single lifecycle state. It is the
 // one place this precedence lives — list, inspect, and doctor all call it
 // instead of re-deriving the same switch.
@@ -393,7 +423,7 @@ func (c CertInfo) Status() CertStatus {
 		return CertStatusMissing
 	case c.IsExpired:
 		return CertStatusExpired
-	case c.DaysLeft <= constants.CertExpiryWarningDays:
+	case c.DaysLeft <= CertWarningDays():
 		return CertStatusExpiring
 	default:
 		return CertStatusValid
@@ -414,6 +444,113 @@ func GetLocalCertInfo(siteName, domain string) CertInfo {
 	return info
 }
 
+// Cert export formats accepted by ExportLocalCert.
+const (
+	CertFormatPEM = "pem"
+	CertFormatDER = "der"
+)
+
+// ExportLocalCert copies a site's local certificate and key into outDir,
+// named after the site: <site>.crt / <site>.key for PEM (mkcert's native
+// encoding, copied as-is) or <site>.der / <site>.key.der for DER. The key
+// keeps owner-only permissions. Returns the written paths.
+func ExportLocalCert(cfg *config.Config, siteName, domain, outDir, format string) (certOut, keyOut string, err error) {
+	if err := validate.NoTraversal(siteName); err != nil {
+		return "", "", err
+	}
+	certFile := filepath.Join(cfg.SiteCertsDir(siteName), domain+constants.ExtCert)
+	keyFile := filepath.Join(cfg.SiteCertsDir(siteName), domain+constants.ExtKey)
+	certData, err := os.ReadFile(certFile)
+	if err != nil {
+		return "", "", fmt.Errorf("read certificate: %w", err)
+	}
+	keyData, err := os.ReadFile(keyFile)
+	if err != nil {
+		return "", "", fmt.Errorf("read key: %w", err)
+	}
+
+	switch format {
+	case CertFormatPEM:
+		certOut = filepath.Join(outDir, siteName+constants.ExtCert)
+		keyOut = filepath.Join(outDir, siteName+constants.ExtKey)
+	case CertFormatDER:
+		if certData, err = pemToDER(certData); err != nil {
+			return "", "", fmt.Errorf("convert certificate: %w", err)
+		}
+		if keyData, err = pemToDER(keyData); err != nil {
+			return "", "", fmt.Errorf("convert key: %w", err)
+		}
+		certOut = filepath.Join(outDir, siteName+".der")
+		keyOut = filepath.Join(outDir, siteName+constants.ExtKey+".der")
+	default:
+		return "", "", fmt.Errorf("unknown certificate format %q (expected %s or %s)", format, CertFormatPEM, CertFormatDER)
+	}
+
+	if err := os.MkdirAll(outDir, constants.DirPermDefault); err != nil {
+		return "", "", fmt.Errorf("create output directory: %w", err)
+	}
+	if err := fsutil.AtomicWriteFile(certOut, certData, constants.FilePermDefault); err != nil {
+		return "", "", fmt.Errorf("write certificate: %w", err)
+	}
+	if err := fsutil.AtomicWriteFile(keyOut, keyData, constants.FilePermACME); err != nil {
+		return "", "", fmt.Errorf("write key: %w", err)
+	}
+	return certOut, keyOut, nil
+}
+
+// ImportLocalCert validates a PEM certificate and private key and installs
+// them as the site's local certificate for domain, replacing any mkcert one.
+// The pair must match, and the certificate must cover domain. Returns the
+// parsed leaf so callers can report its expiry.
+func ImportLocalCert(cfg *config.Config, siteName, domain, certPath, keyPath string) (*x509.Certificate, error) {
+	if err := validate.NoTraversal(siteName); err != nil {
+		return nil, err
+	}
+	if err := validate.NoTraversal(domain); err != nil {
+		return nil, err
+	}
+	certData, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, fmt.Errorf("read certificate: %w", err)
+	}
+	keyData, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("read key: %w", err)
+	}
+	pair, err := tls.X509KeyPair(certData, keyData)
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate or key: %w", err)
+	}
+	leaf, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("parse certificate: %w", err)
+	}
+	if err := leaf.VerifyHostname(domain); err != nil {
+		return nil, fmt.Errorf("certificate does not cover %s: %w", domain, err)
+	}
+
+	certDir := cfg.SiteCertsDir(siteName)
+	if err := os.MkdirAll(certDir, constants.DirPermPrivate); err != nil {
+		return nil, fmt.Errorf("failed to create certs directory: %w", err)
+	}
+	if err := fsutil.AtomicWriteFile(filepath.Join(certDir, domain+constants.ExtCert), certData, constants.FilePermDefault); err != nil {
+		return nil, fmt.Errorf("write certificate: %w", err)
+	}
+	if err := fsutil.AtomicWriteFile(filepath.Join(certDir, domain+constants.ExtKey), keyData, constants.FilePermACME); err != nil {
+		return nil, fmt.Errorf("write key: %w", err)
+	}
+	return leaf, nil
+}
+
+// pemToDER returns the body of the first PEM block in data.
+func pemToDER(data []byte) ([]byte, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+	return block.Bytes, nil
+}
+
 // ListLocalCerts returns information about all local SSL certificates across all sites.
 func ListLocalCerts() []CertInfo {
 	cfg, err := config.Load()
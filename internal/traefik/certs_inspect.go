@@ -0,0 +1,103 @@
+// Package traefik — certs_inspect.go reports on the local certificates for
+// `srv cert list` and `srv cert info`: who owns each one (a site, a proxy or
+// a redirect, going by the cert directory's name) and the X.509 details.
+package traefik
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/constants"
+)
+
+// Owners of local certificates.
+const (
+	CertOwnerSite     = "site"
+	CertOwnerProxy    = "proxy"
+	CertOwnerRedirect = "redirect"
+)
+
+// CertOwner splits a certificate's site directory name into the kind of
+// thing it belongs to and that thing's name: proxies keep their certs under
+// _proxy-NAME and redirects under _redirect-NAME.
+func CertOwner(siteName string) (kind, name string) {
+	if name, ok := strings.CutPrefix(siteName, "_proxy-"); ok {
+		return CertOwnerProxy, name
+	}
+	if name, ok := strings.CutPrefix(siteName, "_"+constants.RedirectConfigPrefix); ok {
+		return CertOwnerRedirect, name
+	}
+	return CertOwnerSite, siteName
+}
+
+// CertDetails holds the X.509 details `srv cert info` shows.
+type CertDetails struct {
+	CertInfo
+	Path        string
+	Subject     string
+	Issuer      string
+	SANs        []string
+	NotBefore   time.Time
+	Fingerprint string // SHA-256 of the DER certificate, colon-separated hex
+}
+
+// FindLocalCert returns the local certificate issued for domain.
+func FindLocalCert(domain string) (CertInfo, bool) {
+	for _, cert := range ListLocalCerts() {
+		if strings.EqualFold(cert.Domain, domain) {
+			return cert, true
+		}
+	}
+	return CertInfo{}, false
+}
+
+// LocalCertDetails reads the local certificate issued for domain.
+func LocalCertDetails(domain string) (*CertDetails, error) {
+	info, ok := FindLocalCert(domain)
+	if !ok {
+		return nil, fmt.Errorf("no local certificate for %s", domain)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(cfg.SiteCertsDir(info.SiteName), info.Domain+constants.ExtCert)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("certificate %s is not PEM encoded", path)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse certificate %s: %w", path, err)
+	}
+
+	sans := append([]string(nil), cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	sum := sha256.Sum256(cert.Raw)
+	hexes := make([]string, len(sum))
+	for i, b := range sum {
+		hexes[i] = fmt.Sprintf("%02X", b)
+	}
+	return &CertDetails{
+		CertInfo:    info,
+		Path:        path,
+		Subject:     cert.Subject.String(),
+		Issuer:      cert.Issuer.String(),
+		SANs:        sans,
+		NotBefore:   cert.NotBefore,
+		Fingerprint: strings.Join(hexes, ":"),
+	}, nil
+}
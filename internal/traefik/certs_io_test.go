@@ -346,3 +346,43 @@ func find(s, sub string) int {
 	}
 	return -1
 }
+
+func TestCertOwner(t *testing.T) {
+	cases := map[string][2]string{
+		"blog":              {CertOwnerSite, "blog"},
+		"_proxy-api":        {CertOwnerProxy, "api"},
+		"_redirect-old-www": {CertOwnerRedirect, "old-www"},
+	}
+	for dir, want := range cases {
+		if kind, name := CertOwner(dir); kind != want[0] || name != want[1] {
+			t.Errorf("CertOwner(%q) = %q, %q; want %q, %q", dir, kind, name, want[0], want[1])
+		}
+	}
+}
+
+func TestLocalCertDetails(t *testing.T) {
+	setupSrvRoot(t)
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	certDir := cfg.SiteCertsDir("_proxy-api")
+	writePEMCert(t, filepath.Join(certDir, "api.test.crt"), []string{"api.test", "*.api.test"}, -time.Hour, 30*24*time.Hour)
+	if err := os.WriteFile(filepath.Join(certDir, "api.test.key"), []byte("key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	details, err := LocalCertDetails("api.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if details.SiteName != "_proxy-api" || len(details.SANs) != 2 || details.SANs[1] != "*.api.test" {
+		t.Errorf("details = %+v", details)
+	}
+	if details.Subject != "CN=api.test" || len(details.Fingerprint) != 32*3-1 {
+		t.Errorf("subject %q, fingerprint %q", details.Subject, details.Fingerprint)
+	}
+	if _, err := LocalCertDetails("nope.test"); err == nil {
+		t.Error("expected an error for an unknown domain")
+	}
+}
@@ -139,3 +139,50 @@ func TestCertInfo(t *testing.T) {
 		}
 	})
 }
+
+func TestExportLocalCert(t *testing.T) {
+	cfg := newTraefikCfg(t)
+	certDir := cfg.SiteCertsDir("app")
+	if err := os.MkdirAll(certDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	generateTestCert(t, filepath.Join(certDir, "app.test.crt"), time.Now().Add(24*time.Hour))
+	key := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("keybytes")})
+	if err := os.WriteFile(filepath.Join(certDir, "app.test.key"), key, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	out := t.TempDir()
+
+	certOut, keyOut, err := ExportLocalCert(cfg, "app", "app.test", out, CertFormatPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if certOut != filepath.Join(out, "app.crt") || keyOut != filepath.Join(out, "app.key") {
+		t.Errorf("pem paths = %s, %s", certOut, keyOut)
+	}
+	if got, _ := os.ReadFile(keyOut); string(got) != string(key) {
+		t.Error("pem key should be copied verbatim")
+	}
+	if fi, err := os.Stat(keyOut); err != nil || fi.Mode().Perm() != 0o600 {
+		t.Errorf("key perms = %v, %v", fi.Mode().Perm(), err)
+	}
+
+	certOut, keyOut, err = ExportLocalCert(cfg, "app", "app.test", out, CertFormatDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, _ := os.ReadFile(certOut)
+	if _, err := x509.ParseCertificate(der); err != nil {
+		t.Errorf("der cert does not parse: %v", err)
+	}
+	if got, _ := os.ReadFile(keyOut); string(got) != "keybytes" {
+		t.Errorf("der key = %q", got)
+	}
+
+	if _, _, err := ExportLocalCert(cfg, "app", "app.test", out, "p12"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+	if _, _, err := ExportLocalCert(cfg, "ghost", "ghost.test", out, CertFormatPEM); err == nil {
+		t.Error("expected error for missing cert")
+	}
+}
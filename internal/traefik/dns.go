@@ -22,22 +22,61 @@ import (
 	"github.com/stubbedev/srv/internal/shell"
 )
 
-// LocalDomains are the TLDs used for local development.
+// LocalDomains are the built-in TLDs used for local development. Users can
+// add more with `srv config set local-tlds` (UserConfig.LocalTLDs); callers
+// that need the full set use ActiveLocalTLDs.
 var LocalDomains = []string{"test", "local", "localhost"}
 
-// routingTLDs are the local TLDs srv routes to dnsmasq wholesale. `local` is
-// deliberately excluded: it is reserved for mDNS (RFC 6762), so claiming the
-// entire `~local` / `/local/` TLD would hijack every LAN mDNS name (other
-// hosts, printers, `ssh foo.local`) to dnsmasq. srv instead routes its own
-// `.local` domains by exact name (see the resolver builders), leaving the rest
-// of `.local` to the system's mDNS resolver.
-var routingTLDs = []string{"test", "localhost"}
+// mdnsTLD is never routed to dnsmasq wholesale: it is reserved for mDNS
+// (RFC 6762), so claiming the entire `~local` / `/local/` TLD would hijack
+// every LAN mDNS name (other hosts, printers, `ssh foo.local`). srv instead
+// routes its own `.local` domains by exact name (see the resolver builders),
+// leaving the rest of `.local` to the system's mDNS resolver.
+const mdnsTLD = "local"
+
+// MergeLocalTLDs returns the built-in LocalDomains followed by the custom
+// TLDs, normalised (lower-case, no surrounding dots) and deduplicated.
+func MergeLocalTLDs(custom []string) []string {
+	out := append([]string(nil), LocalDomains...)
+	for _, tld := range custom {
+		tld = strings.ToLower(strings.Trim(strings.TrimSpace(tld), "."))
+		if tld == "" || slices.Contains(out, tld) {
+			continue
+		}
+		out = append(out, tld)
+	}
+	return out
+}
+
+// ActiveLocalTLDs returns the built-in local TLDs plus any the user added in
+// config.yml. An unreadable config falls back to the built-in list.
+func ActiveLocalTLDs() []string {
+	var custom []string
+	if cfg, err := config.Load(); err == nil {
+		if userCfg, err := cfg.LoadUserConfig(); err == nil {
+			custom = userCfg.LocalTLDs
+		}
+	}
+	return MergeLocalTLDs(custom)
+}
+
+// routingTLDsFrom returns the local TLDs srv routes to dnsmasq wholesale:
+// every active TLD except `.local`.
+func routingTLDsFrom(tlds []string) []string {
+	out := make([]string, 0, len(tlds))
+	for _, tld := range tlds {
+		if tld != mdnsTLD {
+			out = append(out, tld)
+		}
+	}
+	return out
+}
 
-// isUnderRoutingTLD reports whether bare equals or is a subdomain of a
-// TLD-wide-routed local TLD. `.local` names return false so they are routed
+// isUnderRoutingTLD reports whether bare equals or is a subdomain of one of
+// the TLD-wide-routed tlds. `.local` names return false so they are routed
 // per-name rather than swallowing the whole mDNS TLD.
-func isUnderRoutingTLD(bare string) bool {
-	for _, tld := range routingTLDs {
+func isUnderRoutingTLD(bare string, tlds []string) bool {
+	for _, tld := range tlds {
 		if bare == tld || strings.HasSuffix(bare, "."+tld) {
 			return true
 		}
@@ -67,10 +106,19 @@ const (
 	ResolverSystemdResolved
 	ResolverMacOS
 	ResolverNetworkManager
+	// ResolverWSL2 is WSL2's generated /etc/resolv.conf, which srv replaces
+	// with one pointing at dnsmasq.
+	ResolverWSL2
 )
 
 // DetectResolver detects the DNS resolver type on the system.
 func DetectResolver() DNSResolverType {
+	// WSL2 regenerates /etc/resolv.conf itself, even when systemd-resolved
+	// is enabled in the distribution.
+	if platform.IsWSL() {
+		return ResolverWSL2
+	}
+
 	// Check for systemd-resolved
 	if _, err := os.Stat(constants.SystemdResolvePath); err == nil {
 		return ResolverSystemdResolved
@@ -89,9 +137,10 @@ func DetectResolver() DNSResolverType {
 	return ResolverUnknown
 }
 
-// CheckDNS tests if the local DNS server resolves the given domain to localhost.
-// It queries 127.0.0.1:53 directly using a custom resolver so the result is
-// independent of the system-wide DNS configuration.
+// CheckDNS tests if the local DNS server resolves the given domain to localhost
+// over both IPv4 (A → 127.0.0.1) and IPv6 (AAAA → ::1). It queries
+// 127.0.0.1:53 directly using a custom resolver so the result is independent
+// of the system-wide DNS configuration.
 func CheckDNS(domain string) bool {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -104,10 +153,16 @@ func CheckDNS(domain string) bool {
 	}
 
 	addrs, err := resolver.LookupHost(ctx, domain)
+	if err != nil || !slices.Contains(addrs, constants.LocalhostIP) {
+		return false
+	}
+	// Dual-stack browsers try AAAA first; a missing ::1 record shows up as a
+	// slow or failed connection even though the A record is fine.
+	ips, err := resolver.LookupIP(ctx, "ip6", domain)
 	if err != nil {
 		return false
 	}
-	return slices.Contains(addrs, constants.LocalhostIP)
+	return slices.ContainsFunc(ips, func(ip net.IP) bool { return ip.Equal(net.IPv6loopback) })
 }
 
 // CheckSystemDNS tests if the system's default resolver resolves the given
@@ -123,8 +178,8 @@ func CheckSystemDNS(domain string) bool {
 	return slices.Contains(addrs, constants.LocalhostIP)
 }
 
-// SetupDNS configures the system to use the local DNS server for .test domains.
-// Returns an error if setup fails or requires manual intervention.
+// SetupDNS configures the system to use the local DNS server for the active
+// local TLDs. Returns an error if setup fails or requires manual intervention.
 func SetupDNS() error {
 	resolver := DetectResolver()
 
@@ -135,8 +190,10 @@ func SetupDNS() error {
 		return setupMacOSResolver()
 	case ResolverNetworkManager:
 		return setupNetworkManager()
+	case ResolverWSL2:
+		return setupWSL2Resolver()
 	default:
-		return fmt.Errorf("unsupported DNS configuration. Please manually configure your system to use 127.0.0.1 for .test, .local, and .localhost domains")
+		return fmt.Errorf("unsupported DNS configuration. Please manually configure your system to use 127.0.0.1 for .%s domains", strings.Join(ActiveLocalTLDs(), ", ."))
 	}
 }
 
@@ -192,13 +249,14 @@ func updateSystemdResolvedConfig(domains []string) error {
 	// registered domain that is NOT already covered by a local TLD entry.
 	// The ~ prefix tells systemd-resolved to route matching queries to this
 	// DNS server rather than to the default.
+	routingTLDs := routingTLDsFrom(ActiveLocalTLDs())
 	routingDomains := make([]string, 0, len(routingTLDs)+len(domains))
 	for _, tld := range routingTLDs {
 		routingDomains = append(routingDomains, "~"+tld)
 	}
 	for _, d := range domains {
 		bare := BareDomain(d)
-		if isUnderRoutingTLD(bare) {
+		if isUnderRoutingTLD(bare, routingTLDs) {
 			continue
 		}
 		// .local domains land here and get a per-name route (~grafana.local),
@@ -232,22 +290,51 @@ func updateSystemdResolvedConfig(domains []string) error {
 func FlushDNSCache() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
+	_ = flushResolverCache(ctx)
+}
 
+// FlushAllDNSCaches flushes the system resolver's cache, as FlushDNSCache
+// does, and also restarts nscd (with sudo) when it is installed. It returns
+// the caches that were flushed, for `srv dns flush` to report; errors
+// flushing nscd are returned, resolver flushes stay best-effort.
+func FlushAllDNSCaches() ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	flushed := flushResolverCache(ctx)
+	if shell.Exists("nscd") {
+		if err := shell.SudoRun("service", "nscd", "restart"); err != nil {
+			return flushed, fmt.Errorf("failed to restart nscd: %w", err)
+		}
+		flushed = append(flushed, "nscd")
+	}
+	return flushed, nil
+}
+
+// flushResolverCache flushes the detected resolver's cache and returns the
+// caches it flushed.
+func flushResolverCache(ctx context.Context) []string {
 	switch DetectResolver() {
-	case ResolverSystemdResolved:
+	case ResolverSystemdResolved, ResolverNetworkManager:
 		// systemd-resolved is already restarted in updateSystemdResolvedConfig,
-		// but resolvectl flush-caches clears any remaining per-link caches.
-		if shell.Exists("resolvectl") {
-			_, _ = shell.RunQuietWithContext(ctx, "resolvectl", "flush-caches")
+		// but flushing clears any remaining per-link caches. Older systemd
+		// ships systemd-resolve instead of resolvectl.
+		switch {
+		case shell.Exists("resolvectl"):
+			if _, err := shell.RunQuietWithContext(ctx, "resolvectl", "flush-caches"); err == nil {
+				return []string{"systemd-resolved"}
+			}
+		case shell.Exists("systemd-resolve"):
+			if _, err := shell.RunQuietWithContext(ctx, "systemd-resolve", "--flush-caches"); err == nil {
+				return []string{"systemd-resolved"}
+			}
 		}
 	case ResolverMacOS:
 		_, _ = shell.RunQuietWithContext(ctx, "dscacheutil", "-flushcache")
-		_, _ = shell.RunQuietWithContext(ctx, "killall", "-HUP", "mDNSResponder")
-	case ResolverNetworkManager:
-		if shell.Exists("resolvectl") {
-			_, _ = shell.RunQuietWithContext(ctx, "resolvectl", "flush-caches")
+		if _, err := shell.RunQuietWithContext(ctx, "killall", "-HUP", "mDNSResponder"); err == nil {
+			return []string{"mDNSResponder"}
 		}
 	}
+	return nil
 }
 
 // setupMacOSResolver configures macOS resolver for local domains.
@@ -270,14 +357,16 @@ func updateMacOSResolverConfig(domains []string) error {
 		return fmt.Errorf("failed to create resolver directory: %w", err)
 	}
 
-	nameserver := "nameserver " + constants.LocalhostIP + "\n"
+	// Point the resolver at the address dnsmasq is published on: the DNS
+	// container binds IPv4 loopback only, so `nameserver ::1` would time out.
+	nameserver := "nameserver " + dnsmasqListenAddr() + "\n"
 
 	// Build the full set of names that should have resolver files. The TLD-wide
 	// files cover .test/.localhost; .local is NOT given a TLD-wide
 	// /etc/resolver/local file (that would hijack all Bonjour .local names) —
 	// each registered .local domain gets its own per-name resolver file below.
 	wanted := make(map[string]struct{})
-	for _, tld := range routingTLDs {
+	for _, tld := range routingTLDsFrom(ActiveLocalTLDs()) {
 		wanted[tld] = struct{}{}
 	}
 	for _, d := range domains {
@@ -320,6 +409,15 @@ func updateMacOSResolverConfig(domains []string) error {
 	return nil
 }
 
+// dnsmasqListenAddr returns the host address the dnsmasq container's port 53
+// is published on (the host part of PortMapDNS).
+func dnsmasqListenAddr() string {
+	if host, _, ok := strings.Cut(constants.PortMapDNS, ":53:"); ok && host != "" {
+		return host
+	}
+	return constants.LocalhostIP
+}
+
 // setupNetworkManager configures NetworkManager to use local DNS for local domains.
 // It delegates to updateNetworkManagerConfig with the current domain list.
 func setupNetworkManager() error {
@@ -341,6 +439,7 @@ func updateNetworkManagerConfig(domains []string) error {
 	configFile := constants.NetworkManagerConfigPath
 	configDir := filepath.Dir(configFile)
 
+	routingTLDs := routingTLDsFrom(ActiveLocalTLDs())
 	var content strings.Builder
 	content.WriteString("# srv local DNS configuration\n")
 	for _, tld := range routingTLDs {
@@ -348,7 +447,7 @@ func updateNetworkManagerConfig(domains []string) error {
 	}
 	for _, d := range domains {
 		bare := BareDomain(d)
-		if isUnderRoutingTLD(bare) {
+		if isUnderRoutingTLD(bare, routingTLDs) {
 			continue
 		}
 		// .local domains get a per-name server= line; other .local names stay
@@ -415,6 +514,9 @@ func RemoveDNS() error {
 		}
 		return shell.SudoSystemctl("restart", "NetworkManager")
 
+	case ResolverWSL2:
+		return removeWSL2Resolver()
+
 	default:
 		return nil
 	}
@@ -429,6 +531,8 @@ func GetResolverName() string {
 		return "macOS resolver"
 	case ResolverNetworkManager:
 		return "NetworkManager"
+	case ResolverWSL2:
+		return "WSL2 resolv.conf"
 	default:
 		return "unknown"
 	}
@@ -501,7 +605,7 @@ func SaveLocalDomains(domains []string) error {
 		content += "\n"
 	}
 
-	return os.WriteFile(path, []byte(content), constants.FilePermDefault)
+	return fsutil.AtomicWriteFile(path, []byte(content), constants.FilePermDefault)
 }
 
 // RegisterLocalDomain adds a domain to the local DNS registry and updates dnsmasq.
@@ -621,9 +725,10 @@ func buildDnsmasqConf(wildcards []string, aliases []ResolvedAlias, upstreamDNS [
 	if len(wildcards) == 0 {
 		b.WriteString("# No wildcard domains registered\n")
 	} else {
-		b.WriteString("# Wildcard domains — match the apex and every subdomain\n")
+		b.WriteString("# Wildcard domains — match the apex and every subdomain (A and AAAA)\n")
 		for _, d := range wildcards {
-			fmt.Fprintf(&b, "address=/%s/127.0.0.1\n", d)
+			fmt.Fprintf(&b, "address=/%s/%s\n", d, constants.LocalhostIP)
+			fmt.Fprintf(&b, "address=/%s/%s\n", d, constants.LocalhostIPv6)
 		}
 	}
 
@@ -644,24 +749,43 @@ func buildDnsmasqConf(wildcards []string, aliases []ResolvedAlias, upstreamDNS [
 	}
 
 	b.WriteString("\n# Forward all other queries to upstream DNS\n")
+	b.WriteString(dnsmasqServerLines(upstreamDNS))
+	b.WriteString("\n# Don't read /etc/resolv.conf\n")
+	b.WriteString("no-resolv\n")
+	return b.String()
+}
+
+// dnsmasqServerLines renders one server= directive per upstream resolver.
+func dnsmasqServerLines(upstreamDNS []string) string {
+	var b strings.Builder
 	for _, server := range upstreamDNS {
 		fmt.Fprintf(&b, "server=%s\n", server)
 	}
-	b.WriteString("\n# Don't read /etc/resolv.conf\n")
-	b.WriteString("no-resolv\n")
 	return b.String()
 }
 
+// UpstreamDNSServers returns the resolvers dnsmasq forwards to: the
+// upstream_dns setting, or Google DNS when it is unset.
+func UpstreamDNSServers() []string {
+	if servers := config.UserSettings().UpstreamDNS; len(servers) > 0 {
+		return servers
+	}
+	return []string{constants.GoogleDNS1, constants.GoogleDNS2}
+}
+
 // buildDnsmasqHosts renders the /etc/hosts-format file in the hostsdir. dnsmasq
-// auto-reloads this file without a restart. It always carries a header so the
-// file is never zero-length — dnsmasq cannot detect a change to an emptied
-// file, so removing the last domain still needs a non-empty file to land.
+// auto-reloads this file without a restart. Each domain gets an IPv4 and an
+// IPv6 loopback record so dual-stack clients get an AAAA answer. It always
+// carries a header so the file is never zero-length — dnsmasq cannot detect a
+// change to an emptied file, so removing the last domain still needs a
+// non-empty file to land.
 func buildDnsmasqHosts(exact []string) string {
 	var b strings.Builder
 	b.WriteString("# Local domains managed by srv — auto-reloaded by dnsmasq\n")
 	b.WriteString("# Do not edit manually - changes will be overwritten\n")
 	for _, d := range exact {
-		fmt.Fprintf(&b, "127.0.0.1 %s\n", d)
+		fmt.Fprintf(&b, "%s %s\n", constants.LocalhostIP, d)
+		fmt.Fprintf(&b, "%s %s\n", constants.LocalhostIPv6, d)
 	}
 	return b.String()
 }
@@ -705,10 +829,7 @@ func UpdateDnsmasqConfig() error {
 		}
 	}
 
-	upstreamDNS := []string{constants.GoogleDNS1, constants.GoogleDNS2}
-	if userCfg, ucErr := cfg.LoadUserConfig(); ucErr == nil && len(userCfg.UpstreamDNS) > 0 {
-		upstreamDNS = userCfg.UpstreamDNS
-	}
+	upstreamDNS := UpstreamDNSServers()
 
 	// Pick up DNS-alias redirects from redirect-<name>.yml files. Resolution
 	// errors land as commented-out entries inside the conf so a single
@@ -747,7 +868,8 @@ func UpdateDnsmasqConfig() error {
 	}
 
 	// Keep the system resolver routing config in sync so that every registered
-	// domain (not just .test/.local/.localhost TLDs) is routed through dnsmasq.
+	// domain and every active local TLD (built-in or user-added) is routed
+	// through dnsmasq.
 	switch DetectResolver() {
 	case ResolverSystemdResolved:
 		if _, err := os.Stat(constants.SystemdResolvedConfigPath); err == nil {
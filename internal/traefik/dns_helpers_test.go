@@ -3,10 +3,12 @@ package traefik
 import (
 	"errors"
 	"os"
+	"slices"
 	"strings"
 	"testing"
 
 	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/fsutil"
 )
 
 func TestIsUnderRoutingTLD(t *testing.T) {
@@ -27,12 +29,42 @@ func TestIsUnderRoutingTLD(t *testing.T) {
 		{"local", false},
 	}
 	for _, c := range cases {
-		if got := isUnderRoutingTLD(c.in); got != c.want {
+		if got := isUnderRoutingTLD(c.in, routingTLDsFrom(LocalDomains)); got != c.want {
 			t.Errorf("isUnderRoutingTLD(%q) = %v, want %v", c.in, got, c.want)
 		}
 	}
 }
 
+func TestMergeLocalTLDs(t *testing.T) {
+	got := MergeLocalTLDs([]string{"dev", " .Internal. ", "test", "", "dev"})
+	want := "test,local,localhost,dev,internal"
+	if strings.Join(got, ",") != want {
+		t.Errorf("MergeLocalTLDs = %v, want %s", got, want)
+	}
+	if strings.Join(MergeLocalTLDs(nil), ",") != strings.Join(LocalDomains, ",") {
+		t.Error("no custom TLDs should yield the built-in list")
+	}
+}
+
+func TestRoutingTLDsFromCustom(t *testing.T) {
+	tlds := routingTLDsFrom(MergeLocalTLDs([]string{"dev"}))
+	if slices.Contains(tlds, "local") {
+		t.Error(".local must never be routed TLD-wide")
+	}
+	if !isUnderRoutingTLD("app.dev", tlds) {
+		t.Error("custom TLD should be routed TLD-wide")
+	}
+	if isUnderRoutingTLD("app.dev", routingTLDsFrom(LocalDomains)) {
+		t.Error(".dev should not be routed without the custom TLD")
+	}
+}
+
+func TestDnsmasqListenAddr(t *testing.T) {
+	if got := dnsmasqListenAddr(); got != "127.0.0.1" {
+		t.Errorf("dnsmasqListenAddr() = %q, want the IPv4 loopback dnsmasq is published on", got)
+	}
+}
+
 func TestBuildDnsmasqConfNoWildcards(t *testing.T) {
 	out := buildDnsmasqConf(nil, nil, []string{"8.8.8.8"})
 	if !strings.Contains(out, "No wildcard domains") {
@@ -48,11 +80,15 @@ func TestBuildDnsmasqConfNoWildcards(t *testing.T) {
 
 func TestBuildDnsmasqConfWildcards(t *testing.T) {
 	out := buildDnsmasqConf([]string{"foo.local", "bar.local"}, nil, []string{"1.1.1.1"})
-	if !strings.Contains(out, "address=/foo.local/127.0.0.1") {
-		t.Error("wildcard 1 missing")
-	}
-	if !strings.Contains(out, "address=/bar.local/127.0.0.1") {
-		t.Error("wildcard 2 missing")
+	for _, want := range []string{
+		"address=/foo.local/127.0.0.1",
+		"address=/foo.local/::1",
+		"address=/bar.local/127.0.0.1",
+		"address=/bar.local/::1",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q", want)
+		}
 	}
 }
 
@@ -81,11 +117,10 @@ func TestBuildDnsmasqConfAliasResolveErr(t *testing.T) {
 
 func TestBuildDnsmasqHosts(t *testing.T) {
 	out := buildDnsmasqHosts([]string{"foo.local", "bar.local"})
-	if !strings.Contains(out, "127.0.0.1 foo.local") {
-		t.Error("entry 1 missing")
-	}
-	if !strings.Contains(out, "127.0.0.1 bar.local") {
-		t.Error("entry 2 missing")
+	for _, want := range []string{"127.0.0.1 foo.local", "::1 foo.local", "127.0.0.1 bar.local", "::1 bar.local"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q", want)
+		}
 	}
 }
 
@@ -94,7 +129,7 @@ func TestBuildDnsmasqHostsEmpty(t *testing.T) {
 	if !strings.HasPrefix(out, "#") {
 		t.Error("empty hosts file should still have header")
 	}
-	if strings.Contains(out, "127.0.0.1 ") {
+	if strings.Contains(out, "127.0.0.1 ") || strings.Contains(out, "::1 ") {
 		t.Error("no host entries expected")
 	}
 }
@@ -140,6 +175,36 @@ func TestSaveLoadLocalDomains(t *testing.T) {
 	}
 }
 
+// TestSaveLocalDomainsCrashKeepsOriginal simulates a crash between the temp
+// write and the rename: the registry must keep its previous contents.
+func TestSaveLocalDomainsCrashKeepsOriginal(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("SRV_ROOT", root)
+	config.ResetCache()
+	t.Cleanup(config.ResetCache)
+	if err := os.MkdirAll(root+"/traefik", 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveLocalDomains([]string{"foo.local"}); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(fsutil.SwapRename(func(string, string) error { panic("crash") }))
+	func() {
+		defer func() { _ = recover() }()
+		_ = SaveLocalDomains([]string{"bar.local"})
+		t.Error("expected the simulated crash")
+	}()
+
+	loaded, err := LoadLocalDomains()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(loaded, ",") != "foo.local" {
+		t.Errorf("domains = %v, want the original [foo.local]", loaded)
+	}
+}
+
 func TestLoadLocalDomainsMissing(t *testing.T) {
 	t.Setenv("SRV_ROOT", t.TempDir())
 	config.ResetCache()
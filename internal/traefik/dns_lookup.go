@@ -0,0 +1,268 @@
+// Package traefik — dns_lookup.go resolves a domain three ways for
+// `srv dns lookup`: straight at srv's dnsmasq, through the system resolver
+// (what browsers and apps use), and at a public resolver for comparison.
+// The direct queries use a minimal A-record client so the answer's TTL can be
+// shown; net.Resolver doesn't expose it.
+package traefik
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/stubbedev/srv/internal/constants"
+)
+
+// Resolution paths checked by LookupDomain.
+const (
+	LookupPathLocal  = "local"
+	LookupPathSystem = "system"
+	LookupPathPublic = "public"
+)
+
+// Problems DiagnoseLookup reports; each diagnosis starts with one.
+const (
+	DiagnosisNoSystemDNS = "system DNS not configured"
+	DiagnosisHijacked    = "DNS hijacking"
+)
+
+// lookupTimeout bounds each resolution path.
+const lookupTimeout = 5 * time.Second
+
+// publicDNSServer is the resolver local answers are compared against.
+var publicDNSServer = "8.8.8.8:53"
+
+// localDNSServer returns the address srv's dnsmasq answers on. Tests swap it.
+var localDNSServer = func() string { return net.JoinHostPort(dnsmasqListenAddr(), "53") }
+
+// systemLookup resolves through the system resolver. Tests swap it.
+var systemLookup = net.DefaultResolver.LookupHost
+
+// LookupResult is the outcome of resolving a domain through one path.
+type LookupResult struct {
+	Path   string   `json:"path"`
+	Server string   `json:"server"`
+	Addrs  []string `json:"addrs"`
+	// TTL is the smallest TTL of the answers, in seconds; -1 when the path
+	// doesn't report one (the system resolver).
+	TTL       int    `json:"ttl"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// OK reports whether the path returned at least one address.
+func (r LookupResult) OK() bool { return r.Error == "" && len(r.Addrs) > 0 }
+
+// LookupDomain resolves domain's IPv4 addresses through srv's DNS, the system
+// resolver and a public resolver, in that order.
+func LookupDomain(domain string) []LookupResult {
+	return []LookupResult{
+		lookupDirect(LookupPathLocal, localDNSServer(), domain),
+		lookupSystem(domain),
+		lookupDirect(LookupPathPublic, publicDNSServer, domain),
+	}
+}
+
+// lookupDirect queries server for domain's A records.
+func lookupDirect(path, server, domain string) LookupResult {
+	ctx, cancel := context.WithTimeout(context.Background(), lookupTimeout)
+	defer cancel()
+	res := LookupResult{Path: path, Server: server, TTL: -1}
+	start := time.Now()
+	addrs, ttl, err := queryA(ctx, server, domain)
+	res.LatencyMS = time.Since(start).Milliseconds()
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.Addrs, res.TTL = addrs, ttl
+	return res
+}
+
+// lookupSystem resolves domain's IPv4 addresses through the system resolver.
+func lookupSystem(domain string) LookupResult {
+	ctx, cancel := context.WithTimeout(context.Background(), lookupTimeout)
+	defer cancel()
+	res := LookupResult{Path: LookupPathSystem, Server: "system resolver", TTL: -1}
+	start := time.Now()
+	addrs, err := systemLookup(ctx, domain)
+	res.LatencyMS = time.Since(start).Milliseconds()
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	for _, a := range addrs {
+		if ip := net.ParseIP(a); ip != nil && ip.To4() != nil {
+			res.Addrs = append(res.Addrs, a)
+		}
+	}
+	if len(res.Addrs) == 0 {
+		res.Error = "no IPv4 address"
+	}
+	return res
+}
+
+// DiagnoseLookup explains a mismatch between the local and system paths of
+// LookupDomain's results; it returns "" when there is nothing to report. Only
+// srv's own domains (answered with 127.0.0.1) are diagnosed: names dnsmasq
+// forwards upstream can legitimately differ between resolvers.
+func DiagnoseLookup(results []LookupResult) string {
+	var local, system LookupResult
+	for _, r := range results {
+		switch r.Path {
+		case LookupPathLocal:
+			local = r
+		case LookupPathSystem:
+			system = r
+		}
+	}
+	switch {
+	case !local.OK() || !slices.Contains(local.Addrs, constants.LocalhostIP):
+		return ""
+	case !system.OK():
+		return DiagnosisNoSystemDNS + ": srv's DNS answers but the system resolver doesn't ask it"
+	case !slices.ContainsFunc(system.Addrs, func(a string) bool { return slices.Contains(local.Addrs, a) }):
+		return fmt.Sprintf("%s: the system resolver returned %s instead of %s", DiagnosisHijacked,
+			strings.Join(system.Addrs, ", "), strings.Join(local.Addrs, ", "))
+	}
+	return ""
+}
+
+// DNS wire-format constants used by queryA (RFC 1035).
+const (
+	dnsTypeA      = 1
+	dnsClassIN    = 1
+	dnsFlagRD     = 0x0100
+	dnsRcodeMask  = 0x000F
+	dnsRcodeNXDom = 3
+	dnsHeaderLen  = 12
+)
+
+// queryA sends one A query for domain to server over UDP and returns the
+// addresses in the answer and their smallest TTL.
+func queryA(ctx context.Context, server, domain string) ([]string, int, error) {
+	var id [2]byte
+	_, _ = rand.Read(id[:])
+	query, err := buildAQuery(binary.BigEndian.Uint16(id[:]), domain)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "udp", server)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = conn.Close() }()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if _, err := conn.Write(query); err != nil {
+		return nil, 0, err
+	}
+	buf := make([]byte, 1232)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, 0, err
+	}
+	return parseAResponse(buf[:n], binary.BigEndian.Uint16(id[:]))
+}
+
+// buildAQuery encodes a recursive A/IN query for domain.
+func buildAQuery(id uint16, domain string) ([]byte, error) {
+	msg := make([]byte, dnsHeaderLen, dnsHeaderLen+len(domain)+6)
+	binary.BigEndian.PutUint16(msg[0:], id)
+	binary.BigEndian.PutUint16(msg[2:], dnsFlagRD)
+	binary.BigEndian.PutUint16(msg[4:], 1) // QDCOUNT
+	for _, label := range strings.Split(strings.TrimSuffix(domain, "."), ".") {
+		if label == "" || len(label) > 63 {
+			return nil, fmt.Errorf("invalid domain %q", domain)
+		}
+		msg = append(msg, byte(len(label)))
+		msg = append(msg, label...)
+	}
+	msg = append(msg, 0)
+	msg = binary.BigEndian.AppendUint16(msg, dnsTypeA)
+	msg = binary.BigEndian.AppendUint16(msg, dnsClassIN)
+	return msg, nil
+}
+
+// errShortDNSMessage reports a truncated or malformed response.
+var errShortDNSMessage = errors.New("malformed DNS response")
+
+// parseAResponse extracts the A records (and smallest TTL) from a response to
+// the query with the given id. CNAMEs and other records are skipped.
+func parseAResponse(msg []byte, id uint16) ([]string, int, error) {
+	if len(msg) < dnsHeaderLen {
+		return nil, 0, errShortDNSMessage
+	}
+	if binary.BigEndian.Uint16(msg[0:]) != id {
+		return nil, 0, errors.New("DNS response does not match the query")
+	}
+	switch rcode := binary.BigEndian.Uint16(msg[2:]) & dnsRcodeMask; rcode {
+	case 0:
+	case dnsRcodeNXDom:
+		return nil, 0, errors.New("no such host")
+	default:
+		return nil, 0, fmt.Errorf("DNS server returned rcode %d", rcode)
+	}
+	qdcount := int(binary.BigEndian.Uint16(msg[4:]))
+	ancount := int(binary.BigEndian.Uint16(msg[6:]))
+
+	off := dnsHeaderLen
+	for range qdcount {
+		var ok bool
+		if off, ok = skipDNSName(msg, off); !ok || off+4 > len(msg) {
+			return nil, 0, errShortDNSMessage
+		}
+		off += 4 // QTYPE, QCLASS
+	}
+
+	var addrs []string
+	ttl := -1
+	for range ancount {
+		var ok bool
+		if off, ok = skipDNSName(msg, off); !ok || off+10 > len(msg) {
+			return nil, 0, errShortDNSMessage
+		}
+		rtype := binary.BigEndian.Uint16(msg[off:])
+		rttl := int(binary.BigEndian.Uint32(msg[off+4:]))
+		rdlen := int(binary.BigEndian.Uint16(msg[off+8:]))
+		off += 10
+		if off+rdlen > len(msg) {
+			return nil, 0, errShortDNSMessage
+		}
+		if rtype == dnsTypeA && rdlen == net.IPv4len {
+			addrs = append(addrs, net.IP(msg[off:off+rdlen]).String())
+			if ttl < 0 || rttl < ttl {
+				ttl = rttl
+			}
+		}
+		off += rdlen
+	}
+	if len(addrs) == 0 {
+		return nil, 0, errors.New("no A record")
+	}
+	return addrs, ttl, nil
+}
+
+// skipDNSName returns the offset just past the (possibly compressed) name
+// starting at off.
+func skipDNSName(msg []byte, off int) (int, bool) {
+	for off < len(msg) {
+		switch l := int(msg[off]); {
+		case l == 0:
+			return off + 1, true
+		case l&0xC0 == 0xC0:
+			return off + 2, off+2 <= len(msg)
+		default:
+			off += 1 + l
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,106 @@
+package traefik
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// serveOneDNSAnswer answers every A query on a loopback UDP port with addr
+// (TTL ttl), behind a CNAME so name compression and record skipping are
+// exercised. Returns the server address.
+func serveOneDNSAnswer(t *testing.T, addr net.IP, ttl uint32) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen on UDP: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, from, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			resp := append([]byte(nil), buf[:n]...)
+			binary.BigEndian.PutUint16(resp[2:], 0x8180) // response, RD, RA
+			binary.BigEndian.PutUint16(resp[6:], 2)      // ANCOUNT
+			// CNAME: pointer to the question name -> "edge" + pointer.
+			resp = append(resp, 0xC0, dnsHeaderLen, 0, 5, 0, 1, 0, 0, 0, 60, 0, 7, 4, 'e', 'd', 'g', 'e', 0xC0, dnsHeaderLen)
+			resp = append(resp, 0xC0, dnsHeaderLen, 0, dnsTypeA, 0, dnsClassIN)
+			resp = binary.BigEndian.AppendUint32(resp, ttl)
+			resp = append(resp, 0, 4)
+			resp = append(resp, addr.To4()...)
+			_, _ = conn.WriteTo(resp, from)
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func TestQueryA(t *testing.T) {
+	server := serveOneDNSAnswer(t, net.ParseIP("127.0.0.1"), 300)
+	addrs, ttl, err := queryA(context.Background(), server, "app.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(addrs, []string{"127.0.0.1"}) || ttl != 300 {
+		t.Errorf("queryA = %v ttl %d, want [127.0.0.1] ttl 300", addrs, ttl)
+	}
+}
+
+func TestParseAResponseErrors(t *testing.T) {
+	query, err := buildAQuery(7, "app.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	nx := append([]byte(nil), query...)
+	binary.BigEndian.PutUint16(nx[2:], 0x8183)
+	if _, _, err := parseAResponse(nx, 7); err == nil || !strings.Contains(err.Error(), "no such host") {
+		t.Errorf("NXDOMAIN -> %v", err)
+	}
+	if _, _, err := parseAResponse(query, 8); err == nil {
+		t.Error("expected an error for a mismatched ID")
+	}
+	if _, _, err := parseAResponse(query[:5], 7); err == nil {
+		t.Error("expected an error for a truncated message")
+	}
+	if _, err := buildAQuery(1, "bad..name"); err == nil {
+		t.Error("expected an error for an empty label")
+	}
+}
+
+func TestLookupDomain(t *testing.T) {
+	local := serveOneDNSAnswer(t, net.ParseIP("127.0.0.1"), 0)
+	public := serveOneDNSAnswer(t, net.ParseIP("203.0.113.9"), 60)
+	origLocal, origPublic, origSystem := localDNSServer, publicDNSServer, systemLookup
+	t.Cleanup(func() { localDNSServer, publicDNSServer, systemLookup = origLocal, origPublic, origSystem })
+	localDNSServer = func() string { return local }
+	publicDNSServer = public
+
+	systemLookup = func(context.Context, string) ([]string, error) { return []string{"127.0.0.1", "::1"}, nil }
+	results := LookupDomain("app.test")
+	if len(results) != 3 || !results[0].OK() || !results[1].OK() || results[2].Addrs[0] != "203.0.113.9" {
+		t.Fatalf("results = %+v", results)
+	}
+	if !slices.Equal(results[1].Addrs, []string{"127.0.0.1"}) || results[1].TTL != -1 {
+		t.Errorf("system = %+v, want only the IPv4 address and no TTL", results[1])
+	}
+	if d := DiagnoseLookup(results); d != "" {
+		t.Errorf("healthy lookup diagnosed %q", d)
+	}
+
+	systemLookup = func(context.Context, string) ([]string, error) { return nil, errors.New("no such host") }
+	if d := DiagnoseLookup(LookupDomain("app.test")); !strings.HasPrefix(d, "system DNS not configured") {
+		t.Errorf("unresolved system path diagnosed %q", d)
+	}
+
+	systemLookup = func(context.Context, string) ([]string, error) { return []string{"198.51.100.7"}, nil }
+	if d := DiagnoseLookup(LookupDomain("app.test")); !strings.HasPrefix(d, "DNS hijacking") || !strings.Contains(d, "198.51.100.7") {
+		t.Errorf("wrong system answer diagnosed %q", d)
+	}
+}
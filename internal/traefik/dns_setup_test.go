@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -222,6 +223,29 @@ func TestFlushDNSCache(t *testing.T) {
 	FlushDNSCache() // no return value; just confirm no panic
 }
 
+func TestFlushAllDNSCachesRestartsNscd(t *testing.T) {
+	fake := shelltest.New(map[string]shelltest.Response{"nscd": {Exists: true}})
+	swapShell(t, fake)
+	flushed, err := FlushAllDNSCaches()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(flushed, "nscd") {
+		t.Errorf("flushed = %v, want nscd listed", flushed)
+	}
+	if !slices.ContainsFunc(fake.Snapshot(), func(c shelltest.Call) bool {
+		return c.Method == "SudoRun" && strings.Join(c.Args, " ") == "service nscd restart"
+	}) {
+		t.Errorf("nscd not restarted; calls: %+v", fake.Snapshot())
+	}
+
+	fake = shelltest.New(map[string]shelltest.Response{"nscd": {Exists: true}, "sudo:service": {Err: errors.New("denied")}})
+	swapShell(t, fake)
+	if _, err := FlushAllDNSCaches(); err == nil {
+		t.Error("expected an error when nscd cannot be restarted")
+	}
+}
+
 func TestSetupSystemdResolvedCallsUpdate(t *testing.T) {
 	root := t.TempDir()
 	t.Setenv("SRV_ROOT", root)
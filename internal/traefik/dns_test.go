@@ -217,8 +217,10 @@ func TestGenerateDnsmasqConfig(t *testing.T) {
 			}
 		}
 		withWildcard := buildDnsmasqConf([]string{"foo.test"}, nil, []string{"1.1.1.1"})
-		if !strings.Contains(withWildcard, "address=/foo.test/127.0.0.1") {
-			t.Errorf("buildDnsmasqConf missing wildcard directive in:\n%s", withWildcard)
+		for _, want := range []string{"address=/foo.test/127.0.0.1", "address=/foo.test/::1"} {
+			if !strings.Contains(withWildcard, want) {
+				t.Errorf("buildDnsmasqConf missing %q in:\n%s", want, withWildcard)
+			}
 		}
 	})
 
@@ -229,7 +231,7 @@ func TestGenerateDnsmasqConfig(t *testing.T) {
 			t.Error("buildDnsmasqHosts(nil) must not be empty")
 		}
 		hosts := buildDnsmasqHosts([]string{"api.test", "web.test"})
-		for _, want := range []string{"127.0.0.1 api.test", "127.0.0.1 web.test"} {
+		for _, want := range []string{"127.0.0.1 api.test", "::1 api.test", "127.0.0.1 web.test", "::1 web.test"} {
 			if !strings.Contains(hosts, want) {
 				t.Errorf("buildDnsmasqHosts missing %q in:\n%s", want, hosts)
 			}
@@ -237,12 +239,17 @@ func TestGenerateDnsmasqConfig(t *testing.T) {
 	})
 
 	t.Run("DnsmasqConf constant matches builder output", func(t *testing.T) {
-		// EnsureConfig writes the DnsmasqConf constant on a fresh install;
+		// EnsureConfig writes the rendered DnsmasqConf on a fresh install;
 		// if it drifts from the builder, the first domain add sees a spurious
 		// config change and needlessly restarts the DNS container.
-		want := buildDnsmasqConf(nil, nil, []string{constants.GoogleDNS1, constants.GoogleDNS2})
-		if DnsmasqConf != want {
-			t.Errorf("DnsmasqConf drifted from buildDnsmasqConf:\n--- const ---\n%s\n--- builder ---\n%s", DnsmasqConf, want)
+		for _, upstream := range [][]string{
+			{constants.GoogleDNS1, constants.GoogleDNS2},
+			{"1.1.1.1", "2606:4700:4700::1111"},
+		} {
+			want := buildDnsmasqConf(nil, nil, upstream)
+			if got := RenderDnsmasqConf(upstream); got != want {
+				t.Errorf("DnsmasqConf drifted from buildDnsmasqConf:\n--- const ---\n%s\n--- builder ---\n%s", got, want)
+			}
 		}
 	})
 
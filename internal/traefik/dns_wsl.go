@@ -0,0 +1,168 @@
+// Package traefik — dns_wsl.go routes local domains to dnsmasq under WSL2.
+// WSL2 writes /etc/resolv.conf itself on every start and has no per-domain
+// resolver, so srv turns the generation off in /etc/wsl.conf and puts
+// dnsmasq first in resolv.conf. dnsmasq forwards everything else upstream;
+// WSL's own nameservers stay listed as fallbacks for when it isn't running.
+package traefik
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/stubbedev/srv/internal/constants"
+	"github.com/stubbedev/srv/internal/shell"
+)
+
+// wslConfMarker precedes the generateResolvConf line srv adds to wsl.conf,
+// so RemoveDNS only takes out what srv put there.
+const wslConfMarker = "# srv: keep /etc/resolv.conf pointing at local DNS"
+
+// wslResolvHeader opens the resolv.conf srv writes under WSL2.
+const wslResolvHeader = "# Generated by srv: local domains resolve through dnsmasq; the\n" +
+	"# nameservers after it are WSL's own, kept as fallbacks.\n"
+
+// setupWSL2Resolver stops WSL2 regenerating /etc/resolv.conf and points the
+// resolver at dnsmasq.
+func setupWSL2Resolver() error {
+	existing, _ := os.ReadFile(constants.WSLConfPath)
+	if conf := renderWSLConf(string(existing)); conf != string(existing) {
+		if err := shell.SudoWrite(constants.WSLConfPath, conf); err != nil {
+			return fmt.Errorf("failed to update %s: %w", constants.WSLConfPath, err)
+		}
+	}
+
+	current, err := os.ReadFile(constants.ResolvConfPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", constants.ResolvConfPath, err)
+	}
+	content := renderWSLResolvConf(string(current))
+	if content == string(current) {
+		return nil
+	}
+	// WSL usually links resolv.conf into a directory it regenerates; with
+	// generation off, the link has to become a plain file.
+	if info, err := os.Lstat(constants.ResolvConfPath); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		if err := shell.SudoRemove(constants.ResolvConfPath); err != nil {
+			return fmt.Errorf("failed to replace %s: %w", constants.ResolvConfPath, err)
+		}
+	}
+	if err := shell.SudoWrite(constants.ResolvConfPath, content); err != nil {
+		return fmt.Errorf("failed to write %s: %w", constants.ResolvConfPath, err)
+	}
+	return nil
+}
+
+// removeWSL2Resolver undoes setupWSL2Resolver. WSL regenerates
+// /etc/resolv.conf again from its next start ('wsl --shutdown').
+func removeWSL2Resolver() error {
+	if current, err := os.ReadFile(constants.ResolvConfPath); err == nil {
+		if restored := stripWSLResolvConf(string(current)); restored != string(current) {
+			if err := shell.SudoWrite(constants.ResolvConfPath, restored); err != nil {
+				return fmt.Errorf("failed to restore %s: %w", constants.ResolvConfPath, err)
+			}
+		}
+	}
+	if existing, err := os.ReadFile(constants.WSLConfPath); err == nil {
+		if conf := stripWSLConf(string(existing)); conf != string(existing) {
+			if err := shell.SudoWrite(constants.WSLConfPath, conf); err != nil {
+				return fmt.Errorf("failed to update %s: %w", constants.WSLConfPath, err)
+			}
+		}
+	}
+	return nil
+}
+
+// renderWSLConf returns wsl.conf with generateResolvConf = false in its
+// [network] section. A setting that is already false is left alone.
+func renderWSLConf(existing string) string {
+	lines := splitLines(existing)
+	section := ""
+	networkEnd := -1
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			section = strings.ToLower(strings.Trim(trimmed, "[]"))
+			if section == "network" {
+				networkEnd = i + 1
+			}
+			continue
+		}
+		if section != "network" {
+			continue
+		}
+		if trimmed != "" {
+			networkEnd = i + 1
+		}
+		key, value, ok := strings.Cut(trimmed, "=")
+		if !ok || !strings.EqualFold(strings.TrimSpace(key), "generateResolvConf") {
+			continue
+		}
+		if strings.EqualFold(strings.TrimSpace(value), "false") {
+			return existing
+		}
+		// Comment the user's value out rather than lose it.
+		lines[i] = "# " + line + "\n" + wslConfMarker + "\ngenerateResolvConf = false"
+		return joinLines(lines)
+	}
+	setting := wslConfMarker + "\ngenerateResolvConf = false"
+	if networkEnd < 0 {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		return joinLines(append(lines, "[network]", setting))
+	}
+	lines = append(lines[:networkEnd], append([]string{setting}, lines[networkEnd:]...)...)
+	return joinLines(lines)
+}
+
+// stripWSLConf removes the setting renderWSLConf added, restoring a value it
+// commented out.
+func stripWSLConf(existing string) string {
+	lines := splitLines(existing)
+	out := make([]string, 0, len(lines))
+	for i := 0; i < len(lines); i++ {
+		if lines[i] != wslConfMarker {
+			out = append(out, lines[i])
+			continue
+		}
+		i++ // the generateResolvConf line
+		if n := len(out); n > 0 && strings.HasPrefix(out[n-1], "# ") &&
+			strings.Contains(strings.ToLower(out[n-1]), "generateresolvconf") {
+			out[n-1] = strings.TrimPrefix(out[n-1], "# ")
+		}
+	}
+	return joinLines(out)
+}
+
+// renderWSLResolvConf puts dnsmasq ahead of the nameservers in existing,
+// keeping everything else. A file srv already wrote is rebuilt from the
+// original lines, so repeated calls don't stack.
+func renderWSLResolvConf(existing string) string {
+	original := stripWSLResolvConf(existing)
+	return wslResolvHeader + "nameserver " + dnsmasqListenAddr() + "\n" + original
+}
+
+// stripWSLResolvConf removes what renderWSLResolvConf added.
+func stripWSLResolvConf(existing string) string {
+	rest, ok := strings.CutPrefix(existing, wslResolvHeader)
+	if !ok {
+		return existing
+	}
+	_, rest, _ = strings.Cut(rest, "\n") // our nameserver line
+	return rest
+}
+
+// splitLines splits s into lines without a trailing empty one.
+func splitLines(s string) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// joinLines is the inverse of splitLines.
+func joinLines(lines []string) string {
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,58 @@
+package traefik
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderWSLConf(t *testing.T) {
+	cases := map[string]struct{ in, want string }{
+		"empty": {"", "[network]\n" + wslConfMarker + "\ngenerateResolvConf = false\n"},
+		"other section": {
+			"[boot]\nsystemd=true\n",
+			"[boot]\nsystemd=true\n\n[network]\n" + wslConfMarker + "\ngenerateResolvConf = false\n",
+		},
+		"network section": {
+			"[network]\nhostname = dev\n\n[boot]\nsystemd=true\n",
+			"[network]\nhostname = dev\n" + wslConfMarker + "\ngenerateResolvConf = false\n\n[boot]\nsystemd=true\n",
+		},
+		"enabled": {
+			"[network]\ngenerateResolvConf = true\n",
+			"[network]\n# generateResolvConf = true\n" + wslConfMarker + "\ngenerateResolvConf = false\n",
+		},
+		"already off": {"[network]\ngenerateResolvConf=false\n", "[network]\ngenerateResolvConf=false\n"},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := renderWSLConf(c.in)
+			if got != c.want {
+				t.Fatalf("renderWSLConf =\n%s\nwant\n%s", got, c.want)
+			}
+			if again := renderWSLConf(got); again != got {
+				t.Errorf("second render changed the file:\n%s", again)
+			}
+			if name != "empty" && name != "other section" {
+				if restored := stripWSLConf(got); restored != c.in {
+					t.Errorf("stripWSLConf =\n%s\nwant\n%s", restored, c.in)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderWSLResolvConf(t *testing.T) {
+	original := "# This file was automatically generated by WSL.\nnameserver 10.255.255.254\nsearch corp.example\n"
+	got := renderWSLResolvConf(original)
+	if !strings.HasPrefix(got, wslResolvHeader+"nameserver 127.0.0.1\n") || !strings.HasSuffix(got, original) {
+		t.Fatalf("renderWSLResolvConf =\n%s", got)
+	}
+	if again := renderWSLResolvConf(got); again != got {
+		t.Errorf("second render stacked:\n%s", again)
+	}
+	if restored := stripWSLResolvConf(got); restored != original {
+		t.Errorf("stripWSLResolvConf =\n%s\nwant\n%s", restored, original)
+	}
+	if stripWSLResolvConf(original) != original {
+		t.Error("stripWSLResolvConf changed a file srv didn't write")
+	}
+}
@@ -7,7 +7,22 @@
 // injection-safe path for generating these files.
 package traefik
 
-import "gopkg.in/yaml.v3"
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/stubbedev/srv/internal/constants"
+	"github.com/stubbedev/srv/internal/validate"
+)
 
 // dynServer is a single upstream URL in a load balancer.
 type dynServer struct {
@@ -16,17 +31,38 @@ type dynServer struct {
 
 // dynLoadBalancer is a service's set of upstream servers.
 type dynLoadBalancer struct {
-	Servers          []dynServer `yaml:"servers"`
-	PassHostHeader   *bool       `yaml:"passHostHeader,omitempty"`
-	ServersTransport string      `yaml:"serversTransport,omitempty"` // name of a serversTransports entry
+	Servers          []dynServer     `yaml:"servers"`
+	PassHostHeader   *bool           `yaml:"passHostHeader,omitempty"`
+	ServersTransport string          `yaml:"serversTransport,omitempty"` // name of a serversTransports entry
+	Strategy         string          `yaml:"strategy,omitempty"`         // wrr (Traefik default) or p2c
+	HealthCheck      *dynHealthCheck `yaml:"healthCheck,omitempty"`
+}
+
+// dynHealthCheck makes Traefik probe each server and stop sending it
+// traffic while the probe fails.
+type dynHealthCheck struct {
+	Path     string `yaml:"path"`
+	Interval string `yaml:"interval,omitempty"`
+	Timeout  string `yaml:"timeout,omitempty"`
 }
 
-// dynServersTransport configures how Traefik dials an HTTPS upstream. Only the
-// insecureSkipVerify knob is modelled — it lets an upstream whose certificate
-// can't be verified (self-signed, or a cert whose SAN doesn't match its IP) be
-// reached. Referenced by name from dynLoadBalancer.ServersTransport.
+// dynServersTransport configures how Traefik dials an upstream.
+// insecureSkipVerify lets an upstream whose certificate can't be verified
+// (self-signed, or a cert whose SAN doesn't match its IP) be reached;
+// forwardingTimeouts overrides Traefik's dial/response timeouts. Referenced by
+// name from dynLoadBalancer.ServersTransport.
 type dynServersTransport struct {
-	InsecureSkipVerify bool `yaml:"insecureSkipVerify"`
+	InsecureSkipVerify bool                   `yaml:"insecureSkipVerify"`
+	ForwardingTimeouts *dynForwardingTimeouts `yaml:"forwardingTimeouts,omitempty"`
+}
+
+// dynForwardingTimeouts is the forwardingTimeouts block of a serversTransport.
+// Values are Go duration strings (e.g. "90s"); empty keeps Traefik's default.
+type dynForwardingTimeouts struct {
+	DialTimeout           string `yaml:"dialTimeout,omitempty"`
+	ResponseHeaderTimeout string `yaml:"responseHeaderTimeout,omitempty"`
+	ReadIdleTimeout       string `yaml:"readIdleTimeout,omitempty"`
+	IdleConnTimeout       string `yaml:"idleConnTimeout,omitempty"`
 }
 
 // dynService wraps a load balancer under the Traefik `services` map.
@@ -64,10 +100,137 @@ type dynReplacePathRegex struct {
 	Replacement string `yaml:"replacement"`
 }
 
+// dynHeaders is the headers middleware; only custom header injection is
+// modelled. An empty value removes the header.
+type dynHeaders struct {
+	CustomRequestHeaders  map[string]string `yaml:"customRequestHeaders,omitempty"`
+	CustomResponseHeaders map[string]string `yaml:"customResponseHeaders,omitempty"`
+}
+
+// dynStripPrefix is the stripPrefix middleware (used by path-prefixed proxies).
+type dynStripPrefix struct {
+	Prefixes []string `yaml:"prefixes"`
+}
+
+// dynIPAllowList is the ipAllowList middleware (used by sites with allow_ips).
+type dynIPAllowList struct {
+	SourceRange []string `yaml:"sourceRange"`
+}
+
+// dynRateLimit is the rateLimit middleware: Average requests per Period,
+// per client IP.
+type dynRateLimit struct {
+	Average int    `yaml:"average"`
+	Period  string `yaml:"period"`
+}
+
+// DefaultRetryInterval is Traefik's initial back-off for a retry middleware
+// that doesn't set one.
+const DefaultRetryInterval = "100ms"
+
+// dynRetry is the retry middleware: a request that fails to reach the
+// upstream is reissued up to Attempts times, backing off from
+// InitialInterval.
+type dynRetry struct {
+	Attempts        int    `yaml:"attempts"`
+	InitialInterval string `yaml:"initialInterval,omitempty"`
+}
+
+// dynCompress is the compress middleware; its defaults are used as-is.
+type dynCompress struct{}
+
+// dynBuffering is the buffering middleware, used to cap body sizes: Traefik
+// answers 413 to a larger request and 500 when the upstream's response is
+// larger.
+type dynBuffering struct {
+	MaxRequestBodyBytes  int64 `yaml:"maxRequestBodyBytes,omitempty"`
+	MaxResponseBodyBytes int64 `yaml:"maxResponseBodyBytes,omitempty"`
+}
+
 // dynMiddleware is a Traefik middleware. Exactly one field is set per instance.
 type dynMiddleware struct {
 	RedirectRegex    *dynRedirectRegex    `yaml:"redirectRegex,omitempty"`
 	ReplacePathRegex *dynReplacePathRegex `yaml:"replacePathRegex,omitempty"`
+	StripPrefix      *dynStripPrefix      `yaml:"stripPrefix,omitempty"`
+	Headers          *dynHeaders          `yaml:"headers,omitempty"`
+	IPAllowList      *dynIPAllowList      `yaml:"ipAllowList,omitempty"`
+	RateLimit        *dynRateLimit        `yaml:"rateLimit,omitempty"`
+	Compress         *dynCompress         `yaml:"compress,omitempty"`
+	Buffering        *dynBuffering        `yaml:"buffering,omitempty"`
+	Retry            *dynRetry            `yaml:"retry,omitempty"`
+}
+
+// rateLimitPeriods maps the unit of a rate limit spec to Traefik's period.
+var rateLimitPeriods = map[string]string{"S": "1s", "M": "1m", "H": "1h"}
+
+// ParseRateLimit parses a rate limit written AVERAGE-UNIT, e.g. 100-S for 100
+// requests a second per client (units S, M and H), into Traefik's average and
+// period.
+func ParseRateLimit(spec string) (average int, period string, err error) {
+	avg, unit, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	period, known := rateLimitPeriods[strings.ToUpper(unit)]
+	n, convErr := strconv.Atoi(avg)
+	if !ok || !known || convErr != nil || n < 1 {
+		return 0, "", fmt.Errorf("invalid rate limit %q (expected AVERAGE-UNIT with unit S, M or H, e.g. 100-S)", spec)
+	}
+	return n, period, nil
+}
+
+// Body size limits accepted by ParseBodySize.
+const (
+	minBodySize int64 = 1 << 10
+	maxBodySize int64 = 10 << 30
+)
+
+// bodySizeUnits maps the unit suffix of a body size to its multiplier.
+var bodySizeUnits = map[string]int64{"KB": 1 << 10, "MB": 1 << 20, "GB": 1 << 30}
+
+// ParseBodySize parses a body size limit written NUMBER+UNIT, e.g. 10MB
+// (units KB, MB and GB, powers of 1024), into bytes. Sizes must be between
+// 1KB and 10GB.
+func ParseBodySize(spec string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(spec))
+	if len(s) > 2 {
+		if mult, ok := bodySizeUnits[s[len(s)-2:]]; ok {
+			n, err := strconv.ParseInt(s[:len(s)-2], 10, 64)
+			if err == nil && n > 0 && n <= maxBodySize/mult {
+				if size := n * mult; size >= minBodySize {
+					return size, nil
+				}
+			}
+		}
+	}
+	return 0, fmt.Errorf("invalid body size %q (expected 1KB to 10GB with unit KB, MB or GB, e.g. 10MB)", spec)
+}
+
+// websocketIdleTimeout keeps idle upstream connections open long enough for
+// quiet WebSocket sessions instead of Traefik's 90s default.
+const websocketIdleTimeout = "3600s"
+
+// websocketMiddleware returns the headers middleware that marks forwarded
+// requests as WebSocket upgrades, for upstreams that only speak WebSocket.
+func websocketMiddleware() dynMiddleware {
+	return dynMiddleware{Headers: &dynHeaders{CustomRequestHeaders: map[string]string{
+		"Connection": "Upgrade",
+		"Upgrade":    "websocket",
+	}}}
+}
+
+// ResponseHeaderEdits merges headers to set and headers to strip into one
+// customResponseHeaders map, where Traefik reads an empty value as "remove".
+// Returns nil when there is nothing to change.
+func ResponseHeaderEdits(add map[string]string, remove []string) map[string]string {
+	if len(add) == 0 && len(remove) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(add)+len(remove))
+	for k, v := range add {
+		out[k] = v
+	}
+	for _, k := range remove {
+		out[k] = ""
+	}
+	return out
 }
 
 // dynHTTP is the `http` block: routers, services, and optional middlewares.
@@ -78,9 +241,44 @@ type dynHTTP struct {
 	ServersTransports map[string]dynServersTransport `yaml:"serversTransports,omitempty"`
 }
 
+// dynTCP is the `tcp` block of a TCP proxy: SNI routers and the services
+// they forward raw connections to.
+type dynTCP struct {
+	Routers  map[string]dynTCPRouter  `yaml:"routers"`
+	Services map[string]dynTCPService `yaml:"services"`
+}
+
+// dynTCPRouter is a Traefik TCP router. Routing on HostSNI needs TLS: an
+// empty TLS block terminates it with the file-provider certs, passthrough
+// forwards the encrypted stream untouched.
+type dynTCPRouter struct {
+	Rule        string     `yaml:"rule"`
+	EntryPoints []string   `yaml:"entryPoints"`
+	Service     string     `yaml:"service"`
+	TLS         *dynTCPTLS `yaml:"tls,omitempty"`
+}
+
+type dynTCPTLS struct {
+	Passthrough bool `yaml:"passthrough,omitempty"`
+}
+
+type dynTCPService struct {
+	LoadBalancer dynTCPLoadBalancer `yaml:"loadBalancer"`
+}
+
+type dynTCPLoadBalancer struct {
+	Servers []dynTCPServer `yaml:"servers"`
+}
+
+// dynTCPServer is a TCP upstream as host:port.
+type dynTCPServer struct {
+	Address string `yaml:"address"`
+}
+
 // DynConfig is a complete Traefik file-provider dynamic config document.
 type DynConfig struct {
-	HTTP dynHTTP `yaml:"http"`
+	HTTP dynHTTP `yaml:"http,omitempty"`
+	TCP  *dynTCP `yaml:"tcp,omitempty"`
 }
 
 // localTLS returns the TLS block for a local (file-provider cert) router:
@@ -91,8 +289,203 @@ func localTLS() *dynTLS { return &dynTLS{} }
 // ACME cert resolver (Let's Encrypt).
 func resolverTLS(resolver string) *dynTLS { return &dynTLS{CertResolver: resolver} }
 
+// ACMEResolver returns the cert resolver for a production site: the Let's
+// Encrypt staging resolver when staging is set, the real one otherwise.
+func ACMEResolver(staging bool) string {
+	if staging {
+		return constants.CertResolverLetsEncryptStaging
+	}
+	return constants.CertResolverLetsEncrypt
+}
+
 // MarshalDynConfig renders a DynConfig to YAML. It is the single marshalling
 // entry point so callers never hand-assemble Traefik YAML as strings.
 func MarshalDynConfig(c DynConfig) ([]byte, error) {
 	return yaml.Marshal(&c)
 }
+
+// ConfigError is one problem in a dynamic config file. Line is 1-based, or 0
+// when the problem can't be pinned to a line.
+type ConfigError struct {
+	Line int
+	Msg  string
+}
+
+func (e ConfigError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+	}
+	return e.Msg
+}
+
+// ValidateDynConfig checks a file-provider dynamic config without loading it
+// into Traefik. The typed model above doubles as the schema: unknown keys and
+// mistyped values are rejected. On top of that every router needs a rule, an
+// entrypoint, and a service defined in the same file (or an @provider
+// reference), and every service needs at least one server with a valid URL.
+// Router middlewares are not checked — srv resolves them from other files.
+func ValidateDynConfig(data []byte) []ConfigError {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		line, msg := validate.YAMLError(err)
+		return []ConfigError{{Line: line, Msg: msg}}
+	}
+
+	var conf DynConfig
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&conf); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil // empty file: nothing for Traefik to load
+		}
+		var te *yaml.TypeError
+		if !errors.As(err, &te) {
+			line, msg := validate.YAMLError(err)
+			return []ConfigError{{Line: line, Msg: msg}}
+		}
+		out := make([]ConfigError, 0, len(te.Errors))
+		for _, msg := range te.Errors {
+			line, msg := validate.YAMLError(errors.New(msg))
+			out = append(out, ConfigError{Line: line, Msg: msg})
+		}
+		return out
+	}
+
+	var out []ConfigError
+	for _, name := range sortedKeys(conf.HTTP.Routers) {
+		r := conf.HTTP.Routers[name]
+		line := nodeLine(&root, "http", "routers", name)
+		if strings.TrimSpace(r.Rule) == "" {
+			out = append(out, ConfigError{Line: line, Msg: fmt.Sprintf("router %q has no rule", name)})
+		} else if err := checkRuleSyntax(r.Rule); err != nil {
+			out = append(out, ConfigError{Line: line, Msg: fmt.Sprintf("router %q: %v", name, err)})
+		}
+		if len(r.EntryPoints) == 0 {
+			out = append(out, ConfigError{Line: line, Msg: fmt.Sprintf("router %q has no entryPoints", name)})
+		}
+		if _, ok := conf.HTTP.Services[r.Service]; !ok && !strings.Contains(r.Service, "@") {
+			out = append(out, ConfigError{Line: line, Msg: fmt.Sprintf("router %q references undefined service %q", name, r.Service)})
+		}
+	}
+	if conf.TCP != nil {
+		out = append(out, validateTCPConfig(&root, conf.TCP)...)
+	}
+	for _, name := range sortedKeys(conf.HTTP.Services) {
+		lb := conf.HTTP.Services[name].LoadBalancer
+		line := nodeLine(&root, "http", "services", name)
+		if len(lb.Servers) == 0 {
+			out = append(out, ConfigError{Line: line, Msg: fmt.Sprintf("service %q has no servers", name)})
+		}
+		for _, srv := range lb.Servers {
+			if u, err := url.Parse(srv.URL); err != nil || u.Scheme == "" || u.Host == "" {
+				out = append(out, ConfigError{Line: line, Msg: fmt.Sprintf("service %q has an invalid server url %q", name, srv.URL)})
+			}
+		}
+		if lb.ServersTransport != "" {
+			if _, ok := conf.HTTP.ServersTransports[lb.ServersTransport]; !ok && !strings.Contains(lb.ServersTransport, "@") {
+				out = append(out, ConfigError{Line: line, Msg: fmt.Sprintf("service %q references undefined serversTransport %q", name, lb.ServersTransport)})
+			}
+		}
+	}
+	return out
+}
+
+// validateTCPConfig applies ValidateDynConfig's router and service checks to
+// the tcp block; servers need a host:port address instead of a URL.
+func validateTCPConfig(root *yaml.Node, tcp *dynTCP) []ConfigError {
+	var out []ConfigError
+	for _, name := range sortedKeys(tcp.Routers) {
+		r := tcp.Routers[name]
+		line := nodeLine(root, "tcp", "routers", name)
+		if strings.TrimSpace(r.Rule) == "" {
+			out = append(out, ConfigError{Line: line, Msg: fmt.Sprintf("tcp router %q has no rule", name)})
+		} else if err := checkRuleSyntax(r.Rule); err != nil {
+			out = append(out, ConfigError{Line: line, Msg: fmt.Sprintf("tcp router %q: %v", name, err)})
+		}
+		if len(r.EntryPoints) == 0 {
+			out = append(out, ConfigError{Line: line, Msg: fmt.Sprintf("tcp router %q has no entryPoints", name)})
+		}
+		if _, ok := tcp.Services[r.Service]; !ok && !strings.Contains(r.Service, "@") {
+			out = append(out, ConfigError{Line: line, Msg: fmt.Sprintf("tcp router %q references undefined service %q", name, r.Service)})
+		}
+	}
+	for _, name := range sortedKeys(tcp.Services) {
+		lb := tcp.Services[name].LoadBalancer
+		line := nodeLine(root, "tcp", "services", name)
+		if len(lb.Servers) == 0 {
+			out = append(out, ConfigError{Line: line, Msg: fmt.Sprintf("tcp service %q has no servers", name)})
+		}
+		for _, srv := range lb.Servers {
+			if _, _, err := net.SplitHostPort(srv.Address); err != nil {
+				out = append(out, ConfigError{Line: line, Msg: fmt.Sprintf("tcp service %q has an invalid server address %q", name, srv.Address)})
+			}
+		}
+	}
+	return out
+}
+
+// checkRuleSyntax catches the rule typos Traefik would reject at load time:
+// unbalanced parentheses and unterminated backtick literals.
+func checkRuleSyntax(rule string) error {
+	depth := 0
+	inLiteral := false
+	for _, c := range rule {
+		switch {
+		case c == '`':
+			inLiteral = !inLiteral
+		case inLiteral:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth < 0 {
+				return fmt.Errorf("unbalanced parentheses in rule")
+			}
+		}
+	}
+	if inLiteral {
+		return fmt.Errorf("unterminated backtick literal in rule")
+	}
+	if depth != 0 {
+		return fmt.Errorf("unbalanced parentheses in rule")
+	}
+	return nil
+}
+
+// nodeLine returns the line of the mapping key at path below the document
+// root, or 0 when the path doesn't exist.
+func nodeLine(root *yaml.Node, path ...string) int {
+	n := root
+	if n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		n = n.Content[0]
+	}
+	line := 0
+	for _, key := range path {
+		if n.Kind != yaml.MappingNode {
+			return 0
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Content[i].Value == key {
+				line = n.Content[i].Line
+				next = n.Content[i+1]
+				break
+			}
+		}
+		if next == nil {
+			return 0
+		}
+		n = next
+	}
+	return line
+}
+
+// sortedKeys returns m's keys in order, so reports are deterministic.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -15,7 +15,7 @@ func TestEnsureConfigFreshInstall(t *testing.T) {
 	config.ResetCache()
 	t.Cleanup(config.ResetCache)
 
-	if err := EnsureConfig("alice@example.com"); err != nil {
+	if _, err := EnsureConfig("alice@example.com"); err != nil {
 		t.Fatalf("EnsureConfig err: %v", err)
 	}
 
@@ -43,18 +43,26 @@ func TestEnsureConfigIsIdempotent(t *testing.T) {
 	config.ResetCache()
 	t.Cleanup(config.ResetCache)
 
-	if err := EnsureConfig("a@b.com"); err != nil {
+	if changed, err := EnsureConfig("a@b.com"); err != nil || !changed {
+		t.Fatalf("first EnsureConfig: changed=%v err=%v", changed, err)
+	}
+	cfg, _ := config.Load()
+	composeInfo, err := os.Stat(cfg.TraefikComposePath())
+	if err != nil {
 		t.Fatal(err)
 	}
-	if err := EnsureConfig("a@b.com"); err != nil {
-		t.Fatalf("second EnsureConfig err: %v", err)
+	if changed, err := EnsureConfig("a@b.com"); err != nil || changed {
+		t.Fatalf("second EnsureConfig: changed=%v err=%v, want unchanged", changed, err)
+	}
+	if info, _ := os.Stat(cfg.TraefikComposePath()); !info.ModTime().Equal(composeInfo.ModTime()) {
+		t.Error("second EnsureConfig rewrote an unchanged docker-compose.yml")
 	}
 }
 
 func TestWriteOrMergeTraefikYMLFresh(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "traefik.yml")
-	if err := writeOrMergeTraefikYML(path, "tnet", "x@y.com"); err != nil {
+	if _, err := writeOrMergeTraefikYML(path, "tnet", "x@y.com", ""); err != nil {
 		t.Fatal(err)
 	}
 	data, _ := os.ReadFile(path)
@@ -82,7 +90,7 @@ entryPoints:
 	if err := os.WriteFile(path, []byte(existing), 0o644); err != nil {
 		t.Fatal(err)
 	}
-	if err := writeOrMergeTraefikYML(path, "tnet", "x@y.com"); err != nil {
+	if _, err := writeOrMergeTraefikYML(path, "tnet", "x@y.com", ""); err != nil {
 		t.Fatal(err)
 	}
 	data, _ := os.ReadFile(path)
@@ -104,7 +112,7 @@ func TestWriteOrMergeTraefikYMLMalformedFails(t *testing.T) {
 	if err := os.WriteFile(path, []byte(":\n:\n: bad yaml"), 0o644); err != nil {
 		t.Fatal(err)
 	}
-	if err := writeOrMergeTraefikYML(path, "tnet", "x@y.com"); err == nil {
+	if _, err := writeOrMergeTraefikYML(path, "tnet", "x@y.com", ""); err == nil {
 		t.Error("expected err on malformed existing file")
 	}
 }
@@ -83,7 +83,7 @@ func TestBuildHostRuleSkipsEmpty(t *testing.T) {
 
 func TestWriteRoutesConfigEmptyRemoves(t *testing.T) {
 	cfg := newTraefikCfg(t)
-	path := routesConfigPath(cfg, "site")
+	path := RoutesConfigPath(cfg, "site")
 	if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
 		t.Fatal(err)
 	}
@@ -108,7 +108,7 @@ func TestWriteRoutesConfigOne(t *testing.T) {
 	if err := WriteRoutesConfig(cfg, set); err != nil {
 		t.Fatal(err)
 	}
-	data, err := os.ReadFile(routesConfigPath(cfg, "site"))
+	data, err := os.ReadFile(RoutesConfigPath(cfg, "site"))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -137,7 +137,7 @@ func TestWriteRoutesConfigRewrite(t *testing.T) {
 	if err := WriteRoutesConfig(cfg, set); err != nil {
 		t.Fatal(err)
 	}
-	data, _ := os.ReadFile(routesConfigPath(cfg, "site"))
+	data, _ := os.ReadFile(RoutesConfigPath(cfg, "site"))
 	body := string(data)
 	if !strings.Contains(body, "replacePathRegex") {
 		t.Error("middleware missing")
@@ -161,7 +161,7 @@ func TestWriteRoutesConfigInsecureSkipVerify(t *testing.T) {
 	if err := WriteRoutesConfig(cfg, set); err != nil {
 		t.Fatal(err)
 	}
-	body, err := os.ReadFile(routesConfigPath(cfg, "site"))
+	body, err := os.ReadFile(RoutesConfigPath(cfg, "site"))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -222,7 +222,7 @@ func TestHasRoutesConfig(t *testing.T) {
 	if HasRoutesConfig(cfg, "x") {
 		t.Error("should be false initially")
 	}
-	path := routesConfigPath(cfg, "x")
+	path := RoutesConfigPath(cfg, "x")
 	if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
 		t.Fatal(err)
 	}
@@ -233,7 +233,7 @@ func TestHasRoutesConfig(t *testing.T) {
 
 func TestRemoveRoutesConfig(t *testing.T) {
 	cfg := newTraefikCfg(t)
-	path := routesConfigPath(cfg, "x")
+	path := RoutesConfigPath(cfg, "x")
 	if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
 		t.Fatal(err)
 	}
@@ -250,7 +250,7 @@ func TestRemoveRoutesConfig(t *testing.T) {
 
 func TestRoutesConfigPath(t *testing.T) {
 	cfg := newTraefikCfg(t)
-	got := routesConfigPath(cfg, "blog")
+	got := RoutesConfigPath(cfg, "blog")
 	if !strings.Contains(got, "blog") || !strings.HasSuffix(got, ".yml") {
 		t.Errorf("got %q", got)
 	}
@@ -278,7 +278,7 @@ func TestDockerComposeTemplateCreds(t *testing.T) {
 		sitesDir = "/sites:with\"quote"
 		network  = "net'name"
 	)
-	out, err := DockerComposeTemplate(network, sitesDir, user, pass)
+	out, err := DockerComposeTemplate(network, sitesDir, user, pass, nil, "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -0,0 +1,81 @@
+// Package traefik — health.go renders the load balancer health checks of
+// `srv add --healthcheck` and reads their outcome back from Traefik's API
+// for the HEALTH column of `srv list`.
+package traefik
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/stubbedev/srv/internal/constants"
+)
+
+// DefaultHealthCheckInterval is how often Traefik probes a site whose
+// health check doesn't set an interval.
+const DefaultHealthCheckInterval = "10s"
+
+// maxHealthCheckTimeout caps the probe timeout derived from the interval.
+const maxHealthCheckTimeout = 5 * time.Second
+
+// Health states reported by ServiceHealth.
+const (
+	HealthHealthy   = "healthy"
+	HealthUnhealthy = "unhealthy"
+	HealthUnknown   = "unknown"
+)
+
+// APIURL is Traefik's API, on the dashboard port. Tests swap it.
+var APIURL = fmt.Sprintf("%s%s:%d/api", constants.SchemeHTTPPrefix, constants.LocalhostIP, constants.PortDashboard)
+
+// apiTimeout bounds one API request; `srv list` makes one per site.
+const apiTimeout = 2 * time.Second
+
+// healthCheck builds a load balancer health check probing path every
+// interval. The probe times out after half the interval, at most 5s, so a
+// slow answer counts as a failure before the next probe is due.
+func healthCheck(path, interval string) *dynHealthCheck {
+	if interval == "" {
+		interval = DefaultHealthCheckInterval
+	}
+	timeout := maxHealthCheckTimeout
+	if d, err := time.ParseDuration(interval); err == nil && d/2 < timeout {
+		timeout = d / 2
+	}
+	return &dynHealthCheck{Path: path, Interval: interval, Timeout: timeout.String()}
+}
+
+// ServiceHealth asks Traefik how the health check of the site's service is
+// doing: healthy when every server is UP, unhealthy when any is DOWN, and
+// unknown when Traefik can't be reached or hasn't probed the service yet.
+func ServiceHealth(name string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), apiTimeout)
+	defer cancel()
+	url := fmt.Sprintf("%s/http/services/%s%s@file", APIURL, constants.SiteConfigPrefix, name)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return HealthUnknown
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return HealthUnknown
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return HealthUnknown
+	}
+	var svc struct {
+		ServerStatus map[string]string `json:"serverStatus"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&svc); err != nil || len(svc.ServerStatus) == 0 {
+		return HealthUnknown
+	}
+	for _, status := range svc.ServerStatus {
+		if status != "UP" {
+			return HealthUnhealthy
+		}
+	}
+	return HealthHealthy
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/stubbedev/srv/internal/config"
 	"github.com/stubbedev/srv/internal/constants"
 	"github.com/stubbedev/srv/internal/docker"
+	"github.com/stubbedev/srv/internal/fsutil"
 )
 
 // IsRunning checks if Traefik container is running.
@@ -126,7 +127,7 @@ func writeDashboardProxyConfig(cfg *config.Config, name, domain string) error {
 	)
 
 	proxyFile := filepath.Join(cfg.TraefikConfDir(), constants.ProxyConfigPrefix+name+constants.ExtYAML)
-	return os.WriteFile(proxyFile, []byte(content), constants.FilePermDefault)
+	return fsutil.AtomicWriteFile(proxyFile, []byte(content), constants.FilePermDefault)
 }
 
 // RestartTraefik restarts the Traefik container.
@@ -164,3 +165,20 @@ func Reset() error {
 	config.ResetCache()
 	return nil
 }
+
+// ResetTraefikDir removes Traefik's directory — static and dynamic config,
+// ACME stores, logs and the dnsmasq files — leaving the registered sites in
+// place. Stops the Traefik and DNS containers first (best-effort).
+func ResetTraefikDir() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if IsRunning() || IsDNSRunning() {
+		_ = docker.Compose(cfg.TraefikDir, "down")
+	}
+	if err := os.RemoveAll(cfg.TraefikDir); err != nil {
+		return fmt.Errorf("failed to remove Traefik directory: %w", err)
+	}
+	return nil
+}
@@ -33,7 +33,7 @@ func TestSetMetricsKey(t *testing.T) {
 // The base template must not ship the exporter — it is opt-in via
 // `srv metrics enable`.
 func TestTemplateHasNoMetricsBlock(t *testing.T) {
-	out, err := renderTraefikTemplate("srv-network", "x@y.com")
+	out, err := renderTraefikTemplate("srv-network", "x@y.com", "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -0,0 +1,85 @@
+// Package traefik — proxy_containers.go reads the upstream containers back
+// out of the proxy configs in the conf directory. The daemon tracks them
+// alongside site containers, so a container a proxy forwards to is
+// reconnected to the srv network whenever it starts.
+package traefik
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/constants"
+)
+
+// ContainerFromURL extracts the container name from a proxy target URL.
+// Returns "" when the target is the host machine (localhost, 127.0.0.1, ::1
+// or host.docker.internal) rather than a named container.
+func ContainerFromURL(targetURL string) string {
+	parsed, err := url.Parse(targetURL)
+	if err != nil {
+		return ""
+	}
+	host := parsed.Hostname()
+	switch host {
+	case constants.DockerHostInternal, constants.LocalhostIP, "localhost", "::1":
+		return ""
+	}
+	return host
+}
+
+// ProxyContainers maps each container an HTTP or TCP proxy forwards to onto
+// the proxy's name. The dashboard proxy targets Traefik itself and is left
+// out. A config that can't be read or parsed is skipped.
+func ProxyContainers(cfg *config.Config) (map[string]string, error) {
+	entries, err := os.ReadDir(cfg.TraefikConfDir())
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	containers := map[string]string{}
+	for _, entry := range entries {
+		file := entry.Name()
+		if !strings.HasPrefix(file, constants.ProxyConfigPrefix) || !strings.HasSuffix(file, constants.ExtYAML) {
+			continue
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(file, constants.ProxyConfigPrefix), constants.ExtYAML)
+		if name == constants.TraefikDashboardProxyName {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(cfg.TraefikConfDir(), file))
+		if err != nil {
+			continue
+		}
+		var conf DynConfig
+		if err := yaml.Unmarshal(data, &conf); err != nil {
+			continue
+		}
+		if conf.TCP != nil {
+			name = strings.TrimSuffix(strings.TrimPrefix(file, constants.TCPProxyConfigPrefix), constants.ExtYAML)
+		}
+		for _, svc := range conf.HTTP.Services {
+			for _, server := range svc.LoadBalancer.Servers {
+				if c := ContainerFromURL(server.URL); c != "" {
+					containers[c] = name
+				}
+			}
+		}
+		if conf.TCP != nil {
+			for _, svc := range conf.TCP.Services {
+				for _, server := range svc.LoadBalancer.Servers {
+					if c := ContainerFromURL("tcp://" + server.Address); c != "" {
+						containers[c] = name
+					}
+				}
+			}
+		}
+	}
+	return containers, nil
+}
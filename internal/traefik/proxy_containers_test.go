@@ -0,0 +1,50 @@
+package traefik
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestContainerFromURL(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{"http://redis:6379", "redis"},
+		{"http://localhost:8080", ""},
+		{"http://127.0.0.1:80", ""},
+		{"http://host.docker.internal:9000", ""},
+		{"http://[::1]:80", ""},
+		{"not-a-url", ""},
+		{"", ""},
+		{"https://my-app:3000/path", "my-app"},
+	}
+	for _, c := range cases {
+		if got := ContainerFromURL(c.in); got != c.want {
+			t.Errorf("ContainerFromURL(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestProxyContainers(t *testing.T) {
+	cfg := newTraefikCfg(t)
+	for _, p := range []ProxyRoute{
+		{Name: "api", Domain: "api.test", TargetURL: "http://api-web:8080", Backends: []string{"http://api-web-2:8080"}},
+		{Name: "host", Domain: "host.test", TargetURL: "http://localhost:3000"},
+	} {
+		if err := WriteProxyConfig(cfg, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := WriteTCPProxyConfig(cfg, TCPProxyRoute{Name: "redis", Domain: "redis.test", Address: "redis:6379", EntryPointPort: 6380}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ProxyContainers(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"api-web": "api", "api-web-2": "api", "redis": "redis"}
+	if !maps.Equal(got, want) {
+		t.Errorf("ProxyContainers = %v, want %v", got, want)
+	}
+}
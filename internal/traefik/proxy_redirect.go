@@ -8,6 +8,7 @@ package traefik
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/stubbedev/srv/internal/config"
 	"github.com/stubbedev/srv/internal/constants"
@@ -21,10 +22,107 @@ type ProxyRoute struct {
 	TargetURL string // upstream URL (http://host:port or http://container:port)
 	Container string // optional container name, recorded in the header comment
 	Wildcard  bool   // match apex + one-level subdomains
+	// Backends are extra upstream URLs load-balanced alongside TargetURL.
+	Backends []string
+	// LBMethod is the load-balancing method (LBMethodWRR or LBMethodLeastConn);
+	// empty leaves Traefik's default weighted round-robin.
+	LBMethod string
+	// PathPrefix limits the router to requests under this path (e.g. /api);
+	// empty routes the whole host.
+	PathPrefix string
+	// StripPrefix removes PathPrefix from the request path before forwarding.
+	StripPrefix bool
+	// Timeouts overrides Traefik's upstream timeouts; zero value keeps the defaults.
+	Timeouts ProxyTimeouts
+	// WebSocket injects upgrade headers and keeps idle upstream connections
+	// open for an hour.
+	WebSocket bool
+	// GRPC forwards to a gRPC upstream over HTTP/2: GRPCH2C for cleartext,
+	// GRPCTLS for TLS. Empty proxies plain HTTP.
+	GRPC string
+}
+
+// ProxyTimeouts are upstream timeouts for a proxy, as Go duration strings.
+// Empty fields keep Traefik's defaults.
+type ProxyTimeouts struct {
+	Response string // time to wait for the upstream's response headers
+	Read     string // idle time on an HTTP/2 upstream connection before a health ping
+	Dial     string // time to establish the upstream connection
+}
+
+// IsZero reports whether no timeout is overridden.
+func (t ProxyTimeouts) IsZero() bool {
+	return t.Response == "" && t.Read == "" && t.Dial == ""
+}
+
+// Proxy load-balancing methods accepted by ProxyRoute.LBMethod.
+// LBMethodDRR is recognised only to explain why it is rejected.
+const (
+	LBMethodWRR       = "wrr"
+	LBMethodLeastConn = "leastconn"
+	LBMethodDRR       = "drr"
+)
+
+// Proxy gRPC modes accepted by ProxyRoute.GRPC.
+const (
+	GRPCH2C = "h2c"
+	GRPCTLS = "tls"
+)
+
+// grpcDialTimeout bounds connecting to a gRPC upstream unless the proxy sets
+// its own dial timeout.
+const grpcDialTimeout = "30s"
+
+// grpcScheme returns the upstream URL scheme Traefik needs for a gRPC mode:
+// h2c:// speaks cleartext HTTP/2, https:// negotiates HTTP/2 over TLS.
+func grpcScheme(mode string) (string, error) {
+	switch mode {
+	case GRPCH2C:
+		return "h2c", nil
+	case GRPCTLS:
+		return "https", nil
+	}
+	return "", fmt.Errorf("unknown gRPC mode %q (expected %s or %s)", mode, GRPCH2C, GRPCTLS)
+}
+
+// withScheme replaces the scheme of an upstream URL.
+func withScheme(rawURL, scheme string) string {
+	if _, rest, ok := strings.Cut(rawURL, "://"); ok {
+		return scheme + "://" + rest
+	}
+	return scheme + "://" + rawURL
+}
+
+// lbStrategy maps an srv load-balancing method onto Traefik's loadBalancer
+// strategy. Traefik has no strict least-connections balancer; p2c (power of
+// two choices) picks the less-loaded of two random servers, which is its
+// closest equivalent. drr (dynamic round robin) was a Traefik v1 method that
+// v2 and v3 dropped, so it has no strategy to map onto.
+func lbStrategy(method string) (string, error) {
+	switch method {
+	case "":
+		return "", nil
+	case LBMethodWRR:
+		return "wrr", nil
+	case LBMethodLeastConn:
+		return "p2c", nil
+	case LBMethodDRR:
+		return "", fmt.Errorf("load-balancing method %s isn't supported: Traefik v2 and later dropped dynamic round robin (use %s, or %s to favour less-loaded backends)", LBMethodDRR, LBMethodWRR, LBMethodLeastConn)
+	}
+	return "", fmt.Errorf("unknown load-balancing method %q (expected %s or %s)", method, LBMethodWRR, LBMethodLeastConn)
+}
+
+// ValidateLBMethod checks a load-balancing method; "" means Traefik's
+// default (wrr).
+func ValidateLBMethod(method string) error {
+	_, err := lbStrategy(method)
+	return err
 }
 
 // WriteProxyConfig renders proxy-<name>.yml. The config terminates TLS with a
-// file-provider (mkcert) certificate and forwards to TargetURL.
+// file-provider (mkcert) certificate and forwards to TargetURL. With a
+// PathPrefix the router only matches that prefix, optionally stripping it
+// through a stripPrefix middleware.
 func WriteProxyConfig(cfg *config.Config, p ProxyRoute) error {
 	key := constants.ProxyConfigPrefix + p.Name
 	router := dynRouter{
@@ -33,12 +131,66 @@ func WriteProxyConfig(cfg *config.Config, p ProxyRoute) error {
 		Service:     key,
 		TLS:         localTLS(),
 	}
+	middlewares := map[string]dynMiddleware{}
+	if p.PathPrefix != "" {
+		router.Rule = WithPathPrefix(router.Rule, p.PathPrefix)
+		if p.StripPrefix {
+			mwKey := key + "-stripprefix"
+			middlewares[mwKey] = dynMiddleware{StripPrefix: &dynStripPrefix{Prefixes: []string{p.PathPrefix}}}
+			router.Middlewares = append(router.Middlewares, mwKey)
+		}
+	}
+	if p.WebSocket {
+		mwKey := key + "-websocket"
+		middlewares[mwKey] = websocketMiddleware()
+		router.Middlewares = append(router.Middlewares, mwKey)
+	}
+	strategy, err := lbStrategy(p.LBMethod)
+	if err != nil {
+		return err
+	}
+	servers := []dynServer{{URL: p.TargetURL}}
+	for _, b := range p.Backends {
+		servers = append(servers, dynServer{URL: b})
+	}
+	lb := dynLoadBalancer{Servers: servers, Strategy: strategy}
+	dial := p.Timeouts.Dial
+	if p.GRPC != "" {
+		// gRPC clients address services by authority, so the upstream must
+		// see the original Host.
+		scheme, err := grpcScheme(p.GRPC)
+		if err != nil {
+			return err
+		}
+		for i := range lb.Servers {
+			lb.Servers[i].URL = withScheme(lb.Servers[i].URL, scheme)
+		}
+		passHost := true
+		lb.PassHostHeader = &passHost
+		if dial == "" {
+			dial = grpcDialTimeout
+		}
+	}
+	var transports map[string]dynServersTransport
+	if !p.Timeouts.IsZero() || p.WebSocket || p.GRPC != "" {
+		timeouts := &dynForwardingTimeouts{
+			DialTimeout:           dial,
+			ResponseHeaderTimeout: p.Timeouts.Response,
+			ReadIdleTimeout:       p.Timeouts.Read,
+		}
+		if p.WebSocket {
+			timeouts.IdleConnTimeout = websocketIdleTimeout
+		}
+		transportKey := key + "-transport"
+		transports = map[string]dynServersTransport{transportKey: {ForwardingTimeouts: timeouts}}
+		lb.ServersTransport = transportKey
+	}
 	conf := DynConfig{
 		HTTP: dynHTTP{
-			Routers: map[string]dynRouter{key: router},
-			Services: map[string]dynService{
-				key: {LoadBalancer: dynLoadBalancer{Servers: []dynServer{{URL: p.TargetURL}}}},
-			},
+			Routers:           map[string]dynRouter{key: router},
+			Services:          map[string]dynService{key: {LoadBalancer: lb}},
+			Middlewares:       middlewares,
+			ServersTransports: transports,
 		},
 	}
 
@@ -47,12 +199,14 @@ func WriteProxyConfig(cfg *config.Config, p ProxyRoute) error {
 		return fmt.Errorf("failed to marshal proxy config: %w", err)
 	}
 
-	var content string
+	content := fmt.Sprintf("# Proxy configuration for %s - generated by srv\n# Domain: %s\n", p.Name, p.Domain)
 	if p.Container != "" {
-		content = fmt.Sprintf("# Proxy configuration for %s - generated by srv\n# Domain: %s\n# Container: %s\n%s", p.Name, p.Domain, p.Container, data)
-	} else {
-		content = fmt.Sprintf("# Proxy configuration for %s - generated by srv\n# Domain: %s\n%s", p.Name, p.Domain, data)
+		content += fmt.Sprintf("# Container: %s\n", p.Container)
+	}
+	if p.GRPC != "" {
+		content += fmt.Sprintf("# Service type: grpc (%s)\n", p.GRPC)
 	}
+	content += string(data)
 
 	// Atomic write: Traefik watches this file.
 	proxyFile := filepath.Join(cfg.TraefikConfDir(), constants.ProxyConfigPrefix+p.Name+constants.ExtYAML)
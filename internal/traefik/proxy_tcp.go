@@ -0,0 +1,183 @@
+// Package traefik — proxy_tcp.go renders TCP proxies for `srv proxy add
+// --tcp`: a tcp router matching HostSNI on a dedicated tcp-<port> entrypoint,
+// forwarding raw connections to a non-HTTP service (Redis, PostgreSQL, SMTP).
+// The entrypoint lives in the static traefik.yml, so adding or removing one
+// needs Traefik recreated.
+package traefik
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/stubbedev/srv/internal/config"
+	"github.com/stubbedev/srv/internal/constants"
+	"github.com/stubbedev/srv/internal/fsutil"
+)
+
+// TCPProxyRoute describes a TCP proxy to render as a Traefik file config.
+type TCPProxyRoute struct {
+	Name      string // short proxy name (file is proxy-tcp-<name>.yml)
+	Domain    string // SNI hostname clients connect with
+	Address   string // upstream host:port
+	Container string // optional container name, recorded in the header comment
+	// EntryPointPort is the port the proxy's tcp-<port> entrypoint listens on.
+	EntryPointPort int
+	// Passthrough forwards the TLS stream to the upstream instead of
+	// terminating it with the local certificate.
+	Passthrough bool
+}
+
+// ReservedTCPPorts are the host ports srv's own entrypoints and services
+// already listen on; a TCP entrypoint can't use them.
+var ReservedTCPPorts = []int{constants.PortHTTP, constants.PortHTTPS, constants.PortInternal, constants.PortDashboard, constants.PortDNS}
+
+// TCPEntryPoint returns the name of the entrypoint listening on port.
+func TCPEntryPoint(port int) string {
+	return constants.TCPEntryPointPrefix + strconv.Itoa(port)
+}
+
+// TCPProxyConfigPath returns the path of proxy-tcp-<name>.yml.
+func TCPProxyConfigPath(cfg *config.Config, name string) string {
+	return filepath.Join(cfg.TraefikConfDir(), constants.TCPProxyConfigPrefix+name+constants.ExtYAML)
+}
+
+// WriteTCPProxyConfig renders proxy-tcp-<name>.yml.
+func WriteTCPProxyConfig(cfg *config.Config, p TCPProxyRoute) error {
+	key := constants.TCPProxyConfigPrefix + p.Name
+	conf := DynConfig{
+		TCP: &dynTCP{
+			Routers: map[string]dynTCPRouter{key: {
+				Rule:        fmt.Sprintf("HostSNI(`%s`)", p.Domain),
+				EntryPoints: []string{TCPEntryPoint(p.EntryPointPort)},
+				Service:     key,
+				TLS:         &dynTCPTLS{Passthrough: p.Passthrough},
+			}},
+			Services: map[string]dynTCPService{key: {
+				LoadBalancer: dynTCPLoadBalancer{Servers: []dynTCPServer{{Address: p.Address}}},
+			}},
+		},
+	}
+	data, err := MarshalDynConfig(conf)
+	if err != nil {
+		return fmt.Errorf("failed to marshal TCP proxy config: %w", err)
+	}
+	content := fmt.Sprintf("# TCP proxy configuration for %s - generated by srv\n# Domain: %s\n", p.Name, p.Domain)
+	if p.Container != "" {
+		content += fmt.Sprintf("# Container: %s\n", p.Container)
+	}
+	content += string(data)
+	return fsutil.AtomicWriteFile(TCPProxyConfigPath(cfg, p.Name), []byte(content), constants.FilePermDefault)
+}
+
+// ReadTCPProxyConfig parses proxy-tcp-<name>.yml back into a TCPProxyRoute.
+// Container is left empty; callers derive it from Address.
+func ReadTCPProxyConfig(cfg *config.Config, name string) (*TCPProxyRoute, error) {
+	data, err := os.ReadFile(TCPProxyConfigPath(cfg, name))
+	if os.IsNotExist(err) {
+		// A disabled proxy keeps its config under *.disabled.
+		data, err = os.ReadFile(TCPProxyConfigPath(cfg, name) + constants.ExtDisabled)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var conf DynConfig
+	if err := yaml.Unmarshal(data, &conf); err != nil {
+		return nil, err
+	}
+	if conf.TCP == nil {
+		return nil, fmt.Errorf("%s has no tcp section", TCPProxyConfigPath(cfg, name))
+	}
+	p := &TCPProxyRoute{Name: name}
+	for _, r := range conf.TCP.Routers {
+		p.Domain = extractSNIDomain(r.Rule)
+		p.Passthrough = r.TLS != nil && r.TLS.Passthrough
+		for _, ep := range r.EntryPoints {
+			if port, err := strconv.Atoi(strings.TrimPrefix(ep, constants.TCPEntryPointPrefix)); err == nil {
+				p.EntryPointPort = port
+			}
+		}
+		if svc, ok := conf.TCP.Services[r.Service]; ok && len(svc.LoadBalancer.Servers) > 0 {
+			p.Address = svc.LoadBalancer.Servers[0].Address
+		}
+		break
+	}
+	return p, nil
+}
+
+// extractSNIDomain returns the hostname in a HostSNI(`…`) rule.
+func extractSNIDomain(rule string) string {
+	_, rest, ok := strings.Cut(rule, "HostSNI(`")
+	if !ok {
+		return ""
+	}
+	domain, _, _ := strings.Cut(rest, "`")
+	return domain
+}
+
+// SetTCPEntryPoint adds or removes the tcp-<port> entrypoint in the static
+// traefik.yml. Returns true when the file changed; the caller must then
+// regenerate the compose file (WriteCompose) and recreate Traefik.
+func SetTCPEntryPoint(port int, enabled bool) (changed bool, err error) {
+	return editTraefikYML(func(doc map[string]any) bool {
+		name := TCPEntryPoint(port)
+		eps, _ := doc["entryPoints"].(map[string]any)
+		_, has := eps[name]
+		if has == enabled {
+			return false
+		}
+		if enabled {
+			if eps == nil {
+				eps = map[string]any{}
+				doc["entryPoints"] = eps
+			}
+			eps[name] = map[string]any{"address": fmt.Sprintf(":%d", port)}
+		} else {
+			delete(eps, name)
+		}
+		return true
+	})
+}
+
+// ApplyTCPEntryPoints makes Traefik pick up changed TCP entrypoints: the
+// compose file is regenerated so the ports are published, and a running
+// Traefik is recreated to read the static config again.
+func ApplyTCPEntryPoints() error {
+	if err := WriteCompose(); err != nil {
+		return err
+	}
+	if !IsRunning() {
+		return nil
+	}
+	return RecreateTraefik()
+}
+
+// TCPEntryPointPorts returns the ports of the tcp-<port> entrypoints in the
+// static traefik.yml, sorted.
+func TCPEntryPointPorts(cfg *config.Config) []int {
+	data, err := os.ReadFile(filepath.Join(cfg.TraefikConfDir(), "traefik.yml"))
+	if err != nil {
+		return nil
+	}
+	var doc struct {
+		EntryPoints map[string]any `yaml:"entryPoints"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil
+	}
+	var ports []int
+	for name := range doc.EntryPoints {
+		if rest, ok := strings.CutPrefix(name, constants.TCPEntryPointPrefix); ok {
+			if port, err := strconv.Atoi(rest); err == nil {
+				ports = append(ports, port)
+			}
+		}
+	}
+	slices.Sort(ports)
+	return ports
+}
@@ -0,0 +1,96 @@
+package traefik
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stubbedev/srv/internal/config"
+)
+
+func TestWriteTCPProxyConfigRoundTrip(t *testing.T) {
+	cfg := newTraefikCfg(t)
+	want := TCPProxyRoute{
+		Name:           "redis",
+		Domain:         "redis.test",
+		Address:        "redis:6379",
+		Container:      "redis",
+		EntryPointPort: 6380,
+	}
+	if err := WriteTCPProxyConfig(cfg, want); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(TCPProxyConfigPath(cfg, "redis"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := ValidateDynConfig(data); len(errs) != 0 {
+		t.Fatalf("generated TCP config fails validation: %v", errs)
+	}
+	for _, s := range []string{"HostSNI(`redis.test`)", "tcp-6380", "address: redis:6379", "# Container: redis"} {
+		if !strings.Contains(string(data), s) {
+			t.Errorf("config missing %q:\n%s", s, data)
+		}
+	}
+	if strings.Contains(string(data), "passthrough: true") {
+		t.Errorf("terminating proxy should not pass TLS through:\n%s", data)
+	}
+
+	got, err := ReadTCPProxyConfig(cfg, "redis")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want.Container = ""
+	if *got != want {
+		t.Errorf("ReadTCPProxyConfig = %+v, want %+v", *got, want)
+	}
+
+	want.Passthrough = true
+	if err := WriteTCPProxyConfig(cfg, want); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ReadTCPProxyConfig(cfg, "redis"); err != nil || !got.Passthrough {
+		t.Errorf("passthrough not read back: %+v, %v", got, err)
+	}
+}
+
+func TestReadTCPProxyConfigMissing(t *testing.T) {
+	cfg := newTraefikCfg(t)
+	if _, err := ReadTCPProxyConfig(cfg, "nope"); err == nil {
+		t.Error("expected error for a missing config")
+	}
+}
+
+func TestSetTCPEntryPoint(t *testing.T) {
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_, _ = SetTCPEntryPoint(6380, false)
+		_, _ = SetTCPEntryPoint(5433, false)
+	})
+
+	for _, port := range []int{6380, 5433} {
+		if changed, err := SetTCPEntryPoint(port, true); err != nil || !changed {
+			t.Fatalf("add %d: changed=%v err=%v", port, changed, err)
+		}
+	}
+	if changed, _ := SetTCPEntryPoint(6380, true); changed {
+		t.Error("adding an existing entrypoint should be a no-op")
+	}
+	if got := TCPEntryPointPorts(cfg); !slices.Equal(got, []int{5433, 6380}) {
+		t.Errorf("TCPEntryPointPorts = %v", got)
+	}
+
+	if changed, err := SetTCPEntryPoint(6380, false); err != nil || !changed {
+		t.Fatalf("remove: changed=%v err=%v", changed, err)
+	}
+	if changed, _ := SetTCPEntryPoint(6380, false); changed {
+		t.Error("removing a missing entrypoint should be a no-op")
+	}
+	if got := TCPEntryPointPorts(cfg); !slices.Equal(got, []int{5433}) {
+		t.Errorf("TCPEntryPointPorts after remove = %v", got)
+	}
+}
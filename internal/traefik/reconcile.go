@@ -87,7 +87,7 @@ func ReconcileVersion(version string) (reconciled bool, err error) {
 	// idempotent and skips unchanged files. The persisted ACME email is reused
 	// ("" simply leaves production SSL disabled, which is the existing state).
 	email, _ := GetEmail("")
-	if err := EnsureConfig(email); err != nil {
+	if _, err := EnsureConfig(email); err != nil {
 		return false, fmt.Errorf("reconcile edge config: %w", err)
 	}
 
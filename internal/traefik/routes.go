@@ -39,13 +39,14 @@ type SiteRouteSet struct {
 	Domains  []string
 	Wildcard bool
 	IsLocal  bool
+	Staging  bool
 	Routes   []RouteSpec
 }
 
 // WriteRoutesConfig renders the per-site routes-<name>.yml file. If the set
 // has zero routes, the file is removed instead.
 func WriteRoutesConfig(cfg *config.Config, set SiteRouteSet) error {
-	path := routesConfigPath(cfg, set.SiteName)
+	path := RoutesConfigPath(cfg, set.SiteName)
 	if len(set.Routes) == 0 {
 		return RemoveRoutesConfig(cfg, set.SiteName)
 	}
@@ -92,7 +93,7 @@ func WriteRoutesConfig(cfg *config.Config, set SiteRouteSet) error {
 		if set.IsLocal {
 			router.TLS = localTLS()
 		} else {
-			router.TLS = resolverTLS(constants.CertResolverLetsEncrypt)
+			router.TLS = resolverTLS(ACMEResolver(set.Staging))
 		}
 
 		if r.Rewrite != "" {
@@ -146,7 +147,7 @@ func WriteRoutesConfig(cfg *config.Config, set SiteRouteSet) error {
 
 // RemoveRoutesConfig deletes the per-site extra-routes file if it exists.
 func RemoveRoutesConfig(cfg *config.Config, name string) error {
-	path := routesConfigPath(cfg, name)
+	path := RoutesConfigPath(cfg, name)
 	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("remove routes config: %w", err)
 	}
@@ -155,11 +156,12 @@ func RemoveRoutesConfig(cfg *config.Config, name string) error {
 
 // HasRoutesConfig reports whether a routes-<name>.yml file is present.
 func HasRoutesConfig(cfg *config.Config, name string) bool {
-	_, err := os.Stat(routesConfigPath(cfg, name))
+	_, err := os.Stat(RoutesConfigPath(cfg, name))
 	return err == nil
 }
 
-func routesConfigPath(cfg *config.Config, name string) string {
+// RoutesConfigPath returns the path of a site's routes-{name}.yml file.
+func RoutesConfigPath(cfg *config.Config, name string) string {
 	return filepath.Join(cfg.TraefikConfDir(), constants.RoutesConfigPrefix+name+constants.ExtYAML)
 }
 
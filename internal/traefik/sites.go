@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -33,6 +34,15 @@ func BuildHostRule(domains []string, wildcard bool) string {
 	return strings.Join(parts, " || ")
 }
 
+// WithPathPrefix narrows a router rule to requests under prefix. An empty
+// prefix returns rule unchanged.
+func WithPathPrefix(rule, prefix string) string {
+	if prefix == "" {
+		return rule
+	}
+	return fmt.Sprintf("(%s) && PathPrefix(`%s`)", rule, prefix)
+}
+
 // SiteRouteConfig holds the configuration for a site's Traefik routing.
 type SiteRouteConfig struct {
 	Name        string   // Site name (used for router/service names)
@@ -40,8 +50,36 @@ type SiteRouteConfig struct {
 	ServiceName string   // Container name to route to
 	Port        int      // Port the service listens on
 	IsLocal     bool     // Whether to use local SSL (mkcert) or Let's Encrypt
+	Staging     bool     // Use the Let's Encrypt staging CA (non-local only)
 	Wildcard    bool     // Match apex + one-level subdomains (apex only when false)
 	Listeners   []string // Extra entrypoints to attach to this site, e.g. ["internal"]
+	Middlewares []string // Custom middlewares appended to the site's HTTPS router
+	WebSocket   bool     // Inject WebSocket upgrade headers and keep idle upstream connections open
+	AllowIPs    []string // Client CIDRs allowed to reach the site; empty allows everyone
+	RateLimit   string   // Per-client rate limit as AVERAGE-UNIT (see ParseRateLimit); empty means none
+	Compress    bool     // Compress responses at Traefik
+	// MaxRequestBody and MaxResponseBody cap body sizes (see ParseBodySize);
+	// empty means no limit.
+	MaxRequestBody  string
+	MaxResponseBody string
+	// AddHeaders and RemoveHeaders edit the site's responses.
+	AddHeaders    map[string]string
+	RemoveHeaders []string
+	// EntryPoints the site is served on: websecure (HTTPS) and/or web (plain
+	// HTTP). Empty means websecure only.
+	EntryPoints []string
+	// HealthCheckPath, when set, makes Traefik probe the container at this
+	// path every HealthCheckInterval (default 10s).
+	HealthCheckPath     string
+	HealthCheckInterval string
+	// PathPrefix limits the site's routers to requests under this path (e.g.
+	// /api); StripPrefix removes it before forwarding.
+	PathPrefix  string
+	StripPrefix bool
+	// RetryAttempts, when set, retries requests that fail to reach the
+	// container, backing off from RetryInterval (Traefik's default when empty).
+	RetryAttempts int
+	RetryInterval string
 }
 
 // WriteSiteRouteConfig creates a Traefik file provider config for a site.
@@ -56,22 +94,117 @@ func WriteSiteRouteConfig(cfg *config.Config, route SiteRouteConfig) error {
 	// We use the container name directly since Traefik resolves via Docker network
 	serviceURL := fmt.Sprintf("http://%s:%d", route.ServiceName, route.Port)
 
+	rule := WithPathPrefix(BuildHostRule(route.Domains, route.Wildcard), route.PathPrefix)
 	router := dynRouter{
-		Rule:        BuildHostRule(route.Domains, route.Wildcard),
+		Rule:        rule,
 		EntryPoints: []string{constants.EntryPointWebsecure},
 		Service:     serviceName,
+		Middlewares: route.Middlewares,
 	}
-
-	if route.IsLocal {
-		// Local SSL uses file provider certificates (no certResolver)
-		router.TLS = localTLS()
-	} else {
-		// Production uses Let's Encrypt
-		router.TLS = resolverTLS(constants.CertResolverLetsEncrypt)
+	lb := dynLoadBalancer{Servers: []dynServer{{URL: serviceURL}}}
+	if route.HealthCheckPath != "" {
+		lb.HealthCheck = healthCheck(route.HealthCheckPath, route.HealthCheckInterval)
+	}
+	middlewares := map[string]dynMiddleware{}
+	var transports map[string]dynServersTransport
+	// srv's own middlewares guard the internal router too. The allowlist
+	// runs first so rejected clients never reach anything else.
+	var internalMiddlewares []string
+	if len(route.AllowIPs) > 0 {
+		mwKey := routerName + "-allowip"
+		middlewares[mwKey] = dynMiddleware{IPAllowList: &dynIPAllowList{SourceRange: route.AllowIPs}}
+		internalMiddlewares = append(internalMiddlewares, mwKey)
+	}
+	if route.RateLimit != "" {
+		average, period, err := ParseRateLimit(route.RateLimit)
+		if err != nil {
+			return err
+		}
+		mwKey := routerName + "-ratelimit"
+		middlewares[mwKey] = dynMiddleware{RateLimit: &dynRateLimit{Average: average, Period: period}}
+		internalMiddlewares = append(internalMiddlewares, mwKey)
+	}
+	if route.MaxRequestBody != "" || route.MaxResponseBody != "" {
+		buffering := &dynBuffering{}
+		for _, limit := range []struct {
+			spec string
+			dst  *int64
+		}{{route.MaxRequestBody, &buffering.MaxRequestBodyBytes}, {route.MaxResponseBody, &buffering.MaxResponseBodyBytes}} {
+			if limit.spec == "" {
+				continue
+			}
+			size, err := ParseBodySize(limit.spec)
+			if err != nil {
+				return err
+			}
+			*limit.dst = size
+		}
+		mwKey := routerName + "-buffering"
+		middlewares[mwKey] = dynMiddleware{Buffering: buffering}
+		internalMiddlewares = append(internalMiddlewares, mwKey)
+	}
+	if edits := ResponseHeaderEdits(route.AddHeaders, route.RemoveHeaders); edits != nil {
+		mwKey := routerName + "-headers"
+		middlewares[mwKey] = dynMiddleware{Headers: &dynHeaders{CustomResponseHeaders: edits}}
+		internalMiddlewares = append(internalMiddlewares, mwKey)
+	}
+	if route.Compress {
+		mwKey := routerName + "-compress"
+		middlewares[mwKey] = dynMiddleware{Compress: &dynCompress{}}
+		internalMiddlewares = append(internalMiddlewares, mwKey)
+	}
+	if route.PathPrefix != "" && route.StripPrefix {
+		mwKey := routerName + "-stripprefix"
+		middlewares[mwKey] = dynMiddleware{StripPrefix: &dynStripPrefix{Prefixes: []string{route.PathPrefix}}}
+		internalMiddlewares = append(internalMiddlewares, mwKey)
+	}
+	if route.RetryAttempts > 0 {
+		mwKey := routerName + "-retry"
+		middlewares[mwKey] = dynMiddleware{Retry: &dynRetry{Attempts: route.RetryAttempts, InitialInterval: route.RetryInterval}}
+		internalMiddlewares = append(internalMiddlewares, mwKey)
+	}
+	if len(internalMiddlewares) > 0 {
+		router.Middlewares = append(append([]string(nil), internalMiddlewares...), route.Middlewares...)
+	}
+	if route.WebSocket {
+		mwKey := routerName + "-websocket"
+		transportKey := serviceName + "-transport"
+		middlewares[mwKey] = websocketMiddleware()
+		transports = map[string]dynServersTransport{
+			transportKey: {ForwardingTimeouts: &dynForwardingTimeouts{IdleConnTimeout: websocketIdleTimeout}},
+		}
+		router.Middlewares = append(append([]string(nil), router.Middlewares...), mwKey)
+		internalMiddlewares = append(internalMiddlewares, mwKey)
+		lb.ServersTransport = transportKey
 	}
 
-	routers := map[string]dynRouter{
-		routerName: router,
+	entryPoints := route.EntryPoints
+	if len(entryPoints) == 0 {
+		entryPoints = []string{constants.EntryPointWebsecure}
+	}
+	routers := map[string]dynRouter{}
+	if slices.Contains(entryPoints, constants.EntryPointWebsecure) {
+		secure := router
+		if route.IsLocal {
+			// Local SSL uses file provider certificates (no certResolver)
+			secure.TLS = localTLS()
+		} else {
+			// Production uses Let's Encrypt
+			secure.TLS = resolverTLS(ACMEResolver(route.Staging))
+		}
+		routers[routerName] = secure
+	}
+	// A router on `web` takes the request before the entrypoint's redirect
+	// to HTTPS, so the site answers plain HTTP. It keeps the site's router
+	// name when it's the only one.
+	if slices.Contains(entryPoints, constants.EntryPointWeb) {
+		plain := router
+		plain.EntryPoints = []string{constants.EntryPointWeb}
+		key := routerName
+		if _, ok := routers[routerName]; ok {
+			key = routerName + "-http"
+		}
+		routers[key] = plain
 	}
 
 	// Optional plain-HTTP router on the `internal` entrypoint, sharing the
@@ -79,23 +212,20 @@ func WriteSiteRouteConfig(cfg *config.Config, route SiteRouteConfig) error {
 	for _, l := range route.Listeners {
 		if l == constants.ListenerInternal {
 			routers[routerName+"-internal"] = dynRouter{
-				Rule:        BuildHostRule(route.Domains, route.Wildcard),
+				Rule:        rule,
 				EntryPoints: []string{constants.EntryPointInternal},
 				Service:     serviceName,
+				Middlewares: internalMiddlewares,
 			}
 		}
 	}
 
 	siteConfig := DynConfig{
 		HTTP: dynHTTP{
-			Routers: routers,
-			Services: map[string]dynService{
-				serviceName: {
-					LoadBalancer: dynLoadBalancer{
-						Servers: []dynServer{{URL: serviceURL}},
-					},
-				},
-			},
+			Routers:           routers,
+			Services:          map[string]dynService{serviceName: {LoadBalancer: lb}},
+			Middlewares:       middlewares,
+			ServersTransports: transports,
 		},
 	}
 
@@ -118,13 +248,18 @@ func WriteSiteRouteConfig(cfg *config.Config, route SiteRouteConfig) error {
 	content := header + string(data)
 
 	// Atomic write: Traefik watches this file and must never read it truncated.
-	siteFile := filepath.Join(cfg.TraefikConfDir(), constants.SiteConfigPrefix+route.Name+constants.ExtYAML)
-	return fsutil.AtomicWriteFile(siteFile, []byte(content), constants.FilePermDefault)
+	return fsutil.AtomicWriteFile(SiteRouteConfigPath(cfg, route.Name), []byte(content), constants.FilePermDefault)
+}
+
+// SiteRouteConfigPath returns the path of a compose site's Traefik file
+// provider config (~/.config/srv/traefik/conf/site-{name}.yml).
+func SiteRouteConfigPath(cfg *config.Config, name string) string {
+	return filepath.Join(cfg.TraefikConfDir(), constants.SiteConfigPrefix+name+constants.ExtYAML)
 }
 
 // RemoveSiteRouteConfig removes the Traefik file provider config for a site.
 func RemoveSiteRouteConfig(cfg *config.Config, name string) error {
-	siteFile := filepath.Join(cfg.TraefikConfDir(), constants.SiteConfigPrefix+name+constants.ExtYAML)
+	siteFile := SiteRouteConfigPath(cfg, name)
 	if err := os.Remove(siteFile); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to remove site config: %w", err)
 	}
@@ -186,8 +321,7 @@ func ExtractDomainsFromRule(rule string) []string {
 
 // ReadSiteRouteDomain reads the domain from a site route config file.
 func ReadSiteRouteDomain(cfg *config.Config, name string) string {
-	siteFile := filepath.Join(cfg.TraefikConfDir(), constants.SiteConfigPrefix+name+constants.ExtYAML)
-	data, err := os.ReadFile(siteFile)
+	data, err := os.ReadFile(SiteRouteConfigPath(cfg, name))
 	if err != nil {
 		return ""
 	}
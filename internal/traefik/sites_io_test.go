@@ -1,10 +1,16 @@
 package traefik
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/stubbedev/srv/internal/constants"
 )
 
 func TestWriteSiteRouteConfigLocal(t *testing.T) {
@@ -54,6 +60,24 @@ func TestWriteSiteRouteConfigProduction(t *testing.T) {
 	}
 }
 
+func TestWriteSiteRouteConfigStaging(t *testing.T) {
+	cfg := newTraefikCfg(t)
+	route := SiteRouteConfig{
+		Name:        "blog",
+		Domains:     []string{"blog.com"},
+		ServiceName: "srv-blog-web",
+		Port:        80,
+		Staging:     true,
+	}
+	if err := WriteSiteRouteConfig(cfg, route); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(cfg.TraefikConfDir(), "site-blog.yml"))
+	if !strings.Contains(string(data), "certResolver: letsencrypt-staging") {
+		t.Errorf("staging resolver missing:\n%s", data)
+	}
+}
+
 func TestWriteSiteRouteConfigInternalListener(t *testing.T) {
 	cfg := newTraefikCfg(t)
 	route := SiteRouteConfig{
@@ -74,6 +98,267 @@ func TestWriteSiteRouteConfigInternalListener(t *testing.T) {
 	}
 }
 
+func TestWriteSiteRouteConfigMiddlewares(t *testing.T) {
+	cfg := newTraefikCfg(t)
+	route := SiteRouteConfig{
+		Name:        "blog",
+		Domains:     []string{"blog.local"},
+		ServiceName: "srv-blog-web",
+		Port:        80,
+		IsLocal:     true,
+		Middlewares: []string{"compress", "retry"},
+	}
+	if err := WriteSiteRouteConfig(cfg, route); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(cfg.TraefikConfDir(), "site-blog.yml"))
+	var parsed struct {
+		HTTP struct {
+			Routers map[string]struct {
+				Middlewares []string `yaml:"middlewares"`
+			} `yaml:"routers"`
+		} `yaml:"http"`
+	}
+	if err := yaml.Unmarshal(data, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	got := parsed.HTTP.Routers["site-blog"].Middlewares
+	if strings.Join(got, ",") != "compress,retry" {
+		t.Errorf("router middlewares = %v, want [compress retry]", got)
+	}
+}
+
+func TestWriteSiteRouteConfigAllowIPs(t *testing.T) {
+	cfg := newTraefikCfg(t)
+	route := SiteRouteConfig{
+		Name:        "blog",
+		Domains:     []string{"blog.local"},
+		ServiceName: "srv-blog-web",
+		Port:        80,
+		IsLocal:     true,
+		Listeners:   []string{"internal"},
+		Middlewares: []string{"compress"},
+		AllowIPs:    []string{"192.168.1.0/24", "10.0.0.0/8"},
+	}
+	if err := WriteSiteRouteConfig(cfg, route); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(cfg.TraefikConfDir(), "site-blog.yml"))
+	var parsed struct {
+		HTTP struct {
+			Routers map[string]struct {
+				Middlewares []string `yaml:"middlewares"`
+			} `yaml:"routers"`
+			Middlewares map[string]struct {
+				IPAllowList struct {
+					SourceRange []string `yaml:"sourceRange"`
+				} `yaml:"ipAllowList"`
+			} `yaml:"middlewares"`
+		} `yaml:"http"`
+	}
+	if err := yaml.Unmarshal(data, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	if got := parsed.HTTP.Routers["site-blog"].Middlewares; strings.Join(got, ",") != "site-blog-allowip,compress" {
+		t.Errorf("router middlewares = %v, want allowlist first", got)
+	}
+	if got := parsed.HTTP.Routers["site-blog-internal"].Middlewares; strings.Join(got, ",") != "site-blog-allowip" {
+		t.Errorf("internal router middlewares = %v", got)
+	}
+	if got := parsed.HTTP.Middlewares["site-blog-allowip"].IPAllowList.SourceRange; strings.Join(got, ",") != "192.168.1.0/24,10.0.0.0/8" {
+		t.Errorf("sourceRange = %v", got)
+	}
+}
+
+func TestWriteSiteRouteConfigHeaders(t *testing.T) {
+	cfg := newTraefikCfg(t)
+	route := SiteRouteConfig{
+		Name:          "blog",
+		Domains:       []string{"blog.local"},
+		ServiceName:   "srv-blog-web",
+		Port:          80,
+		IsLocal:       true,
+		Middlewares:   []string{"compress"},
+		AllowIPs:      []string{"10.0.0.0/8"},
+		AddHeaders:    map[string]string{"X-Robots-Tag": "noindex"},
+		RemoveHeaders: []string{"X-Powered-By"},
+	}
+	if err := WriteSiteRouteConfig(cfg, route); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(cfg.TraefikConfDir(), "site-blog.yml"))
+	var parsed struct {
+		HTTP struct {
+			Routers map[string]struct {
+				Middlewares []string `yaml:"middlewares"`
+			} `yaml:"routers"`
+			Middlewares map[string]struct {
+				Headers struct {
+					CustomResponseHeaders map[string]string `yaml:"customResponseHeaders"`
+				} `yaml:"headers"`
+			} `yaml:"middlewares"`
+		} `yaml:"http"`
+	}
+	if err := yaml.Unmarshal(data, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	if got := parsed.HTTP.Routers["site-blog"].Middlewares; strings.Join(got, ",") != "site-blog-allowip,site-blog-headers,compress" {
+		t.Errorf("router middlewares = %v", got)
+	}
+	headers := parsed.HTTP.Middlewares["site-blog-headers"].Headers.CustomResponseHeaders
+	if headers["X-Robots-Tag"] != "noindex" {
+		t.Errorf("X-Robots-Tag = %q", headers["X-Robots-Tag"])
+	}
+	if v, ok := headers["X-Powered-By"]; !ok || v != "" {
+		t.Errorf("X-Powered-By = %q (present %v), want empty to strip it", v, ok)
+	}
+}
+
+func TestWriteSiteRouteConfigBodyLimits(t *testing.T) {
+	cfg := newTraefikCfg(t)
+	route := SiteRouteConfig{Name: "api", Domains: []string{"api.test"}, ServiceName: "web", Port: 80, IsLocal: true,
+		RateLimit: "10-S", MaxRequestBody: "10MB", MaxResponseBody: "1GB"}
+	if err := WriteSiteRouteConfig(cfg, route); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(SiteRouteConfigPath(cfg, "api"))
+	var parsed DynConfig
+	if err := yaml.Unmarshal(data, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	if got := parsed.HTTP.Routers["site-api"].Middlewares; strings.Join(got, ",") != "site-api-ratelimit,site-api-buffering" {
+		t.Errorf("router middlewares = %v", got)
+	}
+	b := parsed.HTTP.Middlewares["site-api-buffering"].Buffering
+	if b == nil || b.MaxRequestBodyBytes != 10<<20 || b.MaxResponseBodyBytes != 1<<30 {
+		t.Errorf("buffering = %+v", b)
+	}
+
+	route.MaxResponseBody = "20GB"
+	if err := WriteSiteRouteConfig(cfg, route); err == nil {
+		t.Error("expected an error for a body size over 10GB")
+	}
+}
+
+func TestWriteSiteRouteConfigWebSocket(t *testing.T) {
+	cfg := newTraefikCfg(t)
+	route := SiteRouteConfig{
+		Name:        "chat",
+		Domains:     []string{"chat.local"},
+		ServiceName: "srv-chat-web",
+		Port:        3000,
+		IsLocal:     true,
+		Middlewares: []string{"compress"},
+		WebSocket:   true,
+	}
+	if err := WriteSiteRouteConfig(cfg, route); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(cfg.TraefikConfDir(), "site-chat.yml"))
+	var parsed struct {
+		HTTP struct {
+			Routers map[string]struct {
+				Middlewares []string `yaml:"middlewares"`
+			} `yaml:"routers"`
+			Services map[string]struct {
+				LoadBalancer struct {
+					ServersTransport string `yaml:"serversTransport"`
+				} `yaml:"loadBalancer"`
+			} `yaml:"services"`
+			Middlewares map[string]struct {
+				Headers struct {
+					CustomRequestHeaders map[string]string `yaml:"customRequestHeaders"`
+				} `yaml:"headers"`
+			} `yaml:"middlewares"`
+			ServersTransports map[string]struct {
+				ForwardingTimeouts struct {
+					IdleConnTimeout string `yaml:"idleConnTimeout"`
+				} `yaml:"forwardingTimeouts"`
+			} `yaml:"serversTransports"`
+		} `yaml:"http"`
+	}
+	if err := yaml.Unmarshal(data, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	if got := parsed.HTTP.Routers["site-chat"].Middlewares; strings.Join(got, ",") != "compress,site-chat-websocket" {
+		t.Errorf("router middlewares = %v", got)
+	}
+	headers := parsed.HTTP.Middlewares["site-chat-websocket"].Headers.CustomRequestHeaders
+	if headers["Connection"] != "Upgrade" || headers["Upgrade"] != "websocket" {
+		t.Errorf("upgrade headers = %v", headers)
+	}
+	transport := parsed.HTTP.Services["site-chat"].LoadBalancer.ServersTransport
+	if got := parsed.HTTP.ServersTransports[transport].ForwardingTimeouts.IdleConnTimeout; got != "3600s" {
+		t.Errorf("idleConnTimeout = %q (transport %q)", got, transport)
+	}
+	if len(route.Middlewares) != 1 {
+		t.Errorf("caller's middlewares mutated: %v", route.Middlewares)
+	}
+}
+
+func TestWriteSiteRouteConfigHealthCheck(t *testing.T) {
+	cfg := newTraefikCfg(t)
+	cases := []struct {
+		interval, wantInterval, wantTimeout string
+	}{
+		{"", "10s", "5s"},
+		{"4s", "4s", "2s"},
+		{"1m", "1m", "5s"},
+	}
+	for _, tc := range cases {
+		route := SiteRouteConfig{Name: "api", Domains: []string{"api.test"}, ServiceName: "web", Port: 80, IsLocal: true,
+			HealthCheckPath: "/health", HealthCheckInterval: tc.interval}
+		if err := WriteSiteRouteConfig(cfg, route); err != nil {
+			t.Fatal(err)
+		}
+		data, _ := os.ReadFile(SiteRouteConfigPath(cfg, "api"))
+		var parsed DynConfig
+		if err := yaml.Unmarshal(data, &parsed); err != nil {
+			t.Fatal(err)
+		}
+		hc := parsed.HTTP.Services["site-api"].LoadBalancer.HealthCheck
+		if hc == nil || hc.Path != "/health" || hc.Interval != tc.wantInterval || hc.Timeout != tc.wantTimeout {
+			t.Errorf("interval %q: healthCheck = %+v", tc.interval, hc)
+		}
+	}
+
+	if err := WriteSiteRouteConfig(cfg, SiteRouteConfig{Name: "api", Domains: []string{"api.test"}, ServiceName: "web", Port: 80, IsLocal: true}); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(SiteRouteConfigPath(cfg, "api"))
+	if strings.Contains(string(data), "healthCheck") {
+		t.Errorf("healthCheck written without a path:\n%s", data)
+	}
+}
+
+func TestServiceHealth(t *testing.T) {
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/http/services/site-api@file" || body == "" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+	orig := APIURL
+	APIURL = srv.URL + "/api"
+	defer func() { APIURL = orig }()
+
+	cases := []struct{ body, want string }{
+		{`{"serverStatus":{"http://web:80":"UP"}}`, HealthHealthy},
+		{`{"serverStatus":{"http://web:80":"DOWN"}}`, HealthUnhealthy},
+		{`{"status":"enabled"}`, HealthUnknown},
+		{"", HealthUnknown},
+	}
+	for _, tc := range cases {
+		body = tc.body
+		if got := ServiceHealth("api"); got != tc.want {
+			t.Errorf("body %q: ServiceHealth = %q, want %q", tc.body, got, tc.want)
+		}
+	}
+}
+
 func TestRemoveSiteRouteConfigMissing(t *testing.T) {
 	cfg := newTraefikCfg(t)
 	if err := RemoveSiteRouteConfig(cfg, "ghost"); err != nil {
@@ -126,3 +411,199 @@ func TestReadSiteRouteDomainBadYAML(t *testing.T) {
 		t.Errorf("bad YAML -> %q, want empty", got)
 	}
 }
+
+// TestValidateDynConfig: generated configs pass, and schema, rule and
+// reference mistakes are reported against the right line.
+func TestValidateDynConfig(t *testing.T) {
+	cfg := newTraefikCfg(t)
+	if err := WriteSiteRouteConfig(cfg, SiteRouteConfig{Name: "app", Domains: []string{"app.test"}, ServiceName: "web", Port: 80, IsLocal: true, WebSocket: true}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(SiteRouteConfigPath(cfg, "app"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := ValidateDynConfig(data); len(errs) != 0 {
+		t.Fatalf("generated config reported %v", errs)
+	}
+	if errs := ValidateDynConfig(nil); len(errs) != 0 {
+		t.Errorf("empty config reported %v", errs)
+	}
+
+	cases := []struct {
+		name, yaml string
+		line       int
+		want       string
+	}{
+		{"unknown field", "http:\n  routers:\n    r:\n      rulez: Host(`a.test`)\n", 4, "field rulez not found"},
+		{"syntax", "http:\n  routers: [\n", 2, "did not find expected"},
+		{"bad rule", "http:\n  routers:\n    r:\n      rule: Host(`a.test`\n      entryPoints: [web]\n      service: s@docker\n", 3, "unbalanced parentheses"},
+		{"undefined service", "http:\n  routers:\n    r:\n      rule: Host(`a.test`)\n      entryPoints: [web]\n      service: ghost\n", 3, `undefined service "ghost"`},
+		{"bad server url", "http:\n  services:\n    s:\n      loadBalancer:\n        servers:\n          - url: not-a-url\n", 3, "invalid server url"},
+		{"undefined transport", "http:\n  services:\n    s:\n      loadBalancer:\n        servers:\n          - url: http://web:80\n        serversTransport: gone\n", 3, `undefined serversTransport "gone"`},
+	}
+	for _, tc := range cases {
+		errs := ValidateDynConfig([]byte(tc.yaml))
+		if len(errs) != 1 || errs[0].Line != tc.line || !strings.Contains(errs[0].Msg, tc.want) {
+			t.Errorf("%s: got %v, want line %d containing %q", tc.name, errs, tc.line, tc.want)
+		}
+	}
+}
+
+func TestWriteSiteRouteConfigEntryPoints(t *testing.T) {
+	type router struct {
+		EntryPoints []string       `yaml:"entryPoints"`
+		Middlewares []string       `yaml:"middlewares"`
+		TLS         map[string]any `yaml:"tls"`
+	}
+	read := func(t *testing.T, entryPoints []string) map[string]router {
+		t.Helper()
+		cfg := newTraefikCfg(t)
+		route := SiteRouteConfig{
+			Name:        "blog",
+			Domains:     []string{"blog.local"},
+			ServiceName: "srv-blog-web",
+			Port:        80,
+			IsLocal:     true,
+			Middlewares: []string{"compress"},
+			EntryPoints: entryPoints,
+		}
+		if err := WriteSiteRouteConfig(cfg, route); err != nil {
+			t.Fatal(err)
+		}
+		data, _ := os.ReadFile(filepath.Join(cfg.TraefikConfDir(), "site-blog.yml"))
+		var parsed struct {
+			HTTP struct {
+				Routers map[string]router `yaml:"routers"`
+			} `yaml:"http"`
+		}
+		if err := yaml.Unmarshal(data, &parsed); err != nil {
+			t.Fatal(err)
+		}
+		return parsed.HTTP.Routers
+	}
+
+	routers := read(t, []string{"web"})
+	if len(routers) != 1 {
+		t.Fatalf("routers = %v, want only site-blog", routers)
+	}
+	if r := routers["site-blog"]; strings.Join(r.EntryPoints, ",") != "web" || r.TLS != nil {
+		t.Errorf("http-only router = %+v, want web without tls", r)
+	}
+
+	routers = read(t, []string{"websecure", "web"})
+	if r := routers["site-blog"]; strings.Join(r.EntryPoints, ",") != "websecure" || r.TLS == nil {
+		t.Errorf("https router = %+v, want websecure with tls", r)
+	}
+	r := routers["site-blog-http"]
+	if strings.Join(r.EntryPoints, ",") != "web" || r.TLS != nil {
+		t.Errorf("http router = %+v, want web without tls", r)
+	}
+	if strings.Join(r.Middlewares, ",") != "compress" {
+		t.Errorf("http router middlewares = %v", r.Middlewares)
+	}
+}
+
+func TestWriteSiteRouteConfigRateLimitAndCompress(t *testing.T) {
+	cfg := newTraefikCfg(t)
+	route := SiteRouteConfig{
+		Name:        "api",
+		Domains:     []string{"api.test"},
+		ServiceName: "srv-api-web",
+		Port:        80,
+		IsLocal:     true,
+		RateLimit:   "100-S",
+		Compress:    true,
+	}
+	if err := WriteSiteRouteConfig(cfg, route); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(cfg.TraefikConfDir(), "site-api.yml"))
+	body := string(data)
+	for _, want := range []string{"site-api-ratelimit", "average: 100", "period: 1s", "site-api-compress", "compress: {}"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("route config missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestParseBodySize(t *testing.T) {
+	for spec, want := range map[string]int64{"1KB": 1024, "10mb": 10 << 20, " 2GB ": 2 << 30, "10GB": 10 << 30} {
+		if got, err := ParseBodySize(spec); err != nil || got != want {
+			t.Errorf("ParseBodySize(%q) = %d, %v; want %d", spec, got, err, want)
+		}
+	}
+	for _, spec := range []string{"", "10", "MB", "0KB", "-1MB", "1.5MB", "11GB", "10TB", "99999999999999999GB"} {
+		if _, err := ParseBodySize(spec); err == nil {
+			t.Errorf("ParseBodySize(%q) = nil error", spec)
+		}
+	}
+}
+
+func TestParseRateLimit(t *testing.T) {
+	avg, period, err := ParseRateLimit("30-m")
+	if err != nil || avg != 30 || period != "1m" {
+		t.Errorf("ParseRateLimit(30-m) = %d, %q, %v", avg, period, err)
+	}
+	for _, spec := range []string{"", "100", "100-D", "0-S", "x-S"} {
+		if _, _, err := ParseRateLimit(spec); err == nil {
+			t.Errorf("ParseRateLimit(%q) = nil error", spec)
+		}
+	}
+}
+
+func TestWriteSiteRouteConfigPathPrefix(t *testing.T) {
+	cfg := newTraefikCfg(t)
+	route := SiteRouteConfig{
+		Name:        "api",
+		Domains:     []string{"app.test"},
+		ServiceName: "srv-api-web",
+		Port:        80,
+		IsLocal:     true,
+		Listeners:   []string{constants.ListenerInternal},
+		PathPrefix:  "/api",
+		StripPrefix: true,
+	}
+	if err := WriteSiteRouteConfig(cfg, route); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(cfg.TraefikConfDir(), "site-api.yml"))
+	body := string(data)
+	if n := strings.Count(body, "(Host(`app.test`)) && PathPrefix(`/api`)"); n != 2 {
+		t.Errorf("want the prefixed rule on the HTTPS and internal routers, found %d:\n%s", n, body)
+	}
+	for _, want := range []string{"site-api-stripprefix", "stripPrefix:", "- /api"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("config missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestWriteSiteRouteConfigRetry(t *testing.T) {
+	cfg := newTraefikCfg(t)
+	route := SiteRouteConfig{
+		Name:          "api",
+		Domains:       []string{"api.test"},
+		ServiceName:   "srv-api-web",
+		Port:          80,
+		IsLocal:       true,
+		Middlewares:   []string{"auth"},
+		RetryAttempts: 3,
+		RetryInterval: "250ms",
+	}
+	if err := WriteSiteRouteConfig(cfg, route); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(cfg.TraefikConfDir(), "site-api.yml"))
+	var conf DynConfig
+	if err := yaml.Unmarshal(data, &conf); err != nil {
+		t.Fatal(err)
+	}
+	retry := conf.HTTP.Middlewares["site-api-retry"].Retry
+	if retry == nil || retry.Attempts != 3 || retry.InitialInterval != "250ms" {
+		t.Errorf("retry middleware = %+v", retry)
+	}
+	if got := conf.HTTP.Routers["site-api"].Middlewares; len(got) != 2 || got[0] != "site-api-retry" || got[1] != "auth" {
+		t.Errorf("router middlewares = %v, want retry ahead of custom ones", got)
+	}
+}
@@ -0,0 +1,97 @@
+// Package traefik — static_diff.go compares the stored static traefik.yml
+// with the one a fresh install would write, for `srv traefik config show
+// --diff`. The comparison goes through mergeTraefikConfigs, so it reports
+// exactly which of the user's edits the next install keeps.
+package traefik
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/stubbedev/srv/internal/config"
+)
+
+// StaticConfigPath returns the path of the static traefik.yml.
+func StaticConfigPath(cfg *config.Config) string {
+	return filepath.Join(cfg.TraefikConfDir(), "traefik.yml")
+}
+
+// FreshStaticConfig renders traefik.yml as EnsureConfig writes it when no
+// file exists yet.
+func FreshStaticConfig(cfg *config.Config) ([]byte, error) {
+	email, _ := GetEmail("")
+	return renderTraefikTemplate(cfg.NetworkName, email, ACMEDNSProvider(cfg))
+}
+
+// SectionStatus says how a top-level traefik.yml section relates to the
+// template.
+type SectionStatus string
+
+const (
+	// SectionDefault matches the template.
+	SectionDefault SectionStatus = "default"
+	// SectionCustomized differs from the template and is kept on reinstall.
+	SectionCustomized SectionStatus = "customized"
+	// SectionAdded isn't in the template and is kept on reinstall.
+	SectionAdded SectionStatus = "added"
+	// SectionMissing is in the template only; the next install restores it.
+	SectionMissing SectionStatus = "missing"
+	// SectionOverwritten differs from the template in a part srv owns; the
+	// next install replaces those edits.
+	SectionOverwritten SectionStatus = "overwritten"
+)
+
+// SectionDiff is the status of one top-level section of traefik.yml.
+type SectionDiff struct {
+	Key    string        `json:"key"`
+	Status SectionStatus `json:"status"`
+}
+
+// CompareStaticConfig classifies every top-level section of the stored
+// traefik.yml against the fresh template, sorted by key.
+func CompareStaticConfig(stored, fresh []byte) ([]SectionDiff, error) {
+	var storedDoc, freshDoc map[string]any
+	if err := yaml.Unmarshal(stored, &storedDoc); err != nil {
+		return nil, fmt.Errorf("traefik.yml is not valid YAML: %w", err)
+	}
+	if err := yaml.Unmarshal(fresh, &freshDoc); err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	merged := mergeTraefikConfigs(storedDoc, freshDoc)
+
+	keys := make([]string, 0, len(storedDoc)+len(freshDoc))
+	for k := range storedDoc {
+		keys = append(keys, k)
+	}
+	for k := range freshDoc {
+		if _, ok := storedDoc[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+
+	diffs := make([]SectionDiff, 0, len(keys))
+	for _, k := range keys {
+		storedVal, inStored := storedDoc[k]
+		freshVal, inFresh := freshDoc[k]
+		var status SectionStatus
+		switch {
+		case !inStored:
+			status = SectionMissing
+		case reflect.DeepEqual(storedVal, freshVal):
+			status = SectionDefault
+		case !reflect.DeepEqual(merged[k], storedVal):
+			status = SectionOverwritten
+		case !inFresh:
+			status = SectionAdded
+		default:
+			status = SectionCustomized
+		}
+		diffs = append(diffs, SectionDiff{Key: k, Status: status})
+	}
+	return diffs, nil
+}
@@ -0,0 +1,48 @@
+package traefik
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompareStaticConfig(t *testing.T) {
+	cfg := newTraefikCfg(t)
+	fresh, err := FreshStaticConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stored := strings.Replace(string(fresh), "level: INFO", "level: DEBUG", 1)
+	stored = strings.Replace(stored, "watch: true", "watch: false", 1)
+	stored = strings.Replace(stored, "tracing:\n  serviceName: traefik\n", "", 1)
+	stored += "metrics:\n  prometheus: {}\n"
+
+	diffs, err := CompareStaticConfig([]byte(stored), fresh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]SectionStatus, len(diffs))
+	for _, d := range diffs {
+		got[d.Key] = d.Status
+	}
+	want := map[string]SectionStatus{
+		"api":                   SectionDefault,
+		"log":                   SectionCustomized,
+		"providers":             SectionOverwritten,
+		"tracing":               SectionMissing,
+		"metrics":               SectionAdded,
+		"entryPoints":           SectionDefault,
+		"certificatesResolvers": SectionDefault,
+	}
+	for k, w := range want {
+		if got[k] != w {
+			t.Errorf("%s = %q, want %q", k, got[k], w)
+		}
+	}
+}
+
+func TestCompareStaticConfigInvalidYAML(t *testing.T) {
+	if _, err := CompareStaticConfig([]byte("a: [\n"), []byte("a: 1\n")); err == nil {
+		t.Error("expected error for invalid stored YAML")
+	}
+}
@@ -2,12 +2,14 @@
 package traefik
 
 import (
+	"cmp"
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
 	"maps"
 	"os"
 	"path/filepath"
+	"reflect"
 	"sort"
 	"strings"
 
@@ -22,7 +24,9 @@ import (
 	"github.com/stubbedev/srv/internal/yamlpatch"
 )
 
-// TraefikYML is the static Traefik configuration template.
+// TraefikYML is the static Traefik configuration template. The web
+// entrypoint's HTTPS redirect runs at the lowest router priority, so routers
+// that sites and redirects put on web answer plain HTTP themselves.
 const TraefikYML = `api:
   dashboard: true
   insecure: true
@@ -33,6 +37,7 @@ log:
 
 accessLog:
   filePath: /etc/traefik/logs/access.log
+  format: json
   bufferingSize: 100
   filters:
     statusCodes:
@@ -50,6 +55,7 @@ entryPoints:
         entryPoint:
           to: websecure
           scheme: https
+          priority: 1
   websecure:
     address: ":443"
   internal:
@@ -70,6 +76,13 @@ certificatesResolvers:
       storage: /etc/traefik/certs/acme.json
       httpChallenge:
         entryPoint: web
+  letsencrypt-staging:
+    acme:
+      email: ""
+      caServer: https://acme-staging-v02.api.letsencrypt.org/directory
+      storage: /etc/traefik/certs/acme-staging.json
+      httpChallenge:
+        entryPoint: web
 `
 
 // Typed model for traefik/docker-compose.yml. Building the document from these
@@ -86,6 +99,7 @@ type composeService struct {
 	Restart       string          `yaml:"restart"`
 	NetworkMode   string          `yaml:"network_mode,omitempty"`
 	Ports         []string        `yaml:"ports,omitempty"`
+	EnvFile       []string        `yaml:"env_file,omitempty"`
 	Environment   []string        `yaml:"environment,omitempty"`
 	Volumes       []string        `yaml:"volumes,omitempty"`
 	Networks      []string        `yaml:"networks,omitempty"`
@@ -120,18 +134,25 @@ func generateRandomString(length int) (string, error) {
 // and paths are encoded as YAML scalars — no string substitution into YAML, no
 // injection surface.
 //
+// tcpPorts are the TCP proxy entrypoint ports (see TCPEntryPointPorts),
+// published alongside the standard ones where Traefik doesn't use host
+// networking.
+//
+// envFile, when set, is loaded as Traefik's environment: env.traefik once a
+// DNS-01 provider is configured, so the provider can read its credentials.
+//
 // On Linux, Traefik uses network_mode: host to access localhost services
 // directly. This lets 'srv proxy' reach services bound to 127.0.0.1 without
 // code changes. The custom Docker network still backs container-to-container
 // communication (containers connect to it and publish ports Traefik reaches via
 // localhost).
-func DockerComposeTemplate(networkName, sitesDir, dnsUser, dnsPass string) (string, error) {
+func DockerComposeTemplate(networkName, sitesDir, dnsUser, dnsPass string, tcpPorts []int, envFile string) (string, error) {
 	traefikSvc := &composeService{
-		Image:         docker.ImageTraefik,
+		Image:         TraefikImage(),
 		ContainerName: docker.ContainerTraefik,
 		Restart:       "unless-stopped",
 		Volumes: []string{
-			"/var/run/docker.sock:/var/run/docker.sock:ro",
+			docker.SocketPath() + ":/var/run/docker.sock:ro",
 			"./conf/traefik.yml:/etc/traefik/traefik.yml:ro",
 			"./conf:/etc/traefik/conf:ro",
 			"./certs:/etc/traefik/certs",
@@ -139,6 +160,9 @@ func DockerComposeTemplate(networkName, sitesDir, dnsUser, dnsPass string) (stri
 			sitesDir + ":/etc/traefik/sites:ro",
 		},
 	}
+	if envFile != "" {
+		traefikSvc.EnvFile = []string{envFile}
+	}
 
 	if platform.IsLinux() {
 		// Host network mode gives Traefik direct access to localhost services;
@@ -147,11 +171,14 @@ func DockerComposeTemplate(networkName, sitesDir, dnsUser, dnsPass string) (stri
 	} else {
 		// Mac/Windows: publish the host ports and join the shared network.
 		traefikSvc.Ports = []string{"80:80", "443:443", "88:88", "8080:8080"}
+		for _, port := range tcpPorts {
+			traefikSvc.Ports = append(traefikSvc.Ports, fmt.Sprintf("%d:%d", port, port))
+		}
 		traefikSvc.Networks = []string{"traefik"}
 	}
 
 	dnsSvc := &composeService{
-		Image:         docker.ImageDNS,
+		Image:         DNSImage(),
 		ContainerName: docker.ContainerDNS,
 		Restart:       "unless-stopped",
 		Ports:         []string{"127.0.0.1:53:53/udp"},
@@ -183,6 +210,32 @@ func DockerComposeTemplate(networkName, sitesDir, dnsUser, dnsPass string) (stri
 	return string(data), nil
 }
 
+// TraefikImage returns the Traefik image: the traefik_image setting, or
+// docker.ImageTraefik.
+func TraefikImage() string {
+	return cmp.Or(config.UserSettings().TraefikImage, docker.ImageTraefik)
+}
+
+// DNSImage returns the dnsmasq image: the dns_image setting, or
+// docker.ImageDNS.
+func DNSImage() string {
+	return cmp.Or(config.UserSettings().DNSImage, docker.ImageDNS)
+}
+
+// WriteCompose regenerates traefik/docker-compose.yml after a setting it
+// depends on (the images) changed; a no-op before `srv install`. Running
+// containers keep their image until they're recreated.
+func WriteCompose() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if !IsInstalled(cfg) {
+		return nil
+	}
+	return writeTraefikCompose(cfg)
+}
+
 // writeTraefikCompose regenerates traefik/docker-compose.yml from the current
 // template. It is idempotent — the content is stable across calls — and exists
 // so callers other than EnsureConfig (notably ReloadDNS) can pick up template
@@ -192,13 +245,22 @@ func writeTraefikCompose(cfg *config.Config) error {
 	if err != nil {
 		return err
 	}
-	composeYML, err := DockerComposeTemplate(cfg.NetworkName, cfg.SitesDir, dnsUser, dnsPass)
+	composeYML, err := DockerComposeTemplate(cfg.NetworkName, cfg.SitesDir, dnsUser, dnsPass, TCPEntryPointPorts(cfg), traefikEnvFile(cfg))
 	if err != nil {
 		return err
 	}
 	return fsutil.AtomicWriteFile(cfg.TraefikComposePath(), []byte(composeYML), constants.FilePermDefault)
 }
 
+// traefikEnvFile returns the env file the Traefik container loads: env.traefik
+// when a DNS-01 provider is configured, "" otherwise.
+func traefikEnvFile(cfg *config.Config) string {
+	if ACMEDNSProvider(cfg) == "" {
+		return ""
+	}
+	return cfg.EnvTraefikPath()
+}
+
 // readEnvFile reads the env.traefik file and returns its key/value pairs.
 // Returns an empty map if the file does not exist.
 func readEnvFile(path string) map[string]string {
@@ -274,10 +336,12 @@ func loadOrGenerateDNSCredentials(envPath string) (user, pass string, err error)
 	return user, pass, nil
 }
 
-// DnsmasqConf is the initial dnsmasq configuration (no domains).
-// Domains are added dynamically via UpdateDnsmasqConfig(). It must stay
-// byte-identical to buildDnsmasqConf(nil, {GoogleDNS1, GoogleDNS2}) so a fresh
-// install does not see a spurious config change on the first domain add.
+// DnsmasqConf is the initial dnsmasq configuration (no domains), with
+// dnsmasqUpstreamMarker standing in for the upstream server= lines; see
+// RenderDnsmasqConf. Domains are added dynamically via UpdateDnsmasqConfig().
+// Rendered, it must stay byte-identical to buildDnsmasqConf(nil, nil,
+// upstream) so a fresh install does not see a spurious config change on the
+// first domain add.
 //
 // Exact (non-wildcard) domains live in the hostsdir, which dnsmasq auto-reloads
 // without a restart; only wildcard domains land in this file.
@@ -290,19 +354,29 @@ hostsdir=/etc/dnsmasq.hosts
 # No wildcard domains registered
 
 # Forward all other queries to upstream DNS
-server=8.8.8.8
-server=8.8.4.4
-
+` + dnsmasqUpstreamMarker + `
 # Don't read /etc/resolv.conf
 no-resolv
 `
 
+// dnsmasqUpstreamMarker marks where RenderDnsmasqConf puts the server= lines.
+const dnsmasqUpstreamMarker = "{{UPSTREAM_DNS}}\n"
+
+// RenderDnsmasqConf returns DnsmasqConf forwarding to the given upstream
+// servers.
+func RenderDnsmasqConf(upstreamDNS []string) string {
+	return strings.Replace(DnsmasqConf, dnsmasqUpstreamMarker, dnsmasqServerLines(upstreamDNS), 1)
+}
+
 // EnsureConfig ensures all Traefik configuration files exist.
 // If traefik.yml exists, it merges user customizations with the template.
-func EnsureConfig(email string) error {
+// Files already holding the rendered content are left untouched; changed
+// reports whether traefik.yml or docker-compose.yml was rewritten, i.e.
+// whether a running Traefik is out of date.
+func EnsureConfig(email string) (changed bool, err error) {
 	cfg, err := config.Load()
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	// Create directories
@@ -316,37 +390,39 @@ func EnsureConfig(email string) error {
 	}
 	for _, dir := range dirs {
 		if err := os.MkdirAll(dir, constants.DirPermDefault); err != nil {
-			return fmt.Errorf("failed to create directory %s: %w", dir, err)
+			return false, fmt.Errorf("failed to create directory %s: %w", dir, err)
 		}
 	}
 
 	// Write or merge traefik.yml
 	traefikPath := filepath.Join(cfg.TraefikConfDir(), "traefik.yml")
-	if err := writeOrMergeTraefikYML(traefikPath, cfg.NetworkName, email); err != nil {
-		return err
+	ymlChanged, err := writeOrMergeTraefikYML(traefikPath, cfg.NetworkName, email, ACMEDNSProvider(cfg))
+	if err != nil {
+		return false, err
 	}
 
 	// Write traefik-dynamic.yml atomically (Traefik watches the conf dir).
 	dynamicPath := filepath.Join(cfg.TraefikConfDir(), "traefik-dynamic.yml")
-	if err := fsutil.AtomicWriteFile(dynamicPath, []byte(renderDynamicConfig(nil)), constants.FilePermDefault); err != nil {
-		return fmt.Errorf("failed to write traefik-dynamic.yml: %w", err)
+	if _, err := fsutil.WriteFileIfChanged(dynamicPath, []byte(renderDynamicConfig(nil)), constants.FilePermDefault); err != nil {
+		return false, fmt.Errorf("failed to write traefik-dynamic.yml: %w", err)
 	}
 
 	// Load or generate DNS credentials once; they are persisted to env.traefik so
 	// subsequent calls to EnsureConfig reuse the same values instead of rotating them.
 	dnsUser, dnsPass, err := loadOrGenerateDNSCredentials(cfg.EnvTraefikPath())
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	// Write docker-compose.yml
-	composeYML, err := DockerComposeTemplate(cfg.NetworkName, cfg.SitesDir, dnsUser, dnsPass)
+	composeYML, err := DockerComposeTemplate(cfg.NetworkName, cfg.SitesDir, dnsUser, dnsPass, TCPEntryPointPorts(cfg), traefikEnvFile(cfg))
 	if err != nil {
-		return err
+		return false, err
 	}
 	composePath := cfg.TraefikComposePath()
-	if err := fsutil.AtomicWriteFile(composePath, []byte(composeYML), constants.FilePermDefault); err != nil {
-		return fmt.Errorf("failed to write docker-compose.yml: %w", err)
+	composeChanged, err := fsutil.WriteFileIfChanged(composePath, []byte(composeYML), constants.FilePermDefault)
+	if err != nil {
+		return false, fmt.Errorf("failed to write docker-compose.yml: %w", err)
 	}
 
 	// Write dnsmasq.conf — preserve registered domains on re-init.
@@ -355,30 +431,32 @@ func EnsureConfig(email string) error {
 	// entries are regenerated from local-domains.txt rather than wiped.
 	dnsmasqPath := filepath.Join(cfg.TraefikDir, constants.DnsmasqConfFile)
 	if _, statErr := os.Stat(dnsmasqPath); os.IsNotExist(statErr) {
-		if err := os.WriteFile(dnsmasqPath, []byte(DnsmasqConf), constants.FilePermDefault); err != nil {
-			return fmt.Errorf("failed to write dnsmasq.conf: %w", err)
+		if err := fsutil.AtomicWriteFile(dnsmasqPath, []byte(RenderDnsmasqConf(UpstreamDNSServers())), constants.FilePermDefault); err != nil {
+			return false, fmt.Errorf("failed to write dnsmasq.conf: %w", err)
 		}
 		// Seed an (empty but non-empty-file) hosts file so dnsmasq's hostsdir
 		// has something to read on first start.
 		hostsPath := filepath.Join(cfg.TraefikDir, constants.DnsmasqHostsDir, constants.DnsmasqHostsFile)
-		if err := os.WriteFile(hostsPath, []byte(buildDnsmasqHosts(nil)), constants.FilePermDefault); err != nil {
-			return fmt.Errorf("failed to write dnsmasq hosts file: %w", err)
+		if err := fsutil.AtomicWriteFile(hostsPath, []byte(buildDnsmasqHosts(nil)), constants.FilePermDefault); err != nil {
+			return false, fmt.Errorf("failed to write dnsmasq hosts file: %w", err)
 		}
 	} else {
 		if err := UpdateDnsmasqConfig(); err != nil {
-			return fmt.Errorf("failed to refresh dnsmasq.conf: %w", err)
+			return false, fmt.Errorf("failed to refresh dnsmasq.conf: %w", err)
 		}
 	}
 
-	// Create acme.json with proper permissions
-	acmePath := filepath.Join(cfg.TraefikDir, constants.CertsSubdir, constants.ACMEJSONFile)
-	if _, err := os.Stat(acmePath); os.IsNotExist(err) {
-		if err := os.WriteFile(acmePath, []byte("{}"), constants.FilePermACME); err != nil {
-			return fmt.Errorf("failed to create acme.json: %w", err)
+	// Create the ACME stores (production + staging) with proper permissions
+	for _, name := range []string{constants.ACMEJSONFile, constants.ACMEStagingJSONFile} {
+		acmePath := filepath.Join(cfg.TraefikDir, constants.CertsSubdir, name)
+		if _, err := os.Stat(acmePath); os.IsNotExist(err) {
+			if err := fsutil.AtomicWriteFile(acmePath, []byte("{}"), constants.FilePermACME); err != nil {
+				return false, fmt.Errorf("failed to create %s: %w", name, err)
+			}
 		}
 	}
 
-	return nil
+	return ymlChanged || composeChanged, nil
 }
 
 // managedTraefikSections are the top-level keys that srv owns. The template
@@ -391,14 +469,16 @@ var managedTraefikSections = []string{"providers", "certificatesResolvers"}
 // All other top-level keys (api, log, metrics, tracing, experimental, tls, …) are preserved
 // verbatim from the existing file. If the existing file is malformed YAML, the call fails
 // rather than silently overwriting it — the user's customizations are too valuable to drop.
-func writeOrMergeTraefikYML(path, networkName, email string) error {
+// dnsProvider, when set, switches the Let's Encrypt resolvers to the DNS-01
+// challenge. Returns whether the file was (re)written.
+func writeOrMergeTraefikYML(path, networkName, email, dnsProvider string) (changed bool, err error) {
 	// Render the template by setting networkName/email structurally rather than
 	// by textual substitution: email is user-supplied, so splicing it into the
 	// YAML text could break the document or inject sibling keys. yamlpatch.Set
 	// encodes each value as a YAML scalar node, which is injection-safe.
-	templateYML, err := renderTraefikTemplate(networkName, email)
+	templateYML, err := renderTraefikTemplate(networkName, email, dnsProvider)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	// Check if file exists
@@ -406,33 +486,39 @@ func writeOrMergeTraefikYML(path, networkName, email string) error {
 	if err != nil {
 		// File doesn't exist, write fresh
 		if os.IsNotExist(err) {
-			return fsutil.AtomicWriteFile(path, templateYML, constants.FilePermDefault)
+			return fsutil.WriteFileIfChanged(path, templateYML, constants.FilePermDefault)
 		}
-		return fmt.Errorf("failed to read existing traefik.yml: %w", err)
+		return false, fmt.Errorf("failed to read existing traefik.yml: %w", err)
 	}
 
 	// Parse existing config — refuse to clobber a file we cannot parse.
 	var existing map[string]any
 	if err := yaml.Unmarshal(existingData, &existing); err != nil {
-		return fmt.Errorf("existing traefik.yml at %s is not valid YAML: %w\n(refusing to overwrite — fix the file or delete it to regenerate)", path, err)
+		return false, fmt.Errorf("existing traefik.yml at %s is not valid YAML: %w\n(refusing to overwrite — fix the file or delete it to regenerate)", path, err)
 	}
 
 	// Parse template config
 	var template map[string]any
 	if err := yaml.Unmarshal(templateYML, &template); err != nil {
-		return fmt.Errorf("failed to parse template: %w", err)
+		return false, fmt.Errorf("failed to parse template: %w", err)
 	}
 
-	// Merge configs
+	// Merge configs. When the merge adds nothing the file is left as it is,
+	// keeping its formatting and comments (the fresh template's included).
 	merged := mergeTraefikConfigs(existing, template)
+	var current map[string]any
+	_ = yaml.Unmarshal(existingData, &current)
+	if reflect.DeepEqual(merged, current) {
+		return false, nil
+	}
 
 	// Marshal back to YAML
 	output, err := yaml.Marshal(merged)
 	if err != nil {
-		return fmt.Errorf("failed to marshal merged config: %w", err)
+		return false, fmt.Errorf("failed to marshal merged config: %w", err)
 	}
 
-	return fsutil.AtomicWriteFile(path, output, constants.FilePermDefault)
+	return fsutil.WriteFileIfChanged(path, output, constants.FilePermDefault)
 }
 
 // traefikMetricsBlock is the prometheus exporter section that `srv metrics
@@ -453,6 +539,14 @@ var traefikMetricsBlock = map[string]any{
 // then restart Traefik, since static config is only read at startup.
 // When enabling, an existing user-customised metrics section is left alone.
 func SetMetricsExporter(enabled bool) (changed bool, err error) {
+	return editTraefikYML(func(doc map[string]any) bool { return setMetricsKey(doc, enabled) })
+}
+
+// editTraefikYML applies edit to the parsed static traefik.yml and writes the
+// file back when edit reports a change. A missing file is edited as an empty
+// document; the next EnsureConfig merge fills in the rest of the template
+// around it.
+func editTraefikYML(edit func(doc map[string]any) bool) (changed bool, err error) {
 	cfg, err := config.Load()
 	if err != nil {
 		return false, err
@@ -469,25 +563,23 @@ func SetMetricsExporter(enabled bool) (changed bool, err error) {
 			doc = map[string]any{}
 		}
 	case os.IsNotExist(err):
-		// Not initialized yet — write just the metrics key; the next
-		// EnsureConfig merge fills in the rest of the template around it.
 	default:
 		return false, fmt.Errorf("read traefik.yml: %w", err)
 	}
-	if setMetricsKey(doc, enabled) {
-		out, err := yaml.Marshal(doc)
-		if err != nil {
-			return false, err
-		}
-		if err := os.MkdirAll(filepath.Dir(path), constants.DirPermDefault); err != nil {
-			return false, err
-		}
-		if err := fsutil.AtomicWriteFile(path, out, constants.FilePermDefault); err != nil {
-			return false, err
-		}
-		return true, nil
+	if !edit(doc) {
+		return false, nil
+	}
+	out, err := yaml.Marshal(doc)
+	if err != nil {
+		return false, err
 	}
-	return false, nil
+	if err := os.MkdirAll(filepath.Dir(path), constants.DirPermDefault); err != nil {
+		return false, err
+	}
+	if err := fsutil.AtomicWriteFile(path, out, constants.FilePermDefault); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 // setMetricsKey toggles the metrics key on a parsed traefik.yml document.
@@ -508,8 +600,9 @@ func setMetricsKey(doc map[string]any, enabled bool) bool {
 // renderTraefikTemplate parses the static-config template and sets the
 // docker-provider network and ACME email by dotted path. Using yamlpatch (the
 // YAML AST) instead of string replacement keeps untrusted values (email)
-// encoded as scalars so they cannot alter the document structure.
-func renderTraefikTemplate(networkName, email string) ([]byte, error) {
+// encoded as scalars so they cannot alter the document structure. A non-empty
+// dnsProvider replaces the resolvers' HTTP-01 challenge with DNS-01.
+func renderTraefikTemplate(networkName, email, dnsProvider string) ([]byte, error) {
 	var doc yaml.Node
 	if err := yaml.Unmarshal([]byte(TraefikYML), &doc); err != nil {
 		return nil, fmt.Errorf("failed to parse traefik.yml template: %w", err)
@@ -517,8 +610,15 @@ func renderTraefikTemplate(networkName, email string) ([]byte, error) {
 	if err := yamlpatch.SetPath(&doc, "providers.docker.network", networkName); err != nil {
 		return nil, fmt.Errorf("failed to set provider network: %w", err)
 	}
-	if err := yamlpatch.SetPath(&doc, "certificatesResolvers.letsencrypt.acme.email", email); err != nil {
-		return nil, fmt.Errorf("failed to set acme email: %w", err)
+	for _, resolver := range []string{constants.CertResolverLetsEncrypt, constants.CertResolverLetsEncryptStaging} {
+		if err := yamlpatch.SetPath(&doc, "certificatesResolvers."+resolver+".acme.email", email); err != nil {
+			return nil, fmt.Errorf("failed to set acme email: %w", err)
+		}
+	}
+	if dnsProvider != "" {
+		if err := setDNSChallenge(&doc, dnsProvider); err != nil {
+			return nil, err
+		}
 	}
 	return yamlpatch.Marshal(&doc)
 }
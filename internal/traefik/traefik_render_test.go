@@ -9,7 +9,7 @@ import (
 // TestRenderTraefikTemplatePositive: ordinary network/email values land at the
 // right paths and the document parses cleanly.
 func TestRenderTraefikTemplatePositive(t *testing.T) {
-	out, err := renderTraefikTemplate("srv-network", "ops@example.com")
+	out, err := renderTraefikTemplate("srv-network", "ops@example.com", "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -25,6 +25,10 @@ func TestRenderTraefikTemplatePositive(t *testing.T) {
 	if email != "ops@example.com" {
 		t.Errorf("email = %v, want ops@example.com", email)
 	}
+	staging := m["certificatesResolvers"].(map[string]any)["letsencrypt-staging"].(map[string]any)["acme"].(map[string]any)
+	if staging["email"] != "ops@example.com" || staging["caServer"] != "https://acme-staging-v02.api.letsencrypt.org/directory" {
+		t.Errorf("staging resolver = %v", staging)
+	}
 }
 
 // TestRenderTraefikTemplateInjection: a malicious email (the value srv takes
@@ -35,7 +39,7 @@ func TestRenderTraefikTemplateInjection(t *testing.T) {
 	// Sentinel key that the template does not contain; if it appears at the top
 	// level, the email payload escaped its scalar and injected structure.
 	malicious := "x@x.com\"\nevilInjectedKey: pwned\nlog:\n  level: DEBUG"
-	out, err := renderTraefikTemplate("srv-network", malicious)
+	out, err := renderTraefikTemplate("srv-network", malicious, "")
 	if err != nil {
 		t.Fatal(err)
 	}
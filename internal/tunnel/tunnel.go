@@ -0,0 +1,80 @@
+// Package tunnel exposes a local origin on a public URL through a
+// Cloudflare quick tunnel (cloudflared) or ngrok. The tunnel runs in the
+// foreground until interrupted; the tool's own output carries the public URL.
+package tunnel
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/stubbedev/srv/internal/shell"
+)
+
+// Supported tunnel tools.
+const (
+	ToolCloudflared = "cloudflared"
+	ToolNgrok       = "ngrok"
+)
+
+// Tools lists the supported tools in the order Detect prefers them.
+var Tools = []string{ToolCloudflared, ToolNgrok}
+
+// Detect returns the first supported tool installed on the host.
+func Detect() (string, error) {
+	for _, tool := range Tools {
+		if shell.Exists(tool) {
+			return tool, nil
+		}
+	}
+	return "", fmt.Errorf("no tunnel tool found: install %s", strings.Join(Tools, " or "))
+}
+
+// Share forwards a public URL to origin with tool until the tool exits.
+// insecure skips verifying the origin's certificate (mkcert certificates are
+// only trusted on this machine).
+func Share(tool, origin string, insecure bool) error {
+	if !shell.Exists(tool) {
+		return fmt.Errorf("%s is not installed", tool)
+	}
+	switch tool {
+	case ToolCloudflared:
+		return RunCloudflared(origin, insecure)
+	case ToolNgrok:
+		return RunNgrok(origin)
+	default:
+		return fmt.Errorf("unknown tunnel tool %q (expected %s)", tool, strings.Join(Tools, " or "))
+	}
+}
+
+// RunCloudflared opens a Cloudflare quick tunnel to origin. The Host header
+// is rewritten to the origin's so Traefik routes the request.
+func RunCloudflared(origin string, insecure bool) error {
+	host, err := originHost(origin)
+	if err != nil {
+		return err
+	}
+	args := []string{"tunnel", "--url", origin, "--http-host-header", host}
+	if insecure {
+		args = append(args, "--no-tls-verify")
+	}
+	return shell.Run(ToolCloudflared, args...)
+}
+
+// RunNgrok opens an ngrok HTTP tunnel to origin, rewriting the Host header to
+// the origin's. ngrok doesn't verify upstream certificates.
+func RunNgrok(origin string) error {
+	if _, err := originHost(origin); err != nil {
+		return err
+	}
+	return shell.Run(ToolNgrok, "http", origin, "--host-header=rewrite")
+}
+
+// originHost returns origin's hostname.
+func originHost(origin string) (string, error) {
+	u, err := url.Parse(origin)
+	if err != nil || u.Hostname() == "" {
+		return "", fmt.Errorf("invalid tunnel origin %q", origin)
+	}
+	return u.Hostname(), nil
+}
@@ -0,0 +1,62 @@
+package tunnel
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stubbedev/srv/internal/shell"
+	"github.com/stubbedev/srv/internal/shell/shelltest"
+)
+
+func TestDetect(t *testing.T) {
+	t.Cleanup(shell.SwapDefault(shelltest.New(map[string]shelltest.Response{
+		ToolNgrok: {Exists: true},
+	})))
+	if tool, err := Detect(); err != nil || tool != ToolNgrok {
+		t.Errorf("Detect() = %q, %v; want ngrok", tool, err)
+	}
+
+	t.Cleanup(shell.SwapDefault(shelltest.New(nil)))
+	if _, err := Detect(); err == nil {
+		t.Error("expected an error with no tool installed")
+	}
+}
+
+func TestShare(t *testing.T) {
+	cases := []struct {
+		tool     string
+		insecure bool
+		want     []string
+	}{
+		{ToolCloudflared, false, []string{"tunnel", "--url", "https://blog.example.com", "--http-host-header", "blog.example.com"}},
+		{ToolCloudflared, true, []string{"tunnel", "--url", "https://blog.example.com", "--http-host-header", "blog.example.com", "--no-tls-verify"}},
+		{ToolNgrok, true, []string{"http", "https://blog.example.com", "--host-header=rewrite"}},
+	}
+	for _, tc := range cases {
+		fake := &shelltest.Fake{Default: shelltest.Response{Exists: true}}
+		t.Cleanup(shell.SwapDefault(fake))
+		if err := Share(tc.tool, "https://blog.example.com", tc.insecure); err != nil {
+			t.Fatalf("%s: %v", tc.tool, err)
+		}
+		calls := fake.Snapshot()
+		last := calls[len(calls)-1]
+		if last.Method != "Run" || last.Name != tc.tool || !slices.Equal(last.Args, tc.want) {
+			t.Errorf("%s (insecure=%v) ran %+v, want args %v", tc.tool, tc.insecure, last, tc.want)
+		}
+	}
+}
+
+func TestShareErrors(t *testing.T) {
+	t.Cleanup(shell.SwapDefault(shelltest.New(nil)))
+	if err := Share(ToolNgrok, "https://blog.example.com", false); err == nil {
+		t.Error("expected an error for a missing tool")
+	}
+
+	t.Cleanup(shell.SwapDefault(&shelltest.Fake{Default: shelltest.Response{Exists: true}}))
+	if err := Share("localtunnel", "https://blog.example.com", false); err == nil {
+		t.Error("expected an error for an unknown tool")
+	}
+	if err := Share(ToolCloudflared, "not a url", false); err == nil {
+		t.Error("expected an error for an invalid origin")
+	}
+}
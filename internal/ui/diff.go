@@ -0,0 +1,129 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+)
+
+// sideBySideMaxWidth caps the width of the left column of RenderSideBySide.
+const sideBySideMaxWidth = 60
+
+// RenderSideBySide returns a two-column line diff of left and right in the
+// style of `diff -y`: the marker between the columns is blank for equal
+// lines, | for changed ones, < for lines only on the left and > for lines
+// only on the right. Differing lines are coloured: left in yellow, right in
+// green.
+func RenderSideBySide(leftTitle, rightTitle string, left, right []string) string {
+	width := len(leftTitle)
+	for _, line := range left {
+		width = max(width, len(line))
+	}
+	width = min(width, sideBySideMaxWidth)
+
+	var b strings.Builder
+	row := func(l, marker, r string, colour bool) {
+		l = truncate(l, width)
+		pad := strings.Repeat(" ", width-len(l))
+		if colour {
+			if l != "" {
+				l = warnC(l)
+			}
+			if r != "" {
+				r = successC(r)
+			}
+		}
+		fmt.Fprintf(&b, "%s%s %s %s\n", l, pad, marker, r)
+	}
+	row(leftTitle, " ", rightTitle, false)
+	b.WriteString(dimC(strings.Repeat("-", width+3+len(rightTitle))) + "\n")
+
+	// Lines removed and added between two equal lines are paired up as
+	// changed rows; the surplus on either side is shown alone.
+	var removed, added []string
+	flush := func() {
+		for i := range max(len(removed), len(added)) {
+			switch {
+			case i < len(removed) && i < len(added):
+				row(removed[i], "|", added[i], true)
+			case i < len(removed):
+				row(removed[i], "<", "", true)
+			default:
+				row("", ">", added[i], true)
+			}
+		}
+		removed, added = removed[:0], added[:0]
+	}
+	for _, op := range diffLines(left, right) {
+		switch op.kind {
+		case '-':
+			removed = append(removed, op.line)
+		case '+':
+			added = append(added, op.line)
+		default:
+			flush()
+			row(op.line, " ", op.line, false)
+		}
+	}
+	flush()
+	return b.String()
+}
+
+// diffOp is one line of a line diff: kind is ' ' (in both), '-' (left only)
+// or '+' (right only).
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// diffLines computes a line diff of a and b from their longest common
+// subsequence.
+func diffLines(a, b []string) []diffOp {
+	// lcs[i][j] is the LCS length of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	ops := make([]diffOp, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
+
+// truncate shortens s to width characters, marking the cut with "...".
+func truncate(s string, width int) string {
+	if len(s) <= width {
+		return s
+	}
+	if width <= 3 {
+		return s[:width]
+	}
+	return s[:width-3] + "..."
+}
@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderSideBySide(t *testing.T) {
+	left := []string{"a", "b", "c", "d"}
+	right := []string{"a", "B", "c", "e", "f"}
+	out := stripAnsi(RenderSideBySide("old", "new", left, right))
+	lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+
+	if !strings.HasPrefix(lines[0], "old") || !strings.Contains(lines[0], "new") {
+		t.Errorf("header = %q", lines[0])
+	}
+	var changed, added, same int
+	for _, l := range lines[1:] {
+		switch {
+		case strings.Contains(l, " | "):
+			changed++
+		case strings.Contains(l, " > "):
+			added++
+		case strings.Contains(l, " < "):
+			t.Errorf("unexpected removal line %q", l)
+		default:
+			same++
+		}
+	}
+	if changed != 2 || added != 1 || same < 2 {
+		t.Errorf("changed=%d added=%d same=%d\n%s", changed, added, same, out)
+	}
+}
+
+func TestDiffLinesIdentical(t *testing.T) {
+	ops := diffLines([]string{"x", "y"}, []string{"x", "y"})
+	for _, op := range ops {
+		if op.kind != ' ' {
+			t.Errorf("op %q on identical input", op.kind)
+		}
+	}
+	if len(ops) != 2 {
+		t.Errorf("len(ops) = %d, want 2", len(ops))
+	}
+}
@@ -5,8 +5,8 @@
 //  1. Scriptable. Results go to stdout, diagnostics (Info/Warn/Dim/Success
 //     status messages) go to stderr. Pipe-safe by default: NO_COLOR is
 //     honoured and ANSI colour codes are auto-disabled when stdout/stderr
-//     isn't a TTY. No interactive prompts in this package — callers must
-//     either drive the run via flags or read stdin themselves.
+//     isn't a TTY. The only interactive prompt is Confirm, which callers
+//     offer behind a --yes flag and a stdin TTY check.
 //  2. Plain. No Unicode icons; the colour alone signals severity. The
 //     message text always carries the semantics so output remains
 //     greppable even when colour is stripped.
@@ -15,14 +15,18 @@
 package ui
 
 import (
+	"bufio"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
 
 	"github.com/stubbedev/srv/internal/constants"
 )
@@ -30,8 +34,8 @@ import (
 var (
 	// Verbose unlocks VerboseLog. Off by default.
 	Verbose bool
-	// Quiet suppresses Info/Warn/Dim/Success diagnostic lines. Error and
-	// Print (result) output still go through.
+	// Quiet suppresses Info/Dim/Success/Bold diagnostic lines. Error, Warn
+	// and Print (result) output still go through.
 	Quiet bool
 
 	// printMu serialises stdout/stderr writes for the parallel-operation helpers.
@@ -48,15 +52,34 @@ var (
 	boldC    = color.New(color.Bold).SprintFunc()
 	cyanC    = color.New(color.FgCyan).SprintFunc()
 	purpleC  = color.New(color.FgMagenta).SprintFunc()
+	flashC   = color.New(color.ReverseVideo, color.Bold).SprintFunc()
 )
 
-// outStdout / outStderr are the destinations for diagnostic / result output.
-// Exposed as vars so tests can swap them.
+// outStdout / outStderr are the destinations for diagnostic / result output,
+// and inStdin is where Confirm reads answers. Exposed as vars so tests can
+// swap them.
 var (
 	outStdout io.Writer = os.Stdout
 	outStderr io.Writer = os.Stderr
+	inStdin   io.Reader = os.Stdin
 )
 
+// SwapStdout redirects result output to w. Returns a restore func for
+// t.Cleanup.
+func SwapStdout(w io.Writer) func() {
+	prev := outStdout
+	outStdout = w
+	return func() { outStdout = prev }
+}
+
+// SwapStderr redirects diagnostic output to w. Returns a restore func for
+// t.Cleanup.
+func SwapStderr(w io.Writer) func() {
+	prev := outStderr
+	outStderr = w
+	return func() { outStderr = prev }
+}
+
 // Steps tracks progress through a multi-step operation. Output goes to stderr
 // (it's diagnostic, not result data).
 type Steps struct {
@@ -95,6 +118,71 @@ func (s *Steps) Skip(format string, args ...any) {
 	fmt.Fprintf(outStderr, "%s %s\n", dimC(fmt.Sprintf("[%d/%d]", s.current, s.total)), dimC(msg))
 }
 
+// spinnerFrames are drawn in turn while a Spinner runs. Plain ASCII, per the
+// package's no-icons rule.
+var spinnerFrames = []string{"|", "/", "-", "\\"}
+
+// spinnerInterval is how long each frame shows.
+const spinnerInterval = 120 * time.Millisecond
+
+// Spinner animates a status line on stderr while a long step runs. When
+// stderr isn't a terminal it prints the message once, as Info would, so logs
+// don't fill with frames. Suppressed under --quiet.
+type Spinner struct {
+	msg  string
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewSpinner creates a spinner for the message. Call Start, then Stop once
+// the step finishes.
+func NewSpinner(format string, args ...any) *Spinner {
+	return &Spinner{msg: fmt.Sprintf(format, args...)}
+}
+
+// Start begins the animation.
+func (s *Spinner) Start() {
+	if Quiet || s.stop != nil {
+		return
+	}
+	f, ok := outStderr.(*os.File)
+	if !ok || !isatty.IsTerminal(f.Fd()) {
+		fmt.Fprintln(outStderr, infoC(s.msg))
+		return
+	}
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(spinnerInterval)
+		defer ticker.Stop()
+		for i := 0; ; i++ {
+			printMu.Lock()
+			fmt.Fprintf(outStderr, "\r%s %s", infoC(spinnerFrames[i%len(spinnerFrames)]), s.msg)
+			printMu.Unlock()
+			select {
+			case <-s.stop:
+				printMu.Lock()
+				fmt.Fprintf(outStderr, "\r%s\r", strings.Repeat(" ", len(s.msg)+2))
+				printMu.Unlock()
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop ends the animation and clears its line. Safe to call more than once,
+// and without Start.
+func (s *Spinner) Stop() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	<-s.done
+	s.stop = nil
+}
+
 // Success writes a diagnostic success line to stderr. Suppressed under --quiet.
 func Success(format string, args ...any) {
 	if Quiet {
@@ -108,11 +196,8 @@ func Error(format string, args ...any) {
 	fmt.Fprintln(outStderr, errorC(fmt.Sprintf(format, args...)))
 }
 
-// Warn writes a warning line to stderr. Suppressed under --quiet.
+// Warn writes a warning line to stderr. Never suppressed.
 func Warn(format string, args ...any) {
-	if Quiet {
-		return
-	}
 	fmt.Fprintln(outStderr, warnC(fmt.Sprintf(format, args...)))
 }
 
@@ -172,6 +257,16 @@ func UsageError(usage, format string, args ...any) error {
 	return fmt.Errorf("%s\n%s", msg, hint)
 }
 
+// Confirm asks question on stderr, so it stays out of piped result output,
+// and reports whether the answer from stdin is y or yes. Anything else,
+// including EOF, is no.
+func Confirm(question string) bool {
+	fmt.Fprintf(outStderr, "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(inStdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
 // VerboseLog writes to stderr only when Verbose is true.
 func VerboseLog(format string, args ...any) {
 	if !Verbose {
@@ -200,9 +295,6 @@ func IndentedError(level int, format string, args ...any) {
 
 // IndentedWarn writes an indented warning to stderr.
 func IndentedWarn(level int, format string, args ...any) {
-	if Quiet {
-		return
-	}
 	fmt.Fprintln(outStderr, warnC(Indent(level, format, args...)))
 }
 
@@ -226,6 +318,14 @@ func IndentedDim(level int, format string, args ...any) {
 // Thread-safe variants for parallel operations
 // =============================================================================
 
+// SafePrint writes a result line to stdout under a mutex, so lines from
+// concurrent goroutines never interleave mid-line.
+func SafePrint(format string, args ...any) {
+	printMu.Lock()
+	defer printMu.Unlock()
+	fmt.Fprintln(outStdout, fmt.Sprintf(format, args...))
+}
+
 // SafeIndentedDim writes a dim line under a mutex.
 func SafeIndentedDim(level int, format string, args ...any) {
 	if Quiet {
@@ -236,6 +336,16 @@ func SafeIndentedDim(level int, format string, args ...any) {
 	fmt.Fprintln(outStderr, dimC(Indent(level, format, args...)))
 }
 
+// SafeSuccess writes a success line under a mutex.
+func SafeSuccess(format string, args ...any) {
+	if Quiet {
+		return
+	}
+	printMu.Lock()
+	defer printMu.Unlock()
+	fmt.Fprintln(outStderr, successC(fmt.Sprintf(format, args...)))
+}
+
 // SafeError writes an error line under a mutex.
 func SafeError(format string, args ...any) {
 	printMu.Lock()
@@ -245,9 +355,6 @@ func SafeError(format string, args ...any) {
 
 // SafeWarn writes a warning line under a mutex.
 func SafeWarn(format string, args ...any) {
-	if Quiet {
-		return
-	}
 	printMu.Lock()
 	defer printMu.Unlock()
 	fmt.Fprintln(outStderr, warnC(fmt.Sprintf(format, args...)))
@@ -263,12 +370,14 @@ func StatusColor(status string) string {
 	switch status {
 	case "running", "valid", "active":
 		return successC(status)
-	case "stopped", "auto", "inactive":
+	case "stopped", "auto", "inactive", constants.StatusDisabled:
 		return dimC(status)
 	case "broken", "expired", "missing", "failed":
 		return errorC(status)
 	case "expiring":
 		return warnC(status)
+	case constants.StatusParked:
+		return cyanC(status)
 	default:
 		if strings.HasPrefix(status, constants.StatusPartial) {
 			return warnC(status)
@@ -297,6 +406,29 @@ func InfoText(s string) string    { return infoC(s) }
 func DimText(s string) string     { return dimC(s) }
 func AccentText(s string) string  { return purpleC(s) }
 
+// FlashText returns text in reverse video, for drawing the eye to a value
+// that just changed.
+func FlashText(s string) string { return flashC(s) }
+
+// paletteC is the colour cycle PaletteText picks from.
+var paletteC = []func(a ...any) string{
+	cyanC, purpleC, successC, warnC, infoC,
+	color.New(color.FgHiCyan).SprintFunc(),
+	color.New(color.FgHiMagenta).SprintFunc(),
+	color.New(color.FgHiGreen).SprintFunc(),
+	color.New(color.FgHiYellow).SprintFunc(),
+	color.New(color.FgHiBlue).SprintFunc(),
+}
+
+// PaletteText returns s in the i-th colour of a fixed cycle, so interleaved
+// streams (one per site) can be told apart at a glance.
+func PaletteText(i int, s string) string {
+	if i < 0 {
+		i = -i
+	}
+	return paletteC[i%len(paletteC)](s)
+}
+
 // =============================================================================
 // Table output — plain ASCII, no lipgloss
 // =============================================================================
@@ -308,8 +440,38 @@ func AccentText(s string) string  { return purpleC(s) }
 // Width is computed from the visible character count (ANSI sequences stripped)
 // so coloured cells don't throw alignment off.
 func PrintTable(headers []string, rows [][]string) {
+	fmt.Fprint(outStdout, RenderTable(headers, rows))
+}
+
+// PrintCSV writes headers and rows to STDOUT as RFC 4180 CSV, for pasting
+// into a spreadsheet. ANSI colour codes are stripped from every cell, so the
+// rows PrintTable takes can be passed as they are.
+func PrintCSV(headers []string, rows [][]string) error {
+	w := csv.NewWriter(outStdout)
+	plain := func(cells []string) []string {
+		out := make([]string, len(cells))
+		for i, c := range cells {
+			out[i] = stripAnsi(c)
+		}
+		return out
+	}
+	if err := w.Write(plain(headers)); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := w.Write(plain(row)); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// RenderTable returns the table PrintTable would print, for callers that
+// compose a whole screen before writing it (e.g. `srv status`).
+func RenderTable(headers []string, rows [][]string) string {
 	if len(headers) == 0 && len(rows) == 0 {
-		return
+		return ""
 	}
 	widths := make([]int, len(headers))
 	for i, h := range headers {
@@ -326,16 +488,17 @@ func PrintTable(headers []string, rows [][]string) {
 		}
 	}
 
+	var b strings.Builder
 	// Header
 	for i, h := range headers {
 		visible := stripAnsi(h)
 		padding := widths[i] - len(visible)
-		fmt.Fprint(outStdout, boldC(h)+strings.Repeat(" ", padding))
+		b.WriteString(boldC(h) + strings.Repeat(" ", padding))
 		if i < len(headers)-1 {
-			fmt.Fprint(outStdout, "  ")
+			b.WriteString("  ")
 		}
 	}
-	fmt.Fprintln(outStdout)
+	b.WriteString("\n")
 
 	// Rows
 	for _, row := range rows {
@@ -345,13 +508,14 @@ func PrintTable(headers []string, rows [][]string) {
 			}
 			visible := stripAnsi(cell)
 			padding := widths[i] - len(visible)
-			fmt.Fprint(outStdout, cell+strings.Repeat(" ", padding))
+			b.WriteString(cell + strings.Repeat(" ", padding))
 			if i < len(row)-1 {
-				fmt.Fprint(outStdout, "  ")
+				b.WriteString("  ")
 			}
 		}
-		fmt.Fprintln(outStdout)
+		b.WriteString("\n")
 	}
+	return b.String()
 }
 
 // PrintJSON writes the given value to STDOUT as indented JSON followed by a
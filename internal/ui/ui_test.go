@@ -159,7 +159,7 @@ func TestSafeHelpersSmoke(t *testing.T) {
 	SafeWarn("w")
 }
 
-// Quiet mode suppresses Info/Warn/Dim/Success but lets Error and Print through.
+// Quiet mode suppresses Info/Dim/Success but lets Error, Warn and Print through.
 func TestQuietSuppressesDiagnostics(t *testing.T) {
 	prev := Quiet
 	defer func() { Quiet = prev }()
@@ -184,7 +184,10 @@ func TestQuietSuppressesDiagnostics(t *testing.T) {
 	if !strings.Contains(stderr.String(), "err") {
 		t.Errorf("stderr missing error: %q", stderr.String())
 	}
-	if strings.Contains(stderr.String(), "info") || strings.Contains(stderr.String(), "warn") || strings.Contains(stderr.String(), "dim") || strings.Contains(stderr.String(), "ok") {
+	if !strings.Contains(stderr.String(), "warn") {
+		t.Errorf("stderr missing warning: %q", stderr.String())
+	}
+	if strings.Contains(stderr.String(), "info") || strings.Contains(stderr.String(), "dim") || strings.Contains(stderr.String(), "ok") {
 		t.Errorf("Quiet leaked diagnostic: %q", stderr.String())
 	}
 	if !strings.Contains(stdout.String(), "result") {
@@ -232,3 +235,63 @@ func TestDiagnosticsGoToStderr(t *testing.T) {
 		}
 	}
 }
+
+// Off a terminal the spinner prints its message once instead of animating.
+func TestSpinnerWithoutTerminal(t *testing.T) {
+	var stderr bytes.Buffer
+	swapStderr := outStderr
+	defer func() { outStderr = swapStderr }()
+	outStderr = &stderr
+
+	s := NewSpinner("Rebuilding %s...", "blog")
+	s.Start()
+	s.Stop()
+	s.Stop()
+	if got := stderr.String(); got != "Rebuilding blog...\n" {
+		t.Errorf("stderr = %q", got)
+	}
+}
+
+func TestPrintCSV(t *testing.T) {
+	var buf bytes.Buffer
+	t.Cleanup(SwapStdout(&buf))
+	rows := [][]string{
+		{successC("running"), "/srv/a,b", `say "hi"`},
+		{"plain", "", "multi\nline"},
+	}
+	if err := PrintCSV([]string{"STATUS", "PATH", "NOTE"}, rows); err != nil {
+		t.Fatal(err)
+	}
+	want := "STATUS,PATH,NOTE\nrunning,\"/srv/a,b\",\"say \"\"hi\"\"\"\nplain,,\"multi\nline\"\n"
+	if got := buf.String(); got != want {
+		t.Errorf("PrintCSV =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	prevIn := inStdin
+	t.Cleanup(func() { inStdin = prevIn })
+	var stderr bytes.Buffer
+	t.Cleanup(SwapStderr(&stderr))
+	var stdout bytes.Buffer
+	t.Cleanup(SwapStdout(&stdout))
+
+	for answer, want := range map[string]bool{
+		"y\n":    true,
+		"YES\n":  true,
+		"n\n":    false,
+		"":       false,
+		"sure\n": false,
+	} {
+		inStdin = strings.NewReader(answer)
+		if got := Confirm("Proceed?"); got != want {
+			t.Errorf("Confirm with %q = %v, want %v", answer, got, want)
+		}
+	}
+	if !strings.Contains(stderr.String(), "Proceed? [y/N]") {
+		t.Errorf("prompt not on stderr: %q", stderr.String())
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("prompt leaked to stdout: %q", stdout.String())
+	}
+}
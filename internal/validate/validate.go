@@ -7,6 +7,7 @@ package validate
 
 import (
 	"fmt"
+	"net"
 	"regexp"
 	"strconv"
 	"strings"
@@ -23,9 +24,23 @@ var (
 	// siteNameRegex matches site names: alphanumeric with hyphens and underscores.
 	siteNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
 
+	// tagRegex matches site tags: lowercase alphanumeric words joined by
+	// single hyphens. Examples: production, team-a.
+	tagRegex = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
 	// containerNameRegex matches Docker container/compose service names:
 	// alphanumeric, underscores, hyphens, periods.
 	containerNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+	// middlewareNameRegex matches Traefik middleware names as srv references
+	// them: alphanumeric, underscores, hyphens (no @provider suffix).
+	middlewareNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+	// headerNameRegex matches an HTTP header field name: an RFC 7230 token.
+	headerNameRegex = regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]+$")
+
+	// yamlLineRegex finds the "line N: " yaml.v3 puts in parse and type errors.
+	yamlLineRegex = regexp.MustCompile(`(?:yaml: )?\bline (\d+): ?`)
 )
 
 // Domain validates a domain/hostname format, returning an error if invalid.
@@ -101,6 +116,20 @@ func SiteName(name string) error {
 	return nil
 }
 
+// Tag validates a site tag.
+func Tag(tag string) error {
+	if tag == "" {
+		return fmt.Errorf("tag cannot be empty")
+	}
+	if len(tag) > constants.MaxTagLength {
+		return fmt.Errorf("tag %q is too long (max %d characters)", tag, constants.MaxTagLength)
+	}
+	if !tagRegex.MatchString(tag) {
+		return fmt.Errorf("invalid tag: %s (use lowercase letters, digits and single hyphens)", tag)
+	}
+	return nil
+}
+
 // ContainerName validates a Docker container or compose service name.
 func ContainerName(name string) error {
 	if name == "" {
@@ -112,6 +141,83 @@ func ContainerName(name string) error {
 	return nil
 }
 
+// Middlewares validates a site's custom Traefik middleware list: at most
+// MaxSiteMiddlewares entries, each a plain middleware name.
+func Middlewares(names []string) error {
+	if len(names) > constants.MaxSiteMiddlewares {
+		return fmt.Errorf("too many middlewares: %d (max %d)", len(names), constants.MaxSiteMiddlewares)
+	}
+	for _, n := range names {
+		if !middlewareNameRegex.MatchString(n) {
+			return fmt.Errorf("invalid middleware name: %q (use alphanumeric characters, hyphens, and underscores)", n)
+		}
+	}
+	return nil
+}
+
+// CIDRs validates a site's IP allowlist: each entry must be a CIDR such as
+// 192.168.1.0/24 or fd00::/8. A single host is written as /32 (or /128).
+func CIDRs(cidrs []string) error {
+	for _, c := range cidrs {
+		if _, _, err := net.ParseCIDR(c); err != nil {
+			return fmt.Errorf("invalid CIDR %q (expected e.g. 192.168.1.0/24 or 10.0.0.5/32)", c)
+		}
+	}
+	return nil
+}
+
+// HeaderName validates an HTTP header field name against the RFC 7230 token
+// grammar.
+func HeaderName(name string) error {
+	if !headerNameRegex.MatchString(name) {
+		return fmt.Errorf("invalid header name %q (RFC 7230 token: letters, digits and !#$%%&'*+-.^_`|~)", name)
+	}
+	return nil
+}
+
+// ResponseHeaders validates a site's header edits: names must be tokens,
+// values must not contain control characters (which would split the header),
+// and a header can't be both added and removed.
+func ResponseHeaders(add map[string]string, remove []string) error {
+	for name, value := range add {
+		if err := HeaderName(name); err != nil {
+			return err
+		}
+		if strings.ContainsFunc(value, func(r rune) bool { return (r < 0x20 && r != '\t') || r == 0x7f }) {
+			return fmt.Errorf("invalid value for header %s: control characters are not allowed", name)
+		}
+	}
+	for _, name := range remove {
+		if err := HeaderName(name); err != nil {
+			return err
+		}
+		for added := range add {
+			if strings.EqualFold(added, name) {
+				return fmt.Errorf("header %s is both added and removed", name)
+			}
+		}
+	}
+	return nil
+}
+
+// PathPrefix validates a router path prefix and returns it without trailing
+// slashes. It must start with / and can't contain whitespace, quotes or
+// backticks, which would break out of the PathPrefix(`...`) rule; "/" alone is
+// rejected because it matches every request.
+func PathPrefix(prefix string) (string, error) {
+	if !strings.HasPrefix(prefix, "/") {
+		return "", fmt.Errorf("invalid path prefix %q: must start with /", prefix)
+	}
+	if strings.ContainsAny(prefix, "`\" \t") {
+		return "", fmt.Errorf("invalid path prefix %q: must not contain spaces, quotes or backticks", prefix)
+	}
+	prefix = strings.TrimRight(prefix, "/")
+	if prefix == "" {
+		return "", fmt.Errorf("invalid path prefix: / matches every request; omit the flag instead")
+	}
+	return prefix, nil
+}
+
 // ProxyName validates a proxy name. Proxy names may contain periods because
 // they are often derived from domain names (e.g. "myapp.com").
 func ProxyName(name string) error {
@@ -126,3 +232,19 @@ func ProxyName(name string) error {
 	}
 	return nil
 }
+
+// YAMLError splits a yaml.v3 error into the 1-based line it reports (0 when
+// the message carries none) and the message without the "yaml: line N: "
+// prefix, so callers can print it as file:line: message.
+func YAMLError(err error) (int, string) {
+	if err == nil {
+		return 0, ""
+	}
+	msg := err.Error()
+	m := yamlLineRegex.FindStringSubmatchIndex(msg)
+	if m == nil {
+		return 0, strings.TrimPrefix(msg, "yaml: ")
+	}
+	n, _ := strconv.Atoi(msg[m[2]:m[3]])
+	return n, msg[:m[0]] + msg[m[1]:]
+}
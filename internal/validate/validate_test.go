@@ -1,6 +1,10 @@
 package validate
 
-import "testing"
+import (
+	"errors"
+	"strings"
+	"testing"
+)
 
 func TestDomain(t *testing.T) {
 	valid := []string{
@@ -104,3 +108,114 @@ func TestSiteName(t *testing.T) {
 		}
 	}
 }
+
+func TestTag(t *testing.T) {
+	for _, tag := range []string{"production", "team-a", "v2", strings.Repeat("a", 32)} {
+		if err := Tag(tag); err != nil {
+			t.Errorf("Tag(%q) = %v, want nil", tag, err)
+		}
+	}
+	for _, tag := range []string{"", "Production", "-lead", "trail-", "a--b", "has space", "a_b", strings.Repeat("a", 33)} {
+		if err := Tag(tag); err == nil {
+			t.Errorf("Tag(%q) = nil, want error", tag)
+		}
+	}
+}
+
+func TestMiddlewares(t *testing.T) {
+	if err := Middlewares([]string{"compress", "retry_2", "secure-headers"}); err != nil {
+		t.Errorf("valid middlewares rejected: %v", err)
+	}
+	if err := Middlewares(nil); err != nil {
+		t.Errorf("empty list rejected: %v", err)
+	}
+	for _, n := range []string{"", "auth@file", "has space", "a.b"} {
+		if err := Middlewares([]string{n}); err == nil {
+			t.Errorf("Middlewares([%q]) = nil, want error", n)
+		}
+	}
+	tooMany := make([]string, 11)
+	for i := range tooMany {
+		tooMany[i] = "m"
+	}
+	if err := Middlewares(tooMany); err == nil {
+		t.Error("expected error for more than 10 middlewares")
+	}
+}
+
+func TestCIDRs(t *testing.T) {
+	if err := CIDRs([]string{"192.168.1.0/24", "10.0.0.5/32", "fd00::/8"}); err != nil {
+		t.Errorf("valid CIDRs rejected: %v", err)
+	}
+	if err := CIDRs(nil); err != nil {
+		t.Errorf("empty list rejected: %v", err)
+	}
+	for _, c := range []string{"", "10.0.0.1", "10.0.0.0/33", "lan"} {
+		if err := CIDRs([]string{c}); err == nil {
+			t.Errorf("CIDRs([%q]) = nil, want error", c)
+		}
+	}
+}
+
+func TestHeaderName(t *testing.T) {
+	for _, n := range []string{"X-Robots-Tag", "x_custom", "Accept"} {
+		if err := HeaderName(n); err != nil {
+			t.Errorf("HeaderName(%q) = %v", n, err)
+		}
+	}
+	for _, n := range []string{"", "X Env", "X-Env:", "X-\u00e9", "X(1)"} {
+		if err := HeaderName(n); err == nil {
+			t.Errorf("HeaderName(%q) = nil, want error", n)
+		}
+	}
+}
+
+func TestResponseHeaders(t *testing.T) {
+	if err := ResponseHeaders(map[string]string{"X-Env": "staging"}, []string{"X-Powered-By"}); err != nil {
+		t.Errorf("valid edits rejected: %v", err)
+	}
+	if err := ResponseHeaders(map[string]string{"X-Env": "a\r\nSet-Cookie: x"}, nil); err == nil {
+		t.Error("expected error for a value with CRLF")
+	}
+	if err := ResponseHeaders(map[string]string{"X-Env": "a"}, []string{"x-env"}); err == nil {
+		t.Error("expected error for a header both added and removed")
+	}
+	if err := ResponseHeaders(nil, []string{"bad name"}); err == nil {
+		t.Error("expected error for an invalid removed header")
+	}
+}
+
+func TestYAMLError(t *testing.T) {
+	cases := []struct {
+		in   string
+		line int
+		msg  string
+	}{
+		{"yaml: line 7: mapping values are not allowed in this context", 7, "mapping values are not allowed in this context"},
+		{"line 12: field foo not found in type traefik.dynRouter", 12, "field foo not found in type traefik.dynRouter"},
+		{"parse metadata: yaml: line 3: did not find expected key", 3, "parse metadata: did not find expected key"},
+		{"yaml: unmarshal errors", 0, "unmarshal errors"},
+	}
+	for _, tc := range cases {
+		line, msg := YAMLError(errors.New(tc.in))
+		if line != tc.line || msg != tc.msg {
+			t.Errorf("YAMLError(%q) = %d, %q; want %d, %q", tc.in, line, msg, tc.line, tc.msg)
+		}
+	}
+	if line, msg := YAMLError(nil); line != 0 || msg != "" {
+		t.Error("nil error should yield 0, \"\"")
+	}
+}
+
+func TestPathPrefix(t *testing.T) {
+	for in, want := range map[string]string{"/api": "/api", "/api/": "/api", "/a/b": "/a/b"} {
+		if got, err := PathPrefix(in); err != nil || got != want {
+			t.Errorf("PathPrefix(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"api", "/", "/my api", "/a`b", "/a\"b"} {
+		if _, err := PathPrefix(in); err == nil {
+			t.Errorf("PathPrefix(%q) = nil error, want error", in)
+		}
+	}
+}